@@ -13,6 +13,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/cloudflare/cloudflare-go/v4"
 	"github.com/cloudflare/cloudflare-go/v4/option"
 	"github.com/cyrusaf/ctxlog"
@@ -22,7 +23,9 @@ import (
 	"github.com/ssoready/conf"
 	stripeclient "github.com/stripe/stripe-go/v82/client"
 	svix "github.com/svix/svix-webhooks/go"
+	"github.com/tesseral-labs/tesseral/internal/acceptlanguage"
 	auditlogstore "github.com/tesseral-labs/tesseral/internal/auditlog/store"
+	auditlogexportstore "github.com/tesseral-labs/tesseral/internal/auditlogexport/store"
 	backendinterceptor "github.com/tesseral-labs/tesseral/internal/backend/authn/interceptor"
 	"github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1/backendv1connect"
 	backendservice "github.com/tesseral-labs/tesseral/internal/backend/service"
@@ -39,6 +42,7 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/dbconn"
 	defaultoauthservice "github.com/tesseral-labs/tesseral/internal/defaultoauth/service"
 	defaultoauthstore "github.com/tesseral-labs/tesseral/internal/defaultoauth/store"
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
 	frontendinterceptor "github.com/tesseral-labs/tesseral/internal/frontend/authn/interceptor"
 	"github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1/frontendv1connect"
 	frontendservice "github.com/tesseral-labs/tesseral/internal/frontend/service"
@@ -69,14 +73,18 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/secretload"
 	"github.com/tesseral-labs/tesseral/internal/slogcorrelation"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
+	webhookstore "github.com/tesseral-labs/tesseral/internal/webhooks/store"
 	"go.opentelemetry.io/contrib/bridges/otelslog"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
@@ -106,6 +114,8 @@ func main() {
 	config := struct {
 		OTELExportTraces                    bool          `conf:"otel_export_traces,noredact"`
 		OTLPTraceGRPCInsecure               bool          `conf:"otlp_trace_grpc_insecure,noredact"`
+		OTELExportMetrics                   bool          `conf:"otel_export_metrics,noredact"`
+		OTLPMetricGRPCInsecure              bool          `conf:"otlp_metric_grpc_insecure,noredact"`
 		ConsoleDomain                       string        `conf:"console_domain,noredact"`
 		AuthAppsRootDomain                  string        `conf:"auth_apps_root_domain,noredact"`
 		TesseralDNSVaultCNAMEValue          string        `conf:"tesseral_dns_vault_cname_value,noredact"`
@@ -119,6 +129,12 @@ func main() {
 		S3UserContentBucketName             string        `conf:"s3_user_content_bucket_name,noredact"`
 		S3Endpoint                          string        `conf:"s3_endpoint_resolver_url,noredact"`
 		SESEndpoint                         string        `conf:"ses_endpoint_resolver_url,noredact"`
+		STSEndpoint                         string        `conf:"sts_endpoint_resolver_url,noredact"`
+		EmailSender                         string        `conf:"email_sender,noredact"`
+		SMTPAddr                            string        `conf:"smtp_addr,noredact"`
+		SMTPUsername                        string        `conf:"smtp_username,noredact"`
+		SMTPPassword                        string        `conf:"smtp_password"`
+		EmailDirectory                      string        `conf:"email_directory,noredact"`
 		ServeAddr                           string        `conf:"serve_addr,noredact"`
 		SessionKMSKeyID                     string        `conf:"session_kms_key_id,noredact"`
 		GithubOAuthClientSecretsKMSKeyID    string        `conf:"github_oauth_client_secrets_kms_key_id,noredact"`
@@ -130,7 +146,10 @@ func main() {
 		TesseralDNSCloudflareZoneID         string        `conf:"tesseral_dns_cloudflare_zone_id,noredact"`
 		StripeAPIKey                        string        `conf:"stripe_api_key"`
 		StripePriceIDGrowthTier             string        `conf:"stripe_price_id_growth_tier,noredact"`
+		WebhookDispatcher                   string        `conf:"webhook_dispatcher,noredact"`
 		SvixApiKey                          string        `conf:"svix_api_key"`
+		WebhookSigningSecretsKMSKeyID       string        `conf:"webhook_signing_secrets_kms_key_id,noredact"`
+		AuditLogChainKeysKMSKeyID           string        `conf:"audit_log_chain_keys_kms_key_id,noredact"`
 		DefaultGoogleOAuthClientID          string        `conf:"default_google_oauth_client_id,noredact"`
 		DefaultGoogleOAuthClientSecret      string        `conf:"default_google_oauth_client_secret"`
 		DefaultGoogleOAuthRedirectURI       string        `conf:"default_google_oauth_redirect_uri,noredact"`
@@ -142,6 +161,8 @@ func main() {
 		DefaultGitHubOAuthRedirectURI       string        `conf:"default_github_oauth_redirect_uri,noredact"`
 	}{
 		PageEncodingValue: "0000000000000000000000000000000000000000000000000000000000000000",
+		WebhookDispatcher: "svix",
+		EmailSender:       "ses",
 	}
 
 	conf.Load(&config)
@@ -194,6 +215,28 @@ func main() {
 		slog.SetDefault(slog.New(slogHandler))
 	}
 
+	if config.OTELExportMetrics {
+		var exporterOpts []otlpmetricgrpc.Option
+		if config.OTLPMetricGRPCInsecure {
+			exporterOpts = append(exporterOpts, otlpmetricgrpc.WithInsecure())
+		}
+
+		exporter, err := otlpmetricgrpc.New(context.Background(), exporterOpts...)
+		if err != nil {
+			panic(fmt.Errorf("create otel metric exporter: %w", err))
+		}
+
+		meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)))
+
+		defer func() {
+			if err := meterProvider.Shutdown(context.Background()); err != nil {
+				panic(fmt.Errorf("shutdown meter provider: %w", err))
+			}
+		}()
+
+		otel.SetMeterProvider(meterProvider)
+	}
+
 	slog.Info("config", "config", conf.Redact(config))
 
 	db, err := dbconn.Open(context.Background(), config.DB)
@@ -237,11 +280,90 @@ func main() {
 		}
 	})
 
-	svixClient, err := svix.New(config.SvixApiKey, nil)
-	if err != nil {
-		panic(fmt.Errorf("create svix client: %w", err))
+	sts_ := sts.NewFromConfig(awsConfig, func(o *sts.Options) {
+		if config.STSEndpoint != "" {
+			o.BaseEndpoint = &config.STSEndpoint
+		}
+	})
+
+	var emailSender emailsender.Sender
+	switch config.EmailSender {
+	case "ses":
+		emailSender = &emailsender.SESSender{Client: ses_}
+	case "smtp":
+		emailSender = &emailsender.SMTPSender{
+			Addr:     config.SMTPAddr,
+			Username: config.SMTPUsername,
+			Password: config.SMTPPassword,
+		}
+	case "directory":
+		emailSender = &emailsender.DirectorySender{Dir: config.EmailDirectory}
+	case "stdout":
+		emailSender = &emailsender.WriterSender{W: os.Stdout}
+	default:
+		panic(fmt.Errorf("unknown email sender: %q", config.EmailSender))
+	}
+
+	var webhookDispatcher webhooks.Dispatcher
+	webhookStoreParams := webhookstore.NewStoreParams{
+		DB:                            db,
+		KMS:                           kms_,
+		WebhookSigningSecretsKMSKeyID: config.WebhookSigningSecretsKMSKeyID,
+		HTTPClient: &http.Client{
+			Transport: restrictedhttp.NewTransport(),
+		},
 	}
 
+	switch config.WebhookDispatcher {
+	case "svix":
+		svixClient, err := svix.New(config.SvixApiKey, nil)
+		if err != nil {
+			panic(fmt.Errorf("create svix client: %w", err))
+		}
+
+		webhookDispatcher = &webhooks.SvixDispatcher{Client: svixClient}
+		webhookStoreParams.Dispatcher = webhookDispatcher
+	case "builtin":
+		webhookStore := webhookstore.New(webhookStoreParams)
+
+		go func() {
+			if err := webhookStore.RunDelivery(context.Background()); err != nil {
+				panic(fmt.Errorf("run webhook delivery: %w", err))
+			}
+		}()
+
+		webhookDispatcher = webhookStore
+	default:
+		panic(fmt.Errorf("unknown webhook dispatcher: %q", config.WebhookDispatcher))
+	}
+
+	// Relay webhook messages written to the outbox by the stores below to the
+	// dispatcher.
+	webhookRelay := webhookstore.New(webhookStoreParams)
+	go func() {
+		if err := webhookRelay.RunRelay(context.Background()); err != nil {
+			panic(fmt.Errorf("run webhook relay: %w", err))
+		}
+	}()
+
+	// Export audit log events to each project's audit log export destinations.
+	auditLogExportStore := auditlogexportstore.New(auditlogexportstore.NewStoreParams{
+		DB:                            db,
+		KMS:                           kms_,
+		WebhookSigningSecretsKMSKeyID: config.WebhookSigningSecretsKMSKeyID,
+		HTTPClient: &http.Client{
+			Transport: restrictedhttp.NewTransport(),
+		},
+		S3:          s3_,
+		STS:         sts_,
+		DialContext: restrictedhttp.DialContext,
+	})
+	go func() {
+		if err := auditLogExportStore.RunExport(context.Background()); err != nil {
+			panic(fmt.Errorf("run audit log export: %w", err))
+		}
+	}()
+
 	stripeClient := stripeclient.New(config.StripeAPIKey, nil)
 
 	commonStore := commonstore.New(commonstore.NewStoreParams{
@@ -253,7 +375,10 @@ func main() {
 
 	cookier := cookies.Cookier{Store: commonStore}
 
-	auditlogStore := auditlogstore.Store{}
+	auditlogStore := auditlogstore.New(auditlogstore.NewStoreParams{
+		KMS:                       kms_,
+		AuditLogChainKeysKMSKeyID: config.AuditLogChainKeysKMSKeyID,
+	})
 	oidcClient := &oidcclient.Client{
 		HTTPClient: &http.Client{
 			Transport: restrictedhttp.NewTransport(),
@@ -268,9 +393,11 @@ func main() {
 		IntermediateSessionSigningKeyKMSKeyID: config.IntermediateSessionKMSKeyID,
 		KMS:                                   kms_,
 		SES:                                   ses_,
+		EmailSender:                           emailSender,
 		PageEncoder:                           pagetoken.Encoder{Secret: pageEncodingValue},
 		S3:                                    s3_,
 		S3UserContentBucketName:               config.S3UserContentBucketName,
+		STS:                                   sts_,
 		SessionSigningKeyKmsKeyID:             config.SessionKMSKeyID,
 		GoogleOAuthClientSecretsKMSKeyID:      config.GoogleOAuthClientSecretsKMSKeyID,
 		MicrosoftOAuthClientSecretsKMSKeyID:   config.MicrosoftOAuthClientSecretsKMSKeyID,
@@ -285,10 +412,48 @@ func main() {
 		CloudflareDOH:                         &cloudflaredoh.Client{HTTPClient: &http.Client{}},
 		Stripe:                                stripeClient,
 		StripePriceIDGrowthTier:               config.StripePriceIDGrowthTier,
-		SvixClient:                            svixClient,
-		AuditlogStore:                         &auditlogStore,
+		WebhookDispatcher:                     webhookDispatcher,
+		WebhookSigningSecretsKMSKeyID:         config.WebhookSigningSecretsKMSKeyID,
+		AuditlogStore:                         auditlogStore,
 		OIDCClient:                            oidcClient,
 	})
+
+	// Clear the previous secret tokens of rotated API Keys once their grace
+	// period ends.
+	go func() {
+		if err := backendStore.RunAPIKeyExpiry(context.Background()); err != nil {
+			panic(fmt.Errorf("run api key expiry: %w", err))
+		}
+	}()
+
+	// Verify organization domains, and re-verify them periodically.
+	go func() {
+		if err := backendStore.RunOrganizationDomainVerification(context.Background()); err != nil {
+			panic(fmt.Errorf("run organization domain verification: %w", err))
+		}
+	}()
+
+	// Delete role assignments once their expire_time passes.
+	go func() {
+		if err := backendStore.RunRoleAssignmentExpiry(context.Background()); err != nil {
+			panic(fmt.Errorf("run role assignment expiry: %w", err))
+		}
+	}()
+
+	// Give organizations created after a role template its roles.
+	go func() {
+		if err := backendStore.RunRoleTemplateSync(context.Background()); err != nil {
+			panic(fmt.Errorf("run role template sync: %w", err))
+		}
+	}()
+
+	// Archive audit log events once their retention period ends.
+	go func() {
+		if err := backendStore.RunAuditLogRetention(context.Background()); err != nil {
+			panic(fmt.Errorf("run audit log retention: %w", err))
+		}
+	}()
+
 	backendConnectPath, backendConnectHandler := backendv1connect.NewBackendServiceHandler(
 		&backendservice.Service{
 			Store: backendStore,
@@ -296,6 +461,7 @@ func main() {
 		connect.WithInterceptors(
 			opaqueinternalerror.NewInterceptor(),
 			httplog.NewInterceptor(),
+			acceptlanguage.NewInterceptor(),
 			backendinterceptor.New(backendStore, config.DogfoodProjectID),
 		),
 	)
@@ -313,12 +479,14 @@ func main() {
 		IntermediateSessionSigningKeyKMSKeyID: config.IntermediateSessionKMSKeyID,
 		OIDCClientSecretsKMSKeyID:             config.OIDCClientSecretsKMSKeyID,
 		KMS:                                   kms_,
-		SES:                                   ses_,
+		EmailSender:                           emailSender,
+		S3:                                    s3_,
+		S3UserContentBucketName:               config.S3UserContentBucketName,
+		UserContentBaseUrl:                    config.UserContentBaseUrl,
 		PageEncoder:                           pagetoken.Encoder{Secret: pageEncodingValue},
 		SessionSigningKeyKmsKeyID:             config.SessionKMSKeyID,
 		AuthenticatorAppSecretsKMSKeyID:       config.AuthenticatorAppSecretsKMSKeyID,
-		SvixClient:                            svixClient,
-		AuditlogStore:                         &auditlogStore,
+		AuditlogStore:                         auditlogStore,
 		OIDCClient:                            oidcClient,
 	})
 	frontendConnectPath, frontendConnectHandler := frontendv1connect.NewFrontendServiceHandler(
@@ -330,6 +498,7 @@ func main() {
 		connect.WithInterceptors(
 			opaqueinternalerror.NewInterceptor(),
 			httplog.NewInterceptor(),
+			acceptlanguage.NewInterceptor(),
 			frontendinterceptor.New(frontendStore, projectid.NewSniffer(config.AuthAppsRootDomain, commonStore), &cookier),
 		),
 	)
@@ -352,7 +521,7 @@ func main() {
 		GoogleOAuthClient:                     &googleoauth.Client{HTTPClient: &http.Client{}},
 		MicrosoftOAuthClient:                  &microsoftoauth.Client{HTTPClient: &http.Client{}},
 		S3:                                    s3_,
-		SES:                                   ses_,
+		EmailSender:                           emailSender,
 		SessionSigningKeyKmsKeyID:             config.SessionKMSKeyID,
 		GithubOAuthClientSecretsKMSKeyID:      config.GithubOAuthClientSecretsKMSKeyID,
 		GoogleOAuthClientSecretsKMSKeyID:      config.GoogleOAuthClientSecretsKMSKeyID,
@@ -361,8 +530,8 @@ func main() {
 		UserContentBaseUrl:                    config.UserContentBaseUrl,
 		S3UserContentBucketName:               config.S3UserContentBucketName,
 		StripeClient:                          stripeClient,
-		SvixClient:                            svixClient,
-		AuditlogStore:                         &auditlogStore,
+		WebhookDispatcher:                     webhookDispatcher,
+		AuditlogStore:                         auditlogStore,
 		DefaultGoogleOAuthClientID:            config.DefaultGoogleOAuthClientID,
 		DefaultGoogleOAuthClientSecret:        config.DefaultGoogleOAuthClientSecret,
 		DefaultGoogleOAuthRedirectURI:         config.DefaultGoogleOAuthRedirectURI,
@@ -382,6 +551,7 @@ func main() {
 		connect.WithInterceptors(
 			opaqueinternalerror.NewInterceptor(),
 			httplog.NewInterceptor(),
+			acceptlanguage.NewInterceptor(),
 			intermediateinterceptor.New(intermediateStore, projectid.NewSniffer(config.AuthAppsRootDomain, commonStore), &cookier),
 		),
 	)
@@ -393,7 +563,7 @@ func main() {
 
 	samlStore := samlstore.New(samlstore.NewStoreParams{
 		DB:            db,
-		AuditlogStore: &auditlogStore,
+		AuditlogStore: auditlogStore,
 	})
 	samlService := samlservice.Service{
 		Store:             samlStore,
@@ -408,7 +578,7 @@ func main() {
 		KMS:                       kms_,
 		OIDCClientSecretsKMSKeyID: config.OIDCClientSecretsKMSKeyID,
 		OIDCClient:                oidcClient,
-		AuditlogStore:             &auditlogStore,
+		AuditlogStore:             auditlogStore,
 	})
 	oidcService := oidcservice.Service{
 		Store:             oidcStore,
@@ -420,7 +590,7 @@ func main() {
 
 	scimStore := scimstore.New(scimstore.NewStoreParams{
 		DB:            db,
-		AuditlogStore: &auditlogStore,
+		AuditlogStore: auditlogStore,
 	})
 	scimService := scimservice.Service{
 		Store: scimStore,
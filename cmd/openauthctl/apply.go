@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
+)
+
+type planArgs struct {
+	Args          args   `cli:"plan,subcmd"`
+	BackendAPIURL string `cli:"--backend-api-url"`
+	BackendAPIKey string `cli:"--backend-api-key"`
+	File          string `cli:"--file"`
+}
+
+func (planArgs) Description() string {
+	return "Show the changes applying a project config would make"
+}
+
+func (planArgs) ExtendedDescription() string {
+	return strings.TrimSpace(`
+Show the changes that apply would make to a project, without making them.
+
+See apply for the format of --file and the meaning of the other flags.
+`)
+}
+
+func plan(ctx context.Context, args planArgs) error {
+	return applyProjectConfig(ctx, args.BackendAPIURL, args.BackendAPIKey, args.File, true)
+}
+
+type applyArgs struct {
+	Args          args   `cli:"apply,subcmd"`
+	BackendAPIURL string `cli:"--backend-api-url"`
+	BackendAPIKey string `cli:"--backend-api-key"`
+	File          string `cli:"--file"`
+}
+
+func (applyArgs) Description() string {
+	return "Make a project match a project config"
+}
+
+func (applyArgs) ExtendedDescription() string {
+	return strings.TrimSpace(`
+Make a project's configuration match a project config file, in a single
+transaction.
+
+The project is changed using the Backend API, at --backend-api-url (for
+example, https://api.tesseral.com), authenticated with --backend-api-key,
+which must be an unscoped Backend API Key. If --backend-api-key is not
+provided, the TESSERAL_BACKEND_API_KEY environment variable is used instead.
+
+--file is a YAML or JSON document in the format of the Backend API's
+ProjectConfig, for example:
+
+  project:
+    displayName: Acme
+    logInWithPassword: false
+    trustedDomains: [app.acme.com]
+    redirectUri: https://app.acme.com/callback
+  uiSettings:
+    primaryColor: "#0f172a"
+  rbac:
+    actions:
+      - name: acme.widgets.read
+        description: Read widgets
+    roles:
+      - displayName: Widget Reader
+        actions: [acme.widgets.read]
+
+Settings that are not in the file are left unchanged. If rbac is present, the
+project's actions and roles available to all organizations are replaced with
+the ones listed; roles are matched by display name.
+
+Outputs, tab-separated, one line per change: the kind of change (create,
+update, or delete), the kind of resource (project, ui_settings, action, or
+role), the name of the action or role, and for updates the changed fields.
+`)
+}
+
+func apply(ctx context.Context, args applyArgs) error {
+	return applyProjectConfig(ctx, args.BackendAPIURL, args.BackendAPIKey, args.File, false)
+}
+
+func applyProjectConfig(ctx context.Context, backendAPIURL, backendAPIKey, file string, dryRun bool) error {
+	if backendAPIKey == "" {
+		backendAPIKey = os.Getenv("TESSERAL_BACKEND_API_KEY")
+	}
+
+	projectConfig, err := readProjectConfig(file)
+	if err != nil {
+		return err
+	}
+
+	var res backendv1.ApplyProjectConfigResponse
+	if err := postBackendAPI(ctx, backendAPIURL, backendAPIKey, "/v1/project-config/apply", &backendv1.ApplyProjectConfigRequest{
+		ProjectConfig: projectConfig,
+		DryRun:        dryRun,
+	}, &res); err != nil {
+		return fmt.Errorf("apply project config: %w", err)
+	}
+
+	for _, change := range res.Changes {
+		fmt.Printf("%s\t%s\t%s\t%s\n", change.ChangeType, change.ResourceType, change.ResourceName, strings.Join(change.Fields, ","))
+	}
+
+	return nil
+}
+
+// readProjectConfig reads a project config from a YAML or JSON file.
+func readProjectConfig(file string) (*backendv1.ProjectConfig, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	// JSON is a subset of YAML, so parse both as YAML and convert to JSON for
+	// protojson
+	var v any
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("parse file: %w", err)
+	}
+
+	configJSON, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("convert file to json: %w", err)
+	}
+
+	var projectConfig backendv1.ProjectConfig
+	if err := protojson.Unmarshal(configJSON, &projectConfig); err != nil {
+		return nil, fmt.Errorf("unmarshal project config: %w", err)
+	}
+
+	return &projectConfig, nil
+}
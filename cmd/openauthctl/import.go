@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/passwordhash"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// importBatchSize is the number of users sent in each ImportUsers request.
+const importBatchSize = 1000
+
+type importArgs struct {
+	Args                  args   `cli:"import,subcmd"`
+	BackendAPIURL         string `cli:"--backend-api-url"`
+	BackendAPIKey         string `cli:"--backend-api-key"`
+	OrganizationID        string `cli:"--organization-id"`
+	Format                string `cli:"--format"`
+	File                  string `cli:"--file"`
+	FirebaseSignerKey     string `cli:"--firebase-signer-key"`
+	FirebaseSaltSeparator string `cli:"--firebase-salt-separator"`
+	FirebaseRounds        int    `cli:"--firebase-rounds"`
+	FirebaseMemCost       int    `cli:"--firebase-mem-cost"`
+}
+
+func (importArgs) Description() string {
+	return "Import users from another identity provider"
+}
+
+func (importArgs) ExtendedDescription() string {
+	return strings.TrimSpace(`
+Import users from another identity provider into an organization.
+
+Users are imported using the Backend API, at --backend-api-url (for example,
+https://api.tesseral.com), authenticated with --backend-api-key. If
+--backend-api-key is not provided, the TESSERAL_BACKEND_API_KEY environment
+variable is used instead.
+
+--format is one of:
+
+  auth0     JSON Lines, one user per line, as produced by Auth0's user export
+            or password hash export. Uses the email, name, picture, and
+            passwordHash fields.
+
+  cognito   CSV with a header row, as produced from Cognito's ListUsers. Uses
+            the email, name, and picture columns. Cognito does not export
+            password hashes; imported users will need to reset their password.
+
+  firebase  CSV, as produced by "firebase auth:export --format=csv". Password
+            hashes use Firebase's scrypt variant, and require the
+            --firebase-signer-key, --firebase-salt-separator,
+            --firebase-rounds, and --firebase-mem-cost parameters from the
+            Firebase project's password hash configuration.
+
+Imported password hashes are replaced with Tesseral's own hash the first time
+each user logs in with their password.
+
+Outputs, tab-separated, one line per user that could not be imported: the line
+number in --file, the user's email, and the reason the user was not imported.
+Exits with an error if any users could not be imported.
+`)
+}
+
+// importRow is a user parsed from an import file.
+type importRow struct {
+	line       int
+	userImport *backendv1.UserImport
+
+	// err is set if the row could not be parsed
+	err error
+}
+
+func importUsers(ctx context.Context, args importArgs) error {
+	if args.BackendAPIKey == "" {
+		args.BackendAPIKey = os.Getenv("TESSERAL_BACKEND_API_KEY")
+	}
+
+	f, err := os.Open(args.File)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	var rows []importRow
+	switch args.Format {
+	case "auth0":
+		rows, err = parseAuth0Users(f)
+	case "cognito":
+		rows, err = parseCognitoUsers(f)
+	case "firebase":
+		rows, err = parseFirebaseUsers(f, args)
+	default:
+		return fmt.Errorf("unsupported format: %q", args.Format)
+	}
+	if err != nil {
+		return fmt.Errorf("parse %s users: %w", args.Format, err)
+	}
+
+	var importedCount, failedCount int
+	for len(rows) > 0 {
+		batch := rows[:min(len(rows), importBatchSize)]
+		rows = rows[len(batch):]
+
+		var batchRows []importRow
+		req := &backendv1.ImportUsersRequest{}
+		for _, row := range batch {
+			if row.err != nil {
+				fmt.Printf("%d\t%s\t%s\n", row.line, row.userImport.GetUser().GetEmail(), row.err)
+				failedCount++
+				continue
+			}
+
+			row.userImport.User.OrganizationId = args.OrganizationID
+			batchRows = append(batchRows, row)
+			req.Users = append(req.Users, row.userImport)
+		}
+
+		if len(req.Users) == 0 {
+			continue
+		}
+
+		res, err := postImportUsers(ctx, args, req)
+		if err != nil {
+			return fmt.Errorf("import users: %w", err)
+		}
+
+		for i, result := range res.Results {
+			if result.Error != "" {
+				fmt.Printf("%d\t%s\t%s\n", batchRows[i].line, batchRows[i].userImport.User.Email, result.Error)
+				failedCount++
+				continue
+			}
+
+			importedCount++
+		}
+	}
+
+	if failedCount > 0 {
+		return fmt.Errorf("failed to import %d users; imported %d users", failedCount, importedCount)
+	}
+
+	return nil
+}
+
+func postImportUsers(ctx context.Context, args importArgs, req *backendv1.ImportUsersRequest) (*backendv1.ImportUsersResponse, error) {
+	var res backendv1.ImportUsersResponse
+	if err := postBackendAPI(ctx, args.BackendAPIURL, args.BackendAPIKey, "/v1/users/import", req, &res); err != nil {
+		return nil, err
+	}
+
+	if len(res.Results) != len(req.Users) {
+		return nil, fmt.Errorf("expected %d results, got %d", len(req.Users), len(res.Results))
+	}
+
+	return &res, nil
+}
+
+// postBackendAPI sends req as JSON to path on the Backend API, and unmarshals
+// the response into res.
+func postBackendAPI(ctx context.Context, backendAPIURL, backendAPIKey, path string, req, res proto.Message) error {
+	body, err := protojson.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(backendAPIURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", backendAPIKey))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpRes, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer httpRes.Body.Close()
+
+	resBody, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+
+	if httpRes.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad response status: %d: %s", httpRes.StatusCode, resBody)
+	}
+
+	if err := protojson.Unmarshal(resBody, res); err != nil {
+		return fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+func parseAuth0Users(r io.Reader) ([]importRow, error) {
+	var rows []importRow
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var auth0User struct {
+			Email        string `json:"email"`
+			Name         string `json:"name"`
+			Picture      string `json:"picture"`
+			PasswordHash string `json:"passwordHash"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &auth0User); err != nil {
+			rows = append(rows, importRow{line: line, err: fmt.Errorf("parse json: %w", err)})
+			continue
+		}
+
+		rows = append(rows, newImportRow(line, auth0User.Email, auth0User.Name, auth0User.Picture, "", auth0User.PasswordHash))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read lines: %w", err)
+	}
+
+	return rows, nil
+}
+
+func parseCognitoUsers(r io.Reader) ([]importRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[name] = i
+	}
+
+	if _, ok := columns["email"]; !ok {
+		return nil, fmt.Errorf("missing email column")
+	}
+
+	column := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	var rows []importRow
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read line %d: %w", line, err)
+		}
+
+		rows = append(rows, newImportRow(line, column(record, "email"), column(record, "name"), column(record, "picture"), "", ""))
+	}
+
+	return rows, nil
+}
+
+func parseFirebaseUsers(r io.Reader, args importArgs) ([]importRow, error) {
+	signerKey, err := base64.StdEncoding.DecodeString(args.FirebaseSignerKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode firebase signer key: %w", err)
+	}
+
+	saltSeparator, err := base64.StdEncoding.DecodeString(args.FirebaseSaltSeparator)
+	if err != nil {
+		return nil, fmt.Errorf("decode firebase salt separator: %w", err)
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	var rows []importRow
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read line %d: %w", line, err)
+		}
+
+		// Firebase's CSV columns are, in order: UID, Email, Email Verified,
+		// Password Hash, Password Salt, Name, Photo URL, Google ID, and then
+		// columns for other providers.
+		if len(record) < 8 {
+			rows = append(rows, importRow{line: line, err: fmt.Errorf("expected at least 8 columns, got %d", len(record))})
+			continue
+		}
+
+		var passwordHash string
+		if record[3] != "" {
+			hash, err := base64.StdEncoding.DecodeString(record[3])
+			if err != nil {
+				rows = append(rows, importRow{line: line, err: fmt.Errorf("decode password hash: %w", err)})
+				continue
+			}
+
+			salt, err := base64.StdEncoding.DecodeString(record[4])
+			if err != nil {
+				rows = append(rows, importRow{line: line, err: fmt.Errorf("decode password salt: %w", err)})
+				continue
+			}
+
+			passwordHash = passwordhash.FormatFirebaseScrypt(args.FirebaseRounds, args.FirebaseMemCost, salt, saltSeparator, signerKey, hash)
+		}
+
+		rows = append(rows, newImportRow(line, record[1], record[5], record[6], record[7], passwordHash))
+	}
+
+	return rows, nil
+}
+
+func newImportRow(line int, email, name, picture, googleUserID, passwordHash string) importRow {
+	user := &backendv1.User{
+		Email:             email,
+		DisplayName:       refOrNil(name),
+		ProfilePictureUrl: refOrNil(picture),
+		GoogleUserId:      refOrNil(googleUserID),
+	}
+
+	row := importRow{
+		line: line,
+		userImport: &backendv1.UserImport{
+			User:         user,
+			PasswordHash: passwordHash,
+		},
+	}
+
+	if email == "" {
+		row.err = fmt.Errorf("missing email")
+	}
+
+	return row
+}
+
+func refOrNil[T comparable](t T) *T {
+	var z T
+	if t == z {
+		return nil
+	}
+	return &t
+}
@@ -8,7 +8,7 @@ import (
 )
 
 func main() {
-	cli.Run(context.Background(), version, force, up, bootstrap)
+	cli.Run(context.Background(), version, force, up, bootstrap, verifyAuditLogChain, importUsers, exportProject, importProject, plan, apply)
 }
 
 type args struct {
@@ -0,0 +1,44 @@
+create table webhook_endpoints (
+    id uuid not null primary key,
+    project_id uuid not null references projects (id) on delete cascade,
+    url varchar not null,
+    description varchar,
+    event_types varchar[] not null default '{}',
+    disabled boolean not null default false,
+    signing_secret_ciphertext bytea not null,
+    create_time timestamp with time zone not null default now(),
+    update_time timestamp with time zone not null default now()
+);
+
+create index on webhook_endpoints (project_id, id);
+
+create table webhook_messages (
+    id uuid not null primary key,
+    project_id uuid not null references projects (id) on delete cascade,
+    event_type varchar not null,
+    payload jsonb not null,
+    create_time timestamp with time zone not null default now()
+);
+
+create type webhook_delivery_status as enum (
+    'pending',
+    'succeeded',
+    'failed'
+);
+
+create table webhook_deliveries (
+    id uuid not null primary key,
+    webhook_message_id uuid not null references webhook_messages (id) on delete cascade,
+    webhook_endpoint_id uuid not null references webhook_endpoints (id) on delete cascade,
+    status webhook_delivery_status not null default 'pending',
+    attempt_count integer not null default 0,
+    next_attempt_time timestamp with time zone,
+    last_attempt_time timestamp with time zone,
+    last_response_status_code integer,
+    last_error varchar,
+    create_time timestamp with time zone not null default now(),
+    update_time timestamp with time zone not null default now()
+);
+
+create index on webhook_deliveries (next_attempt_time) where status = 'pending';
+create index on webhook_deliveries (webhook_endpoint_id, id desc);
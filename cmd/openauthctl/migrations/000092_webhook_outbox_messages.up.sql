@@ -0,0 +1,12 @@
+create table webhook_outbox_messages (
+    id uuid not null primary key,
+    project_id uuid not null references projects (id) on delete cascade,
+    event_type varchar not null,
+    payload jsonb not null,
+    attempt_count integer not null default 0,
+    next_attempt_time timestamp with time zone not null default now(),
+    last_error varchar,
+    create_time timestamp with time zone not null default now()
+);
+
+create index on webhook_outbox_messages (next_attempt_time);
@@ -0,0 +1,21 @@
+create type email_template_type as enum (
+    'email_verification',
+    'password_reset',
+    'user_invite'
+);
+
+create table email_templates (
+    id uuid not null primary key,
+    project_id uuid not null references projects (id) on delete cascade,
+    template_type email_template_type not null,
+    locale varchar,
+    subject varchar not null,
+    text_body varchar not null,
+    html_body varchar not null,
+    create_time timestamp with time zone not null default now(),
+    update_time timestamp with time zone not null default now()
+);
+
+-- At most one template per type and locale, counting the locale-less
+-- template as its own locale.
+create unique index on email_templates (project_id, template_type, coalesce(locale, ''));
@@ -0,0 +1,15 @@
+alter table api_keys
+    add column last_used_time timestamp with time zone,
+    add column last_used_ip_address varchar,
+    add column ip_allowlist varchar[] not null default '{}',
+    add column request_quota int,
+    add column request_quota_window_seconds int;
+
+create table api_key_request_quota_usage
+(
+    api_key_id    uuid                     not null references api_keys (id) on delete cascade,
+    window_start  timestamp with time zone not null,
+    request_count int                      not null,
+
+    primary key (api_key_id, window_start)
+);
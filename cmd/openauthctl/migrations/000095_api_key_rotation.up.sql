@@ -0,0 +1,7 @@
+alter table api_keys
+    add column previous_secret_token_sha256 bytea,
+    add column previous_secret_token_suffix varchar,
+    add column previous_secret_token_expire_time timestamp with time zone;
+
+create unique index on api_keys (previous_secret_token_sha256);
+create index on api_keys (previous_secret_token_expire_time) where previous_secret_token_expire_time is not null;
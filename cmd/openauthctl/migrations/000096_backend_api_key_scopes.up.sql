@@ -0,0 +1,3 @@
+alter table backend_api_keys
+    add column scopes varchar[] not null default '{}',
+    add column organization_ids uuid[] not null default '{}';
@@ -0,0 +1,17 @@
+create table role_inherited_roles
+(
+    role_id           uuid not null references roles (id) on delete cascade,
+    inherited_role_id uuid not null references roles (id) on delete cascade,
+
+    primary key (role_id, inherited_role_id),
+    check (role_id <> inherited_role_id)
+);
+
+alter table user_role_assignments
+    add column resource_type varchar,
+    add column resource_id   varchar,
+    add constraint user_role_assignments_resource_check check ((resource_type is null) = (resource_id is null)),
+    drop constraint user_role_assignments_role_id_user_id_key,
+    add constraint user_role_assignments_role_id_user_id_resource_key unique nulls not distinct (role_id, user_id, resource_type, resource_id);
+
+create index on user_role_assignments (user_id, resource_type, resource_id);
@@ -0,0 +1,32 @@
+alter table user_role_assignments
+    add column expire_time timestamp with time zone;
+
+alter table api_key_role_assignments
+    add column expire_time timestamp with time zone;
+
+create index on user_role_assignments (expire_time) where expire_time is not null;
+create index on api_key_role_assignments (expire_time) where expire_time is not null;
+
+alter table roles
+    add column requestable boolean not null default false;
+
+create type access_request_status as enum (
+    'pending',
+    'approved',
+    'denied'
+);
+
+create table access_requests (
+    id uuid not null primary key,
+    user_id uuid not null references users (id) on delete cascade,
+    role_id uuid not null references roles (id) on delete cascade,
+    reason varchar not null,
+    duration_seconds integer not null,
+    status access_request_status not null default 'pending',
+    reviewer_user_id uuid references users (id) on delete set null,
+    review_time timestamp with time zone,
+    create_time timestamp with time zone not null default now(),
+    update_time timestamp with time zone not null default now()
+);
+
+create unique index on access_requests (user_id, role_id) where status = 'pending';
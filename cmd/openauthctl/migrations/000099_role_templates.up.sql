@@ -0,0 +1,25 @@
+create table role_templates (
+    id uuid not null primary key,
+    project_id uuid not null references projects (id) on delete cascade,
+    create_time timestamp with time zone not null default now(),
+    update_time timestamp with time zone not null default now(),
+    display_name varchar not null,
+    description varchar not null,
+    version integer not null default 1
+);
+
+create table role_template_actions (
+    id uuid not null primary key,
+    role_template_id uuid not null references role_templates (id) on delete cascade,
+    action_id uuid not null references actions (id) on delete cascade,
+
+    unique (role_template_id, action_id)
+);
+
+alter table roles
+    add column role_template_id uuid references role_templates (id) on delete cascade,
+    add column role_template_version integer;
+
+create unique index on roles (role_template_id, organization_id) where role_template_id is not null;
+
+alter type audit_log_event_resource_type add value 'role_template';
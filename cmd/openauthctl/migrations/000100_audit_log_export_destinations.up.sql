@@ -0,0 +1,35 @@
+create type audit_log_export_destination_type as enum (
+    'https',
+    's3',
+    'syslog'
+);
+
+create table audit_log_export_destinations (
+    id uuid not null primary key,
+    project_id uuid not null references projects (id) on delete cascade,
+    organization_id uuid references organizations (id) on delete cascade,
+    display_name varchar not null,
+    type audit_log_export_destination_type not null,
+    disabled boolean not null default false,
+    https_url varchar,
+    signing_secret_ciphertext bytea,
+    s3_bucket varchar,
+    s3_prefix varchar,
+    syslog_network varchar,
+    syslog_address varchar,
+    cursor_audit_log_event_id uuid,
+    next_attempt_time timestamp with time zone not null default now(),
+    last_attempt_time timestamp with time zone,
+    last_success_time timestamp with time zone,
+    last_error varchar,
+    consecutive_failure_count integer not null default 0,
+    create_time timestamp with time zone not null default now(),
+    update_time timestamp with time zone not null default now(),
+
+    check ((type = 'https') = (https_url is not null and signing_secret_ciphertext is not null)),
+    check ((type = 's3') = (s3_bucket is not null)),
+    check ((type = 'syslog') = (syslog_network is not null and syslog_address is not null))
+);
+
+create index on audit_log_export_destinations (project_id, id);
+create index on audit_log_export_destinations (next_attempt_time) where not disabled;
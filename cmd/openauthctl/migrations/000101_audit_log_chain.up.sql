@@ -0,0 +1,15 @@
+-- key_ciphertext is the project's chain key, encrypted with kms. chain hashes
+-- are keyed with it, so that they can't be recomputed by anyone who can only
+-- write to the database.
+create table audit_log_chain_heads (
+    project_id uuid not null primary key references projects (id) on delete cascade,
+    sequence bigint not null default 0,
+    hash bytea not null default ''::bytea,
+    update_time timestamp with time zone not null default now(),
+    key_ciphertext bytea
+);
+
+alter table audit_log_events add column chain_sequence bigint;
+alter table audit_log_events add column chain_hash bytea;
+
+create unique index on audit_log_events (project_id, chain_sequence) where chain_sequence is not null;
@@ -0,0 +1,39 @@
+alter table projects add column audit_log_retention_days integer check (audit_log_retention_days > 0);
+alter table projects add column entitled_audit_log_retention_days integer check (entitled_audit_log_retention_days > 0);
+alter table projects add column audit_log_archive_s3_bucket varchar;
+
+alter table organizations add column audit_log_retention_days integer check (audit_log_retention_days > 0);
+
+create table audit_log_archives (
+    id uuid not null primary key,
+    project_id uuid not null references projects(id) on delete cascade,
+    organization_id uuid,
+    s3_bucket varchar not null,
+    s3_key varchar not null,
+    event_count integer not null,
+    first_event_time timestamp with time zone not null,
+    last_event_time timestamp with time zone not null,
+    create_time timestamp with time zone not null default now(),
+    restore_time timestamp with time zone
+);
+
+create index on audit_log_archives (project_id, id desc);
+create index on audit_log_archives (project_id, organization_id, id desc) where organization_id is not null;
+
+-- audit_log_archived_chain_links keeps the chain position of archived events,
+-- so the audit log chain can still be verified after they are deleted.
+create table audit_log_archived_chain_links (
+    project_id uuid not null references projects(id) on delete cascade,
+    chain_sequence bigint not null,
+    chain_hash bytea not null,
+    audit_log_archive_id uuid not null references audit_log_archives(id) on delete cascade,
+
+    primary key (project_id, chain_sequence)
+);
+
+create index on audit_log_archived_chain_links (audit_log_archive_id);
+
+-- Restored events are on legal hold, and are not archived again.
+alter table audit_log_events add column restored_from_audit_log_archive_id uuid references audit_log_archives(id) on delete set null;
+
+create index on audit_log_events (project_id, event_time);
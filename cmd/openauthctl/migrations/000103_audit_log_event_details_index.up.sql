@@ -0,0 +1 @@
+create index on audit_log_events using gin (event_details jsonb_path_ops);
@@ -0,0 +1,81 @@
+-- identities are the people behind users. A user is an identity's membership
+-- in an organization; credentials belong to the identity, and so are shared
+-- across all of its users.
+create table identities
+(
+    id                                      uuid                     not null primary key,
+    project_id                              uuid                     not null references projects (id) on delete cascade,
+    email                                   varchar                  not null,
+    create_time                             timestamp with time zone not null default now(),
+    update_time                             timestamp with time zone not null default now(),
+    password_bcrypt                         varchar,
+    failed_password_attempts                integer                  not null default 0,
+    password_lockout_expire_time            timestamp with time zone,
+    authenticator_app_secret_ciphertext     bytea,
+    failed_authenticator_app_attempts       integer                  not null default 0,
+    authenticator_app_lockout_expire_time   timestamp with time zone,
+    authenticator_app_recovery_code_sha256s bytea[]
+);
+
+create index on identities (project_id, email);
+
+-- Each existing user gets an identity of their own, with their own
+-- credentials. Users with the same email in different organizations were
+-- not required to prove they are the same person, so their credentials are
+-- not shared.
+alter table users
+    add column identity_id uuid;
+
+update users
+set identity_id = gen_random_uuid();
+
+insert into identities (id, project_id, email, password_bcrypt, failed_password_attempts, password_lockout_expire_time,
+                        authenticator_app_secret_ciphertext, failed_authenticator_app_attempts,
+                        authenticator_app_lockout_expire_time, authenticator_app_recovery_code_sha256s)
+select users.identity_id,
+       organizations.project_id,
+       users.email,
+       users.password_bcrypt,
+       users.failed_password_attempts,
+       users.password_lockout_expire_time,
+       users.authenticator_app_secret_ciphertext,
+       users.failed_authenticator_app_attempts,
+       users.authenticator_app_lockout_expire_time,
+       users.authenticator_app_recovery_code_sha256s
+from users
+    join organizations on users.organization_id = organizations.id;
+
+alter table users
+    add foreign key (identity_id) references identities (id),
+    alter column identity_id set not null;
+
+create index on users (identity_id);
+
+alter table users
+    drop column password_bcrypt,
+    drop column failed_password_attempts,
+    drop column password_lockout_expire_time,
+    drop column authenticator_app_secret_ciphertext,
+    drop column failed_authenticator_app_attempts,
+    drop column authenticator_app_lockout_expire_time,
+    drop column authenticator_app_recovery_code_sha256s;
+
+-- passkeys belong to identities; user_id records the user that registered the
+-- passkey, if it still exists.
+alter table passkeys
+    add column identity_id uuid references identities (id) on delete cascade;
+
+update passkeys
+set identity_id = users.identity_id
+from users
+where passkeys.user_id = users.id;
+
+alter table passkeys
+    alter column identity_id set not null,
+    alter column user_id drop not null;
+
+create index on passkeys (identity_id);
+
+alter table passkeys drop constraint passkeys_user_id_fkey;
+alter table passkeys add constraint passkeys_user_id_fkey
+    foreign key (user_id) references users (id) on delete set null;
@@ -0,0 +1,10 @@
+alter table organizations
+    add column parent_organization_id uuid references organizations (id),
+    add constraint organizations_parent_organization_id_check check (parent_organization_id <> id);
+
+create index on organizations (parent_organization_id);
+
+-- a user role assignment that inherits to descendants also applies to the
+-- users with the same identity in the descendants of the user's organization
+alter table user_role_assignments
+    add column inherit_to_descendants boolean not null default false;
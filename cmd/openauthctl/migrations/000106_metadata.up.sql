@@ -0,0 +1,9 @@
+-- public metadata is readable from the frontend api and embedded in access
+-- tokens; private metadata is only ever readable from the backend api
+alter table organizations
+    add column public_metadata  jsonb not null default '{}',
+    add column private_metadata jsonb not null default '{}';
+
+alter table users
+    add column public_metadata  jsonb not null default '{}',
+    add column private_metadata jsonb not null default '{}';
@@ -0,0 +1,20 @@
+-- organization domains gate saml, oidc, and scim email matching, so they must
+-- be verified with a dns txt record before they are used.
+--
+-- verify_time is the last time the txt record was found. verified domains are
+-- periodically re-checked, and lose their verification if the record has been
+-- missing for a while.
+alter table organization_domains
+    add column create_time     timestamp with time zone not null default now(),
+    add column verified        boolean                  not null default false,
+    add column verify_time     timestamp with time zone,
+    add column last_check_time timestamp with time zone;
+
+create index on organization_domains (last_check_time);
+
+-- existing domains were accepted without verification; treat them as verified
+-- as of now, so that their owners have the re-verification grace period to add
+-- the txt record before logins through them stop working
+update organization_domains
+set verified    = true,
+    verify_time = now();
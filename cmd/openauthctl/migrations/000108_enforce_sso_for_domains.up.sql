@@ -0,0 +1,4 @@
+-- when set, users whose email is at one of the organization's verified domains
+-- may only log in to the organization with saml or oidc
+alter table organizations
+    add column enforce_sso_for_domains boolean not null default false;
@@ -0,0 +1,5 @@
+-- password hashes imported from other providers, in a non-bcrypt format. These
+-- are replaced with a bcrypt hash the next time the identity logs in with its
+-- password.
+alter table identities
+    add column imported_password_hash varchar;
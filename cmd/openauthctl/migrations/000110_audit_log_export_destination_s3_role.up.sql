@@ -0,0 +1,5 @@
+-- the IAM role tesseral assumes to write to an s3 destination's bucket. s3
+-- destinations created before this column existed have no role, and fail to
+-- export until one is set.
+alter table audit_log_export_destinations
+    add column s3_role_arn varchar;
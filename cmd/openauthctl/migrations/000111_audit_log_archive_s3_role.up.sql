@@ -0,0 +1,7 @@
+-- the IAM role tesseral assumes to write archives to a project's own s3 bucket,
+-- and to read them back when they are restored.
+alter table projects
+    add column audit_log_archive_s3_role_arn varchar;
+
+alter table audit_log_archives
+    add column s3_role_arn varchar;
@@ -0,0 +1,20 @@
+-- a user only joins an existing identity once it proves ownership of it, by
+-- the identity's credentials or an email verification code. users provisioned
+-- without such proof, such as by an organization's saml or oidc identity
+-- provider or by scim, get an identity of their own. only identities with a
+-- verified email are matched by email, and there is at most one of those per
+-- email in each project.
+--
+-- existing identities were created without any such proof, so they start out
+-- unverified. their users link to a verified identity by proving ownership of
+-- it.
+alter table identities
+    add column email_verified boolean not null default false;
+
+create unique index identities_project_id_email_verified_key on identities (project_id, email)
+    where email_verified;
+
+-- the identity whose credentials an intermediate session has verified, and
+-- so which the session's user must have.
+alter table intermediate_sessions
+    add column verified_identity_id uuid references identities (id) on delete cascade;
@@ -0,0 +1,2 @@
+-- changes to a project's webhook endpoints are recorded in its audit log.
+alter type audit_log_event_resource_type add value 'webhook_endpoint';
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tesseral-labs/tesseral/internal/projectexport"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+type exportProjectArgs struct {
+	Args                                args   `cli:"export-project,subcmd"`
+	Database                            string `cli:"--database"`
+	ProjectID                           string `cli:"--project-id"`
+	File                                string `cli:"--file"`
+	IncludeCredentials                  bool   `cli:"--include-credentials"`
+	KMSEndpoint                         string `cli:"--kms-endpoint"`
+	SessionKMSKeyID                     string `cli:"--session-kms-key-id"`
+	GoogleOAuthClientSecretsKMSKeyID    string `cli:"--google-oauth-client-secrets-kms-key-id"`
+	MicrosoftOAuthClientSecretsKMSKeyID string `cli:"--microsoft-oauth-client-secrets-kms-key-id"`
+	GithubOAuthClientSecretsKMSKeyID    string `cli:"--github-oauth-client-secrets-kms-key-id"`
+	OIDCClientSecretsKMSKeyID           string `cli:"--oidc-client-secrets-kms-key-id"`
+	AuthenticatorAppSecretsKMSKeyID     string `cli:"--authenticator-app-secrets-kms-key-id"`
+}
+
+func (exportProjectArgs) Description() string {
+	return "Export a project to a file"
+}
+
+func (exportProjectArgs) ExtendedDescription() string {
+	return strings.TrimSpace(`
+Export a project to a file, for import into another Tesseral database with
+import-project.
+
+Exports include the project and its settings, UI settings, email templates,
+session signing keys, organizations and their domains, SAML and OIDC
+connections, roles and actions, and users. Password hashes, authenticator apps,
+and passkeys are only included with --include-credentials.
+
+Secrets are decrypted using the --*-kms-key-id flags, which must match the
+KMS keys the database's secrets are encrypted with. The exported file contains
+those secrets in plaintext; handle it accordingly.
+`)
+}
+
+func exportProject(ctx context.Context, args exportProjectArgs) error {
+	projectID, err := idformat.Project.Parse(args.ProjectID)
+	if err != nil {
+		return fmt.Errorf("parse project id: %w", err)
+	}
+
+	db, err := pgxpool.New(ctx, args.Database)
+	if err != nil {
+		return fmt.Errorf("connect to database: %w", err)
+	}
+	defer db.Close()
+
+	kms_, err := newKMSClient(ctx, args.KMSEndpoint)
+	if err != nil {
+		return err
+	}
+
+	export, err := projectexport.ExportProject(ctx, projectexport.ExportProjectParams{
+		DB:  db,
+		KMS: kms_,
+		KMSKeyIDs: projectexport.KMSKeyIDs{
+			Session:                     args.SessionKMSKeyID,
+			GoogleOAuthClientSecrets:    args.GoogleOAuthClientSecretsKMSKeyID,
+			MicrosoftOAuthClientSecrets: args.MicrosoftOAuthClientSecretsKMSKeyID,
+			GithubOAuthClientSecrets:    args.GithubOAuthClientSecretsKMSKeyID,
+			OIDCClientSecrets:           args.OIDCClientSecretsKMSKeyID,
+			AuthenticatorAppSecrets:     args.AuthenticatorAppSecretsKMSKeyID,
+		},
+		ProjectID:          projectID,
+		IncludeCredentials: args.IncludeCredentials,
+	})
+	if err != nil {
+		return fmt.Errorf("export project: %w", err)
+	}
+
+	exportJSON, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal export: %w", err)
+	}
+
+	if err := os.WriteFile(args.File, exportJSON, 0600); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
+
+type importProjectArgs struct {
+	Args                                args   `cli:"import-project,subcmd"`
+	Database                            string `cli:"--database"`
+	File                                string `cli:"--file"`
+	OwnerOrganizationID                 string `cli:"--owner-organization-id"`
+	DryRun                              bool   `cli:"--dry-run"`
+	KMSEndpoint                         string `cli:"--kms-endpoint"`
+	SessionKMSKeyID                     string `cli:"--session-kms-key-id"`
+	GoogleOAuthClientSecretsKMSKeyID    string `cli:"--google-oauth-client-secrets-kms-key-id"`
+	MicrosoftOAuthClientSecretsKMSKeyID string `cli:"--microsoft-oauth-client-secrets-kms-key-id"`
+	GithubOAuthClientSecretsKMSKeyID    string `cli:"--github-oauth-client-secrets-kms-key-id"`
+	OIDCClientSecretsKMSKeyID           string `cli:"--oidc-client-secrets-kms-key-id"`
+	AuthenticatorAppSecretsKMSKeyID     string `cli:"--authenticator-app-secrets-kms-key-id"`
+}
+
+func (importProjectArgs) Description() string {
+	return "Import a project from a file"
+}
+
+func (importProjectArgs) ExtendedDescription() string {
+	return strings.TrimSpace(`
+Import a project from a file created by export-project.
+
+The database must be migrated to the same schema version as the database the
+project was exported from.
+
+If the project does not exist in the database, it is created. Otherwise, it is
+updated to match the export: rows missing from the export are deleted. If the
+export does not include credentials, existing credentials are left unchanged.
+
+Secrets are encrypted using the --*-kms-key-id flags, which must match the KMS
+keys the database's secrets are encrypted with.
+
+--owner-organization-id is the organization in this database's dogfood project
+that owns the project. It is only used if the project is created.
+
+Outputs, tab-separated, one line per change: the kind of change (create,
+update, or delete), the table, the row's ID, and for updates the changed
+columns. With --dry-run, outputs the changes without making them.
+`)
+}
+
+func importProject(ctx context.Context, args importProjectArgs) error {
+	exportJSON, err := os.ReadFile(args.File)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	var export projectexport.Export
+	if err := json.Unmarshal(exportJSON, &export); err != nil {
+		return fmt.Errorf("unmarshal export: %w", err)
+	}
+
+	var ownerOrganizationID *uuid.UUID
+	if args.OwnerOrganizationID != "" {
+		orgID, err := idformat.Organization.Parse(args.OwnerOrganizationID)
+		if err != nil {
+			return fmt.Errorf("parse owner organization id: %w", err)
+		}
+
+		id := uuid.UUID(orgID)
+		ownerOrganizationID = &id
+	}
+
+	db, err := pgxpool.New(ctx, args.Database)
+	if err != nil {
+		return fmt.Errorf("connect to database: %w", err)
+	}
+	defer db.Close()
+
+	kms_, err := newKMSClient(ctx, args.KMSEndpoint)
+	if err != nil {
+		return err
+	}
+
+	changes, err := projectexport.ImportProject(ctx, projectexport.ImportProjectParams{
+		DB:  db,
+		KMS: kms_,
+		KMSKeyIDs: projectexport.KMSKeyIDs{
+			Session:                     args.SessionKMSKeyID,
+			GoogleOAuthClientSecrets:    args.GoogleOAuthClientSecretsKMSKeyID,
+			MicrosoftOAuthClientSecrets: args.MicrosoftOAuthClientSecretsKMSKeyID,
+			GithubOAuthClientSecrets:    args.GithubOAuthClientSecretsKMSKeyID,
+			OIDCClientSecrets:           args.OIDCClientSecretsKMSKeyID,
+			AuthenticatorAppSecrets:     args.AuthenticatorAppSecretsKMSKeyID,
+		},
+		Export:              &export,
+		OwnerOrganizationID: ownerOrganizationID,
+		DryRun:              args.DryRun,
+	})
+	if err != nil {
+		return fmt.Errorf("import project: %w", err)
+	}
+
+	for _, change := range changes {
+		fmt.Printf("%s\t%s\t%s\t%s\n", change.Type, change.Table, change.Key, strings.Join(change.Columns, ","))
+	}
+
+	return nil
+}
+
+func newKMSClient(ctx context.Context, endpoint string) (*kms.Client, error) {
+	awsConf, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load aws config: %w", err)
+	}
+
+	return kms.NewFromConfig(awsConf, func(o *kms.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = &endpoint
+		}
+	}), nil
+}
@@ -0,0 +1,30 @@
+// Package acceptlanguage makes a request's Accept-Language header available to
+// stores, which use it to localize the emails they send.
+package acceptlanguage
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+)
+
+type ctxKey struct{}
+
+func NewInterceptor() connect.Interceptor {
+	return connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			return next(NewContext(ctx, req.Header().Get("Accept-Language")), req)
+		}
+	})
+}
+
+func NewContext(ctx context.Context, acceptLanguage string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, acceptLanguage)
+}
+
+// FromContext returns the Accept-Language header of the current request, or
+// the empty string if there is none.
+func FromContext(ctx context.Context) string {
+	v, _ := ctx.Value(ctxKey{}).(string)
+	return v
+}
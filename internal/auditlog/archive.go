@@ -0,0 +1,100 @@
+package auditlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchivedEvent is an audit log event as stored in an audit log archive.
+//
+// Archives hold every column of the event, including its chain position, so
+// that restored events are identical to the originals and still verify
+// against the chain.
+type ArchivedEvent struct {
+	ID                         uuid.UUID       `json:"id"`
+	ProjectID                  uuid.UUID       `json:"projectId"`
+	OrganizationID             *uuid.UUID      `json:"organizationId,omitempty"`
+	ActorUserID                *uuid.UUID      `json:"actorUserId,omitempty"`
+	ActorSessionID             *uuid.UUID      `json:"actorSessionId,omitempty"`
+	ActorAPIKeyID              *uuid.UUID      `json:"actorApiKeyId,omitempty"`
+	ActorConsoleUserID         *uuid.UUID      `json:"actorConsoleUserId,omitempty"`
+	ActorConsoleSessionID      *uuid.UUID      `json:"actorConsoleSessionId,omitempty"`
+	ActorBackendAPIKeyID       *uuid.UUID      `json:"actorBackendApiKeyId,omitempty"`
+	ActorIntermediateSessionID *uuid.UUID      `json:"actorIntermediateSessionId,omitempty"`
+	ActorSCIMAPIKeyID          *uuid.UUID      `json:"actorScimApiKeyId,omitempty"`
+	ResourceType               *string         `json:"resourceType,omitempty"`
+	ResourceID                 *uuid.UUID      `json:"resourceId,omitempty"`
+	EventName                  string          `json:"eventName"`
+	EventTime                  time.Time       `json:"eventTime"`
+	EventDetails               json.RawMessage `json:"eventDetails"`
+	ChainSequence              *int64          `json:"chainSequence,omitempty"`
+	ChainHash                  []byte          `json:"chainHash,omitempty"`
+}
+
+// ChainEvent returns the content of the event covered by its chain hash.
+func (e ArchivedEvent) ChainEvent() ChainEvent {
+	return ChainEvent{
+		ID:                         e.ID,
+		ProjectID:                  e.ProjectID,
+		OrganizationID:             e.OrganizationID,
+		ActorUserID:                e.ActorUserID,
+		ActorSessionID:             e.ActorSessionID,
+		ActorAPIKeyID:              e.ActorAPIKeyID,
+		ActorConsoleUserID:         e.ActorConsoleUserID,
+		ActorConsoleSessionID:      e.ActorConsoleSessionID,
+		ActorBackendAPIKeyID:       e.ActorBackendAPIKeyID,
+		ActorIntermediateSessionID: e.ActorIntermediateSessionID,
+		ActorSCIMAPIKeyID:          e.ActorSCIMAPIKeyID,
+		ResourceType:               e.ResourceType,
+		ResourceID:                 e.ResourceID,
+		EventName:                  e.EventName,
+		EventTime:                  e.EventTime,
+		EventDetails:               e.EventDetails,
+	}
+}
+
+// MarshalArchive encodes events as gzip-compressed newline-delimited JSON.
+func MarshalArchive(events []ArchivedEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(w)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return nil, fmt.Errorf("encode event: %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("close gzip writer: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalArchive decodes events encoded by MarshalArchive.
+func UnmarshalArchive(data []byte) ([]ArchivedEvent, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("create gzip reader: %w", err)
+	}
+	defer r.Close()
+
+	var events []ArchivedEvent
+	dec := json.NewDecoder(r)
+	for {
+		var event ArchivedEvent
+		if err := dec.Decode(&event); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("decode event: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
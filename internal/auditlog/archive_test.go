@@ -0,0 +1,52 @@
+package auditlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive_RoundTrip(t *testing.T) {
+	event := testChainEvent()
+	sequence := int64(7)
+	hash, err := ChainHash(testChainKey, []byte("previous"), sequence, event)
+	require.NoError(t, err)
+
+	events := []ArchivedEvent{
+		{
+			ID:            event.ID,
+			ProjectID:     event.ProjectID,
+			EventName:     event.EventName,
+			EventTime:     event.EventTime,
+			EventDetails:  event.EventDetails,
+			ChainSequence: &sequence,
+			ChainHash:     hash,
+		},
+		{
+			ID:           event.ID,
+			ProjectID:    event.ProjectID,
+			EventName:    "tesseral.users.delete",
+			EventTime:    event.EventTime,
+			EventDetails: []byte(`{}`),
+		},
+	}
+
+	data, err := MarshalArchive(events)
+	require.NoError(t, err)
+
+	got, err := UnmarshalArchive(data)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, sequence, *got[0].ChainSequence)
+	require.Nil(t, got[1].ChainSequence)
+
+	// The restored event still hashes to its chain hash.
+	gotHash, err := ChainHash(testChainKey, []byte("previous"), sequence, got[0].ChainEvent())
+	require.NoError(t, err)
+	require.Equal(t, hash, gotHash)
+}
+
+func TestUnmarshalArchive_Invalid(t *testing.T) {
+	_, err := UnmarshalArchive([]byte("not gzip"))
+	require.Error(t, err)
+}
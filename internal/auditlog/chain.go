@@ -0,0 +1,131 @@
+// Package auditlog makes audit log events tamper-evident.
+//
+// Within a project, audit log events form a hash chain. Each event has a
+// chain sequence number, starting at 1 and increasing by one per event, and a
+// chain hash covering the event's content and the previous event's chain hash.
+// Editing an event changes its hash, and deleting an event leaves a gap in the
+// sequence; either breaks the chain from that point on.
+//
+// Chain hashes are HMACs keyed with a secret per-project chain key, which is
+// kept in the database only encrypted with KMS. Without access to KMS, someone
+// who can write to the database can't recompute the hashes of events they
+// edit.
+//
+// The latest sequence number and hash for each project are kept in
+// audit_log_chain_heads, along with the encrypted chain key. Writers lock the
+// head row while appending an event, so events in a project are chained in the
+// order their transactions commit.
+package auditlog
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// chainHashVersion prefixes the content of every chain hash, so the hash
+// format can be changed without ambiguity.
+const chainHashVersion = "tesseral.audit_log_chain.v1"
+
+// ChainEvent is the content of an audit log event covered by its chain hash.
+type ChainEvent struct {
+	ID                         uuid.UUID
+	ProjectID                  uuid.UUID
+	OrganizationID             *uuid.UUID
+	ActorUserID                *uuid.UUID
+	ActorSessionID             *uuid.UUID
+	ActorAPIKeyID              *uuid.UUID
+	ActorConsoleUserID         *uuid.UUID
+	ActorConsoleSessionID      *uuid.UUID
+	ActorBackendAPIKeyID       *uuid.UUID
+	ActorIntermediateSessionID *uuid.UUID
+	ActorSCIMAPIKeyID          *uuid.UUID
+	ResourceType               *string
+	ResourceID                 *uuid.UUID
+	EventName                  string
+	EventTime                  time.Time
+	EventDetails               []byte
+}
+
+// chainHashContent is the canonical JSON encoding of a ChainEvent. Fields are
+// encoded in declaration order.
+type chainHashContent struct {
+	Version                    string          `json:"version"`
+	Sequence                   int64           `json:"sequence"`
+	ID                         uuid.UUID       `json:"id"`
+	ProjectID                  uuid.UUID       `json:"projectId"`
+	OrganizationID             *uuid.UUID      `json:"organizationId"`
+	ActorUserID                *uuid.UUID      `json:"actorUserId"`
+	ActorSessionID             *uuid.UUID      `json:"actorSessionId"`
+	ActorAPIKeyID              *uuid.UUID      `json:"actorApiKeyId"`
+	ActorConsoleUserID         *uuid.UUID      `json:"actorConsoleUserId"`
+	ActorConsoleSessionID      *uuid.UUID      `json:"actorConsoleSessionId"`
+	ActorBackendAPIKeyID       *uuid.UUID      `json:"actorBackendApiKeyId"`
+	ActorIntermediateSessionID *uuid.UUID      `json:"actorIntermediateSessionId"`
+	ActorSCIMAPIKeyID          *uuid.UUID      `json:"actorScimApiKeyId"`
+	ResourceType               *string         `json:"resourceType"`
+	ResourceID                 *uuid.UUID      `json:"resourceId"`
+	EventName                  string          `json:"eventName"`
+	EventTimeMicros            int64           `json:"eventTimeMicros"`
+	EventDetails               json.RawMessage `json:"eventDetails"`
+}
+
+// ChainHash returns the chain hash of the event at sequence, given the
+// project's chain key and the chain hash of the previous event. The first event
+// in a chain has an empty previousHash.
+func ChainHash(key []byte, previousHash []byte, sequence int64, event ChainEvent) ([]byte, error) {
+	eventDetails, err := canonicalizeEventDetails(event.EventDetails)
+	if err != nil {
+		return nil, fmt.Errorf("canonicalize event details: %w", err)
+	}
+
+	content, err := json.Marshal(chainHashContent{
+		Version:                    chainHashVersion,
+		Sequence:                   sequence,
+		ID:                         event.ID,
+		ProjectID:                  event.ProjectID,
+		OrganizationID:             event.OrganizationID,
+		ActorUserID:                event.ActorUserID,
+		ActorSessionID:             event.ActorSessionID,
+		ActorAPIKeyID:              event.ActorAPIKeyID,
+		ActorConsoleUserID:         event.ActorConsoleUserID,
+		ActorConsoleSessionID:      event.ActorConsoleSessionID,
+		ActorBackendAPIKeyID:       event.ActorBackendAPIKeyID,
+		ActorIntermediateSessionID: event.ActorIntermediateSessionID,
+		ActorSCIMAPIKeyID:          event.ActorSCIMAPIKeyID,
+		ResourceType:               event.ResourceType,
+		ResourceID:                 event.ResourceID,
+		EventName:                  event.EventName,
+		// Postgres stores timestamps with microsecond precision.
+		EventTimeMicros: event.EventTime.UnixMicro(),
+		EventDetails:    eventDetails,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal chain hash content: %w", err)
+	}
+
+	h := hmac.New(sha256.New, key)
+	h.Write(previousHash)
+	h.Write(content)
+	return h.Sum(nil), nil
+}
+
+// canonicalizeEventDetails re-encodes event details so that the bytes written
+// by a store and the bytes read back from a jsonb column, which does not
+// preserve key order or whitespace, hash the same way.
+func canonicalizeEventDetails(eventDetails []byte) ([]byte, error) {
+	if len(eventDetails) == 0 {
+		return []byte("{}"), nil
+	}
+
+	var v any
+	if err := json.Unmarshal(eventDetails, &v); err != nil {
+		return nil, fmt.Errorf("unmarshal event details: %w", err)
+	}
+
+	return json.Marshal(v)
+}
@@ -0,0 +1,129 @@
+package auditlog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var testChainKey = []byte("0123456789abcdef0123456789abcdef")
+
+func testChainEvent() ChainEvent {
+	return ChainEvent{
+		ID:           uuid.MustParse("0197a6e0-7c5a-7f1e-9d3b-3c1a2b4d5e6f"),
+		ProjectID:    uuid.MustParse("0197a6e0-7c5a-7f1e-9d3b-000000000001"),
+		EventName:    "tesseral.users.create",
+		EventTime:    time.Date(2025, 6, 1, 12, 0, 0, 123456789, time.UTC),
+		EventDetails: []byte(`{"user":{"email":"alice@example.com","owner":true}}`),
+	}
+}
+
+func TestChainHash_Deterministic(t *testing.T) {
+	event := testChainEvent()
+
+	hash1, err := ChainHash(testChainKey, nil, 1, event)
+	require.NoError(t, err)
+	hash2, err := ChainHash(testChainKey, nil, 1, event)
+	require.NoError(t, err)
+
+	require.Len(t, hash1, 32)
+	require.Equal(t, hash1, hash2)
+}
+
+func TestChainHash_CanonicalEventDetails(t *testing.T) {
+	event := testChainEvent()
+	hash1, err := ChainHash(testChainKey, nil, 1, event)
+	require.NoError(t, err)
+
+	// jsonb does not preserve key order or whitespace.
+	event.EventDetails = []byte(`{ "user": { "owner": true, "email": "alice@example.com" } }`)
+	hash2, err := ChainHash(testChainKey, nil, 1, event)
+	require.NoError(t, err)
+
+	require.Equal(t, hash1, hash2)
+}
+
+func TestChainHash_EventTimeMicroseconds(t *testing.T) {
+	event := testChainEvent()
+	hash1, err := ChainHash(testChainKey, nil, 1, event)
+	require.NoError(t, err)
+
+	// Postgres stores timestamps with microsecond precision.
+	event.EventTime = event.EventTime.Truncate(time.Microsecond)
+	hash2, err := ChainHash(testChainKey, nil, 1, event)
+	require.NoError(t, err)
+
+	require.Equal(t, hash1, hash2)
+}
+
+func TestChainHash_EmptyEventDetails(t *testing.T) {
+	event := testChainEvent()
+	event.EventDetails = nil
+	hash1, err := ChainHash(testChainKey, nil, 1, event)
+	require.NoError(t, err)
+
+	event.EventDetails = []byte(`{}`)
+	hash2, err := ChainHash(testChainKey, nil, 1, event)
+	require.NoError(t, err)
+
+	require.Equal(t, hash1, hash2)
+}
+
+func TestChainHash_Changes(t *testing.T) {
+	event := testChainEvent()
+	hash, err := ChainHash(testChainKey, nil, 1, event)
+	require.NoError(t, err)
+
+	organizationID := uuid.New()
+	for name, modify := range map[string]func(*ChainEvent){
+		"event name": func(e *ChainEvent) {
+			e.EventName = "tesseral.users.delete"
+		},
+		"event details": func(e *ChainEvent) {
+			e.EventDetails = []byte(`{"user":{"email":"mallory@example.com","owner":true}}`)
+		},
+		"event time": func(e *ChainEvent) {
+			e.EventTime = e.EventTime.Add(time.Second)
+		},
+		"organization id": func(e *ChainEvent) {
+			e.OrganizationID = &organizationID
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			modified := testChainEvent()
+			modify(&modified)
+
+			modifiedHash, err := ChainHash(testChainKey, nil, 1, modified)
+			require.NoError(t, err)
+			require.NotEqual(t, hash, modifiedHash)
+		})
+	}
+
+	t.Run("sequence", func(t *testing.T) {
+		modifiedHash, err := ChainHash(testChainKey, nil, 2, event)
+		require.NoError(t, err)
+		require.NotEqual(t, hash, modifiedHash)
+	})
+
+	t.Run("key", func(t *testing.T) {
+		modifiedHash, err := ChainHash([]byte("another key"), nil, 1, event)
+		require.NoError(t, err)
+		require.NotEqual(t, hash, modifiedHash)
+	})
+
+	t.Run("previous hash", func(t *testing.T) {
+		modifiedHash, err := ChainHash(testChainKey, []byte("previous"), 1, event)
+		require.NoError(t, err)
+		require.NotEqual(t, hash, modifiedHash)
+	})
+}
+
+func TestChainHash_InvalidEventDetails(t *testing.T) {
+	event := testChainEvent()
+	event.EventDetails = []byte(`{`)
+
+	_, err := ChainHash(testChainKey, nil, 1, event)
+	require.Error(t, err)
+}
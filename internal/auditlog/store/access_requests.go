@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/auditlog/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) GetAccessRequest(ctx context.Context, db queries.DBTX, id uuid.UUID) (*auditlogv1.AccessRequest, error) {
+	qAccessRequest, err := queries.New(db).GetAccessRequest(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get user role assignment request: %w", err)
+	}
+
+	var status auditlogv1.AccessRequestStatus
+	switch qAccessRequest.Status {
+	case queries.AccessRequestStatusPending:
+		status = auditlogv1.AccessRequestStatus_ACCESS_REQUEST_STATUS_PENDING
+	case queries.AccessRequestStatusApproved:
+		status = auditlogv1.AccessRequestStatus_ACCESS_REQUEST_STATUS_APPROVED
+	case queries.AccessRequestStatusDenied:
+		status = auditlogv1.AccessRequestStatus_ACCESS_REQUEST_STATUS_DENIED
+	}
+
+	var reviewerUserID string
+	if qAccessRequest.ReviewerUserID != nil {
+		reviewerUserID = idformat.User.Format(*qAccessRequest.ReviewerUserID)
+	}
+
+	return &auditlogv1.AccessRequest{
+		Id:              idformat.AccessRequest.Format(qAccessRequest.ID),
+		CreateTime:      timestamppb.New(*qAccessRequest.CreateTime),
+		UpdateTime:      timestamppb.New(*qAccessRequest.UpdateTime),
+		UserId:          idformat.User.Format(qAccessRequest.UserID),
+		RoleId:          idformat.Role.Format(qAccessRequest.RoleID),
+		Reason:          qAccessRequest.Reason,
+		DurationSeconds: qAccessRequest.DurationSeconds,
+		Status:          status,
+		ReviewerUserId:  reviewerUserID,
+		ReviewTime:      timestampOrNil(qAccessRequest.ReviewTime),
+	}, nil
+}
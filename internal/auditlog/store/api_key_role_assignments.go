@@ -17,8 +17,9 @@ func (s *Store) GetAPIKeyRoleAssignment(ctx context.Context, db queries.DBTX, id
 	}
 
 	return &auditlogv1.APIKeyRoleAssignment{
-		Id:       idformat.APIKeyRoleAssignment.Format(qAPIKeyRoleAssignment.ID),
-		ApiKeyId: idformat.APIKey.Format(qAPIKeyRoleAssignment.ApiKeyID),
-		RoleId:   idformat.Role.Format(qAPIKeyRoleAssignment.RoleID),
+		Id:         idformat.APIKeyRoleAssignment.Format(qAPIKeyRoleAssignment.ID),
+		ApiKeyId:   idformat.APIKey.Format(qAPIKeyRoleAssignment.ApiKeyID),
+		RoleId:     idformat.Role.Format(qAPIKeyRoleAssignment.RoleID),
+		ExpireTime: timestampOrNil(qAPIKeyRoleAssignment.ExpireTime),
 	}, nil
 }
@@ -18,12 +18,17 @@ func (s *Store) GetAPIKey(ctx context.Context, db queries.DBTX, id uuid.UUID) (*
 	}
 
 	return &auditlogv1.APIKey{
-		Id:                idformat.APIKey.Format(qAPIKey.ID),
-		CreateTime:        timestamppb.New(*qAPIKey.CreateTime),
-		UpdateTime:        timestamppb.New(*qAPIKey.UpdateTime),
-		ExpireTime:        timestampOrNil(qAPIKey.ExpireTime),
-		DisplayName:       qAPIKey.DisplayName,
-		SecretTokenSuffix: derefOrEmpty(qAPIKey.SecretTokenSuffix),
-		Revoked:           qAPIKey.SecretTokenSha256 == nil,
+		Id:                            idformat.APIKey.Format(qAPIKey.ID),
+		CreateTime:                    timestamppb.New(*qAPIKey.CreateTime),
+		UpdateTime:                    timestamppb.New(*qAPIKey.UpdateTime),
+		ExpireTime:                    timestampOrNil(qAPIKey.ExpireTime),
+		DisplayName:                   qAPIKey.DisplayName,
+		SecretTokenSuffix:             derefOrEmpty(qAPIKey.SecretTokenSuffix),
+		Revoked:                       qAPIKey.SecretTokenSha256 == nil,
+		IpAllowlist:                   qAPIKey.IpAllowlist,
+		RequestQuota:                  qAPIKey.RequestQuota,
+		RequestQuotaWindowSeconds:     qAPIKey.RequestQuotaWindowSeconds,
+		PreviousSecretTokenSuffix:     qAPIKey.PreviousSecretTokenSuffix,
+		PreviousSecretTokenExpireTime: timestampOrNil(qAPIKey.PreviousSecretTokenExpireTime),
 	}, nil
 }
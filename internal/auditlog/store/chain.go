@@ -0,0 +1,298 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/auditlog"
+	"github.com/tesseral-labs/tesseral/internal/auditlog/store/queries"
+)
+
+type ChainProblemType string
+
+const (
+	// ChainProblemMissingEvents means events were deleted from the chain.
+	ChainProblemMissingEvents ChainProblemType = "missing_events"
+
+	// ChainProblemHashMismatch means an event's content, or its hash, was
+	// modified.
+	ChainProblemHashMismatch ChainProblemType = "hash_mismatch"
+
+	// ChainProblemHeadMismatch means the chain head does not match the last
+	// event in the chain, e.g. because events at the end of the chain were
+	// deleted.
+	ChainProblemHeadMismatch ChainProblemType = "head_mismatch"
+
+	// ChainProblemUnchainedEvent means an event was created without a chain
+	// sequence or hash after the chain began.
+	ChainProblemUnchainedEvent ChainProblemType = "unchained_event"
+)
+
+type ChainProblem struct {
+	Type ChainProblemType
+
+	// Sequence is the chain sequence number the problem was found at, if any.
+	// For ChainProblemMissingEvents, it is the first missing sequence number.
+	Sequence int64
+
+	// AuditLogEventID is the event the problem was found at, if any.
+	AuditLogEventID *uuid.UUID
+}
+
+// ChainPosition identifies a point in a project's audit log chain up to which
+// events have been verified. The zero value is the start of the chain.
+type ChainPosition struct {
+	Sequence int64
+	Hash     []byte
+}
+
+type VerifyChainParams struct {
+	ProjectID uuid.UUID
+
+	// After is where verification starts, exclusive.
+	After ChainPosition
+
+	// Limit is the maximum number of events to verify.
+	Limit int
+}
+
+type VerifyChainResult struct {
+	// VerifiedEventCount is the number of events checked.
+	VerifiedEventCount int
+
+	Problems []ChainProblem
+
+	// Next is where to continue verification from. It is nil once the end of
+	// the chain has been reached.
+	Next *ChainPosition
+}
+
+// ChainKey returns the chain key of a project's audit log, and the key
+// encrypted with KMS. keyCiphertext is the encrypted key from the project's
+// chain head; if it is nil, ChainKey generates a new key, which the caller
+// must store on the chain head.
+//
+// Chain keys are generated by KMS with a symmetric KMS key, so that nobody can
+// create a ciphertext for a key of their own without access to KMS. The
+// project ID is the encryption context, so a project's encrypted chain key
+// can't be used by another project.
+func (s *Store) ChainKey(ctx context.Context, projectID uuid.UUID, keyCiphertext []byte) ([]byte, []byte, error) {
+	encryptionContext := map[string]string{"project_id": projectID.String()}
+
+	if keyCiphertext == nil {
+		generateRes, err := s.kms.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+			KeyId:             &s.auditLogChainKeysKMSKeyID,
+			KeySpec:           types.DataKeySpecAes256,
+			EncryptionContext: encryptionContext,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("generate data key: %w", err)
+		}
+
+		return generateRes.Plaintext, generateRes.CiphertextBlob, nil
+	}
+
+	decryptRes, err := s.kms.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:             &s.auditLogChainKeysKMSKeyID,
+		CiphertextBlob:    keyCiphertext,
+		EncryptionContext: encryptionContext,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("decrypt chain key: %w", err)
+	}
+
+	return decryptRes.Plaintext, keyCiphertext, nil
+}
+
+// maxUnchainedEventProblems bounds how many unchained events are reported.
+const maxUnchainedEventProblems = 100
+
+// VerifyChain verifies part of a project's audit log hash chain. Callers
+// verify the whole chain by calling VerifyChain until Next is nil.
+//
+// Every event's hash is recomputed from its content and the previous event's
+// hash. Archived events are checked only for their place in the sequence;
+// their content is checked if they are restored. Once the end of the chain is
+// reached, the last event is compared against the chain head, and events
+// created since the start of the chain without a chain position are reported.
+func (s *Store) VerifyChain(ctx context.Context, db queries.DBTX, params VerifyChainParams) (*VerifyChainResult, error) {
+	q := queries.New(db)
+
+	links, err := listChainLinks(ctx, q, params)
+	if err != nil {
+		return nil, err
+	}
+
+	qChainHead, err := q.GetAuditLogChainHead(ctx, params.ProjectID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("get audit log chain head: %w", err)
+	}
+
+	// A chain without a key has no events to hash. If events claim otherwise,
+	// their hashes are reported as mismatched.
+	var key []byte
+	if qChainHead.KeyCiphertext != nil {
+		key, _, err = s.ChainKey(ctx, params.ProjectID, qChainHead.KeyCiphertext)
+		if err != nil {
+			return nil, fmt.Errorf("get audit log chain key: %w", err)
+		}
+	}
+
+	var res VerifyChainResult
+	position := params.After
+	for _, link := range links {
+		// After a gap, the previous hash is unknown, so the event's own hash
+		// can't be checked. Its successors still can.
+		if link.sequence != position.Sequence+1 {
+			res.Problems = append(res.Problems, ChainProblem{
+				Type:            ChainProblemMissingEvents,
+				Sequence:        position.Sequence + 1,
+				AuditLogEventID: link.eventID(),
+			})
+		} else if link.event != nil {
+			hash, err := auditlog.ChainHash(key, position.Hash, link.sequence, parseChainEvent(*link.event))
+			if err != nil {
+				return nil, fmt.Errorf("compute audit log chain hash: %w", err)
+			}
+
+			if key == nil || !bytes.Equal(hash, link.hash) {
+				res.Problems = append(res.Problems, ChainProblem{
+					Type:            ChainProblemHashMismatch,
+					Sequence:        link.sequence,
+					AuditLogEventID: link.eventID(),
+				})
+			}
+		}
+
+		res.VerifiedEventCount++
+		position = ChainPosition{Sequence: link.sequence, Hash: link.hash}
+	}
+
+	if len(links) == params.Limit {
+		res.Next = &position
+		return &res, nil
+	}
+
+	// The end of the chain has been reached. A project without a chain head
+	// has a zero head.
+	if qChainHead.Sequence != position.Sequence || !bytes.Equal(qChainHead.Hash, position.Hash) {
+		res.Problems = append(res.Problems, ChainProblem{
+			Type:     ChainProblemHeadMismatch,
+			Sequence: qChainHead.Sequence,
+		})
+	}
+
+	qFirstEvents, err := q.ListAuditLogEventsByChainSequence(ctx, queries.ListAuditLogEventsByChainSequenceParams{
+		ProjectID:     params.ProjectID,
+		AfterSequence: 0,
+		Limit:         1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list first audit log event by chain sequence: %w", err)
+	}
+
+	if len(qFirstEvents) > 0 {
+		unchainedEventIDs, err := q.ListUnchainedAuditLogEventIDsAfter(ctx, queries.ListUnchainedAuditLogEventIDsAfterParams{
+			ProjectID: params.ProjectID,
+			ID:        qFirstEvents[0].ID,
+			Limit:     maxUnchainedEventProblems,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list unchained audit log event ids: %w", err)
+		}
+
+		for _, unchainedEventID := range unchainedEventIDs {
+			res.Problems = append(res.Problems, ChainProblem{
+				Type:            ChainProblemUnchainedEvent,
+				AuditLogEventID: &unchainedEventID,
+			})
+		}
+	}
+
+	return &res, nil
+}
+
+// chainLink is a position in the chain. It is either an audit log event, or
+// the link left behind by an archived audit log event, whose content is no
+// longer available to check.
+type chainLink struct {
+	sequence int64
+	hash     []byte
+	event    *queries.AuditLogEvent
+}
+
+func (l chainLink) eventID() *uuid.UUID {
+	if l.event == nil {
+		return nil
+	}
+	return &l.event.ID
+}
+
+// listChainLinks returns up to params.Limit links after params.After, merging
+// audit log events with archived chain links.
+func listChainLinks(ctx context.Context, q *queries.Queries, params VerifyChainParams) ([]chainLink, error) {
+	qEvents, err := q.ListAuditLogEventsByChainSequence(ctx, queries.ListAuditLogEventsByChainSequenceParams{
+		ProjectID:     params.ProjectID,
+		AfterSequence: params.After.Sequence,
+		Limit:         int32(params.Limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list audit log events by chain sequence: %w", err)
+	}
+
+	qArchivedLinks, err := q.ListAuditLogArchivedChainLinks(ctx, queries.ListAuditLogArchivedChainLinksParams{
+		ProjectID:     params.ProjectID,
+		AfterSequence: params.After.Sequence,
+		Limit:         int32(params.Limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list audit log archived chain links: %w", err)
+	}
+
+	var links []chainLink
+	for len(links) < params.Limit && (len(qEvents) > 0 || len(qArchivedLinks) > 0) {
+		if len(qArchivedLinks) == 0 || (len(qEvents) > 0 && *qEvents[0].ChainSequence < qArchivedLinks[0].ChainSequence) {
+			links = append(links, chainLink{
+				sequence: *qEvents[0].ChainSequence,
+				hash:     qEvents[0].ChainHash,
+				event:    &qEvents[0],
+			})
+			qEvents = qEvents[1:]
+		} else {
+			links = append(links, chainLink{
+				sequence: qArchivedLinks[0].ChainSequence,
+				hash:     qArchivedLinks[0].ChainHash,
+			})
+			qArchivedLinks = qArchivedLinks[1:]
+		}
+	}
+
+	return links, nil
+}
+
+func parseChainEvent(qEvent queries.AuditLogEvent) auditlog.ChainEvent {
+	return auditlog.ChainEvent{
+		ID:                         qEvent.ID,
+		ProjectID:                  qEvent.ProjectID,
+		OrganizationID:             qEvent.OrganizationID,
+		ActorUserID:                qEvent.ActorUserID,
+		ActorSessionID:             qEvent.ActorSessionID,
+		ActorAPIKeyID:              qEvent.ActorApiKeyID,
+		ActorConsoleUserID:         qEvent.ActorConsoleUserID,
+		ActorConsoleSessionID:      qEvent.ActorConsoleSessionID,
+		ActorBackendAPIKeyID:       qEvent.ActorBackendApiKeyID,
+		ActorIntermediateSessionID: qEvent.ActorIntermediateSessionID,
+		ActorSCIMAPIKeyID:          qEvent.ActorScimApiKeyID,
+		ResourceType:               (*string)(qEvent.ResourceType),
+		ResourceID:                 qEvent.ResourceID,
+		EventName:                  qEvent.EventName,
+		EventTime:                  *qEvent.EventTime,
+		EventDetails:               qEvent.EventDetails,
+	}
+}
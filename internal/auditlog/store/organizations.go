@@ -8,6 +8,8 @@ import (
 	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
 	"github.com/tesseral-labs/tesseral/internal/auditlog/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -17,6 +19,16 @@ func (s *Store) GetOrganization(ctx context.Context, db queries.DBTX, id uuid.UU
 		return nil, fmt.Errorf("get organization: %w", err)
 	}
 
+	var parentOrganizationID string
+	if qOrganization.ParentOrganizationID != nil {
+		parentOrganizationID = idformat.Organization.Format(*qOrganization.ParentOrganizationID)
+	}
+
+	var publicMetadata structpb.Struct
+	if err := protojson.Unmarshal(qOrganization.PublicMetadata, &publicMetadata); err != nil {
+		return nil, fmt.Errorf("unmarshal public metadata: %w", err)
+	}
+
 	return &auditlogv1.Organization{
 		Id:                        idformat.Organization.Format(qOrganization.ID),
 		DisplayName:               qOrganization.DisplayName,
@@ -35,5 +47,8 @@ func (s *Store) GetOrganization(ctx context.Context, db queries.DBTX, id uuid.UU
 		CustomRolesEnabled:        &qOrganization.CustomRolesEnabled,
 		ApiKeysEnabled:            &qOrganization.ApiKeysEnabled,
 		LogInWithGithub:           &qOrganization.LogInWithGithub,
+		ParentOrganizationId:      parentOrganizationID,
+		EnforceSsoForDomains:      &qOrganization.EnforceSsoForDomains,
+		PublicMetadata:            &publicMetadata,
 	}, nil
 }
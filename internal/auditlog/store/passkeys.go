@@ -18,9 +18,14 @@ func (s *Store) GetPasskey(ctx context.Context, db queries.DBTX, id uuid.UUID) (
 		return nil, fmt.Errorf("get passkey: %w", err)
 	}
 
+	var userID string
+	if qPasskey.UserID != nil {
+		userID = idformat.User.Format(*qPasskey.UserID)
+	}
+
 	return &auditlogv1.Passkey{
 		Id:           idformat.Passkey.Format(qPasskey.ID),
-		UserId:       idformat.User.Format(qPasskey.UserID),
+		UserId:       userID,
 		CreateTime:   timestamppb.New(*qPasskey.CreateTime),
 		UpdateTime:   timestamppb.New(*qPasskey.UpdateTime),
 		Disabled:     &qPasskey.Disabled,
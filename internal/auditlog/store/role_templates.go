@@ -0,0 +1,34 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/auditlog/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) GetRoleTemplate(ctx context.Context, db queries.DBTX, id uuid.UUID) (*auditlogv1.RoleTemplate, error) {
+	qRoleTemplate, err := queries.New(db).GetRoleTemplate(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get role template: %w", err)
+	}
+
+	actions, err := queries.New(db).GetRoleTemplateActions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get role template actions: %w", err)
+	}
+
+	return &auditlogv1.RoleTemplate{
+		Id:          idformat.RoleTemplate.Format(qRoleTemplate.ID),
+		CreateTime:  timestamppb.New(*qRoleTemplate.CreateTime),
+		UpdateTime:  timestamppb.New(*qRoleTemplate.UpdateTime),
+		DisplayName: qRoleTemplate.DisplayName,
+		Description: qRoleTemplate.Description,
+		Actions:     actions,
+		Version:     qRoleTemplate.Version,
+	}, nil
+}
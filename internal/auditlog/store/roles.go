@@ -27,6 +27,21 @@ func (s *Store) GetRole(ctx context.Context, db queries.DBTX, id uuid.UUID) (*au
 		return nil, fmt.Errorf("get actions: %w", err)
 	}
 
+	qRoleInheritedRoles, err := queries.New(db).BatchGetRoleInheritedRolesByRoleID(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, fmt.Errorf("get role inherited roles: %w", err)
+	}
+
+	var inheritedRoleIDs []string
+	for _, qRoleInheritedRole := range qRoleInheritedRoles {
+		inheritedRoleIDs = append(inheritedRoleIDs, idformat.Role.Format(qRoleInheritedRole.InheritedRoleID))
+	}
+
+	var roleTemplateID string
+	if qRole.RoleTemplateID != nil {
+		roleTemplateID = idformat.RoleTemplate.Format(*qRole.RoleTemplateID)
+	}
+
 	var actions []string
 	for _, qRoleAction := range qRoleActions {
 		if qRoleAction.RoleID != qRole.ID {
@@ -42,11 +57,15 @@ func (s *Store) GetRole(ctx context.Context, db queries.DBTX, id uuid.UUID) (*au
 	}
 
 	return &auditlogv1.Role{
-		Id:          idformat.Role.Format(qRole.ID),
-		CreateTime:  timestamppb.New(*qRole.CreateTime),
-		UpdateTime:  timestamppb.New(*qRole.UpdateTime),
-		DisplayName: qRole.DisplayName,
-		Description: qRole.Description,
-		Actions:     actions,
+		Id:                  idformat.Role.Format(qRole.ID),
+		CreateTime:          timestamppb.New(*qRole.CreateTime),
+		UpdateTime:          timestamppb.New(*qRole.UpdateTime),
+		DisplayName:         qRole.DisplayName,
+		Description:         qRole.Description,
+		Actions:             actions,
+		InheritedRoleIds:    inheritedRoleIDs,
+		Requestable:         qRole.Requestable,
+		RoleTemplateId:      roleTemplateID,
+		RoleTemplateVersion: derefOrEmpty(qRole.RoleTemplateVersion),
 	}, nil
 }
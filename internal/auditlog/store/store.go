@@ -3,10 +3,28 @@ package store
 import (
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type Store struct {
+	kms                       *kms.Client
+	auditLogChainKeysKMSKeyID string
+}
+
+type NewStoreParams struct {
+	KMS *kms.Client
+
+	// AuditLogChainKeysKMSKeyID is the symmetric KMS key that audit log chain
+	// keys are generated and encrypted with; see ChainKey.
+	AuditLogChainKeysKMSKeyID string
+}
+
+func New(p NewStoreParams) *Store {
+	return &Store{
+		kms:                       p.KMS,
+		auditLogChainKeysKMSKeyID: p.AuditLogChainKeysKMSKeyID,
+	}
 }
 
 func timestampOrNil(t *time.Time) *timestamppb.Timestamp {
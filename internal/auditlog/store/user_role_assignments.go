@@ -17,8 +17,12 @@ func (s *Store) GetUserRoleAssignment(ctx context.Context, db queries.DBTX, id u
 	}
 
 	return &auditlogv1.UserRoleAssignment{
-		Id:     idformat.UserRoleAssignment.Format(qUserRoleAssignment.ID),
-		UserId: idformat.User.Format(qUserRoleAssignment.UserID),
-		RoleId: idformat.Role.Format(qUserRoleAssignment.RoleID),
+		Id:                   idformat.UserRoleAssignment.Format(qUserRoleAssignment.ID),
+		UserId:               idformat.User.Format(qUserRoleAssignment.UserID),
+		RoleId:               idformat.Role.Format(qUserRoleAssignment.RoleID),
+		ResourceType:         derefOrEmpty(qUserRoleAssignment.ResourceType),
+		ResourceId:           derefOrEmpty(qUserRoleAssignment.ResourceID),
+		ExpireTime:           timestampOrNil(qUserRoleAssignment.ExpireTime),
+		InheritToDescendants: qUserRoleAssignment.InheritToDescendants,
 	}, nil
 }
@@ -8,15 +8,28 @@ import (
 	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
 	"github.com/tesseral-labs/tesseral/internal/auditlog/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *Store) GetUser(ctx context.Context, db queries.DBTX, id uuid.UUID) (*auditlogv1.User, error) {
-	qUser, err := queries.New(db).GetUser(ctx, id)
+	q := queries.New(db)
+	qUser, err := q.GetUser(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("get user: %w", err)
 	}
 
+	qIdentity, err := q.GetIdentity(ctx, qUser.IdentityID)
+	if err != nil {
+		return nil, fmt.Errorf("get identity: %w", err)
+	}
+
+	var publicMetadata structpb.Struct
+	if err := protojson.Unmarshal(qUser.PublicMetadata, &publicMetadata); err != nil {
+		return nil, fmt.Errorf("unmarshal public metadata: %w", err)
+	}
+
 	return &auditlogv1.User{
 		Id:                  idformat.User.Format(qUser.ID),
 		Email:               qUser.Email,
@@ -26,8 +39,9 @@ func (s *Store) GetUser(ctx context.Context, db queries.DBTX, id uuid.UUID) (*au
 		GoogleUserId:        qUser.GoogleUserID,
 		MicrosoftUserId:     qUser.MicrosoftUserID,
 		GithubUserId:        qUser.GithubUserID,
-		HasAuthenticatorApp: qUser.AuthenticatorAppSecretCiphertext != nil,
+		HasAuthenticatorApp: qIdentity.AuthenticatorAppSecretCiphertext != nil,
 		DisplayName:         qUser.DisplayName,
 		ProfilePictureUrl:   qUser.ProfilePictureUrl,
+		PublicMetadata:      &publicMetadata,
 	}, nil
 }
@@ -0,0 +1,29 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/auditlog/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) GetWebhookEndpoint(ctx context.Context, db queries.DBTX, id uuid.UUID) (*auditlogv1.WebhookEndpoint, error) {
+	qWebhookEndpoint, err := queries.New(db).GetWebhookEndpoint(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get webhook endpoint: %w", err)
+	}
+
+	return &auditlogv1.WebhookEndpoint{
+		Id:          idformat.WebhookEndpoint.Format(qWebhookEndpoint.ID),
+		CreateTime:  timestamppb.New(*qWebhookEndpoint.CreateTime),
+		UpdateTime:  timestamppb.New(*qWebhookEndpoint.UpdateTime),
+		Url:         qWebhookEndpoint.Url,
+		Description: derefOrEmpty(qWebhookEndpoint.Description),
+		EventTypes:  qWebhookEndpoint.EventTypes,
+		Disabled:    qWebhookEndpoint.Disabled,
+	}, nil
+}
@@ -0,0 +1,80 @@
+// Package auditlogexport exports audit log events to customer-configured
+// destinations, such as SIEMs.
+package auditlogexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Event is the exported representation of an audit log event. It matches the
+// shape of AuditLogEvent in the Backend API, plus the Project ID.
+type Event struct {
+	ID                         string          `json:"id"`
+	ProjectID                  string          `json:"projectId"`
+	OrganizationID             string          `json:"organizationId,omitempty"`
+	ActorUserID                string          `json:"actorUserId,omitempty"`
+	ActorSessionID             string          `json:"actorSessionId,omitempty"`
+	ActorAPIKeyID              string          `json:"actorApiKeyId,omitempty"`
+	ActorBackendAPIKeyID       string          `json:"actorBackendApiKeyId,omitempty"`
+	ActorIntermediateSessionID string          `json:"actorIntermediateSessionId,omitempty"`
+	ActorSCIMAPIKeyID          string          `json:"actorScimApiKeyId,omitempty"`
+	EventName                  string          `json:"eventName"`
+	EventTime                  time.Time       `json:"eventTime"`
+	EventDetails               json.RawMessage `json:"eventDetails"`
+
+	// ChainSequence and ChainHash are the event's position and hash in its
+	// project's audit log hash chain. Keeping them outside of Tesseral lets the
+	// chain be checked against an independent copy.
+	ChainSequence int64  `json:"chainSequence,omitempty"`
+	ChainHash     string `json:"chainHash,omitempty"`
+}
+
+// MarshalJSONL encodes events as newline-delimited JSON.
+func MarshalJSONL(events []Event) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return nil, fmt.Errorf("encode event: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+const (
+	// syslogPriority is facility 13 (log audit) at severity 6 (informational).
+	syslogPriority = 13*8 + 6
+	syslogAppName  = "tesseral"
+
+	// syslogMaxMsgIDLen is the maximum length of the RFC 5424 MSGID field.
+	syslogMaxMsgIDLen = 32
+)
+
+// FormatSyslog formats an event as an RFC 5424 syslog message. The event name
+// is the MSGID, and the message is the event as JSON.
+func FormatSyslog(event Event) ([]byte, error) {
+	msg, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("marshal event: %w", err)
+	}
+
+	msgID := event.EventName
+	if len(msgID) > syslogMaxMsgIDLen {
+		msgID = msgID[:syslogMaxMsgIDLen]
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s - %s - %s - ", syslogPriority, event.EventTime.UTC().Format(time.RFC3339Nano), syslogAppName, msgID)
+	buf.Write(msg)
+	return buf.Bytes(), nil
+}
+
+// FrameSyslog applies RFC 6587 octet-counting framing to a syslog message, as
+// required when sending messages over a stream transport such as TCP.
+func FrameSyslog(msg []byte) []byte {
+	return append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+}
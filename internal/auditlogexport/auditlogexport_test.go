@@ -0,0 +1,47 @@
+package auditlogexport
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testEvent = Event{
+	ID:             "audit_log_event_123",
+	ProjectID:      "project_123",
+	OrganizationID: "org_123",
+	ActorUserID:    "user_123",
+	EventName:      "tesseral.users.update",
+	EventTime:      time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	EventDetails:   json.RawMessage(`{"user":{"id":"user_123"}}`),
+}
+
+func TestMarshalJSONL(t *testing.T) {
+	other := testEvent
+	other.ID = "audit_log_event_456"
+	other.OrganizationID = ""
+
+	b, err := MarshalJSONL([]Event{testEvent, other})
+	require.NoError(t, err)
+	require.Equal(t, `{"id":"audit_log_event_123","projectId":"project_123","organizationId":"org_123","actorUserId":"user_123","eventName":"tesseral.users.update","eventTime":"2025-01-02T03:04:05Z","eventDetails":{"user":{"id":"user_123"}}}
+{"id":"audit_log_event_456","projectId":"project_123","actorUserId":"user_123","eventName":"tesseral.users.update","eventTime":"2025-01-02T03:04:05Z","eventDetails":{"user":{"id":"user_123"}}}
+`, string(b))
+}
+
+func TestFormatSyslog(t *testing.T) {
+	b, err := FormatSyslog(testEvent)
+	require.NoError(t, err)
+	require.Equal(t, `<110>1 2025-01-02T03:04:05Z - tesseral - tesseral.users.update - {"id":"audit_log_event_123","projectId":"project_123","organizationId":"org_123","actorUserId":"user_123","eventName":"tesseral.users.update","eventTime":"2025-01-02T03:04:05Z","eventDetails":{"user":{"id":"user_123"}}}`, string(b))
+
+	long := testEvent
+	long.EventName = "tesseral.saml_connections.update_very_long_name"
+	b, err = FormatSyslog(long)
+	require.NoError(t, err)
+	require.Contains(t, string(b), " tesseral.saml_connections.update - ")
+}
+
+func TestFrameSyslog(t *testing.T) {
+	require.Equal(t, "5 hello", string(FrameSyslog([]byte("hello"))))
+}
@@ -0,0 +1,203 @@
+package store
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tesseral-labs/tesseral/internal/auditlogexport"
+	"github.com/tesseral-labs/tesseral/internal/auditlogexport/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+)
+
+const (
+	exportDestinationBatchSize = 10
+	exportEventBatchSize       = 500
+	exportPollInterval         = time.Second
+	exportInterval             = 10 * time.Second
+	exportLeaseDuration        = time.Minute
+
+	// exportSettleDelay is how far behind the present exports stay. Audit log
+	// event IDs are assigned before their transaction commits, so an event may
+	// become visible after events with later IDs. Only exporting events older
+	// than exportSettleDelay keeps the cursor from skipping past them.
+	exportSettleDelay = 30 * time.Second
+
+	maxExportBackoff = time.Hour
+)
+
+// RunExport exports audit log events to Audit Log Export Destinations until
+// ctx is canceled.
+//
+// Destinations are claimed with a lease, so multiple API servers may call
+// RunExport concurrently. Each destination's cursor only advances once a batch
+// is accepted, so a batch may be sent more than once; export is at-least-once.
+func (s *Store) RunExport(ctx context.Context) error {
+	ticker := time.NewTicker(exportPollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.exportPending(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "export_pending_audit_logs_error", "err", err)
+		}
+
+		// A full batch suggests there is a backlog; keep going without waiting
+		// for the next tick.
+		if err == nil && n == exportDestinationBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Store) exportPending(ctx context.Context) (int, error) {
+	leaseExpireTime := time.Now().Add(exportLeaseDuration)
+	qDestinations, err := s.q.ClaimAuditLogExportDestinations(ctx, queries.ClaimAuditLogExportDestinationsParams{
+		Limit:           exportDestinationBatchSize,
+		LeaseExpireTime: &leaseExpireTime,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("claim audit log export destinations: %w", err)
+	}
+
+	for _, qDestination := range qDestinations {
+		if err := s.export(ctx, qDestination); err != nil {
+			return 0, fmt.Errorf("export audit logs: %w", err)
+		}
+	}
+
+	return len(qDestinations), nil
+}
+
+func (s *Store) export(ctx context.Context, qDestination queries.AuditLogExportDestination) error {
+	var cursor uuid.UUID
+	if qDestination.CursorAuditLogEventID != nil {
+		cursor = *qDestination.CursorAuditLogEventID
+	}
+
+	attemptTime := time.Now()
+	qEvents, err := s.q.ListAuditLogEventsAfterCursor(ctx, queries.ListAuditLogEventsAfterCursorParams{
+		Limit:          exportEventBatchSize,
+		ProjectID:      qDestination.ProjectID,
+		OrganizationID: qDestination.OrganizationID,
+		Cursor:         cursor,
+		Before:         uuidv7.NewWithTime(attemptTime.Add(-exportSettleDelay)),
+	})
+	if err != nil {
+		return fmt.Errorf("list audit log events after cursor: %w", err)
+	}
+
+	if len(qEvents) == 0 {
+		nextAttemptTime := attemptTime.Add(exportInterval)
+		if err := s.q.RescheduleAuditLogExportDestination(ctx, queries.RescheduleAuditLogExportDestinationParams{
+			ID:              qDestination.ID,
+			NextAttemptTime: &nextAttemptTime,
+		}); err != nil {
+			return fmt.Errorf("reschedule audit log export destination: %w", err)
+		}
+		return nil
+	}
+
+	var events []auditlogexport.Event
+	for _, qEvent := range qEvents {
+		events = append(events, parseEvent(qEvent))
+	}
+
+	sendErr := s.send(ctx, qDestination, events)
+	if sendErr != nil {
+		errorMessage := sendErr.Error()
+		nextAttemptTime := attemptTime.Add(exportBackoff(qDestination.ConsecutiveFailureCount))
+		if err := s.q.UpdateAuditLogExportDestinationFailure(ctx, queries.UpdateAuditLogExportDestinationFailureParams{
+			ID:              qDestination.ID,
+			NextAttemptTime: &nextAttemptTime,
+			LastAttemptTime: &attemptTime,
+			LastError:       &errorMessage,
+		}); err != nil {
+			return fmt.Errorf("update audit log export destination failure: %w", err)
+		}
+	} else {
+		// A full batch suggests there is a backlog; export the next batch as
+		// soon as possible.
+		nextAttemptTime := attemptTime.Add(exportInterval)
+		if len(qEvents) == exportEventBatchSize {
+			nextAttemptTime = attemptTime
+		}
+
+		if err := s.q.UpdateAuditLogExportDestinationSuccess(ctx, queries.UpdateAuditLogExportDestinationSuccessParams{
+			ID:                    qDestination.ID,
+			CursorAuditLogEventID: &qEvents[len(qEvents)-1].ID,
+			NextAttemptTime:       &nextAttemptTime,
+			LastAttemptTime:       &attemptTime,
+		}); err != nil {
+			return fmt.Errorf("update audit log export destination success: %w", err)
+		}
+	}
+
+	slog.InfoContext(ctx, "audit_log_export_attempt",
+		"audit_log_export_destination_id", idformat.AuditLogExportDestination.Format(qDestination.ID),
+		"event_count", len(events),
+		"error", sendErr)
+
+	return nil
+}
+
+// exportBackoff returns the delay before retrying a destination that has
+// failed failureCount times in a row before its latest failure.
+func exportBackoff(failureCount int32) time.Duration {
+	backoff := 5 * time.Second
+	for i := int32(0); i < failureCount; i++ {
+		backoff *= 2
+		if backoff >= maxExportBackoff {
+			return maxExportBackoff
+		}
+	}
+	return backoff
+}
+
+func parseEvent(qEvent queries.AuditLogEvent) auditlogexport.Event {
+	event := auditlogexport.Event{
+		ID:           idformat.AuditLogEvent.Format(qEvent.ID),
+		ProjectID:    idformat.Project.Format(qEvent.ProjectID),
+		EventName:    qEvent.EventName,
+		EventTime:    *qEvent.EventTime,
+		EventDetails: qEvent.EventDetails,
+	}
+
+	if qEvent.ChainSequence != nil {
+		event.ChainSequence = *qEvent.ChainSequence
+		event.ChainHash = hex.EncodeToString(qEvent.ChainHash)
+	}
+	if qEvent.OrganizationID != nil {
+		event.OrganizationID = idformat.Organization.Format(*qEvent.OrganizationID)
+	}
+	if qEvent.ActorUserID != nil {
+		event.ActorUserID = idformat.User.Format(*qEvent.ActorUserID)
+	}
+	if qEvent.ActorSessionID != nil {
+		event.ActorSessionID = idformat.Session.Format(*qEvent.ActorSessionID)
+	}
+	if qEvent.ActorApiKeyID != nil {
+		event.ActorAPIKeyID = idformat.APIKey.Format(*qEvent.ActorApiKeyID)
+	}
+	if qEvent.ActorBackendApiKeyID != nil {
+		event.ActorBackendAPIKeyID = idformat.BackendAPIKey.Format(*qEvent.ActorBackendApiKeyID)
+	}
+	if qEvent.ActorIntermediateSessionID != nil {
+		event.ActorIntermediateSessionID = idformat.IntermediateSession.Format(*qEvent.ActorIntermediateSessionID)
+	}
+	if qEvent.ActorScimApiKeyID != nil {
+		event.ActorSCIMAPIKeyID = idformat.SCIMAPIKey.Format(*qEvent.ActorScimApiKeyID)
+	}
+
+	return event
+}
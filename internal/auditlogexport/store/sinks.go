@@ -0,0 +1,180 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/tesseral-labs/tesseral/internal/auditlogexport"
+	"github.com/tesseral-labs/tesseral/internal/auditlogexport/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/s3role"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
+)
+
+const sendTimeout = 30 * time.Second
+
+// send exports a batch of events to a destination.
+func (s *Store) send(ctx context.Context, qDestination queries.AuditLogExportDestination, events []auditlogexport.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	switch qDestination.Type {
+	case queries.AuditLogExportDestinationTypeHttps:
+		return s.sendHTTPS(ctx, qDestination, events)
+	case queries.AuditLogExportDestinationTypeS3:
+		return s.sendS3(ctx, qDestination, events)
+	case queries.AuditLogExportDestinationTypeSyslog:
+		return s.sendSyslog(ctx, qDestination, events)
+	default:
+		return fmt.Errorf("unknown audit log export destination type: %q", qDestination.Type)
+	}
+}
+
+// sendHTTPS posts a batch as newline-delimited JSON, signed per the Standard
+// Webhooks specification. The ID of the last event in the batch is the message
+// ID, so receivers can deduplicate retried batches. Only 2xx responses count as
+// successful.
+func (s *Store) sendHTTPS(ctx context.Context, qDestination queries.AuditLogExportDestination, events []auditlogexport.Event) error {
+	payload, err := auditlogexport.MarshalJSONL(events)
+	if err != nil {
+		return fmt.Errorf("marshal events: %w", err)
+	}
+
+	decryptRes, err := s.kms.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:               &s.webhookSigningSecretsKMSKeyID,
+		EncryptionAlgorithm: kmstypes.EncryptionAlgorithmSpecRsaesOaepSha256,
+		CiphertextBlob:      qDestination.SigningSecretCiphertext,
+	})
+	if err != nil {
+		return fmt.Errorf("decrypt signing secret: %w", err)
+	}
+
+	msgID := events[len(events)-1].ID
+	timestamp := time.Now()
+	signature, err := webhooks.Sign(string(decryptRes.Plaintext), msgID, timestamp, payload)
+	if err != nil {
+		return fmt.Errorf("sign events: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, *qDestination.HttpsUrl, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set(webhooks.HeaderID, msgID)
+	req.Header.Set(webhooks.HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(webhooks.HeaderSignature, signature)
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	// Drain a bounded amount of the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status code: %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// sendS3 writes a batch as a newline-delimited JSON object, using the
+// destination's role. Objects are keyed by the date and ID of their first
+// event, so a retried batch overwrites the same object.
+func (s *Store) sendS3(ctx context.Context, qDestination queries.AuditLogExportDestination, events []auditlogexport.Event) error {
+	if qDestination.S3RoleArn == nil {
+		return fmt.Errorf("s3 destination has no role")
+	}
+
+	payload, err := auditlogexport.MarshalJSONL(events)
+	if err != nil {
+		return fmt.Errorf("marshal events: %w", err)
+	}
+
+	var prefix string
+	if qDestination.S3Prefix != nil {
+		prefix = *qDestination.S3Prefix
+	}
+
+	key := path.Join(prefix, events[0].EventTime.UTC().Format("2006/01/02"), events[0].ID+".jsonl")
+	if _, err := s.s3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      qDestination.S3Bucket,
+		Key:         &key,
+		Body:        bytes.NewReader(payload),
+		ContentType: aws.String("application/x-ndjson"),
+	}, s3role.WithRole(s.sts, *qDestination.S3RoleArn, qDestination.ProjectID)); err != nil {
+		return fmt.Errorf("put object: %w", err)
+	}
+
+	return nil
+}
+
+// sendSyslog sends each event in a batch as an RFC 5424 message. Messages sent
+// over TCP or TLS use octet-counting framing; messages sent over UDP are one
+// per datagram.
+func (s *Store) sendSyslog(ctx context.Context, qDestination queries.AuditLogExportDestination, events []auditlogexport.Event) error {
+	network := *qDestination.SyslogNetwork
+	addr := *qDestination.SyslogAddress
+
+	dialNetwork := network
+	if network == "tls" {
+		dialNetwork = "tcp"
+	}
+
+	conn, err := s.dialContext(ctx, dialNetwork, addr)
+	if err != nil {
+		return fmt.Errorf("dial syslog server: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return fmt.Errorf("set deadline: %w", err)
+		}
+	}
+
+	if network == "tls" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return fmt.Errorf("split syslog address: %w", err)
+		}
+
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return fmt.Errorf("tls handshake: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	for _, event := range events {
+		msg, err := auditlogexport.FormatSyslog(event)
+		if err != nil {
+			return fmt.Errorf("format syslog message: %w", err)
+		}
+
+		if network != "udp" {
+			msg = auditlogexport.FrameSyslog(msg)
+		}
+
+		if _, err := conn.Write(msg); err != nil {
+			return fmt.Errorf("write syslog message: %w", err)
+		}
+	}
+
+	return nil
+}
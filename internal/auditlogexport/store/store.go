@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tesseral-labs/tesseral/internal/auditlogexport/store/queries"
+)
+
+// Store exports audit log events to each Audit Log Export Destination. See
+// RunExport.
+type Store struct {
+	db                            *pgxpool.Pool
+	q                             *queries.Queries
+	kms                           *kms.Client
+	webhookSigningSecretsKMSKeyID string
+	httpClient                    *http.Client
+	s3                            *s3.Client
+	sts                           *sts.Client
+	dialContext                   func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+type NewStoreParams struct {
+	DB  *pgxpool.Pool
+	KMS *kms.Client
+
+	// WebhookSigningSecretsKMSKeyID is the KMS key that HTTPS destination
+	// signing secrets are encrypted with. They share a key with webhook
+	// endpoint signing secrets, which use the same signing scheme.
+	WebhookSigningSecretsKMSKeyID string
+
+	HTTPClient *http.Client
+	S3         *s3.Client
+
+	// STS is used to assume the roles that S3 destinations write with.
+	STS *sts.Client
+
+	// DialContext is used to connect to syslog destinations.
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+func New(p NewStoreParams) *Store {
+	return &Store{
+		db:                            p.DB,
+		q:                             queries.New(p.DB),
+		kms:                           p.KMS,
+		webhookSigningSecretsKMSKeyID: p.WebhookSigningSecretsKMSKeyID,
+		httpClient:                    p.HTTPClient,
+		s3:                            p.S3,
+		sts:                           p.STS,
+		dialContext:                   p.DialContext,
+	}
+}
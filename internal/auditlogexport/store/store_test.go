@@ -0,0 +1,205 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/tesseral-labs/tesseral/internal/auditlogexport"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/storetesting"
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
+)
+
+var (
+	environment *storetesting.Environment
+)
+
+func TestMain(m *testing.M) {
+	testEnvironment, cleanup := storetesting.NewEnvironment()
+	defer cleanup()
+
+	environment = testEnvironment
+	m.Run()
+}
+
+type receivedBatch struct {
+	header http.Header
+	body   []byte
+}
+
+// testReceiver is an HTTPS destination that responds to each request with the
+// next status code in statusCodes, and records the requests it receives.
+type testReceiver struct {
+	mu          sync.Mutex
+	statusCodes []int
+	received    []receivedBatch
+}
+
+func (r *testReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.received = append(r.received, receivedBatch{header: req.Header, body: body})
+
+	statusCode := http.StatusOK
+	if len(r.statusCodes) > 0 {
+		statusCode, r.statusCodes = r.statusCodes[0], r.statusCodes[1:]
+	}
+	w.WriteHeader(statusCode)
+}
+
+func newTestStore(t *testing.T) (*Store, uuid.UUID) {
+	formattedProjectID, _ := environment.NewProject(t)
+	projectID, err := idformat.Project.Parse(formattedProjectID)
+	require.NoError(t, err)
+
+	return New(NewStoreParams{
+		DB:                            environment.DB,
+		KMS:                           environment.KMS.Client,
+		WebhookSigningSecretsKMSKeyID: environment.KMS.WebhookSigningSecretsKMSKeyID,
+		HTTPClient:                    http.DefaultClient,
+	}), projectID
+}
+
+func newTestHTTPSDestination(t *testing.T, projectID uuid.UUID, url string) (uuid.UUID, string) {
+	secret, err := webhooks.NewSigningSecret()
+	require.NoError(t, err)
+
+	encryptRes, err := environment.KMS.Client.Encrypt(t.Context(), &kms.EncryptInput{
+		KeyId:               &environment.KMS.WebhookSigningSecretsKMSKeyID,
+		EncryptionAlgorithm: types.EncryptionAlgorithmSpecRsaesOaepSha256,
+		Plaintext:           []byte(secret),
+	})
+	require.NoError(t, err)
+
+	id := uuid.New()
+	_, err = environment.DB.Exec(t.Context(), `
+INSERT INTO audit_log_export_destinations (id, project_id, display_name, type, https_url, signing_secret_ciphertext)
+  VALUES ($1::uuid, $2::uuid, 'test', 'https', $3, $4);
+`,
+		id.String(),
+		projectID.String(),
+		url,
+		encryptRes.CiphertextBlob,
+	)
+	require.NoError(t, err)
+
+	return id, secret
+}
+
+func newTestEvent(t *testing.T, projectID uuid.UUID, eventTime time.Time) uuid.UUID {
+	id := uuidv7.NewWithTime(eventTime)
+	_, err := environment.DB.Exec(t.Context(), `
+INSERT INTO audit_log_events (id, project_id, event_name, event_time)
+  VALUES ($1::uuid, $2::uuid, 'tesseral.test', $3);
+`,
+		id.String(),
+		projectID.String(),
+		eventTime,
+	)
+	require.NoError(t, err)
+
+	return id
+}
+
+func TestExport_HTTPS(t *testing.T) {
+	store, projectID := newTestStore(t)
+
+	receiver := &testReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	destinationID, secret := newTestHTTPSDestination(t, projectID, server.URL)
+
+	firstEventID := newTestEvent(t, projectID, time.Now().Add(-2*time.Minute))
+	lastEventID := newTestEvent(t, projectID, time.Now().Add(-time.Minute))
+
+	// Not yet settled, so not exported.
+	newTestEvent(t, projectID, time.Now())
+
+	_, err := store.exportPending(t.Context())
+	require.NoError(t, err)
+
+	require.Len(t, receiver.received, 1)
+	received := receiver.received[0]
+	require.Equal(t, idformat.AuditLogEvent.Format(lastEventID), received.header.Get(webhooks.HeaderID))
+	require.NoError(t, webhooks.Verify(secret, received.header, received.body, time.Now()))
+
+	var events []auditlogexport.Event
+	scanner := bufio.NewScanner(bytes.NewReader(received.body))
+	for scanner.Scan() {
+		var event auditlogexport.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.Len(t, events, 2)
+	require.Equal(t, idformat.AuditLogEvent.Format(firstEventID), events[0].ID)
+	require.Equal(t, idformat.AuditLogEvent.Format(lastEventID), events[1].ID)
+
+	var cursor uuid.UUID
+	err = environment.DB.QueryRow(t.Context(), `SELECT cursor_audit_log_event_id FROM audit_log_export_destinations WHERE id = $1`, destinationID).Scan(&cursor)
+	require.NoError(t, err)
+	require.Equal(t, lastEventID, cursor)
+}
+
+func TestExport_HTTPSRetries(t *testing.T) {
+	store, projectID := newTestStore(t)
+
+	receiver := &testReceiver{statusCodes: []int{http.StatusInternalServerError}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	destinationID, _ := newTestHTTPSDestination(t, projectID, server.URL)
+	eventID := newTestEvent(t, projectID, time.Now().Add(-time.Minute))
+
+	_, err := store.exportPending(t.Context())
+	require.NoError(t, err)
+	require.Len(t, receiver.received, 1)
+
+	var (
+		cursor                  *uuid.UUID
+		lastError               *string
+		consecutiveFailureCount int32
+	)
+	err = environment.DB.QueryRow(t.Context(), `SELECT cursor_audit_log_event_id, last_error, consecutive_failure_count FROM audit_log_export_destinations WHERE id = $1`, destinationID).Scan(&cursor, &lastError, &consecutiveFailureCount)
+	require.NoError(t, err)
+	require.Nil(t, cursor)
+	require.NotNil(t, lastError)
+	require.Equal(t, int32(1), consecutiveFailureCount)
+
+	// Skip the backoff.
+	_, err = environment.DB.Exec(t.Context(), `UPDATE audit_log_export_destinations SET next_attempt_time = now() WHERE id = $1`, destinationID)
+	require.NoError(t, err)
+
+	_, err = store.exportPending(t.Context())
+	require.NoError(t, err)
+	require.Len(t, receiver.received, 2)
+	require.Equal(t, receiver.received[0].body, receiver.received[1].body)
+
+	err = environment.DB.QueryRow(t.Context(), `SELECT cursor_audit_log_event_id, last_error, consecutive_failure_count FROM audit_log_export_destinations WHERE id = $1`, destinationID).Scan(&cursor, &lastError, &consecutiveFailureCount)
+	require.NoError(t, err)
+	require.Equal(t, eventID, *cursor)
+	require.Nil(t, lastError)
+	require.Equal(t, int32(0), consecutiveFailureCount)
+}
+
+func TestExportBackoff(t *testing.T) {
+	require.Equal(t, 5*time.Second, exportBackoff(0))
+	require.Equal(t, 10*time.Second, exportBackoff(1))
+	require.Equal(t, 40*time.Second, exportBackoff(3))
+	require.Equal(t, time.Hour, exportBackoff(20))
+}
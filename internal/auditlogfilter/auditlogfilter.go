@@ -0,0 +1,366 @@
+// Package auditlogfilter compiles filter expressions over audit log event
+// details into Postgres jsonpath predicates.
+//
+// A filter compares fields of an event's details against literal values:
+//
+//	details.user.email = "alice@example.com"
+//	details.role.actions contains "admin" and not details.previousRole.actions contains "admin"
+//	details.user.owner = true or (details.user.email != null and details.apiKey exists)
+//
+// Fields are paths starting with "details". Path segments that aren't plain
+// identifiers may be quoted, as in details."my-field". Values are strings,
+// numbers, true, false, or null.
+//
+// The operators are =, !=, <, <=, >, >=, contains, and exists. contains matches
+// arrays containing the value, and strings containing the value as a substring.
+// Comparisons are combined with and, or, not, and parentheses.
+//
+// The compiled predicate is meant to be used with the jsonb @@ operator, which
+// a jsonb_path_ops GIN index can serve for equality and exists checks.
+package auditlogfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxFilterLen is the longest filter expression accepted.
+	maxFilterLen = 2048
+
+	// maxDepth bounds how deeply expressions may nest.
+	maxDepth = 32
+)
+
+// Compile compiles a filter expression into a jsonpath predicate.
+func Compile(filter string) (string, error) {
+	if len(filter) > maxFilterLen {
+		return "", fmt.Errorf("filter must be at most %d characters", maxFilterLen)
+	}
+
+	tokens, err := lex(filter)
+	if err != nil {
+		return "", err
+	}
+
+	p := parser{tokens: tokens}
+	jsonpath, err := p.parseOr(0)
+	if err != nil {
+		return "", err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return "", fmt.Errorf("at position %d: unexpected %s", tok.pos, tok)
+	}
+
+	return jsonpath, nil
+}
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.i]
+	if tok.kind != tokenEOF {
+		p.i++
+	}
+	return tok
+}
+
+func (p *parser) parseOr(depth int) (string, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return "", err
+	}
+
+	for p.peek().isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return "", err
+		}
+		left = fmt.Sprintf("(%s || %s)", left, right)
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd(depth int) (string, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return "", err
+	}
+
+	for p.peek().isKeyword("and") {
+		p.next()
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return "", err
+		}
+		left = fmt.Sprintf("(%s && %s)", left, right)
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary(depth int) (string, error) {
+	if depth > maxDepth {
+		return "", fmt.Errorf("at position %d: filter is nested too deeply", p.peek().pos)
+	}
+
+	if p.peek().isKeyword("not") {
+		p.next()
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("!(%s)", operand), nil
+	}
+
+	if p.peek().kind == tokenLParen {
+		p.next()
+		inner, err := p.parseOr(depth + 1)
+		if err != nil {
+			return "", err
+		}
+
+		if tok := p.next(); tok.kind != tokenRParen {
+			return "", fmt.Errorf("at position %d: expected ), got %s", tok.pos, tok)
+		}
+		return inner, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (string, error) {
+	path, err := p.parsePath()
+	if err != nil {
+		return "", err
+	}
+
+	op := p.next()
+	if op.isKeyword("exists") {
+		return fmt.Sprintf("exists(%s)", path), nil
+	}
+
+	var jsonpathOp string
+	switch {
+	case op.kind == tokenOp:
+		jsonpathOp = op.text
+		if jsonpathOp == "=" {
+			jsonpathOp = "=="
+		}
+	case op.isKeyword("contains"):
+	default:
+		return "", fmt.Errorf("at position %d: expected operator, got %s", op.pos, op)
+	}
+
+	value, isString, err := p.parseValue()
+	if err != nil {
+		return "", err
+	}
+
+	if op.isKeyword("contains") {
+		if !isString {
+			return fmt.Sprintf("%s[*] == %s", path, value), nil
+		}
+
+		var s string
+		if err := json.Unmarshal([]byte(value), &s); err != nil {
+			panic(fmt.Errorf("unmarshal compiled string literal: %w", err))
+		}
+		return fmt.Sprintf("(%s[*] == %s || %s like_regex %s)", path, value, path, quote(regexp.QuoteMeta(s))), nil
+	}
+
+	return fmt.Sprintf("%s %s %s", path, jsonpathOp, value), nil
+}
+
+func (p *parser) parsePath() (string, error) {
+	tok := p.next()
+	if tok.kind != tokenIdent || tok.text != "details" {
+		return "", fmt.Errorf("at position %d: expected a field starting with \"details\", got %s", tok.pos, tok)
+	}
+
+	var b strings.Builder
+	b.WriteString("$")
+	for p.peek().kind == tokenDot {
+		p.next()
+
+		segment := p.next()
+		if segment.kind != tokenIdent && segment.kind != tokenString {
+			return "", fmt.Errorf("at position %d: expected a field name, got %s", segment.pos, segment)
+		}
+
+		b.WriteString(".")
+		b.WriteString(quote(segment.text))
+	}
+
+	if b.Len() == 1 {
+		return "", fmt.Errorf("at position %d: expected a field of details", tok.pos)
+	}
+
+	return b.String(), nil
+}
+
+// parseValue returns a value as a jsonpath literal, and whether it is a
+// string.
+func (p *parser) parseValue() (string, bool, error) {
+	tok := p.next()
+	switch {
+	case tok.kind == tokenString:
+		return quote(tok.text), true, nil
+	case tok.kind == tokenNumber:
+		return tok.text, false, nil
+	case tok.isKeyword("true"), tok.isKeyword("false"), tok.isKeyword("null"):
+		return strings.ToLower(tok.text), false, nil
+	default:
+		return "", false, fmt.Errorf("at position %d: expected a value, got %s", tok.pos, tok)
+	}
+}
+
+// quote returns s as a jsonpath string literal. jsonpath string literals use
+// the same escapes as JSON.
+func quote(s string) string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		panic(fmt.Errorf("marshal string: %w", err))
+	}
+	return string(b)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOp
+	tokenDot
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, keyword)
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of filter"
+	case tokenString:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '.':
+			tokens = append(tokens, token{kind: tokenDot, text: ".", pos: i})
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case c == '=':
+			tokens = append(tokens, token{kind: tokenOp, text: "=", pos: i})
+			i++
+		case c == '!' || c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(s) && s[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("at position %d: unexpected \"!\"; use != or not", i)
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: i})
+			i += len(op)
+		case c == '"':
+			str, n, err := lexString(s[i:])
+			if err != nil {
+				return nil, fmt.Errorf("at position %d: %w", i, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: str, pos: i})
+			i += n
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(s) && strings.IndexByte("0123456789.eE+-", s[j]) != -1 {
+				j++
+			}
+			f, err := strconv.ParseFloat(s[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("at position %d: invalid number %q", i, s[i:j])
+			}
+
+			// Normalize the number, so that it is a valid jsonpath literal.
+			tokens = append(tokens, token{kind: tokenNumber, text: strconv.FormatFloat(f, 'f', -1, 64), pos: i})
+			i = j
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(s) && isIdentPart(s[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: s[i:j], pos: i})
+			i = j
+		default:
+			return nil, fmt.Errorf("at position %d: unexpected character %q", i, c)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(s)})
+	return tokens, nil
+}
+
+// lexString lexes a double-quoted string at the start of s, returning its
+// value and its length in s. Strings use JSON escapes.
+func lexString(s string) (string, int, error) {
+	for j := 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '"':
+			var str string
+			if err := json.Unmarshal([]byte(s[:j+1]), &str); err != nil {
+				return "", 0, fmt.Errorf("invalid string: %w", err)
+			}
+			return str, j + 1, nil
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,107 @@
+package auditlogfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	testCases := []struct {
+		filter string
+		want   string
+	}{
+		{
+			filter: `details.user.email = "alice@example.com"`,
+			want:   `$."user"."email" == "alice@example.com"`,
+		},
+		{
+			filter: `details.user.email != null`,
+			want:   `$."user"."email" != null`,
+		},
+		{
+			filter: `details.user.owner = TRUE`,
+			want:   `$."user"."owner" == true`,
+		},
+		{
+			filter: `details.count >= 1e3`,
+			want:   `$."count" >= 1000`,
+		},
+		{
+			filter: `details."weird key".x < -1.5`,
+			want:   `$."weird key"."x" < -1.5`,
+		},
+		{
+			filter: `details.role.actions contains "admin"`,
+			want:   `($."role"."actions"[*] == "admin" || $."role"."actions" like_regex "admin")`,
+		},
+		{
+			filter: `details.role.actions contains "a.b"`,
+			want:   `($."role"."actions"[*] == "a.b" || $."role"."actions" like_regex "a\\.b")`,
+		},
+		{
+			filter: `details.counts contains 3`,
+			want:   `$."counts"[*] == 3`,
+		},
+		{
+			filter: `details.apiKey exists`,
+			want:   `exists($."apiKey")`,
+		},
+		{
+			filter: `details.a = 1 or details.b = 2 and not details.c = 3`,
+			want:   `($."a" == 1 || ($."b" == 2 && !($."c" == 3)))`,
+		},
+		{
+			filter: `(details.a = 1 or details.b = 2) and details.c = 3`,
+			want:   `(($."a" == 1 || $."b" == 2) && $."c" == 3)`,
+		},
+		{
+			filter: `details.user.email = "\"quoted\" \\ é"`,
+			want:   `$."user"."email" == "\"quoted\" \\ é"`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.filter, func(t *testing.T) {
+			got, err := Compile(tt.filter)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	for _, filter := range []string{
+		``,
+		`details`,
+		`user.email = "x"`,
+		`details.user.email`,
+		`details.user.email = `,
+		`details.user.email = x`,
+		`details.user.email == "x"`,
+		`details.user.email = "x`,
+		`details.user.email = "x" and`,
+		`(details.user.email = "x"`,
+		`details.user.email = "x")`,
+		`!details.user.email = "x"`,
+		`details.user.email = "x"; drop table users`,
+		`details.user.email = 1.2.3`,
+		`details.user.$ = 1`,
+	} {
+		t.Run(filter, func(t *testing.T) {
+			_, err := Compile(filter)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCompile_TooDeep(t *testing.T) {
+	filter := ""
+	for range maxDepth + 1 {
+		filter += "not "
+	}
+	filter += `details.a = 1`
+
+	_, err := Compile(filter)
+	require.Error(t, err)
+}
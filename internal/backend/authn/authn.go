@@ -16,6 +16,11 @@ type ContextData struct {
 type BackendAPIKeyContextData struct {
 	BackendAPIKeyID string
 	ProjectID       string
+
+	// Scopes and OrganizationIDs restrict what the Backend API Key may do. If
+	// empty, the Backend API Key is unrestricted.
+	Scopes          []string
+	OrganizationIDs []string
 }
 
 // DogfoodSessionContextData contains data related to a user logged into
@@ -14,6 +14,7 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/backend/store"
 	"github.com/tesseral-labs/tesseral/internal/ujwt"
 	"go.opentelemetry.io/otel"
+	"google.golang.org/protobuf/proto"
 )
 
 var errAuthorizationHeaderRequired = errors.New("authorization header is required")
@@ -49,10 +50,27 @@ func New(s *store.Store, dogfoodProjectID string) connect.UnaryInterceptorFunc {
 					return nil, fmt.Errorf("authenticate project api key: %w", err)
 				}
 
+				if !authn.ScopesAllowProcedure(res.Scopes, req.Spec().Procedure) {
+					return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("backend api key scopes do not allow %s", req.Spec().Procedure))
+				}
+
 				ctx = authn.NewBackendAPIKeyContext(ctx, &authn.BackendAPIKeyContextData{
 					BackendAPIKeyID: res.BackendAPIKeyID,
 					ProjectID:       res.ProjectID,
+					Scopes:          res.Scopes,
+					OrganizationIDs: res.OrganizationIDs,
 				})
+
+				if len(res.OrganizationIDs) > 0 {
+					msg, ok := req.Any().(proto.Message)
+					if !ok {
+						return nil, connect.NewError(connect.CodePermissionDenied, fmt.Errorf("backend api key is restricted to specific organizations"))
+					}
+
+					if err := s.ValidateBackendAPIKeyOrganizationAccess(ctx, res.OrganizationIDs, req.Spec().Procedure, msg); err != nil {
+						return nil, fmt.Errorf("validate backend api key organization access: %w", err)
+					}
+				}
 			} else {
 				// look for access token in cookie
 				var accessToken string
@@ -0,0 +1,263 @@
+package authn
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1/backendv1connect"
+)
+
+// Backend API Keys may be granted scopes that limit which BackendService RPCs
+// they can call. A Backend API Key with no scopes can call any RPC.
+//
+// Scopes take one of the following forms:
+//
+//   - "read-only" allows every RPC that does not change anything, other than
+//     impersonation.
+//   - "<resource>:read" allows the RPCs that read resource.
+//   - "<resource>:write" and "<resource>:*" allow every RPC on resource.
+//   - "impersonation" allows creating User Impersonation Tokens.
+const (
+	ScopeReadOnly      = "read-only"
+	ScopeImpersonation = "impersonation"
+)
+
+// Resources Backend API Key scopes apply to.
+const (
+	scopeResourceProject        = "project"
+	scopeResourceOrganizations  = "organizations"
+	scopeResourceSSO            = "sso"
+	scopeResourceSCIM           = "scim"
+	scopeResourceUsers          = "users"
+	scopeResourceRBAC           = "rbac"
+	scopeResourceAPIKeys        = "api-keys"
+	scopeResourceAuditLogs      = "audit-logs"
+	scopeResourceWebhooks       = "webhooks"
+	scopeResourceEmailTemplates = "email-templates"
+	scopeResourceImpersonation  = "impersonation"
+)
+
+var scopeResources = []string{
+	scopeResourceProject,
+	scopeResourceOrganizations,
+	scopeResourceSSO,
+	scopeResourceSCIM,
+	scopeResourceUsers,
+	scopeResourceRBAC,
+	scopeResourceAPIKeys,
+	scopeResourceAuditLogs,
+	scopeResourceWebhooks,
+	scopeResourceEmailTemplates,
+}
+
+type procedureScope struct {
+	resource string
+	write    bool
+}
+
+func read(resource string) procedureScope {
+	return procedureScope{resource: resource}
+}
+
+func write(resource string) procedureScope {
+	return procedureScope{resource: resource, write: true}
+}
+
+// procedureScopes maps each BackendService procedure a scoped Backend API Key
+// may call to the resource it acts on. Procedures that are not listed, such as
+// those that manage Backend API Keys themselves, are only available to
+// unscoped Backend API Keys.
+var procedureScopes = map[string]procedureScope{
+	backendv1connect.BackendServiceGetProjectProcedure:                            read(scopeResourceProject),
+	backendv1connect.BackendServiceUpdateProjectProcedure:                         write(scopeResourceProject),
+	backendv1connect.BackendServiceDisableProjectLoginsProcedure:                  write(scopeResourceProject),
+	backendv1connect.BackendServiceEnableProjectLoginsProcedure:                   write(scopeResourceProject),
+	backendv1connect.BackendServiceGetVaultDomainSettingsProcedure:                read(scopeResourceProject),
+	backendv1connect.BackendServiceUpdateVaultDomainSettingsProcedure:             write(scopeResourceProject),
+	backendv1connect.BackendServiceEnableCustomVaultDomainProcedure:               write(scopeResourceProject),
+	backendv1connect.BackendServiceEnableEmailSendFromDomainProcedure:             write(scopeResourceProject),
+	backendv1connect.BackendServiceGetProjectUISettingsProcedure:                  read(scopeResourceProject),
+	backendv1connect.BackendServiceUpdateProjectUISettingsProcedure:               write(scopeResourceProject),
+	backendv1connect.BackendServiceListPublishableKeysProcedure:                   read(scopeResourceProject),
+	backendv1connect.BackendServiceGetPublishableKeyProcedure:                     read(scopeResourceProject),
+	backendv1connect.BackendServiceCreatePublishableKeyProcedure:                  write(scopeResourceProject),
+	backendv1connect.BackendServiceUpdatePublishableKeyProcedure:                  write(scopeResourceProject),
+	backendv1connect.BackendServiceDeletePublishableKeyProcedure:                  write(scopeResourceProject),
+	backendv1connect.BackendServiceGetProjectEntitlementsProcedure:                read(scopeResourceProject),
+	backendv1connect.BackendServiceListOrganizationsProcedure:                     read(scopeResourceOrganizations),
+	backendv1connect.BackendServiceGetOrganizationProcedure:                       read(scopeResourceOrganizations),
+	backendv1connect.BackendServiceCreateOrganizationProcedure:                    write(scopeResourceOrganizations),
+	backendv1connect.BackendServiceUpdateOrganizationProcedure:                    write(scopeResourceOrganizations),
+	backendv1connect.BackendServiceDeleteOrganizationProcedure:                    write(scopeResourceOrganizations),
+	backendv1connect.BackendServiceListOrganizationDescendantsProcedure:           read(scopeResourceOrganizations),
+	backendv1connect.BackendServiceGetOrganizationDomainsProcedure:                read(scopeResourceOrganizations),
+	backendv1connect.BackendServiceUpdateOrganizationDomainsProcedure:             write(scopeResourceOrganizations),
+	backendv1connect.BackendServiceVerifyOrganizationDomainsProcedure:             write(scopeResourceOrganizations),
+	backendv1connect.BackendServiceGetOrganizationGoogleHostedDomainsProcedure:    read(scopeResourceOrganizations),
+	backendv1connect.BackendServiceUpdateOrganizationGoogleHostedDomainsProcedure: write(scopeResourceOrganizations),
+	backendv1connect.BackendServiceGetOrganizationMicrosoftTenantIDsProcedure:     read(scopeResourceOrganizations),
+	backendv1connect.BackendServiceUpdateOrganizationMicrosoftTenantIDsProcedure:  write(scopeResourceOrganizations),
+	backendv1connect.BackendServiceDisableOrganizationLoginsProcedure:             write(scopeResourceOrganizations),
+	backendv1connect.BackendServiceEnableOrganizationLoginsProcedure:              write(scopeResourceOrganizations),
+	backendv1connect.BackendServiceListSAMLConnectionsProcedure:                   read(scopeResourceSSO),
+	backendv1connect.BackendServiceGetSAMLConnectionProcedure:                     read(scopeResourceSSO),
+	backendv1connect.BackendServiceCreateSAMLConnectionProcedure:                  write(scopeResourceSSO),
+	backendv1connect.BackendServiceUpdateSAMLConnectionProcedure:                  write(scopeResourceSSO),
+	backendv1connect.BackendServiceDeleteSAMLConnectionProcedure:                  write(scopeResourceSSO),
+	backendv1connect.BackendServiceListOIDCConnectionsProcedure:                   read(scopeResourceSSO),
+	backendv1connect.BackendServiceGetOIDCConnectionProcedure:                     read(scopeResourceSSO),
+	backendv1connect.BackendServiceCreateOIDCConnectionProcedure:                  write(scopeResourceSSO),
+	backendv1connect.BackendServiceUpdateOIDCConnectionProcedure:                  write(scopeResourceSSO),
+	backendv1connect.BackendServiceDeleteOIDCConnectionProcedure:                  write(scopeResourceSSO),
+	backendv1connect.BackendServiceListSCIMAPIKeysProcedure:                       read(scopeResourceSCIM),
+	backendv1connect.BackendServiceGetSCIMAPIKeyProcedure:                         read(scopeResourceSCIM),
+	backendv1connect.BackendServiceCreateSCIMAPIKeyProcedure:                      write(scopeResourceSCIM),
+	backendv1connect.BackendServiceUpdateSCIMAPIKeyProcedure:                      write(scopeResourceSCIM),
+	backendv1connect.BackendServiceDeleteSCIMAPIKeyProcedure:                      write(scopeResourceSCIM),
+	backendv1connect.BackendServiceRevokeSCIMAPIKeyProcedure:                      write(scopeResourceSCIM),
+	backendv1connect.BackendServiceListUsersProcedure:                             read(scopeResourceUsers),
+	backendv1connect.BackendServiceGetUserProcedure:                               read(scopeResourceUsers),
+	backendv1connect.BackendServiceCreateUserProcedure:                            write(scopeResourceUsers),
+	backendv1connect.BackendServiceImportUsersProcedure:                           write(scopeResourceUsers),
+	backendv1connect.BackendServiceUpdateUserProcedure:                            write(scopeResourceUsers),
+	backendv1connect.BackendServiceDeleteUserProcedure:                            write(scopeResourceUsers),
+	backendv1connect.BackendServiceListPasskeysProcedure:                          read(scopeResourceUsers),
+	backendv1connect.BackendServiceGetPasskeyProcedure:                            read(scopeResourceUsers),
+	backendv1connect.BackendServiceUpdatePasskeyProcedure:                         write(scopeResourceUsers),
+	backendv1connect.BackendServiceDeletePasskeyProcedure:                         write(scopeResourceUsers),
+	backendv1connect.BackendServiceListSessionsProcedure:                          read(scopeResourceUsers),
+	backendv1connect.BackendServiceGetSessionProcedure:                            read(scopeResourceUsers),
+	backendv1connect.BackendServiceListUserInvitesProcedure:                       read(scopeResourceUsers),
+	backendv1connect.BackendServiceGetUserInviteProcedure:                         read(scopeResourceUsers),
+	backendv1connect.BackendServiceCreateUserInviteProcedure:                      write(scopeResourceUsers),
+	backendv1connect.BackendServiceDeleteUserInviteProcedure:                      write(scopeResourceUsers),
+	backendv1connect.BackendServiceGetRBACPolicyProcedure:                         read(scopeResourceRBAC),
+	backendv1connect.BackendServiceUpdateRBACPolicyProcedure:                      write(scopeResourceRBAC),
+	backendv1connect.BackendServiceListRolesProcedure:                             read(scopeResourceRBAC),
+	backendv1connect.BackendServiceGetRoleProcedure:                               read(scopeResourceRBAC),
+	backendv1connect.BackendServiceCreateRoleProcedure:                            write(scopeResourceRBAC),
+	backendv1connect.BackendServiceUpdateRoleProcedure:                            write(scopeResourceRBAC),
+	backendv1connect.BackendServiceDeleteRoleProcedure:                            write(scopeResourceRBAC),
+	backendv1connect.BackendServiceListUserRoleAssignmentsProcedure:               read(scopeResourceRBAC),
+	backendv1connect.BackendServiceGetUserRoleAssignmentProcedure:                 read(scopeResourceRBAC),
+	backendv1connect.BackendServiceCreateUserRoleAssignmentProcedure:              write(scopeResourceRBAC),
+	backendv1connect.BackendServiceDeleteUserRoleAssignmentProcedure:              write(scopeResourceRBAC),
+	backendv1connect.BackendServiceListRoleTemplatesProcedure:                     read(scopeResourceRBAC),
+	backendv1connect.BackendServiceGetRoleTemplateProcedure:                       read(scopeResourceRBAC),
+	backendv1connect.BackendServiceCreateRoleTemplateProcedure:                    write(scopeResourceRBAC),
+	backendv1connect.BackendServiceUpdateRoleTemplateProcedure:                    write(scopeResourceRBAC),
+	backendv1connect.BackendServiceDeleteRoleTemplateProcedure:                    write(scopeResourceRBAC),
+	backendv1connect.BackendServiceListAccessRequestsProcedure:                    read(scopeResourceRBAC),
+	backendv1connect.BackendServiceGetAccessRequestProcedure:                      read(scopeResourceRBAC),
+	backendv1connect.BackendServiceListAPIKeyRoleAssignmentsProcedure:             read(scopeResourceRBAC),
+	backendv1connect.BackendServiceCreateAPIKeyRoleAssignmentProcedure:            write(scopeResourceRBAC),
+	backendv1connect.BackendServiceDeleteAPIKeyRoleAssignmentProcedure:            write(scopeResourceRBAC),
+	backendv1connect.BackendServiceCheckPermissionProcedure:                       read(scopeResourceRBAC),
+	backendv1connect.BackendServiceListPermittedResourcesProcedure:                read(scopeResourceRBAC),
+	backendv1connect.BackendServiceCheckActionProcedure:                           read(scopeResourceRBAC),
+	backendv1connect.BackendServiceBatchCheckActionsProcedure:                     read(scopeResourceRBAC),
+	backendv1connect.BackendServiceListAPIKeysProcedure:                           read(scopeResourceAPIKeys),
+	backendv1connect.BackendServiceGetAPIKeyProcedure:                             read(scopeResourceAPIKeys),
+	backendv1connect.BackendServiceCreateAPIKeyProcedure:                          write(scopeResourceAPIKeys),
+	backendv1connect.BackendServiceUpdateAPIKeyProcedure:                          write(scopeResourceAPIKeys),
+	backendv1connect.BackendServiceDeleteAPIKeyProcedure:                          write(scopeResourceAPIKeys),
+	backendv1connect.BackendServiceRevokeAPIKeyProcedure:                          write(scopeResourceAPIKeys),
+	backendv1connect.BackendServiceRotateAPIKeyProcedure:                          write(scopeResourceAPIKeys),
+	backendv1connect.BackendServiceAuthenticateAPIKeyProcedure:                    read(scopeResourceAPIKeys),
+	backendv1connect.BackendServiceCreateAuditLogEventProcedure:                   write(scopeResourceAuditLogs),
+	backendv1connect.BackendServiceVerifyAuditLogChainProcedure:                   read(scopeResourceAuditLogs),
+	backendv1connect.BackendServiceListAuditLogArchivesProcedure:                  read(scopeResourceAuditLogs),
+	backendv1connect.BackendServiceRestoreAuditLogArchiveProcedure:                write(scopeResourceAuditLogs),
+	backendv1connect.BackendServiceListAuditLogExportDestinationsProcedure:        read(scopeResourceAuditLogs),
+	backendv1connect.BackendServiceGetAuditLogExportDestinationProcedure:          read(scopeResourceAuditLogs),
+	backendv1connect.BackendServiceCreateAuditLogExportDestinationProcedure:       write(scopeResourceAuditLogs),
+	backendv1connect.BackendServiceUpdateAuditLogExportDestinationProcedure:       write(scopeResourceAuditLogs),
+	backendv1connect.BackendServiceDeleteAuditLogExportDestinationProcedure:       write(scopeResourceAuditLogs),
+	backendv1connect.BackendServiceConsoleExportAuditLogEventsProcedure:           read(scopeResourceAuditLogs),
+	backendv1connect.BackendServiceConsoleListAuditLogEventsProcedure:             read(scopeResourceAuditLogs),
+	backendv1connect.BackendServiceConsoleListAuditLogEventNamesProcedure:         read(scopeResourceAuditLogs),
+	backendv1connect.BackendServiceGetProjectWebhookManagementURLProcedure:        read(scopeResourceWebhooks),
+	backendv1connect.BackendServiceListWebhookEndpointsProcedure:                  read(scopeResourceWebhooks),
+	backendv1connect.BackendServiceGetWebhookEndpointProcedure:                    read(scopeResourceWebhooks),
+	backendv1connect.BackendServiceCreateWebhookEndpointProcedure:                 write(scopeResourceWebhooks),
+	backendv1connect.BackendServiceUpdateWebhookEndpointProcedure:                 write(scopeResourceWebhooks),
+	backendv1connect.BackendServiceDeleteWebhookEndpointProcedure:                 write(scopeResourceWebhooks),
+	backendv1connect.BackendServiceListWebhookDeliveriesProcedure:                 read(scopeResourceWebhooks),
+	backendv1connect.BackendServiceListEmailTemplatesProcedure:                    read(scopeResourceEmailTemplates),
+	backendv1connect.BackendServiceGetEmailTemplateProcedure:                      read(scopeResourceEmailTemplates),
+	backendv1connect.BackendServiceCreateEmailTemplateProcedure:                   write(scopeResourceEmailTemplates),
+	backendv1connect.BackendServiceUpdateEmailTemplateProcedure:                   write(scopeResourceEmailTemplates),
+	backendv1connect.BackendServiceDeleteEmailTemplateProcedure:                   write(scopeResourceEmailTemplates),
+	backendv1connect.BackendServiceGetDefaultEmailTemplateProcedure:               read(scopeResourceEmailTemplates),
+	backendv1connect.BackendServicePreviewEmailTemplateProcedure:                  read(scopeResourceEmailTemplates),
+	backendv1connect.BackendServiceCreateUserImpersonationTokenProcedure:          write(scopeResourceImpersonation),
+}
+
+// ValidateScopes returns an error describing the first invalid scope in
+// scopes.
+func ValidateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if scope == ScopeReadOnly || scope == ScopeImpersonation {
+			continue
+		}
+
+		resource, access, ok := strings.Cut(scope, ":")
+		if !ok || !isScopeResource(resource) || (access != "read" && access != "write" && access != "*") {
+			return fmt.Errorf("invalid scope: %q", scope)
+		}
+	}
+	return nil
+}
+
+// ScopesAllowProcedure reports whether a Backend API Key with scopes may call
+// the BackendService procedure.
+func ScopesAllowProcedure(scopes []string, procedure string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+
+	ps, ok := procedureScopes[procedure]
+	if !ok {
+		return false
+	}
+
+	for _, scope := range scopes {
+		if scopeAllows(scope, ps) {
+			return true
+		}
+	}
+	return false
+}
+
+func scopeAllows(scope string, ps procedureScope) bool {
+	if ps.resource == scopeResourceImpersonation {
+		return scope == ScopeImpersonation
+	}
+
+	if scope == ScopeReadOnly {
+		return !ps.write
+	}
+
+	resource, access, ok := strings.Cut(scope, ":")
+	if !ok || resource != ps.resource {
+		return false
+	}
+
+	switch access {
+	case "read":
+		return !ps.write
+	case "write", "*":
+		return true
+	default:
+		return false
+	}
+}
+
+func isScopeResource(resource string) bool {
+	for _, r := range scopeResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package authn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1/backendv1connect"
+)
+
+func TestValidateScopes(t *testing.T) {
+	require.NoError(t, ValidateScopes(nil))
+	require.NoError(t, ValidateScopes([]string{"read-only", "impersonation", "users:write", "organizations:*", "audit-logs:read"}))
+	require.Error(t, ValidateScopes([]string{"users"}))
+	require.Error(t, ValidateScopes([]string{"users:delete"}))
+	require.Error(t, ValidateScopes([]string{"widgets:read"}))
+	require.Error(t, ValidateScopes([]string{"impersonation:write"}))
+}
+
+func TestScopesAllowProcedure(t *testing.T) {
+	testCases := []struct {
+		name      string
+		scopes    []string
+		procedure string
+		want      bool
+	}{
+		{"unscoped", nil, backendv1connect.BackendServiceDeleteUserProcedure, true},
+		{"unscoped backend api keys", nil, backendv1connect.BackendServiceCreateBackendAPIKeyProcedure, true},
+		{"read-only read", []string{"read-only"}, backendv1connect.BackendServiceListUsersProcedure, true},
+		{"read-only write", []string{"read-only"}, backendv1connect.BackendServiceDeleteUserProcedure, false},
+		{"read-only impersonation", []string{"read-only"}, backendv1connect.BackendServiceCreateUserImpersonationTokenProcedure, false},
+		{"resource read", []string{"audit-logs:read"}, backendv1connect.BackendServiceConsoleListAuditLogEventsProcedure, true},
+		{"resource read write", []string{"audit-logs:read"}, backendv1connect.BackendServiceCreateAuditLogEventProcedure, false},
+		{"resource write", []string{"users:write"}, backendv1connect.BackendServiceDeleteUserProcedure, true},
+		{"resource write read", []string{"users:write"}, backendv1connect.BackendServiceGetUserProcedure, true},
+		{"resource wildcard", []string{"organizations:*"}, backendv1connect.BackendServiceUpdateOrganizationProcedure, true},
+		{"other resource", []string{"users:write"}, backendv1connect.BackendServiceUpdateOrganizationProcedure, false},
+		{"impersonation", []string{"impersonation"}, backendv1connect.BackendServiceCreateUserImpersonationTokenProcedure, true},
+		{"impersonation only", []string{"impersonation"}, backendv1connect.BackendServiceGetUserProcedure, false},
+		{"users write impersonation", []string{"users:write"}, backendv1connect.BackendServiceCreateUserImpersonationTokenProcedure, false},
+		{"scoped backend api keys", []string{"read-only"}, backendv1connect.BackendServiceListBackendAPIKeysProcedure, false},
+		{"scoped apply project config", []string{"project:write", "rbac:write"}, backendv1connect.BackendServiceApplyProjectConfigProcedure, false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ScopesAllowProcedure(tt.scopes, tt.procedure))
+		})
+	}
+}
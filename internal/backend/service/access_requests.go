@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) ListAccessRequests(ctx context.Context, req *connect.Request[backendv1.ListAccessRequestsRequest]) (*connect.Response[backendv1.ListAccessRequestsResponse], error) {
+	res, err := s.Store.ListAccessRequests(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) GetAccessRequest(ctx context.Context, req *connect.Request[backendv1.GetAccessRequestRequest]) (*connect.Response[backendv1.GetAccessRequestResponse], error) {
+	res, err := s.Store.GetAccessRequest(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
@@ -53,6 +53,15 @@ func (s *Service) RevokeAPIKey(ctx context.Context, req *connect.Request[backend
 	return connect.NewResponse(res), nil
 }
 
+func (s *Service) RotateAPIKey(ctx context.Context, req *connect.Request[backendv1.RotateAPIKeyRequest]) (*connect.Response[backendv1.RotateAPIKeyResponse], error) {
+	res, err := s.Store.RotateAPIKey(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
 func (s *Service) UpdateAPIKey(ctx context.Context, req *connect.Request[backendv1.UpdateAPIKeyRequest]) (*connect.Response[backendv1.UpdateAPIKeyResponse], error) {
 	res, err := s.Store.UpdateAPIKey(ctx, req.Msg)
 	if err != nil {
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) ListAuditLogArchives(ctx context.Context, req *connect.Request[backendv1.ListAuditLogArchivesRequest]) (*connect.Response[backendv1.ListAuditLogArchivesResponse], error) {
+	res, err := s.Store.ListAuditLogArchives(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) RestoreAuditLogArchive(ctx context.Context, req *connect.Request[backendv1.RestoreAuditLogArchiveRequest]) (*connect.Response[backendv1.RestoreAuditLogArchiveResponse], error) {
+	res, err := s.Store.RestoreAuditLogArchive(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
@@ -33,3 +33,21 @@ func (s *Service) ConsoleListAuditLogEventNames(ctx context.Context, req *connec
 
 	return connect.NewResponse(res), nil
 }
+
+func (s *Service) ConsoleExportAuditLogEvents(ctx context.Context, req *connect.Request[backendv1.ConsoleExportAuditLogEventsRequest]) (*connect.Response[backendv1.ConsoleExportAuditLogEventsResponse], error) {
+	res, err := s.Store.ConsoleExportAuditLogEvents(ctx, req.Msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) VerifyAuditLogChain(ctx context.Context, req *connect.Request[backendv1.VerifyAuditLogChainRequest]) (*connect.Response[backendv1.VerifyAuditLogChainResponse], error) {
+	res, err := s.Store.VerifyAuditLogChain(ctx, req.Msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(res), nil
+}
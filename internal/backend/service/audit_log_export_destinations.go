@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) ListAuditLogExportDestinations(ctx context.Context, req *connect.Request[backendv1.ListAuditLogExportDestinationsRequest]) (*connect.Response[backendv1.ListAuditLogExportDestinationsResponse], error) {
+	res, err := s.Store.ListAuditLogExportDestinations(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) GetAuditLogExportDestination(ctx context.Context, req *connect.Request[backendv1.GetAuditLogExportDestinationRequest]) (*connect.Response[backendv1.GetAuditLogExportDestinationResponse], error) {
+	res, err := s.Store.GetAuditLogExportDestination(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) CreateAuditLogExportDestination(ctx context.Context, req *connect.Request[backendv1.CreateAuditLogExportDestinationRequest]) (*connect.Response[backendv1.CreateAuditLogExportDestinationResponse], error) {
+	res, err := s.Store.CreateAuditLogExportDestination(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) UpdateAuditLogExportDestination(ctx context.Context, req *connect.Request[backendv1.UpdateAuditLogExportDestinationRequest]) (*connect.Response[backendv1.UpdateAuditLogExportDestinationResponse], error) {
+	res, err := s.Store.UpdateAuditLogExportDestination(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) DeleteAuditLogExportDestination(ctx context.Context, req *connect.Request[backendv1.DeleteAuditLogExportDestinationRequest]) (*connect.Response[backendv1.DeleteAuditLogExportDestinationResponse], error) {
+	res, err := s.Store.DeleteAuditLogExportDestination(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) CheckAction(ctx context.Context, req *connect.Request[backendv1.CheckActionRequest]) (*connect.Response[backendv1.CheckActionResponse], error) {
+	res, err := s.Store.CheckAction(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) BatchCheckActions(ctx context.Context, req *connect.Request[backendv1.BatchCheckActionsRequest]) (*connect.Response[backendv1.BatchCheckActionsResponse], error) {
+	res, err := s.Store.BatchCheckActions(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
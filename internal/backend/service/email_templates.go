@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) ListEmailTemplates(ctx context.Context, req *connect.Request[backendv1.ListEmailTemplatesRequest]) (*connect.Response[backendv1.ListEmailTemplatesResponse], error) {
+	res, err := s.Store.ListEmailTemplates(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) GetEmailTemplate(ctx context.Context, req *connect.Request[backendv1.GetEmailTemplateRequest]) (*connect.Response[backendv1.GetEmailTemplateResponse], error) {
+	res, err := s.Store.GetEmailTemplate(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) CreateEmailTemplate(ctx context.Context, req *connect.Request[backendv1.CreateEmailTemplateRequest]) (*connect.Response[backendv1.CreateEmailTemplateResponse], error) {
+	res, err := s.Store.CreateEmailTemplate(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) UpdateEmailTemplate(ctx context.Context, req *connect.Request[backendv1.UpdateEmailTemplateRequest]) (*connect.Response[backendv1.UpdateEmailTemplateResponse], error) {
+	res, err := s.Store.UpdateEmailTemplate(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) DeleteEmailTemplate(ctx context.Context, req *connect.Request[backendv1.DeleteEmailTemplateRequest]) (*connect.Response[backendv1.DeleteEmailTemplateResponse], error) {
+	res, err := s.Store.DeleteEmailTemplate(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) GetDefaultEmailTemplate(ctx context.Context, req *connect.Request[backendv1.GetDefaultEmailTemplateRequest]) (*connect.Response[backendv1.GetDefaultEmailTemplateResponse], error) {
+	res, err := s.Store.GetDefaultEmailTemplate(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) PreviewEmailTemplate(ctx context.Context, req *connect.Request[backendv1.PreviewEmailTemplateRequest]) (*connect.Response[backendv1.PreviewEmailTemplateResponse], error) {
+	res, err := s.Store.PreviewEmailTemplate(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
@@ -23,3 +23,11 @@ func (s *Service) UpdateOrganizationDomains(ctx context.Context, req *connect.Re
 	}
 	return connect.NewResponse(res), nil
 }
+
+func (s *Service) VerifyOrganizationDomains(ctx context.Context, req *connect.Request[backendv1.VerifyOrganizationDomainsRequest]) (*connect.Response[backendv1.VerifyOrganizationDomainsResponse], error) {
+	res, err := s.Store.VerifyOrganizationDomains(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
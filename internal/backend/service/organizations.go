@@ -70,3 +70,12 @@ func (s *Service) EnableOrganizationLogins(ctx context.Context, req *connect.Req
 
 	return connect.NewResponse(res), nil
 }
+
+func (s *Service) ListOrganizationDescendants(ctx context.Context, req *connect.Request[backendv1.ListOrganizationDescendantsRequest]) (*connect.Response[backendv1.ListOrganizationDescendantsResponse], error) {
+	res, err := s.Store.ListOrganizationDescendants(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
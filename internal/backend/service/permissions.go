@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) CheckPermission(ctx context.Context, req *connect.Request[backendv1.CheckPermissionRequest]) (*connect.Response[backendv1.CheckPermissionResponse], error) {
+	res, err := s.Store.CheckPermission(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) ListPermittedResources(ctx context.Context, req *connect.Request[backendv1.ListPermittedResourcesRequest]) (*connect.Response[backendv1.ListPermittedResourcesResponse], error) {
+	res, err := s.Store.ListPermittedResources(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
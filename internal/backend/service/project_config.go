@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) ApplyProjectConfig(ctx context.Context, req *connect.Request[backendv1.ApplyProjectConfigRequest]) (*connect.Response[backendv1.ApplyProjectConfigResponse], error) {
+	res, err := s.Store.ApplyProjectConfig(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) ListRoleTemplates(ctx context.Context, req *connect.Request[backendv1.ListRoleTemplatesRequest]) (*connect.Response[backendv1.ListRoleTemplatesResponse], error) {
+	res, err := s.Store.ListRoleTemplates(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) GetRoleTemplate(ctx context.Context, req *connect.Request[backendv1.GetRoleTemplateRequest]) (*connect.Response[backendv1.GetRoleTemplateResponse], error) {
+	res, err := s.Store.GetRoleTemplate(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) CreateRoleTemplate(ctx context.Context, req *connect.Request[backendv1.CreateRoleTemplateRequest]) (*connect.Response[backendv1.CreateRoleTemplateResponse], error) {
+	res, err := s.Store.CreateRoleTemplate(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) UpdateRoleTemplate(ctx context.Context, req *connect.Request[backendv1.UpdateRoleTemplateRequest]) (*connect.Response[backendv1.UpdateRoleTemplateResponse], error) {
+	res, err := s.Store.UpdateRoleTemplate(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) DeleteRoleTemplate(ctx context.Context, req *connect.Request[backendv1.DeleteRoleTemplateRequest]) (*connect.Response[backendv1.DeleteRoleTemplateResponse], error) {
+	res, err := s.Store.DeleteRoleTemplate(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
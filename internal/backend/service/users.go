@@ -35,6 +35,15 @@ func (s *Service) CreateUser(ctx context.Context, req *connect.Request[backendv1
 	return connect.NewResponse(res), nil
 }
 
+func (s *Service) ImportUsers(ctx context.Context, req *connect.Request[backendv1.ImportUsersRequest]) (*connect.Response[backendv1.ImportUsersResponse], error) {
+	res, err := s.Store.ImportUsers(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
 func (s *Service) UpdateUser(ctx context.Context, req *connect.Request[backendv1.UpdateUserRequest]) (*connect.Response[backendv1.UpdateUserResponse], error) {
 	res, err := s.Store.UpdateUser(ctx, req.Msg)
 	if err != nil {
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func (s *Service) ListWebhookEndpoints(ctx context.Context, req *connect.Request[backendv1.ListWebhookEndpointsRequest]) (*connect.Response[backendv1.ListWebhookEndpointsResponse], error) {
+	res, err := s.Store.ListWebhookEndpoints(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) GetWebhookEndpoint(ctx context.Context, req *connect.Request[backendv1.GetWebhookEndpointRequest]) (*connect.Response[backendv1.GetWebhookEndpointResponse], error) {
+	res, err := s.Store.GetWebhookEndpoint(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) CreateWebhookEndpoint(ctx context.Context, req *connect.Request[backendv1.CreateWebhookEndpointRequest]) (*connect.Response[backendv1.CreateWebhookEndpointResponse], error) {
+	res, err := s.Store.CreateWebhookEndpoint(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) UpdateWebhookEndpoint(ctx context.Context, req *connect.Request[backendv1.UpdateWebhookEndpointRequest]) (*connect.Response[backendv1.UpdateWebhookEndpointResponse], error) {
+	res, err := s.Store.UpdateWebhookEndpoint(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) DeleteWebhookEndpoint(ctx context.Context, req *connect.Request[backendv1.DeleteWebhookEndpointRequest]) (*connect.Response[backendv1.DeleteWebhookEndpointResponse], error) {
+	res, err := s.Store.DeleteWebhookEndpoint(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) ListWebhookDeliveries(ctx context.Context, req *connect.Request[backendv1.ListWebhookDeliveriesRequest]) (*connect.Response[backendv1.ListWebhookDeliveriesResponse], error) {
+	res, err := s.Store.ListWebhookDeliveries(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
@@ -0,0 +1,194 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) ListAccessRequests(ctx context.Context, req *backendv1.ListAccessRequestsRequest) (*backendv1.ListAccessRequestsResponse, error) {
+	if req.OrganizationId != "" {
+		return s.listAccessRequestsByOrganizationID(ctx, req)
+	} else if req.UserId != "" {
+		return s.listAccessRequestsByUserID(ctx, req)
+	} else {
+		return nil, apierror.NewInvalidArgumentError("one of organization_id or user_id must be provided", nil)
+	}
+}
+
+func (s *Store) listAccessRequestsByOrganizationID(ctx context.Context, req *backendv1.ListAccessRequestsRequest) (*backendv1.ListAccessRequestsResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	orgID, err := idformat.Organization.Parse(req.OrganizationId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+	}
+
+	// authz
+	if _, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        orgID,
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("organization not found", fmt.Errorf("get organization by project id and id: %w", err))
+		}
+
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+
+	var startID uuid.UUID
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	qAccessRequests, err := q.ListAccessRequestsByOrganization(ctx, queries.ListAccessRequestsByOrganizationParams{
+		OrganizationID: orgID,
+		ID:             startID,
+		Limit:          int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list access requests: %w", err)
+	}
+
+	var accessRequests []*backendv1.AccessRequest
+	for _, qAccessRequest := range qAccessRequests {
+		accessRequests = append(accessRequests, parseAccessRequest(qAccessRequest))
+	}
+
+	var nextPageToken string
+	if len(accessRequests) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qAccessRequests[limit].ID)
+		accessRequests = accessRequests[:limit]
+	}
+
+	return &backendv1.ListAccessRequestsResponse{
+		AccessRequests: accessRequests,
+		NextPageToken:  nextPageToken,
+	}, nil
+}
+
+func (s *Store) listAccessRequestsByUserID(ctx context.Context, req *backendv1.ListAccessRequestsRequest) (*backendv1.ListAccessRequestsResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	userID, err := idformat.User.Parse(req.UserId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid user id", fmt.Errorf("parse user id: %w", err))
+	}
+
+	// authz
+	if _, err := q.GetUser(ctx, queries.GetUserParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        userID,
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("user not found", fmt.Errorf("get user by project id and id: %w", err))
+		}
+
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	var startID uuid.UUID
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	qAccessRequests, err := q.ListAccessRequestsByUser(ctx, queries.ListAccessRequestsByUserParams{
+		UserID: userID,
+		ID:     startID,
+		Limit:  int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list access requests: %w", err)
+	}
+
+	var accessRequests []*backendv1.AccessRequest
+	for _, qAccessRequest := range qAccessRequests {
+		accessRequests = append(accessRequests, parseAccessRequest(qAccessRequest))
+	}
+
+	var nextPageToken string
+	if len(accessRequests) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qAccessRequests[limit].ID)
+		accessRequests = accessRequests[:limit]
+	}
+
+	return &backendv1.ListAccessRequestsResponse{
+		AccessRequests: accessRequests,
+		NextPageToken:  nextPageToken,
+	}, nil
+}
+
+func (s *Store) GetAccessRequest(ctx context.Context, req *backendv1.GetAccessRequestRequest) (*backendv1.GetAccessRequestResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	id, err := idformat.AccessRequest.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid access request id", fmt.Errorf("parse access request id: %w", err))
+	}
+
+	qAccessRequest, err := q.GetAccessRequest(ctx, queries.GetAccessRequestParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        id,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("access request not found", fmt.Errorf("get access request: %w", err))
+		}
+		return nil, fmt.Errorf("get access request: %w", err)
+	}
+
+	return &backendv1.GetAccessRequestResponse{AccessRequest: parseAccessRequest(qAccessRequest)}, nil
+}
+
+func parseAccessRequest(qAccessRequest queries.AccessRequest) *backendv1.AccessRequest {
+	var status backendv1.AccessRequestStatus
+	switch qAccessRequest.Status {
+	case queries.AccessRequestStatusPending:
+		status = backendv1.AccessRequestStatus_ACCESS_REQUEST_STATUS_PENDING
+	case queries.AccessRequestStatusApproved:
+		status = backendv1.AccessRequestStatus_ACCESS_REQUEST_STATUS_APPROVED
+	case queries.AccessRequestStatusDenied:
+		status = backendv1.AccessRequestStatus_ACCESS_REQUEST_STATUS_DENIED
+	}
+
+	var reviewerUserID string
+	if qAccessRequest.ReviewerUserID != nil {
+		reviewerUserID = idformat.User.Format(*qAccessRequest.ReviewerUserID)
+	}
+
+	return &backendv1.AccessRequest{
+		Id:              idformat.AccessRequest.Format(qAccessRequest.ID),
+		CreateTime:      timestamppb.New(*qAccessRequest.CreateTime),
+		UpdateTime:      timestamppb.New(*qAccessRequest.UpdateTime),
+		UserId:          idformat.User.Format(qAccessRequest.UserID),
+		RoleId:          idformat.Role.Format(qAccessRequest.RoleID),
+		Reason:          qAccessRequest.Reason,
+		DurationSeconds: qAccessRequest.DurationSeconds,
+		Status:          status,
+		ReviewerUserId:  reviewerUserID,
+		ReviewTime:      timestampOrNil(qAccessRequest.ReviewTime),
+	}
+}
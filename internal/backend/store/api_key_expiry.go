@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+)
+
+const (
+	apiKeyExpiryBatchSize    = 100
+	apiKeyExpiryPollInterval = time.Minute
+)
+
+// RunAPIKeyExpiry clears the previous secret tokens of rotated API Keys once
+// their grace period has ended, until ctx is canceled.
+func (s *Store) RunAPIKeyExpiry(ctx context.Context) error {
+	ticker := time.NewTicker(apiKeyExpiryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.ExpireAPIKeyPreviousSecretTokens(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "expire_api_key_previous_secret_tokens_error", "err", err)
+		}
+
+		// A full batch suggests there is a backlog; keep going without waiting
+		// for the next tick.
+		if err == nil && n == apiKeyExpiryBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireAPIKeyPreviousSecretTokens clears a batch of previous secret tokens
+// whose grace period has ended, logging an audit event for each. It returns
+// the number of API Keys updated.
+//
+// AuthenticateAPIKey stops accepting a previous secret token as soon as its
+// grace period ends; this only makes the expiry visible in the audit log.
+func (s *Store) ExpireAPIKeyPreviousSecretTokens(ctx context.Context) (int, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer rollback()
+
+	qAPIKeys, err := q.ListAPIKeysWithExpiredPreviousSecretToken(ctx, apiKeyExpiryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("list api keys with expired previous secret token: %w", err)
+	}
+
+	for _, qAPIKey := range qAPIKeys {
+		auditPreviousAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, qAPIKey.ID)
+		if err != nil {
+			return 0, fmt.Errorf("get audit log api key: %w", err)
+		}
+
+		if _, err := q.ClearAPIKeyPreviousSecretToken(ctx, qAPIKey.ID); err != nil {
+			return 0, fmt.Errorf("clear api key previous secret token: %w", err)
+		}
+
+		auditAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, qAPIKey.ID)
+		if err != nil {
+			return 0, fmt.Errorf("get audit log api key: %w", err)
+		}
+
+		if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+			ProjectID: &qAPIKey.ProjectID,
+			EventName: "tesseral.api_keys.expire_previous_secret_token",
+			EventDetails: &auditlogv1.ExpireAPIKeyPreviousSecretToken{
+				ApiKey:         auditAPIKey,
+				PreviousApiKey: auditPreviousAPIKey,
+			},
+			OrganizationID: &qAPIKey.OrganizationID,
+			ResourceType:   queries.AuditLogEventResourceTypeApiKey,
+			ResourceID:     &qAPIKey.ID,
+		}); err != nil {
+			return 0, fmt.Errorf("create audit log event: %w", err)
+		}
+	}
+
+	if err := commit(); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return len(qAPIKeys), nil
+}
@@ -32,6 +32,11 @@ func (s *Store) CreateAPIKeyRoleAssignment(ctx context.Context, req *backendv1.C
 		return nil, apierror.NewInvalidArgumentError("invalid role id", fmt.Errorf("parse role id: %w", err))
 	}
 
+	expireTime, err := parseRoleAssignmentExpireTime(req.ApiKeyRoleAssignment.ExpireTime)
+	if err != nil {
+		return nil, err
+	}
+
 	qAPIKey, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
 		ID:        apiKeyID,
 		ProjectID: authn.ProjectID(ctx),
@@ -66,9 +71,10 @@ func (s *Store) CreateAPIKeyRoleAssignment(ctx context.Context, req *backendv1.C
 	}
 
 	qAPIKeyRoleAssignment, err := q.CreateAPIKeyRoleAssignment(ctx, queries.CreateAPIKeyRoleAssignmentParams{
-		ID:       uuid.New(),
-		ApiKeyID: apiKeyID,
-		RoleID:   roleID,
+		ID:         uuid.New(),
+		ApiKeyID:   apiKeyID,
+		RoleID:     roleID,
+		ExpireTime: expireTime,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create api key role assignment: %w", err)
@@ -212,8 +218,9 @@ func (s *Store) ListAPIKeyRoleAssignments(ctx context.Context, req *backendv1.Li
 
 func parseAPIKeyRoleAssignment(qAPIKeyRoleAssignment queries.ApiKeyRoleAssignment) *backendv1.APIKeyRoleAssignment {
 	return &backendv1.APIKeyRoleAssignment{
-		Id:       idformat.APIKeyRoleAssignment.Format(qAPIKeyRoleAssignment.ID),
-		ApiKeyId: idformat.APIKey.Format(qAPIKeyRoleAssignment.ApiKeyID),
-		RoleId:   idformat.Role.Format(qAPIKeyRoleAssignment.RoleID),
+		Id:         idformat.APIKeyRoleAssignment.Format(qAPIKeyRoleAssignment.ID),
+		ApiKeyId:   idformat.APIKey.Format(qAPIKeyRoleAssignment.ApiKeyID),
+		RoleId:     idformat.Role.Format(qAPIKeyRoleAssignment.RoleID),
+		ExpireTime: timestampOrNil(qAPIKeyRoleAssignment.ExpireTime),
 	}
 }
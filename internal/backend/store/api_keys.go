@@ -6,7 +6,9 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"net/netip"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,6 +25,17 @@ import (
 
 const apiKeySecretTokenSuffixLength = 4
 
+// apiKeyLastUsedDebounce is how out of date an API Key's last_used_time may
+// get before AuthenticateAPIKey writes it again.
+const apiKeyLastUsedDebounce = time.Minute
+
+// defaultAPIKeyRotationGracePeriod and maxAPIKeyRotationGracePeriod bound how
+// long an API Key's previous secret token remains valid after RotateAPIKey.
+const (
+	defaultAPIKeyRotationGracePeriod = 24 * time.Hour
+	maxAPIKeyRotationGracePeriod     = 30 * 24 * time.Hour
+)
+
 func (s *Store) CreateAPIKey(ctx context.Context, req *backendv1.CreateAPIKeyRequest) (*backendv1.CreateAPIKeyResponse, error) {
 	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
@@ -79,13 +92,25 @@ func (s *Store) CreateAPIKey(ctx context.Context, req *backendv1.CreateAPIKeyReq
 		expireTime = &formattedExpireTime
 	}
 
+	ipAllowlist, err := parseAPIKeyIPAllowlist(req.ApiKey.IpAllowlist)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAPIKeyRequestQuota(req.ApiKey.RequestQuota, req.ApiKey.RequestQuotaWindowSeconds); err != nil {
+		return nil, err
+	}
+
 	qAPIKey, err := q.CreateAPIKey(ctx, queries.CreateAPIKeyParams{
-		ID:                uuid.New(),
-		DisplayName:       req.ApiKey.DisplayName,
-		ExpireTime:        expireTime,
-		OrganizationID:    orgID,
-		SecretTokenSha256: secretTokenSHA256[:],
-		SecretTokenSuffix: &secretTokenSuffix,
+		ID:                        uuid.New(),
+		DisplayName:               req.ApiKey.DisplayName,
+		ExpireTime:                expireTime,
+		OrganizationID:            orgID,
+		SecretTokenSha256:         secretTokenSHA256[:],
+		SecretTokenSuffix:         &secretTokenSuffix,
+		IpAllowlist:               ipAllowlist,
+		RequestQuota:              req.ApiKey.RequestQuota,
+		RequestQuotaWindowSeconds: req.ApiKey.RequestQuotaWindowSeconds,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create api key: %w", err)
@@ -319,7 +344,7 @@ func (s *Store) RevokeAPIKey(ctx context.Context, req *backendv1.RevokeAPIKeyReq
 	return &backendv1.RevokeAPIKeyResponse{}, nil
 }
 
-func (s *Store) UpdateAPIKey(ctx context.Context, req *backendv1.UpdateAPIKeyRequest) (*backendv1.UpdateAPIKeyResponse, error) {
+func (s *Store) RotateAPIKey(ctx context.Context, req *backendv1.RotateAPIKeyRequest) (*backendv1.RotateAPIKeyResponse, error) {
 	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
 		return nil, err
@@ -331,6 +356,11 @@ func (s *Store) UpdateAPIKey(ctx context.Context, req *backendv1.UpdateAPIKeyReq
 		return nil, apierror.NewInvalidArgumentError("invalid api key id", fmt.Errorf("parse api key id: %w", err))
 	}
 
+	gracePeriod, err := parseAPIKeyRotationGracePeriod(req.GracePeriodSeconds)
+	if err != nil {
+		return nil, err
+	}
+
 	qPreviousAPIKey, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
 		ID:        apiKeyID,
 		ProjectID: authn.ProjectID(ctx),
@@ -342,16 +372,153 @@ func (s *Store) UpdateAPIKey(ctx context.Context, req *backendv1.UpdateAPIKeyReq
 		return nil, fmt.Errorf("get api key by id: %w", err)
 	}
 
+	if qPreviousAPIKey.SecretTokenSha256 == nil {
+		return nil, apierror.NewFailedPreconditionError("revoked api keys cannot be rotated", fmt.Errorf("api key is revoked"))
+	}
+
+	qProject, err := q.GetProjectByID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get project by id: %w", err)
+	}
+
+	if !qProject.ApiKeysEnabled {
+		return nil, apierror.NewPermissionDeniedError("api keys are not enabled for this project", fmt.Errorf("api keys not enabled for project"))
+	}
+
+	if qProject.ApiKeySecretTokenPrefix == nil || *qProject.ApiKeySecretTokenPrefix == "" {
+		return nil, apierror.NewFailedPreconditionError("api key secret token prefix is not set for this project", fmt.Errorf("api key secret token prefix not set for project"))
+	}
+
 	auditPreviousAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, qPreviousAPIKey.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get audit log api key: %w", err)
 	}
 
-	qUpdatedAPIKey, err := q.UpdateAPIKey(ctx, queries.UpdateAPIKeyParams{
-		ID:          apiKeyID,
-		DisplayName: req.ApiKey.DisplayName,
-		ProjectID:   authn.ProjectID(ctx),
+	var secretTokenValue [35]byte
+	if _, err := rand.Read(secretTokenValue[:]); err != nil {
+		return nil, fmt.Errorf("generate secret token: %w", err)
+	}
+
+	secretToken := prettysecret.Format(*qProject.ApiKeySecretTokenPrefix, secretTokenValue)
+	secretTokenSuffix := secretToken[len(secretToken)-apiKeySecretTokenSuffixLength:]
+	secretTokenSHA256 := sha256.Sum256(secretTokenValue[:])
+
+	// The previous secret token is cleared, and its expiry logged, by
+	// ExpireAPIKeyPreviousSecretTokens. With no grace period, it expires
+	// immediately.
+	previousSecretTokenExpireTime := time.Now().Add(gracePeriod)
+
+	qAPIKey, err := q.RotateAPIKey(ctx, queries.RotateAPIKeyParams{
+		ID:                            apiKeyID,
+		ProjectID:                     authn.ProjectID(ctx),
+		PreviousSecretTokenExpireTime: &previousSecretTokenExpireTime,
+		SecretTokenSha256:             secretTokenSHA256[:],
+		SecretTokenSuffix:             &secretTokenSuffix,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rotate api key: %w", err)
+	}
+
+	auditAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, qAPIKey.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit log api key: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+		EventName: "tesseral.api_keys.rotate",
+		EventDetails: &auditlogv1.RotateAPIKey{
+			ApiKey:         auditAPIKey,
+			PreviousApiKey: auditPreviousAPIKey,
+		},
+		OrganizationID: &qAPIKey.OrganizationID,
+		ResourceType:   queries.AuditLogEventResourceTypeApiKey,
+		ResourceID:     &qAPIKey.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	apiKey := parseAPIKey(qAPIKey)
+	apiKey.SecretToken = secretToken
+	return &backendv1.RotateAPIKeyResponse{
+		ApiKey: apiKey,
+	}, nil
+}
+
+func (s *Store) UpdateAPIKey(ctx context.Context, req *backendv1.UpdateAPIKeyRequest) (*backendv1.UpdateAPIKeyResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	apiKeyID, err := idformat.APIKey.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid api key id", fmt.Errorf("parse api key id: %w", err))
+	}
+
+	qPreviousAPIKey, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
+		ID:        apiKeyID,
+		ProjectID: authn.ProjectID(ctx),
 	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("api key not found", fmt.Errorf("get api key: %w", err))
+		}
+		return nil, fmt.Errorf("get api key by id: %w", err)
+	}
+
+	auditPreviousAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, qPreviousAPIKey.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit log api key: %w", err)
+	}
+
+	updates := queries.UpdateAPIKeyParams{
+		ID:                        apiKeyID,
+		DisplayName:               req.ApiKey.DisplayName,
+		ProjectID:                 authn.ProjectID(ctx),
+		IpAllowlist:               qPreviousAPIKey.IpAllowlist,
+		RequestQuota:              qPreviousAPIKey.RequestQuota,
+		RequestQuotaWindowSeconds: qPreviousAPIKey.RequestQuotaWindowSeconds,
+	}
+
+	if req.ClearIpAllowlist && len(req.ApiKey.IpAllowlist) > 0 {
+		return nil, apierror.NewInvalidArgumentError("clear_ip_allowlist may not be combined with a non-empty ip_allowlist", fmt.Errorf("clear_ip_allowlist with non-empty ip_allowlist"))
+	}
+
+	if req.ClearIpAllowlist {
+		updates.IpAllowlist = []string{}
+	}
+
+	if len(req.ApiKey.IpAllowlist) > 0 {
+		ipAllowlist, err := parseAPIKeyIPAllowlist(req.ApiKey.IpAllowlist)
+		if err != nil {
+			return nil, err
+		}
+		updates.IpAllowlist = ipAllowlist
+	}
+
+	if req.ApiKey.RequestQuotaWindowSeconds != nil {
+		updates.RequestQuotaWindowSeconds = req.ApiKey.RequestQuotaWindowSeconds
+	}
+
+	// A request quota of zero removes the API Key's quota.
+	if req.ApiKey.RequestQuota != nil {
+		updates.RequestQuota = req.ApiKey.RequestQuota
+		if *req.ApiKey.RequestQuota == 0 {
+			updates.RequestQuota = nil
+			updates.RequestQuotaWindowSeconds = nil
+		}
+	}
+
+	if err := validateAPIKeyRequestQuota(updates.RequestQuota, updates.RequestQuotaWindowSeconds); err != nil {
+		return nil, err
+	}
+
+	qUpdatedAPIKey, err := q.UpdateAPIKey(ctx, updates)
 	if err != nil {
 		return nil, fmt.Errorf("update api key: %w", err)
 	}
@@ -384,7 +551,7 @@ func (s *Store) UpdateAPIKey(ctx context.Context, req *backendv1.UpdateAPIKeyReq
 }
 
 func (s *Store) AuthenticateAPIKey(ctx context.Context, req *backendv1.AuthenticateAPIKeyRequest) (*backendv1.AuthenticateAPIKeyResponse, error) {
-	_, q, _, rollback, err := s.tx(ctx)
+	_, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -436,6 +603,55 @@ func (s *Store) AuthenticateAPIKey(ctx context.Context, req *backendv1.Authentic
 		return nil, apierror.NewPermissionDeniedError("api keys are not enabled for this organization", fmt.Errorf("api keys not enabled for organization"))
 	}
 
+	if len(qApiKeyDetails.IpAllowlist) > 0 {
+		if req.IpAddress == "" {
+			return nil, apierror.NewUnauthenticatedApiKeyError("api_key_ip_address_required", fmt.Errorf("api key has an ip allowlist but no ip address was provided"))
+		}
+
+		ipAddress, err := netip.ParseAddr(req.IpAddress)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid ip address", fmt.Errorf("parse ip address: %w", err))
+		}
+
+		if !apiKeyIPAllowed(qApiKeyDetails.IpAllowlist, ipAddress) {
+			return nil, apierror.NewUnauthenticatedApiKeyError("api_key_ip_address_not_allowed", fmt.Errorf("ip address not in api key ip allowlist: %s", ipAddress))
+		}
+	}
+
+	now := time.Now()
+
+	if qApiKeyDetails.RequestQuota != nil && qApiKeyDetails.RequestQuotaWindowSeconds != nil {
+		window := time.Duration(*qApiKeyDetails.RequestQuotaWindowSeconds) * time.Second
+		windowStart := now.Truncate(window)
+
+		if err := q.DeleteAPIKeyRequestQuotaUsageBefore(ctx, queries.DeleteAPIKeyRequestQuotaUsageBeforeParams{
+			ApiKeyID:    qApiKeyDetails.ID,
+			WindowStart: &windowStart,
+		}); err != nil {
+			return nil, fmt.Errorf("delete api key request quota usage: %w", err)
+		}
+
+		qUsage, err := q.IncrementAPIKeyRequestQuotaUsage(ctx, queries.IncrementAPIKeyRequestQuotaUsageParams{
+			ApiKeyID:    qApiKeyDetails.ID,
+			WindowStart: &windowStart,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("increment api key request quota usage: %w", err)
+		}
+
+		if qUsage.RequestCount > *qApiKeyDetails.RequestQuota {
+			return nil, apierror.NewUnauthenticatedApiKeyError("api_key_request_quota_exceeded", fmt.Errorf("api key request quota exceeded"))
+		}
+	}
+
+	if err := q.UpdateAPIKeyLastUsed(ctx, queries.UpdateAPIKeyLastUsedParams{
+		ID:                qApiKeyDetails.ID,
+		LastUsedIpAddress: refOrNil(req.IpAddress),
+		LastUsedTime:      refOrNil(now.Add(-apiKeyLastUsedDebounce)),
+	}); err != nil {
+		return nil, fmt.Errorf("update api key last used: %w", err)
+	}
+
 	// Get all actions for the api key
 	actions, err := q.GetAPIKeyActions(ctx, qApiKeyDetails.ID)
 	if err != nil {
@@ -444,23 +660,120 @@ func (s *Store) AuthenticateAPIKey(ctx context.Context, req *backendv1.Authentic
 
 	slices.Sort(actions)
 
+	var apiKeyToken string
+	var apiKeyTokenExpireTime *timestamppb.Timestamp
+	if req.IssueToken {
+		token, expireTime, err := s.issueAPIKeyToken(ctx, q, issueAPIKeyTokenParams{
+			ProjectID:      authn.ProjectID(ctx),
+			APIKeyID:       qApiKeyDetails.ID,
+			OrganizationID: qApiKeyDetails.OrganizationID,
+			Actions:        actions,
+			Now:            now,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("issue api key token: %w", err)
+		}
+
+		apiKeyToken = token
+		apiKeyTokenExpireTime = timestamppb.New(expireTime)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
 	return &backendv1.AuthenticateAPIKeyResponse{
-		ApiKeyId:       idformat.APIKey.Format(qApiKeyDetails.ID),
-		Actions:        actions,
-		OrganizationId: idformat.Organization.Format(qApiKeyDetails.OrganizationID),
+		ApiKeyId:              idformat.APIKey.Format(qApiKeyDetails.ID),
+		Actions:               actions,
+		OrganizationId:        idformat.Organization.Format(qApiKeyDetails.OrganizationID),
+		ApiKeyToken:           apiKeyToken,
+		ApiKeyTokenExpireTime: apiKeyTokenExpireTime,
 	}, nil
 }
 
 func parseAPIKey(qAPIKey queries.ApiKey) *backendv1.APIKey {
 	return &backendv1.APIKey{
-		Id:                idformat.APIKey.Format(qAPIKey.ID),
-		OrganizationId:    idformat.Organization.Format(qAPIKey.OrganizationID),
-		DisplayName:       qAPIKey.DisplayName,
-		CreateTime:        timestamppb.New(*qAPIKey.CreateTime),
-		UpdateTime:        timestamppb.New(*qAPIKey.UpdateTime),
-		ExpireTime:        timestampOrNil(qAPIKey.ExpireTime),
-		Revoked:           qAPIKey.SecretTokenSha256 == nil,
-		SecretToken:       "", // intentionally left blank
-		SecretTokenSuffix: derefOrEmpty(qAPIKey.SecretTokenSuffix),
+		Id:                            idformat.APIKey.Format(qAPIKey.ID),
+		OrganizationId:                idformat.Organization.Format(qAPIKey.OrganizationID),
+		DisplayName:                   qAPIKey.DisplayName,
+		CreateTime:                    timestamppb.New(*qAPIKey.CreateTime),
+		UpdateTime:                    timestamppb.New(*qAPIKey.UpdateTime),
+		ExpireTime:                    timestampOrNil(qAPIKey.ExpireTime),
+		Revoked:                       qAPIKey.SecretTokenSha256 == nil,
+		SecretToken:                   "", // intentionally left blank
+		SecretTokenSuffix:             derefOrEmpty(qAPIKey.SecretTokenSuffix),
+		LastUsedTime:                  timestampOrNil(qAPIKey.LastUsedTime),
+		LastUsedIpAddress:             derefOrEmpty(qAPIKey.LastUsedIpAddress),
+		IpAllowlist:                   qAPIKey.IpAllowlist,
+		RequestQuota:                  qAPIKey.RequestQuota,
+		RequestQuotaWindowSeconds:     qAPIKey.RequestQuotaWindowSeconds,
+		PreviousSecretTokenSuffix:     qAPIKey.PreviousSecretTokenSuffix,
+		PreviousSecretTokenExpireTime: timestampOrNil(qAPIKey.PreviousSecretTokenExpireTime),
+	}
+}
+
+func parseAPIKeyRotationGracePeriod(gracePeriodSeconds *int32) (time.Duration, error) {
+	if gracePeriodSeconds == nil {
+		return defaultAPIKeyRotationGracePeriod, nil
+	}
+
+	gracePeriod := time.Duration(*gracePeriodSeconds) * time.Second
+	if gracePeriod < 0 || gracePeriod > maxAPIKeyRotationGracePeriod {
+		return 0, apierror.NewInvalidArgumentError(fmt.Sprintf("grace_period_seconds must be between 0 and %d", int(maxAPIKeyRotationGracePeriod.Seconds())), fmt.Errorf("invalid grace period: %d", *gracePeriodSeconds))
+	}
+	return gracePeriod, nil
+}
+
+// parseAPIKeyIPAllowlist validates ipAllowlist and returns it in canonical
+// form. Bare IP addresses are accepted as single-address CIDR blocks.
+func parseAPIKeyIPAllowlist(ipAllowlist []string) ([]string, error) {
+	prefixes := []string{}
+	for _, s := range ipAllowlist {
+		if !strings.Contains(s, "/") {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid ip allowlist entry: %q", s), fmt.Errorf("parse ip address: %w", err))
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()).String())
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid ip allowlist entry: %q", s), fmt.Errorf("parse cidr: %w", err))
+		}
+		prefixes = append(prefixes, prefix.Masked().String())
+	}
+	return prefixes, nil
+}
+
+func apiKeyIPAllowed(ipAllowlist []string, ipAddress netip.Addr) bool {
+	ipAddress = ipAddress.Unmap()
+	for _, s := range ipAllowlist {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			continue
+		}
+		if prefix.Contains(ipAddress) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateAPIKeyRequestQuota(requestQuota, requestQuotaWindowSeconds *int32) error {
+	if requestQuota == nil {
+		if requestQuotaWindowSeconds != nil {
+			return apierror.NewInvalidArgumentError("request_quota_window_seconds requires request_quota", fmt.Errorf("request quota window set without request quota"))
+		}
+		return nil
+	}
+
+	if *requestQuota <= 0 {
+		return apierror.NewInvalidArgumentError("request_quota must be positive", fmt.Errorf("invalid request quota: %d", *requestQuota))
+	}
+	if requestQuotaWindowSeconds == nil || *requestQuotaWindowSeconds <= 0 {
+		return apierror.NewInvalidArgumentError("request_quota_window_seconds must be positive", fmt.Errorf("invalid request quota window"))
 	}
+	return nil
 }
@@ -1,13 +1,19 @@
 package store
 
 import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"connectrpc.com/connect"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/ujwt"
 )
 
 func TestCreateAPIKey_ApiKeysEnabled(t *testing.T) {
@@ -298,3 +304,370 @@ func TestAuthenticateAPIKey_InvalidSecretToken(t *testing.T) {
 	require.ErrorAs(t, err, &connectErr)
 	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
 }
+
+func TestAuthenticateAPIKey_RecordsLastUsed(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, createResp.ApiKey.LastUsedTime)
+
+	_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+		IpAddress:   "203.0.113.7",
+	})
+	require.NoError(t, err)
+
+	getResp, err := u.Store.GetAPIKey(ctx, &backendv1.GetAPIKeyRequest{Id: createResp.ApiKey.Id})
+	require.NoError(t, err)
+	require.NotNil(t, getResp.ApiKey.LastUsedTime)
+	require.Equal(t, "203.0.113.7", getResp.ApiKey.LastUsedIpAddress)
+}
+
+func TestAuthenticateAPIKey_IPAllowlist(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+			IpAllowlist:    []string{"203.0.113.17/24", "2001:db8::1"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"203.0.113.0/24", "2001:db8::1/128"}, createResp.ApiKey.IpAllowlist)
+
+	_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+		IpAddress:   "203.0.113.200",
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+		IpAddress:   "2001:db8::1",
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+		IpAddress:   "198.51.100.1",
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestCreateAPIKey_InvalidIPAllowlist(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	_, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+			IpAllowlist:    []string{"not-a-cidr"},
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestUpdateAPIKey_IPAllowlist(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+			IpAllowlist:    []string{"203.0.113.0/24"},
+		},
+	})
+	require.NoError(t, err)
+
+	// an empty allowlist leaves the existing one in place
+	updateResp, err := u.Store.UpdateAPIKey(ctx, &backendv1.UpdateAPIKeyRequest{
+		Id:     createResp.ApiKey.Id,
+		ApiKey: &backendv1.APIKey{DisplayName: "key1"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"203.0.113.0/24"}, updateResp.ApiKey.IpAllowlist)
+
+	_, err = u.Store.UpdateAPIKey(ctx, &backendv1.UpdateAPIKeyRequest{
+		Id:               createResp.ApiKey.Id,
+		ApiKey:           &backendv1.APIKey{DisplayName: "key1", IpAllowlist: []string{"198.51.100.0/24"}},
+		ClearIpAllowlist: true,
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	updateResp, err = u.Store.UpdateAPIKey(ctx, &backendv1.UpdateAPIKeyRequest{
+		Id:               createResp.ApiKey.Id,
+		ApiKey:           &backendv1.APIKey{DisplayName: "key1"},
+		ClearIpAllowlist: true,
+	})
+	require.NoError(t, err)
+	require.Empty(t, updateResp.ApiKey.IpAllowlist)
+
+	_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+		IpAddress:   "198.51.100.1",
+	})
+	require.NoError(t, err)
+}
+
+func TestAuthenticateAPIKey_RequestQuota(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId:            orgID,
+			DisplayName:               "key1",
+			RequestQuota:              refOrNil(int32(2)),
+			RequestQuotaWindowSeconds: refOrNil(int32(3600)),
+		},
+	})
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err := u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+			SecretToken: createResp.ApiKey.SecretToken,
+		})
+		require.NoError(t, err)
+	}
+
+	_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	// Removing the quota lets the API Key authenticate again.
+	_, err = u.Store.UpdateAPIKey(ctx, &backendv1.UpdateAPIKeyRequest{
+		Id: createResp.ApiKey.Id,
+		ApiKey: &backendv1.APIKey{
+			DisplayName:  "key1",
+			RequestQuota: refOrNil(int32(0)),
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+	})
+	require.NoError(t, err)
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+		},
+	})
+	require.NoError(t, err)
+
+	rotateResp, err := u.Store.RotateAPIKey(ctx, &backendv1.RotateAPIKeyRequest{
+		Id: createResp.ApiKey.Id,
+	})
+	require.NoError(t, err)
+	require.Equal(t, createResp.ApiKey.Id, rotateResp.ApiKey.Id)
+	require.NotEqual(t, createResp.ApiKey.SecretToken, rotateResp.ApiKey.SecretToken)
+	require.Equal(t, createResp.ApiKey.SecretTokenSuffix, rotateResp.ApiKey.GetPreviousSecretTokenSuffix())
+	require.NotNil(t, rotateResp.ApiKey.PreviousSecretTokenExpireTime)
+
+	// Both the old and new secret tokens authenticate during the grace period.
+	for _, secretToken := range []string{createResp.ApiKey.SecretToken, rotateResp.ApiKey.SecretToken} {
+		authResp, err := u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+			SecretToken: secretToken,
+		})
+		require.NoError(t, err)
+		require.Equal(t, createResp.ApiKey.Id, authResp.ApiKeyId)
+	}
+}
+
+func TestRotateAPIKey_NoGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+		},
+	})
+	require.NoError(t, err)
+
+	rotateResp, err := u.Store.RotateAPIKey(ctx, &backendv1.RotateAPIKeyRequest{
+		Id:                 createResp.ApiKey.Id,
+		GracePeriodSeconds: refOrNil(int32(0)),
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	_, err = u.Store.ExpireAPIKeyPreviousSecretTokens(ctx)
+	require.NoError(t, err)
+
+	getResp, err := u.Store.GetAPIKey(ctx, &backendv1.GetAPIKeyRequest{Id: rotateResp.ApiKey.Id})
+	require.NoError(t, err)
+	require.Nil(t, getResp.ApiKey.PreviousSecretTokenSuffix)
+	require.Nil(t, getResp.ApiKey.PreviousSecretTokenExpireTime)
+
+	listResp, err := u.Store.ConsoleListCustomAuditLogEvents(ctx, &backendv1.ConsoleListAuditLogEventsRequest{
+		OrganizationId: orgID,
+		ResourceType:   backendv1.AuditLogEventResourceType_AUDIT_LOG_EVENT_RESOURCE_TYPE_API_KEY,
+		ResourceId:     rotateResp.ApiKey.Id,
+	})
+	require.NoError(t, err)
+
+	var eventNames []string
+	for _, event := range listResp.AuditLogEvents {
+		eventNames = append(eventNames, event.EventName)
+	}
+	require.Contains(t, eventNames, "tesseral.api_keys.rotate")
+	require.Contains(t, eventNames, "tesseral.api_keys.expire_previous_secret_token")
+}
+
+func TestRotateAPIKey_Revoked(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.RevokeAPIKey(ctx, &backendv1.RevokeAPIKeyRequest{Id: createResp.ApiKey.Id})
+	require.NoError(t, err)
+
+	_, err = u.Store.RotateAPIKey(ctx, &backendv1.RotateAPIKeyRequest{Id: createResp.ApiKey.Id})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+}
+
+func TestAuthenticateAPIKey_IssueToken(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+		},
+	})
+	require.NoError(t, err)
+
+	authResp, err := u.Store.AuthenticateAPIKey(ctx, &backendv1.AuthenticateAPIKeyRequest{
+		SecretToken: createResp.ApiKey.SecretToken,
+		IssueToken:  true,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, authResp.ApiKeyToken)
+	require.NotNil(t, authResp.ApiKeyTokenExpireTime)
+
+	kid, err := ujwt.KeyID(authResp.ApiKeyToken)
+	require.NoError(t, err)
+	sessionSigningKeyID, err := idformat.SessionSigningKey.Parse(kid)
+	require.NoError(t, err)
+
+	var publicKeyBytes []byte
+	err = u.Environment.DB.QueryRow(t.Context(), "SELECT public_key FROM session_signing_keys WHERE id = $1", uuid.UUID(sessionSigningKeyID)).Scan(&publicKeyBytes)
+	require.NoError(t, err)
+	pub, err := x509.ParsePKIXPublicKey(publicKeyBytes)
+	require.NoError(t, err)
+
+	iss := fmt.Sprintf("https://%s.tesseral.app", strings.ReplaceAll(u.ProjectID, "_", "-"))
+
+	var claims struct {
+		Sub    string `json:"sub"`
+		ApiKey struct {
+			ID string `json:"id"`
+		} `json:"apiKey"`
+		Organization struct {
+			ID string `json:"id"`
+		} `json:"organization"`
+	}
+	require.NoError(t, ujwt.Claims(pub.(*ecdsa.PublicKey), iss+"/api-keys", time.Now(), &claims, authResp.ApiKeyToken))
+	require.Equal(t, createResp.ApiKey.Id, claims.Sub)
+	require.Equal(t, createResp.ApiKey.Id, claims.ApiKey.ID)
+	require.Equal(t, orgID, claims.Organization.ID)
+
+	// An API Key token is not a valid access token.
+	require.Error(t, ujwt.Claims(pub.(*ecdsa.PublicKey), iss, time.Now(), &claims, authResp.ApiKeyToken))
+}
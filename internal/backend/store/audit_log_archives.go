@@ -0,0 +1,486 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/auditlog"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/s3role"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	auditLogRetentionBatchSize    = 1000
+	auditLogRetentionPollInterval = time.Minute
+)
+
+// RunAuditLogRetention archives audit log events once their retention period
+// ends, until ctx is canceled.
+func (s *Store) RunAuditLogRetention(ctx context.Context) error {
+	ticker := time.NewTicker(auditLogRetentionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.ArchiveExpiredAuditLogEvents(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "archive_expired_audit_log_events_error", "err", err)
+		}
+
+		// A full batch suggests there is a backlog; keep going without waiting
+		// for the next tick.
+		if err == nil && n >= auditLogRetentionBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ArchiveExpiredAuditLogEvents archives a batch of expired audit log events
+// from each Project with a retention period. It returns the size of the largest
+// batch archived. A Project that fails to archive is logged and skipped, so
+// that it does not hold up the others.
+//
+// An event expires once it is older than its Organization's retention period,
+// or else its Project's, capped at the Project's entitlement. Expired events
+// are written to S3, grouped by Organization, and then deleted. Each archived
+// event leaves behind its link in the audit log chain, so that the chain can
+// still be verified.
+func (s *Store) ArchiveExpiredAuditLogEvents(ctx context.Context) (int, error) {
+	qProjects, err := s.q.ListAuditLogRetentionProjects(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list audit log retention projects: %w", err)
+	}
+
+	var maxN int
+	for _, qProject := range qProjects {
+		n, err := s.archiveProjectExpiredAuditLogEvents(ctx, qProject)
+		if err != nil {
+			slog.ErrorContext(ctx, "archive_project_expired_audit_log_events_error",
+				"project_id", idformat.Project.Format(qProject.ID),
+				"err", err)
+			continue
+		}
+
+		maxN = max(maxN, n)
+	}
+
+	return maxN, nil
+}
+
+func (s *Store) archiveProjectExpiredAuditLogEvents(ctx context.Context, qProject queries.Project) (int, error) {
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer rollback()
+
+	qEvents, err := q.ListExpiredAuditLogEvents(ctx, queries.ListExpiredAuditLogEventsParams{
+		ProjectID: qProject.ID,
+		Limit:     auditLogRetentionBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("list expired audit log events: %w", err)
+	}
+
+	if len(qEvents) == 0 {
+		return 0, nil
+	}
+
+	bucket := s.s3UserContentBucketName
+	var roleARN *string
+	if qProject.AuditLogArchiveS3Bucket != nil {
+		// Never write to a customer's bucket with Tesseral's own credentials.
+		if qProject.AuditLogArchiveS3RoleArn == nil {
+			return 0, fmt.Errorf("audit log archive s3 bucket has no role arn")
+		}
+
+		bucket = *qProject.AuditLogArchiveS3Bucket
+		roleARN = qProject.AuditLogArchiveS3RoleArn
+	}
+
+	// Group events by Organization, keeping them in event_time order. Events
+	// without an Organization are grouped under uuid.Nil.
+	var orgIDs []uuid.UUID
+	orgEvents := map[uuid.UUID][]queries.AuditLogEvent{}
+	for _, qEvent := range qEvents {
+		var orgID uuid.UUID
+		if qEvent.OrganizationID != nil {
+			orgID = *qEvent.OrganizationID
+		}
+
+		if _, ok := orgEvents[orgID]; !ok {
+			orgIDs = append(orgIDs, orgID)
+		}
+		orgEvents[orgID] = append(orgEvents[orgID], qEvent)
+	}
+
+	for _, orgID := range orgIDs {
+		if err := s.archiveAuditLogEvents(ctx, q, bucket, roleARN, orgEvents[orgID]); err != nil {
+			return 0, fmt.Errorf("archive audit log events: %w", err)
+		}
+	}
+
+	var eventIDs []uuid.UUID
+	for _, qEvent := range qEvents {
+		eventIDs = append(eventIDs, qEvent.ID)
+	}
+
+	if err := q.DeleteAuditLogEvents(ctx, eventIDs); err != nil {
+		return 0, fmt.Errorf("delete audit log events: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+
+	slog.InfoContext(ctx, "archive_audit_log_events",
+		"project_id", idformat.Project.Format(qProject.ID),
+		"event_count", len(qEvents))
+
+	return len(qEvents), nil
+}
+
+// archiveAuditLogEvents writes events, which must belong to the same Project
+// and Organization, to a new Audit Log Archive in bucket, through roleARN if
+// set. It does not delete them.
+func (s *Store) archiveAuditLogEvents(ctx context.Context, q *queries.Queries, bucket string, roleARN *string, qEvents []queries.AuditLogEvent) error {
+	projectID := qEvents[0].ProjectID
+	archiveID := uuidv7.NewWithTime(time.Now())
+
+	var (
+		events         []auditlog.ArchivedEvent
+		chainSequences []int64
+		chainHashes    [][]byte
+	)
+	for _, qEvent := range qEvents {
+		events = append(events, archivedAuditLogEvent(qEvent))
+
+		if qEvent.ChainSequence != nil {
+			chainSequences = append(chainSequences, *qEvent.ChainSequence)
+			chainHashes = append(chainHashes, qEvent.ChainHash)
+		}
+	}
+
+	data, err := auditlog.MarshalArchive(events)
+	if err != nil {
+		return fmt.Errorf("marshal archive: %w", err)
+	}
+
+	// If the transaction fails after this, the object is left behind without
+	// an archive record. The events are archived again under a new key.
+	key := fmt.Sprintf("audit-log-archives/%s/%s.jsonl.gz", idformat.Project.Format(projectID), idformat.AuditLogArchive.Format(archiveID))
+	if _, err := s.s3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &bucket,
+		Key:         &key,
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/gzip"),
+	}, s.auditLogArchiveS3Options(projectID, roleARN)...); err != nil {
+		return fmt.Errorf("put object: %w", err)
+	}
+
+	if _, err := q.CreateAuditLogArchive(ctx, queries.CreateAuditLogArchiveParams{
+		ID:             archiveID,
+		ProjectID:      projectID,
+		OrganizationID: qEvents[0].OrganizationID,
+		S3Bucket:       bucket,
+		S3Key:          key,
+		S3RoleArn:      roleARN,
+		EventCount:     int32(len(qEvents)),
+		FirstEventTime: qEvents[0].EventTime,
+		LastEventTime:  qEvents[len(qEvents)-1].EventTime,
+	}); err != nil {
+		return fmt.Errorf("create audit log archive: %w", err)
+	}
+
+	if len(chainSequences) > 0 {
+		if err := q.CreateAuditLogArchivedChainLinks(ctx, queries.CreateAuditLogArchivedChainLinksParams{
+			ProjectID:         projectID,
+			ChainSequences:    chainSequences,
+			ChainHashes:       chainHashes,
+			AuditLogArchiveID: archiveID,
+		}); err != nil {
+			return fmt.Errorf("create audit log archived chain links: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) ListAuditLogArchives(ctx context.Context, req *backendv1.ListAuditLogArchivesRequest) (*backendv1.ListAuditLogArchivesResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	startID := uuid.Max
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	var qAuditLogArchives []queries.AuditLogArchive
+	if req.OrganizationId != "" {
+		orgID, err := idformat.Organization.Parse(req.OrganizationId)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+		}
+
+		qAuditLogArchives, err = q.ListAuditLogArchivesByOrganization(ctx, queries.ListAuditLogArchivesByOrganizationParams{
+			ProjectID:      authn.ProjectID(ctx),
+			OrganizationID: (*uuid.UUID)(&orgID),
+			ID:             startID,
+			Limit:          int32(limit + 1),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list audit log archives by organization: %w", err)
+		}
+	} else {
+		qAuditLogArchives, err = q.ListAuditLogArchives(ctx, queries.ListAuditLogArchivesParams{
+			ProjectID: authn.ProjectID(ctx),
+			ID:        startID,
+			Limit:     int32(limit + 1),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list audit log archives: %w", err)
+		}
+	}
+
+	var auditLogArchives []*backendv1.AuditLogArchive
+	for _, qAuditLogArchive := range qAuditLogArchives {
+		auditLogArchives = append(auditLogArchives, parseAuditLogArchive(qAuditLogArchive))
+	}
+
+	var nextPageToken string
+	if len(auditLogArchives) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qAuditLogArchives[limit].ID)
+		auditLogArchives = auditLogArchives[:limit]
+	}
+
+	return &backendv1.ListAuditLogArchivesResponse{
+		AuditLogArchives: auditLogArchives,
+		NextPageToken:    nextPageToken,
+	}, nil
+}
+
+func (s *Store) RestoreAuditLogArchive(ctx context.Context, req *backendv1.RestoreAuditLogArchiveRequest) (*backendv1.RestoreAuditLogArchiveResponse, error) {
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	archiveID, err := idformat.AuditLogArchive.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid audit log archive id", fmt.Errorf("parse audit log archive id: %w", err))
+	}
+
+	qAuditLogArchive, err := q.GetAuditLogArchiveForUpdate(ctx, queries.GetAuditLogArchiveForUpdateParams{
+		ID:        archiveID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("audit log archive not found", fmt.Errorf("get audit log archive: %w", err))
+		}
+
+		return nil, fmt.Errorf("get audit log archive: %w", err)
+	}
+
+	if qAuditLogArchive.RestoreTime != nil {
+		return nil, apierror.NewFailedPreconditionError("audit log archive has already been restored", nil)
+	}
+
+	// Archives in a customer's bucket are only ever read through their role.
+	if qAuditLogArchive.S3Bucket != s.s3UserContentBucketName && qAuditLogArchive.S3RoleArn == nil {
+		return nil, apierror.NewFailedPreconditionError("audit log archive was written without an s3 role arn and cannot be restored", nil)
+	}
+
+	getObjectRes, err := s.s3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &qAuditLogArchive.S3Bucket,
+		Key:    &qAuditLogArchive.S3Key,
+	}, s.auditLogArchiveS3Options(qAuditLogArchive.ProjectID, qAuditLogArchive.S3RoleArn)...)
+	if err != nil {
+		return nil, fmt.Errorf("get object: %w", err)
+	}
+	defer getObjectRes.Body.Close()
+
+	data, err := io.ReadAll(getObjectRes.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read object: %w", err)
+	}
+
+	events, err := auditlog.UnmarshalArchive(data)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal archive: %w", err)
+	}
+
+	qArchivedChainLinks, err := q.ListAuditLogArchivedChainLinksByArchive(ctx, qAuditLogArchive.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list audit log archived chain links by archive: %w", err)
+	}
+
+	if err := validateArchivedAuditLogEvents(qAuditLogArchive, qArchivedChainLinks, events); err != nil {
+		return nil, apierror.NewFailedPreconditionError("audit log archive does not match the audit log", fmt.Errorf("validate archived audit log events: %w", err))
+	}
+
+	// Restored events take back their chain positions from the archived links,
+	// so VerifyAuditLogChain checks their content again.
+	if err := q.DeleteAuditLogArchivedChainLinks(ctx, qAuditLogArchive.ID); err != nil {
+		return nil, fmt.Errorf("delete audit log archived chain links: %w", err)
+	}
+
+	for _, event := range events {
+		if err := q.RestoreAuditLogEvent(ctx, queries.RestoreAuditLogEventParams{
+			ID:                            event.ID,
+			ProjectID:                     event.ProjectID,
+			OrganizationID:                event.OrganizationID,
+			ActorUserID:                   event.ActorUserID,
+			ActorSessionID:                event.ActorSessionID,
+			ActorApiKeyID:                 event.ActorAPIKeyID,
+			ActorConsoleUserID:            event.ActorConsoleUserID,
+			ActorConsoleSessionID:         event.ActorConsoleSessionID,
+			ActorBackendApiKeyID:          event.ActorBackendAPIKeyID,
+			ActorIntermediateSessionID:    event.ActorIntermediateSessionID,
+			ActorScimApiKeyID:             event.ActorSCIMAPIKeyID,
+			ResourceType:                  (*queries.AuditLogEventResourceType)(event.ResourceType),
+			ResourceID:                    event.ResourceID,
+			EventName:                     event.EventName,
+			EventTime:                     &event.EventTime,
+			EventDetails:                  event.EventDetails,
+			ChainSequence:                 event.ChainSequence,
+			ChainHash:                     event.ChainHash,
+			RestoredFromAuditLogArchiveID: &qAuditLogArchive.ID,
+		}); err != nil {
+			return nil, fmt.Errorf("restore audit log event: %w", err)
+		}
+	}
+
+	qUpdatedAuditLogArchive, err := q.UpdateAuditLogArchiveRestoreTime(ctx, qAuditLogArchive.ID)
+	if err != nil {
+		return nil, fmt.Errorf("update audit log archive restore time: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.RestoreAuditLogArchiveResponse{
+		AuditLogArchive: parseAuditLogArchive(qUpdatedAuditLogArchive),
+	}, nil
+}
+
+// validateArchivedAuditLogEvents checks that events read from an archive are
+// the ones that were archived: they belong to the archive's Project, and they
+// occupy exactly the chain positions the archive left behind.
+func validateArchivedAuditLogEvents(qAuditLogArchive queries.AuditLogArchive, qArchivedChainLinks []queries.AuditLogArchivedChainLink, events []auditlog.ArchivedEvent) error {
+	if len(events) != int(qAuditLogArchive.EventCount) {
+		return fmt.Errorf("archive has %d events, want %d", len(events), qAuditLogArchive.EventCount)
+	}
+
+	chainHashes := map[int64][]byte{}
+	for _, qArchivedChainLink := range qArchivedChainLinks {
+		chainHashes[qArchivedChainLink.ChainSequence] = qArchivedChainLink.ChainHash
+	}
+
+	var chainedEventCount int
+	for _, event := range events {
+		if event.ProjectID != qAuditLogArchive.ProjectID {
+			return fmt.Errorf("event %s belongs to another project", idformat.AuditLogEvent.Format(event.ID))
+		}
+
+		if event.ChainSequence == nil {
+			continue
+		}
+
+		chainHash, ok := chainHashes[*event.ChainSequence]
+		if !ok || !bytes.Equal(chainHash, event.ChainHash) {
+			return fmt.Errorf("event %s does not match its chain link", idformat.AuditLogEvent.Format(event.ID))
+		}
+		chainedEventCount++
+	}
+
+	if chainedEventCount != len(qArchivedChainLinks) {
+		return fmt.Errorf("archive has %d chained events, want %d", chainedEventCount, len(qArchivedChainLinks))
+	}
+
+	return nil
+}
+
+// auditLogArchiveS3Options returns the options for accessing an archive bucket
+// on behalf of projectID: through roleARN if set, or else with Tesseral's own
+// credentials.
+func (s *Store) auditLogArchiveS3Options(projectID uuid.UUID, roleARN *string) []func(*s3.Options) {
+	if roleARN == nil {
+		return nil
+	}
+
+	return []func(*s3.Options){s3role.WithRole(s.sts, *roleARN, projectID)}
+}
+
+func archivedAuditLogEvent(qEvent queries.AuditLogEvent) auditlog.ArchivedEvent {
+	return auditlog.ArchivedEvent{
+		ID:                         qEvent.ID,
+		ProjectID:                  qEvent.ProjectID,
+		OrganizationID:             qEvent.OrganizationID,
+		ActorUserID:                qEvent.ActorUserID,
+		ActorSessionID:             qEvent.ActorSessionID,
+		ActorAPIKeyID:              qEvent.ActorApiKeyID,
+		ActorConsoleUserID:         qEvent.ActorConsoleUserID,
+		ActorConsoleSessionID:      qEvent.ActorConsoleSessionID,
+		ActorBackendAPIKeyID:       qEvent.ActorBackendApiKeyID,
+		ActorIntermediateSessionID: qEvent.ActorIntermediateSessionID,
+		ActorSCIMAPIKeyID:          qEvent.ActorScimApiKeyID,
+		ResourceType:               (*string)(qEvent.ResourceType),
+		ResourceID:                 qEvent.ResourceID,
+		EventName:                  qEvent.EventName,
+		EventTime:                  *qEvent.EventTime,
+		EventDetails:               qEvent.EventDetails,
+		ChainSequence:              qEvent.ChainSequence,
+		ChainHash:                  qEvent.ChainHash,
+	}
+}
+
+func parseAuditLogArchive(qAuditLogArchive queries.AuditLogArchive) *backendv1.AuditLogArchive {
+	var organizationID string
+	if qAuditLogArchive.OrganizationID != nil {
+		organizationID = idformat.Organization.Format(*qAuditLogArchive.OrganizationID)
+	}
+
+	var restoreTime *timestamppb.Timestamp
+	if qAuditLogArchive.RestoreTime != nil {
+		restoreTime = timestamppb.New(*qAuditLogArchive.RestoreTime)
+	}
+
+	return &backendv1.AuditLogArchive{
+		Id:             idformat.AuditLogArchive.Format(qAuditLogArchive.ID),
+		OrganizationId: organizationID,
+		S3Bucket:       qAuditLogArchive.S3Bucket,
+		S3Key:          qAuditLogArchive.S3Key,
+		EventCount:     qAuditLogArchive.EventCount,
+		FirstEventTime: timestamppb.New(*qAuditLogArchive.FirstEventTime),
+		LastEventTime:  timestamppb.New(*qAuditLogArchive.LastEventTime),
+		CreateTime:     timestamppb.New(*qAuditLogArchive.CreateTime),
+		RestoreTime:    restoreTime,
+	}
+}
@@ -0,0 +1,178 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func backdateAuditLogEvent(t *testing.T, u *testUtil, id string, days int) {
+	eventID, err := idformat.AuditLogEvent.Parse(id)
+	require.NoError(t, err)
+
+	_, err = u.Environment.DB.Exec(t.Context(), `UPDATE audit_log_events SET event_time = now() - make_interval(days => $2) WHERE id = $1`, uuid.UUID(eventID), days)
+	require.NoError(t, err)
+}
+
+func countAuditLogEvents(t *testing.T, u *testUtil, ids []string) int {
+	var eventIDs []uuid.UUID
+	for _, id := range ids {
+		eventID, err := idformat.AuditLogEvent.Parse(id)
+		require.NoError(t, err)
+		eventIDs = append(eventIDs, eventID)
+	}
+
+	var count int
+	err := u.Environment.DB.QueryRow(t.Context(), `SELECT count(*) FROM audit_log_events WHERE id = ANY($1)`, eventIDs).Scan(&count)
+	require.NoError(t, err)
+	return count
+}
+
+func TestArchiveExpiredAuditLogEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	ids := createChainTestAuditLogEvents(t, u, 3)
+
+	_, err := u.Store.UpdateProject(ctx, &backendv1.UpdateProjectRequest{
+		Project: &backendv1.Project{AuditLogRetentionDays: refOrNil(int32(30))},
+	})
+	require.NoError(t, err)
+
+	backdateAuditLogEvent(t, u, ids[0], 60)
+	backdateAuditLogEvent(t, u, ids[1], 45)
+
+	_, err = u.Store.ArchiveExpiredAuditLogEvents(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, countAuditLogEvents(t, u, ids[:2]))
+	require.Equal(t, 1, countAuditLogEvents(t, u, ids[2:]))
+
+	listRes, err := u.Store.ListAuditLogArchives(ctx, &backendv1.ListAuditLogArchivesRequest{})
+	require.NoError(t, err)
+	require.Len(t, listRes.AuditLogArchives, 1)
+	require.Equal(t, int32(2), listRes.AuditLogArchives[0].EventCount)
+	require.NotEmpty(t, listRes.AuditLogArchives[0].OrganizationId)
+	require.Nil(t, listRes.AuditLogArchives[0].RestoreTime)
+
+	// Archived events leave their links in the chain behind.
+	verifyRes, err := u.Store.VerifyAuditLogChain(ctx, &backendv1.VerifyAuditLogChainRequest{})
+	require.NoError(t, err)
+	require.Empty(t, verifyRes.Problems)
+
+	restoreRes, err := u.Store.RestoreAuditLogArchive(ctx, &backendv1.RestoreAuditLogArchiveRequest{
+		Id: listRes.AuditLogArchives[0].Id,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, restoreRes.AuditLogArchive.RestoreTime)
+	require.Equal(t, 3, countAuditLogEvents(t, u, ids))
+
+	verifyRes, err = u.Store.VerifyAuditLogChain(ctx, &backendv1.VerifyAuditLogChainRequest{})
+	require.NoError(t, err)
+	require.Empty(t, verifyRes.Problems)
+
+	// Restored events are not archived again.
+	_, err = u.Store.ArchiveExpiredAuditLogEvents(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, countAuditLogEvents(t, u, ids))
+
+	_, err = u.Store.RestoreAuditLogArchive(ctx, &backendv1.RestoreAuditLogArchiveRequest{
+		Id: listRes.AuditLogArchives[0].Id,
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+}
+
+func TestArchiveExpiredAuditLogEvents_OrganizationOverride(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	ids := createChainTestAuditLogEvents(t, u, 1)
+
+	eventID, err := idformat.AuditLogEvent.Parse(ids[0])
+	require.NoError(t, err)
+
+	var orgID uuid.UUID
+	err = u.Environment.DB.QueryRow(ctx, `SELECT organization_id FROM audit_log_events WHERE id = $1`, uuid.UUID(eventID)).Scan(&orgID)
+	require.NoError(t, err)
+
+	_, err = u.Store.UpdateProject(ctx, &backendv1.UpdateProjectRequest{
+		Project: &backendv1.Project{AuditLogRetentionDays: refOrNil(int32(30))},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.UpdateOrganization(ctx, &backendv1.UpdateOrganizationRequest{
+		Id:           idformat.Organization.Format(orgID),
+		Organization: &backendv1.Organization{AuditLogRetentionDays: refOrNil(int32(7))},
+	})
+	require.NoError(t, err)
+
+	backdateAuditLogEvent(t, u, ids[0], 10)
+
+	_, err = u.Store.ArchiveExpiredAuditLogEvents(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, countAuditLogEvents(t, u, ids))
+
+	listRes, err := u.Store.ListAuditLogArchives(ctx, &backendv1.ListAuditLogArchivesRequest{
+		OrganizationId: idformat.Organization.Format(orgID),
+	})
+	require.NoError(t, err)
+	require.Len(t, listRes.AuditLogArchives, 1)
+}
+
+func TestUpdateProject_AuditLogRetentionDaysEntitlement(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+
+	_, err = u.Environment.DB.Exec(ctx, `UPDATE projects SET entitled_audit_log_retention_days = 90 WHERE id = $1`, uuid.UUID(projectID))
+	require.NoError(t, err)
+
+	_, err = u.Store.UpdateProject(ctx, &backendv1.UpdateProjectRequest{
+		Project: &backendv1.Project{AuditLogRetentionDays: refOrNil(int32(365))},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+
+	res, err := u.Store.UpdateProject(ctx, &backendv1.UpdateProjectRequest{
+		Project: &backendv1.Project{AuditLogRetentionDays: refOrNil(int32(90))},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(90), res.Project.GetAuditLogRetentionDays())
+	require.Equal(t, int32(90), res.Project.GetEntitledAuditLogRetentionDays())
+}
+
+func TestUpdateProject_AuditLogArchiveS3Bucket(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	for _, project := range []*backendv1.Project{
+		{AuditLogArchiveS3Bucket: refOrNil("customer-audit-logs")},
+		{AuditLogArchiveS3Bucket: refOrNil("customer-audit-logs"), AuditLogArchiveS3RoleArn: refOrNil("arn:aws:iam::123456789012:user/tesseral")},
+		{AuditLogArchiveS3Bucket: refOrNil(u.Environment.S3.UserContentBucketName), AuditLogArchiveS3RoleArn: refOrNil("arn:aws:iam::123456789012:role/tesseral")},
+	} {
+		_, err := u.Store.UpdateProject(ctx, &backendv1.UpdateProjectRequest{Project: project})
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr)
+		require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+	}
+
+	res, err := u.Store.UpdateProject(ctx, &backendv1.UpdateProjectRequest{
+		Project: &backendv1.Project{
+			AuditLogArchiveS3Bucket:  refOrNil("customer-audit-logs"),
+			AuditLogArchiveS3RoleArn: refOrNil("arn:aws:iam::123456789012:role/tesseral"),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "customer-audit-logs", res.Project.GetAuditLogArchiveS3Bucket())
+	require.Equal(t, "arn:aws:iam::123456789012:role/tesseral", res.Project.GetAuditLogArchiveS3RoleArn())
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	auditlogstore "github.com/tesseral-labs/tesseral/internal/auditlog/store"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+// verifyAuditLogChainPageSize is the number of audit log events verified per
+// VerifyAuditLogChain call.
+const verifyAuditLogChainPageSize = 1000
+
+func (s *Store) VerifyAuditLogChain(ctx context.Context, req *backendv1.VerifyAuditLogChainRequest) (*backendv1.VerifyAuditLogChainResponse, error) {
+	tx, _, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	var after auditlogstore.ChainPosition
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &after); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	res, err := s.auditlogStore.VerifyChain(ctx, tx, auditlogstore.VerifyChainParams{
+		ProjectID: authn.ProjectID(ctx),
+		After:     after,
+		Limit:     verifyAuditLogChainPageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("verify audit log chain: %w", err)
+	}
+
+	var problems []*backendv1.AuditLogChainProblem
+	for _, problem := range res.Problems {
+		problems = append(problems, parseAuditLogChainProblem(problem))
+	}
+
+	var nextPageToken string
+	if res.Next != nil {
+		nextPageToken = s.pageEncoder.Marshal(res.Next)
+	}
+
+	return &backendv1.VerifyAuditLogChainResponse{
+		VerifiedEventCount: int32(res.VerifiedEventCount),
+		Problems:           problems,
+		NextPageToken:      nextPageToken,
+	}, nil
+}
+
+func parseAuditLogChainProblem(problem auditlogstore.ChainProblem) *backendv1.AuditLogChainProblem {
+	var problemType backendv1.AuditLogChainProblemType
+	switch problem.Type {
+	case auditlogstore.ChainProblemMissingEvents:
+		problemType = backendv1.AuditLogChainProblemType_AUDIT_LOG_CHAIN_PROBLEM_TYPE_MISSING_EVENTS
+	case auditlogstore.ChainProblemHashMismatch:
+		problemType = backendv1.AuditLogChainProblemType_AUDIT_LOG_CHAIN_PROBLEM_TYPE_HASH_MISMATCH
+	case auditlogstore.ChainProblemHeadMismatch:
+		problemType = backendv1.AuditLogChainProblemType_AUDIT_LOG_CHAIN_PROBLEM_TYPE_HEAD_MISMATCH
+	case auditlogstore.ChainProblemUnchainedEvent:
+		problemType = backendv1.AuditLogChainProblemType_AUDIT_LOG_CHAIN_PROBLEM_TYPE_UNCHAINED_EVENT
+	}
+
+	var auditLogEventID string
+	if problem.AuditLogEventID != nil {
+		auditLogEventID = idformat.AuditLogEvent.Format(*problem.AuditLogEventID)
+	}
+
+	return &backendv1.AuditLogChainProblem{
+		Type:            problemType,
+		Sequence:        problem.Sequence,
+		AuditLogEventId: auditLogEventID,
+	}
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func createChainTestAuditLogEvents(t *testing.T, u *testUtil, n int) []string {
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	var ids []string
+	for i := range n {
+		eventDetails, err := structpb.NewStruct(map[string]any{"index": i})
+		require.NoError(t, err)
+
+		res, err := u.Store.CreateCustomAuditLogEvent(t.Context(), &backendv1.CreateAuditLogEventRequest{
+			AuditLogEvent: &backendv1.AuditLogEvent{
+				OrganizationId: orgID,
+				EventName:      fmt.Sprintf("custom.event.%d", i),
+				EventTime:      timestamppb.New(time.Now()),
+				EventDetails:   eventDetails,
+			},
+		})
+		require.NoError(t, err)
+		ids = append(ids, res.AuditLogEvent.Id)
+	}
+	return ids
+}
+
+func TestVerifyAuditLogChain_Valid(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	createChainTestAuditLogEvents(t, u, 3)
+
+	res, err := u.Store.VerifyAuditLogChain(ctx, &backendv1.VerifyAuditLogChainRequest{})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, res.VerifiedEventCount, int32(3))
+	require.Empty(t, res.Problems)
+	require.Empty(t, res.NextPageToken)
+}
+
+func TestVerifyAuditLogChain_ModifiedEvent(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	ids := createChainTestAuditLogEvents(t, u, 3)
+
+	eventID, err := idformat.AuditLogEvent.Parse(ids[1])
+	require.NoError(t, err)
+
+	_, err = u.Environment.DB.Exec(ctx, `UPDATE audit_log_events SET event_details = '{"index": 100}' WHERE id = $1`, uuid.UUID(eventID))
+	require.NoError(t, err)
+
+	res, err := u.Store.VerifyAuditLogChain(ctx, &backendv1.VerifyAuditLogChainRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.Problems, 1)
+	require.Equal(t, backendv1.AuditLogChainProblemType_AUDIT_LOG_CHAIN_PROBLEM_TYPE_HASH_MISMATCH, res.Problems[0].Type)
+	require.Equal(t, ids[1], res.Problems[0].AuditLogEventId)
+}
+
+func TestVerifyAuditLogChain_DeletedEvent(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	ids := createChainTestAuditLogEvents(t, u, 3)
+
+	eventID, err := idformat.AuditLogEvent.Parse(ids[1])
+	require.NoError(t, err)
+
+	_, err = u.Environment.DB.Exec(ctx, `DELETE FROM audit_log_events WHERE id = $1`, uuid.UUID(eventID))
+	require.NoError(t, err)
+
+	res, err := u.Store.VerifyAuditLogChain(ctx, &backendv1.VerifyAuditLogChainRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.Problems, 1)
+	require.Equal(t, backendv1.AuditLogChainProblemType_AUDIT_LOG_CHAIN_PROBLEM_TYPE_MISSING_EVENTS, res.Problems[0].Type)
+	require.Equal(t, ids[2], res.Problems[0].AuditLogEventId)
+}
+
+func TestVerifyAuditLogChain_DeletedLastEvent(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	ids := createChainTestAuditLogEvents(t, u, 3)
+
+	eventID, err := idformat.AuditLogEvent.Parse(ids[2])
+	require.NoError(t, err)
+
+	_, err = u.Environment.DB.Exec(ctx, `DELETE FROM audit_log_events WHERE id = $1`, uuid.UUID(eventID))
+	require.NoError(t, err)
+
+	res, err := u.Store.VerifyAuditLogChain(ctx, &backendv1.VerifyAuditLogChainRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.Problems, 1)
+	require.Equal(t, backendv1.AuditLogChainProblemType_AUDIT_LOG_CHAIN_PROBLEM_TYPE_HEAD_MISMATCH, res.Problems[0].Type)
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/auditlogfilter"
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
@@ -71,7 +72,7 @@ func (s *Store) CreateCustomAuditLogEvent(ctx context.Context, req *backendv1.Cr
 		eventDetails = b
 	}
 
-	qEvent, err := q.CreateAuditLogEvent(ctx, queries.CreateAuditLogEventParams{
+	qEventParams := queries.CreateAuditLogEventParams{
 		ID:             eventID,
 		ProjectID:      authn.ProjectID(ctx),
 		OrganizationID: deriveEventContextRes.OrganizationID,
@@ -81,7 +82,13 @@ func (s *Store) CreateCustomAuditLogEvent(ctx context.Context, req *backendv1.Cr
 		EventName:      eventName,
 		EventTime:      &eventTime,
 		EventDetails:   eventDetails,
-	})
+	}
+
+	if err := s.chainAuditLogEvent(ctx, q, &qEventParams, eventDetails); err != nil {
+		return nil, fmt.Errorf("chain audit log event: %w", err)
+	}
+
+	qEvent, err := q.CreateAuditLogEvent(ctx, qEventParams)
 	if err != nil {
 		return nil, fmt.Errorf("create audit log event: %w", err)
 	}
@@ -110,11 +117,44 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 		return nil, fmt.Errorf("unmarshal page token: %w", err)
 	}
 
+	listParams, eventDetailsFilter, err := consoleListAuditLogEventsParams(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
 	limit := 10
+	listParams.ID = startID
+	listParams.Limit = int32(limit + 1)
+
+	qAuditLogEvents, err := consoleListAuditLogEvents(ctx, q, listParams, eventDetailsFilter)
+	if err != nil {
+		return nil, fmt.Errorf("list audit log events: %w", err)
+	}
+
+	var auditLogEvents []*backendv1.ConsoleAuditLogEvent
+	for _, qAuditLogEvent := range qAuditLogEvents {
+		event := parseConsoleAuditLogEvent(qAuditLogEvent)
+		auditLogEvents = append(auditLogEvents, event)
+	}
+
+	var nextPageToken string
+	if len(qAuditLogEvents) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qAuditLogEvents[limit].ID)
+		auditLogEvents = auditLogEvents[:limit]
+	}
+
+	return &backendv1.ConsoleListAuditLogEventsResponse{
+		AuditLogEvents: auditLogEvents,
+		NextPageToken:  nextPageToken,
+	}, nil
+}
+
+// consoleListAuditLogEventsParams returns the filters of req as query params,
+// and the compiled event details filter, if any. Callers set the pagination
+// params.
+func consoleListAuditLogEventsParams(ctx context.Context, req *backendv1.ConsoleListAuditLogEventsRequest) (queries.ConsoleListAuditLogEventsParams, string, error) {
 	listParams := queries.ConsoleListAuditLogEventsParams{
 		ProjectID: authn.ProjectID(ctx),
-		ID:        startID,
-		Limit:     int32(limit + 1),
 	}
 
 	if req.ResourceType != backendv1.AuditLogEventResourceType_AUDIT_LOG_EVENT_RESOURCE_TYPE_UNSPECIFIED && req.ResourceId != "" {
@@ -123,7 +163,7 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 			resourceType := queries.AuditLogEventResourceTypeApiKey
 			apiKeyID, err := idformat.APIKey.Parse(req.ResourceId)
 			if err != nil {
-				return nil, apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse api key id: %w", err))
+				return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse api key id: %w", err))
 			}
 			listParams.ResourceID = (*uuid.UUID)(&apiKeyID)
 			listParams.ResourceType = &resourceType
@@ -131,7 +171,7 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 			resourceType := queries.AuditLogEventResourceTypeOrganization
 			orgID, err := idformat.Organization.Parse(req.ResourceId)
 			if err != nil {
-				return nil, apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse organization id: %w", err))
+				return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse organization id: %w", err))
 			}
 			listParams.ResourceID = (*uuid.UUID)(&orgID)
 			listParams.ResourceType = &resourceType
@@ -139,7 +179,7 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 			resourceType := queries.AuditLogEventResourceTypePasskey
 			passkeyID, err := idformat.Passkey.Parse(req.ResourceId)
 			if err != nil {
-				return nil, apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse passkey id: %w", err))
+				return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse passkey id: %w", err))
 			}
 			listParams.ResourceID = (*uuid.UUID)(&passkeyID)
 			listParams.ResourceType = &resourceType
@@ -147,15 +187,31 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 			resourceType := queries.AuditLogEventResourceTypeRole
 			roleID, err := idformat.Role.Parse(req.ResourceId)
 			if err != nil {
-				return nil, apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse role id: %w", err))
+				return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse role id: %w", err))
 			}
 			listParams.ResourceID = (*uuid.UUID)(&roleID)
 			listParams.ResourceType = &resourceType
+		case backendv1.AuditLogEventResourceType_AUDIT_LOG_EVENT_RESOURCE_TYPE_ROLE_TEMPLATE:
+			resourceType := queries.AuditLogEventResourceTypeRoleTemplate
+			roleTemplateID, err := idformat.RoleTemplate.Parse(req.ResourceId)
+			if err != nil {
+				return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse role template id: %w", err))
+			}
+			listParams.ResourceID = (*uuid.UUID)(&roleTemplateID)
+			listParams.ResourceType = &resourceType
+		case backendv1.AuditLogEventResourceType_AUDIT_LOG_EVENT_RESOURCE_TYPE_WEBHOOK_ENDPOINT:
+			resourceType := queries.AuditLogEventResourceTypeWebhookEndpoint
+			webhookEndpointID, err := idformat.WebhookEndpoint.Parse(req.ResourceId)
+			if err != nil {
+				return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse webhook endpoint id: %w", err))
+			}
+			listParams.ResourceID = (*uuid.UUID)(&webhookEndpointID)
+			listParams.ResourceType = &resourceType
 		case backendv1.AuditLogEventResourceType_AUDIT_LOG_EVENT_RESOURCE_TYPE_SAML_CONNECTION:
 			resourceType := queries.AuditLogEventResourceTypeSamlConnection
 			samlConnectionID, err := idformat.SAMLConnection.Parse(req.ResourceId)
 			if err != nil {
-				return nil, apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse saml connection id: %w", err))
+				return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse saml connection id: %w", err))
 			}
 			listParams.ResourceID = (*uuid.UUID)(&samlConnectionID)
 			listParams.ResourceType = &resourceType
@@ -163,7 +219,7 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 			resourceType := queries.AuditLogEventResourceTypeOidcConnection
 			oidcConnectionID, err := idformat.OIDCConnection.Parse(req.ResourceId)
 			if err != nil {
-				return nil, apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse oidc connection id: %w", err))
+				return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse oidc connection id: %w", err))
 			}
 			listParams.ResourceID = (*uuid.UUID)(&oidcConnectionID)
 			listParams.ResourceType = &resourceType
@@ -171,7 +227,7 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 			resourceType := queries.AuditLogEventResourceTypeScimApiKey
 			scimAPIKeyID, err := idformat.SCIMAPIKey.Parse(req.ResourceId)
 			if err != nil {
-				return nil, apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse scim api key id: %w", err))
+				return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse scim api key id: %w", err))
 			}
 			listParams.ResourceID = (*uuid.UUID)(&scimAPIKeyID)
 			listParams.ResourceType = &resourceType
@@ -179,7 +235,7 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 			resourceType := queries.AuditLogEventResourceTypeSession
 			sessionID, err := idformat.Session.Parse(req.ResourceId)
 			if err != nil {
-				return nil, apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse session id: %w", err))
+				return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse session id: %w", err))
 			}
 			listParams.ResourceID = (*uuid.UUID)(&sessionID)
 			listParams.ResourceType = &resourceType
@@ -187,7 +243,7 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 			resourceType := queries.AuditLogEventResourceTypeUserInvite
 			inviteID, err := idformat.UserInvite.Parse(req.ResourceId)
 			if err != nil {
-				return nil, apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse user invite id: %w", err))
+				return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse user invite id: %w", err))
 			}
 			listParams.ResourceID = (*uuid.UUID)(&inviteID)
 			listParams.ResourceType = &resourceType
@@ -195,19 +251,19 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 			resourceType := queries.AuditLogEventResourceTypeUser
 			userID, err := idformat.User.Parse(req.ResourceId)
 			if err != nil {
-				return nil, apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse user id: %w", err))
+				return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid resource id", fmt.Errorf("parse user id: %w", err))
 			}
 			listParams.ResourceID = (*uuid.UUID)(&userID)
 			listParams.ResourceType = &resourceType
 		default:
-			return nil, apierror.NewInvalidArgumentError("invalid resource_type", fmt.Errorf("unknown resource type: %s", req.ResourceType))
+			return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid resource_type", fmt.Errorf("unknown resource type: %s", req.ResourceType))
 		}
 	}
 
 	if req.OrganizationId != "" {
 		orgID, err := idformat.Organization.Parse(req.OrganizationId)
 		if err != nil {
-			return nil, apierror.NewInvalidArgumentError("invalid organization_id", fmt.Errorf("parse organization id: %w", err))
+			return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid organization_id", fmt.Errorf("parse organization id: %w", err))
 		}
 		listParams.OrganizationID = (*uuid.UUID)(&orgID)
 	}
@@ -215,7 +271,7 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 	if req.ActorUserId != "" {
 		userID, err := idformat.User.Parse(req.ActorUserId)
 		if err != nil {
-			return nil, apierror.NewInvalidArgumentError("invalid user_id", fmt.Errorf("parse user id: %w", err))
+			return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid user_id", fmt.Errorf("parse user id: %w", err))
 		}
 		listParams.ActorUserID = (*uuid.UUID)(&userID)
 	}
@@ -223,7 +279,7 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 	if req.ActorSessionId != "" {
 		sessionID, err := idformat.Session.Parse(req.ActorSessionId)
 		if err != nil {
-			return nil, apierror.NewInvalidArgumentError("invalid session_id", fmt.Errorf("parse session id: %w", err))
+			return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid session_id", fmt.Errorf("parse session id: %w", err))
 		}
 		listParams.ActorSessionID = (*uuid.UUID)(&sessionID)
 	}
@@ -231,7 +287,7 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 	if req.ActorApiKeyId != "" {
 		apiKeyID, err := idformat.APIKey.Parse(req.ActorApiKeyId)
 		if err != nil {
-			return nil, apierror.NewInvalidArgumentError("invalid api_key_id", fmt.Errorf("parse api key id: %w", err))
+			return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid api_key_id", fmt.Errorf("parse api key id: %w", err))
 		}
 		listParams.ActorApiKeyID = (*uuid.UUID)(&apiKeyID)
 	}
@@ -239,7 +295,7 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 	if req.ActorBackendApiKeyId != "" {
 		backendApiKeyID, err := idformat.BackendAPIKey.Parse(req.ActorBackendApiKeyId)
 		if err != nil {
-			return nil, apierror.NewInvalidArgumentError("invalid backend_api_key_id", fmt.Errorf("parse backend api key id: %w", err))
+			return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid backend_api_key_id", fmt.Errorf("parse backend api key id: %w", err))
 		}
 		listParams.ActorBackendApiKeyID = (*uuid.UUID)(&backendApiKeyID)
 	}
@@ -247,7 +303,7 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 	if req.ActorScimApiKeyId != "" {
 		scimApiKeyID, err := idformat.SCIMAPIKey.Parse(req.ActorScimApiKeyId)
 		if err != nil {
-			return nil, apierror.NewInvalidArgumentError("invalid scim_api_key_id", fmt.Errorf("parse scim api key id: %w", err))
+			return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError("invalid scim_api_key_id", fmt.Errorf("parse scim api key id: %w", err))
 		}
 		listParams.ActorScimApiKeyID = (*uuid.UUID)(&scimApiKeyID)
 	}
@@ -266,27 +322,45 @@ func (s *Store) ConsoleListCustomAuditLogEvents(ctx context.Context, req *backen
 		listParams.EventName = &req.FilterEventName
 	}
 
-	qAuditLogEvents, err := q.ConsoleListAuditLogEvents(ctx, listParams)
-	if err != nil {
-		return nil, fmt.Errorf("list audit log events: %w", err)
-	}
-
-	var auditLogEvents []*backendv1.ConsoleAuditLogEvent
-	for _, qAuditLogEvent := range qAuditLogEvents {
-		event := parseConsoleAuditLogEvent(qAuditLogEvent)
-		auditLogEvents = append(auditLogEvents, event)
+	var eventDetailsFilter string
+	if req.FilterEventDetails != "" {
+		var err error
+		eventDetailsFilter, err = auditlogfilter.Compile(req.FilterEventDetails)
+		if err != nil {
+			return queries.ConsoleListAuditLogEventsParams{}, "", apierror.NewInvalidArgumentError(fmt.Sprintf("invalid filter_event_details: %s", err), fmt.Errorf("compile event details filter: %w", err))
+		}
 	}
 
-	var nextPageToken string
-	if len(qAuditLogEvents) == limit+1 {
-		nextPageToken = s.pageEncoder.Marshal(qAuditLogEvents[limit].ID)
-		auditLogEvents = auditLogEvents[:limit]
-	}
+	return listParams, eventDetailsFilter, nil
+}
 
-	return &backendv1.ConsoleListAuditLogEventsResponse{
-		AuditLogEvents: auditLogEvents,
-		NextPageToken:  nextPageToken,
-	}, nil
+// consoleListAuditLogEvents lists audit log events matching listParams and,
+// if not empty, eventDetailsFilter.
+//
+// Filtering on event details is a separate query, rather than an optional
+// condition, so that postgres can use the GIN index on event_details.
+func consoleListAuditLogEvents(ctx context.Context, q *queries.Queries, listParams queries.ConsoleListAuditLogEventsParams, eventDetailsFilter string) ([]queries.AuditLogEvent, error) {
+	if eventDetailsFilter == "" {
+		return q.ConsoleListAuditLogEvents(ctx, listParams)
+	}
+
+	return q.ConsoleListAuditLogEventsWithEventDetailsFilter(ctx, queries.ConsoleListAuditLogEventsWithEventDetailsFilterParams{
+		Limit:                listParams.Limit,
+		ProjectID:            listParams.ProjectID,
+		OrganizationID:       listParams.OrganizationID,
+		StartTime:            listParams.StartTime,
+		EndTime:              listParams.EndTime,
+		EventName:            listParams.EventName,
+		ActorUserID:          listParams.ActorUserID,
+		ActorSessionID:       listParams.ActorSessionID,
+		ActorApiKeyID:        listParams.ActorApiKeyID,
+		ActorBackendApiKeyID: listParams.ActorBackendApiKeyID,
+		ActorScimApiKeyID:    listParams.ActorScimApiKeyID,
+		ResourceType:         listParams.ResourceType,
+		ResourceID:           listParams.ResourceID,
+		EventDetailsFilter:   eventDetailsFilter,
+		ID:                   listParams.ID,
+	})
 }
 
 func (s *Store) ConsoleListAuditLogEventNames(ctx context.Context, req *backendv1.ConsoleListAuditLogEventNamesRequest) (*backendv1.ConsoleListAuditLogEventNamesResponse, error) {
@@ -326,6 +400,12 @@ func (s *Store) ConsoleListAuditLogEventNames(ctx context.Context, req *backendv
 		case backendv1.AuditLogEventResourceType_AUDIT_LOG_EVENT_RESOURCE_TYPE_ROLE:
 			resourceType := queries.AuditLogEventResourceTypeRole
 			listParams.ResourceType = &resourceType
+		case backendv1.AuditLogEventResourceType_AUDIT_LOG_EVENT_RESOURCE_TYPE_ROLE_TEMPLATE:
+			resourceType := queries.AuditLogEventResourceTypeRoleTemplate
+			listParams.ResourceType = &resourceType
+		case backendv1.AuditLogEventResourceType_AUDIT_LOG_EVENT_RESOURCE_TYPE_WEBHOOK_ENDPOINT:
+			resourceType := queries.AuditLogEventResourceTypeWebhookEndpoint
+			listParams.ResourceType = &resourceType
 		case backendv1.AuditLogEventResourceType_AUDIT_LOG_EVENT_RESOURCE_TYPE_SAML_CONNECTION:
 			resourceType := queries.AuditLogEventResourceTypeSamlConnection
 			listParams.ResourceType = &resourceType
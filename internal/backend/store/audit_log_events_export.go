@@ -0,0 +1,205 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/google/uuid"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	auditLogEventsExportBatchSize = 1000
+	auditLogEventsExportMaxEvents = 100_000
+	auditLogEventsExportURLExpiry = 15 * time.Minute
+)
+
+// ConsoleExportAuditLogEvents writes the audit log events matching a
+// ConsoleListAuditLogEvents filter to a file, and returns a short-lived URL to
+// download it from.
+func (s *Store) ConsoleExportAuditLogEvents(ctx context.Context, req *backendv1.ConsoleExportAuditLogEventsRequest) (*backendv1.ConsoleExportAuditLogEventsResponse, error) {
+	var (
+		contentType string
+		extension   string
+	)
+	switch req.Format {
+	case backendv1.AuditLogEventsExportFormat_AUDIT_LOG_EVENTS_EXPORT_FORMAT_CSV:
+		contentType, extension = "text/csv", "csv"
+	case backendv1.AuditLogEventsExportFormat_AUDIT_LOG_EVENTS_EXPORT_FORMAT_JSONL:
+		contentType, extension = "application/x-ndjson", "jsonl"
+	default:
+		return nil, apierror.NewInvalidArgumentError("format must be csv or jsonl", fmt.Errorf("unknown export format: %s", req.Format))
+	}
+
+	filter := req.Filter
+	if filter == nil {
+		filter = &backendv1.ConsoleListAuditLogEventsRequest{}
+	}
+
+	listParams, eventDetailsFilter, err := consoleListAuditLogEventsParams(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	qAuditLogEvents, truncated, err := s.listAuditLogEventsForExport(ctx, listParams, eventDetailsFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	var auditLogEvents []*backendv1.ConsoleAuditLogEvent
+	for _, qAuditLogEvent := range qAuditLogEvents {
+		auditLogEvents = append(auditLogEvents, parseConsoleAuditLogEvent(qAuditLogEvent))
+	}
+
+	var data []byte
+	switch req.Format {
+	case backendv1.AuditLogEventsExportFormat_AUDIT_LOG_EVENTS_EXPORT_FORMAT_CSV:
+		data, err = marshalAuditLogEventsCSV(auditLogEvents)
+	case backendv1.AuditLogEventsExportFormat_AUDIT_LOG_EVENTS_EXPORT_FORMAT_JSONL:
+		data, err = marshalAuditLogEventsJSONL(auditLogEvents)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("marshal audit log events: %w", err)
+	}
+
+	// The key is unguessable, and the object is only readable through the
+	// presigned URL returned below.
+	key := fmt.Sprintf("audit-log-exports/%s/%s.%s", idformat.Project.Format(authn.ProjectID(ctx)), uuid.New(), extension)
+	if _, err := s.s3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:             &s.s3UserContentBucketName,
+		Key:                &key,
+		Body:               bytes.NewReader(data),
+		ContentType:        &contentType,
+		ContentDisposition: aws.String(fmt.Sprintf("attachment; filename=\"audit-log-events.%s\"", extension)),
+	}); err != nil {
+		return nil, fmt.Errorf("put object: %w", err)
+	}
+
+	presignRes, err := s.s3PresignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: &s.s3UserContentBucketName,
+		Key:    &key,
+	}, func(opts *s3.PresignOptions) {
+		opts.Expires = auditLogEventsExportURLExpiry
+	})
+	if err != nil {
+		return nil, fmt.Errorf("presign get object: %w", err)
+	}
+
+	return &backendv1.ConsoleExportAuditLogEventsResponse{
+		DownloadUrl: presignRes.URL,
+		EventCount:  int32(len(qAuditLogEvents)),
+		Truncated:   truncated,
+	}, nil
+}
+
+// listAuditLogEventsForExport returns up to auditLogEventsExportMaxEvents
+// audit log events matching listParams and eventDetailsFilter, newest first,
+// and whether there were more.
+func (s *Store) listAuditLogEventsForExport(ctx context.Context, listParams queries.ConsoleListAuditLogEventsParams, eventDetailsFilter string) ([]queries.AuditLogEvent, bool, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rollback()
+
+	var qAuditLogEvents []queries.AuditLogEvent
+	listParams.ID = uuid.Max
+	for {
+		limit := min(auditLogEventsExportBatchSize, auditLogEventsExportMaxEvents-len(qAuditLogEvents))
+		listParams.Limit = int32(limit + 1)
+
+		qBatch, err := consoleListAuditLogEvents(ctx, q, listParams, eventDetailsFilter)
+		if err != nil {
+			return nil, false, fmt.Errorf("list audit log events: %w", err)
+		}
+
+		if len(qBatch) <= limit {
+			return append(qAuditLogEvents, qBatch...), false, nil
+		}
+
+		qAuditLogEvents = append(qAuditLogEvents, qBatch[:limit]...)
+		if len(qAuditLogEvents) == auditLogEventsExportMaxEvents {
+			return qAuditLogEvents, true, nil
+		}
+
+		listParams.ID = qBatch[limit].ID
+	}
+}
+
+var auditLogEventsCSVHeader = []string{
+	"id",
+	"event_time",
+	"event_name",
+	"organization_id",
+	"actor_user_id",
+	"actor_session_id",
+	"actor_api_key_id",
+	"actor_backend_api_key_id",
+	"actor_intermediate_session_id",
+	"actor_scim_api_key_id",
+	"actor_console_user_id",
+	"actor_console_session_id",
+	"event_details",
+}
+
+func marshalAuditLogEventsCSV(auditLogEvents []*backendv1.ConsoleAuditLogEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(auditLogEventsCSVHeader); err != nil {
+		return nil, fmt.Errorf("write header: %w", err)
+	}
+
+	for _, event := range auditLogEvents {
+		eventDetails, err := protojson.Marshal(event.EventDetails)
+		if err != nil {
+			return nil, fmt.Errorf("marshal event details: %w", err)
+		}
+
+		if err := w.Write([]string{
+			event.Id,
+			event.EventTime.AsTime().Format(time.RFC3339Nano),
+			event.EventName,
+			event.OrganizationId,
+			event.ActorUserId,
+			event.ActorSessionId,
+			event.ActorApiKeyId,
+			event.ActorBackendApiKeyId,
+			event.ActorIntermediateSessionId,
+			event.ActorScimApiKeyId,
+			event.ActorConsoleUserId,
+			event.ActorConsoleSessionId,
+			string(eventDetails),
+		}); err != nil {
+			return nil, fmt.Errorf("write row: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("flush: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func marshalAuditLogEventsJSONL(auditLogEvents []*backendv1.ConsoleAuditLogEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, event := range auditLogEvents {
+		line, err := protojson.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("marshal event: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func createExportTestAuditLogEvents(t *testing.T, u *testUtil) string {
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	for _, eventName := range []string{"custom.event.created", "custom.event.updated"} {
+		eventDetails, err := structpb.NewStruct(map[string]any{"user": map[string]any{"email": "alice@example.com"}})
+		require.NoError(t, err)
+
+		_, err = u.Store.CreateCustomAuditLogEvent(t.Context(), &backendv1.CreateAuditLogEventRequest{
+			AuditLogEvent: &backendv1.AuditLogEvent{
+				OrganizationId: orgID,
+				EventName:      eventName,
+				EventDetails:   eventDetails,
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	return orgID
+}
+
+func downloadAuditLogEventsExport(t *testing.T, url string) []byte {
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return body
+}
+
+func TestConsoleExportAuditLogEvents_CSV(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := createExportTestAuditLogEvents(t, u)
+
+	res, err := u.Store.ConsoleExportAuditLogEvents(ctx, &backendv1.ConsoleExportAuditLogEventsRequest{
+		Filter: &backendv1.ConsoleListAuditLogEventsRequest{
+			OrganizationId:     orgID,
+			FilterEventDetails: `details.user.email = "alice@example.com"`,
+		},
+		Format: backendv1.AuditLogEventsExportFormat_AUDIT_LOG_EVENTS_EXPORT_FORMAT_CSV,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), res.EventCount)
+	require.False(t, res.Truncated)
+
+	records, err := csv.NewReader(bytes.NewReader(downloadAuditLogEventsExport(t, res.DownloadUrl))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, auditLogEventsCSVHeader, records[0])
+
+	// Newest first.
+	require.Equal(t, "custom.event.updated", records[1][2])
+	require.Equal(t, "custom.event.created", records[2][2])
+	require.Equal(t, orgID, records[2][3])
+}
+
+func TestConsoleExportAuditLogEvents_JSONL(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := createExportTestAuditLogEvents(t, u)
+
+	res, err := u.Store.ConsoleExportAuditLogEvents(ctx, &backendv1.ConsoleExportAuditLogEventsRequest{
+		Filter: &backendv1.ConsoleListAuditLogEventsRequest{
+			OrganizationId:  orgID,
+			FilterEventName: "custom.event.created",
+		},
+		Format: backendv1.AuditLogEventsExportFormat_AUDIT_LOG_EVENTS_EXPORT_FORMAT_JSONL,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), res.EventCount)
+
+	scanner := bufio.NewScanner(bytes.NewReader(downloadAuditLogEventsExport(t, res.DownloadUrl)))
+	var lines int
+	for scanner.Scan() {
+		var event map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		require.Equal(t, "custom.event.created", event["eventName"])
+		lines++
+	}
+	require.Equal(t, 1, lines)
+}
+
+func TestConsoleExportAuditLogEvents_InvalidFormat(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.ConsoleExportAuditLogEvents(ctx, &backendv1.ConsoleExportAuditLogEventsRequest{})
+	require.Error(t, err)
+}
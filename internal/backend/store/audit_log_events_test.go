@@ -850,3 +850,68 @@ func TestConsoleListAuditLogEventNames_ResourceTypeUnspecified(t *testing.T) {
 	})
 	require.Error(t, err)
 }
+
+func TestConsoleListCustomAuditLogEvents_FilterEventDetails(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	for _, details := range []map[string]any{
+		{"user": map[string]any{"email": "alice@example.com"}, "actions": []any{"admin", "read"}},
+		{"user": map[string]any{"email": "bob@example.com"}, "actions": []any{"read"}},
+	} {
+		eventDetails, err := structpb.NewStruct(details)
+		require.NoError(t, err)
+
+		_, err = u.Store.CreateCustomAuditLogEvent(ctx, &backendv1.CreateAuditLogEventRequest{
+			AuditLogEvent: &backendv1.AuditLogEvent{
+				OrganizationId: orgID,
+				EventName:      "custom.event.created",
+				EventDetails:   eventDetails,
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		filter string
+		want   int
+	}{
+		{`details.user.email = "alice@example.com"`, 1},
+		{`details.user.email != "alice@example.com"`, 1},
+		{`details.actions contains "admin"`, 1},
+		{`details.actions contains "read"`, 2},
+		{`details.user.email contains "@example.com"`, 2},
+		{`details.user.email = "alice@example.com" or details.user.email = "bob@example.com"`, 2},
+		{`not details.actions contains "admin"`, 1},
+		{`details.user exists`, 2},
+		{`details.role exists`, 0},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.filter, func(t *testing.T) {
+			resp, err := u.Store.ConsoleListCustomAuditLogEvents(ctx, &backendv1.ConsoleListAuditLogEventsRequest{
+				OrganizationId:     orgID,
+				FilterEventDetails: tt.filter,
+			})
+			require.NoError(t, err)
+			require.Len(t, resp.AuditLogEvents, tt.want)
+		})
+	}
+}
+
+func TestConsoleListCustomAuditLogEvents_InvalidEventDetailsFilter(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.ConsoleListCustomAuditLogEvents(ctx, &backendv1.ConsoleListAuditLogEventsRequest{
+		FilterEventDetails: `details.user.email = `,
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
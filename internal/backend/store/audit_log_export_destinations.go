@@ -0,0 +1,426 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/s3role"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) ListAuditLogExportDestinations(ctx context.Context, req *backendv1.ListAuditLogExportDestinationsRequest) (*backendv1.ListAuditLogExportDestinationsResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	var startID uuid.UUID
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	var qAuditLogExportDestinations []queries.AuditLogExportDestination
+	if req.OrganizationId != "" {
+		orgID, err := idformat.Organization.Parse(req.OrganizationId)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+		}
+
+		qAuditLogExportDestinations, err = q.ListAuditLogExportDestinationsByOrganization(ctx, queries.ListAuditLogExportDestinationsByOrganizationParams{
+			ProjectID:      authn.ProjectID(ctx),
+			OrganizationID: (*uuid.UUID)(&orgID),
+			ID:             startID,
+			Limit:          int32(limit + 1),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list audit log export destinations by organization: %w", err)
+		}
+	} else {
+		qAuditLogExportDestinations, err = q.ListAuditLogExportDestinations(ctx, queries.ListAuditLogExportDestinationsParams{
+			ProjectID: authn.ProjectID(ctx),
+			ID:        startID,
+			Limit:     int32(limit + 1),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list audit log export destinations: %w", err)
+		}
+	}
+
+	var auditLogExportDestinations []*backendv1.AuditLogExportDestination
+	for _, qAuditLogExportDestination := range qAuditLogExportDestinations {
+		auditLogExportDestinations = append(auditLogExportDestinations, parseAuditLogExportDestination(qAuditLogExportDestination))
+	}
+
+	var nextPageToken string
+	if len(auditLogExportDestinations) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qAuditLogExportDestinations[limit].ID)
+		auditLogExportDestinations = auditLogExportDestinations[:limit]
+	}
+
+	return &backendv1.ListAuditLogExportDestinationsResponse{
+		AuditLogExportDestinations: auditLogExportDestinations,
+		NextPageToken:              nextPageToken,
+	}, nil
+}
+
+func (s *Store) GetAuditLogExportDestination(ctx context.Context, req *backendv1.GetAuditLogExportDestinationRequest) (*backendv1.GetAuditLogExportDestinationResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qAuditLogExportDestination, err := getAuditLogExportDestination(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &backendv1.GetAuditLogExportDestinationResponse{AuditLogExportDestination: parseAuditLogExportDestination(*qAuditLogExportDestination)}, nil
+}
+
+func (s *Store) CreateAuditLogExportDestination(ctx context.Context, req *backendv1.CreateAuditLogExportDestinationRequest) (*backendv1.CreateAuditLogExportDestinationResponse, error) {
+	destination := req.AuditLogExportDestination
+	if destination.DisplayName == "" {
+		return nil, apierror.NewInvalidArgumentError("display_name is required", fmt.Errorf("display_name is required"))
+	}
+
+	params := queries.CreateAuditLogExportDestinationParams{
+		ID:          uuid.New(),
+		ProjectID:   authn.ProjectID(ctx),
+		DisplayName: destination.DisplayName,
+		Disabled:    derefOrEmpty(destination.Disabled),
+	}
+
+	var signingSecret string
+	switch destination.Type {
+	case backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_HTTPS:
+		if err := validateAuditLogExportDestinationHTTPSURL(destination.HttpsUrl); err != nil {
+			return nil, err
+		}
+
+		var err error
+		signingSecret, err = webhooks.NewSigningSecret()
+		if err != nil {
+			return nil, fmt.Errorf("generate signing secret: %w", err)
+		}
+
+		encryptRes, err := s.kms.Encrypt(ctx, &kms.EncryptInput{
+			KeyId:               &s.webhookSigningSecretsKMSKeyID,
+			EncryptionAlgorithm: types.EncryptionAlgorithmSpecRsaesOaepSha256,
+			Plaintext:           []byte(signingSecret),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("encrypt signing secret: %w", err)
+		}
+
+		params.Type = queries.AuditLogExportDestinationTypeHttps
+		params.HttpsUrl = &destination.HttpsUrl
+		params.SigningSecretCiphertext = encryptRes.CiphertextBlob
+	case backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_S3:
+		if destination.S3Bucket == "" {
+			return nil, apierror.NewInvalidArgumentError("s3_bucket is required", fmt.Errorf("s3_bucket is required"))
+		}
+
+		if err := s.validateCustomerS3Bucket(destination.S3Bucket, destination.S3RoleArn); err != nil {
+			return nil, err
+		}
+
+		params.Type = queries.AuditLogExportDestinationTypeS3
+		params.S3Bucket = &destination.S3Bucket
+		params.S3Prefix = refOrNil(destination.S3Prefix)
+		params.S3RoleArn = &destination.S3RoleArn
+	case backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_SYSLOG:
+		network := destination.SyslogNetwork
+		if network == "" {
+			network = "tcp"
+		}
+
+		if err := validateAuditLogExportDestinationSyslog(network, destination.SyslogAddress); err != nil {
+			return nil, err
+		}
+
+		params.Type = queries.AuditLogExportDestinationTypeSyslog
+		params.SyslogNetwork = &network
+		params.SyslogAddress = &destination.SyslogAddress
+	default:
+		return nil, apierror.NewInvalidArgumentError("invalid audit log export destination type", fmt.Errorf("invalid audit log export destination type: %v", destination.Type))
+	}
+
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	if destination.OrganizationId != "" {
+		orgID, err := idformat.Organization.Parse(destination.OrganizationId)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+		}
+
+		// authz
+		if _, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+			ProjectID: authn.ProjectID(ctx),
+			ID:        orgID,
+		}); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("organization not found", fmt.Errorf("get organization by project id and id: %w", err))
+			}
+
+			return nil, fmt.Errorf("get organization: %w", err)
+		}
+
+		params.OrganizationID = (*uuid.UUID)(&orgID)
+	}
+
+	qAuditLogExportDestination, err := q.CreateAuditLogExportDestination(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("create audit log export destination: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	auditLogExportDestination := parseAuditLogExportDestination(qAuditLogExportDestination)
+	auditLogExportDestination.SigningSecret = signingSecret
+	return &backendv1.CreateAuditLogExportDestinationResponse{AuditLogExportDestination: auditLogExportDestination}, nil
+}
+
+func (s *Store) UpdateAuditLogExportDestination(ctx context.Context, req *backendv1.UpdateAuditLogExportDestinationRequest) (*backendv1.UpdateAuditLogExportDestinationResponse, error) {
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qAuditLogExportDestination, err := getAuditLogExportDestination(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := queries.UpdateAuditLogExportDestinationParams{
+		ID:            qAuditLogExportDestination.ID,
+		DisplayName:   qAuditLogExportDestination.DisplayName,
+		Disabled:      qAuditLogExportDestination.Disabled,
+		HttpsUrl:      qAuditLogExportDestination.HttpsUrl,
+		S3Bucket:      qAuditLogExportDestination.S3Bucket,
+		S3Prefix:      qAuditLogExportDestination.S3Prefix,
+		S3RoleArn:     qAuditLogExportDestination.S3RoleArn,
+		SyslogNetwork: qAuditLogExportDestination.SyslogNetwork,
+		SyslogAddress: qAuditLogExportDestination.SyslogAddress,
+	}
+
+	destination := req.AuditLogExportDestination
+	if destination.DisplayName != "" {
+		updates.DisplayName = destination.DisplayName
+	}
+
+	if destination.Disabled != nil {
+		updates.Disabled = *destination.Disabled
+	}
+
+	switch qAuditLogExportDestination.Type {
+	case queries.AuditLogExportDestinationTypeHttps:
+		if destination.HttpsUrl != "" {
+			if err := validateAuditLogExportDestinationHTTPSURL(destination.HttpsUrl); err != nil {
+				return nil, err
+			}
+			updates.HttpsUrl = &destination.HttpsUrl
+		}
+	case queries.AuditLogExportDestinationTypeS3:
+		if destination.S3Bucket != "" {
+			updates.S3Bucket = &destination.S3Bucket
+		}
+		if destination.S3Prefix != "" {
+			updates.S3Prefix = &destination.S3Prefix
+		}
+		if destination.S3RoleArn != "" {
+			updates.S3RoleArn = &destination.S3RoleArn
+		}
+
+		if err := s.validateCustomerS3Bucket(derefOrEmpty(updates.S3Bucket), derefOrEmpty(updates.S3RoleArn)); err != nil {
+			return nil, err
+		}
+	case queries.AuditLogExportDestinationTypeSyslog:
+		if destination.SyslogNetwork != "" || destination.SyslogAddress != "" {
+			network := derefOrEmpty(updates.SyslogNetwork)
+			if destination.SyslogNetwork != "" {
+				network = destination.SyslogNetwork
+			}
+
+			address := derefOrEmpty(updates.SyslogAddress)
+			if destination.SyslogAddress != "" {
+				address = destination.SyslogAddress
+			}
+
+			if err := validateAuditLogExportDestinationSyslog(network, address); err != nil {
+				return nil, err
+			}
+
+			updates.SyslogNetwork = &network
+			updates.SyslogAddress = &address
+		}
+	}
+
+	qUpdatedAuditLogExportDestination, err := q.UpdateAuditLogExportDestination(ctx, updates)
+	if err != nil {
+		return nil, fmt.Errorf("update audit log export destination: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.UpdateAuditLogExportDestinationResponse{AuditLogExportDestination: parseAuditLogExportDestination(qUpdatedAuditLogExportDestination)}, nil
+}
+
+func (s *Store) DeleteAuditLogExportDestination(ctx context.Context, req *backendv1.DeleteAuditLogExportDestinationRequest) (*backendv1.DeleteAuditLogExportDestinationResponse, error) {
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qAuditLogExportDestination, err := getAuditLogExportDestination(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := q.DeleteAuditLogExportDestination(ctx, qAuditLogExportDestination.ID); err != nil {
+		return nil, fmt.Errorf("delete audit log export destination: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.DeleteAuditLogExportDestinationResponse{}, nil
+}
+
+func getAuditLogExportDestination(ctx context.Context, q *queries.Queries, id string) (*queries.AuditLogExportDestination, error) {
+	auditLogExportDestinationID, err := idformat.AuditLogExportDestination.Parse(id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid audit log export destination id", fmt.Errorf("parse audit log export destination id: %w", err))
+	}
+
+	qAuditLogExportDestination, err := q.GetAuditLogExportDestination(ctx, queries.GetAuditLogExportDestinationParams{
+		ID:        auditLogExportDestinationID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("audit log export destination not found", fmt.Errorf("get audit log export destination: %w", err))
+		}
+
+		return nil, fmt.Errorf("get audit log export destination: %w", err)
+	}
+
+	return &qAuditLogExportDestination, nil
+}
+
+// validateCustomerS3Bucket checks that Tesseral can write to a customer's S3
+// bucket by assuming roleARN, and that the bucket is not Tesseral's own.
+func (s *Store) validateCustomerS3Bucket(bucket, roleARN string) error {
+	if bucket == s.s3UserContentBucketName {
+		return apierror.NewInvalidArgumentError("s3 bucket must be a bucket you own", fmt.Errorf("s3 bucket is the user content bucket"))
+	}
+
+	if roleARN == "" {
+		return apierror.NewInvalidArgumentError("s3 role arn is required", fmt.Errorf("s3 role arn is required"))
+	}
+
+	if err := s3role.ValidateRoleARN(roleARN); err != nil {
+		return apierror.NewInvalidArgumentError("s3 role arn must be the arn of an iam role", fmt.Errorf("validate role arn: %w", err))
+	}
+
+	return nil
+}
+
+func validateAuditLogExportDestinationHTTPSURL(httpsURL string) error {
+	u, err := url.Parse(httpsURL)
+	if err != nil {
+		return apierror.NewInvalidArgumentError("invalid https_url", fmt.Errorf("invalid https_url: %w", err))
+	}
+
+	if u.Scheme != "https" {
+		return apierror.NewInvalidArgumentError("https_url must be https", fmt.Errorf("https_url must be https"))
+	}
+
+	if u.Host == "" {
+		return apierror.NewInvalidArgumentError("https_url must be absolute", fmt.Errorf("https_url must be absolute"))
+	}
+
+	return nil
+}
+
+func validateAuditLogExportDestinationSyslog(network, address string) error {
+	if network != "tcp" && network != "tls" && network != "udp" {
+		return apierror.NewInvalidArgumentError("syslog_network must be one of tcp, tls, or udp", fmt.Errorf("invalid syslog_network: %q", network))
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || host == "" || port == "" {
+		return apierror.NewInvalidArgumentError("syslog_address must be of the form host:port", fmt.Errorf("invalid syslog_address: %q", address))
+	}
+
+	return nil
+}
+
+func parseAuditLogExportDestination(qAuditLogExportDestination queries.AuditLogExportDestination) *backendv1.AuditLogExportDestination {
+	var organizationID string
+	if qAuditLogExportDestination.OrganizationID != nil {
+		organizationID = idformat.Organization.Format(*qAuditLogExportDestination.OrganizationID)
+	}
+
+	var destinationType backendv1.AuditLogExportDestinationType
+	switch qAuditLogExportDestination.Type {
+	case queries.AuditLogExportDestinationTypeHttps:
+		destinationType = backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_HTTPS
+	case queries.AuditLogExportDestinationTypeS3:
+		destinationType = backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_S3
+	case queries.AuditLogExportDestinationTypeSyslog:
+		destinationType = backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_SYSLOG
+	}
+
+	var cursorAuditLogEventID string
+	if qAuditLogExportDestination.CursorAuditLogEventID != nil {
+		cursorAuditLogEventID = idformat.AuditLogEvent.Format(*qAuditLogExportDestination.CursorAuditLogEventID)
+	}
+
+	return &backendv1.AuditLogExportDestination{
+		Id:                      idformat.AuditLogExportDestination.Format(qAuditLogExportDestination.ID),
+		CreateTime:              timestamppb.New(*qAuditLogExportDestination.CreateTime),
+		UpdateTime:              timestamppb.New(*qAuditLogExportDestination.UpdateTime),
+		OrganizationId:          organizationID,
+		DisplayName:             qAuditLogExportDestination.DisplayName,
+		Type:                    destinationType,
+		Disabled:                &qAuditLogExportDestination.Disabled,
+		HttpsUrl:                derefOrEmpty(qAuditLogExportDestination.HttpsUrl),
+		S3Bucket:                derefOrEmpty(qAuditLogExportDestination.S3Bucket),
+		S3RoleArn:               derefOrEmpty(qAuditLogExportDestination.S3RoleArn),
+		S3Prefix:                derefOrEmpty(qAuditLogExportDestination.S3Prefix),
+		SyslogNetwork:           derefOrEmpty(qAuditLogExportDestination.SyslogNetwork),
+		SyslogAddress:           derefOrEmpty(qAuditLogExportDestination.SyslogAddress),
+		CursorAuditLogEventId:   cursorAuditLogEventID,
+		LastAttemptTime:         timestampOrNil(qAuditLogExportDestination.LastAttemptTime),
+		LastSuccessTime:         timestampOrNil(qAuditLogExportDestination.LastSuccessTime),
+		LastError:               derefOrEmpty(qAuditLogExportDestination.LastError),
+		ConsecutiveFailureCount: qAuditLogExportDestination.ConsecutiveFailureCount,
+	}
+}
@@ -0,0 +1,193 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func TestCreateAuditLogExportDestination_HTTPS(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	res, err := u.Store.CreateAuditLogExportDestination(ctx, &backendv1.CreateAuditLogExportDestinationRequest{
+		AuditLogExportDestination: &backendv1.AuditLogExportDestination{
+			DisplayName: "SIEM",
+			Type:        backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_HTTPS,
+			HttpsUrl:    "https://example.com/audit-logs",
+		},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.AuditLogExportDestination.Id)
+	require.Equal(t, "SIEM", res.AuditLogExportDestination.DisplayName)
+	require.Equal(t, "https://example.com/audit-logs", res.AuditLogExportDestination.HttpsUrl)
+	require.False(t, res.AuditLogExportDestination.GetDisabled())
+	require.Contains(t, res.AuditLogExportDestination.SigningSecret, "whsec_")
+	require.Empty(t, res.AuditLogExportDestination.CursorAuditLogEventId)
+
+	getRes, err := u.Store.GetAuditLogExportDestination(ctx, &backendv1.GetAuditLogExportDestinationRequest{Id: res.AuditLogExportDestination.Id})
+	require.NoError(t, err)
+	require.Empty(t, getRes.AuditLogExportDestination.SigningSecret)
+}
+
+func TestCreateAuditLogExportDestination_InvalidArgument(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	for _, destination := range []*backendv1.AuditLogExportDestination{
+		{
+			DisplayName: "missing type",
+		},
+		{
+			DisplayName: "http",
+			Type:        backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_HTTPS,
+			HttpsUrl:    "http://example.com/audit-logs",
+		},
+		{
+			DisplayName: "missing bucket",
+			Type:        backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_S3,
+			S3RoleArn:   "arn:aws:iam::123456789012:role/audit-logs",
+		},
+		{
+			DisplayName: "missing role",
+			Type:        backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_S3,
+			S3Bucket:    "audit-logs",
+		},
+		{
+			DisplayName: "not a role",
+			Type:        backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_S3,
+			S3Bucket:    "audit-logs",
+			S3RoleArn:   "arn:aws:iam::123456789012:user/audit-logs",
+		},
+		{
+			DisplayName: "tesseral bucket",
+			Type:        backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_S3,
+			S3Bucket:    u.Environment.S3.UserContentBucketName,
+			S3RoleArn:   "arn:aws:iam::123456789012:role/audit-logs",
+		},
+		{
+			DisplayName:   "bad network",
+			Type:          backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_SYSLOG,
+			SyslogNetwork: "unix",
+			SyslogAddress: "syslog.example.com:514",
+		},
+		{
+			DisplayName:   "missing port",
+			Type:          backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_SYSLOG,
+			SyslogAddress: "syslog.example.com",
+		},
+	} {
+		_, err := u.Store.CreateAuditLogExportDestination(ctx, &backendv1.CreateAuditLogExportDestinationRequest{
+			AuditLogExportDestination: destination,
+		})
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr, destination.DisplayName)
+		require.Equal(t, connect.CodeInvalidArgument, connectErr.Code(), destination.DisplayName)
+	}
+}
+
+func TestAuditLogExportDestination_Organization(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	res, err := u.Store.CreateAuditLogExportDestination(ctx, &backendv1.CreateAuditLogExportDestinationRequest{
+		AuditLogExportDestination: &backendv1.AuditLogExportDestination{
+			OrganizationId: orgID,
+			DisplayName:    "Org S3",
+			Type:           backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_S3,
+			S3Bucket:       "audit-logs",
+			S3Prefix:       "tesseral",
+			S3RoleArn:      "arn:aws:iam::123456789012:role/audit-logs",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, orgID, res.AuditLogExportDestination.OrganizationId)
+	require.Equal(t, "arn:aws:iam::123456789012:role/audit-logs", res.AuditLogExportDestination.S3RoleArn)
+	require.Empty(t, res.AuditLogExportDestination.SigningSecret)
+
+	_, err = u.Store.CreateAuditLogExportDestination(ctx, &backendv1.CreateAuditLogExportDestinationRequest{
+		AuditLogExportDestination: &backendv1.AuditLogExportDestination{
+			DisplayName:   "Project syslog",
+			Type:          backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_SYSLOG,
+			SyslogAddress: "syslog.example.com:514",
+		},
+	})
+	require.NoError(t, err)
+
+	listRes, err := u.Store.ListAuditLogExportDestinations(ctx, &backendv1.ListAuditLogExportDestinationsRequest{OrganizationId: orgID})
+	require.NoError(t, err)
+	require.Len(t, listRes.AuditLogExportDestinations, 1)
+	require.Equal(t, res.AuditLogExportDestination.Id, listRes.AuditLogExportDestinations[0].Id)
+
+	listRes, err = u.Store.ListAuditLogExportDestinations(ctx, &backendv1.ListAuditLogExportDestinationsRequest{})
+	require.NoError(t, err)
+	require.Len(t, listRes.AuditLogExportDestinations, 2)
+}
+
+func TestUpdateAuditLogExportDestination(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	res, err := u.Store.CreateAuditLogExportDestination(ctx, &backendv1.CreateAuditLogExportDestinationRequest{
+		AuditLogExportDestination: &backendv1.AuditLogExportDestination{
+			DisplayName:   "syslog",
+			Type:          backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_SYSLOG,
+			SyslogAddress: "syslog.example.com:514",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "tcp", res.AuditLogExportDestination.SyslogNetwork)
+
+	updateRes, err := u.Store.UpdateAuditLogExportDestination(ctx, &backendv1.UpdateAuditLogExportDestinationRequest{
+		Id: res.AuditLogExportDestination.Id,
+		AuditLogExportDestination: &backendv1.AuditLogExportDestination{
+			Disabled:      refOrNil(true),
+			SyslogNetwork: "tls",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "syslog", updateRes.AuditLogExportDestination.DisplayName)
+	require.True(t, updateRes.AuditLogExportDestination.GetDisabled())
+	require.Equal(t, "tls", updateRes.AuditLogExportDestination.SyslogNetwork)
+	require.Equal(t, "syslog.example.com:514", updateRes.AuditLogExportDestination.SyslogAddress)
+}
+
+func TestDeleteAuditLogExportDestination(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	res, err := u.Store.CreateAuditLogExportDestination(ctx, &backendv1.CreateAuditLogExportDestinationRequest{
+		AuditLogExportDestination: &backendv1.AuditLogExportDestination{
+			DisplayName: "s3",
+			Type:        backendv1.AuditLogExportDestinationType_AUDIT_LOG_EXPORT_DESTINATION_TYPE_S3,
+			S3Bucket:    "audit-logs",
+			S3RoleArn:   "arn:aws:iam::123456789012:role/audit-logs",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.DeleteAuditLogExportDestination(ctx, &backendv1.DeleteAuditLogExportDestinationRequest{Id: res.AuditLogExportDestination.Id})
+	require.NoError(t, err)
+
+	_, err = u.Store.GetAuditLogExportDestination(ctx, &backendv1.GetAuditLogExportDestinationRequest{Id: res.AuditLogExportDestination.Id})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+
+	_, err = u.Store.GetAuditLogExportDestination(ctx, &backendv1.GetAuditLogExportDestinationRequest{Id: idformat.AuditLogExportDestination.Format(uuid.New())})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
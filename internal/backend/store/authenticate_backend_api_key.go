@@ -16,6 +16,8 @@ var ErrBadBackendAPIKey = fmt.Errorf("bad backend api key")
 type AuthenticateBackendAPIKeyResponse struct {
 	BackendAPIKeyID string
 	ProjectID       string
+	Scopes          []string
+	OrganizationIDs []string
 }
 
 func (s *Store) AuthenticateBackendAPIKey(ctx context.Context, bearerToken string) (*AuthenticateBackendAPIKeyResponse, error) {
@@ -40,8 +42,15 @@ func (s *Store) AuthenticateBackendAPIKey(ctx context.Context, bearerToken strin
 		return nil, fmt.Errorf("get backend api key by secret token sha256: %w", err)
 	}
 
+	var organizationIDs []string
+	for _, organizationID := range qBackendAPIKey.OrganizationIds {
+		organizationIDs = append(organizationIDs, idformat.Organization.Format(organizationID))
+	}
+
 	return &AuthenticateBackendAPIKeyResponse{
 		BackendAPIKeyID: idformat.BackendAPIKey.Format(qBackendAPIKey.ID),
 		ProjectID:       idformat.Project.Format(qBackendAPIKey.ProjectID),
+		Scopes:          qBackendAPIKey.Scopes,
+		OrganizationIDs: organizationIDs,
 	}, nil
 }
@@ -0,0 +1,247 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/ssoready/prettyuuid"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	"github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1/backendv1connect"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// organizationResourceField is a request field that refers to an
+// Organization-owned resource.
+type organizationResourceField struct {
+	// path is the names of the fields leading to the field, e.g. "user",
+	// "organization_id". Repeated fields along the path are expanded.
+	path []string
+
+	// format is the format of the IDs in the field.
+	format *prettyuuid.Format
+}
+
+func orgField(format *prettyuuid.Format, path ...string) organizationResourceField {
+	return organizationResourceField{path: path, format: format}
+}
+
+// organizationProcedureFields maps each BackendService procedure a Backend API
+// Key restricted to a set of Organizations may call to the request fields that
+// identify the Organizations it acts on.
+//
+// At least one of a procedure's fields must be set, and every one that is set
+// must refer to a resource in an allowed Organization. Procedures that are not
+// listed act on the whole Project, or on resources we cannot attribute to an
+// Organization, and are denied.
+var organizationProcedureFields = map[string][]organizationResourceField{
+	backendv1connect.BackendServiceGetOrganizationProcedure:                       {orgField(&idformat.Organization, "id")},
+	backendv1connect.BackendServiceCreateOrganizationProcedure:                    {orgField(&idformat.Organization, "organization", "parent_organization_id")},
+	backendv1connect.BackendServiceUpdateOrganizationProcedure:                    {orgField(&idformat.Organization, "id"), orgField(&idformat.Organization, "organization", "parent_organization_id")},
+	backendv1connect.BackendServiceDeleteOrganizationProcedure:                    {orgField(&idformat.Organization, "id")},
+	backendv1connect.BackendServiceListOrganizationDescendantsProcedure:           {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceGetOrganizationDomainsProcedure:                {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceUpdateOrganizationDomainsProcedure:             {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceVerifyOrganizationDomainsProcedure:             {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceGetOrganizationGoogleHostedDomainsProcedure:    {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceUpdateOrganizationGoogleHostedDomainsProcedure: {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceGetOrganizationMicrosoftTenantIDsProcedure:     {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceUpdateOrganizationMicrosoftTenantIDsProcedure:  {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceDisableOrganizationLoginsProcedure:             {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceEnableOrganizationLoginsProcedure:              {orgField(&idformat.Organization, "organization_id")},
+
+	backendv1connect.BackendServiceListSAMLConnectionsProcedure:  {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceGetSAMLConnectionProcedure:    {orgField(&idformat.SAMLConnection, "id")},
+	backendv1connect.BackendServiceCreateSAMLConnectionProcedure: {orgField(&idformat.Organization, "saml_connection", "organization_id")},
+	backendv1connect.BackendServiceUpdateSAMLConnectionProcedure: {orgField(&idformat.SAMLConnection, "id")},
+	backendv1connect.BackendServiceDeleteSAMLConnectionProcedure: {orgField(&idformat.SAMLConnection, "id")},
+	backendv1connect.BackendServiceListOIDCConnectionsProcedure:  {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceGetOIDCConnectionProcedure:    {orgField(&idformat.OIDCConnection, "id")},
+	backendv1connect.BackendServiceCreateOIDCConnectionProcedure: {orgField(&idformat.Organization, "oidc_connection", "organization_id")},
+	backendv1connect.BackendServiceUpdateOIDCConnectionProcedure: {orgField(&idformat.OIDCConnection, "id")},
+	backendv1connect.BackendServiceDeleteOIDCConnectionProcedure: {orgField(&idformat.OIDCConnection, "id")},
+	backendv1connect.BackendServiceListSCIMAPIKeysProcedure:      {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceGetSCIMAPIKeyProcedure:        {orgField(&idformat.SCIMAPIKey, "id")},
+	backendv1connect.BackendServiceCreateSCIMAPIKeyProcedure:     {orgField(&idformat.Organization, "scim_api_key", "organization_id")},
+	backendv1connect.BackendServiceUpdateSCIMAPIKeyProcedure:     {orgField(&idformat.SCIMAPIKey, "id")},
+	backendv1connect.BackendServiceDeleteSCIMAPIKeyProcedure:     {orgField(&idformat.SCIMAPIKey, "id")},
+	backendv1connect.BackendServiceRevokeSCIMAPIKeyProcedure:     {orgField(&idformat.SCIMAPIKey, "id")},
+
+	backendv1connect.BackendServiceListUsersProcedure:                    {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceGetUserProcedure:                      {orgField(&idformat.User, "id")},
+	backendv1connect.BackendServiceCreateUserProcedure:                   {orgField(&idformat.Organization, "user", "organization_id")},
+	backendv1connect.BackendServiceImportUsersProcedure:                  {orgField(&idformat.Organization, "users", "user", "organization_id")},
+	backendv1connect.BackendServiceUpdateUserProcedure:                   {orgField(&idformat.User, "id"), orgField(&idformat.Organization, "user", "organization_id")},
+	backendv1connect.BackendServiceDeleteUserProcedure:                   {orgField(&idformat.User, "id")},
+	backendv1connect.BackendServiceListPasskeysProcedure:                 {orgField(&idformat.User, "user_id")},
+	backendv1connect.BackendServiceGetPasskeyProcedure:                   {orgField(&idformat.Passkey, "id")},
+	backendv1connect.BackendServiceUpdatePasskeyProcedure:                {orgField(&idformat.Passkey, "id")},
+	backendv1connect.BackendServiceDeletePasskeyProcedure:                {orgField(&idformat.Passkey, "id")},
+	backendv1connect.BackendServiceListSessionsProcedure:                 {orgField(&idformat.User, "user_id")},
+	backendv1connect.BackendServiceGetSessionProcedure:                   {orgField(&idformat.Session, "id")},
+	backendv1connect.BackendServiceListUserInvitesProcedure:              {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceGetUserInviteProcedure:                {orgField(&idformat.UserInvite, "id")},
+	backendv1connect.BackendServiceCreateUserInviteProcedure:             {orgField(&idformat.Organization, "user_invite", "organization_id")},
+	backendv1connect.BackendServiceDeleteUserInviteProcedure:             {orgField(&idformat.UserInvite, "id")},
+	backendv1connect.BackendServiceCreateUserImpersonationTokenProcedure: {orgField(&idformat.User, "user_impersonation_token", "impersonated_id")},
+
+	backendv1connect.BackendServiceListRolesProcedure:                {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceCreateRoleProcedure:               {orgField(&idformat.Organization, "role", "organization_id")},
+	backendv1connect.BackendServiceListUserRoleAssignmentsProcedure:  {orgField(&idformat.User, "user_id")},
+	backendv1connect.BackendServiceGetUserRoleAssignmentProcedure:    {orgField(&idformat.UserRoleAssignment, "id")},
+	backendv1connect.BackendServiceCreateUserRoleAssignmentProcedure: {orgField(&idformat.User, "user_role_assignment", "user_id")},
+	backendv1connect.BackendServiceDeleteUserRoleAssignmentProcedure: {orgField(&idformat.UserRoleAssignment, "id")},
+	backendv1connect.BackendServiceListAccessRequestsProcedure:       {orgField(&idformat.Organization, "organization_id"), orgField(&idformat.User, "user_id")},
+	backendv1connect.BackendServiceGetAccessRequestProcedure:         {orgField(&idformat.AccessRequest, "id")},
+	backendv1connect.BackendServiceCheckPermissionProcedure:          {orgField(&idformat.User, "user_id")},
+	backendv1connect.BackendServiceListPermittedResourcesProcedure:   {orgField(&idformat.User, "user_id")},
+	backendv1connect.BackendServiceCheckActionProcedure:              {orgField(&idformat.User, "user_id"), orgField(&idformat.Session, "session_id"), orgField(&idformat.APIKey, "api_key_id")},
+
+	backendv1connect.BackendServiceListAPIKeysProcedure:                {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceGetAPIKeyProcedure:                  {orgField(&idformat.APIKey, "id")},
+	backendv1connect.BackendServiceCreateAPIKeyProcedure:               {orgField(&idformat.Organization, "api_key", "organization_id")},
+	backendv1connect.BackendServiceUpdateAPIKeyProcedure:               {orgField(&idformat.APIKey, "id")},
+	backendv1connect.BackendServiceDeleteAPIKeyProcedure:               {orgField(&idformat.APIKey, "id")},
+	backendv1connect.BackendServiceRevokeAPIKeyProcedure:               {orgField(&idformat.APIKey, "id")},
+	backendv1connect.BackendServiceRotateAPIKeyProcedure:               {orgField(&idformat.APIKey, "id")},
+	backendv1connect.BackendServiceListAPIKeyRoleAssignmentsProcedure:  {orgField(&idformat.APIKey, "api_key_id")},
+	backendv1connect.BackendServiceCreateAPIKeyRoleAssignmentProcedure: {orgField(&idformat.APIKey, "api_key_role_assignment", "api_key_id")},
+	backendv1connect.BackendServiceDeleteAPIKeyRoleAssignmentProcedure: {orgField(&idformat.APIKeyRoleAssignment, "id"), orgField(&idformat.APIKey, "api_key_id")},
+
+	backendv1connect.BackendServiceCreateAuditLogEventProcedure:             {orgField(&idformat.Organization, "audit_log_event", "organization_id")},
+	backendv1connect.BackendServiceListAuditLogArchivesProcedure:            {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceListAuditLogExportDestinationsProcedure:  {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceCreateAuditLogExportDestinationProcedure: {orgField(&idformat.Organization, "audit_log_export_destination", "organization_id")},
+	backendv1connect.BackendServiceConsoleListAuditLogEventsProcedure:       {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceConsoleListAuditLogEventNamesProcedure:   {orgField(&idformat.Organization, "organization_id")},
+	backendv1connect.BackendServiceConsoleExportAuditLogEventsProcedure:     {orgField(&idformat.Organization, "filter", "organization_id")},
+}
+
+// ValidateBackendAPIKeyOrganizationAccess checks that req, a request to
+// procedure, only acts on Organizations in organizationIDs.
+func (s *Store) ValidateBackendAPIKeyOrganizationAccess(ctx context.Context, organizationIDs []string, procedure string, req proto.Message) error {
+	fields, ok := organizationProcedureFields[procedure]
+	if !ok {
+		return apierror.NewPermissionDeniedError("backend api key is restricted to specific organizations", fmt.Errorf("procedure %s is not organization-scoped", procedure))
+	}
+
+	var allowedOrganizationIDs []uuid.UUID
+	for _, organizationID := range organizationIDs {
+		id, err := idformat.Organization.Parse(organizationID)
+		if err != nil {
+			return fmt.Errorf("parse organization id: %w", err)
+		}
+		allowedOrganizationIDs = append(allowedOrganizationIDs, id)
+	}
+
+	params := queries.GetResourceOrganizationIDsParams{
+		ProjectID:               authn.ProjectID(ctx),
+		OrganizationIds:         []uuid.UUID{},
+		UserIds:                 []uuid.UUID{},
+		SessionIds:              []uuid.UUID{},
+		PasskeyIds:              []uuid.UUID{},
+		UserInviteIds:           []uuid.UUID{},
+		UserRoleAssignmentIds:   []uuid.UUID{},
+		SamlConnectionIds:       []uuid.UUID{},
+		OidcConnectionIds:       []uuid.UUID{},
+		ScimApiKeyIds:           []uuid.UUID{},
+		ApiKeyIds:               []uuid.UUID{},
+		ApiKeyRoleAssignmentIds: []uuid.UUID{},
+		AccessRequestIds:        []uuid.UUID{},
+	}
+
+	formatIDs := map[*prettyuuid.Format]*[]uuid.UUID{
+		&idformat.Organization:         &params.OrganizationIds,
+		&idformat.User:                 &params.UserIds,
+		&idformat.Session:              &params.SessionIds,
+		&idformat.Passkey:              &params.PasskeyIds,
+		&idformat.UserInvite:           &params.UserInviteIds,
+		&idformat.UserRoleAssignment:   &params.UserRoleAssignmentIds,
+		&idformat.SAMLConnection:       &params.SamlConnectionIds,
+		&idformat.OIDCConnection:       &params.OidcConnectionIds,
+		&idformat.SCIMAPIKey:           &params.ScimApiKeyIds,
+		&idformat.APIKey:               &params.ApiKeyIds,
+		&idformat.APIKeyRoleAssignment: &params.ApiKeyRoleAssignmentIds,
+		&idformat.AccessRequest:        &params.AccessRequestIds,
+	}
+
+	resourceIDs := map[uuid.UUID]struct{}{}
+	for _, field := range fields {
+		for _, value := range fieldStringValues(req.ProtoReflect(), field.path) {
+			id, err := field.format.Parse(value)
+			if err != nil {
+				return apierror.NewPermissionDeniedError("backend api key is restricted to specific organizations", fmt.Errorf("parse %s: %w", strings.Join(field.path, "."), err))
+			}
+
+			resourceIDs[id] = struct{}{}
+			*formatIDs[field.format] = append(*formatIDs[field.format], id)
+		}
+	}
+
+	if len(resourceIDs) == 0 {
+		return apierror.NewPermissionDeniedError("backend api key is restricted to specific organizations", fmt.Errorf("request does not refer to an organization"))
+	}
+
+	qResourceOrganizationIDs, err := s.q.GetResourceOrganizationIDs(ctx, params)
+	if err != nil {
+		return fmt.Errorf("get resource organization ids: %w", err)
+	}
+
+	found := map[uuid.UUID]struct{}{}
+	for _, qResourceOrganizationID := range qResourceOrganizationIDs {
+		if !slices.Contains(allowedOrganizationIDs, qResourceOrganizationID.OrganizationID) {
+			return apierror.NewPermissionDeniedError("backend api key is restricted to specific organizations", fmt.Errorf("resource belongs to disallowed organization"))
+		}
+		found[qResourceOrganizationID.ResourceID] = struct{}{}
+	}
+
+	// Deny requests referring to resources that don't exist, as we cannot tell
+	// which Organization they would belong to.
+	if len(found) != len(resourceIDs) {
+		return apierror.NewPermissionDeniedError("backend api key is restricted to specific organizations", fmt.Errorf("resource not found"))
+	}
+
+	return nil
+}
+
+// fieldStringValues returns the non-empty string values at path in m,
+// expanding repeated fields along the way.
+func fieldStringValues(m protoreflect.Message, path []string) []string {
+	fd := m.Descriptor().Fields().ByName(protoreflect.Name(path[0]))
+	if fd == nil {
+		panic(fmt.Errorf("%s has no field %q", m.Descriptor().FullName(), path[0]))
+	}
+
+	if !m.Has(fd) {
+		return nil
+	}
+
+	var values []protoreflect.Value
+	if fd.IsList() {
+		l := m.Get(fd).List()
+		for i := 0; i < l.Len(); i++ {
+			values = append(values, l.Get(i))
+		}
+	} else {
+		values = append(values, m.Get(fd))
+	}
+
+	var strs []string
+	for _, v := range values {
+		if len(path) > 1 {
+			strs = append(strs, fieldStringValues(v.Message(), path[1:])...)
+			continue
+		}
+
+		if v.String() != "" {
+			strs = append(strs, v.String())
+		}
+	}
+	return strs
+}
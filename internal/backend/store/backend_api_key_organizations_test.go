@@ -0,0 +1,127 @@
+package store
+
+import (
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1/backendv1connect"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestValidateBackendAPIKeyOrganizationAccess(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	allowedOrgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{DisplayName: "allowed"})
+	otherOrgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{DisplayName: "other"})
+	allowedUserID := u.Environment.NewUser(t, allowedOrgID, &backendv1.User{Email: "allowed@example.com"})
+	otherUserID := u.Environment.NewUser(t, otherOrgID, &backendv1.User{Email: "other@example.com"})
+
+	allowed := []string{allowedOrgID}
+
+	testCases := []struct {
+		name      string
+		procedure string
+		req       proto.Message
+		code      connect.Code
+	}{
+		{
+			name:      "allowed user",
+			procedure: backendv1connect.BackendServiceUpdateUserProcedure,
+			req:       &backendv1.UpdateUserRequest{Id: allowedUserID, User: &backendv1.User{Email: "x@example.com"}},
+		},
+		{
+			name:      "other user",
+			procedure: backendv1connect.BackendServiceUpdateUserProcedure,
+			req:       &backendv1.UpdateUserRequest{Id: otherUserID, User: &backendv1.User{Email: "x@example.com"}},
+			code:      connect.CodePermissionDenied,
+		},
+		{
+			name:      "nested organization id",
+			procedure: backendv1connect.BackendServiceUpdateUserProcedure,
+			req:       &backendv1.UpdateUserRequest{Id: allowedUserID, User: &backendv1.User{OrganizationId: otherOrgID}},
+			code:      connect.CodePermissionDenied,
+		},
+		{
+			name:      "unknown user",
+			procedure: backendv1connect.BackendServiceUpdateUserProcedure,
+			req:       &backendv1.UpdateUserRequest{Id: idformat.User.Format(uuid.New())},
+			code:      connect.CodePermissionDenied,
+		},
+		{
+			name:      "no organization resources",
+			procedure: backendv1connect.BackendServiceUpdateUserProcedure,
+			req:       &backendv1.UpdateUserRequest{User: &backendv1.User{Email: "x@example.com"}},
+			code:      connect.CodePermissionDenied,
+		},
+		{
+			name:      "organization id in free text",
+			procedure: backendv1connect.BackendServiceUpdateProjectProcedure,
+			req:       &backendv1.UpdateProjectRequest{Project: &backendv1.Project{DisplayName: allowedOrgID}},
+			code:      connect.CodePermissionDenied,
+		},
+		{
+			name:      "organization id in unmapped field",
+			procedure: backendv1connect.BackendServiceUpdateUserProcedure,
+			req:       &backendv1.UpdateUserRequest{Id: otherUserID, User: &backendv1.User{DisplayName: &allowedOrgID}},
+			code:      connect.CodePermissionDenied,
+		},
+		{
+			name:      "allowed list users",
+			procedure: backendv1connect.BackendServiceListUsersProcedure,
+			req:       &backendv1.ListUsersRequest{OrganizationId: allowedOrgID},
+		},
+		{
+			name:      "wrong id format",
+			procedure: backendv1connect.BackendServiceGetUserProcedure,
+			req:       &backendv1.GetUserRequest{Id: allowedOrgID},
+			code:      connect.CodePermissionDenied,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := u.Store.ValidateBackendAPIKeyOrganizationAccess(ctx, allowed, tt.procedure, tt.req)
+			if tt.code == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var connectErr *connect.Error
+			require.ErrorAs(t, err, &connectErr)
+			require.Equal(t, tt.code, connectErr.Code())
+		})
+	}
+}
+
+func TestOrganizationProcedureFields(t *testing.T) {
+	t.Parallel()
+
+	methods := backendv1.File_tesseral_backend_v1_backend_proto.Services().ByName("BackendService").Methods()
+	for procedure, fields := range organizationProcedureFields {
+		method := methods.ByName(protoreflect.Name(procedure[strings.LastIndex(procedure, "/")+1:]))
+		require.NotNil(t, method, procedure)
+
+		for _, field := range fields {
+			md := method.Input()
+			for i, name := range field.path {
+				fd := md.Fields().ByName(protoreflect.Name(name))
+				require.NotNil(t, fd, "%s: %s", procedure, strings.Join(field.path, "."))
+
+				if i < len(field.path)-1 {
+					require.Equal(t, protoreflect.MessageKind, fd.Kind(), "%s: %s", procedure, strings.Join(field.path, "."))
+					md = fd.Message()
+				} else {
+					require.Equal(t, protoreflect.StringKind, fd.Kind(), "%s: %s", procedure, strings.Join(field.path, "."))
+				}
+			}
+		}
+	}
+}
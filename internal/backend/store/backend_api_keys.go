@@ -98,12 +98,22 @@ func (s *Store) CreateBackendAPIKey(ctx context.Context, req *backendv1.CreateBa
 		return nil, fmt.Errorf("not entitled to backend api keys")
 	}
 
+	scopes, err := parseBackendAPIKeyScopes(req.BackendApiKey.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
 	_, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer rollback()
 
+	organizationIDs, err := s.parseBackendAPIKeyOrganizationIDs(ctx, q, req.BackendApiKey.OrganizationIds)
+	if err != nil {
+		return nil, err
+	}
+
 	token := uuid.New()
 	tokenSHA256 := sha256.Sum256(token[:])
 	qBackendAPIKey, err := q.CreateBackendAPIKey(ctx, queries.CreateBackendAPIKeyParams{
@@ -111,6 +121,8 @@ func (s *Store) CreateBackendAPIKey(ctx context.Context, req *backendv1.CreateBa
 		ProjectID:         authn.ProjectID(ctx),
 		DisplayName:       req.BackendApiKey.DisplayName,
 		SecretTokenSha256: tokenSHA256[:],
+		Scopes:            scopes,
+		OrganizationIds:   organizationIDs,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create backend api key: %w", err)
@@ -154,14 +166,34 @@ func (s *Store) UpdateBackendAPIKey(ctx context.Context, req *backendv1.UpdateBa
 	}
 
 	updates := queries.UpdateBackendAPIKeyParams{
-		ID:          backendAPIKeyID,
-		DisplayName: qBackendAPIKey.DisplayName,
+		ID:              backendAPIKeyID,
+		DisplayName:     qBackendAPIKey.DisplayName,
+		Scopes:          qBackendAPIKey.Scopes,
+		OrganizationIds: qBackendAPIKey.OrganizationIds,
 	}
 
 	if req.BackendApiKey.DisplayName != "" {
 		updates.DisplayName = req.BackendApiKey.DisplayName
 	}
 
+	if len(req.BackendApiKey.Scopes) > 0 {
+		scopes, err := parseBackendAPIKeyScopes(req.BackendApiKey.Scopes)
+		if err != nil {
+			return nil, err
+		}
+
+		updates.Scopes = scopes
+	}
+
+	if len(req.BackendApiKey.OrganizationIds) > 0 {
+		organizationIDs, err := s.parseBackendAPIKeyOrganizationIDs(ctx, q, req.BackendApiKey.OrganizationIds)
+		if err != nil {
+			return nil, err
+		}
+
+		updates.OrganizationIds = organizationIDs
+	}
+
 	qUpdatedBackendAPIKey, err := q.UpdateBackendAPIKey(ctx, updates)
 	if err != nil {
 		return nil, fmt.Errorf("update backend api key: %w", err)
@@ -245,13 +277,53 @@ func (s *Store) RevokeBackendAPIKey(ctx context.Context, req *backendv1.RevokeBa
 	return &backendv1.RevokeBackendAPIKeyResponse{BackendApiKey: parseBackendAPIKey(qBackendAPIKey)}, nil
 }
 
+func parseBackendAPIKeyScopes(scopes []string) ([]string, error) {
+	if err := authn.ValidateScopes(scopes); err != nil {
+		return nil, apierror.NewInvalidArgumentError(err.Error(), fmt.Errorf("validate scopes: %w", err))
+	}
+	return append([]string{}, scopes...), nil
+}
+
+// parseBackendAPIKeyOrganizationIDs parses organizationIDs, checking that each
+// belongs to the current project.
+func (s *Store) parseBackendAPIKeyOrganizationIDs(ctx context.Context, q *queries.Queries, organizationIDs []string) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	for _, organizationID := range organizationIDs {
+		id, err := idformat.Organization.Parse(organizationID)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+		}
+
+		if _, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+			ProjectID: authn.ProjectID(ctx),
+			ID:        id,
+		}); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("organization not found", fmt.Errorf("get organization by project id and id: %w", err))
+			}
+
+			return nil, fmt.Errorf("get organization by project id and id: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 func parseBackendAPIKey(qBackendAPIKey queries.BackendApiKey) *backendv1.BackendAPIKey {
+	var organizationIDs []string
+	for _, organizationID := range qBackendAPIKey.OrganizationIds {
+		organizationIDs = append(organizationIDs, idformat.Organization.Format(organizationID))
+	}
+
 	return &backendv1.BackendAPIKey{
-		Id:          idformat.BackendAPIKey.Format(qBackendAPIKey.ID),
-		DisplayName: qBackendAPIKey.DisplayName,
-		CreateTime:  timestamppb.New(*qBackendAPIKey.CreateTime),
-		UpdateTime:  timestamppb.New(*qBackendAPIKey.UpdateTime),
-		SecretToken: "", // intentionally left blank
-		Revoked:     qBackendAPIKey.SecretTokenSha256 == nil,
+		Id:              idformat.BackendAPIKey.Format(qBackendAPIKey.ID),
+		DisplayName:     qBackendAPIKey.DisplayName,
+		CreateTime:      timestamppb.New(*qBackendAPIKey.CreateTime),
+		UpdateTime:      timestamppb.New(*qBackendAPIKey.UpdateTime),
+		SecretToken:     "", // intentionally left blank
+		Revoked:         qBackendAPIKey.SecretTokenSha256 == nil,
+		Scopes:          qBackendAPIKey.Scopes,
+		OrganizationIds: organizationIDs,
 	}
 }
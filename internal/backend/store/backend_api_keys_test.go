@@ -228,3 +228,66 @@ func TestListBackendAPIKeys_Pagination(t *testing.T) {
 	}
 	require.ElementsMatch(t, createdIDs, allIDs)
 }
+
+func TestCreateBackendAPIKey_ScopesAndOrganizations(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	res, err := u.Store.CreateBackendAPIKey(ctx, &backendv1.CreateBackendAPIKeyRequest{
+		BackendApiKey: &backendv1.BackendAPIKey{
+			DisplayName:     "key1",
+			Scopes:          []string{"users:write", "audit-logs:read"},
+			OrganizationIds: []string{orgID},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"users:write", "audit-logs:read"}, res.BackendApiKey.Scopes)
+	require.Equal(t, []string{orgID}, res.BackendApiKey.OrganizationIds)
+
+	updateRes, err := u.Store.UpdateBackendAPIKey(ctx, &backendv1.UpdateBackendAPIKeyRequest{
+		Id: res.BackendApiKey.Id,
+		BackendApiKey: &backendv1.BackendAPIKey{
+			Scopes: []string{"read-only"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"read-only"}, updateRes.BackendApiKey.Scopes)
+	require.Equal(t, []string{orgID}, updateRes.BackendApiKey.OrganizationIds)
+}
+
+func TestCreateBackendAPIKey_InvalidScope(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.CreateBackendAPIKey(ctx, &backendv1.CreateBackendAPIKeyRequest{
+		BackendApiKey: &backendv1.BackendAPIKey{
+			DisplayName: "key1",
+			Scopes:      []string{"users:delete"},
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestCreateBackendAPIKey_OrganizationNotFound(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.CreateBackendAPIKey(ctx, &backendv1.CreateBackendAPIKeyRequest{
+		BackendApiKey: &backendv1.BackendAPIKey{
+			DisplayName:     "key1",
+			OrganizationIds: []string{idformat.Organization.Format(uuid.New())},
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
@@ -0,0 +1,296 @@
+package store
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	commonv1 "github.com/tesseral-labs/tesseral/internal/common/gen/tesseral/common/v1"
+	"github.com/tesseral-labs/tesseral/internal/prettysecret"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/ujwt"
+)
+
+const maxBatchCheckActions = 100
+
+func (s *Store) CheckAction(ctx context.Context, req *backendv1.CheckActionRequest) (*backendv1.CheckActionResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	return s.checkAction(ctx, q, req)
+}
+
+func (s *Store) BatchCheckActions(ctx context.Context, req *backendv1.BatchCheckActionsRequest) (*backendv1.BatchCheckActionsResponse, error) {
+	if len(req.Checks) == 0 {
+		return nil, apierror.NewInvalidArgumentError("checks is required", fmt.Errorf("checks is required"))
+	}
+
+	if len(req.Checks) > maxBatchCheckActions {
+		return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("at most %d checks may be performed at once", maxBatchCheckActions), fmt.Errorf("too many checks: %d", len(req.Checks)))
+	}
+
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	var results []*backendv1.CheckActionResponse
+	for _, check := range req.Checks {
+		result, err := s.checkAction(ctx, q, check)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return &backendv1.BatchCheckActionsResponse{Results: results}, nil
+}
+
+func (s *Store) checkAction(ctx context.Context, q *queries.Queries, req *backendv1.CheckActionRequest) (*backendv1.CheckActionResponse, error) {
+	if (req.ResourceType == "") != (req.ResourceId == "") {
+		return nil, apierror.NewInvalidArgumentError("resource_type and resource_id must be provided together", fmt.Errorf("resource_type and resource_id must be provided together"))
+	}
+
+	if err := validateProjectAction(ctx, q, req.Action); err != nil {
+		return nil, err
+	}
+
+	switch principal := req.Principal.(type) {
+	case *backendv1.CheckActionRequest_UserId:
+		qUser, err := s.getPermissionUser(ctx, q, principal.UserId)
+		if err != nil {
+			return nil, err
+		}
+
+		return checkUserAction(ctx, q, qUser, req)
+	case *backendv1.CheckActionRequest_SessionId:
+		sessionID, err := idformat.Session.Parse(principal.SessionId)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid session id", fmt.Errorf("parse session id: %w", err))
+		}
+
+		qSession, err := q.GetSession(ctx, queries.GetSessionParams{
+			ID:        sessionID,
+			ProjectID: authn.ProjectID(ctx),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("session not found", fmt.Errorf("get session: %w", err))
+			}
+			return nil, fmt.Errorf("get session: %w", err)
+		}
+
+		if qSession.ExpireTime != nil && qSession.ExpireTime.Before(time.Now()) {
+			return nil, apierror.NewFailedPreconditionError("session is expired or revoked", fmt.Errorf("session expired"))
+		}
+
+		qUser, err := s.getPermissionUser(ctx, q, idformat.User.Format(qSession.UserID))
+		if err != nil {
+			return nil, err
+		}
+
+		return checkUserAction(ctx, q, qUser, req)
+	case *backendv1.CheckActionRequest_AccessToken:
+		userID, err := authenticateCheckActionAccessToken(ctx, q, principal.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+
+		qUser, err := s.getPermissionUser(ctx, q, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		return checkUserAction(ctx, q, qUser, req)
+	case *backendv1.CheckActionRequest_ApiKeyId:
+		apiKeyID, err := idformat.APIKey.Parse(principal.ApiKeyId)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid api key id", fmt.Errorf("parse api key id: %w", err))
+		}
+
+		qAPIKey, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
+			ID:        apiKeyID,
+			ProjectID: authn.ProjectID(ctx),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("api key not found", fmt.Errorf("get api key by id: %w", err))
+			}
+			return nil, fmt.Errorf("get api key by id: %w", err)
+		}
+
+		if qAPIKey.SecretTokenSha256 == nil || (qAPIKey.ExpireTime != nil && qAPIKey.ExpireTime.Before(time.Now())) {
+			return nil, apierror.NewFailedPreconditionError("api key is expired or revoked", fmt.Errorf("api key expired or revoked"))
+		}
+
+		return checkAPIKeyAction(ctx, q, qAPIKey.ID, req)
+	case *backendv1.CheckActionRequest_ApiKeySecretToken:
+		apiKeyID, err := getCheckActionAPIKeyIDBySecretToken(ctx, q, principal.ApiKeySecretToken)
+		if err != nil {
+			return nil, err
+		}
+
+		return checkAPIKeyAction(ctx, q, apiKeyID, req)
+	default:
+		return nil, apierror.NewInvalidArgumentError("one of user_id, session_id, access_token, api_key_id, or api_key_secret_token must be provided", fmt.Errorf("principal is required"))
+	}
+}
+
+func checkUserAction(ctx context.Context, q *queries.Queries, qUser *queries.User, req *backendv1.CheckActionRequest) (*backendv1.CheckActionResponse, error) {
+	qGrants, err := q.ExplainUserAction(ctx, queries.ExplainUserActionParams{
+		UserID:       qUser.ID,
+		Action:       req.Action,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("explain user action: %w", err)
+	}
+
+	var grants []*backendv1.ActionGrant
+	for _, qGrant := range qGrants {
+		grants = append(grants, &backendv1.ActionGrant{
+			UserRoleAssignmentId: idformat.UserRoleAssignment.Format(qGrant.UserRoleAssignmentID),
+			RoleIds:              formatRoleIDs(qGrant.RoleIds),
+			ResourceType:         derefOrEmpty(qGrant.ResourceType),
+			ResourceId:           derefOrEmpty(qGrant.ResourceID),
+		})
+	}
+
+	// owners may perform every action, consistent with the actions claim in
+	// their access tokens
+	return &backendv1.CheckActionResponse{
+		Allowed: qUser.IsOwner || len(grants) > 0,
+		UserId:  idformat.User.Format(qUser.ID),
+		Owner:   qUser.IsOwner,
+		Grants:  grants,
+	}, nil
+}
+
+func checkAPIKeyAction(ctx context.Context, q *queries.Queries, apiKeyID uuid.UUID, req *backendv1.CheckActionRequest) (*backendv1.CheckActionResponse, error) {
+	if req.ResourceType != "" {
+		return nil, apierror.NewInvalidArgumentError("resource_type is not supported for api keys", fmt.Errorf("resource_type is not supported for api keys"))
+	}
+
+	qGrants, err := q.ExplainAPIKeyAction(ctx, queries.ExplainAPIKeyActionParams{
+		ApiKeyID: apiKeyID,
+		Action:   req.Action,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("explain api key action: %w", err)
+	}
+
+	var grants []*backendv1.ActionGrant
+	for _, qGrant := range qGrants {
+		grants = append(grants, &backendv1.ActionGrant{
+			ApiKeyRoleAssignmentId: idformat.APIKeyRoleAssignment.Format(qGrant.ApiKeyRoleAssignmentID),
+			RoleIds:                formatRoleIDs(qGrant.RoleIds),
+		})
+	}
+
+	return &backendv1.CheckActionResponse{
+		Allowed:  len(grants) > 0,
+		ApiKeyId: idformat.APIKey.Format(apiKeyID),
+		Grants:   grants,
+	}, nil
+}
+
+// authenticateCheckActionAccessToken validates accessToken against the
+// Project's session signing keys, and returns the ID of its User.
+func authenticateCheckActionAccessToken(ctx context.Context, q *queries.Queries, accessToken string) (string, error) {
+	kid, err := ujwt.KeyID(accessToken)
+	if err != nil {
+		return "", apierror.NewInvalidArgumentError("invalid access token", fmt.Errorf("get access token key id: %w", err))
+	}
+
+	qSessionSigningKeys, err := q.GetSessionSigningKeysByProjectID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return "", fmt.Errorf("get session signing keys by project id: %w", err)
+	}
+
+	var pub *ecdsa.PublicKey
+	for _, qSessionSigningKey := range qSessionSigningKeys {
+		if idformat.SessionSigningKey.Format(qSessionSigningKey.ID) != kid {
+			continue
+		}
+
+		key, err := x509.ParsePKIXPublicKey(qSessionSigningKey.PublicKey)
+		if err != nil {
+			return "", fmt.Errorf("parse session signing key public key: %w", err)
+		}
+		pub = key.(*ecdsa.PublicKey)
+	}
+
+	if pub == nil {
+		return "", apierror.NewInvalidArgumentError("invalid access token", fmt.Errorf("session signing key not found: %s", kid))
+	}
+
+	aud := fmt.Sprintf("https://%s.tesseral.app", strings.ReplaceAll(idformat.Project.Format(authn.ProjectID(ctx)), "_", "-"))
+
+	var claims commonv1.AccessTokenData
+	if err := ujwt.Claims(pub, aud, time.Now(), &claims, accessToken); err != nil {
+		return "", apierror.NewInvalidArgumentError("invalid access token", fmt.Errorf("validate access token: %w", err))
+	}
+
+	if claims.User == nil {
+		return "", apierror.NewInvalidArgumentError("invalid access token", fmt.Errorf("access token has no user"))
+	}
+
+	return claims.User.Id, nil
+}
+
+// getCheckActionAPIKeyIDBySecretToken returns the ID of the active API Key
+// with the given secret token. Unlike AuthenticateAPIKey, it does not record
+// usage or enforce IP allowlists and request quotas.
+func getCheckActionAPIKeyIDBySecretToken(ctx context.Context, q *queries.Queries, secretToken string) (uuid.UUID, error) {
+	qProject, err := q.GetProjectByID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("get project by id: %w", err)
+	}
+
+	if qProject.ApiKeySecretTokenPrefix == nil {
+		return uuid.Nil, apierror.NewFailedPreconditionError("api key secret token prefix is not set for this project", fmt.Errorf("api key secret token prefix not set for project"))
+	}
+
+	secretTokenBytes, err := prettysecret.Parse(*qProject.ApiKeySecretTokenPrefix, secretToken)
+	if err != nil {
+		return uuid.Nil, apierror.NewInvalidArgumentError("invalid api key secret token", fmt.Errorf("parse secret token: %w", err))
+	}
+	secretTokenSHA256 := sha256.Sum256(secretTokenBytes[:])
+
+	qAPIKeyDetails, err := q.GetAPIKeyDetailsBySecretTokenSHA256(ctx, queries.GetAPIKeyDetailsBySecretTokenSHA256Params{
+		SecretTokenSha256: secretTokenSHA256[:],
+		ProjectID:         authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, apierror.NewNotFoundError("api key not found", fmt.Errorf("get api key details: %w", err))
+		}
+		return uuid.Nil, fmt.Errorf("get api key details: %w", err)
+	}
+
+	return qAPIKeyDetails.ID, nil
+}
+
+func formatRoleIDs(roleIDs []uuid.UUID) []string {
+	var out []string
+	for _, roleID := range roleIDs {
+		out = append(out, idformat.Role.Format(roleID))
+	}
+	return out
+}
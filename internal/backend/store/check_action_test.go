@@ -0,0 +1,249 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func TestCheckAction_User(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "test@example.com",
+	})
+
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO actions (id, project_id, name, description)
+  VALUES (gen_random_uuid(), $1::uuid, $2, $2),
+  		 (gen_random_uuid(), $1::uuid, $3, $3);
+`,
+		uuid.UUID(projectID).String(),
+		"test.read",
+		"test.write",
+	)
+	require.NoError(t, err)
+
+	readerResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName: "reader",
+			Actions:     []string{"test.read"},
+		},
+	})
+	require.NoError(t, err)
+
+	editorResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName:      "editor",
+			InheritedRoleIds: []string{readerResp.Role.Id},
+		},
+	})
+	require.NoError(t, err)
+
+	assignmentResp, err := u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId: userID,
+			RoleId: editorResp.Role.Id,
+		},
+	})
+	require.NoError(t, err)
+
+	resp, err := u.Store.CheckAction(ctx, &backendv1.CheckActionRequest{
+		Principal: &backendv1.CheckActionRequest_UserId{UserId: userID},
+		Action:    "test.read",
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Allowed)
+	require.Equal(t, userID, resp.UserId)
+	require.False(t, resp.Owner)
+	require.Len(t, resp.Grants, 1)
+	require.Equal(t, assignmentResp.UserRoleAssignment.Id, resp.Grants[0].UserRoleAssignmentId)
+	require.Equal(t, []string{editorResp.Role.Id, readerResp.Role.Id}, resp.Grants[0].RoleIds)
+
+	resp, err = u.Store.CheckAction(ctx, &backendv1.CheckActionRequest{
+		Principal: &backendv1.CheckActionRequest_UserId{UserId: userID},
+		Action:    "test.write",
+	})
+	require.NoError(t, err)
+	require.False(t, resp.Allowed)
+	require.Empty(t, resp.Grants)
+}
+
+func TestCheckAction_InheritedFromParentOrganization(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	parentOrgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "parent",
+	})
+	childOrgResp, err := u.Store.CreateOrganization(ctx, &backendv1.CreateOrganizationRequest{
+		Organization: &backendv1.Organization{
+			DisplayName:          "child",
+			ParentOrganizationId: &parentOrgID,
+		},
+	})
+	require.NoError(t, err)
+
+	parentUserID := u.Environment.NewUser(t, parentOrgID, &backendv1.User{
+		Email: "admin@example.com",
+	})
+	childUserID := u.Environment.NewUser(t, childOrgResp.Organization.Id, &backendv1.User{
+		Email: "admin@example.com",
+	})
+
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO actions (id, project_id, name, description)
+  VALUES (gen_random_uuid(), $1::uuid, $2, $2);
+`,
+		uuid.UUID(projectID).String(),
+		"test.manage",
+	)
+	require.NoError(t, err)
+
+	roleResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName: "manager",
+			Actions:     []string{"test.manage"},
+		},
+	})
+	require.NoError(t, err)
+
+	assignmentResp, err := u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId: parentUserID,
+			RoleId: roleResp.Role.Id,
+		},
+	})
+	require.NoError(t, err)
+
+	resp, err := u.Store.CheckAction(ctx, &backendv1.CheckActionRequest{
+		Principal: &backendv1.CheckActionRequest_UserId{UserId: childUserID},
+		Action:    "test.manage",
+	})
+	require.NoError(t, err)
+	require.False(t, resp.Allowed)
+
+	// once the assignment inherits to descendants, it applies to the same
+	// person in the child organization
+	_, err = u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId:               parentUserID,
+			RoleId:               roleResp.Role.Id,
+			InheritToDescendants: true,
+		},
+	})
+	require.NoError(t, err)
+
+	resp, err = u.Store.CheckAction(ctx, &backendv1.CheckActionRequest{
+		Principal: &backendv1.CheckActionRequest_UserId{UserId: childUserID},
+		Action:    "test.manage",
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Allowed)
+	require.Len(t, resp.Grants, 1)
+	require.Equal(t, assignmentResp.UserRoleAssignment.Id, resp.Grants[0].UserRoleAssignmentId)
+}
+
+func TestCheckAction_APIKey(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:    "test",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	apiKeyResp, err := u.Store.CreateAPIKey(ctx, &backendv1.CreateAPIKeyRequest{
+		ApiKey: &backendv1.APIKey{
+			OrganizationId: orgID,
+			DisplayName:    "key1",
+		},
+	})
+	require.NoError(t, err)
+
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO actions (id, project_id, name, description)
+  VALUES (gen_random_uuid(), $1::uuid, $2, $2);
+`,
+		uuid.UUID(projectID).String(),
+		"test.read",
+	)
+	require.NoError(t, err)
+
+	roleResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			OrganizationId: orgID,
+			DisplayName:    "reader",
+			Actions:        []string{"test.read"},
+		},
+	})
+	require.NoError(t, err)
+
+	assignmentResp, err := u.Store.CreateAPIKeyRoleAssignment(ctx, &backendv1.CreateAPIKeyRoleAssignmentRequest{
+		ApiKeyRoleAssignment: &backendv1.APIKeyRoleAssignment{
+			ApiKeyId: apiKeyResp.ApiKey.Id,
+			RoleId:   roleResp.Role.Id,
+		},
+	})
+	require.NoError(t, err)
+
+	resp, err := u.Store.BatchCheckActions(ctx, &backendv1.BatchCheckActionsRequest{
+		Checks: []*backendv1.CheckActionRequest{
+			{
+				Principal: &backendv1.CheckActionRequest_ApiKeyId{ApiKeyId: apiKeyResp.ApiKey.Id},
+				Action:    "test.read",
+			},
+			{
+				Principal: &backendv1.CheckActionRequest_ApiKeySecretToken{ApiKeySecretToken: apiKeyResp.ApiKey.SecretToken},
+				Action:    "test.read",
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	for _, result := range resp.Results {
+		require.True(t, result.Allowed)
+		require.Equal(t, apiKeyResp.ApiKey.Id, result.ApiKeyId)
+		require.Len(t, result.Grants, 1)
+		require.Equal(t, assignmentResp.ApiKeyRoleAssignment.Id, result.Grants[0].ApiKeyRoleAssignmentId)
+		require.Equal(t, []string{roleResp.Role.Id}, result.Grants[0].RoleIds)
+	}
+}
+
+func TestCheckAction_NoPrincipal(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO actions (id, project_id, name, description)
+  VALUES (gen_random_uuid(), $1::uuid, $2, $2);
+`,
+		uuid.UUID(projectID).String(),
+		"test.read",
+	)
+	require.NoError(t, err)
+
+	_, err = u.Store.CheckAction(ctx, &backendv1.CheckActionRequest{
+		Action: "test.read",
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
@@ -0,0 +1,402 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/emailtemplates"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) ListEmailTemplates(ctx context.Context, req *backendv1.ListEmailTemplatesRequest) (*backendv1.ListEmailTemplatesResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	var startID uuid.UUID
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, err
+	}
+
+	limit := 10
+	qEmailTemplates, err := q.ListEmailTemplates(ctx, queries.ListEmailTemplatesParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        startID,
+		Limit:     int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list email templates: %w", err)
+	}
+
+	var emailTemplates []*backendv1.EmailTemplate
+	for _, qEmailTemplate := range qEmailTemplates {
+		emailTemplates = append(emailTemplates, parseEmailTemplate(qEmailTemplate))
+	}
+
+	var nextPageToken string
+	if len(emailTemplates) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qEmailTemplates[limit].ID)
+		emailTemplates = emailTemplates[:limit]
+	}
+
+	return &backendv1.ListEmailTemplatesResponse{
+		EmailTemplates: emailTemplates,
+		NextPageToken:  nextPageToken,
+	}, nil
+}
+
+func (s *Store) GetEmailTemplate(ctx context.Context, req *backendv1.GetEmailTemplateRequest) (*backendv1.GetEmailTemplateResponse, error) {
+	emailTemplateID, err := idformat.EmailTemplate.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid email template id", fmt.Errorf("parse email template id: %w", err))
+	}
+
+	qEmailTemplate, err := s.q.GetEmailTemplate(ctx, queries.GetEmailTemplateParams{
+		ID:        emailTemplateID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("email template not found", fmt.Errorf("get email template: %w", err))
+		}
+
+		return nil, fmt.Errorf("get email template: %w", err)
+	}
+
+	return &backendv1.GetEmailTemplateResponse{EmailTemplate: parseEmailTemplate(qEmailTemplate)}, nil
+}
+
+func (s *Store) CreateEmailTemplate(ctx context.Context, req *backendv1.CreateEmailTemplateRequest) (*backendv1.CreateEmailTemplateResponse, error) {
+	template, err := validateEmailTemplate(req.EmailTemplate.Type, req.EmailTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	if err := checkEmailTemplateLocaleAvailable(ctx, q, uuid.Nil, req.EmailTemplate.Type, template.Locale); err != nil {
+		return nil, err
+	}
+
+	qEmailTemplate, err := q.CreateEmailTemplate(ctx, queries.CreateEmailTemplateParams{
+		ID:           uuid.New(),
+		ProjectID:    authn.ProjectID(ctx),
+		TemplateType: queries.EmailTemplateType(req.EmailTemplate.Type),
+		Locale:       refOrNil(template.Locale),
+		Subject:      template.Subject,
+		TextBody:     template.TextBody,
+		HtmlBody:     template.HTMLBody,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create email template: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.CreateEmailTemplateResponse{EmailTemplate: parseEmailTemplate(qEmailTemplate)}, nil
+}
+
+func (s *Store) UpdateEmailTemplate(ctx context.Context, req *backendv1.UpdateEmailTemplateRequest) (*backendv1.UpdateEmailTemplateResponse, error) {
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	emailTemplateID, err := idformat.EmailTemplate.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid email template id", fmt.Errorf("parse email template id: %w", err))
+	}
+
+	qEmailTemplate, err := q.GetEmailTemplate(ctx, queries.GetEmailTemplateParams{
+		ID:        emailTemplateID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("email template not found", fmt.Errorf("get email template: %w", err))
+		}
+
+		return nil, fmt.Errorf("get email template: %w", err)
+	}
+
+	if req.EmailTemplate.Type != "" && req.EmailTemplate.Type != string(qEmailTemplate.TemplateType) {
+		return nil, apierror.NewInvalidArgumentError("email template type cannot be changed", fmt.Errorf("email template type cannot be changed"))
+	}
+
+	updates := &backendv1.EmailTemplate{
+		Locale:   derefOrEmpty(qEmailTemplate.Locale),
+		Subject:  qEmailTemplate.Subject,
+		TextBody: qEmailTemplate.TextBody,
+		HtmlBody: qEmailTemplate.HtmlBody,
+	}
+
+	if req.EmailTemplate.Locale != "" {
+		updates.Locale = req.EmailTemplate.Locale
+	}
+
+	if req.EmailTemplate.Subject != "" {
+		updates.Subject = req.EmailTemplate.Subject
+	}
+
+	if req.EmailTemplate.TextBody != "" {
+		updates.TextBody = req.EmailTemplate.TextBody
+	}
+
+	if req.EmailTemplate.HtmlBody != "" {
+		updates.HtmlBody = req.EmailTemplate.HtmlBody
+	}
+
+	template, err := validateEmailTemplate(string(qEmailTemplate.TemplateType), updates)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkEmailTemplateLocaleAvailable(ctx, q, emailTemplateID, string(qEmailTemplate.TemplateType), template.Locale); err != nil {
+		return nil, err
+	}
+
+	qUpdatedEmailTemplate, err := q.UpdateEmailTemplate(ctx, queries.UpdateEmailTemplateParams{
+		ID:       emailTemplateID,
+		Locale:   refOrNil(template.Locale),
+		Subject:  template.Subject,
+		TextBody: template.TextBody,
+		HtmlBody: template.HTMLBody,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update email template: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.UpdateEmailTemplateResponse{EmailTemplate: parseEmailTemplate(qUpdatedEmailTemplate)}, nil
+}
+
+func (s *Store) DeleteEmailTemplate(ctx context.Context, req *backendv1.DeleteEmailTemplateRequest) (*backendv1.DeleteEmailTemplateResponse, error) {
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	emailTemplateID, err := idformat.EmailTemplate.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid email template id", fmt.Errorf("parse email template id: %w", err))
+	}
+
+	if _, err := q.GetEmailTemplate(ctx, queries.GetEmailTemplateParams{
+		ID:        emailTemplateID,
+		ProjectID: authn.ProjectID(ctx),
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("email template not found", fmt.Errorf("get email template: %w", err))
+		}
+
+		return nil, fmt.Errorf("get email template: %w", err)
+	}
+
+	if err := q.DeleteEmailTemplate(ctx, emailTemplateID); err != nil {
+		return nil, fmt.Errorf("delete email template: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.DeleteEmailTemplateResponse{}, nil
+}
+
+func (s *Store) GetDefaultEmailTemplate(ctx context.Context, req *backendv1.GetDefaultEmailTemplateRequest) (*backendv1.GetDefaultEmailTemplateResponse, error) {
+	if !slices.Contains(emailtemplates.Types(), req.Type) {
+		return nil, apierror.NewInvalidArgumentError("invalid email template type", fmt.Errorf("invalid email template type: %q", req.Type))
+	}
+
+	template := emailtemplates.Default(req.Type)
+	return &backendv1.GetDefaultEmailTemplateResponse{
+		EmailTemplate: &backendv1.EmailTemplate{
+			Type:     req.Type,
+			Subject:  template.Subject,
+			TextBody: template.TextBody,
+			HtmlBody: template.HTMLBody,
+		},
+		Variables: emailtemplates.Variables(req.Type),
+	}, nil
+}
+
+func (s *Store) PreviewEmailTemplate(ctx context.Context, req *backendv1.PreviewEmailTemplateRequest) (*backendv1.PreviewEmailTemplateResponse, error) {
+	template, err := validateEmailTemplate(req.EmailTemplate.Type, req.EmailTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	qProject, err := s.q.GetProjectByID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get project by id: %w", err)
+	}
+
+	vars, err := s.emailTemplateProjectVariables(ctx, qProject, emailtemplates.SampleVariables(req.EmailTemplate.Type))
+	if err != nil {
+		return nil, fmt.Errorf("get email template project variables: %w", err)
+	}
+
+	email, err := emailtemplates.Render(template, vars)
+	if err != nil {
+		return nil, fmt.Errorf("render email template: %w", err)
+	}
+
+	return &backendv1.PreviewEmailTemplateResponse{
+		Subject:  email.Subject,
+		TextBody: email.TextBody,
+		HtmlBody: email.HTMLBody,
+	}, nil
+}
+
+// renderEmail renders the current project's email template of type
+// templateType, localized for acceptLanguage.
+func (s *Store) renderEmail(ctx context.Context, qProject queries.Project, templateType string, acceptLanguage string, vars map[string]string) (*emailtemplates.Email, error) {
+	qEmailTemplates, err := s.q.ListEmailTemplatesByType(ctx, queries.ListEmailTemplatesByTypeParams{
+		ProjectID:    qProject.ID,
+		TemplateType: queries.EmailTemplateType(templateType),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list email templates by type: %w", err)
+	}
+
+	var templates []emailtemplates.Template
+	for _, qEmailTemplate := range qEmailTemplates {
+		templates = append(templates, emailtemplates.Template{
+			Locale:   derefOrEmpty(qEmailTemplate.Locale),
+			Subject:  qEmailTemplate.Subject,
+			TextBody: qEmailTemplate.TextBody,
+			HTMLBody: qEmailTemplate.HtmlBody,
+		})
+	}
+
+	vars, err = s.emailTemplateProjectVariables(ctx, qProject, vars)
+	if err != nil {
+		return nil, fmt.Errorf("get email template project variables: %w", err)
+	}
+
+	template := emailtemplates.Select(templateType, templates, acceptLanguage)
+	email, err := emailtemplates.Render(template, vars)
+	if err != nil {
+		return nil, fmt.Errorf("render email template: %w", err)
+	}
+
+	return email, nil
+}
+
+// emailTemplateProjectVariables adds the project's display name and branding
+// from its UI settings to vars.
+func (s *Store) emailTemplateProjectVariables(ctx context.Context, qProject queries.Project, vars map[string]string) (map[string]string, error) {
+	qProjectUISettings, err := s.q.GetProjectUISettings(ctx, qProject.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get project ui settings: %w", err)
+	}
+
+	// Emails outlive presigned URLs, so link to the logo by its public user
+	// content URL instead.
+	var logoURL string
+	if s.userContentBaseUrl != "" {
+		logoKey := fmt.Sprintf("vault-ui-settings-v1/%s/logo", idformat.Project.Format(qProject.ID))
+		logoExists, err := s.getUserContentFileExists(ctx, logoKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check if logo file exists: %w", err)
+		}
+		if logoExists {
+			logoURL = fmt.Sprintf("%s/%s", s.userContentBaseUrl, logoKey)
+		}
+	}
+
+	primaryColor := emailtemplates.DefaultPrimaryColor
+	if qProjectUISettings.PrimaryColor != nil {
+		primaryColor = *qProjectUISettings.PrimaryColor
+	}
+
+	vars[emailtemplates.VariableProjectDisplayName] = qProject.DisplayName
+	vars[emailtemplates.VariableLogoURL] = logoURL
+	vars[emailtemplates.VariablePrimaryColor] = primaryColor
+	return vars, nil
+}
+
+// validateEmailTemplate validates emailTemplate as a template of type
+// templateType, returning it with its locale in canonical form.
+func validateEmailTemplate(templateType string, emailTemplate *backendv1.EmailTemplate) (emailtemplates.Template, error) {
+	if !slices.Contains(emailtemplates.Types(), templateType) {
+		return emailtemplates.Template{}, apierror.NewInvalidArgumentError("invalid email template type", fmt.Errorf("invalid email template type: %q", templateType))
+	}
+
+	template := emailtemplates.Template{
+		Locale:   emailTemplate.Locale,
+		Subject:  emailTemplate.Subject,
+		TextBody: emailTemplate.TextBody,
+		HTMLBody: emailTemplate.HtmlBody,
+	}
+
+	if err := emailtemplates.Validate(templateType, template); err != nil {
+		return emailtemplates.Template{}, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid email template: %s", err), fmt.Errorf("validate email template: %w", err))
+	}
+
+	if template.Locale != "" {
+		locale, err := emailtemplates.ParseLocale(template.Locale)
+		if err != nil {
+			return emailtemplates.Template{}, fmt.Errorf("parse locale: %w", err)
+		}
+		template.Locale = locale
+	}
+
+	return template, nil
+}
+
+// checkEmailTemplateLocaleAvailable returns an error if the current project
+// already has an email template of type templateType for locale, other than
+// the one with ID excludeID.
+func checkEmailTemplateLocaleAvailable(ctx context.Context, q *queries.Queries, excludeID uuid.UUID, templateType string, locale string) error {
+	qEmailTemplates, err := q.ListEmailTemplatesByType(ctx, queries.ListEmailTemplatesByTypeParams{
+		ProjectID:    authn.ProjectID(ctx),
+		TemplateType: queries.EmailTemplateType(templateType),
+	})
+	if err != nil {
+		return fmt.Errorf("list email templates by type: %w", err)
+	}
+
+	for _, qEmailTemplate := range qEmailTemplates {
+		if qEmailTemplate.ID != excludeID && derefOrEmpty(qEmailTemplate.Locale) == locale {
+			return apierror.NewFailedPreconditionError("an email template of this type already exists for this locale", fmt.Errorf("email template already exists for locale: %q", locale))
+		}
+	}
+
+	return nil
+}
+
+func parseEmailTemplate(qEmailTemplate queries.EmailTemplate) *backendv1.EmailTemplate {
+	return &backendv1.EmailTemplate{
+		Id:         idformat.EmailTemplate.Format(qEmailTemplate.ID),
+		CreateTime: timestamppb.New(*qEmailTemplate.CreateTime),
+		UpdateTime: timestamppb.New(*qEmailTemplate.UpdateTime),
+		Type:       string(qEmailTemplate.TemplateType),
+		Locale:     derefOrEmpty(qEmailTemplate.Locale),
+		Subject:    qEmailTemplate.Subject,
+		TextBody:   qEmailTemplate.TextBody,
+		HtmlBody:   qEmailTemplate.HtmlBody,
+	}
+}
@@ -0,0 +1,222 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	"github.com/tesseral-labs/tesseral/internal/acceptlanguage"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func TestCreateEmailTemplate(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	res, err := u.Store.CreateEmailTemplate(ctx, &backendv1.CreateEmailTemplateRequest{
+		EmailTemplate: &backendv1.EmailTemplate{
+			Type:     "password_reset",
+			Locale:   "pt-br",
+			Subject:  "{{ .ProjectDisplayName }} - Redefinir senha",
+			TextBody: "Seu código é {{ .PasswordResetCode }}",
+			HtmlBody: "<p>Seu código é {{ .PasswordResetCode }}</p>",
+		},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.EmailTemplate.Id)
+	require.Equal(t, "password_reset", res.EmailTemplate.Type)
+	require.Equal(t, "pt-BR", res.EmailTemplate.Locale)
+	require.NotEmpty(t, res.EmailTemplate.CreateTime)
+	require.NotEmpty(t, res.EmailTemplate.UpdateTime)
+
+	getRes, err := u.Store.GetEmailTemplate(ctx, &backendv1.GetEmailTemplateRequest{Id: res.EmailTemplate.Id})
+	require.NoError(t, err)
+	require.Equal(t, res.EmailTemplate.Subject, getRes.EmailTemplate.Subject)
+}
+
+func TestCreateEmailTemplate_UnknownVariable(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.CreateEmailTemplate(ctx, &backendv1.CreateEmailTemplateRequest{
+		EmailTemplate: &backendv1.EmailTemplate{
+			Type:     "password_reset",
+			Subject:  "Reset password",
+			TextBody: "Sign up at {{ .SignupLink }}",
+			HtmlBody: "<p>Reset password</p>",
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestCreateEmailTemplate_DuplicateLocale(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	emailTemplate := &backendv1.EmailTemplate{
+		Type:     "user_invite",
+		Subject:  "Join {{ .OrganizationDisplayName }}",
+		TextBody: "{{ .SignupLink }}",
+		HtmlBody: "<a href=\"{{ .SignupLink }}\">Join</a>",
+	}
+
+	_, err := u.Store.CreateEmailTemplate(ctx, &backendv1.CreateEmailTemplateRequest{EmailTemplate: emailTemplate})
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateEmailTemplate(ctx, &backendv1.CreateEmailTemplateRequest{EmailTemplate: emailTemplate})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+}
+
+func TestUpdateEmailTemplate(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateEmailTemplate(ctx, &backendv1.CreateEmailTemplateRequest{
+		EmailTemplate: &backendv1.EmailTemplate{
+			Type:     "email_verification",
+			Subject:  "Verify your email",
+			TextBody: "{{ .EmailVerificationLink }}",
+			HtmlBody: "<a href=\"{{ .EmailVerificationLink }}\">Verify</a>",
+		},
+	})
+	require.NoError(t, err)
+
+	updateRes, err := u.Store.UpdateEmailTemplate(ctx, &backendv1.UpdateEmailTemplateRequest{
+		Id: createRes.EmailTemplate.Id,
+		EmailTemplate: &backendv1.EmailTemplate{
+			Subject: "{{ .ProjectDisplayName }} - Verify your email",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "{{ .ProjectDisplayName }} - Verify your email", updateRes.EmailTemplate.Subject)
+	require.Equal(t, "{{ .EmailVerificationLink }}", updateRes.EmailTemplate.TextBody)
+
+	_, err = u.Store.UpdateEmailTemplate(ctx, &backendv1.UpdateEmailTemplateRequest{
+		Id: createRes.EmailTemplate.Id,
+		EmailTemplate: &backendv1.EmailTemplate{
+			Type: "password_reset",
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestDeleteEmailTemplate(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateEmailTemplate(ctx, &backendv1.CreateEmailTemplateRequest{
+		EmailTemplate: &backendv1.EmailTemplate{
+			Type:     "password_reset",
+			Subject:  "Reset password",
+			TextBody: "{{ .PasswordResetCode }}",
+			HtmlBody: "<p>{{ .PasswordResetCode }}</p>",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.DeleteEmailTemplate(ctx, &backendv1.DeleteEmailTemplateRequest{Id: createRes.EmailTemplate.Id})
+	require.NoError(t, err)
+
+	_, err = u.Store.GetEmailTemplate(ctx, &backendv1.GetEmailTemplateRequest{Id: createRes.EmailTemplate.Id})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
+
+func TestPreviewEmailTemplate(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	defaultRes, err := u.Store.GetDefaultEmailTemplate(ctx, &backendv1.GetDefaultEmailTemplateRequest{Type: "user_invite"})
+	require.NoError(t, err)
+	require.Contains(t, defaultRes.Variables, "SignupLink")
+
+	res, err := u.Store.PreviewEmailTemplate(ctx, &backendv1.PreviewEmailTemplateRequest{
+		EmailTemplate: defaultRes.EmailTemplate,
+	})
+	require.NoError(t, err)
+	require.Contains(t, res.Subject, "Example Organization")
+	require.Contains(t, res.TextBody, "https://vault.example.com/signup")
+	require.Contains(t, res.HtmlBody, "<!DOCTYPE html>")
+}
+
+func TestSendUserInviteEmail_Localized(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	_, err := u.Store.CreateEmailTemplate(ctx, &backendv1.CreateEmailTemplateRequest{
+		EmailTemplate: &backendv1.EmailTemplate{
+			Type:     "user_invite",
+			Locale:   "de",
+			Subject:  "Einladung zu {{ .OrganizationDisplayName }}",
+			TextBody: "Registrieren: {{ .SignupLink }}",
+			HtmlBody: "<p style=\"color: {{ .PrimaryColor }}\">Registrieren</p>",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateUserInvite(acceptlanguage.NewContext(ctx, "de-DE,de;q=0.9"), &backendv1.CreateUserInviteRequest{
+		UserInvite: &backendv1.UserInvite{
+			OrganizationId: orgID,
+			Email:          "german@example.com",
+		},
+		SendEmail: true,
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateUserInvite(acceptlanguage.NewContext(ctx, "en-US"), &backendv1.CreateUserInviteRequest{
+		UserInvite: &backendv1.UserInvite{
+			OrganizationId: orgID,
+			Email:          "english@example.com",
+		},
+		SendEmail: true,
+	})
+	require.NoError(t, err)
+
+	// an explicit locale takes precedence over Accept-Language
+	_, err = u.Store.CreateUserInvite(acceptlanguage.NewContext(ctx, "en-US"), &backendv1.CreateUserInviteRequest{
+		UserInvite: &backendv1.UserInvite{
+			OrganizationId: orgID,
+			Email:          "explicit@example.com",
+		},
+		SendEmail: true,
+		Locale:    "de-AT",
+	})
+	require.NoError(t, err)
+
+	messages := u.EmailSender.Messages()
+	require.Len(t, messages, 3)
+	require.Equal(t, "Einladung zu test", messages[0].Subject)
+	require.Contains(t, messages[0].TextBody, "/signup")
+	require.Contains(t, messages[1].Subject, "You've been invited to join test")
+	require.NotEmpty(t, messages[1].HTMLBody)
+	require.Equal(t, "Einladung zu test", messages[2].Subject)
+
+	_, err = u.Store.CreateUserInvite(ctx, &backendv1.CreateUserInviteRequest{
+		UserInvite: &backendv1.UserInvite{
+			OrganizationId: orgID,
+			Email:          "invalid@example.com",
+		},
+		SendEmail: true,
+		Locale:    "not a locale",
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
@@ -6,15 +6,21 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/tesseral-labs/tesseral/internal/auditlog"
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
 
 type logAuditEventParams struct {
+	// ProjectID is the project the event belongs to. If nil, it is the
+	// project ctx is authenticated to. Events logged outside of a request,
+	// such as by background jobs, must set ProjectID; they have no actor.
+	ProjectID      *uuid.UUID
 	OrganizationID *uuid.UUID
 
 	EventName    string
@@ -33,9 +39,18 @@ func (s *Store) logAuditEvent(ctx context.Context, q *queries.Queries, data logA
 		return queries.AuditLogEvent{}, fmt.Errorf("failed to marshal event details: %w", err)
 	}
 
+	var projectID uuid.UUID
+	var contextData authn.ContextData
+	if data.ProjectID != nil {
+		projectID = *data.ProjectID
+	} else {
+		projectID = authn.ProjectID(ctx)
+		contextData = authn.GetContextData(ctx)
+	}
+
 	qEventParams := queries.CreateAuditLogEventParams{
 		ID:             eventID,
-		ProjectID:      authn.ProjectID(ctx),
+		ProjectID:      projectID,
 		OrganizationID: data.OrganizationID,
 		ResourceType:   refOrNil(data.ResourceType),
 		ResourceID:     data.ResourceID,
@@ -44,7 +59,6 @@ func (s *Store) logAuditEvent(ctx context.Context, q *queries.Queries, data logA
 		EventDetails:   eventDetailsBytes,
 	}
 
-	contextData := authn.GetContextData(ctx)
 	switch {
 	case contextData.ProjectAPIKey != nil:
 		backendApiKeyUUID, err := idformat.BackendAPIKey.Parse(contextData.ProjectAPIKey.BackendAPIKeyID)
@@ -66,10 +80,95 @@ func (s *Store) logAuditEvent(ctx context.Context, q *queries.Queries, data logA
 		qEventParams.ActorConsoleSessionID = (*uuid.UUID)(&dogfoodSessionUUID)
 	}
 
+	if err := s.chainAuditLogEvent(ctx, q, &qEventParams, eventDetailsBytes); err != nil {
+		return queries.AuditLogEvent{}, err
+	}
+
 	qEvent, err := q.CreateAuditLogEvent(ctx, qEventParams)
 	if err != nil {
 		return queries.AuditLogEvent{}, err
 	}
 
+	if err := s.sendWebhookEvent(ctx, q, qEvent, data.EventDetails); err != nil {
+		return queries.AuditLogEvent{}, fmt.Errorf("send webhook event: %w", err)
+	}
+
 	return qEvent, nil
 }
+
+// chainAuditLogEvent sets the chain sequence and hash of an audit log event
+// about to be created, and advances its project's chain head. The chain head
+// stays locked until q's transaction ends; see package auditlog.
+func (s *Store) chainAuditLogEvent(ctx context.Context, q *queries.Queries, params *queries.CreateAuditLogEventParams, eventDetails []byte) error {
+	qChainHead, err := q.LockAuditLogChainHead(ctx, params.ProjectID)
+	if err != nil {
+		return fmt.Errorf("lock audit log chain head: %w", err)
+	}
+
+	key, keyCiphertext, err := s.auditlogStore.ChainKey(ctx, params.ProjectID, qChainHead.KeyCiphertext)
+	if err != nil {
+		return fmt.Errorf("get audit log chain key: %w", err)
+	}
+
+	sequence := qChainHead.Sequence + 1
+	hash, err := auditlog.ChainHash(key, qChainHead.Hash, sequence, auditlog.ChainEvent{
+		ID:                         params.ID,
+		ProjectID:                  params.ProjectID,
+		OrganizationID:             params.OrganizationID,
+		ActorUserID:                params.ActorUserID,
+		ActorSessionID:             params.ActorSessionID,
+		ActorAPIKeyID:              params.ActorApiKeyID,
+		ActorConsoleUserID:         params.ActorConsoleUserID,
+		ActorConsoleSessionID:      params.ActorConsoleSessionID,
+		ActorBackendAPIKeyID:       params.ActorBackendApiKeyID,
+		ActorIntermediateSessionID: params.ActorIntermediateSessionID,
+		ActorSCIMAPIKeyID:          params.ActorScimApiKeyID,
+		ResourceType:               (*string)(params.ResourceType),
+		ResourceID:                 params.ResourceID,
+		EventName:                  params.EventName,
+		EventTime:                  *params.EventTime,
+		EventDetails:               eventDetails,
+	})
+	if err != nil {
+		return fmt.Errorf("compute audit log chain hash: %w", err)
+	}
+
+	if err := q.UpdateAuditLogChainHead(ctx, queries.UpdateAuditLogChainHeadParams{
+		ProjectID:     params.ProjectID,
+		Sequence:      sequence,
+		Hash:          hash,
+		KeyCiphertext: keyCiphertext,
+	}); err != nil {
+		return fmt.Errorf("update audit log chain head: %w", err)
+	}
+
+	params.ChainSequence = &sequence
+	params.ChainHash = hash
+	return nil
+}
+
+// sendWebhookEvent enqueues the typed webhook event corresponding to an audit
+// log event, if the event catalogue has one.
+func (s *Store) sendWebhookEvent(ctx context.Context, q *queries.Queries, qEvent queries.AuditLogEvent, eventDetails proto.Message) error {
+	event := webhooks.NewEvent(webhooks.NewEventParams{
+		AuditLogEventID: qEvent.ID,
+		ProjectID:       qEvent.ProjectID,
+		OrganizationID:  qEvent.OrganizationID,
+		EventTime:       *qEvent.EventTime,
+		EventDetails:    eventDetails,
+	})
+	if event == nil {
+		return nil
+	}
+
+	msg, err := webhooks.NewEventMessage(event)
+	if err != nil {
+		return fmt.Errorf("create webhook event message: %w", err)
+	}
+
+	if err := s.enqueueWebhookMessage(ctx, q, qEvent.ProjectID, msg); err != nil {
+		return fmt.Errorf("enqueue webhook message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// a user's and their organization's public metadata are both embedded in
+	// access tokens, which are set as cookies; browsers drop cookies over
+	// about 4KiB, so together they get a budget of 1KiB
+	maxPublicMetadataSize  = 512
+	maxPrivateMetadataSize = 16 * 1024
+)
+
+// applyMetadataPatch applies patch to metadata, a JSON object, as a JSON Merge
+// Patch (RFC 7386). A nil patch leaves metadata unchanged.
+func applyMetadataPatch(name string, metadata []byte, patch *structpb.Struct, maxSize int) ([]byte, error) {
+	if patch == nil {
+		return metadata, nil
+	}
+
+	var target map[string]any
+	if err := json.Unmarshal(metadata, &target); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", name, err)
+	}
+
+	patched, err := json.Marshal(mergePatch(target, patch.AsMap()))
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s: %w", name, err)
+	}
+
+	if len(patched) > maxSize {
+		return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("%s must be at most %d bytes", name, maxSize), fmt.Errorf("%s is %d bytes", name, len(patched)))
+	}
+
+	return patched, nil
+}
+
+func mergePatch(target, patch map[string]any) map[string]any {
+	if target == nil {
+		target = map[string]any{}
+	}
+
+	for k, v := range patch {
+		switch v := v.(type) {
+		case nil:
+			delete(target, k)
+		case map[string]any:
+			// a non-object target value is replaced, as if it were empty
+			targetValue, _ := target[k].(map[string]any)
+			target[k] = mergePatch(targetValue, v)
+		default:
+			target[k] = v
+		}
+	}
+
+	return target
+}
+
+// changedMetadataKeys returns the sorted top-level keys whose values differ
+// between two metadata objects.
+func changedMetadataKeys(previous, current []byte) ([]string, error) {
+	var previousMap, currentMap map[string]any
+	if err := json.Unmarshal(previous, &previousMap); err != nil {
+		return nil, fmt.Errorf("unmarshal previous metadata: %w", err)
+	}
+	if err := json.Unmarshal(current, &currentMap); err != nil {
+		return nil, fmt.Errorf("unmarshal current metadata: %w", err)
+	}
+
+	keys := map[string]struct{}{}
+	for k, v := range previousMap {
+		if !reflect.DeepEqual(v, currentMap[k]) {
+			keys[k] = struct{}{}
+		}
+	}
+	for k, v := range currentMap {
+		if !reflect.DeepEqual(v, previousMap[k]) {
+			keys[k] = struct{}{}
+		}
+	}
+
+	return slices.Sorted(maps.Keys(keys)), nil
+}
+
+// metadataFilterValue returns the JSON encoding of a list metadata filter's
+// value, or nil if there is none.
+func metadataFilterValue(key string, value *structpb.Value) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	if key == "" {
+		return nil, apierror.NewInvalidArgumentError("filter_metadata_value requires filter_metadata_key", fmt.Errorf("filter_metadata_value requires filter_metadata_key"))
+	}
+
+	b, err := protojson.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("marshal filter metadata value: %w", err)
+	}
+
+	return b, nil
+}
+
+func parseMetadata(metadata []byte) *structpb.Struct {
+	var s structpb.Struct
+	if err := protojson.Unmarshal(metadata, &s); err != nil {
+		panic(fmt.Errorf("unmarshal metadata: %w", err))
+	}
+	return &s
+}
@@ -0,0 +1,44 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatch(t *testing.T) {
+	t.Parallel()
+
+	target := map[string]any{
+		"plan":    "free",
+		"seats":   float64(5),
+		"billing": map[string]any{"customer": "cus_123", "currency": "usd"},
+		"tags":    []any{"a", "b"},
+	}
+
+	patched := mergePatch(target, map[string]any{
+		"plan":    "pro",
+		"seats":   nil,
+		"billing": map[string]any{"currency": nil, "interval": "month"},
+		"tags":    []any{"c"},
+		"owner":   map[string]any{"name": "alice", "deleted": nil},
+	})
+
+	require.Equal(t, map[string]any{
+		"plan":    "pro",
+		"billing": map[string]any{"customer": "cus_123", "interval": "month"},
+		"tags":    []any{"c"},
+		"owner":   map[string]any{"name": "alice"},
+	}, patched)
+}
+
+func TestChangedMetadataKeys(t *testing.T) {
+	t.Parallel()
+
+	keys, err := changedMetadataKeys(
+		[]byte(`{"a": 1, "b": {"c": 2}, "d": "same"}`),
+		[]byte(`{"b": {"c": 3}, "d": "same", "e": true}`),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "e"}, keys)
+}
@@ -0,0 +1,238 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/cloudflaredoh"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+const (
+	organizationDomainVerificationBatchSize    = 20
+	organizationDomainVerificationPollInterval = time.Minute
+
+	// verified domains are re-checked daily, and only lose their verification
+	// once their TXT record has been missing for a week, so that a transient
+	// DNS problem does not break logins
+	organizationDomainRecheckInterval     = 24 * time.Hour
+	organizationDomainVerificationGrace   = 7 * 24 * time.Hour
+	organizationDomainPendingCheckWindow  = 7 * 24 * time.Hour
+	organizationDomainPendingRecheckDelay = time.Hour
+)
+
+// RunOrganizationDomainVerification checks the DNS TXT records of pending and
+// verified organization domains, until ctx is canceled.
+func (s *Store) RunOrganizationDomainVerification(ctx context.Context) error {
+	ticker := time.NewTicker(organizationDomainVerificationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.CheckOrganizationDomains(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "check_organization_domains_error", "err", err)
+		}
+
+		// A full batch suggests there is a backlog; keep going without waiting
+		// for the next tick.
+		if err == nil && n == organizationDomainVerificationBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckOrganizationDomains checks the DNS TXT records of a batch of
+// organization domains that are due for a check. It returns the number of
+// domains checked.
+//
+// Newly added domains are checked right away, pending domains are re-checked
+// hourly for a week after they are added, and verified domains are re-checked
+// daily.
+func (s *Store) CheckOrganizationDomains(ctx context.Context) (int, error) {
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer rollback()
+
+	now := time.Now()
+	verifiedCheckBefore := now.Add(-organizationDomainRecheckInterval)
+	pendingCreateAfter := now.Add(-organizationDomainPendingCheckWindow)
+	pendingCheckBefore := now.Add(-organizationDomainPendingRecheckDelay)
+	qDomains, err := q.ListOrganizationDomainsToCheck(ctx, queries.ListOrganizationDomainsToCheckParams{
+		VerifiedCheckBefore: &verifiedCheckBefore,
+		PendingCreateAfter:  &pendingCreateAfter,
+		PendingCheckBefore:  &pendingCheckBefore,
+		Limit:               organizationDomainVerificationBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("list organization domains to check: %w", err)
+	}
+
+	for _, qDomain := range qDomains {
+		if _, err := s.checkOrganizationDomain(ctx, q, qDomain.ProjectID, queries.OrganizationDomain{
+			ID:             qDomain.ID,
+			OrganizationID: qDomain.OrganizationID,
+			Domain:         qDomain.Domain,
+			CreateTime:     qDomain.CreateTime,
+			Verified:       qDomain.Verified,
+			VerifyTime:     qDomain.VerifyTime,
+			LastCheckTime:  qDomain.LastCheckTime,
+		}); err != nil {
+			return 0, fmt.Errorf("check organization domain: %w", err)
+		}
+	}
+
+	if err := commit(); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return len(qDomains), nil
+}
+
+func (s *Store) VerifyOrganizationDomains(ctx context.Context, req *backendv1.VerifyOrganizationDomainsRequest) (*backendv1.VerifyOrganizationDomainsResponse, error) {
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	orgID, err := idformat.Organization.Parse(req.OrganizationId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+	}
+
+	qOrg, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("organization not found", fmt.Errorf("get organization: %w", err))
+		}
+
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+
+	qDomains, err := q.GetOrganizationDomains(ctx, queries.GetOrganizationDomainsParams{
+		ProjectID:      authn.ProjectID(ctx),
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization domains: %w", err)
+	}
+
+	var qCheckedDomains []queries.OrganizationDomain
+	for _, qDomain := range qDomains {
+		qCheckedDomain, err := s.checkOrganizationDomain(ctx, q, qOrg.ProjectID, qDomain)
+		if err != nil {
+			return nil, fmt.Errorf("check organization domain: %w", err)
+		}
+
+		qCheckedDomains = append(qCheckedDomains, qCheckedDomain)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.VerifyOrganizationDomainsResponse{
+		OrganizationDomains: parseOrganizationDomains(qOrg, qCheckedDomains),
+	}, nil
+}
+
+// checkOrganizationDomain looks up a domain's TXT record and records the
+// result, logging an audit event if the domain's verification changes.
+//
+// A failed DNS lookup is logged and counts as a check that did not find the
+// record.
+func (s *Store) checkOrganizationDomain(ctx context.Context, q *queries.Queries, projectID uuid.UUID, qDomain queries.OrganizationDomain) (queries.OrganizationDomain, error) {
+	found, err := s.hasOrganizationDomainTXTRecord(ctx, qDomain)
+	if err != nil {
+		slog.ErrorContext(ctx, "organization_domain_dns_query_error", "domain", qDomain.Domain, "err", err)
+	}
+
+	now := time.Now()
+	verified := qDomain.Verified
+	verifyTime := qDomain.VerifyTime
+	switch {
+	case found:
+		verified = true
+		verifyTime = &now
+	case verified && (verifyTime == nil || verifyTime.Before(now.Add(-organizationDomainVerificationGrace))):
+		verified = false
+	}
+
+	qUpdatedDomain, err := q.UpdateOrganizationDomainVerification(ctx, queries.UpdateOrganizationDomainVerificationParams{
+		ID:         qDomain.ID,
+		Verified:   verified,
+		VerifyTime: verifyTime,
+	})
+	if err != nil {
+		return queries.OrganizationDomain{}, fmt.Errorf("update organization domain verification: %w", err)
+	}
+
+	if qUpdatedDomain.Verified != qDomain.Verified {
+		if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+			ProjectID: &projectID,
+			EventName: "tesseral.organizations.update_domain_verification",
+			EventDetails: &auditlogv1.UpdateOrganizationDomainVerification{
+				Domain:           qUpdatedDomain.Domain,
+				Verified:         qUpdatedDomain.Verified,
+				PreviousVerified: qDomain.Verified,
+			},
+			OrganizationID: &qUpdatedDomain.OrganizationID,
+			ResourceType:   queries.AuditLogEventResourceTypeOrganization,
+			ResourceID:     &qUpdatedDomain.OrganizationID,
+		}); err != nil {
+			return queries.OrganizationDomain{}, fmt.Errorf("create audit log event: %w", err)
+		}
+	}
+
+	return qUpdatedDomain, nil
+}
+
+func (s *Store) hasOrganizationDomainTXTRecord(ctx context.Context, qDomain queries.OrganizationDomain) (bool, error) {
+	name := organizationDomainTXTRecordName(qDomain.Domain)
+	res, err := s.cloudflareDOH.DNSQuery(ctx, &cloudflaredoh.DNSQueryRequest{
+		Name: name,
+		Type: "TXT",
+	})
+	if err != nil {
+		return false, fmt.Errorf("dns query: %w", err)
+	}
+
+	want := organizationDomainTXTRecordValue(qDomain.OrganizationID)
+	for _, answer := range res.Answer {
+		// 16 is the TXT record type; other answers are just related records
+		if answer.Name == name && answer.Type == 16 && answer.Data == want {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func organizationDomainTXTRecordName(domain string) string {
+	return fmt.Sprintf("_tesseral_organization_verification.%s", domain)
+}
+
+func organizationDomainTXTRecordValue(orgID uuid.UUID) string {
+	return fmt.Sprintf("\"%s\"", idformat.Organization.Format(orgID))
+}
@@ -74,17 +74,24 @@ func (s *Store) UpdateOrganizationDomains(ctx context.Context, req *backendv1.Up
 		return nil, fmt.Errorf("get organization google hosted domains: %w", err)
 	}
 
-	if err := q.DeleteOrganizationDomains(ctx, orgID); err != nil {
-		return nil, fmt.Errorf("delete organization google hosted domains: %w", err)
+	// domains that are kept keep their verification state, so only remove
+	// domains that are no longer present
+	domains := append([]string{}, req.OrganizationDomains.Domains...)
+
+	if err := q.DeleteOrganizationDomainsNotIn(ctx, queries.DeleteOrganizationDomainsNotInParams{
+		OrganizationID: orgID,
+		Domains:        domains,
+	}); err != nil {
+		return nil, fmt.Errorf("delete organization domains: %w", err)
 	}
 
-	for _, domain := range req.OrganizationDomains.Domains {
-		if _, err := q.CreateOrganizationDomain(ctx, queries.CreateOrganizationDomainParams{
+	for _, domain := range domains {
+		if err := q.CreateOrganizationDomain(ctx, queries.CreateOrganizationDomainParams{
 			ID:             uuid.New(),
 			OrganizationID: orgID,
 			Domain:         domain,
 		}); err != nil {
-			return nil, fmt.Errorf("create organization google hosted domain: %w", err)
+			return nil, fmt.Errorf("create organization domain: %w", err)
 		}
 	}
 
@@ -121,11 +128,21 @@ func (s *Store) UpdateOrganizationDomains(ctx context.Context, req *backendv1.Up
 
 func parseOrganizationDomains(qOrg queries.Organization, qOrganizationDomains []queries.OrganizationDomain) *backendv1.OrganizationDomains {
 	var Domains []string
+	var domainVerifications []*backendv1.OrganizationDomainVerification
 	for _, qOrganizationDomain := range qOrganizationDomains {
 		Domains = append(Domains, qOrganizationDomain.Domain)
+		domainVerifications = append(domainVerifications, &backendv1.OrganizationDomainVerification{
+			Domain:         qOrganizationDomain.Domain,
+			Verified:       qOrganizationDomain.Verified,
+			VerifyTime:     timestampOrNil(qOrganizationDomain.VerifyTime),
+			LastCheckTime:  timestampOrNil(qOrganizationDomain.LastCheckTime),
+			TxtRecordName:  organizationDomainTXTRecordName(qOrganizationDomain.Domain),
+			TxtRecordValue: organizationDomainTXTRecordValue(qOrg.ID),
+		})
 	}
 	return &backendv1.OrganizationDomains{
-		OrganizationId: idformat.Organization.Format(qOrg.ID),
-		Domains:        Domains,
+		OrganizationId:      idformat.Organization.Format(qOrg.ID),
+		Domains:             Domains,
+		DomainVerifications: domainVerifications,
 	}
 }
@@ -1,10 +1,17 @@
 package store
 
 import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/cloudflaredoh"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 )
 
 func TestGetOrganizationDomains_Empty(t *testing.T) {
@@ -82,3 +89,128 @@ func TestUpdateOrganizationDomains_ReplaceDomains(t *testing.T) {
 	require.NoError(t, err)
 	require.ElementsMatch(t, newDomains, getResp.OrganizationDomains.Domains)
 }
+
+func TestUpdateOrganizationDomains_KeepsVerification(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	_, err := u.Store.UpdateOrganizationDomains(ctx, &backendv1.UpdateOrganizationDomainsRequest{
+		OrganizationId: orgID,
+		OrganizationDomains: &backendv1.OrganizationDomains{
+			Domains: []string{"a.com"},
+		},
+	})
+	require.NoError(t, err)
+
+	setOrganizationDomainVerified(t, u, orgID, "a.com", "now()")
+
+	updateResp, err := u.Store.UpdateOrganizationDomains(ctx, &backendv1.UpdateOrganizationDomainsRequest{
+		OrganizationId: orgID,
+		OrganizationDomains: &backendv1.OrganizationDomains{
+			Domains: []string{"a.com", "b.com"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, updateResp.OrganizationDomains.DomainVerifications, 2)
+
+	verifications := updateResp.OrganizationDomains.DomainVerifications
+	require.Equal(t, "a.com", verifications[0].Domain)
+	require.True(t, verifications[0].Verified)
+	require.Equal(t, "b.com", verifications[1].Domain)
+	require.False(t, verifications[1].Verified)
+	require.Equal(t, "_tesseral_organization_verification.b.com", verifications[1].TxtRecordName)
+	require.Equal(t, "\""+orgID+"\"", verifications[1].TxtRecordValue)
+}
+
+func TestVerifyOrganizationDomains(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	_, err := u.Store.UpdateOrganizationDomains(ctx, &backendv1.UpdateOrganizationDomainsRequest{
+		OrganizationId: orgID,
+		OrganizationDomains: &backendv1.OrganizationDomains{
+			Domains: []string{"lapsed.com", "missing.com", "present.com", "recent.com"},
+		},
+	})
+	require.NoError(t, err)
+
+	// lapsed.com lost its record more than a week ago, recent.com only just
+	setOrganizationDomainVerified(t, u, orgID, "lapsed.com", "now() - interval '8 days'")
+	setOrganizationDomainVerified(t, u, orgID, "recent.com", "now() - interval '1 day'")
+
+	u.Store.cloudflareDOH = &cloudflaredoh.Client{
+		HTTPClient: &http.Client{
+			Transport: fakeTXTRecords{
+				"_tesseral_organization_verification.present.com": "\"" + orgID + "\"",
+			},
+		},
+	}
+
+	resp, err := u.Store.VerifyOrganizationDomains(ctx, &backendv1.VerifyOrganizationDomainsRequest{
+		OrganizationId: orgID,
+	})
+	require.NoError(t, err)
+
+	verified := map[string]bool{}
+	for _, verification := range resp.OrganizationDomains.DomainVerifications {
+		require.NotNil(t, verification.LastCheckTime)
+		verified[verification.Domain] = verification.Verified
+	}
+	require.Equal(t, map[string]bool{
+		"lapsed.com":  false,
+		"missing.com": false,
+		"present.com": true,
+		"recent.com":  true,
+	}, verified)
+}
+
+func setOrganizationDomainVerified(t *testing.T, u *testUtil, orgID, domain, verifyTime string) {
+	orgUUID, err := idformat.Organization.Parse(orgID)
+	require.NoError(t, err)
+
+	_, err = u.Environment.DB.Exec(t.Context(), `
+UPDATE organization_domains
+  SET verified = true, verify_time = `+verifyTime+`
+  WHERE organization_id = $1::uuid AND domain = $2;
+`,
+		uuid.UUID(orgUUID).String(),
+		domain,
+	)
+	require.NoError(t, err)
+}
+
+// fakeTXTRecords serves Cloudflare DNS-over-HTTPS responses for a set of TXT
+// records, keyed by name.
+type fakeTXTRecords map[string]string
+
+func (f fakeTXTRecords) RoundTrip(req *http.Request) (*http.Response, error) {
+	name := req.URL.Query().Get("name")
+
+	var res cloudflaredoh.DNSQueryResponse
+	if value, ok := f[name]; ok {
+		res.Answer = append(res.Answer, cloudflaredoh.DNSQueryResponseAnswer{
+			Name: name,
+			Type: 16,
+			Data: value,
+			TTL:  300,
+		})
+	}
+
+	body, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(string(body))),
+	}, nil
+}
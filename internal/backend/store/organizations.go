@@ -4,17 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log/slog"
+	"slices"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/svix/svix-webhooks/go/models"
 	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -83,8 +83,24 @@ func (s *Store) CreateOrganization(ctx context.Context, req *backendv1.CreateOrg
 		scimEnabled = *req.Organization.ScimEnabled
 	}
 
+	orgID := uuid.New()
+	parentOrganizationID, err := s.getParentOrganizationID(ctx, q, orgID, req.Organization.GetParentOrganizationId())
+	if err != nil {
+		return nil, err
+	}
+
+	publicMetadata, err := applyMetadataPatch("public metadata", []byte("{}"), req.Organization.PublicMetadata, maxPublicMetadataSize)
+	if err != nil {
+		return nil, err
+	}
+
+	privateMetadata, err := applyMetadataPatch("private metadata", []byte("{}"), req.Organization.PrivateMetadata, maxPrivateMetadataSize)
+	if err != nil {
+		return nil, err
+	}
+
 	qOrg, err := q.CreateOrganization(ctx, queries.CreateOrganizationParams{
-		ID:                        uuid.New(),
+		ID:                        orgID,
 		ProjectID:                 authn.ProjectID(ctx),
 		DisplayName:               req.Organization.DisplayName,
 		LogInWithGoogle:           derefOrEmpty(req.Organization.LogInWithGoogle),
@@ -97,11 +113,18 @@ func (s *Store) CreateOrganization(ctx context.Context, req *backendv1.CreateOrg
 		LogInWithAuthenticatorApp: derefOrEmpty(req.Organization.LogInWithAuthenticatorApp),
 		LogInWithPasskey:          derefOrEmpty(req.Organization.LogInWithPasskey),
 		ScimEnabled:               scimEnabled,
+		ParentOrganizationID:      parentOrganizationID,
+		PublicMetadata:            publicMetadata,
+		PrivateMetadata:           privateMetadata,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create organization: %w", err)
 	}
 
+	if err := createOrganizationRoleTemplateRoles(ctx, q, qOrg.ID); err != nil {
+		return nil, err
+	}
+
 	auditOrganization, err := s.auditlogStore.GetOrganization(ctx, tx, qOrg.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get audit organization: %w", err)
@@ -119,15 +142,15 @@ func (s *Store) CreateOrganization(ctx context.Context, req *backendv1.CreateOrg
 		return nil, fmt.Errorf("log audit event: %w", err)
 	}
 
-	if err := commit(); err != nil {
-		return nil, fmt.Errorf("commit: %w", err)
-	}
-
 	// Send webhook event
-	if err := s.sendSyncOrganizationEvent(ctx, qOrg); err != nil {
+	if err := s.sendSyncOrganizationEvent(ctx, q, qOrg); err != nil {
 		return nil, fmt.Errorf("send sync organization event: %w", err)
 	}
 
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
 	return &backendv1.CreateOrganizationResponse{Organization: parseOrganization(qProject, qOrg)}, nil
 }
 
@@ -152,11 +175,18 @@ func (s *Store) ListOrganizations(ctx context.Context, req *backendv1.ListOrgani
 		return nil, fmt.Errorf("get project by id: %w", err)
 	}
 
+	metadataValue, err := metadataFilterValue(req.FilterMetadataKey, req.FilterMetadataValue)
+	if err != nil {
+		return nil, err
+	}
+
 	limit := 10
 	qOrgs, err := q.ListOrganizationsByProjectId(ctx, queries.ListOrganizationsByProjectIdParams{
-		ProjectID: authn.ProjectID(ctx),
-		ID:        startID,
-		Limit:     int32(limit + 1),
+		ProjectID:     authn.ProjectID(ctx),
+		ID:            startID,
+		MetadataKey:   refOrNil(req.FilterMetadataKey),
+		MetadataValue: metadataValue,
+		Limit:         int32(limit + 1),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("list organizations: %w", err)
@@ -340,6 +370,17 @@ func (s *Store) UpdateOrganization(ctx context.Context, req *backendv1.UpdateOrg
 		updates.LogInWithPasskey = *req.Organization.LogInWithPasskey
 	}
 
+	updates.EnforceSsoForDomains = qOrg.EnforceSsoForDomains
+	if req.Organization.EnforceSsoForDomains != nil {
+		updates.EnforceSsoForDomains = *req.Organization.EnforceSsoForDomains
+	}
+
+	// enforcing sso without any sso login method would lock out every user at
+	// the organization's domains
+	if updates.EnforceSsoForDomains && !updates.LogInWithSaml && !updates.LogInWithOidc {
+		return nil, apierror.NewFailedPreconditionError("enforcing sso requires log in with saml or oidc", fmt.Errorf("enforcing sso requires log in with saml or oidc"))
+	}
+
 	updates.ScimEnabled = qOrg.ScimEnabled
 	if req.Organization.ScimEnabled != nil {
 		updates.ScimEnabled = *req.Organization.ScimEnabled
@@ -366,11 +407,54 @@ func (s *Store) UpdateOrganization(ctx context.Context, req *backendv1.UpdateOrg
 		updates.ApiKeysEnabled = *req.Organization.ApiKeysEnabled
 	}
 
+	updates.AuditLogRetentionDays = qOrg.AuditLogRetentionDays
+	if req.Organization.AuditLogRetentionDays != nil {
+		retentionDays := req.Organization.GetAuditLogRetentionDays()
+
+		// an organization without an override falls back to the project's
+		// retention period, so clearing the override is always allowed
+		if retentionDays != 0 {
+			if err := validateAuditLogRetentionDays(qProject, retentionDays); err != nil {
+				return nil, err
+			}
+		}
+
+		updates.AuditLogRetentionDays = nil
+		if retentionDays != 0 {
+			updates.AuditLogRetentionDays = &retentionDays
+		}
+	}
+
+	updates.ParentOrganizationID = qOrg.ParentOrganizationID
+	if req.Organization.ParentOrganizationId != nil {
+		parentOrganizationID, err := s.getParentOrganizationID(ctx, q, qOrg.ID, req.Organization.GetParentOrganizationId())
+		if err != nil {
+			return nil, err
+		}
+
+		updates.ParentOrganizationID = parentOrganizationID
+	}
+
+	updates.PublicMetadata, err = applyMetadataPatch("public metadata", qOrg.PublicMetadata, req.Organization.PublicMetadata, maxPublicMetadataSize)
+	if err != nil {
+		return nil, err
+	}
+
+	updates.PrivateMetadata, err = applyMetadataPatch("private metadata", qOrg.PrivateMetadata, req.Organization.PrivateMetadata, maxPrivateMetadataSize)
+	if err != nil {
+		return nil, err
+	}
+
 	qUpdatedOrg, err := q.UpdateOrganization(ctx, updates)
 	if err != nil {
 		return nil, fmt.Errorf("update organization: %w", err)
 	}
 
+	privateMetadataChangedKeys, err := changedMetadataKeys(qOrg.PrivateMetadata, qUpdatedOrg.PrivateMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("changed private metadata keys: %w", err)
+	}
+
 	auditOrganization, err := s.auditlogStore.GetOrganization(ctx, tx, qUpdatedOrg.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get audit organization: %w", err)
@@ -379,8 +463,9 @@ func (s *Store) UpdateOrganization(ctx context.Context, req *backendv1.UpdateOrg
 	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
 		EventName: "tesseral.organizations.update",
 		EventDetails: &auditlogv1.UpdateOrganization{
-			Organization:         auditOrganization,
-			PreviousOrganization: auditPreviousOrganization,
+			Organization:               auditOrganization,
+			PreviousOrganization:       auditPreviousOrganization,
+			PrivateMetadataChangedKeys: privateMetadataChangedKeys,
 		},
 		OrganizationID: &qOrg.ID,
 		ResourceType:   queries.AuditLogEventResourceTypeOrganization,
@@ -389,15 +474,15 @@ func (s *Store) UpdateOrganization(ctx context.Context, req *backendv1.UpdateOrg
 		return nil, fmt.Errorf("log audit event: %w", err)
 	}
 
-	if err := commit(); err != nil {
-		return nil, fmt.Errorf("commit: %w", err)
-	}
-
 	// Send webhook event
-	if err := s.sendSyncOrganizationEvent(ctx, qUpdatedOrg); err != nil {
+	if err := s.sendSyncOrganizationEvent(ctx, q, qUpdatedOrg); err != nil {
 		return nil, fmt.Errorf("send sync organization event: %w", err)
 	}
 
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
 	return &backendv1.UpdateOrganizationResponse{Organization: parseOrganization(qProject, qUpdatedOrg)}, nil
 }
 
@@ -426,6 +511,15 @@ func (s *Store) DeleteOrganization(ctx context.Context, req *backendv1.DeleteOrg
 		return nil, fmt.Errorf("get organization: %w", err)
 	}
 
+	hasChildren, err := q.GetOrganizationHasChildren(ctx, (*uuid.UUID)(&orgID))
+	if err != nil {
+		return nil, fmt.Errorf("get organization has children: %w", err)
+	}
+
+	if hasChildren {
+		return nil, apierror.NewFailedPreconditionError("organization has child organizations; delete or move them first", fmt.Errorf("organization has child organizations"))
+	}
+
 	auditOrganization, err := s.auditlogStore.GetOrganization(ctx, tx, qOrg.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get audit organization: %w", err)
@@ -435,6 +529,11 @@ func (s *Store) DeleteOrganization(ctx context.Context, req *backendv1.DeleteOrg
 		return nil, fmt.Errorf("delete organization: %w", err)
 	}
 
+	// clean up the identities of users that were only in this organization
+	if err := q.DeleteUnusedProjectIdentities(ctx, authn.ProjectID(ctx)); err != nil {
+		return nil, fmt.Errorf("delete unused project identities: %w", err)
+	}
+
 	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
 		EventName: "tesseral.organizations.delete",
 		EventDetails: &auditlogv1.DeleteOrganization{
@@ -447,16 +546,77 @@ func (s *Store) DeleteOrganization(ctx context.Context, req *backendv1.DeleteOrg
 		return nil, fmt.Errorf("log audit event: %w", err)
 	}
 
+	// Send webhook event
+	if err := s.sendSyncOrganizationEvent(ctx, q, qOrg); err != nil {
+		return nil, fmt.Errorf("send sync organization event: %w", err)
+	}
+
 	if err := commit(); err != nil {
 		return nil, fmt.Errorf("commit: %w", err)
 	}
 
-	// Send webhook event
-	if err := s.sendSyncOrganizationEvent(ctx, qOrg); err != nil {
-		return nil, fmt.Errorf("send sync organization event: %w", err)
+	return &backendv1.DeleteOrganizationResponse{}, nil
+}
+
+func (s *Store) ListOrganizationDescendants(ctx context.Context, req *backendv1.ListOrganizationDescendantsRequest) (*backendv1.ListOrganizationDescendantsResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer rollback()
 
-	return &backendv1.DeleteOrganizationResponse{}, nil
+	orgID, err := idformat.Organization.Parse(req.OrganizationId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+	}
+
+	// authz check
+	if _, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        orgID,
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("organization not found", fmt.Errorf("get organization by id: %w", err))
+		}
+
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+
+	var startID uuid.UUID
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, err
+	}
+
+	qProject, err := q.GetProjectByID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get project by id: %w", err)
+	}
+
+	limit := 10
+	qOrgs, err := q.ListOrganizationDescendants(ctx, queries.ListOrganizationDescendantsParams{
+		OrganizationID: orgID,
+		StartID:        startID,
+		Limit:          int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list organization descendants: %w", err)
+	}
+
+	var organizations []*backendv1.Organization
+	for _, qOrg := range qOrgs {
+		organizations = append(organizations, parseOrganization(qProject, qOrg))
+	}
+
+	var nextPageToken string
+	if len(organizations) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qOrgs[limit].ID)
+		organizations = organizations[:limit]
+	}
+
+	return &backendv1.ListOrganizationDescendantsResponse{
+		Organizations: organizations,
+		NextPageToken: nextPageToken,
+	}, nil
 }
 
 func (s *Store) DisableOrganizationLogins(ctx context.Context, req *backendv1.DisableOrganizationLoginsRequest) (*backendv1.DisableOrganizationLoginsResponse, error) {
@@ -507,35 +667,71 @@ func (s *Store) EnableOrganizationLogins(ctx context.Context, req *backendv1.Ena
 	return &backendv1.EnableOrganizationLoginsResponse{}, nil
 }
 
-func (s *Store) sendSyncOrganizationEvent(ctx context.Context, qOrg queries.Organization) error {
-	qProjectWebhookSettings, err := s.q.GetProjectWebhookSettings(ctx, authn.ProjectID(ctx))
-	if err != nil {
-		// We want to ignore this error if the project does not have webhook settings
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil
-		}
-		return fmt.Errorf("get project by id: %w", err)
-	}
-
-	message, err := s.svixClient.Message.Create(ctx, qProjectWebhookSettings.AppID, models.MessageIn{
+func (s *Store) sendSyncOrganizationEvent(ctx context.Context, q *queries.Queries, qOrg queries.Organization) error {
+	if err := s.enqueueWebhookMessage(ctx, q, authn.ProjectID(ctx), webhooks.Message{
 		EventType: "sync.organization",
 		Payload: map[string]interface{}{
 			"type":           "sync.organization",
 			"organizationId": idformat.Organization.Format(qOrg.ID),
 		},
-	}, nil)
+	}); err != nil {
+		return fmt.Errorf("enqueue webhook message: %w", err)
+	}
+
+	return nil
+}
+
+// getParentOrganizationID parses and validates the parent organization ID of
+// the organization with the given ID. The parent must be in the same project,
+// and must not be the organization itself or one of its descendants. It
+// returns nil if parentOrganizationID is empty.
+func (s *Store) getParentOrganizationID(ctx context.Context, q *queries.Queries, orgID uuid.UUID, parentOrganizationID string) (*uuid.UUID, error) {
+	if parentOrganizationID == "" {
+		return nil, nil
+	}
+
+	parentOrgID, err := idformat.Organization.Parse(parentOrganizationID)
 	if err != nil {
-		return fmt.Errorf("create message: %w", err)
+		return nil, apierror.NewInvalidArgumentError("invalid parent organization id", fmt.Errorf("parse parent organization id: %w", err))
 	}
 
-	slog.InfoContext(ctx, "svix_message_created", "message_id", message.Id, "event_type", message.EventType, "organization_id", idformat.Organization.Format(qOrg.ID))
+	if _, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        parentOrgID,
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("parent organization not found", fmt.Errorf("get parent organization: %w", err))
+		}
+
+		return nil, fmt.Errorf("get parent organization: %w", err)
+	}
 
-	return nil
+	// lock the project so that concurrent changes to the hierarchy cannot
+	// together create a cycle that neither sees on its own
+	if _, err := q.GetProjectByIDForUpdate(ctx, authn.ProjectID(ctx)); err != nil {
+		return nil, fmt.Errorf("get project by id for update: %w", err)
+	}
+
+	ancestorIDs, err := q.GetOrganizationAncestorIDs(ctx, parentOrgID)
+	if err != nil {
+		return nil, fmt.Errorf("get organization ancestor ids: %w", err)
+	}
+
+	if parentOrgID == orgID || slices.Contains(ancestorIDs, orgID) {
+		return nil, apierror.NewInvalidArgumentError("an organization cannot be its own ancestor", fmt.Errorf("parent organization is a descendant of organization"))
+	}
+
+	return (*uuid.UUID)(&parentOrgID), nil
 }
 
 func parseOrganization(qProject queries.Project, qOrg queries.Organization) *backendv1.Organization {
 	apiKeysEnabled := qProject.EntitledBackendApiKeys && qProject.ApiKeysEnabled && qOrg.ApiKeysEnabled
 
+	var parentOrganizationID *string
+	if qOrg.ParentOrganizationID != nil {
+		parentOrganizationID = refOrNil(idformat.Organization.Format(*qOrg.ParentOrganizationID))
+	}
+
 	return &backendv1.Organization{
 		Id:                        idformat.Organization.Format(qOrg.ID),
 		DisplayName:               qOrg.DisplayName,
@@ -554,5 +750,10 @@ func parseOrganization(qProject queries.Project, qOrg queries.Organization) *bac
 		ScimEnabled:               &qOrg.ScimEnabled,
 		CustomRolesEnabled:        &qOrg.CustomRolesEnabled,
 		ApiKeysEnabled:            &apiKeysEnabled,
+		AuditLogRetentionDays:     qOrg.AuditLogRetentionDays,
+		ParentOrganizationId:      parentOrganizationID,
+		EnforceSsoForDomains:      &qOrg.EnforceSsoForDomains,
+		PublicMetadata:            parseMetadata(qOrg.PublicMetadata),
+		PrivateMetadata:           parseMetadata(qOrg.PrivateMetadata),
 	}
 }
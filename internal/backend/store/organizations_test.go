@@ -199,3 +199,88 @@ func TestDeleteOrganization_OIDCConnections(t *testing.T) {
 	_, err = u.Store.DeleteOrganization(ctx, &backendv1.DeleteOrganizationRequest{Id: orgID})
 	require.NoError(t, err)
 }
+
+func TestOrganizationHierarchy(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createOrg := func(displayName, parentOrganizationID string) string {
+		resp, err := u.Store.CreateOrganization(ctx, &backendv1.CreateOrganizationRequest{
+			Organization: &backendv1.Organization{
+				DisplayName:          displayName,
+				ParentOrganizationId: &parentOrganizationID,
+			},
+		})
+		require.NoError(t, err)
+		return resp.Organization.Id
+	}
+
+	rootID := createOrg("root", "")
+	childID := createOrg("child", rootID)
+	grandchildID := createOrg("grandchild", childID)
+
+	getResp, err := u.Store.GetOrganization(ctx, &backendv1.GetOrganizationRequest{Id: grandchildID})
+	require.NoError(t, err)
+	require.Equal(t, childID, getResp.Organization.GetParentOrganizationId())
+
+	descendantsResp, err := u.Store.ListOrganizationDescendants(ctx, &backendv1.ListOrganizationDescendantsRequest{OrganizationId: rootID})
+	require.NoError(t, err)
+
+	var descendantIDs []string
+	for _, org := range descendantsResp.Organizations {
+		descendantIDs = append(descendantIDs, org.Id)
+	}
+	require.ElementsMatch(t, []string{childID, grandchildID}, descendantIDs)
+
+	// an organization cannot become a descendant of itself
+	_, err = u.Store.UpdateOrganization(ctx, &backendv1.UpdateOrganizationRequest{
+		Id: rootID,
+		Organization: &backendv1.Organization{
+			ParentOrganizationId: &grandchildID,
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	// organizations with children cannot be deleted
+	_, err = u.Store.DeleteOrganization(ctx, &backendv1.DeleteOrganizationRequest{Id: childID})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+
+	// moving the grandchild to the root leaves the child without children
+	root := ""
+	updateResp, err := u.Store.UpdateOrganization(ctx, &backendv1.UpdateOrganizationRequest{
+		Id: grandchildID,
+		Organization: &backendv1.Organization{
+			ParentOrganizationId: &root,
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, updateResp.Organization.ParentOrganizationId)
+
+	_, err = u.Store.DeleteOrganization(ctx, &backendv1.DeleteOrganizationRequest{Id: childID})
+	require.NoError(t, err)
+}
+
+func TestUpdateOrganization_EnforceSSORequiresSSO(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName:   "test",
+		LogInWithSaml: refOrNil(false),
+		LogInWithOidc: refOrNil(false),
+	})
+
+	_, err := u.Store.UpdateOrganization(ctx, &backendv1.UpdateOrganizationRequest{
+		Id: orgID,
+		Organization: &backendv1.Organization{
+			EnforceSsoForDomains: refOrNil(true),
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+}
@@ -29,10 +29,11 @@ func (s *Store) ListPasskeys(ctx context.Context, req *backendv1.ListPasskeysReq
 		return nil, fmt.Errorf("parse user id: %w", err)
 	}
 
-	if _, err := q.GetUser(ctx, queries.GetUserParams{
+	qUser, err := q.GetUser(ctx, queries.GetUserParams{
 		ID:        userID,
 		ProjectID: authn.ProjectID(ctx),
-	}); err != nil {
+	})
+	if err != nil {
 		return nil, fmt.Errorf("get user: %w", err)
 	}
 
@@ -43,9 +44,9 @@ func (s *Store) ListPasskeys(ctx context.Context, req *backendv1.ListPasskeysReq
 
 	limit := 10
 	qPasskeys, err := q.ListPasskeys(ctx, queries.ListPasskeysParams{
-		UserID: userID,
-		ID:     startID,
-		Limit:  int32(limit + 1),
+		IdentityID: qUser.IdentityID,
+		ID:         startID,
+		Limit:      int32(limit + 1),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("list passkeys: %w", err)
@@ -119,15 +120,9 @@ func (s *Store) UpdatePasskey(ctx context.Context, req *backendv1.UpdatePasskeyR
 		return nil, fmt.Errorf("get passkey: %w", err)
 	}
 
-	qUser, err := q.GetUser(ctx, queries.GetUserParams{
-		ID:        qPasskey.UserID,
-		ProjectID: authn.ProjectID(ctx),
-	})
+	organizationID, err := s.getPasskeyOrganizationID(ctx, q, qPasskey)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, apierror.NewNotFoundError("user not found", fmt.Errorf("get user: %w", err))
-		}
-		return nil, fmt.Errorf("get user: %w", err)
+		return nil, fmt.Errorf("get passkey organization id: %w", err)
 	}
 
 	auditPreviousPasskey, err := s.auditlogStore.GetPasskey(ctx, tx, qPasskey.ID)
@@ -159,7 +154,7 @@ func (s *Store) UpdatePasskey(ctx context.Context, req *backendv1.UpdatePasskeyR
 			Passkey:         auditPasskey,
 			PreviousPasskey: auditPreviousPasskey,
 		},
-		OrganizationID: &qUser.OrganizationID,
+		OrganizationID: organizationID,
 		ResourceType:   queries.AuditLogEventResourceTypePasskey,
 		ResourceID:     &qUpdatedPasskey.ID,
 	}); err != nil {
@@ -197,15 +192,9 @@ func (s *Store) DeletePasskey(ctx context.Context, req *backendv1.DeletePasskeyR
 		return nil, fmt.Errorf("get passkey: %w", err)
 	}
 
-	qUser, err := q.GetUser(ctx, queries.GetUserParams{
-		ID:        qPasskey.UserID,
-		ProjectID: authn.ProjectID(ctx),
-	})
+	organizationID, err := s.getPasskeyOrganizationID(ctx, q, qPasskey)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, apierror.NewNotFoundError("user not found", fmt.Errorf("get user: %w", err))
-		}
-		return nil, fmt.Errorf("get user: %w", err)
+		return nil, fmt.Errorf("get passkey organization id: %w", err)
 	}
 
 	auditPasskey, err := s.auditlogStore.GetPasskey(ctx, tx, qPasskey.ID)
@@ -222,7 +211,7 @@ func (s *Store) DeletePasskey(ctx context.Context, req *backendv1.DeletePasskeyR
 		EventDetails: &auditlogv1.DeletePasskey{
 			Passkey: auditPasskey,
 		},
-		OrganizationID: &qUser.OrganizationID,
+		OrganizationID: organizationID,
 		ResourceType:   queries.AuditLogEventResourceTypePasskey,
 		ResourceID:     &qPasskey.ID,
 	}); err != nil {
@@ -236,10 +225,34 @@ func (s *Store) DeletePasskey(ctx context.Context, req *backendv1.DeletePasskeyR
 	return &backendv1.DeletePasskeyResponse{}, nil
 }
 
+// getPasskeyOrganizationID returns the organization of the user that registered
+// a passkey. Passkeys belong to identities, and so outlive the user that
+// registered them; in that case, getPasskeyOrganizationID returns nil.
+func (s *Store) getPasskeyOrganizationID(ctx context.Context, q *queries.Queries, qPasskey queries.Passkey) (*uuid.UUID, error) {
+	if qPasskey.UserID == nil {
+		return nil, nil
+	}
+
+	qUser, err := q.GetUser(ctx, queries.GetUserParams{
+		ID:        *qPasskey.UserID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	return &qUser.OrganizationID, nil
+}
+
 func parsePasskey(qPasskey queries.Passkey) *backendv1.Passkey {
+	var userID string
+	if qPasskey.UserID != nil {
+		userID = idformat.User.Format(*qPasskey.UserID)
+	}
+
 	return &backendv1.Passkey{
 		Id:           idformat.Passkey.Format(qPasskey.ID),
-		UserId:       idformat.User.Format(qPasskey.UserID),
+		UserId:       userID,
 		CreateTime:   timestamppb.New(*qPasskey.CreateTime),
 		UpdateTime:   timestamppb.New(*qPasskey.UpdateTime),
 		Disabled:     &qPasskey.Disabled,
@@ -16,8 +16,8 @@ func (u *testUtil) newPasskey(t *testing.T, userID string) string {
 	require.NoError(t, err)
 
 	_, err = u.Environment.DB.Exec(t.Context(), `
-	INSERT INTO passkeys (id, user_id, credential_id, public_key, aaguid, rp_id)
-	VALUES ($1::uuid, $2::uuid, $3, $4, $5, $6)
+	INSERT INTO passkeys (id, identity_id, user_id, credential_id, public_key, aaguid, rp_id)
+	VALUES ($1::uuid, (SELECT identity_id FROM users WHERE id = $2::uuid), $2::uuid, $3, $4, $5, $6)
 	`,
 		passkeyID.String(),
 		uuid.UUID(userUUID).String(),
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func (s *Store) CheckPermission(ctx context.Context, req *backendv1.CheckPermissionRequest) (*backendv1.CheckPermissionResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	if (req.ResourceType == "") != (req.ResourceId == "") {
+		return nil, apierror.NewInvalidArgumentError("resource_type and resource_id must be provided together", fmt.Errorf("resource_type and resource_id must be provided together"))
+	}
+
+	qUser, err := s.getPermissionUser(ctx, q, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateProjectAction(ctx, q, req.Action); err != nil {
+		return nil, err
+	}
+
+	// owners may perform every action, consistent with the actions claim in
+	// their access tokens
+	if qUser.IsOwner {
+		return &backendv1.CheckPermissionResponse{Permitted: true}, nil
+	}
+
+	permitted, err := q.CheckUserPermission(ctx, queries.CheckUserPermissionParams{
+		UserID:       qUser.ID,
+		Action:       req.Action,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("check user permission: %w", err)
+	}
+
+	return &backendv1.CheckPermissionResponse{Permitted: permitted}, nil
+}
+
+func (s *Store) ListPermittedResources(ctx context.Context, req *backendv1.ListPermittedResourcesRequest) (*backendv1.ListPermittedResourcesResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	if req.ResourceType == "" {
+		return nil, apierror.NewInvalidArgumentError("resource_type is required", fmt.Errorf("resource_type is required"))
+	}
+
+	qUser, err := s.getPermissionUser(ctx, q, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateProjectAction(ctx, q, req.Action); err != nil {
+		return nil, err
+	}
+
+	allResources := qUser.IsOwner
+	if !allResources {
+		permitted, err := q.CheckUserPermission(ctx, queries.CheckUserPermissionParams{
+			UserID: qUser.ID,
+			Action: req.Action,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("check user permission: %w", err)
+		}
+
+		allResources = permitted
+	}
+
+	var startResourceID string
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startResourceID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	resourceIDs, err := q.ListUserPermittedResourceIDs(ctx, queries.ListUserPermittedResourceIDsParams{
+		UserID:          qUser.ID,
+		ResourceType:    req.ResourceType,
+		StartResourceID: startResourceID,
+		Action:          req.Action,
+		Limit:           int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list user permitted resource ids: %w", err)
+	}
+
+	var nextPageToken string
+	if len(resourceIDs) == limit+1 {
+		resourceIDs = resourceIDs[:limit]
+		nextPageToken = s.pageEncoder.Marshal(resourceIDs[limit-1])
+	}
+
+	return &backendv1.ListPermittedResourcesResponse{
+		AllResources:  allResources,
+		ResourceIds:   resourceIDs,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+func (s *Store) getPermissionUser(ctx context.Context, q *queries.Queries, userID string) (*queries.User, error) {
+	id, err := idformat.User.Parse(userID)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid user id", fmt.Errorf("parse user id: %w", err))
+	}
+
+	qUser, err := q.GetUser(ctx, queries.GetUserParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        id,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("user not found", fmt.Errorf("get user: %w", err))
+		}
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	return &qUser, nil
+}
+
+func validateProjectAction(ctx context.Context, q *queries.Queries, action string) error {
+	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return fmt.Errorf("get actions: %w", err)
+	}
+
+	if !slices.ContainsFunc(qActions, func(qAction queries.Action) bool {
+		return qAction.Name == action
+	}) {
+		return apierror.NewInvalidArgumentError(fmt.Sprintf("invalid action %q", action), fmt.Errorf("action %q not found", action))
+	}
+	return nil
+}
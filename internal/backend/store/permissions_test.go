@@ -0,0 +1,249 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func TestCheckPermission_InheritedAndScoped(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "test@example.com",
+	})
+
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO actions (id, project_id, name, description)
+  VALUES (gen_random_uuid(), $1::uuid, $2, $2),
+  		 (gen_random_uuid(), $1::uuid, $3, $3);
+`,
+		uuid.UUID(projectID).String(),
+		"test.read",
+		"test.write",
+	)
+	require.NoError(t, err)
+
+	readerResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName: "reader",
+			Actions:     []string{"test.read"},
+		},
+	})
+	require.NoError(t, err)
+
+	editorResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			OrganizationId:   orgID,
+			DisplayName:      "editor",
+			Actions:          []string{"test.write"},
+			InheritedRoleIds: []string{readerResp.Role.Id},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{readerResp.Role.Id}, editorResp.Role.InheritedRoleIds)
+
+	_, err = u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId:       userID,
+			RoleId:       editorResp.Role.Id,
+			ResourceType: "document",
+			ResourceId:   "doc_1",
+		},
+	})
+	require.NoError(t, err)
+
+	checkResp, err := u.Store.CheckPermission(ctx, &backendv1.CheckPermissionRequest{
+		UserId:       userID,
+		Action:       "test.read",
+		ResourceType: "document",
+		ResourceId:   "doc_1",
+	})
+	require.NoError(t, err)
+	require.True(t, checkResp.Permitted)
+
+	checkResp, err = u.Store.CheckPermission(ctx, &backendv1.CheckPermissionRequest{
+		UserId:       userID,
+		Action:       "test.read",
+		ResourceType: "document",
+		ResourceId:   "doc_2",
+	})
+	require.NoError(t, err)
+	require.False(t, checkResp.Permitted)
+
+	checkResp, err = u.Store.CheckPermission(ctx, &backendv1.CheckPermissionRequest{
+		UserId: userID,
+		Action: "test.read",
+	})
+	require.NoError(t, err)
+	require.False(t, checkResp.Permitted)
+
+	listResp, err := u.Store.ListPermittedResources(ctx, &backendv1.ListPermittedResourcesRequest{
+		UserId:       userID,
+		Action:       "test.write",
+		ResourceType: "document",
+	})
+	require.NoError(t, err)
+	require.False(t, listResp.AllResources)
+	require.Equal(t, []string{"doc_1"}, listResp.ResourceIds)
+	require.Empty(t, listResp.NextPageToken)
+}
+
+func TestCheckPermission_InheritedFromParentOrganization(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	parentOrgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "parent",
+	})
+	childOrgResp, err := u.Store.CreateOrganization(ctx, &backendv1.CreateOrganizationRequest{
+		Organization: &backendv1.Organization{
+			DisplayName:          "child",
+			ParentOrganizationId: &parentOrgID,
+		},
+	})
+	require.NoError(t, err)
+
+	parentUserID := u.Environment.NewUser(t, parentOrgID, &backendv1.User{
+		Email: "admin@example.com",
+	})
+	childUserID := u.Environment.NewUser(t, childOrgResp.Organization.Id, &backendv1.User{
+		Email: "admin@example.com",
+	})
+
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO actions (id, project_id, name, description)
+  VALUES (gen_random_uuid(), $1::uuid, $2, $2);
+`,
+		uuid.UUID(projectID).String(),
+		"test.manage",
+	)
+	require.NoError(t, err)
+
+	roleResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName: "manager",
+			Actions:     []string{"test.manage"},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId: parentUserID,
+			RoleId: roleResp.Role.Id,
+		},
+	})
+	require.NoError(t, err)
+
+	checkResp, err := u.Store.CheckPermission(ctx, &backendv1.CheckPermissionRequest{
+		UserId: childUserID,
+		Action: "test.manage",
+	})
+	require.NoError(t, err)
+	require.False(t, checkResp.Permitted)
+
+	_, err = u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId:               parentUserID,
+			RoleId:               roleResp.Role.Id,
+			InheritToDescendants: true,
+		},
+	})
+	require.NoError(t, err)
+
+	checkResp, err = u.Store.CheckPermission(ctx, &backendv1.CheckPermissionRequest{
+		UserId: childUserID,
+		Action: "test.manage",
+	})
+	require.NoError(t, err)
+	require.True(t, checkResp.Permitted)
+}
+
+func TestCheckPermission_Unscoped(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "test@example.com",
+	})
+
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO actions (id, project_id, name, description)
+  VALUES (gen_random_uuid(), $1::uuid, $2, $2);
+`,
+		uuid.UUID(projectID).String(),
+		"test.read",
+	)
+	require.NoError(t, err)
+
+	roleResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName: "reader",
+			Actions:     []string{"test.read"},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId: userID,
+			RoleId: roleResp.Role.Id,
+		},
+	})
+	require.NoError(t, err)
+
+	checkResp, err := u.Store.CheckPermission(ctx, &backendv1.CheckPermissionRequest{
+		UserId:       userID,
+		Action:       "test.read",
+		ResourceType: "document",
+		ResourceId:   "doc_1",
+	})
+	require.NoError(t, err)
+	require.True(t, checkResp.Permitted)
+
+	listResp, err := u.Store.ListPermittedResources(ctx, &backendv1.ListPermittedResourcesRequest{
+		UserId:       userID,
+		Action:       "test.read",
+		ResourceType: "document",
+	})
+	require.NoError(t, err)
+	require.True(t, listResp.AllResources)
+}
+
+func TestCheckPermission_InvalidAction(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "test@example.com",
+	})
+
+	_, err := u.Store.CheckPermission(ctx, &backendv1.CheckPermissionRequest{
+		UserId: userID,
+		Action: "test.nonexistent",
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
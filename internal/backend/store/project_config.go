@@ -0,0 +1,458 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+)
+
+const (
+	projectConfigResourceTypeProject    = "project"
+	projectConfigResourceTypeUISettings = "ui_settings"
+	projectConfigResourceTypeAction     = "action"
+	projectConfigResourceTypeRole       = "role"
+
+	projectConfigChangeTypeCreate = "create"
+	projectConfigChangeTypeUpdate = "update"
+	projectConfigChangeTypeDelete = "delete"
+)
+
+// ApplyProjectConfig makes the current project match req.ProjectConfig, in a
+// single transaction. The changes are computed against the current state of
+// the project; if req.DryRun is set, they are returned but not made.
+//
+// Unlike UpdateProject, ApplyProjectConfig may be called with Backend API Keys,
+// so that a project's configuration can be managed from CI. To limit what such
+// a key can do, a Project Config cannot set OAuth client secrets.
+func (s *Store) ApplyProjectConfig(ctx context.Context, req *backendv1.ApplyProjectConfigRequest) (*backendv1.ApplyProjectConfigResponse, error) {
+	projectConfig := req.ProjectConfig
+	if projectConfig == nil {
+		return nil, apierror.NewInvalidArgumentError("project config is required", fmt.Errorf("project config is nil"))
+	}
+
+	if err := validateProjectConfig(projectConfig); err != nil {
+		return nil, err
+	}
+
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	var changes []*backendv1.ProjectConfigChange
+
+	if projectConfig.Project != nil {
+		qProject, err := q.GetProjectByID(ctx, authn.ProjectID(ctx))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("project not found", fmt.Errorf("get project by id: %w", err))
+			}
+
+			return nil, fmt.Errorf("get project by id: %w", err)
+		}
+
+		qProjectTrustedDomains, err := q.GetProjectTrustedDomains(ctx, authn.ProjectID(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("get project trusted domains: %w", err)
+		}
+
+		// updates from an empty project are the project's current settings
+		currentUpdates, err := s.getProjectUpdates(ctx, qProject, &backendv1.Project{})
+		if err != nil {
+			return nil, err
+		}
+
+		updates, err := s.getProjectUpdates(ctx, qProject, projectConfig.Project)
+		if err != nil {
+			return nil, err
+		}
+
+		fields := diffProjectUpdates(currentUpdates, updates)
+
+		if len(projectConfig.Project.TrustedDomains) > 0 {
+			currentTrustedDomains := map[string]struct{}{}
+			for _, qProjectTrustedDomain := range qProjectTrustedDomains {
+				currentTrustedDomains[qProjectTrustedDomain.Domain] = struct{}{}
+			}
+
+			if !reflect.DeepEqual(currentTrustedDomains, s.getProjectTrustedDomainSet(qProject, projectConfig.Project.TrustedDomains)) {
+				fields = append(fields, "trusted_domains")
+			}
+		}
+
+		if len(fields) > 0 {
+			changes = append(changes, &backendv1.ProjectConfigChange{
+				ResourceType: projectConfigResourceTypeProject,
+				ChangeType:   projectConfigChangeTypeUpdate,
+				Fields:       fields,
+			})
+
+			if !req.DryRun {
+				if _, _, err := s.updateProject(ctx, q, updates, projectConfig.Project.TrustedDomains); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if projectConfig.UiSettings != nil {
+		qProjectUISettings, err := q.GetProjectUISettings(ctx, authn.ProjectID(ctx))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("project ui settings not found", fmt.Errorf("get project ui settings: %w", err))
+			}
+
+			return nil, fmt.Errorf("get project ui settings: %w", err)
+		}
+
+		if fields := diffProjectUISettings(qProjectUISettings, projectConfig.UiSettings); len(fields) > 0 {
+			changes = append(changes, &backendv1.ProjectConfigChange{
+				ResourceType: projectConfigResourceTypeUISettings,
+				ChangeType:   projectConfigChangeTypeUpdate,
+				Fields:       fields,
+			})
+
+			if !req.DryRun {
+				if _, err := s.updateProjectUISettings(ctx, q, &backendv1.UpdateProjectUISettingsRequest{
+					LogInLayout:                  projectConfig.UiSettings.LogInLayout,
+					PrimaryColor:                 projectConfig.UiSettings.PrimaryColor,
+					DetectDarkModeEnabled:        projectConfig.UiSettings.DetectDarkModeEnabled,
+					DarkModePrimaryColor:         projectConfig.UiSettings.DarkModePrimaryColor,
+					AutoCreateOrganizations:      projectConfig.UiSettings.AutoCreateOrganizations,
+					SelfServeCreateOrganizations: projectConfig.UiSettings.SelfServeCreateOrganizations,
+					SelfServeCreateUsers:         projectConfig.UiSettings.SelfServeCreateUsers,
+				}); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if projectConfig.Rbac != nil {
+		qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("get actions: %w", err)
+		}
+
+		actionChanges := diffActions(qActions, projectConfig.Rbac.Actions)
+		changes = append(changes, actionChanges...)
+
+		if len(actionChanges) > 0 && !req.DryRun {
+			if _, err := s.updateRBACPolicy(ctx, q, &backendv1.RBACPolicy{Actions: projectConfig.Rbac.Actions}); err != nil {
+				return nil, err
+			}
+		}
+
+		qRoles, err := q.GetProjectRoles(ctx, authn.ProjectID(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("get project roles: %w", err)
+		}
+
+		var qRoleIDs []uuid.UUID
+		for _, qRole := range qRoles {
+			qRoleIDs = append(qRoleIDs, qRole.ID)
+		}
+
+		qRoleActions, err := q.BatchGetRoleActionsByRoleID(ctx, qRoleIDs)
+		if err != nil {
+			return nil, fmt.Errorf("batch get role actions by role ids: %w", err)
+		}
+
+		// qActions, not the updated actions, are what qRoleActions refer to
+		var currentRoles []*backendv1.Role
+		for _, qRole := range qRoles {
+			currentRoles = append(currentRoles, parseRole(qRole, qRoleActions, nil, qActions))
+		}
+
+		roleChanges, err := diffRoles(currentRoles, projectConfig.Rbac.Roles)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, roleChanges...)
+
+		if !req.DryRun {
+			if err := s.applyRoleChanges(ctx, tx, q, currentRoles, projectConfig.Rbac.Roles, roleChanges); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !req.DryRun {
+		if err := commit(); err != nil {
+			return nil, fmt.Errorf("commit: %w", err)
+		}
+	}
+
+	return &backendv1.ApplyProjectConfigResponse{Changes: changes}, nil
+}
+
+func validateProjectConfig(projectConfig *backendv1.ProjectConfig) error {
+	if project := projectConfig.Project; project != nil {
+		if project.GoogleOauthClientSecret != "" || project.MicrosoftOauthClientSecret != "" || project.GithubOauthClientSecret != "" {
+			return apierror.NewInvalidArgumentError("oauth client secrets cannot be set in a project config", fmt.Errorf("project config contains oauth client secret"))
+		}
+	}
+
+	if projectConfig.Rbac == nil {
+		return nil
+	}
+
+	actionNames := map[string]struct{}{}
+	for _, action := range projectConfig.Rbac.Actions {
+		if err := validateActionName(action.Name); err != nil {
+			return fmt.Errorf("validate action name: %w", err)
+		}
+
+		if _, ok := actionNames[action.Name]; ok {
+			return apierror.NewInvalidArgumentError(fmt.Sprintf("duplicate action %q", action.Name), fmt.Errorf("duplicate action %q", action.Name))
+		}
+
+		actionNames[action.Name] = struct{}{}
+	}
+
+	roleDisplayNames := map[string]struct{}{}
+	for _, role := range projectConfig.Rbac.Roles {
+		if role.DisplayName == "" {
+			return apierror.NewInvalidArgumentError("roles must have a display name", fmt.Errorf("role display name is empty"))
+		}
+
+		if _, ok := roleDisplayNames[role.DisplayName]; ok {
+			return apierror.NewInvalidArgumentError(fmt.Sprintf("duplicate role %q", role.DisplayName), fmt.Errorf("duplicate role %q", role.DisplayName))
+		}
+
+		roleDisplayNames[role.DisplayName] = struct{}{}
+
+		if role.Id != "" || role.OrganizationId != "" || role.RoleTemplateId != "" || len(role.InheritedRoleIds) > 0 {
+			return apierror.NewInvalidArgumentError(fmt.Sprintf("role %q: id, organization_id, role_template_id, and inherited_role_ids cannot be set in a project config", role.DisplayName), fmt.Errorf("project config role has unsupported fields"))
+		}
+
+		for _, action := range role.Actions {
+			if _, ok := actionNames[action]; !ok {
+				return apierror.NewInvalidArgumentError(fmt.Sprintf("role %q: invalid action %q", role.DisplayName, action), fmt.Errorf("action %q not in project config", action))
+			}
+		}
+	}
+
+	return nil
+}
+
+// diffProjectUpdates returns the names of the fields that differ between two
+// sets of project update parameters.
+func diffProjectUpdates(a, b queries.UpdateProjectParams) []string {
+	var fields []string
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := range va.NumField() {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			fields = append(fields, snakeCase(va.Type().Field(i).Name))
+		}
+	}
+
+	return fields
+}
+
+func diffProjectUISettings(qProjectUISettings queries.ProjectUiSetting, uiSettings *backendv1.ProjectConfigUISettings) []string {
+	var fields []string
+	if uiSettings.LogInLayout != "" && uiSettings.LogInLayout != string(qProjectUISettings.LogInLayout) {
+		fields = append(fields, "log_in_layout")
+	}
+	if uiSettings.PrimaryColor != nil && *uiSettings.PrimaryColor != derefOrEmpty(qProjectUISettings.PrimaryColor) {
+		fields = append(fields, "primary_color")
+	}
+	if uiSettings.DetectDarkModeEnabled != nil && *uiSettings.DetectDarkModeEnabled != qProjectUISettings.DetectDarkModeEnabled {
+		fields = append(fields, "detect_dark_mode_enabled")
+	}
+	if uiSettings.DarkModePrimaryColor != nil && *uiSettings.DarkModePrimaryColor != derefOrEmpty(qProjectUISettings.DarkModePrimaryColor) {
+		fields = append(fields, "dark_mode_primary_color")
+	}
+	if uiSettings.AutoCreateOrganizations != nil && *uiSettings.AutoCreateOrganizations != qProjectUISettings.AutoCreateOrganizations {
+		fields = append(fields, "auto_create_organizations")
+	}
+	if uiSettings.SelfServeCreateOrganizations != nil && *uiSettings.SelfServeCreateOrganizations != qProjectUISettings.SelfServeCreateOrganizations {
+		fields = append(fields, "self_serve_create_organizations")
+	}
+	if uiSettings.SelfServeCreateUsers != nil && *uiSettings.SelfServeCreateUsers != qProjectUISettings.SelfServeCreateUsers {
+		fields = append(fields, "self_serve_create_users")
+	}
+
+	return fields
+}
+
+func diffActions(qActions []queries.Action, actions []*backendv1.Action) []*backendv1.ProjectConfigChange {
+	var changes []*backendv1.ProjectConfigChange
+	for _, action := range actions {
+		i := slices.IndexFunc(qActions, func(qAction queries.Action) bool {
+			return qAction.Name == action.Name
+		})
+
+		if i == -1 {
+			changes = append(changes, &backendv1.ProjectConfigChange{
+				ResourceType: projectConfigResourceTypeAction,
+				ResourceName: action.Name,
+				ChangeType:   projectConfigChangeTypeCreate,
+			})
+			continue
+		}
+
+		if qActions[i].Description != action.Description {
+			changes = append(changes, &backendv1.ProjectConfigChange{
+				ResourceType: projectConfigResourceTypeAction,
+				ResourceName: action.Name,
+				ChangeType:   projectConfigChangeTypeUpdate,
+				Fields:       []string{"description"},
+			})
+		}
+	}
+
+	for _, qAction := range qActions {
+		if !slices.ContainsFunc(actions, func(action *backendv1.Action) bool {
+			return action.Name == qAction.Name
+		}) {
+			changes = append(changes, &backendv1.ProjectConfigChange{
+				ResourceType: projectConfigResourceTypeAction,
+				ResourceName: qAction.Name,
+				ChangeType:   projectConfigChangeTypeDelete,
+			})
+		}
+	}
+
+	return changes
+}
+
+// diffRoles returns the changes needed to make currentRoles match roles. Roles
+// are matched by display name.
+func diffRoles(currentRoles, roles []*backendv1.Role) ([]*backendv1.ProjectConfigChange, error) {
+	currentRolesByDisplayName := map[string]*backendv1.Role{}
+	for _, currentRole := range currentRoles {
+		if _, ok := currentRolesByDisplayName[currentRole.DisplayName]; ok {
+			return nil, apierror.NewFailedPreconditionError(fmt.Sprintf("project has multiple roles named %q; rename or delete them before applying a project config", currentRole.DisplayName), fmt.Errorf("duplicate role display name %q", currentRole.DisplayName))
+		}
+
+		currentRolesByDisplayName[currentRole.DisplayName] = currentRole
+	}
+
+	var changes []*backendv1.ProjectConfigChange
+	for _, role := range roles {
+		currentRole, ok := currentRolesByDisplayName[role.DisplayName]
+		if !ok {
+			changes = append(changes, &backendv1.ProjectConfigChange{
+				ResourceType: projectConfigResourceTypeRole,
+				ResourceName: role.DisplayName,
+				ChangeType:   projectConfigChangeTypeCreate,
+			})
+			continue
+		}
+
+		var fields []string
+		if role.Description != "" && role.Description != currentRole.Description {
+			fields = append(fields, "description")
+		}
+
+		currentActions, actions := slices.Clone(currentRole.Actions), slices.Clone(role.Actions)
+		slices.Sort(currentActions)
+		slices.Sort(actions)
+		if !slices.Equal(currentActions, actions) {
+			fields = append(fields, "actions")
+		}
+
+		if role.Requestable != nil && *role.Requestable != currentRole.GetRequestable() {
+			fields = append(fields, "requestable")
+		}
+
+		if len(fields) > 0 {
+			changes = append(changes, &backendv1.ProjectConfigChange{
+				ResourceType: projectConfigResourceTypeRole,
+				ResourceName: role.DisplayName,
+				ChangeType:   projectConfigChangeTypeUpdate,
+				Fields:       fields,
+			})
+		}
+	}
+
+	for _, currentRole := range currentRoles {
+		if !slices.ContainsFunc(roles, func(role *backendv1.Role) bool {
+			return role.DisplayName == currentRole.DisplayName
+		}) {
+			changes = append(changes, &backendv1.ProjectConfigChange{
+				ResourceType: projectConfigResourceTypeRole,
+				ResourceName: currentRole.DisplayName,
+				ChangeType:   projectConfigChangeTypeDelete,
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+// applyRoleChanges makes the changes to roles returned by diffRoles.
+func (s *Store) applyRoleChanges(ctx context.Context, tx pgx.Tx, q *queries.Queries, currentRoles, roles []*backendv1.Role, changes []*backendv1.ProjectConfigChange) error {
+	for _, change := range changes {
+		switch change.ChangeType {
+		case projectConfigChangeTypeCreate:
+			i := slices.IndexFunc(roles, func(role *backendv1.Role) bool {
+				return role.DisplayName == change.ResourceName
+			})
+
+			if _, err := s.createRole(ctx, tx, q, roles[i]); err != nil {
+				return err
+			}
+		case projectConfigChangeTypeUpdate:
+			i := slices.IndexFunc(roles, func(role *backendv1.Role) bool {
+				return role.DisplayName == change.ResourceName
+			})
+			j := slices.IndexFunc(currentRoles, func(role *backendv1.Role) bool {
+				return role.DisplayName == change.ResourceName
+			})
+
+			actions := roles[i].Actions
+			if actions == nil {
+				actions = []string{} // non-nil, so that updateRole removes all actions
+			}
+
+			if _, err := s.updateRole(ctx, tx, q, currentRoles[j].Id, &backendv1.Role{
+				Description: roles[i].Description,
+				Actions:     actions,
+				Requestable: roles[i].Requestable,
+			}); err != nil {
+				return err
+			}
+		case projectConfigChangeTypeDelete:
+			j := slices.IndexFunc(currentRoles, func(role *backendv1.Role) bool {
+				return role.DisplayName == change.ResourceName
+			})
+
+			if err := s.deleteRole(ctx, tx, q, currentRoles[j].Id); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// snakeCase converts a Go field name, like GoogleOauthClientID, to the
+// corresponding proto field name, like google_oauth_client_id.
+func snakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
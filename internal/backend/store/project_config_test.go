@@ -0,0 +1,227 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+)
+
+func TestApplyProjectConfig(t *testing.T) {
+	t.Parallel()
+	ctx, u := newTestUtil(t)
+
+	projectConfig := &backendv1.ProjectConfig{
+		Project: &backendv1.Project{
+			DisplayName:           "config-project-name",
+			LogInWithGoogle:       refOrNil(false),
+			RedirectUri:           "https://app.example.com/callback",
+			AfterLoginRedirectUri: refOrNil("https://app.example.com/home"),
+			TrustedDomains:        []string{"app.example.com"},
+		},
+		UiSettings: &backendv1.ProjectConfigUISettings{
+			PrimaryColor: refOrNil("#123456"),
+		},
+		Rbac: &backendv1.ProjectConfigRBAC{
+			Actions: []*backendv1.Action{
+				{Name: "acme.widgets.read", Description: "Read widgets"},
+				{Name: "acme.widgets.write", Description: "Write widgets"},
+			},
+			Roles: []*backendv1.Role{
+				{DisplayName: "Widget Reader", Actions: []string{"acme.widgets.read"}},
+				{DisplayName: "Widget Writer", Actions: []string{"acme.widgets.read", "acme.widgets.write"}},
+			},
+		},
+	}
+
+	planRes, err := u.Store.ApplyProjectConfig(ctx, &backendv1.ApplyProjectConfigRequest{
+		ProjectConfig: projectConfig,
+		DryRun:        true,
+	})
+	require.NoError(t, err)
+
+	var projectChange *backendv1.ProjectConfigChange
+	for _, change := range planRes.Changes {
+		if change.ResourceType == "project" {
+			projectChange = change
+		}
+	}
+	require.NotNil(t, projectChange)
+	require.Equal(t, "update", projectChange.ChangeType)
+	require.Subset(t, projectChange.Fields, []string{"display_name", "log_in_with_google", "redirect_uri", "after_login_redirect_uri", "trusted_domains"})
+	require.Contains(t, planRes.Changes, &backendv1.ProjectConfigChange{ResourceType: "ui_settings", ChangeType: "update", Fields: []string{"primary_color"}})
+	require.Contains(t, planRes.Changes, &backendv1.ProjectConfigChange{ResourceType: "action", ResourceName: "acme.widgets.read", ChangeType: "create"})
+	require.Contains(t, planRes.Changes, &backendv1.ProjectConfigChange{ResourceType: "role", ResourceName: "Widget Writer", ChangeType: "create"})
+
+	// dry runs make no changes
+	getRes, err := u.Store.GetProject(ctx, &backendv1.GetProjectRequest{})
+	require.NoError(t, err)
+	require.NotEqual(t, "config-project-name", getRes.Project.DisplayName)
+
+	applyRes, err := u.Store.ApplyProjectConfig(ctx, &backendv1.ApplyProjectConfigRequest{
+		ProjectConfig: projectConfig,
+	})
+	require.NoError(t, err)
+	require.Equal(t, len(planRes.Changes), len(applyRes.Changes))
+
+	getRes, err = u.Store.GetProject(ctx, &backendv1.GetProjectRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "config-project-name", getRes.Project.DisplayName)
+	require.False(t, getRes.Project.GetLogInWithGoogle())
+	require.Equal(t, "https://app.example.com/callback", getRes.Project.RedirectUri)
+	require.Equal(t, "https://app.example.com/home", getRes.Project.GetAfterLoginRedirectUri())
+	require.Contains(t, getRes.Project.TrustedDomains, "app.example.com")
+
+	getUISettingsRes, err := u.Store.GetProjectUISettings(ctx, &backendv1.GetProjectUISettingsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "#123456", getUISettingsRes.ProjectUiSettings.PrimaryColor)
+
+	listRolesRes, err := u.Store.ListRoles(ctx, &backendv1.ListRolesRequest{})
+	require.NoError(t, err)
+	var roleNames []string
+	for _, role := range listRolesRes.Roles {
+		roleNames = append(roleNames, role.DisplayName)
+	}
+	require.Subset(t, roleNames, []string{"Widget Reader", "Widget Writer"})
+
+	// applying the same config again is a no-op
+	applyRes, err = u.Store.ApplyProjectConfig(ctx, &backendv1.ApplyProjectConfigRequest{
+		ProjectConfig: projectConfig,
+	})
+	require.NoError(t, err)
+	require.Empty(t, applyRes.Changes)
+
+	// removing an action and a role deletes them, and updates roles using the
+	// action
+	applyRes, err = u.Store.ApplyProjectConfig(ctx, &backendv1.ApplyProjectConfigRequest{
+		ProjectConfig: &backendv1.ProjectConfig{
+			Rbac: &backendv1.ProjectConfigRBAC{
+				Actions: []*backendv1.Action{
+					{Name: "acme.widgets.read", Description: "Read widgets"},
+				},
+				Roles: []*backendv1.Role{
+					{DisplayName: "Widget Reader", Actions: []string{"acme.widgets.read"}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*backendv1.ProjectConfigChange{
+		{ResourceType: "action", ResourceName: "acme.widgets.write", ChangeType: "delete"},
+		{ResourceType: "role", ResourceName: "Widget Writer", ChangeType: "delete"},
+	}, applyRes.Changes)
+
+	getRBACPolicyRes, err := u.Store.GetRBACPolicy(ctx, &backendv1.GetRBACPolicyRequest{})
+	require.NoError(t, err)
+	require.Len(t, getRBACPolicyRes.RbacPolicy.Actions, 1)
+}
+
+func TestApplyProjectConfig_RoleActionsUpdated(t *testing.T) {
+	t.Parallel()
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.ApplyProjectConfig(ctx, &backendv1.ApplyProjectConfigRequest{
+		ProjectConfig: &backendv1.ProjectConfig{
+			Rbac: &backendv1.ProjectConfigRBAC{
+				Actions: []*backendv1.Action{{Name: "acme.widgets.read"}},
+				Roles: []*backendv1.Role{
+					{DisplayName: "Widget Reader", Actions: []string{"acme.widgets.read"}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	applyRes, err := u.Store.ApplyProjectConfig(ctx, &backendv1.ApplyProjectConfigRequest{
+		ProjectConfig: &backendv1.ProjectConfig{
+			Rbac: &backendv1.ProjectConfigRBAC{
+				Actions: []*backendv1.Action{{Name: "acme.widgets.read"}},
+				Roles: []*backendv1.Role{
+					{DisplayName: "Widget Reader", Description: "Reads widgets", Requestable: refOrNil(true)},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*backendv1.ProjectConfigChange{
+		{ResourceType: "role", ResourceName: "Widget Reader", ChangeType: "update", Fields: []string{"description", "actions", "requestable"}},
+	}, applyRes.Changes)
+
+	listRolesRes, err := u.Store.ListRoles(ctx, &backendv1.ListRolesRequest{})
+	require.NoError(t, err)
+	for _, role := range listRolesRes.Roles {
+		if role.DisplayName == "Widget Reader" {
+			require.Equal(t, "Reads widgets", role.Description)
+			require.Empty(t, role.Actions)
+			require.True(t, role.GetRequestable())
+		}
+	}
+}
+
+func TestApplyProjectConfig_Invalid(t *testing.T) {
+	t.Parallel()
+	ctx, u := newTestUtil(t)
+
+	testCases := []struct {
+		name          string
+		projectConfig *backendv1.ProjectConfig
+	}{
+		{
+			name: "oauth client secret",
+			projectConfig: &backendv1.ProjectConfig{
+				Project: &backendv1.Project{GoogleOauthClientSecret: "secret"},
+			},
+		},
+		{
+			name: "duplicate action",
+			projectConfig: &backendv1.ProjectConfig{
+				Rbac: &backendv1.ProjectConfigRBAC{
+					Actions: []*backendv1.Action{{Name: "acme.widgets.read"}, {Name: "acme.widgets.read"}},
+				},
+			},
+		},
+		{
+			name: "unknown role action",
+			projectConfig: &backendv1.ProjectConfig{
+				Rbac: &backendv1.ProjectConfigRBAC{
+					Roles: []*backendv1.Role{{DisplayName: "Widget Reader", Actions: []string{"acme.widgets.read"}}},
+				},
+			},
+		},
+		{
+			name: "role with inherited roles",
+			projectConfig: &backendv1.ProjectConfig{
+				Rbac: &backendv1.ProjectConfigRBAC{
+					Roles: []*backendv1.Role{{DisplayName: "Widget Reader", InheritedRoleIds: []string{"role_123"}}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := u.Store.ApplyProjectConfig(ctx, &backendv1.ApplyProjectConfigRequest{
+				ProjectConfig: tt.projectConfig,
+				DryRun:        true,
+			})
+
+			var connectErr *connect.Error
+			require.ErrorAs(t, err, &connectErr)
+			require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+		})
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	t.Parallel()
+
+	for name, want := range map[string]string{
+		"DisplayName":             "display_name",
+		"GoogleOauthClientID":     "google_oauth_client_id",
+		"LogInWithOidc":           "log_in_with_oidc",
+		"AuditLogArchiveS3Bucket": "audit_log_archive_s3_bucket",
+	} {
+		require.Equal(t, want, snakeCase(name))
+	}
+}
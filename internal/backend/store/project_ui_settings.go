@@ -82,13 +82,54 @@ func (s *Store) UpdateProjectUISettings(ctx context.Context, req *backendv1.Upda
 	}
 	defer rollback()
 
+	qUpdatedProjectUISettings, err := s.updateProjectUISettings(ctx, q, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	res := &backendv1.UpdateProjectUISettingsResponse{
+		Id:                      idformat.ProjectUISettings.Format(qUpdatedProjectUISettings.ID),
+		ProjectId:               idformat.Project.Format(authn.ProjectID(ctx)),
+		CreateTime:              timestamppb.New(*qUpdatedProjectUISettings.CreateTime),
+		UpdateTime:              timestamppb.New(*qUpdatedProjectUISettings.UpdateTime),
+		DarkModePrimaryColor:    derefOrEmpty(qUpdatedProjectUISettings.DarkModePrimaryColor),
+		DetectDarkModeEnabled:   qUpdatedProjectUISettings.DetectDarkModeEnabled,
+		PrimaryColor:            derefOrEmpty(qUpdatedProjectUISettings.PrimaryColor),
+		AutoCreateOrganizations: qUpdatedProjectUISettings.AutoCreateOrganizations,
+		LogInLayout:             string(qUpdatedProjectUISettings.LogInLayout),
+	}
+
+	// generate a presigned URL for the dark mode logo file
+	darkModeLogoPresignedUploadUrl, err := s.getPresignedUrlForFile(ctx, fmt.Sprintf("vault-ui-settings-v1/%s/logo-dark", idformat.Project.Format(authn.ProjectID(ctx))))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get presigned URL for dark mode logo file: %w", err)
+	}
+	res.DarkModeLogoPresignedUploadUrl = darkModeLogoPresignedUploadUrl
+
+	// generate a presigned URL for the logo file
+	logoPresignedUploadUrl, err := s.getPresignedUrlForFile(ctx, fmt.Sprintf("vault-ui-settings-v1/%s/logo", idformat.Project.Format(authn.ProjectID(ctx))))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get presigned URL for logo file: %w", err)
+	}
+	res.LogoPresignedUploadUrl = logoPresignedUploadUrl
+
+	return res, nil
+}
+
+// updateProjectUISettings updates the current project's UI settings. Fields of
+// req that are not set are left unchanged.
+func (s *Store) updateProjectUISettings(ctx context.Context, q *queries.Queries, req *backendv1.UpdateProjectUISettingsRequest) (queries.ProjectUiSetting, error) {
 	qProjectUISettings, err := q.GetProjectUISettings(ctx, authn.ProjectID(ctx))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, apierror.NewNotFoundError("project ui settings not found", fmt.Errorf("failed to get project ui settings: %w", err))
+			return queries.ProjectUiSetting{}, apierror.NewNotFoundError("project ui settings not found", fmt.Errorf("failed to get project ui settings: %w", err))
 		}
 
-		return nil, fmt.Errorf("failed to get project ui settings: %w", err)
+		return queries.ProjectUiSetting{}, fmt.Errorf("failed to get project ui settings: %w", err)
 	}
 
 	updates := queries.UpdateProjectUISettingsParams{
@@ -133,40 +174,10 @@ func (s *Store) UpdateProjectUISettings(ctx context.Context, req *backendv1.Upda
 
 	qUpdatedProjectUISettings, err := q.UpdateProjectUISettings(ctx, updates)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update project ui settings: %w", err)
-	}
-
-	if err := commit(); err != nil {
-		return nil, fmt.Errorf("commit: %w", err)
-	}
-
-	res := &backendv1.UpdateProjectUISettingsResponse{
-		Id:                      idformat.ProjectUISettings.Format(qUpdatedProjectUISettings.ID),
-		ProjectId:               idformat.Project.Format(authn.ProjectID(ctx)),
-		CreateTime:              timestamppb.New(*qUpdatedProjectUISettings.CreateTime),
-		UpdateTime:              timestamppb.New(*qUpdatedProjectUISettings.UpdateTime),
-		DarkModePrimaryColor:    derefOrEmpty(qUpdatedProjectUISettings.DarkModePrimaryColor),
-		DetectDarkModeEnabled:   qUpdatedProjectUISettings.DetectDarkModeEnabled,
-		PrimaryColor:            derefOrEmpty(qUpdatedProjectUISettings.PrimaryColor),
-		AutoCreateOrganizations: qUpdatedProjectUISettings.AutoCreateOrganizations,
-		LogInLayout:             string(qUpdatedProjectUISettings.LogInLayout),
-	}
-
-	// generate a presigned URL for the dark mode logo file
-	darkModeLogoPresignedUploadUrl, err := s.getPresignedUrlForFile(ctx, fmt.Sprintf("vault-ui-settings-v1/%s/logo-dark", idformat.Project.Format(authn.ProjectID(ctx))))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get presigned URL for dark mode logo file: %w", err)
+		return queries.ProjectUiSetting{}, fmt.Errorf("failed to update project ui settings: %w", err)
 	}
-	res.DarkModeLogoPresignedUploadUrl = darkModeLogoPresignedUploadUrl
 
-	// generate a presigned URL for the logo file
-	logoPresignedUploadUrl, err := s.getPresignedUrlForFile(ctx, fmt.Sprintf("vault-ui-settings-v1/%s/logo", idformat.Project.Format(authn.ProjectID(ctx))))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get presigned URL for logo file: %w", err)
-	}
-	res.LogoPresignedUploadUrl = logoPresignedUploadUrl
-
-	return res, nil
+	return qUpdatedProjectUISettings, nil
 }
 
 func (s *Store) buildPresignedGetUrlForFile(ctx context.Context, fileKey string) (string, error) {
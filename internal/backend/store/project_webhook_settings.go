@@ -2,11 +2,13 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
-	"github.com/svix/svix-webhooks/go/models"
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
 )
 
 func (s *Store) GetProjectWebhookManagementURL(ctx context.Context, req *backendv1.GetProjectWebhookManagementURLRequest) (*backendv1.GetProjectWebhookManagementURLResponse, error) {
@@ -21,12 +23,16 @@ func (s *Store) GetProjectWebhookManagementURL(ctx context.Context, req *backend
 		return nil, fmt.Errorf("get project webhook settings: %w", err)
 	}
 
-	dashboard, err := s.svixClient.Authentication.AppPortalAccess(ctx, qProjectWebhookSettings.AppID, models.AppPortalAccessIn{}, nil)
+	url, err := s.webhookDispatcher.ManagementURL(ctx, qProjectWebhookSettings.AppID)
 	if err != nil {
-		return nil, fmt.Errorf("get app portal access: %w", err)
+		if errors.Is(err, webhooks.ErrManagementURLUnsupported) {
+			return nil, apierror.NewFailedPreconditionError("webhook management url is not available; manage webhook endpoints directly instead", fmt.Errorf("get webhook management url: %w", err))
+		}
+
+		return nil, fmt.Errorf("get webhook management url: %w", err)
 	}
 
 	return &backendv1.GetProjectWebhookManagementURLResponse{
-		Url: dashboard.Url,
+		Url: url,
 	}, nil
 }
@@ -111,280 +111,362 @@ func (s *Store) UpdateProject(ctx context.Context, req *backendv1.UpdateProjectR
 		return nil, fmt.Errorf("get project by id: %w", err)
 	}
 
+	updates, err := s.getProjectUpdates(ctx, qProject, req.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qUpdatedProject, qProjectTrustedDomains, err := s.updateProject(ctx, q, updates, req.Project.TrustedDomains)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.UpdateProjectResponse{Project: s.parseProject(&qUpdatedProject, qProjectTrustedDomains)}, nil
+}
+
+// getProjectUpdates validates changes to a project, and returns the parameters
+// to update it with. Fields of project that are not set are left unchanged.
+func (s *Store) getProjectUpdates(ctx context.Context, qProject queries.Project, project *backendv1.Project) (queries.UpdateProjectParams, error) {
 	updates := queries.UpdateProjectParams{
 		ID: qProject.ID,
 	}
 
 	updates.DisplayName = qProject.DisplayName
-	if req.Project.DisplayName != "" {
-		updates.DisplayName = req.Project.DisplayName
+	if project.DisplayName != "" {
+		updates.DisplayName = project.DisplayName
 	}
 
 	updates.GoogleOauthClientID = qProject.GoogleOauthClientID
-	if req.Project.GoogleOauthClientId != nil {
-		updates.GoogleOauthClientID = req.Project.GoogleOauthClientId
+	if project.GoogleOauthClientId != nil {
+		updates.GoogleOauthClientID = project.GoogleOauthClientId
 	}
 
 	updates.GoogleOauthClientSecretCiphertext = qProject.GoogleOauthClientSecretCiphertext
-	if req.Project.GoogleOauthClientSecret != "" {
+	if project.GoogleOauthClientSecret != "" {
 		encryptRes, err := s.kms.Encrypt(ctx, &kms.EncryptInput{
 			KeyId:               &s.googleOAuthClientSecretsKMSKeyID,
-			Plaintext:           []byte(req.Project.GoogleOauthClientSecret),
+			Plaintext:           []byte(project.GoogleOauthClientSecret),
 			EncryptionAlgorithm: types.EncryptionAlgorithmSpecRsaesOaepSha256,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("encrypt google oauth client secret: %w", err)
+			return queries.UpdateProjectParams{}, fmt.Errorf("encrypt google oauth client secret: %w", err)
 		}
 
 		updates.GoogleOauthClientSecretCiphertext = encryptRes.CiphertextBlob
 	}
 
 	updates.MicrosoftOauthClientID = qProject.MicrosoftOauthClientID
-	if req.Project.MicrosoftOauthClientId != nil {
-		updates.MicrosoftOauthClientID = req.Project.MicrosoftOauthClientId
+	if project.MicrosoftOauthClientId != nil {
+		updates.MicrosoftOauthClientID = project.MicrosoftOauthClientId
 	}
 
 	updates.MicrosoftOauthClientSecretCiphertext = qProject.MicrosoftOauthClientSecretCiphertext
-	if req.Project.MicrosoftOauthClientSecret != "" {
+	if project.MicrosoftOauthClientSecret != "" {
 		encryptRes, err := s.kms.Encrypt(ctx, &kms.EncryptInput{
 			KeyId:               &s.microsoftOAuthClientSecretsKMSKeyID,
-			Plaintext:           []byte(req.Project.MicrosoftOauthClientSecret),
+			Plaintext:           []byte(project.MicrosoftOauthClientSecret),
 			EncryptionAlgorithm: types.EncryptionAlgorithmSpecRsaesOaepSha256,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("encrypt microsoft oauth client secret: %w", err)
+			return queries.UpdateProjectParams{}, fmt.Errorf("encrypt microsoft oauth client secret: %w", err)
 		}
 
 		updates.MicrosoftOauthClientSecretCiphertext = encryptRes.CiphertextBlob
 	}
 
 	updates.GithubOauthClientID = qProject.GithubOauthClientID
-	if req.Project.GithubOauthClientId != nil {
-		updates.GithubOauthClientID = req.Project.GithubOauthClientId
+	if project.GithubOauthClientId != nil {
+		updates.GithubOauthClientID = project.GithubOauthClientId
 	}
 
 	updates.GithubOauthClientSecretCiphertext = qProject.GithubOauthClientSecretCiphertext
-	if req.Project.GithubOauthClientSecret != "" {
+	if project.GithubOauthClientSecret != "" {
 		encryptRes, err := s.kms.Encrypt(ctx, &kms.EncryptInput{
 			KeyId:               &s.githubOAuthClientSecretsKMSKeyID,
-			Plaintext:           []byte(req.Project.GithubOauthClientSecret),
+			Plaintext:           []byte(project.GithubOauthClientSecret),
 			EncryptionAlgorithm: types.EncryptionAlgorithmSpecRsaesOaepSha256,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("encrypt github oauth client secret: %w", err)
+			return queries.UpdateProjectParams{}, fmt.Errorf("encrypt github oauth client secret: %w", err)
 		}
 
 		updates.GithubOauthClientSecretCiphertext = encryptRes.CiphertextBlob
 	}
 
 	updates.LogInWithGoogle = qProject.LogInWithGoogle
-	if req.Project.LogInWithGoogle != nil {
+	if project.LogInWithGoogle != nil {
 		// todo: validate that google is configured?
-		updates.LogInWithGoogle = *req.Project.LogInWithGoogle
+		updates.LogInWithGoogle = *project.LogInWithGoogle
 	}
 
 	updates.LogInWithMicrosoft = qProject.LogInWithMicrosoft
-	if req.Project.LogInWithMicrosoft != nil {
+	if project.LogInWithMicrosoft != nil {
 		// todo: validate that microsoft is configured?
-		updates.LogInWithMicrosoft = *req.Project.LogInWithMicrosoft
+		updates.LogInWithMicrosoft = *project.LogInWithMicrosoft
 	}
 
 	updates.LogInWithGithub = qProject.LogInWithGithub
-	if req.Project.LogInWithGithub != nil {
+	if project.LogInWithGithub != nil {
 		// todo: validate that github is configured?
-		updates.LogInWithGithub = *req.Project.LogInWithGithub
+		updates.LogInWithGithub = *project.LogInWithGithub
 	}
 
 	updates.LogInWithEmail = qProject.LogInWithEmail
-	if req.Project.LogInWithEmail != nil {
-		updates.LogInWithEmail = *req.Project.LogInWithEmail
+	if project.LogInWithEmail != nil {
+		updates.LogInWithEmail = *project.LogInWithEmail
 	}
 
 	updates.LogInWithPassword = qProject.LogInWithPassword
-	if req.Project.LogInWithPassword != nil {
-		updates.LogInWithPassword = *req.Project.LogInWithPassword
+	if project.LogInWithPassword != nil {
+		updates.LogInWithPassword = *project.LogInWithPassword
 	}
 
 	updates.LogInWithSaml = qProject.LogInWithSaml
-	if req.Project.LogInWithSaml != nil {
-		updates.LogInWithSaml = *req.Project.LogInWithSaml
+	if project.LogInWithSaml != nil {
+		updates.LogInWithSaml = *project.LogInWithSaml
 	}
 
 	updates.LogInWithOidc = qProject.LogInWithOidc
-	if req.Project.LogInWithOidc != nil {
-		updates.LogInWithOidc = *req.Project.LogInWithOidc
+	if project.LogInWithOidc != nil {
+		updates.LogInWithOidc = *project.LogInWithOidc
 	}
 
 	updates.LogInWithAuthenticatorApp = qProject.LogInWithAuthenticatorApp
-	if req.Project.LogInWithAuthenticatorApp != nil {
-		updates.LogInWithAuthenticatorApp = *req.Project.LogInWithAuthenticatorApp
+	if project.LogInWithAuthenticatorApp != nil {
+		updates.LogInWithAuthenticatorApp = *project.LogInWithAuthenticatorApp
 	}
 
 	updates.LogInWithPasskey = qProject.LogInWithPasskey
-	if req.Project.LogInWithPasskey != nil {
-		updates.LogInWithPasskey = *req.Project.LogInWithPasskey
+	if project.LogInWithPasskey != nil {
+		updates.LogInWithPasskey = *project.LogInWithPasskey
 	}
 
 	updates.ApiKeysEnabled = qProject.ApiKeysEnabled
-	if req.Project.ApiKeysEnabled != nil {
-		updates.ApiKeysEnabled = *req.Project.ApiKeysEnabled
+	if project.ApiKeysEnabled != nil {
+		updates.ApiKeysEnabled = *project.ApiKeysEnabled
 	}
 
 	updates.AuditLogsEnabled = qProject.AuditLogsEnabled
-	if req.Project.AuditLogsEnabled != nil {
-		updates.AuditLogsEnabled = *req.Project.AuditLogsEnabled
+	if project.AuditLogsEnabled != nil {
+		updates.AuditLogsEnabled = *project.AuditLogsEnabled
+	}
+
+	updates.AuditLogRetentionDays = qProject.AuditLogRetentionDays
+	if project.AuditLogRetentionDays != nil {
+		retentionDays := project.GetAuditLogRetentionDays()
+		if err := validateAuditLogRetentionDays(qProject, retentionDays); err != nil {
+			return queries.UpdateProjectParams{}, err
+		}
+
+		updates.AuditLogRetentionDays = nil
+		if retentionDays != 0 {
+			updates.AuditLogRetentionDays = &retentionDays
+		}
+	}
+
+	updates.AuditLogArchiveS3Bucket = qProject.AuditLogArchiveS3Bucket
+	if project.AuditLogArchiveS3Bucket != nil {
+		updates.AuditLogArchiveS3Bucket = nil
+		if *project.AuditLogArchiveS3Bucket != "" {
+			updates.AuditLogArchiveS3Bucket = project.AuditLogArchiveS3Bucket
+		}
+	}
+
+	updates.AuditLogArchiveS3RoleArn = qProject.AuditLogArchiveS3RoleArn
+	if project.AuditLogArchiveS3RoleArn != nil {
+		updates.AuditLogArchiveS3RoleArn = nil
+		if *project.AuditLogArchiveS3RoleArn != "" {
+			updates.AuditLogArchiveS3RoleArn = project.AuditLogArchiveS3RoleArn
+		}
+	}
+
+	if (project.AuditLogArchiveS3Bucket != nil || project.AuditLogArchiveS3RoleArn != nil) && updates.AuditLogArchiveS3Bucket != nil {
+		if err := s.validateCustomerS3Bucket(*updates.AuditLogArchiveS3Bucket, derefOrEmpty(updates.AuditLogArchiveS3RoleArn)); err != nil {
+			return queries.UpdateProjectParams{}, err
+		}
 	}
 
 	updates.ApiKeySecretTokenPrefix = qProject.ApiKeySecretTokenPrefix
-	if req.Project.ApiKeySecretTokenPrefix != nil {
-		if len(*req.Project.ApiKeySecretTokenPrefix) > 64 {
-			return nil, apierror.NewFailedPreconditionError("api key secret token prefix must be no longer than 64 characters", fmt.Errorf("api key secret token prefix too long: %s", *req.Project.ApiKeySecretTokenPrefix))
+	if project.ApiKeySecretTokenPrefix != nil {
+		if len(*project.ApiKeySecretTokenPrefix) > 64 {
+			return queries.UpdateProjectParams{}, apierror.NewFailedPreconditionError("api key secret token prefix must be no longer than 64 characters", fmt.Errorf("api key secret token prefix too long: %s", *project.ApiKeySecretTokenPrefix))
 		}
 
-		if !apiKeySecretTokenPrefixRegex.MatchString(*req.Project.ApiKeySecretTokenPrefix) {
-			return nil, apierror.NewFailedPreconditionError("api key secret token prefix must contain only lowercase letters, numbers, and underscores", fmt.Errorf("api key secret token prefix contains invalid characters: %s", *req.Project.ApiKeySecretTokenPrefix))
+		if !apiKeySecretTokenPrefixRegex.MatchString(*project.ApiKeySecretTokenPrefix) {
+			return queries.UpdateProjectParams{}, apierror.NewFailedPreconditionError("api key secret token prefix must contain only lowercase letters, numbers, and underscores", fmt.Errorf("api key secret token prefix contains invalid characters: %s", *project.ApiKeySecretTokenPrefix))
 		}
 
-		updates.ApiKeySecretTokenPrefix = req.Project.ApiKeySecretTokenPrefix
+		updates.ApiKeySecretTokenPrefix = project.ApiKeySecretTokenPrefix
+	}
+
+	updates.RedirectUri = qProject.RedirectUri
+	if project.RedirectUri != "" {
+		updates.RedirectUri = project.RedirectUri
+	}
+
+	updates.AfterLoginRedirectUri = qProject.AfterLoginRedirectUri
+	if project.AfterLoginRedirectUri != nil {
+		updates.AfterLoginRedirectUri = nil
+		if *project.AfterLoginRedirectUri != "" {
+			updates.AfterLoginRedirectUri = project.AfterLoginRedirectUri
+		}
+	}
+
+	updates.AfterSignupRedirectUri = qProject.AfterSignupRedirectUri
+	if project.AfterSignupRedirectUri != nil {
+		updates.AfterSignupRedirectUri = nil
+		if *project.AfterSignupRedirectUri != "" {
+			updates.AfterSignupRedirectUri = project.AfterSignupRedirectUri
+		}
 	}
 
 	updates.CookieDomain = qProject.CookieDomain
-	if req.Project.CookieDomain != "" {
+	if project.CookieDomain != "" {
 		// only allow updates to cookie domain if the vault domain is custom
 		defaultVaultDomain := fmt.Sprintf("%s.%s", strings.ReplaceAll(idformat.Project.Format(qProject.ID), "_", "-"), s.authAppsRootDomain)
 		if qProject.VaultDomain == defaultVaultDomain {
-			return nil, apierror.NewFailedPreconditionError("cannot update cookie domain unless vault domain is custom", nil)
+			return queries.UpdateProjectParams{}, apierror.NewFailedPreconditionError("cannot update cookie domain unless vault domain is custom", nil)
 		}
 
 		// do not allow leading "." in cookie domain; we will automatically add
 		// it in Set-Cookie headers
-		if strings.HasPrefix(req.Project.CookieDomain, ".") {
-			return nil, apierror.NewFailedPreconditionError("cookie domain must not start with '.'", nil)
+		if strings.HasPrefix(project.CookieDomain, ".") {
+			return queries.UpdateProjectParams{}, apierror.NewFailedPreconditionError("cookie domain must not start with '.'", nil)
 		}
 
 		// do not allow cookie domain to be from the public suffix list
-		publicSuffix, _ := publicsuffix.PublicSuffix(req.Project.CookieDomain)
-		if publicSuffix == req.Project.CookieDomain {
-			return nil, apierror.NewFailedPreconditionError("cookie domain must not be public suffix", nil)
+		publicSuffix, _ := publicsuffix.PublicSuffix(project.CookieDomain)
+		if publicSuffix == project.CookieDomain {
+			return queries.UpdateProjectParams{}, apierror.NewFailedPreconditionError("cookie domain must not be public suffix", nil)
 		}
 
-		updates.CookieDomain = req.Project.CookieDomain
+		updates.CookieDomain = project.CookieDomain
 	}
 
-	_, q, commit, rollback, err := s.tx(ctx)
-	if err != nil {
-		return nil, err
-	}
-	defer rollback()
+	return updates, nil
+}
 
+// updateProject updates a project, and replaces its trusted domains if any are
+// given.
+func (s *Store) updateProject(ctx context.Context, q *queries.Queries, updates queries.UpdateProjectParams, domains []string) (queries.Project, []queries.ProjectTrustedDomain, error) {
 	qUpdatedProject, err := q.UpdateProject(ctx, updates)
 	if err != nil {
-		return nil, fmt.Errorf("update project: %w", err)
+		return queries.Project{}, nil, fmt.Errorf("update project: %w", err)
 	}
 
 	if !qUpdatedProject.LogInWithGoogle {
 		slog.InfoContext(ctx, "disable_project_organizations_log_in_with_google")
 		if err := q.DisableProjectOrganizationsLogInWithGoogle(ctx, authn.ProjectID(ctx)); err != nil {
-			return nil, fmt.Errorf("disable project organizations log in with Google: %w", err)
+			return queries.Project{}, nil, fmt.Errorf("disable project organizations log in with Google: %w", err)
 		}
 	}
 
 	if !qUpdatedProject.LogInWithMicrosoft {
 		slog.InfoContext(ctx, "disable_project_organizations_log_in_with_microsoft")
 		if err := q.DisableProjectOrganizationsLogInWithMicrosoft(ctx, authn.ProjectID(ctx)); err != nil {
-			return nil, fmt.Errorf("disable project organizations log in with Microsoft: %w", err)
+			return queries.Project{}, nil, fmt.Errorf("disable project organizations log in with Microsoft: %w", err)
 		}
 	}
 
 	if !qUpdatedProject.LogInWithGithub {
 		slog.InfoContext(ctx, "disable_project_organizations_log_in_with_github")
 		if err := q.DisableProjectOrganizationsLogInWithGithub(ctx, authn.ProjectID(ctx)); err != nil {
-			return nil, fmt.Errorf("disable project organizations log in with Github: %w", err)
+			return queries.Project{}, nil, fmt.Errorf("disable project organizations log in with Github: %w", err)
 		}
 	}
 
 	if !qUpdatedProject.LogInWithEmail {
 		slog.InfoContext(ctx, "disable_project_organizations_log_in_with_email")
 		if err := q.DisableProjectOrganizationsLogInWithEmail(ctx, authn.ProjectID(ctx)); err != nil {
-			return nil, fmt.Errorf("disable project organizations log in with email: %w", err)
+			return queries.Project{}, nil, fmt.Errorf("disable project organizations log in with email: %w", err)
 		}
 	}
 
 	if !qUpdatedProject.LogInWithPassword {
 		slog.InfoContext(ctx, "disable_project_organizations_log_in_with_password")
 		if err := q.DisableProjectOrganizationsLogInWithPassword(ctx, authn.ProjectID(ctx)); err != nil {
-			return nil, fmt.Errorf("disable project organizations log in with password: %w", err)
+			return queries.Project{}, nil, fmt.Errorf("disable project organizations log in with password: %w", err)
 		}
 	}
 
 	if !qUpdatedProject.LogInWithSaml {
 		slog.InfoContext(ctx, "disable_project_organizations_log_in_with_saml")
 		if err := q.DisableProjectOrganizationsLogInWithSAML(ctx, authn.ProjectID(ctx)); err != nil {
-			return nil, fmt.Errorf("disable project organizations log in with SAML: %w", err)
+			return queries.Project{}, nil, fmt.Errorf("disable project organizations log in with SAML: %w", err)
 		}
 	}
 
 	if !qUpdatedProject.LogInWithOidc {
 		slog.InfoContext(ctx, "disable_project_organizations_log_in_with_oidc")
 		if err := q.DisableProjectOrganizationsLogInWithOIDC(ctx, authn.ProjectID(ctx)); err != nil {
-			return nil, fmt.Errorf("disable project organizations log in with OIDC: %w", err)
+			return queries.Project{}, nil, fmt.Errorf("disable project organizations log in with OIDC: %w", err)
 		}
 	}
 
 	if !qUpdatedProject.LogInWithAuthenticatorApp {
 		slog.InfoContext(ctx, "disable_project_organizations_log_in_with_authenticator_app")
 		if err := q.DisableProjectOrganizationsLogInWithAuthenticatorApp(ctx, authn.ProjectID(ctx)); err != nil {
-			return nil, fmt.Errorf("disable project organizations log in with authenticator app: %w", err)
+			return queries.Project{}, nil, fmt.Errorf("disable project organizations log in with authenticator app: %w", err)
 		}
 	}
 
 	if !qUpdatedProject.LogInWithPasskey {
 		slog.InfoContext(ctx, "disable_project_organizations_log_in_with_passkey")
 		if err := q.DisableProjectOrganizationsLogInWithPasskey(ctx, authn.ProjectID(ctx)); err != nil {
-			return nil, fmt.Errorf("disable project organizations log in with passkey: %w", err)
+			return queries.Project{}, nil, fmt.Errorf("disable project organizations log in with passkey: %w", err)
 		}
 	}
 
 	// only update project trusted domains if mentioned in request
-	if len(req.Project.TrustedDomains) > 0 {
-		// always include the default vault domain (project-xxx.tesseral.app)
-		// and the current vault domain (e.g. auth.company.com) in the set of
-		// trusted domains
-		trustedDomains := map[string]struct{}{
-			qUpdatedProject.VaultDomain: {},
-			fmt.Sprintf("%s.%s", strings.ReplaceAll(idformat.Project.Format(qUpdatedProject.ID), "_", "-"), s.authAppsRootDomain): {},
-		}
-		for _, domain := range req.Project.TrustedDomains {
-			trustedDomains[domain] = struct{}{}
-		}
-
+	if len(domains) > 0 {
 		if err := q.DeleteProjectTrustedDomainsByProjectID(ctx, authn.ProjectID(ctx)); err != nil {
-			return nil, fmt.Errorf("delete project trusted domains by project id: %w", err)
+			return queries.Project{}, nil, fmt.Errorf("delete project trusted domains by project id: %w", err)
 		}
 
-		for trustedDomain := range trustedDomains {
-			domain := strings.Split(trustedDomain, ":")[0] // Remove port if present
-
+		for domain := range s.getProjectTrustedDomainSet(qUpdatedProject, domains) {
 			if _, err := q.CreateProjectTrustedDomain(ctx, queries.CreateProjectTrustedDomainParams{
 				ID:        uuid.New(),
 				ProjectID: authn.ProjectID(ctx),
 				Domain:    domain,
 			}); err != nil {
-				return nil, fmt.Errorf("create project passkey rp id: %w", err)
+				return queries.Project{}, nil, fmt.Errorf("create project passkey rp id: %w", err)
 			}
 		}
 	}
 
 	qProjectTrustedDomains, err := q.GetProjectTrustedDomains(ctx, authn.ProjectID(ctx))
 	if err != nil {
-		return nil, fmt.Errorf("get project trusted domains: %w", err)
+		return queries.Project{}, nil, fmt.Errorf("get project trusted domains: %w", err)
 	}
 
-	if err := commit(); err != nil {
-		return nil, fmt.Errorf("commit: %w", err)
+	return qUpdatedProject, qProjectTrustedDomains, nil
+}
+
+// getProjectTrustedDomainSet returns the set of trusted domains to store for a
+// project, given the domains requested for it.
+func (s *Store) getProjectTrustedDomainSet(qProject queries.Project, domains []string) map[string]struct{} {
+	// always include the default vault domain (project-xxx.tesseral.app) and
+	// the current vault domain (e.g. auth.company.com) in the set of trusted
+	// domains
+	trustedDomains := map[string]struct{}{
+		qProject.VaultDomain: {},
+		fmt.Sprintf("%s.%s", strings.ReplaceAll(idformat.Project.Format(qProject.ID), "_", "-"), s.authAppsRootDomain): {},
+	}
+	for _, domain := range domains {
+		trustedDomains[strings.Split(domain, ":")[0]] = struct{}{} // Remove port if present
 	}
 
-	return &backendv1.UpdateProjectResponse{Project: s.parseProject(&qUpdatedProject, qProjectTrustedDomains)}, nil
+	return trustedDomains
 }
 
 func (s *Store) parseProject(qProject *queries.Project, qProjectTrustedDomains []queries.ProjectTrustedDomain) *backendv1.Project {
@@ -401,32 +483,55 @@ func (s *Store) parseProject(qProject *queries.Project, qProjectTrustedDomains [
 	}
 
 	return &backendv1.Project{
-		Id:                         idformat.Project.Format(qProject.ID),
-		DisplayName:                qProject.DisplayName,
-		CreateTime:                 timestamppb.New(*qProject.CreateTime),
-		UpdateTime:                 timestamppb.New(*qProject.UpdateTime),
-		LogInWithGoogle:            &qProject.LogInWithGoogle,
-		LogInWithMicrosoft:         &qProject.LogInWithMicrosoft,
-		LogInWithGithub:            &qProject.LogInWithGithub,
-		LogInWithEmail:             &qProject.LogInWithEmail,
-		LogInWithPassword:          &qProject.LogInWithPassword,
-		LogInWithSaml:              &qProject.LogInWithSaml,
-		LogInWithOidc:              &qProject.LogInWithOidc,
-		LogInWithAuthenticatorApp:  &qProject.LogInWithAuthenticatorApp,
-		LogInWithPasskey:           &qProject.LogInWithPasskey,
-		GoogleOauthClientId:        qProject.GoogleOauthClientID,
-		GoogleOauthClientSecret:    "", // intentionally left blank
-		MicrosoftOauthClientId:     qProject.MicrosoftOauthClientID,
-		MicrosoftOauthClientSecret: "", // intentionally left blank
-		GithubOauthClientId:        qProject.GithubOauthClientID,
-		GithubOauthClientSecret:    "", // intentionally left blank
-		VaultDomain:                qProject.VaultDomain,
-		VaultDomainCustom:          qProject.VaultDomain != fmt.Sprintf("%s.%s", strings.ReplaceAll(idformat.Project.Format(qProject.ID), "_", "-"), s.authAppsRootDomain),
-		TrustedDomains:             trustedDomains,
-		CookieDomain:               qProject.CookieDomain,
-		EmailSendFromDomain:        qProject.EmailSendFromDomain,
-		ApiKeysEnabled:             &qProject.ApiKeysEnabled,
-		ApiKeySecretTokenPrefix:    qProject.ApiKeySecretTokenPrefix,
-		AuditLogsEnabled:           refOrNil(qProject.AuditLogsEnabled),
+		Id:                            idformat.Project.Format(qProject.ID),
+		DisplayName:                   qProject.DisplayName,
+		CreateTime:                    timestamppb.New(*qProject.CreateTime),
+		UpdateTime:                    timestamppb.New(*qProject.UpdateTime),
+		LogInWithGoogle:               &qProject.LogInWithGoogle,
+		LogInWithMicrosoft:            &qProject.LogInWithMicrosoft,
+		LogInWithGithub:               &qProject.LogInWithGithub,
+		LogInWithEmail:                &qProject.LogInWithEmail,
+		LogInWithPassword:             &qProject.LogInWithPassword,
+		LogInWithSaml:                 &qProject.LogInWithSaml,
+		LogInWithOidc:                 &qProject.LogInWithOidc,
+		LogInWithAuthenticatorApp:     &qProject.LogInWithAuthenticatorApp,
+		LogInWithPasskey:              &qProject.LogInWithPasskey,
+		GoogleOauthClientId:           qProject.GoogleOauthClientID,
+		GoogleOauthClientSecret:       "", // intentionally left blank
+		MicrosoftOauthClientId:        qProject.MicrosoftOauthClientID,
+		MicrosoftOauthClientSecret:    "", // intentionally left blank
+		GithubOauthClientId:           qProject.GithubOauthClientID,
+		GithubOauthClientSecret:       "", // intentionally left blank
+		VaultDomain:                   qProject.VaultDomain,
+		VaultDomainCustom:             qProject.VaultDomain != fmt.Sprintf("%s.%s", strings.ReplaceAll(idformat.Project.Format(qProject.ID), "_", "-"), s.authAppsRootDomain),
+		TrustedDomains:                trustedDomains,
+		CookieDomain:                  qProject.CookieDomain,
+		RedirectUri:                   qProject.RedirectUri,
+		AfterLoginRedirectUri:         qProject.AfterLoginRedirectUri,
+		AfterSignupRedirectUri:        qProject.AfterSignupRedirectUri,
+		EmailSendFromDomain:           qProject.EmailSendFromDomain,
+		ApiKeysEnabled:                &qProject.ApiKeysEnabled,
+		ApiKeySecretTokenPrefix:       qProject.ApiKeySecretTokenPrefix,
+		AuditLogsEnabled:              refOrNil(qProject.AuditLogsEnabled),
+		AuditLogRetentionDays:         qProject.AuditLogRetentionDays,
+		EntitledAuditLogRetentionDays: qProject.EntitledAuditLogRetentionDays,
+		AuditLogArchiveS3Bucket:       qProject.AuditLogArchiveS3Bucket,
+		AuditLogArchiveS3RoleArn:      qProject.AuditLogArchiveS3RoleArn,
+	}
+}
+
+// validateAuditLogRetentionDays checks a Project or Organization audit log
+// retention period. Zero means no retention period.
+func validateAuditLogRetentionDays(qProject queries.Project, retentionDays int32) error {
+	if retentionDays < 0 {
+		return apierror.NewInvalidArgumentError("audit log retention days must not be negative", fmt.Errorf("audit log retention days is negative: %d", retentionDays))
 	}
+
+	if qProject.EntitledAuditLogRetentionDays != nil {
+		if retentionDays == 0 || retentionDays > *qProject.EntitledAuditLogRetentionDays {
+			return apierror.NewFailedPreconditionError(fmt.Sprintf("audit log retention days must be between 1 and %d", *qProject.EntitledAuditLogRetentionDays), fmt.Errorf("audit log retention days exceeds entitlement: %d", retentionDays))
+		}
+	}
+
+	return nil
 }
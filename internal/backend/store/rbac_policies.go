@@ -47,14 +47,29 @@ func (s *Store) UpdateRBACPolicy(ctx context.Context, req *backendv1.UpdateRBACP
 	}
 	defer rollback()
 
-	for _, action := range req.RbacPolicy.Actions {
+	qActions, err := s.updateRBACPolicy(ctx, q, req.RbacPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.UpdateRBACPolicyResponse{RbacPolicy: parseRBACPolicy(qActions)}, nil
+}
+
+// updateRBACPolicy replaces the current project's actions with those in
+// rbacPolicy.
+func (s *Store) updateRBACPolicy(ctx context.Context, q *queries.Queries, rbacPolicy *backendv1.RBACPolicy) ([]queries.Action, error) {
+	for _, action := range rbacPolicy.Actions {
 		if err := validateActionName(action.Name); err != nil {
 			return nil, fmt.Errorf("validate action name: %w", err)
 		}
 	}
 
 	names := []string{} // initialize because passing NULL has the wrong behavior in the postgres query
-	for _, action := range req.RbacPolicy.Actions {
+	for _, action := range rbacPolicy.Actions {
 		names = append(names, action.Name)
 		if err := q.UpsertAction(ctx, queries.UpsertActionParams{
 			ID:          uuid.New(),
@@ -78,11 +93,7 @@ func (s *Store) UpdateRBACPolicy(ctx context.Context, req *backendv1.UpdateRBACP
 		return nil, fmt.Errorf("get actions: %w", err)
 	}
 
-	if err := commit(); err != nil {
-		return nil, fmt.Errorf("commit: %w", err)
-	}
-
-	return &backendv1.UpdateRBACPolicyResponse{RbacPolicy: parseRBACPolicy(qActions)}, nil
+	return qActions, nil
 }
 
 func parseRBACPolicy(qActions []queries.Action) *backendv1.RBACPolicy {
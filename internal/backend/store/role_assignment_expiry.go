@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+)
+
+const (
+	roleAssignmentExpiryBatchSize    = 100
+	roleAssignmentExpiryPollInterval = time.Minute
+)
+
+// RunRoleAssignmentExpiry deletes User and API Key role assignments once their
+// expire_time has passed, until ctx is canceled.
+func (s *Store) RunRoleAssignmentExpiry(ctx context.Context) error {
+	ticker := time.NewTicker(roleAssignmentExpiryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.ExpireRoleAssignments(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "expire_role_assignments_error", "err", err)
+		}
+
+		// A full batch suggests there is a backlog; keep going without waiting
+		// for the next tick.
+		if err == nil && n >= roleAssignmentExpiryBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireRoleAssignments deletes a batch of expired User and API Key role
+// assignments, logging an unassign audit event for each. It returns the number
+// of role assignments deleted.
+//
+// Permission checks ignore role assignments as soon as they expire; this only
+// cleans them up and makes the expiry visible in the audit log.
+func (s *Store) ExpireRoleAssignments(ctx context.Context) (int, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer rollback()
+
+	qUserRoleAssignments, err := q.ListExpiredUserRoleAssignments(ctx, roleAssignmentExpiryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("list expired user role assignments: %w", err)
+	}
+
+	for _, qUserRoleAssignment := range qUserRoleAssignments {
+		auditUserRoleAssignment, err := s.auditlogStore.GetUserRoleAssignment(ctx, tx, qUserRoleAssignment.ID)
+		if err != nil {
+			return 0, fmt.Errorf("get audit user role assignment: %w", err)
+		}
+
+		if err := q.DeleteUserRoleAssignment(ctx, qUserRoleAssignment.ID); err != nil {
+			return 0, fmt.Errorf("delete user role assignment: %w", err)
+		}
+
+		if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+			ProjectID: &qUserRoleAssignment.ProjectID,
+			EventName: "tesseral.users.unassign_role",
+			EventDetails: &auditlogv1.UnassignUserRole{
+				UserRoleAssignment: auditUserRoleAssignment,
+			},
+			OrganizationID: &qUserRoleAssignment.OrganizationID,
+			ResourceType:   queries.AuditLogEventResourceTypeUser,
+			ResourceID:     &qUserRoleAssignment.UserID,
+		}); err != nil {
+			return 0, fmt.Errorf("create audit log event: %w", err)
+		}
+	}
+
+	qAPIKeyRoleAssignments, err := q.ListExpiredAPIKeyRoleAssignments(ctx, roleAssignmentExpiryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("list expired api key role assignments: %w", err)
+	}
+
+	for _, qAPIKeyRoleAssignment := range qAPIKeyRoleAssignments {
+		auditAPIKeyRoleAssignment, err := s.auditlogStore.GetAPIKeyRoleAssignment(ctx, tx, qAPIKeyRoleAssignment.ID)
+		if err != nil {
+			return 0, fmt.Errorf("get audit api key role assignment: %w", err)
+		}
+
+		if err := q.DeleteExpiredAPIKeyRoleAssignment(ctx, qAPIKeyRoleAssignment.ID); err != nil {
+			return 0, fmt.Errorf("delete api key role assignment: %w", err)
+		}
+
+		if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+			ProjectID: &qAPIKeyRoleAssignment.ProjectID,
+			EventName: "tesseral.api_keys.unassign_role",
+			EventDetails: &auditlogv1.UnassignAPIKeyRole{
+				ApiKeyRoleAssignment: auditAPIKeyRoleAssignment,
+			},
+			OrganizationID: &qAPIKeyRoleAssignment.OrganizationID,
+			ResourceType:   queries.AuditLogEventResourceTypeApiKey,
+			ResourceID:     &qAPIKeyRoleAssignment.ApiKeyID,
+		}); err != nil {
+			return 0, fmt.Errorf("create audit log event: %w", err)
+		}
+	}
+
+	if err := commit(); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return len(qUserRoleAssignments) + len(qAPIKeyRoleAssignments), nil
+}
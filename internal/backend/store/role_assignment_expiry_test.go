@@ -0,0 +1,119 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCreateUserRoleAssignment_ExpireTimeInPast(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "test@example.com",
+	})
+
+	roleResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName: "test",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId:     userID,
+			RoleId:     roleResp.Role.Id,
+			ExpireTime: timestamppb.New(time.Now().Add(-time.Hour)),
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestExpireRoleAssignments(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "test@example.com",
+	})
+
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO actions (id, project_id, name, description)
+  VALUES (gen_random_uuid(), $1::uuid, $2, $2);
+`,
+		uuid.UUID(projectID).String(),
+		"test.read",
+	)
+	require.NoError(t, err)
+
+	roleResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName: "reader",
+			Actions:     []string{"test.read"},
+		},
+	})
+	require.NoError(t, err)
+
+	expireTime := time.Now().Add(time.Hour)
+	assignmentResp, err := u.Store.CreateUserRoleAssignment(ctx, &backendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &backendv1.UserRoleAssignment{
+			UserId:     userID,
+			RoleId:     roleResp.Role.Id,
+			ExpireTime: timestamppb.New(expireTime),
+		},
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, expireTime, assignmentResp.UserRoleAssignment.ExpireTime.AsTime(), time.Millisecond)
+
+	checkResp, err := u.Store.CheckAction(ctx, &backendv1.CheckActionRequest{
+		Principal: &backendv1.CheckActionRequest_UserId{UserId: userID},
+		Action:    "test.read",
+	})
+	require.NoError(t, err)
+	require.True(t, checkResp.Allowed)
+
+	assignmentID, err := idformat.UserRoleAssignment.Parse(assignmentResp.UserRoleAssignment.Id)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `
+UPDATE user_role_assignments SET expire_time = now() - interval '1 minute' WHERE id = $1::uuid;
+`,
+		uuid.UUID(assignmentID).String(),
+	)
+	require.NoError(t, err)
+
+	// expired assignments no longer grant actions, even before they are swept
+	checkResp, err = u.Store.CheckAction(ctx, &backendv1.CheckActionRequest{
+		Principal: &backendv1.CheckActionRequest_UserId{UserId: userID},
+		Action:    "test.read",
+	})
+	require.NoError(t, err)
+	require.False(t, checkResp.Allowed)
+
+	_, err = u.Store.ExpireRoleAssignments(ctx)
+	require.NoError(t, err)
+
+	_, err = u.Store.GetUserRoleAssignment(ctx, &backendv1.GetUserRoleAssignmentRequest{
+		Id: assignmentResp.UserRoleAssignment.Id,
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	roleTemplateSyncBatchSize    = 100
+	roleTemplateSyncPollInterval = time.Minute
+)
+
+// RunRoleTemplateSync creates roles based on role templates for organizations
+// that do not have them yet until ctx is canceled.
+func (s *Store) RunRoleTemplateSync(ctx context.Context) error {
+	ticker := time.NewTicker(roleTemplateSyncPollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.SyncRoleTemplates(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "sync_role_templates_error", "err", err)
+		}
+
+		// A full batch suggests there is a backlog; keep going without waiting
+		// for the next tick.
+		if err == nil && n == roleTemplateSyncBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncRoleTemplates syncs a batch of role templates that some organization in
+// their project has no role for. It returns the number of role templates
+// synced.
+//
+// Creating or updating a role template syncs every existing organization, and
+// creating an organization creates its role template roles, both immediately.
+// This is a backstop for organizations that miss out on both, such as one
+// created concurrently with a role template.
+func (s *Store) SyncRoleTemplates(ctx context.Context) (int, error) {
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer rollback()
+
+	roleTemplateIDs, err := q.ListRoleTemplatesMissingRoles(ctx, roleTemplateSyncBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("list role templates missing roles: %w", err)
+	}
+
+	for _, roleTemplateID := range roleTemplateIDs {
+		if err := syncRoleTemplateRoles(ctx, q, roleTemplateID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := commit(); err != nil {
+		return 0, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return len(roleTemplateIDs), nil
+}
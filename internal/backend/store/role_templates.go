@@ -0,0 +1,417 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) ListRoleTemplates(ctx context.Context, req *backendv1.ListRoleTemplatesRequest) (*backendv1.ListRoleTemplatesResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	var startID uuid.UUID
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	qRoleTemplates, err := q.ListRoleTemplates(ctx, queries.ListRoleTemplatesParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        startID,
+		Limit:     int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list role templates: %w", err)
+	}
+
+	var qRoleTemplateIDs []uuid.UUID
+	for _, qRoleTemplate := range qRoleTemplates {
+		qRoleTemplateIDs = append(qRoleTemplateIDs, qRoleTemplate.ID)
+	}
+
+	qRoleTemplateActions, err := q.BatchGetRoleTemplateActionsByRoleTemplateID(ctx, qRoleTemplateIDs)
+	if err != nil {
+		return nil, fmt.Errorf("batch get role template actions by role template ids: %w", err)
+	}
+
+	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get actions: %w", err)
+	}
+
+	var roleTemplates []*backendv1.RoleTemplate
+	for _, qRoleTemplate := range qRoleTemplates {
+		roleTemplates = append(roleTemplates, parseRoleTemplate(qRoleTemplate, qRoleTemplateActions, qActions))
+	}
+
+	var nextPageToken string
+	if len(roleTemplates) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qRoleTemplates[limit].ID)
+		roleTemplates = roleTemplates[:limit]
+	}
+
+	return &backendv1.ListRoleTemplatesResponse{
+		RoleTemplates: roleTemplates,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+func (s *Store) GetRoleTemplate(ctx context.Context, req *backendv1.GetRoleTemplateRequest) (*backendv1.GetRoleTemplateResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	roleTemplateID, err := idformat.RoleTemplate.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid role template id", fmt.Errorf("parse role template id: %w", err))
+	}
+
+	qRoleTemplate, err := q.GetRoleTemplate(ctx, queries.GetRoleTemplateParams{
+		ID:        roleTemplateID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("role template not found", fmt.Errorf("get role template: %w", err))
+		}
+		return nil, fmt.Errorf("get role template: %w", err)
+	}
+
+	qRoleTemplateActions, err := q.BatchGetRoleTemplateActionsByRoleTemplateID(ctx, []uuid.UUID{qRoleTemplate.ID})
+	if err != nil {
+		return nil, fmt.Errorf("batch get role template actions by role template id: %w", err)
+	}
+
+	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get actions: %w", err)
+	}
+
+	return &backendv1.GetRoleTemplateResponse{RoleTemplate: parseRoleTemplate(qRoleTemplate, qRoleTemplateActions, qActions)}, nil
+}
+
+func (s *Store) CreateRoleTemplate(ctx context.Context, req *backendv1.CreateRoleTemplateRequest) (*backendv1.CreateRoleTemplateResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	if req.RoleTemplate.DisplayName == "" {
+		return nil, apierror.NewInvalidArgumentError("display_name is required", fmt.Errorf("display_name is required"))
+	}
+
+	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get actions: %w", err)
+	}
+
+	qActionIDs, err := parseActionIDs(qActions, req.RoleTemplate.Actions)
+	if err != nil {
+		return nil, err
+	}
+
+	qRoleTemplate, err := q.CreateRoleTemplate(ctx, queries.CreateRoleTemplateParams{
+		ID:          uuid.New(),
+		ProjectID:   authn.ProjectID(ctx),
+		DisplayName: req.RoleTemplate.DisplayName,
+		Description: req.RoleTemplate.Description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create role template: %w", err)
+	}
+
+	for _, actionID := range qActionIDs {
+		if err := q.UpsertRoleTemplateAction(ctx, queries.UpsertRoleTemplateActionParams{
+			ID:             uuid.New(),
+			RoleTemplateID: qRoleTemplate.ID,
+			ActionID:       actionID,
+		}); err != nil {
+			return nil, fmt.Errorf("upsert role template action: %w", err)
+		}
+	}
+
+	if err := syncRoleTemplateRoles(ctx, q, qRoleTemplate.ID); err != nil {
+		return nil, err
+	}
+
+	qRoleTemplateActions, err := q.BatchGetRoleTemplateActionsByRoleTemplateID(ctx, []uuid.UUID{qRoleTemplate.ID})
+	if err != nil {
+		return nil, fmt.Errorf("batch get role template actions by role template id: %w", err)
+	}
+
+	auditRoleTemplate, err := s.auditlogStore.GetRoleTemplate(ctx, tx, qRoleTemplate.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit role template: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+		EventName: "tesseral.role_templates.create",
+		EventDetails: &auditlogv1.CreateRoleTemplate{
+			RoleTemplate: auditRoleTemplate,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeRoleTemplate,
+		ResourceID:   &qRoleTemplate.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("log audit event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.CreateRoleTemplateResponse{RoleTemplate: parseRoleTemplate(qRoleTemplate, qRoleTemplateActions, qActions)}, nil
+}
+
+func (s *Store) UpdateRoleTemplate(ctx context.Context, req *backendv1.UpdateRoleTemplateRequest) (*backendv1.UpdateRoleTemplateResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	roleTemplateID, err := idformat.RoleTemplate.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid role template id", fmt.Errorf("parse role template id: %w", err))
+	}
+
+	qRoleTemplate, err := q.GetRoleTemplate(ctx, queries.GetRoleTemplateParams{
+		ID:        roleTemplateID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("role template not found", fmt.Errorf("get role template: %w", err))
+		}
+		return nil, fmt.Errorf("get role template: %w", err)
+	}
+
+	auditPreviousRoleTemplate, err := s.auditlogStore.GetRoleTemplate(ctx, tx, qRoleTemplate.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit role template: %w", err)
+	}
+
+	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get actions: %w", err)
+	}
+
+	var updates queries.UpdateRoleTemplateParams
+	updates.ID = qRoleTemplate.ID
+
+	updates.DisplayName = qRoleTemplate.DisplayName
+	if req.RoleTemplate.DisplayName != "" {
+		updates.DisplayName = req.RoleTemplate.DisplayName
+	}
+
+	updates.Description = qRoleTemplate.Description
+	if req.RoleTemplate.Description != "" {
+		updates.Description = req.RoleTemplate.Description
+	}
+
+	if req.RoleTemplate.Actions != nil {
+		qActionIDs, err := parseActionIDs(qActions, req.RoleTemplate.Actions)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, qActionID := range qActionIDs {
+			if err := q.UpsertRoleTemplateAction(ctx, queries.UpsertRoleTemplateActionParams{
+				ID:             uuid.New(),
+				RoleTemplateID: qRoleTemplate.ID,
+				ActionID:       qActionID,
+			}); err != nil {
+				return nil, fmt.Errorf("upsert role template action: %w", err)
+			}
+		}
+
+		if err := q.DeleteRoleTemplateActionsByActionIDNotInList(ctx, queries.DeleteRoleTemplateActionsByActionIDNotInListParams{
+			RoleTemplateID: qRoleTemplate.ID,
+			ActionIds:      qActionIDs,
+		}); err != nil {
+			return nil, fmt.Errorf("delete role template actions by action id not in list: %w", err)
+		}
+	}
+
+	qUpdatedRoleTemplate, err := q.UpdateRoleTemplate(ctx, updates)
+	if err != nil {
+		return nil, fmt.Errorf("update role template: %w", err)
+	}
+
+	if err := syncRoleTemplateRoles(ctx, q, qUpdatedRoleTemplate.ID); err != nil {
+		return nil, err
+	}
+
+	qRoleTemplateActions, err := q.BatchGetRoleTemplateActionsByRoleTemplateID(ctx, []uuid.UUID{qUpdatedRoleTemplate.ID})
+	if err != nil {
+		return nil, fmt.Errorf("batch get role template actions by role template id: %w", err)
+	}
+
+	auditRoleTemplate, err := s.auditlogStore.GetRoleTemplate(ctx, tx, qUpdatedRoleTemplate.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit role template: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+		EventName: "tesseral.role_templates.update",
+		EventDetails: &auditlogv1.UpdateRoleTemplate{
+			RoleTemplate:         auditRoleTemplate,
+			PreviousRoleTemplate: auditPreviousRoleTemplate,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeRoleTemplate,
+		ResourceID:   &qUpdatedRoleTemplate.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("log audit event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.UpdateRoleTemplateResponse{RoleTemplate: parseRoleTemplate(qUpdatedRoleTemplate, qRoleTemplateActions, qActions)}, nil
+}
+
+func (s *Store) DeleteRoleTemplate(ctx context.Context, req *backendv1.DeleteRoleTemplateRequest) (*backendv1.DeleteRoleTemplateResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	roleTemplateID, err := idformat.RoleTemplate.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid role template id", fmt.Errorf("parse role template id: %w", err))
+	}
+
+	qRoleTemplate, err := q.GetRoleTemplate(ctx, queries.GetRoleTemplateParams{
+		ID:        roleTemplateID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("role template not found", fmt.Errorf("get role template: %w", err))
+		}
+		return nil, fmt.Errorf("get role template: %w", err)
+	}
+
+	auditRoleTemplate, err := s.auditlogStore.GetRoleTemplate(ctx, tx, qRoleTemplate.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit role template: %w", err)
+	}
+
+	// deletes cascade to every organization's role based on the template
+	if err := q.DeleteRoleTemplate(ctx, qRoleTemplate.ID); err != nil {
+		return nil, fmt.Errorf("delete role template: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+		EventName: "tesseral.role_templates.delete",
+		EventDetails: &auditlogv1.DeleteRoleTemplate{
+			RoleTemplate: auditRoleTemplate,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeRoleTemplate,
+		ResourceID:   &qRoleTemplate.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("log audit event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.DeleteRoleTemplateResponse{}, nil
+}
+
+// syncRoleTemplateRoles brings every organization's role based on a role
+// template up to date with the template, creating roles for organizations that
+// do not have one yet.
+func syncRoleTemplateRoles(ctx context.Context, q *queries.Queries, roleTemplateID uuid.UUID) error {
+	if err := q.UpsertRoleTemplateRoles(ctx, roleTemplateID); err != nil {
+		return fmt.Errorf("upsert role template roles: %w", err)
+	}
+
+	if err := q.UpsertRoleTemplateRoleActions(ctx, roleTemplateID); err != nil {
+		return fmt.Errorf("upsert role template role actions: %w", err)
+	}
+
+	if err := q.DeleteRoleTemplateRoleActionsNotInTemplate(ctx, roleTemplateID); err != nil {
+		return fmt.Errorf("delete role template role actions not in template: %w", err)
+	}
+
+	return nil
+}
+
+// createOrganizationRoleTemplateRoles creates an organization's roles based on
+// each of its project's role templates.
+func createOrganizationRoleTemplateRoles(ctx context.Context, q *queries.Queries, organizationID uuid.UUID) error {
+	if err := q.CreateOrganizationRoleTemplateRoles(ctx, organizationID); err != nil {
+		return fmt.Errorf("create organization role template roles: %w", err)
+	}
+
+	if err := q.CreateOrganizationRoleTemplateRoleActions(ctx, organizationID); err != nil {
+		return fmt.Errorf("create organization role template role actions: %w", err)
+	}
+
+	return nil
+}
+
+// parseActionIDs resolves action names to the IDs of the project's actions.
+func parseActionIDs(qActions []queries.Action, actions []string) ([]uuid.UUID, error) {
+	qActionIDs := []uuid.UUID{}
+	for _, action := range actions {
+		var ok bool
+		for _, qAction := range qActions {
+			if qAction.Name == action {
+				qActionIDs = append(qActionIDs, qAction.ID)
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("invalid action %q", action), fmt.Errorf("action %q not found", action))
+		}
+	}
+	return qActionIDs, nil
+}
+
+func parseRoleTemplate(qRoleTemplate queries.RoleTemplate, qRoleTemplateActions []queries.RoleTemplateAction, qActions []queries.Action) *backendv1.RoleTemplate {
+	var actions []string
+	for _, qRoleTemplateAction := range qRoleTemplateActions {
+		if qRoleTemplateAction.RoleTemplateID != qRoleTemplate.ID {
+			continue
+		}
+
+		for _, qAction := range qActions {
+			if qAction.ID == qRoleTemplateAction.ActionID {
+				actions = append(actions, qAction.Name)
+				break
+			}
+		}
+	}
+
+	return &backendv1.RoleTemplate{
+		Id:          idformat.RoleTemplate.Format(qRoleTemplate.ID),
+		CreateTime:  timestamppb.New(*qRoleTemplate.CreateTime),
+		UpdateTime:  timestamppb.New(*qRoleTemplate.UpdateTime),
+		DisplayName: qRoleTemplate.DisplayName,
+		Description: qRoleTemplate.Description,
+		Actions:     actions,
+		Version:     qRoleTemplate.Version,
+	}
+}
@@ -0,0 +1,125 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func TestRoleTemplate_CRUD(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{DisplayName: "org"})
+
+	projectID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO actions (id, project_id, name, description)
+  VALUES (gen_random_uuid(), $1::uuid, $2, $2),
+  		 (gen_random_uuid(), $1::uuid, $3, $3);
+`,
+		uuid.UUID(projectID).String(),
+		"foo.bar.baz",
+		"foo.bar.qux",
+	)
+	require.NoError(t, err)
+
+	createResp, err := u.Store.CreateRoleTemplate(ctx, &backendv1.CreateRoleTemplateRequest{
+		RoleTemplate: &backendv1.RoleTemplate{
+			DisplayName: "viewer",
+			Description: "desc1",
+			Actions:     []string{"foo.bar.baz"},
+		},
+	})
+	require.NoError(t, err)
+	roleTemplate := createResp.RoleTemplate
+	require.Equal(t, "viewer", roleTemplate.DisplayName)
+	require.Equal(t, int32(1), roleTemplate.Version)
+	require.Equal(t, []string{"foo.bar.baz"}, roleTemplate.Actions)
+
+	// every organization receives a role based on the template
+	role := getRoleTemplateRole(ctx, t, u, orgID, roleTemplate.Id)
+	require.Equal(t, "viewer", role.DisplayName)
+	require.Equal(t, int32(1), role.RoleTemplateVersion)
+	require.Equal(t, []string{"foo.bar.baz"}, role.Actions)
+
+	updateResp, err := u.Store.UpdateRoleTemplate(ctx, &backendv1.UpdateRoleTemplateRequest{
+		Id: roleTemplate.Id,
+		RoleTemplate: &backendv1.RoleTemplate{
+			DisplayName: "viewer2",
+			Actions:     []string{"foo.bar.qux"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "viewer2", updateResp.RoleTemplate.DisplayName)
+	require.Equal(t, "desc1", updateResp.RoleTemplate.Description)
+	require.Equal(t, int32(2), updateResp.RoleTemplate.Version)
+
+	// updates propagate to every organization's role
+	role = getRoleTemplateRole(ctx, t, u, orgID, roleTemplate.Id)
+	require.Equal(t, "viewer2", role.DisplayName)
+	require.Equal(t, int32(2), role.RoleTemplateVersion)
+	require.Equal(t, []string{"foo.bar.qux"}, role.Actions)
+
+	// roles based on a template are managed through the template
+	_, err = u.Store.UpdateRole(ctx, &backendv1.UpdateRoleRequest{
+		Id:   role.Id,
+		Role: &backendv1.Role{DisplayName: "renamed"},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+
+	_, err = u.Store.DeleteRole(ctx, &backendv1.DeleteRoleRequest{Id: role.Id})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+
+	// organizations created through the api receive the role immediately
+	createOrgResp, err := u.Store.CreateOrganization(ctx, &backendv1.CreateOrganizationRequest{
+		Organization: &backendv1.Organization{DisplayName: "created"},
+	})
+	require.NoError(t, err)
+
+	role = getRoleTemplateRole(ctx, t, u, createOrgResp.Organization.Id, roleTemplate.Id)
+	require.Equal(t, "viewer2", role.DisplayName)
+	require.Equal(t, int32(2), role.RoleTemplateVersion)
+	require.Equal(t, []string{"foo.bar.qux"}, role.Actions)
+
+	// organizations created some other way receive the role once synced
+	laterOrgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{DisplayName: "later"})
+	_, err = u.Store.SyncRoleTemplates(ctx)
+	require.NoError(t, err)
+
+	role = getRoleTemplateRole(ctx, t, u, laterOrgID, roleTemplate.Id)
+	require.Equal(t, "viewer2", role.DisplayName)
+	require.Equal(t, []string{"foo.bar.qux"}, role.Actions)
+
+	_, err = u.Store.DeleteRoleTemplate(ctx, &backendv1.DeleteRoleTemplateRequest{Id: roleTemplate.Id})
+	require.NoError(t, err)
+
+	_, err = u.Store.GetRole(ctx, &backendv1.GetRoleRequest{Id: role.Id})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
+
+// getRoleTemplateRole returns the organization's role based on the given role
+// template.
+func getRoleTemplateRole(ctx context.Context, t *testing.T, u *testUtil, organizationID string, roleTemplateID string) *backendv1.Role {
+	listResp, err := u.Store.ListRoles(ctx, &backendv1.ListRolesRequest{OrganizationId: organizationID})
+	require.NoError(t, err)
+
+	for _, role := range listResp.Roles {
+		if role.RoleTemplateId == roleTemplateID {
+			return role
+		}
+	}
+
+	t.Fatalf("no role based on role template %s in organization %s", roleTemplateID, organizationID)
+	return nil
+}
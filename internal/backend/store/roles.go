@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -73,6 +74,11 @@ func (s *Store) ListRoles(ctx context.Context, req *backendv1.ListRolesRequest)
 		return nil, fmt.Errorf("batch get role actions by role ids: %w", err)
 	}
 
+	qRoleInheritedRoles, err := q.BatchGetRoleInheritedRolesByRoleID(ctx, qRoleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("batch get role inherited roles by role ids: %w", err)
+	}
+
 	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get actions: %w", err)
@@ -80,7 +86,7 @@ func (s *Store) ListRoles(ctx context.Context, req *backendv1.ListRolesRequest)
 
 	var roles []*backendv1.Role
 	for _, qRole := range qRoles {
-		roles = append(roles, parseRole(qRole, qRoleActions, qActions))
+		roles = append(roles, parseRole(qRole, qRoleActions, qRoleInheritedRoles, qActions))
 	}
 
 	var nextPageToken string
@@ -124,12 +130,17 @@ func (s *Store) GetRole(ctx context.Context, req *backendv1.GetRoleRequest) (*ba
 		return nil, fmt.Errorf("batch get role actions by role id: %w", err)
 	}
 
+	qRoleInheritedRoles, err := q.BatchGetRoleInheritedRolesByRoleID(ctx, []uuid.UUID{qRole.ID})
+	if err != nil {
+		return nil, fmt.Errorf("batch get role inherited roles by role id: %w", err)
+	}
+
 	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get actions: %w", err)
 	}
 
-	return &backendv1.GetRoleResponse{Role: parseRole(qRole, qRoleActions, qActions)}, nil
+	return &backendv1.GetRoleResponse{Role: parseRole(qRole, qRoleActions, qRoleInheritedRoles, qActions)}, nil
 }
 
 func (s *Store) CreateRole(ctx context.Context, req *backendv1.CreateRoleRequest) (*backendv1.CreateRoleResponse, error) {
@@ -139,9 +150,23 @@ func (s *Store) CreateRole(ctx context.Context, req *backendv1.CreateRoleRequest
 	}
 	defer rollback()
 
+	role, err := s.createRole(ctx, tx, q, req.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.CreateRoleResponse{Role: role}, nil
+}
+
+// createRole creates a role and logs an audit event for its creation.
+func (s *Store) createRole(ctx context.Context, tx pgx.Tx, q *queries.Queries, role *backendv1.Role) (*backendv1.Role, error) {
 	var roleOrganizationID *uuid.UUID
-	if req.Role.OrganizationId != "" {
-		orgID, err := idformat.Organization.Parse(req.Role.OrganizationId)
+	if role.OrganizationId != "" {
+		orgID, err := idformat.Organization.Parse(role.OrganizationId)
 		if err != nil {
 			return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
 		}
@@ -168,7 +193,7 @@ func (s *Store) CreateRole(ctx context.Context, req *backendv1.CreateRoleRequest
 	}
 
 	var qActionIDs []uuid.UUID
-	for _, action := range req.Role.Actions {
+	for _, action := range role.Actions {
 		var ok bool
 		for _, qAction := range qActions {
 			if qAction.Name == action {
@@ -182,12 +207,18 @@ func (s *Store) CreateRole(ctx context.Context, req *backendv1.CreateRoleRequest
 		}
 	}
 
+	inheritedRoleIDs, err := s.parseInheritedRoleIDs(ctx, q, nil, roleOrganizationID, role.InheritedRoleIds)
+	if err != nil {
+		return nil, err
+	}
+
 	qRole, err := q.CreateRole(ctx, queries.CreateRoleParams{
 		ID:             uuid.New(),
 		ProjectID:      authn.ProjectID(ctx),
 		OrganizationID: roleOrganizationID,
-		DisplayName:    req.Role.DisplayName,
-		Description:    req.Role.Description,
+		DisplayName:    role.DisplayName,
+		Description:    role.Description,
+		Requestable:    derefOrEmpty(role.Requestable),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create role: %w", err)
@@ -203,11 +234,25 @@ func (s *Store) CreateRole(ctx context.Context, req *backendv1.CreateRoleRequest
 		}
 	}
 
+	for _, inheritedRoleID := range inheritedRoleIDs {
+		if err := q.UpsertRoleInheritedRole(ctx, queries.UpsertRoleInheritedRoleParams{
+			RoleID:          qRole.ID,
+			InheritedRoleID: inheritedRoleID,
+		}); err != nil {
+			return nil, fmt.Errorf("upsert role inherited role: %w", err)
+		}
+	}
+
 	qRoleActions, err := q.BatchGetRoleActionsByRoleID(ctx, []uuid.UUID{qRole.ID})
 	if err != nil {
 		return nil, fmt.Errorf("batch get role actions by role id: %w", err)
 	}
 
+	qRoleInheritedRoles, err := q.BatchGetRoleInheritedRolesByRoleID(ctx, []uuid.UUID{qRole.ID})
+	if err != nil {
+		return nil, fmt.Errorf("batch get role inherited roles by role id: %w", err)
+	}
+
 	auditRole, err := s.auditlogStore.GetRole(ctx, tx, qRole.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get audit role: %w", err)
@@ -225,11 +270,7 @@ func (s *Store) CreateRole(ctx context.Context, req *backendv1.CreateRoleRequest
 		return nil, fmt.Errorf("log audit event: %w", err)
 	}
 
-	if err := commit(); err != nil {
-		return nil, fmt.Errorf("commit: %w", err)
-	}
-
-	return &backendv1.CreateRoleResponse{Role: parseRole(qRole, qRoleActions, qActions)}, nil
+	return parseRole(qRole, qRoleActions, qRoleInheritedRoles, qActions), nil
 }
 
 func (s *Store) UpdateRole(ctx context.Context, req *backendv1.UpdateRoleRequest) (*backendv1.UpdateRoleResponse, error) {
@@ -239,7 +280,22 @@ func (s *Store) UpdateRole(ctx context.Context, req *backendv1.UpdateRoleRequest
 	}
 	defer rollback()
 
-	roleID, err := idformat.Role.Parse(req.Id)
+	role, err := s.updateRole(ctx, tx, q, req.Id, req.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.UpdateRoleResponse{Role: role}, nil
+}
+
+// updateRole updates the role identified by id and logs an audit event for the
+// update. Fields of role that are not set are left unchanged.
+func (s *Store) updateRole(ctx context.Context, tx pgx.Tx, q *queries.Queries, id string, role *backendv1.Role) (*backendv1.Role, error) {
+	roleID, err := idformat.Role.Parse(id)
 	if err != nil {
 		return nil, apierror.NewInvalidArgumentError("invalid role id", fmt.Errorf("parse role id: %w", err))
 	}
@@ -255,6 +311,10 @@ func (s *Store) UpdateRole(ctx context.Context, req *backendv1.UpdateRoleRequest
 		return nil, fmt.Errorf("get role: %w", err)
 	}
 
+	if qRole.RoleTemplateID != nil {
+		return nil, apierror.NewFailedPreconditionError("roles based on a role template cannot be modified; update the role template instead", fmt.Errorf("role is based on a role template"))
+	}
+
 	auditPreviousRole, err := s.auditlogStore.GetRole(ctx, tx, qRole.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get audit role: %w", err)
@@ -269,18 +329,23 @@ func (s *Store) UpdateRole(ctx context.Context, req *backendv1.UpdateRoleRequest
 	updates.ID = roleID
 
 	updates.DisplayName = qRole.DisplayName
-	if req.Role.DisplayName != "" {
-		updates.DisplayName = req.Role.DisplayName
+	if role.DisplayName != "" {
+		updates.DisplayName = role.DisplayName
 	}
 
 	updates.Description = qRole.Description
-	if req.Role.Description != "" {
-		updates.Description = req.Role.Description
+	if role.Description != "" {
+		updates.Description = role.Description
+	}
+
+	updates.Requestable = qRole.Requestable
+	if role.Requestable != nil {
+		updates.Requestable = *role.Requestable
 	}
 
-	if req.Role.Actions != nil {
-		var qActionIDs []uuid.UUID
-		for _, action := range req.Role.Actions {
+	if role.Actions != nil {
+		qActionIDs := []uuid.UUID{} // initialize because passing NULL has the wrong behavior in the postgres query
+		for _, action := range role.Actions {
 			var ok bool
 			for _, qAction := range qActions {
 				if qAction.Name == action {
@@ -313,6 +378,29 @@ func (s *Store) UpdateRole(ctx context.Context, req *backendv1.UpdateRoleRequest
 		}
 	}
 
+	if role.InheritedRoleIds != nil {
+		inheritedRoleIDs, err := s.parseInheritedRoleIDs(ctx, q, &qRole.ID, qRole.OrganizationID, role.InheritedRoleIds)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, inheritedRoleID := range inheritedRoleIDs {
+			if err := q.UpsertRoleInheritedRole(ctx, queries.UpsertRoleInheritedRoleParams{
+				RoleID:          qRole.ID,
+				InheritedRoleID: inheritedRoleID,
+			}); err != nil {
+				return nil, fmt.Errorf("upsert role inherited role: %w", err)
+			}
+		}
+
+		if err := q.DeleteRoleInheritedRolesNotInList(ctx, queries.DeleteRoleInheritedRolesNotInListParams{
+			RoleID:           qRole.ID,
+			InheritedRoleIds: inheritedRoleIDs,
+		}); err != nil {
+			return nil, fmt.Errorf("delete role inherited roles not in list: %w", err)
+		}
+	}
+
 	qUpdatedRole, err := q.UpdateRole(ctx, updates)
 	if err != nil {
 		return nil, fmt.Errorf("update role: %w", err)
@@ -328,6 +416,11 @@ func (s *Store) UpdateRole(ctx context.Context, req *backendv1.UpdateRoleRequest
 		return nil, fmt.Errorf("batch get role actions by role id: %w", err)
 	}
 
+	qRoleInheritedRoles, err := q.BatchGetRoleInheritedRolesByRoleID(ctx, []uuid.UUID{qUpdatedRole.ID})
+	if err != nil {
+		return nil, fmt.Errorf("batch get role inherited roles by role id: %w", err)
+	}
+
 	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
 		EventName: "tesseral.roles.update",
 		EventDetails: &auditlogv1.UpdateRole{
@@ -341,11 +434,7 @@ func (s *Store) UpdateRole(ctx context.Context, req *backendv1.UpdateRoleRequest
 		return nil, fmt.Errorf("log audit event: %w", err)
 	}
 
-	if err := commit(); err != nil {
-		return nil, fmt.Errorf("commit: %w", err)
-	}
-
-	return &backendv1.UpdateRoleResponse{Role: parseRole(qUpdatedRole, qRoleActions, qActions)}, nil
+	return parseRole(qUpdatedRole, qRoleActions, qRoleInheritedRoles, qActions), nil
 }
 
 func (s *Store) DeleteRole(ctx context.Context, req *backendv1.DeleteRoleRequest) (*backendv1.DeleteRoleResponse, error) {
@@ -355,9 +444,23 @@ func (s *Store) DeleteRole(ctx context.Context, req *backendv1.DeleteRoleRequest
 	}
 	defer rollback()
 
-	roleID, err := idformat.Role.Parse(req.Id)
+	if err := s.deleteRole(ctx, tx, q, req.Id); err != nil {
+		return nil, err
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.DeleteRoleResponse{}, nil
+}
+
+// deleteRole deletes the role identified by id and logs an audit event for its
+// deletion.
+func (s *Store) deleteRole(ctx context.Context, tx pgx.Tx, q *queries.Queries, id string) error {
+	roleID, err := idformat.Role.Parse(id)
 	if err != nil {
-		return nil, apierror.NewInvalidArgumentError("invalid role id", fmt.Errorf("parse role id: %w", err))
+		return apierror.NewInvalidArgumentError("invalid role id", fmt.Errorf("parse role id: %w", err))
 	}
 
 	qRole, err := q.GetRole(ctx, queries.GetRoleParams{
@@ -366,18 +469,22 @@ func (s *Store) DeleteRole(ctx context.Context, req *backendv1.DeleteRoleRequest
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, apierror.NewNotFoundError("role not found", fmt.Errorf("get role: %w", err))
+			return apierror.NewNotFoundError("role not found", fmt.Errorf("get role: %w", err))
 		}
-		return nil, fmt.Errorf("get role: %w", err)
+		return fmt.Errorf("get role: %w", err)
+	}
+
+	if qRole.RoleTemplateID != nil {
+		return apierror.NewFailedPreconditionError("roles based on a role template cannot be deleted; delete the role template instead", fmt.Errorf("role is based on a role template"))
 	}
 
 	auditRole, err := s.auditlogStore.GetRole(ctx, tx, qRole.ID)
 	if err != nil {
-		return nil, fmt.Errorf("get audit role: %w", err)
+		return fmt.Errorf("get audit role: %w", err)
 	}
 
 	if err := q.DeleteRole(ctx, roleID); err != nil {
-		return nil, fmt.Errorf("delete role: %w", err)
+		return fmt.Errorf("delete role: %w", err)
 	}
 
 	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
@@ -389,22 +496,79 @@ func (s *Store) DeleteRole(ctx context.Context, req *backendv1.DeleteRoleRequest
 		ResourceType:   queries.AuditLogEventResourceTypeRole,
 		ResourceID:     &qRole.ID,
 	}); err != nil {
-		return nil, fmt.Errorf("log audit event: %w", err)
+		return fmt.Errorf("log audit event: %w", err)
 	}
 
-	if err := commit(); err != nil {
-		return nil, fmt.Errorf("commit: %w", err)
+	return nil
+}
+
+// parseInheritedRoleIDs validates the roles that a role, identified by roleID
+// and belonging to organizationID, may inherit from. roleID is nil for roles
+// that have not yet been created.
+//
+// Organization-specific roles may inherit from roles in the same organization
+// or from roles available to all organizations. Roles available to all
+// organizations may only inherit from other such roles. Inheritance must not
+// form a cycle.
+func (s *Store) parseInheritedRoleIDs(ctx context.Context, q *queries.Queries, roleID *uuid.UUID, organizationID *uuid.UUID, inheritedRoleIDs []string) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	for _, inheritedRoleID := range inheritedRoleIDs {
+		id, err := idformat.Role.Parse(inheritedRoleID)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid inherited role id", fmt.Errorf("parse inherited role id: %w", err))
+		}
+
+		qInheritedRole, err := q.GetRole(ctx, queries.GetRoleParams{
+			ProjectID: authn.ProjectID(ctx),
+			ID:        id,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.NewNotFoundError("inherited role not found", fmt.Errorf("get inherited role: %w", err))
+			}
+			return nil, fmt.Errorf("get inherited role: %w", err)
+		}
+
+		if roleID != nil && qInheritedRole.ID == *roleID {
+			return nil, apierror.NewInvalidArgumentError("a role cannot inherit from itself", fmt.Errorf("role inherits from itself"))
+		}
+
+		if qInheritedRole.OrganizationID != nil && (organizationID == nil || *qInheritedRole.OrganizationID != *organizationID) {
+			return nil, apierror.NewInvalidArgumentError("a role can only inherit from roles in the same organization or roles available to all organizations", fmt.Errorf("inherited role belongs to another organization"))
+		}
+
+		ids = append(ids, qInheritedRole.ID)
 	}
 
-	return &backendv1.DeleteRoleResponse{}, nil
+	// roles being created cannot be part of a cycle, because nothing inherits
+	// from them yet
+	if roleID == nil || len(ids) == 0 {
+		return ids, nil
+	}
+
+	transitiveIDs, err := q.ListTransitivelyInheritedRoleIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("list transitively inherited role ids: %w", err)
+	}
+
+	if slices.Contains(transitiveIDs, *roleID) {
+		return nil, apierror.NewInvalidArgumentError("role inheritance cannot form a cycle", fmt.Errorf("role inheritance cycle"))
+	}
+
+	return ids, nil
 }
 
-func parseRole(qRole queries.Role, qRoleActions []queries.RoleAction, qActions []queries.Action) *backendv1.Role {
+func parseRole(qRole queries.Role, qRoleActions []queries.RoleAction, qRoleInheritedRoles []queries.RoleInheritedRole, qActions []queries.Action) *backendv1.Role {
 	var orgID string
 	if qRole.OrganizationID != nil {
 		orgID = idformat.Organization.Format(*qRole.OrganizationID)
 	}
 
+	var roleTemplateID string
+	if qRole.RoleTemplateID != nil {
+		roleTemplateID = idformat.RoleTemplate.Format(*qRole.RoleTemplateID)
+	}
+
 	var actions []string
 	for _, qRoleAction := range qRoleActions {
 		if qRoleAction.RoleID != qRole.ID {
@@ -419,13 +583,26 @@ func parseRole(qRole queries.Role, qRoleActions []queries.RoleAction, qActions [
 		}
 	}
 
+	var inheritedRoleIDs []string
+	for _, qRoleInheritedRole := range qRoleInheritedRoles {
+		if qRoleInheritedRole.RoleID != qRole.ID {
+			continue
+		}
+
+		inheritedRoleIDs = append(inheritedRoleIDs, idformat.Role.Format(qRoleInheritedRole.InheritedRoleID))
+	}
+
 	return &backendv1.Role{
-		Id:             idformat.Role.Format(qRole.ID),
-		OrganizationId: orgID,
-		CreateTime:     timestamppb.New(*qRole.CreateTime),
-		UpdateTime:     timestamppb.New(*qRole.UpdateTime),
-		DisplayName:    qRole.DisplayName,
-		Description:    qRole.Description,
-		Actions:        actions,
+		Id:                  idformat.Role.Format(qRole.ID),
+		OrganizationId:      orgID,
+		CreateTime:          timestamppb.New(*qRole.CreateTime),
+		UpdateTime:          timestamppb.New(*qRole.UpdateTime),
+		DisplayName:         qRole.DisplayName,
+		Description:         qRole.Description,
+		Actions:             actions,
+		InheritedRoleIds:    inheritedRoleIDs,
+		Requestable:         &qRole.Requestable,
+		RoleTemplateId:      roleTemplateID,
+		RoleTemplateVersion: derefOrEmpty(qRole.RoleTemplateVersion),
 	}
 }
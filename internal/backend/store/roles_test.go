@@ -232,3 +232,67 @@ INSERT INTO actions (id, project_id, name, description)
 	}
 	require.ElementsMatch(t, organizationRoleIDs, orgIDs)
 }
+
+func TestRole_InheritedRoles(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{DisplayName: "org"})
+
+	baseResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{DisplayName: "base"},
+	})
+	require.NoError(t, err)
+
+	orgResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			OrganizationId:   orgID,
+			DisplayName:      "org",
+			InheritedRoleIds: []string{baseResp.Role.Id},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{baseResp.Role.Id}, orgResp.Role.InheritedRoleIds)
+
+	var connectErr *connect.Error
+
+	// roles available to all organizations cannot inherit organization roles
+	_, err = u.Store.UpdateRole(ctx, &backendv1.UpdateRoleRequest{
+		Id:   baseResp.Role.Id,
+		Role: &backendv1.Role{InheritedRoleIds: []string{orgResp.Role.Id}},
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	// self-inheritance
+	_, err = u.Store.UpdateRole(ctx, &backendv1.UpdateRoleRequest{
+		Id:   baseResp.Role.Id,
+		Role: &backendv1.Role{InheritedRoleIds: []string{baseResp.Role.Id}},
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	// cycles
+	middleResp, err := u.Store.CreateRole(ctx, &backendv1.CreateRoleRequest{
+		Role: &backendv1.Role{
+			DisplayName:      "middle",
+			InheritedRoleIds: []string{baseResp.Role.Id},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.UpdateRole(ctx, &backendv1.UpdateRoleRequest{
+		Id:   baseResp.Role.Id,
+		Role: &backendv1.Role{InheritedRoleIds: []string{middleResp.Role.Id}},
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+
+	// clearing inherited roles
+	updateResp, err := u.Store.UpdateRole(ctx, &backendv1.UpdateRoleRequest{
+		Id:   orgResp.Role.Id,
+		Role: &backendv1.Role{InheritedRoleIds: []string{}},
+	})
+	require.NoError(t, err)
+	require.Empty(t, updateResp.Role.InheritedRoleIds)
+}
@@ -8,19 +8,21 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/cloudflare/cloudflare-go/v4"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	stripeclient "github.com/stripe/stripe-go/v82/client"
-	svix "github.com/svix/svix-webhooks/go"
 	auditlogstore "github.com/tesseral-labs/tesseral/internal/auditlog/store"
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/cloudflaredoh"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
 	"github.com/tesseral-labs/tesseral/internal/oidcclient"
 	"github.com/tesseral-labs/tesseral/internal/pagetoken"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -31,6 +33,7 @@ type Store struct {
 	intermediateSessionSigningKeyKMSKeyID string
 	kms                                   *kms.Client
 	ses                                   *sesv2.Client
+	emailSender                           emailsender.Sender
 	cloudflare                            *cloudflare.Client
 	cloudflareDOH                         *cloudflaredoh.Client
 	pageEncoder                           pagetoken.Encoder
@@ -38,11 +41,13 @@ type Store struct {
 	s3                                    *s3.Client
 	s3PresignClient                       *s3.PresignClient
 	s3UserContentBucketName               string
+	sts                                   *sts.Client
 	sessionSigningKeyKmsKeyID             string
 	googleOAuthClientSecretsKMSKeyID      string
 	microsoftOAuthClientSecretsKMSKeyID   string
 	githubOAuthClientSecretsKMSKeyID      string
 	oidcClientSecretsKMSKeyID             string
+	webhookSigningSecretsKMSKeyID         string
 	userContentBaseUrl                    string
 	authAppsRootDomain                    string
 	tesseralDNSCloudflareZoneID           string
@@ -50,7 +55,7 @@ type Store struct {
 	sesSPFMXRecordValue                   string
 	stripe                                *stripeclient.API
 	stripePriceIDGrowthTier               string
-	svixClient                            *svix.Svix
+	webhookDispatcher                     webhooks.Dispatcher
 	auditlogStore                         *auditlogstore.Store
 	oidc                                  *oidcclient.Client
 }
@@ -62,16 +67,19 @@ type NewStoreParams struct {
 	IntermediateSessionSigningKeyKMSKeyID string
 	KMS                                   *kms.Client
 	SES                                   *sesv2.Client
+	EmailSender                           emailsender.Sender
 	Cloudflare                            *cloudflare.Client
 	CloudflareDOH                         *cloudflaredoh.Client
 	PageEncoder                           pagetoken.Encoder
 	S3                                    *s3.Client
 	S3UserContentBucketName               string
+	STS                                   *sts.Client
 	SessionSigningKeyKmsKeyID             string
 	GoogleOAuthClientSecretsKMSKeyID      string
 	MicrosoftOAuthClientSecretsKMSKeyID   string
 	GithubOAuthClientSecretsKMSKeyID      string
 	OIDCClientSecretsKMSKeyID             string
+	WebhookSigningSecretsKMSKeyID         string
 	UserContentBaseUrl                    string
 	AuthAppsRootDomain                    string
 	TesseralDNSCloudflareZoneID           string
@@ -79,7 +87,7 @@ type NewStoreParams struct {
 	SESSPFMXRecordValue                   string
 	Stripe                                *stripeclient.API
 	StripePriceIDGrowthTier               string
-	SvixClient                            *svix.Svix
+	WebhookDispatcher                     webhooks.Dispatcher
 	AuditlogStore                         *auditlogstore.Store
 	OIDCClient                            *oidcclient.Client
 }
@@ -92,6 +100,7 @@ func New(p NewStoreParams) *Store {
 		intermediateSessionSigningKeyKMSKeyID: p.IntermediateSessionSigningKeyKMSKeyID,
 		kms:                                   p.KMS,
 		ses:                                   p.SES,
+		emailSender:                           p.EmailSender,
 		cloudflare:                            p.Cloudflare,
 		cloudflareDOH:                         p.CloudflareDOH,
 		pageEncoder:                           p.PageEncoder,
@@ -99,11 +108,13 @@ func New(p NewStoreParams) *Store {
 		s3:                                    p.S3,
 		s3PresignClient:                       s3.NewPresignClient(p.S3),
 		s3UserContentBucketName:               p.S3UserContentBucketName,
+		sts:                                   p.STS,
 		sessionSigningKeyKmsKeyID:             p.SessionSigningKeyKmsKeyID,
 		googleOAuthClientSecretsKMSKeyID:      p.GoogleOAuthClientSecretsKMSKeyID,
 		microsoftOAuthClientSecretsKMSKeyID:   p.MicrosoftOAuthClientSecretsKMSKeyID,
 		githubOAuthClientSecretsKMSKeyID:      p.GithubOAuthClientSecretsKMSKeyID,
 		oidcClientSecretsKMSKeyID:             p.OIDCClientSecretsKMSKeyID,
+		webhookSigningSecretsKMSKeyID:         p.WebhookSigningSecretsKMSKeyID,
 		userContentBaseUrl:                    p.UserContentBaseUrl,
 		authAppsRootDomain:                    p.AuthAppsRootDomain,
 		tesseralDNSCloudflareZoneID:           p.TesseralDNSCloudflareZoneID,
@@ -111,7 +122,7 @@ func New(p NewStoreParams) *Store {
 		sesSPFMXRecordValue:                   p.SESSPFMXRecordValue,
 		stripe:                                p.Stripe,
 		stripePriceIDGrowthTier:               p.StripePriceIDGrowthTier,
-		svixClient:                            p.SvixClient,
+		webhookDispatcher:                     p.WebhookDispatcher,
 		auditlogStore:                         p.AuditlogStore,
 		oidc:                                  p.OIDCClient,
 	}
@@ -6,8 +6,10 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	auditlogstore "github.com/tesseral-labs/tesseral/internal/auditlog/store"
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	commonstore "github.com/tesseral-labs/tesseral/internal/common/store"
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
 	"github.com/tesseral-labs/tesseral/internal/oidcclient"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 	"github.com/tesseral-labs/tesseral/internal/storetesting"
@@ -30,9 +32,11 @@ type testUtil struct {
 	Common      *commonstore.Store
 	Environment *storetesting.Environment
 	ProjectID   string
+	EmailSender *emailsender.MemorySender
 }
 
 func newTestUtil(t *testing.T) (context.Context, *testUtil) {
+	emailSender := &emailsender.MemorySender{}
 	store := New(NewStoreParams{
 		DB:                                  environment.DB,
 		S3:                                  environment.S3.Client,
@@ -43,10 +47,16 @@ func newTestUtil(t *testing.T) (context.Context, *testUtil) {
 		MicrosoftOAuthClientSecretsKMSKeyID: environment.KMS.MicrosoftOAuthClientSecretsKMSKeyID,
 		GithubOAuthClientSecretsKMSKeyID:    environment.KMS.GithubOAuthClientSecretsKMSKeyID,
 		OIDCClientSecretsKMSKeyID:           environment.KMS.OIDCClientSecretsKMSKeyID,
+		WebhookSigningSecretsKMSKeyID:       environment.KMS.WebhookSigningSecretsKMSKeyID,
 		DogfoodProjectID:                    environment.DogfoodProjectID,
 		ConsoleDomain:                       environment.ConsoleDomain,
 		AuthAppsRootDomain:                  environment.AuthAppsRootDomain,
 		OIDCClient:                          &oidcclient.Client{HTTPClient: http.DefaultClient},
+		EmailSender:                         emailSender,
+		AuditlogStore: auditlogstore.New(auditlogstore.NewStoreParams{
+			KMS:                       environment.KMS.Client,
+			AuditLogChainKeysKMSKeyID: environment.KMS.AuditLogChainKeysKMSKeyID,
+		}),
 	})
 	commonStore := commonstore.New(commonstore.NewStoreParams{
 		AppAuthRootDomain:         environment.ConsoleDomain,
@@ -67,5 +77,6 @@ func newTestUtil(t *testing.T) (context.Context, *testUtil) {
 		Common:      commonStore,
 		Environment: environment,
 		ProjectID:   projectID,
+		EmailSender: emailSender,
 	}
 }
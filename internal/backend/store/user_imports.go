@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"connectrpc.com/connect"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	commonv1 "github.com/tesseral-labs/tesseral/internal/common/gen/tesseral/common/v1"
+	"github.com/tesseral-labs/tesseral/internal/passwordhash"
+)
+
+const maxUserImportBatchSize = 1000
+
+func (s *Store) ImportUsers(ctx context.Context, req *backendv1.ImportUsersRequest) (*backendv1.ImportUsersResponse, error) {
+	if len(req.Users) > maxUserImportBatchSize {
+		return nil, apierror.NewInvalidArgumentError(fmt.Sprintf("at most %d users may be imported at once", maxUserImportBatchSize), fmt.Errorf("too many users: %d", len(req.Users)))
+	}
+
+	var results []*backendv1.UserImportResult
+	for _, userImport := range req.Users {
+		user, err := s.importUser(ctx, userImport)
+		if err != nil {
+			// API errors are specific to the user being imported, and so are
+			// reported alongside it; anything else fails the whole import
+			var connectErr *connect.Error
+			if !errors.As(err, &connectErr) {
+				return nil, fmt.Errorf("import user: %w", err)
+			}
+
+			results = append(results, &backendv1.UserImportResult{
+				Error: connectErrorDescription(connectErr),
+			})
+			continue
+		}
+
+		results = append(results, &backendv1.UserImportResult{
+			User: user,
+		})
+	}
+
+	return &backendv1.ImportUsersResponse{Results: results}, nil
+}
+
+// importUser creates a user, and gives its identity the user's password hash,
+// in a single transaction.
+func (s *Store) importUser(ctx context.Context, userImport *backendv1.UserImport) (*backendv1.User, error) {
+	if userImport.User == nil {
+		return nil, apierror.NewInvalidArgumentError("user is required", fmt.Errorf("user is required"))
+	}
+
+	var passwordBcrypt, importedPasswordHash *string
+	if userImport.PasswordHash != "" {
+		if err := passwordhash.Validate(userImport.PasswordHash); err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid password hash", fmt.Errorf("validate password hash: %w", err))
+		}
+
+		// bcrypt hashes can be used as-is
+		if passwordhash.IsBcrypt(userImport.PasswordHash) {
+			passwordBcrypt = &userImport.PasswordHash
+		} else {
+			importedPasswordHash = &userImport.PasswordHash
+		}
+	}
+
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qUser, qIdentity, err := s.createUser(ctx, tx, q, userImport.User)
+	if err != nil {
+		return nil, err
+	}
+
+	if userImport.PasswordHash != "" {
+		// credentials are shared by every user with the identity, so never
+		// replace a password the identity already has
+		if qIdentity.PasswordBcrypt != nil || qIdentity.ImportedPasswordHash != nil {
+			return nil, apierror.NewFailedPreconditionError("user already has password configured", fmt.Errorf("identity already has password"))
+		}
+
+		qIdentity, err = q.UpdateIdentityPassword(ctx, queries.UpdateIdentityPasswordParams{
+			ID:                   qIdentity.ID,
+			PasswordBcrypt:       passwordBcrypt,
+			ImportedPasswordHash: importedPasswordHash,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("update identity password: %w", err)
+		}
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return parseUser(qUser, qIdentity), nil
+}
+
+// connectErrorDescription returns the human-readable description of an error
+// created by apierror.
+func connectErrorDescription(connectErr *connect.Error) string {
+	for _, detail := range connectErr.Details() {
+		value, err := detail.Value()
+		if err != nil {
+			continue
+		}
+
+		if errorDetail, ok := value.(*commonv1.ErrorDetail); ok {
+			return errorDetail.Description
+		}
+	}
+
+	return connectErr.Message()
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestImportUsers(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+	u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "existing@example.com",
+	})
+
+	passwordBcrypt, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	// pbkdf2-sha256 of "password", with salt "salt" and 1000 iterations
+	passwordPBKDF2 := "$pbkdf2-sha256$i=1000$c2FsdA$YywoEuRtRgQQK6dhjp1tfS+BKPYma0oDJk0qBGC33LM"
+
+	res, err := u.Store.ImportUsers(ctx, &backendv1.ImportUsersRequest{
+		Users: []*backendv1.UserImport{
+			{
+				User: &backendv1.User{
+					OrganizationId: orgID,
+					Email:          "bcrypt@example.com",
+					DisplayName:    refOrNil("Bcrypt User"),
+				},
+				PasswordHash: string(passwordBcrypt),
+			},
+			{
+				User: &backendv1.User{
+					OrganizationId: orgID,
+					Email:          "pbkdf2@example.com",
+				},
+				PasswordHash: passwordPBKDF2,
+			},
+			{
+				User: &backendv1.User{
+					OrganizationId: orgID,
+					Email:          "existing@example.com",
+				},
+			},
+			{
+				User: &backendv1.User{
+					OrganizationId: orgID,
+					Email:          "invalid@example.com",
+				},
+				PasswordHash: "$md5$abc",
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Results, 4)
+
+	require.Empty(t, res.Results[0].Error)
+	require.Equal(t, "bcrypt@example.com", res.Results[0].User.Email)
+	require.Equal(t, "Bcrypt User", res.Results[0].User.GetDisplayName())
+
+	require.Empty(t, res.Results[1].Error)
+	require.Equal(t, "pbkdf2@example.com", res.Results[1].User.Email)
+
+	require.Nil(t, res.Results[2].User)
+	require.Equal(t, "a user with that email already exists", res.Results[2].Error)
+
+	require.Nil(t, res.Results[3].User)
+	require.Equal(t, "invalid password hash", res.Results[3].Error)
+
+	identityPasswordQuery := `SELECT identities.password_bcrypt, identities.imported_password_hash FROM identities JOIN users ON users.identity_id = identities.id WHERE users.id = $1`
+
+	var gotBcrypt, gotImported *string
+	bcryptUserID, err := idformat.User.Parse(res.Results[0].User.Id)
+	require.NoError(t, err)
+	err = u.Environment.DB.QueryRow(t.Context(), identityPasswordQuery, uuid.UUID(bcryptUserID)).Scan(&gotBcrypt, &gotImported)
+	require.NoError(t, err)
+	require.Equal(t, string(passwordBcrypt), *gotBcrypt)
+	require.Nil(t, gotImported)
+
+	pbkdf2UserID, err := idformat.User.Parse(res.Results[1].User.Id)
+	require.NoError(t, err)
+	err = u.Environment.DB.QueryRow(t.Context(), identityPasswordQuery, uuid.UUID(pbkdf2UserID)).Scan(&gotBcrypt, &gotImported)
+	require.NoError(t, err)
+	require.Nil(t, gotBcrypt)
+	require.Equal(t, passwordPBKDF2, *gotImported)
+}
@@ -1,23 +1,21 @@
 package store
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
-	"text/template"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/sesv2"
-	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/acceptlanguage"
 	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
+	"github.com/tesseral-labs/tesseral/internal/emailtemplates"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -105,6 +103,17 @@ func (s *Store) GetUserInvite(ctx context.Context, req *backendv1.GetUserInviteR
 }
 
 func (s *Store) CreateUserInvite(ctx context.Context, req *backendv1.CreateUserInviteRequest) (*backendv1.CreateUserInviteResponse, error) {
+	// The invite email is localized for the request's Accept-Language header
+	// unless the caller names the invitee's locale.
+	acceptLanguage := acceptlanguage.FromContext(ctx)
+	if req.Locale != "" {
+		locale, err := emailtemplates.ParseLocale(req.Locale)
+		if err != nil {
+			return nil, apierror.NewInvalidArgumentError("invalid locale", fmt.Errorf("parse locale: %w", err))
+		}
+		acceptLanguage = locale
+	}
+
 	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
 		return nil, err
@@ -191,7 +200,7 @@ func (s *Store) CreateUserInvite(ctx context.Context, req *backendv1.CreateUserI
 	}
 
 	if req.SendEmail {
-		if err := s.sendUserInviteEmail(ctx, req.UserInvite.Email, qOrg.DisplayName); err != nil {
+		if err := s.sendUserInviteEmail(ctx, req.UserInvite.Email, qOrg.DisplayName, acceptLanguage); err != nil {
 			return nil, fmt.Errorf("send user invite email: %w", err)
 		}
 	}
@@ -262,58 +271,31 @@ func parseUserInvite(qUserInvite queries.UserInvite) *backendv1.UserInvite {
 	}
 }
 
-var userInviteEmailBodyTmpl = template.Must(template.New("userInviteEmailBodyTmpl").Parse(`Hello,
-
-You have been invited to join {{ .OrganizationDisplayName }} in {{ .ProjectDisplayName }}.
-
-You can accept this invite by signing up for {{ .ProjectDisplayName }}:
-
-{{ .SignupLink }}
-`))
-
-func (s *Store) sendUserInviteEmail(ctx context.Context, toAddress string, organizationDisplayName string) error {
+func (s *Store) sendUserInviteEmail(ctx context.Context, toAddress string, organizationDisplayName string, acceptLanguage string) error {
 	qProject, err := s.q.GetProjectByID(ctx, authn.ProjectID(ctx))
 	if err != nil {
 		return fmt.Errorf("get project by id: %w", err)
 	}
 
-	subject := fmt.Sprintf("%s - You've been invited to join %s", qProject.DisplayName, organizationDisplayName)
-
 	vaultDomain := qProject.VaultDomain
 	if authn.ProjectID(ctx) == *s.dogfoodProjectID {
 		vaultDomain = s.consoleDomain
 	}
 
-	var body bytes.Buffer
-	if err := userInviteEmailBodyTmpl.Execute(&body, struct {
-		ProjectDisplayName      string
-		OrganizationDisplayName string
-		SignupLink              string
-	}{
-		ProjectDisplayName:      qProject.DisplayName,
-		OrganizationDisplayName: organizationDisplayName,
-		SignupLink:              fmt.Sprintf("https://%s/signup", vaultDomain),
-	}); err != nil {
-		return fmt.Errorf("execute email verification email body template: %w", err)
-	}
-
-	if _, err := s.ses.SendEmail(ctx, &sesv2.SendEmailInput{
-		Content: &types.EmailContent{
-			Simple: &types.Message{
-				Subject: &types.Content{
-					Data: &subject,
-				},
-				Body: &types.Body{
-					Text: &types.Content{
-						Data: aws.String(body.String()),
-					},
-				},
-			},
-		},
-		Destination: &types.Destination{
-			ToAddresses: []string{toAddress},
-		},
-		FromEmailAddress: aws.String(fmt.Sprintf("noreply@%s", qProject.EmailSendFromDomain)),
+	email, err := s.renderEmail(ctx, qProject, emailtemplates.TypeUserInvite, acceptLanguage, map[string]string{
+		"OrganizationDisplayName": organizationDisplayName,
+		"SignupLink":              fmt.Sprintf("https://%s/signup", vaultDomain),
+	})
+	if err != nil {
+		return fmt.Errorf("render user invite email: %w", err)
+	}
+
+	if err := s.emailSender.Send(ctx, emailsender.Message{
+		From:     fmt.Sprintf("noreply@%s", qProject.EmailSendFromDomain),
+		To:       []string{toAddress},
+		Subject:  email.Subject,
+		TextBody: email.TextBody,
+		HTMLBody: email.HTMLBody,
 	}); err != nil {
 		return fmt.Errorf("send email: %w", err)
 	}
@@ -36,6 +36,31 @@ func TestCreateUserInvite_Success(t *testing.T) {
 	require.NotEmpty(t, resp.UserInvite.UpdateTime)
 }
 
+func TestCreateUserInvite_SendEmail(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	_, err := u.Store.CreateUserInvite(ctx, &backendv1.CreateUserInviteRequest{
+		UserInvite: &backendv1.UserInvite{
+			OrganizationId: orgID,
+			Email:          "test@example.com",
+			Owner:          false,
+		},
+		SendEmail: true,
+	})
+	require.NoError(t, err)
+
+	messages := u.EmailSender.Messages()
+	require.Len(t, messages, 1)
+	require.Equal(t, []string{"test@example.com"}, messages[0].To)
+	require.Contains(t, messages[0].Subject, "You've been invited to join test")
+	require.Contains(t, messages[0].TextBody, "/signup")
+}
+
 func TestCreateUserInvite_DuplicateEmail(t *testing.T) {
 	t.Parallel()
 
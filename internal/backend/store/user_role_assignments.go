@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -13,6 +14,7 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *Store) ListUserRoleAssignments(ctx context.Context, req *backendv1.ListUserRoleAssignmentsRequest) (*backendv1.ListUserRoleAssignmentsResponse, error) {
@@ -180,6 +182,21 @@ func (s *Store) CreateUserRoleAssignment(ctx context.Context, req *backendv1.Cre
 		return nil, apierror.NewInvalidArgumentError("invalid user id", fmt.Errorf("parse user id: %w", err))
 	}
 
+	if (req.UserRoleAssignment.ResourceType == "") != (req.UserRoleAssignment.ResourceId == "") {
+		return nil, apierror.NewInvalidArgumentError("resource_type and resource_id must be provided together", fmt.Errorf("resource_type and resource_id must be provided together"))
+	}
+
+	// resource ids are specific to an organization, so only role assignments
+	// across every resource make sense in descendant organizations
+	if req.UserRoleAssignment.InheritToDescendants && req.UserRoleAssignment.ResourceType != "" {
+		return nil, apierror.NewInvalidArgumentError("inherit_to_descendants is not supported for role assignments scoped to a resource", fmt.Errorf("inherit_to_descendants is not supported for role assignments scoped to a resource"))
+	}
+
+	expireTime, err := parseRoleAssignmentExpireTime(req.UserRoleAssignment.ExpireTime)
+	if err != nil {
+		return nil, err
+	}
+
 	// ensure both role and user belong to project
 	if _, err := q.GetRole(ctx, queries.GetRoleParams{
 		ProjectID: authn.ProjectID(ctx),
@@ -203,16 +220,22 @@ func (s *Store) CreateUserRoleAssignment(ctx context.Context, req *backendv1.Cre
 	}
 
 	if err := q.UpsertUserRoleAssignment(ctx, queries.UpsertUserRoleAssignmentParams{
-		ID:     uuid.New(),
-		RoleID: roleID,
-		UserID: userID,
+		ID:                   uuid.New(),
+		RoleID:               roleID,
+		UserID:               userID,
+		ResourceType:         refOrNil(req.UserRoleAssignment.ResourceType),
+		ResourceID:           refOrNil(req.UserRoleAssignment.ResourceId),
+		ExpireTime:           expireTime,
+		InheritToDescendants: req.UserRoleAssignment.InheritToDescendants,
 	}); err != nil {
 		return nil, fmt.Errorf("upsert user role assignment: %w", err)
 	}
 
 	qUserRoleAssignment, err := q.GetUserRoleAssignmentByUserAndRole(ctx, queries.GetUserRoleAssignmentByUserAndRoleParams{
-		UserID: userID,
-		RoleID: roleID,
+		UserID:       userID,
+		RoleID:       roleID,
+		ResourceType: refOrNil(req.UserRoleAssignment.ResourceType),
+		ResourceID:   refOrNil(req.UserRoleAssignment.ResourceId),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("get user role assignment by user and role: %w", err)
@@ -306,8 +329,26 @@ func (s *Store) DeleteUserRoleAssignment(ctx context.Context, req *backendv1.Del
 
 func parseUserRoleAssignment(qUserRoleAssignment queries.UserRoleAssignment) *backendv1.UserRoleAssignment {
 	return &backendv1.UserRoleAssignment{
-		Id:     idformat.UserRoleAssignment.Format(qUserRoleAssignment.ID),
-		RoleId: idformat.Role.Format(qUserRoleAssignment.RoleID),
-		UserId: idformat.User.Format(qUserRoleAssignment.UserID),
+		Id:                   idformat.UserRoleAssignment.Format(qUserRoleAssignment.ID),
+		RoleId:               idformat.Role.Format(qUserRoleAssignment.RoleID),
+		UserId:               idformat.User.Format(qUserRoleAssignment.UserID),
+		ResourceType:         derefOrEmpty(qUserRoleAssignment.ResourceType),
+		ResourceId:           derefOrEmpty(qUserRoleAssignment.ResourceID),
+		ExpireTime:           timestampOrNil(qUserRoleAssignment.ExpireTime),
+		InheritToDescendants: qUserRoleAssignment.InheritToDescendants,
+	}
+}
+
+// parseRoleAssignmentExpireTime validates an optional role assignment
+// expire_time, which must be in the future if provided.
+func parseRoleAssignmentExpireTime(expireTime *timestamppb.Timestamp) (*time.Time, error) {
+	if expireTime == nil {
+		return nil, nil
+	}
+
+	t := expireTime.AsTime()
+	if !t.After(time.Now()) {
+		return nil, apierror.NewInvalidArgumentError("expire_time must be in the future", fmt.Errorf("expire_time must be in the future"))
 	}
+	return &t, nil
 }
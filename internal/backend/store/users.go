@@ -7,13 +7,14 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/svix/svix-webhooks/go/models"
+	"github.com/jackc/pgx/v5/pgconn"
 	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/authn"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -46,10 +47,17 @@ func (s *Store) ListUsers(ctx context.Context, req *backendv1.ListUsersRequest)
 		return nil, fmt.Errorf("unmarshal page token: %w", err)
 	}
 
+	metadataValue, err := metadataFilterValue(req.FilterMetadataKey, req.FilterMetadataValue)
+	if err != nil {
+		return nil, err
+	}
+
 	limit := 10
 	qUsers, err := q.ListUsers(ctx, queries.ListUsersParams{
 		OrganizationID: orgID,
 		ID:             startID,
+		MetadataKey:    refOrNil(req.FilterMetadataKey),
+		MetadataValue:  metadataValue,
 		Limit:          int32(limit + 1),
 	})
 	if err != nil {
@@ -58,7 +66,12 @@ func (s *Store) ListUsers(ctx context.Context, req *backendv1.ListUsersRequest)
 
 	var users []*backendv1.User
 	for _, qUser := range qUsers {
-		users = append(users, parseUser(qUser))
+		qIdentity, err := q.GetIdentityByID(ctx, qUser.IdentityID)
+		if err != nil {
+			return nil, fmt.Errorf("get identity by id: %w", err)
+		}
+
+		users = append(users, parseUser(qUser, qIdentity))
 	}
 
 	var nextPageToken string
@@ -97,7 +110,12 @@ func (s *Store) GetUser(ctx context.Context, req *backendv1.GetUserRequest) (*ba
 		return nil, fmt.Errorf("get user: %w", err)
 	}
 
-	return &backendv1.GetUserResponse{User: parseUser(qUser)}, nil
+	qIdentity, err := q.GetIdentityByID(ctx, qUser.IdentityID)
+	if err != nil {
+		return nil, fmt.Errorf("get identity by id: %w", err)
+	}
+
+	return &backendv1.GetUserResponse{User: parseUser(qUser, qIdentity)}, nil
 }
 
 func (s *Store) CreateUser(ctx context.Context, req *backendv1.CreateUserRequest) (*backendv1.CreateUserResponse, error) {
@@ -107,9 +125,22 @@ func (s *Store) CreateUser(ctx context.Context, req *backendv1.CreateUserRequest
 	}
 	defer rollback()
 
-	orgID, err := idformat.Organization.Parse(req.User.OrganizationId)
+	qUser, qIdentity, err := s.createUser(ctx, tx, q, req.User)
 	if err != nil {
-		return nil, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+		return nil, err
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.CreateUserResponse{User: parseUser(qUser, qIdentity)}, nil
+}
+
+func (s *Store) createUser(ctx context.Context, tx pgx.Tx, q *queries.Queries, user *backendv1.User) (queries.User, queries.Identity, error) {
+	orgID, err := idformat.Organization.Parse(user.OrganizationId)
+	if err != nil {
+		return queries.User{}, queries.Identity{}, apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
 	}
 
 	if _, err := q.GetOrganizationByProjectIDAndID(ctx, queries.GetOrganizationByProjectIDAndIDParams{
@@ -117,30 +148,60 @@ func (s *Store) CreateUser(ctx context.Context, req *backendv1.CreateUserRequest
 		ID:        orgID,
 	}); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, apierror.NewNotFoundError("organization not found", fmt.Errorf("get organization: %w", err))
+			return queries.User{}, queries.Identity{}, apierror.NewNotFoundError("organization not found", fmt.Errorf("get organization: %w", err))
 		}
-		return nil, fmt.Errorf("get organization: %w", err)
+		return queries.User{}, queries.Identity{}, fmt.Errorf("get organization: %w", err)
+	}
+
+	publicMetadata, err := applyMetadataPatch("public metadata", []byte("{}"), user.PublicMetadata, maxPublicMetadataSize)
+	if err != nil {
+		return queries.User{}, queries.Identity{}, err
+	}
+
+	privateMetadata, err := applyMetadataPatch("private metadata", []byte("{}"), user.PrivateMetadata, maxPrivateMetadataSize)
+	if err != nil {
+		return queries.User{}, queries.Identity{}, err
+	}
+
+	// users created over the backend API get an identity of their own; only
+	// users that prove they own an email join the identity for it
+	qIdentity, err := q.CreateIdentity(ctx, queries.CreateIdentityParams{
+		ID:        uuid.New(),
+		ProjectID: authn.ProjectID(ctx),
+		Email:     user.Email,
+	})
+	if err != nil {
+		return queries.User{}, queries.Identity{}, fmt.Errorf("create identity: %w", err)
 	}
 
 	qUser, err := q.CreateUser(ctx, queries.CreateUserParams{
-		ID:              uuid.New(),
-		OrganizationID:  orgID,
-		Email:           req.User.Email,
-		IsOwner:         req.User.GetOwner(),
-		GoogleUserID:    req.User.GoogleUserId,
-		MicrosoftUserID: req.User.MicrosoftUserId,
-		GithubUserID:    req.User.GithubUserId,
+		ID:                uuid.New(),
+		OrganizationID:    orgID,
+		IdentityID:        qIdentity.ID,
+		Email:             user.Email,
+		IsOwner:           user.GetOwner(),
+		GoogleUserID:      user.GoogleUserId,
+		MicrosoftUserID:   user.MicrosoftUserId,
+		GithubUserID:      user.GithubUserId,
+		PublicMetadata:    publicMetadata,
+		PrivateMetadata:   privateMetadata,
+		DisplayName:       user.DisplayName,
+		ProfilePictureUrl: user.ProfilePictureUrl,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("create user: %w", err)
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23505" && pgxErr.ConstraintName == "users_organization_id_email_key" {
+			return queries.User{}, queries.Identity{}, apierror.NewAlreadyExistsError("a user with that email already exists", fmt.Errorf("create user: %w", err))
+		}
+
+		return queries.User{}, queries.Identity{}, fmt.Errorf("create user: %w", err)
 	}
 
 	auditUser, err := s.auditlogStore.GetUser(ctx, tx, qUser.ID)
 	if err != nil {
-		return nil, fmt.Errorf("get audit user: %w", err)
+		return queries.User{}, queries.Identity{}, fmt.Errorf("get audit user: %w", err)
 	}
 
-	user := parseUser(qUser)
 	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
 		EventName: "tesseral.users.create",
 		EventDetails: &auditlogv1.CreateUser{
@@ -150,19 +211,15 @@ func (s *Store) CreateUser(ctx context.Context, req *backendv1.CreateUserRequest
 		ResourceType:   queries.AuditLogEventResourceTypeUser,
 		ResourceID:     &qUser.ID,
 	}); err != nil {
-		return nil, fmt.Errorf("create audit log event: %w", err)
-	}
-
-	if err := commit(); err != nil {
-		return nil, fmt.Errorf("commit: %w", err)
+		return queries.User{}, queries.Identity{}, fmt.Errorf("create audit log event: %w", err)
 	}
 
 	// send sync user event
-	if err := s.sendSyncUserEvent(ctx, qUser); err != nil {
-		return nil, fmt.Errorf("send sync user event: %w", err)
+	if err := s.sendSyncUserEvent(ctx, q, qUser); err != nil {
+		return queries.User{}, queries.Identity{}, fmt.Errorf("send sync user event: %w", err)
 	}
 
-	return &backendv1.CreateUserResponse{User: user}, nil
+	return qUser, qIdentity, nil
 }
 
 func (s *Store) UpdateUser(ctx context.Context, req *backendv1.UpdateUserRequest) (*backendv1.UpdateUserResponse, error) {
@@ -201,6 +258,22 @@ func (s *Store) UpdateUser(ctx context.Context, req *backendv1.UpdateUserRequest
 		updates.Email = req.User.Email
 	}
 
+	// a user whose email changes moves onto a new identity of its own, and so
+	// does not keep the credentials of its previous email
+	updates.IdentityID = qUser.IdentityID
+	if updates.Email != qUser.Email {
+		qIdentity, err := q.CreateIdentity(ctx, queries.CreateIdentityParams{
+			ID:        uuid.New(),
+			ProjectID: authn.ProjectID(ctx),
+			Email:     updates.Email,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create identity: %w", err)
+		}
+
+		updates.IdentityID = qIdentity.ID
+	}
+
 	updates.IsOwner = qUser.IsOwner
 	if req.User.Owner != nil {
 		updates.IsOwner = *req.User.Owner
@@ -233,22 +306,47 @@ func (s *Store) UpdateUser(ctx context.Context, req *backendv1.UpdateUserRequest
 		updates.ProfilePictureUrl = refOrNil(*req.User.ProfilePictureUrl)
 	}
 
+	updates.PublicMetadata, err = applyMetadataPatch("public metadata", qUser.PublicMetadata, req.User.PublicMetadata, maxPublicMetadataSize)
+	if err != nil {
+		return nil, err
+	}
+
+	updates.PrivateMetadata, err = applyMetadataPatch("private metadata", qUser.PrivateMetadata, req.User.PrivateMetadata, maxPrivateMetadataSize)
+	if err != nil {
+		return nil, err
+	}
+
 	qUpdatedUser, err := q.UpdateUser(ctx, updates)
 	if err != nil {
 		return nil, fmt.Errorf("update user: %w", err)
 	}
 
+	privateMetadataChangedKeys, err := changedMetadataKeys(qUser.PrivateMetadata, qUpdatedUser.PrivateMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("changed private metadata keys: %w", err)
+	}
+
+	if err := q.DeleteIdentityIfUnused(ctx, qUser.IdentityID); err != nil {
+		return nil, fmt.Errorf("delete identity if unused: %w", err)
+	}
+
+	qIdentity, err := q.GetIdentityByID(ctx, qUpdatedUser.IdentityID)
+	if err != nil {
+		return nil, fmt.Errorf("get identity by id: %w", err)
+	}
+
 	auditUser, err := s.auditlogStore.GetUser(ctx, tx, qUpdatedUser.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get audit user: %w", err)
 	}
 
-	user := parseUser(qUpdatedUser)
+	user := parseUser(qUpdatedUser, qIdentity)
 	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
 		EventName: "tesseral.users.update",
 		EventDetails: &auditlogv1.UpdateUser{
-			User:         auditUser,
-			PreviousUser: auditPreviousUser,
+			User:                       auditUser,
+			PreviousUser:               auditPreviousUser,
+			PrivateMetadataChangedKeys: privateMetadataChangedKeys,
 		},
 		OrganizationID: &qUpdatedUser.OrganizationID,
 		ResourceType:   queries.AuditLogEventResourceTypeUser,
@@ -257,15 +355,15 @@ func (s *Store) UpdateUser(ctx context.Context, req *backendv1.UpdateUserRequest
 		return nil, fmt.Errorf("create audit log event: %w", err)
 	}
 
-	if err := commit(); err != nil {
-		return nil, fmt.Errorf("commit: %w", err)
-	}
-
 	// send sync user event
-	if err := s.sendSyncUserEvent(ctx, qUpdatedUser); err != nil {
+	if err := s.sendSyncUserEvent(ctx, q, qUpdatedUser); err != nil {
 		return nil, fmt.Errorf("send sync user event: %w", err)
 	}
 
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
 	return &backendv1.UpdateUserResponse{User: user}, nil
 }
 
@@ -301,6 +399,10 @@ func (s *Store) DeleteUser(ctx context.Context, req *backendv1.DeleteUserRequest
 		return nil, fmt.Errorf("delete user: %w", err)
 	}
 
+	if err := q.DeleteIdentityIfUnused(ctx, qUser.IdentityID); err != nil {
+		return nil, fmt.Errorf("delete identity if unused: %w", err)
+	}
+
 	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
 		EventName: "tesseral.users.delete",
 		EventDetails: &auditlogv1.DeleteUser{
@@ -313,42 +415,33 @@ func (s *Store) DeleteUser(ctx context.Context, req *backendv1.DeleteUserRequest
 		return nil, fmt.Errorf("create audit log event: %w", err)
 	}
 
-	if err := commit(); err != nil {
-		return nil, fmt.Errorf("commit: %w", err)
-	}
-
 	// send sync user event
-	if err := s.sendSyncUserEvent(ctx, qUser); err != nil {
+	if err := s.sendSyncUserEvent(ctx, q, qUser); err != nil {
 		return nil, fmt.Errorf("send sync user event: %w", err)
 	}
 
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
 	return &backendv1.DeleteUserResponse{}, nil
 }
 
-func (s *Store) sendSyncUserEvent(ctx context.Context, qUser queries.User) error {
-	qProjectWebhookSettings, err := s.q.GetProjectWebhookSettings(ctx, authn.ProjectID(ctx))
-	if err != nil {
-		// We want to ignore this error if the project does not have webhook settings
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil
-		}
-		return fmt.Errorf("get project by id: %w", err)
-	}
-
-	if _, err := s.svixClient.Message.Create(ctx, qProjectWebhookSettings.AppID, models.MessageIn{
+func (s *Store) sendSyncUserEvent(ctx context.Context, q *queries.Queries, qUser queries.User) error {
+	if err := s.enqueueWebhookMessage(ctx, q, authn.ProjectID(ctx), webhooks.Message{
 		EventType: "sync.user",
 		Payload: map[string]interface{}{
 			"type":   "sync.user",
 			"userId": idformat.User.Format(qUser.ID),
 		},
-	}, nil); err != nil {
-		return fmt.Errorf("create message: %w", err)
+	}); err != nil {
+		return fmt.Errorf("enqueue webhook message: %w", err)
 	}
 
 	return nil
 }
 
-func parseUser(qUser queries.User) *backendv1.User {
+func parseUser(qUser queries.User, qIdentity queries.Identity) *backendv1.User {
 	return &backendv1.User{
 		Id:                  idformat.User.Format(qUser.ID),
 		OrganizationId:      idformat.Organization.Format(qUser.OrganizationID),
@@ -359,8 +452,10 @@ func parseUser(qUser queries.User) *backendv1.User {
 		GoogleUserId:        qUser.GoogleUserID,
 		MicrosoftUserId:     qUser.MicrosoftUserID,
 		GithubUserId:        qUser.GithubUserID,
-		HasAuthenticatorApp: qUser.AuthenticatorAppSecretCiphertext != nil,
+		HasAuthenticatorApp: qIdentity.AuthenticatorAppSecretCiphertext != nil,
 		DisplayName:         qUser.DisplayName,
 		ProfilePictureUrl:   qUser.ProfilePictureUrl,
+		PublicMetadata:      parseMetadata(qUser.PublicMetadata),
+		PrivateMetadata:     parseMetadata(qUser.PrivateMetadata),
 	}
 }
@@ -2,6 +2,7 @@ package store
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"connectrpc.com/connect"
@@ -9,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 func TestCreateUser_Success(t *testing.T) {
@@ -52,6 +54,66 @@ func TestCreateUser_OrgNotFound(t *testing.T) {
 	require.Equal(t, connect.CodeNotFound, connectErr.Code())
 }
 
+func TestCreateUser_DoesNotJoinExistingIdentity(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	org1ID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test1",
+	})
+	org2ID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test2",
+	})
+	org3ID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test3",
+	})
+
+	// users that have logged in with the same email share an identity
+	userIDs := []string{
+		u.Environment.NewUser(t, org1ID, &backendv1.User{Email: "shared@example.com"}),
+		u.Environment.NewUser(t, org2ID, &backendv1.User{Email: "shared@example.com"}),
+	}
+
+	identityID := func(userID string) uuid.UUID {
+		userUUID, err := idformat.User.Parse(userID)
+		require.NoError(t, err)
+
+		var identityID uuid.UUID
+		err = u.Environment.DB.QueryRow(t.Context(), `SELECT identity_id FROM users WHERE id = $1`, uuid.UUID(userUUID)).Scan(&identityID)
+		require.NoError(t, err)
+		return identityID
+	}
+
+	sharedIdentityID := identityID(userIDs[0])
+	require.Equal(t, sharedIdentityID, identityID(userIDs[1]))
+
+	// a user created over the backend API does not get their credentials
+	resp, err := u.Store.CreateUser(ctx, &backendv1.CreateUserRequest{
+		User: &backendv1.User{
+			OrganizationId: org3ID,
+			Email:          "shared@example.com",
+		},
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, sharedIdentityID, identityID(resp.User.Id))
+
+	identityExists := func() bool {
+		var exists bool
+		err := u.Environment.DB.QueryRow(t.Context(), `SELECT exists(SELECT 1 FROM identities WHERE id = $1)`, sharedIdentityID).Scan(&exists)
+		require.NoError(t, err)
+		return exists
+	}
+
+	// the identity outlives any one of its users, but not all of them
+	_, err = u.Store.DeleteUser(ctx, &backendv1.DeleteUserRequest{Id: userIDs[0]})
+	require.NoError(t, err)
+	require.True(t, identityExists())
+
+	_, err = u.Store.DeleteUser(ctx, &backendv1.DeleteUserRequest{Id: userIDs[1]})
+	require.NoError(t, err)
+	require.False(t, identityExists())
+}
+
 func TestGetUser_Exists(t *testing.T) {
 	t.Parallel()
 
@@ -118,6 +180,79 @@ func TestUpdateUser_UpdatesFields(t *testing.T) {
 	require.Equal(t, "https://example.com/profile.jpg", updateResp.User.GetProfilePictureUrl())
 }
 
+func TestUpdateUser_MergesMetadata(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	publicMetadata, err := structpb.NewStruct(map[string]any{"plan": "free", "seats": 5})
+	require.NoError(t, err)
+	privateMetadata, err := structpb.NewStruct(map[string]any{"stripe": map[string]any{"customer": "cus_123"}})
+	require.NoError(t, err)
+
+	createResp, err := u.Store.CreateUser(ctx, &backendv1.CreateUserRequest{
+		User: &backendv1.User{
+			OrganizationId:  orgID,
+			Email:           "test@example.com",
+			PublicMetadata:  publicMetadata,
+			PrivateMetadata: privateMetadata,
+		},
+	})
+	require.NoError(t, err)
+
+	publicMetadataPatch, err := structpb.NewStruct(map[string]any{"plan": "pro", "seats": nil})
+	require.NoError(t, err)
+	privateMetadataPatch, err := structpb.NewStruct(map[string]any{"stripe": map[string]any{"subscription": "sub_456"}})
+	require.NoError(t, err)
+
+	updateResp, err := u.Store.UpdateUser(ctx, &backendv1.UpdateUserRequest{
+		Id: createResp.User.Id,
+		User: &backendv1.User{
+			PublicMetadata:  publicMetadataPatch,
+			PrivateMetadata: privateMetadataPatch,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"plan": "pro"}, updateResp.User.PublicMetadata.AsMap())
+	require.Equal(t, map[string]any{
+		"stripe": map[string]any{"customer": "cus_123", "subscription": "sub_456"},
+	}, updateResp.User.PrivateMetadata.AsMap())
+
+	// updates that leave metadata unset keep the existing metadata
+	updateResp, err = u.Store.UpdateUser(ctx, &backendv1.UpdateUserRequest{
+		Id:   createResp.User.Id,
+		User: &backendv1.User{DisplayName: refOrNil("Test")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"plan": "pro"}, updateResp.User.PublicMetadata.AsMap())
+}
+
+func TestUpdateUser_PublicMetadataTooLarge(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "test@example.com",
+	})
+
+	publicMetadata, err := structpb.NewStruct(map[string]any{"blob": strings.Repeat("x", maxPublicMetadataSize)})
+	require.NoError(t, err)
+
+	_, err = u.Store.UpdateUser(ctx, &backendv1.UpdateUserRequest{
+		Id:   userID,
+		User: &backendv1.User{PublicMetadata: publicMetadata},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
 func TestDeleteUser(t *testing.T) {
 	t.Parallel()
 
@@ -166,6 +301,55 @@ func TestListUsers_ReturnsAll(t *testing.T) {
 	require.ElementsMatch(t, ids, respIds)
 }
 
+func TestListUsers_FilterMetadata(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+	orgID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{
+		DisplayName: "test",
+	})
+
+	var ids []string
+	for i, externalID := range []string{"ext_1", "ext_2"} {
+		privateMetadata, err := structpb.NewStruct(map[string]any{"external_id": externalID})
+		require.NoError(t, err)
+
+		resp, err := u.Store.CreateUser(ctx, &backendv1.CreateUserRequest{
+			User: &backendv1.User{
+				OrganizationId:  orgID,
+				Email:           fmt.Sprintf("user%d@example.com", i),
+				PrivateMetadata: privateMetadata,
+			},
+		})
+		require.NoError(t, err)
+		ids = append(ids, resp.User.Id)
+	}
+	u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "no-metadata@example.com",
+	})
+
+	listResp, err := u.Store.ListUsers(ctx, &backendv1.ListUsersRequest{
+		OrganizationId:    orgID,
+		FilterMetadataKey: "external_id",
+	})
+	require.NoError(t, err)
+
+	var respIDs []string
+	for _, user := range listResp.Users {
+		respIDs = append(respIDs, user.Id)
+	}
+	require.ElementsMatch(t, ids, respIDs)
+
+	listResp, err = u.Store.ListUsers(ctx, &backendv1.ListUsersRequest{
+		OrganizationId:      orgID,
+		FilterMetadataKey:   "external_id",
+		FilterMetadataValue: structpb.NewStringValue("ext_2"),
+	})
+	require.NoError(t, err)
+	require.Len(t, listResp.Users, 1)
+	require.Equal(t, ids[1], listResp.Users[0].Id)
+}
+
 func TestListUsers_Pagination(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,411 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/authn"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Store) ListWebhookEndpoints(ctx context.Context, req *backendv1.ListWebhookEndpointsRequest) (*backendv1.ListWebhookEndpointsResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	var startID uuid.UUID
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, err
+	}
+
+	limit := 10
+	qWebhookEndpoints, err := q.ListWebhookEndpoints(ctx, queries.ListWebhookEndpointsParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        startID,
+		Limit:     int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list webhook endpoints: %w", err)
+	}
+
+	var webhookEndpoints []*backendv1.WebhookEndpoint
+	for _, qWebhookEndpoint := range qWebhookEndpoints {
+		webhookEndpoints = append(webhookEndpoints, parseWebhookEndpoint(qWebhookEndpoint))
+	}
+
+	var nextPageToken string
+	if len(webhookEndpoints) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qWebhookEndpoints[limit].ID)
+		webhookEndpoints = webhookEndpoints[:limit]
+	}
+
+	return &backendv1.ListWebhookEndpointsResponse{
+		WebhookEndpoints: webhookEndpoints,
+		NextPageToken:    nextPageToken,
+	}, nil
+}
+
+func (s *Store) GetWebhookEndpoint(ctx context.Context, req *backendv1.GetWebhookEndpointRequest) (*backendv1.GetWebhookEndpointResponse, error) {
+	webhookEndpointID, err := idformat.WebhookEndpoint.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid webhook endpoint id", fmt.Errorf("parse webhook endpoint id: %w", err))
+	}
+
+	qWebhookEndpoint, err := s.q.GetWebhookEndpoint(ctx, queries.GetWebhookEndpointParams{
+		ID:        webhookEndpointID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("webhook endpoint not found", fmt.Errorf("get webhook endpoint: %w", err))
+		}
+
+		return nil, fmt.Errorf("get webhook endpoint: %w", err)
+	}
+
+	return &backendv1.GetWebhookEndpointResponse{WebhookEndpoint: parseWebhookEndpoint(qWebhookEndpoint)}, nil
+}
+
+func (s *Store) CreateWebhookEndpoint(ctx context.Context, req *backendv1.CreateWebhookEndpointRequest) (*backendv1.CreateWebhookEndpointResponse, error) {
+	if err := validateWebhookEndpointURL(req.WebhookEndpoint.Url); err != nil {
+		return nil, err
+	}
+
+	signingSecret, err := webhooks.NewSigningSecret()
+	if err != nil {
+		return nil, fmt.Errorf("generate webhook signing secret: %w", err)
+	}
+
+	encryptRes, err := s.kms.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:               &s.webhookSigningSecretsKMSKeyID,
+		EncryptionAlgorithm: types.EncryptionAlgorithmSpecRsaesOaepSha256,
+		Plaintext:           []byte(signingSecret),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encrypt webhook signing secret: %w", err)
+	}
+
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qWebhookEndpoint, err := q.CreateWebhookEndpoint(ctx, queries.CreateWebhookEndpointParams{
+		ID:                      uuid.New(),
+		ProjectID:               authn.ProjectID(ctx),
+		Url:                     req.WebhookEndpoint.Url,
+		Description:             refOrNil(req.WebhookEndpoint.Description),
+		EventTypes:              normalizeWebhookEventTypes(req.WebhookEndpoint.EventTypes),
+		Disabled:                derefOrEmpty(req.WebhookEndpoint.Disabled),
+		SigningSecretCiphertext: encryptRes.CiphertextBlob,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create webhook endpoint: %w", err)
+	}
+
+	auditWebhookEndpoint, err := s.auditlogStore.GetWebhookEndpoint(ctx, tx, qWebhookEndpoint.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit webhook endpoint: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+		EventName: "tesseral.webhook_endpoints.create",
+		EventDetails: &auditlogv1.CreateWebhookEndpoint{
+			WebhookEndpoint: auditWebhookEndpoint,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeWebhookEndpoint,
+		ResourceID:   &qWebhookEndpoint.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	webhookEndpoint := parseWebhookEndpoint(qWebhookEndpoint)
+	webhookEndpoint.SigningSecret = signingSecret
+	return &backendv1.CreateWebhookEndpointResponse{WebhookEndpoint: webhookEndpoint}, nil
+}
+
+func (s *Store) UpdateWebhookEndpoint(ctx context.Context, req *backendv1.UpdateWebhookEndpointRequest) (*backendv1.UpdateWebhookEndpointResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	webhookEndpointID, err := idformat.WebhookEndpoint.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid webhook endpoint id", fmt.Errorf("parse webhook endpoint id: %w", err))
+	}
+
+	qWebhookEndpoint, err := q.GetWebhookEndpoint(ctx, queries.GetWebhookEndpointParams{
+		ID:        webhookEndpointID,
+		ProjectID: authn.ProjectID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("webhook endpoint not found", fmt.Errorf("get webhook endpoint: %w", err))
+		}
+
+		return nil, fmt.Errorf("get webhook endpoint: %w", err)
+	}
+
+	auditPreviousWebhookEndpoint, err := s.auditlogStore.GetWebhookEndpoint(ctx, tx, qWebhookEndpoint.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit previous webhook endpoint: %w", err)
+	}
+
+	updates := queries.UpdateWebhookEndpointParams{
+		ID:          webhookEndpointID,
+		Url:         qWebhookEndpoint.Url,
+		Description: qWebhookEndpoint.Description,
+		EventTypes:  qWebhookEndpoint.EventTypes,
+		Disabled:    qWebhookEndpoint.Disabled,
+	}
+
+	if req.WebhookEndpoint.Url != "" {
+		if err := validateWebhookEndpointURL(req.WebhookEndpoint.Url); err != nil {
+			return nil, err
+		}
+		updates.Url = req.WebhookEndpoint.Url
+	}
+
+	if req.WebhookEndpoint.Description != "" {
+		updates.Description = &req.WebhookEndpoint.Description
+	}
+
+	if len(req.WebhookEndpoint.EventTypes) > 0 {
+		updates.EventTypes = normalizeWebhookEventTypes(req.WebhookEndpoint.EventTypes)
+	}
+
+	if req.WebhookEndpoint.Disabled != nil {
+		updates.Disabled = *req.WebhookEndpoint.Disabled
+	}
+
+	qUpdatedWebhookEndpoint, err := q.UpdateWebhookEndpoint(ctx, updates)
+	if err != nil {
+		return nil, fmt.Errorf("update webhook endpoint: %w", err)
+	}
+
+	auditWebhookEndpoint, err := s.auditlogStore.GetWebhookEndpoint(ctx, tx, qUpdatedWebhookEndpoint.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit webhook endpoint: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+		EventName: "tesseral.webhook_endpoints.update",
+		EventDetails: &auditlogv1.UpdateWebhookEndpoint{
+			WebhookEndpoint:         auditWebhookEndpoint,
+			PreviousWebhookEndpoint: auditPreviousWebhookEndpoint,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeWebhookEndpoint,
+		ResourceID:   &qUpdatedWebhookEndpoint.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.UpdateWebhookEndpointResponse{WebhookEndpoint: parseWebhookEndpoint(qUpdatedWebhookEndpoint)}, nil
+}
+
+func (s *Store) DeleteWebhookEndpoint(ctx context.Context, req *backendv1.DeleteWebhookEndpointRequest) (*backendv1.DeleteWebhookEndpointResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	webhookEndpointID, err := idformat.WebhookEndpoint.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid webhook endpoint id", fmt.Errorf("parse webhook endpoint id: %w", err))
+	}
+
+	if _, err := q.GetWebhookEndpoint(ctx, queries.GetWebhookEndpointParams{
+		ID:        webhookEndpointID,
+		ProjectID: authn.ProjectID(ctx),
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("webhook endpoint not found", fmt.Errorf("get webhook endpoint: %w", err))
+		}
+
+		return nil, fmt.Errorf("get webhook endpoint: %w", err)
+	}
+
+	auditWebhookEndpoint, err := s.auditlogStore.GetWebhookEndpoint(ctx, tx, webhookEndpointID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit webhook endpoint: %w", err)
+	}
+
+	if err := q.DeleteWebhookEndpoint(ctx, webhookEndpointID); err != nil {
+		return nil, fmt.Errorf("delete webhook endpoint: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+		EventName: "tesseral.webhook_endpoints.delete",
+		EventDetails: &auditlogv1.DeleteWebhookEndpoint{
+			WebhookEndpoint: auditWebhookEndpoint,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeWebhookEndpoint,
+		ResourceID:   (*uuid.UUID)(&webhookEndpointID),
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &backendv1.DeleteWebhookEndpointResponse{}, nil
+}
+
+func (s *Store) ListWebhookDeliveries(ctx context.Context, req *backendv1.ListWebhookDeliveriesRequest) (*backendv1.ListWebhookDeliveriesResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	webhookEndpointID, err := idformat.WebhookEndpoint.Parse(req.WebhookEndpointId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid webhook endpoint id", fmt.Errorf("parse webhook endpoint id: %w", err))
+	}
+
+	// authz check
+	if _, err := q.GetWebhookEndpoint(ctx, queries.GetWebhookEndpointParams{
+		ID:        webhookEndpointID,
+		ProjectID: authn.ProjectID(ctx),
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("webhook endpoint not found", fmt.Errorf("get webhook endpoint: %w", err))
+		}
+
+		return nil, fmt.Errorf("get webhook endpoint: %w", err)
+	}
+
+	// Webhook delivery IDs are uuidv7s, so paginating high-to-low returns the
+	// most recent deliveries first.
+	startID := uuid.Max
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	qWebhookDeliveries, err := q.ListWebhookDeliveries(ctx, queries.ListWebhookDeliveriesParams{
+		WebhookEndpointID: webhookEndpointID,
+		ID:                startID,
+		Limit:             int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+
+	var webhookDeliveries []*backendv1.WebhookDelivery
+	for _, qWebhookDelivery := range qWebhookDeliveries {
+		webhookDeliveries = append(webhookDeliveries, parseWebhookDelivery(qWebhookDelivery))
+	}
+
+	var nextPageToken string
+	if len(webhookDeliveries) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qWebhookDeliveries[limit].ID)
+		webhookDeliveries = webhookDeliveries[:limit]
+	}
+
+	return &backendv1.ListWebhookDeliveriesResponse{
+		WebhookDeliveries: webhookDeliveries,
+		NextPageToken:     nextPageToken,
+	}, nil
+}
+
+func validateWebhookEndpointURL(webhookEndpointURL string) error {
+	u, err := url.Parse(webhookEndpointURL)
+	if err != nil {
+		return apierror.NewInvalidArgumentError("invalid webhook endpoint url", fmt.Errorf("invalid webhook endpoint url: %w", err))
+	}
+
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return apierror.NewInvalidArgumentError("webhook endpoint url must be http or https", fmt.Errorf("webhook endpoint url must be http or https"))
+	}
+
+	if u.Host == "" {
+		return apierror.NewInvalidArgumentError("webhook endpoint url must be absolute", fmt.Errorf("webhook endpoint url must be absolute"))
+	}
+
+	return nil
+}
+
+// normalizeWebhookEventTypes converts the "all event types" wildcard into the
+// empty list stored in the database.
+func normalizeWebhookEventTypes(eventTypes []string) []string {
+	if slices.Contains(eventTypes, "*") {
+		return []string{}
+	}
+	if eventTypes == nil {
+		return []string{}
+	}
+	return eventTypes
+}
+
+func parseWebhookEndpoint(qWebhookEndpoint queries.WebhookEndpoint) *backendv1.WebhookEndpoint {
+	return &backendv1.WebhookEndpoint{
+		Id:            idformat.WebhookEndpoint.Format(qWebhookEndpoint.ID),
+		CreateTime:    timestamppb.New(*qWebhookEndpoint.CreateTime),
+		UpdateTime:    timestamppb.New(*qWebhookEndpoint.UpdateTime),
+		Url:           qWebhookEndpoint.Url,
+		Description:   derefOrEmpty(qWebhookEndpoint.Description),
+		EventTypes:    qWebhookEndpoint.EventTypes,
+		Disabled:      &qWebhookEndpoint.Disabled,
+		SigningSecret: "", // intentionally left blank
+	}
+}
+
+func parseWebhookDelivery(qWebhookDelivery queries.ListWebhookDeliveriesRow) *backendv1.WebhookDelivery {
+	var status backendv1.WebhookDeliveryStatus
+	switch qWebhookDelivery.Status {
+	case queries.WebhookDeliveryStatusPending:
+		status = backendv1.WebhookDeliveryStatus_WEBHOOK_DELIVERY_STATUS_PENDING
+	case queries.WebhookDeliveryStatusSucceeded:
+		status = backendv1.WebhookDeliveryStatus_WEBHOOK_DELIVERY_STATUS_SUCCEEDED
+	case queries.WebhookDeliveryStatusFailed:
+		status = backendv1.WebhookDeliveryStatus_WEBHOOK_DELIVERY_STATUS_FAILED
+	}
+
+	var nextAttemptTime *timestamppb.Timestamp
+	if qWebhookDelivery.Status == queries.WebhookDeliveryStatusPending {
+		nextAttemptTime = timestampOrNil(qWebhookDelivery.NextAttemptTime)
+	}
+
+	return &backendv1.WebhookDelivery{
+		Id:                     idformat.WebhookDelivery.Format(qWebhookDelivery.ID),
+		WebhookEndpointId:      idformat.WebhookEndpoint.Format(qWebhookDelivery.WebhookEndpointID),
+		WebhookMessageId:       idformat.WebhookMessage.Format(qWebhookDelivery.WebhookMessageID),
+		EventType:              qWebhookDelivery.EventType,
+		Status:                 status,
+		AttemptCount:           qWebhookDelivery.AttemptCount,
+		CreateTime:             timestamppb.New(*qWebhookDelivery.CreateTime),
+		NextAttemptTime:        nextAttemptTime,
+		LastAttemptTime:        timestampOrNil(qWebhookDelivery.LastAttemptTime),
+		LastResponseStatusCode: derefOrEmpty(qWebhookDelivery.LastResponseStatusCode),
+		LastError:              derefOrEmpty(qWebhookDelivery.LastError),
+	}
+}
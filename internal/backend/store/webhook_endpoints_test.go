@@ -0,0 +1,241 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func TestCreateWebhookEndpoint(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	res, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url:         "https://example.com/webhooks",
+			Description: "test",
+			EventTypes:  []string{"sync.user"},
+		},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.WebhookEndpoint.Id)
+	require.Equal(t, "https://example.com/webhooks", res.WebhookEndpoint.Url)
+	require.Equal(t, "test", res.WebhookEndpoint.Description)
+	require.Equal(t, []string{"sync.user"}, res.WebhookEndpoint.EventTypes)
+	require.False(t, res.WebhookEndpoint.GetDisabled())
+	require.Contains(t, res.WebhookEndpoint.SigningSecret, "whsec_")
+	require.NotEmpty(t, res.WebhookEndpoint.CreateTime)
+	require.NotEmpty(t, res.WebhookEndpoint.UpdateTime)
+
+	getRes, err := u.Store.GetWebhookEndpoint(ctx, &backendv1.GetWebhookEndpointRequest{Id: res.WebhookEndpoint.Id})
+	require.NoError(t, err)
+	require.Empty(t, getRes.WebhookEndpoint.SigningSecret)
+}
+
+func TestCreateWebhookEndpoint_InvalidURL(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url: "ftp://example.com/webhooks",
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
+
+func TestCreateWebhookEndpoint_AllEventTypes(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	res, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url:        "https://example.com/webhooks",
+			EventTypes: []string{"*"},
+		},
+	})
+	require.NoError(t, err)
+	require.Empty(t, res.WebhookEndpoint.EventTypes)
+}
+
+func TestGetWebhookEndpoint_DoesNotExist(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	_, err := u.Store.GetWebhookEndpoint(ctx, &backendv1.GetWebhookEndpointRequest{
+		Id: idformat.WebhookEndpoint.Format(uuid.New()),
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
+
+func TestGetWebhookEndpoint_OtherProject(t *testing.T) {
+	t.Parallel()
+
+	ctx1, u1 := newTestUtil(t)
+	ctx2, u2 := newTestUtil(t)
+
+	res, err := u1.Store.CreateWebhookEndpoint(ctx1, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url: "https://example.com/webhooks",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u2.Store.GetWebhookEndpoint(ctx2, &backendv1.GetWebhookEndpointRequest{Id: res.WebhookEndpoint.Id})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
+
+func TestListWebhookEndpoints(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	var ids []string
+	for range 3 {
+		res, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+			WebhookEndpoint: &backendv1.WebhookEndpoint{
+				Url: "https://example.com/webhooks",
+			},
+		})
+		require.NoError(t, err)
+		ids = append(ids, res.WebhookEndpoint.Id)
+	}
+
+	res, err := u.Store.ListWebhookEndpoints(ctx, &backendv1.ListWebhookEndpointsRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.WebhookEndpoints, 3)
+	require.Empty(t, res.NextPageToken)
+
+	var gotIDs []string
+	for _, webhookEndpoint := range res.WebhookEndpoints {
+		gotIDs = append(gotIDs, webhookEndpoint.Id)
+	}
+	require.ElementsMatch(t, ids, gotIDs)
+}
+
+func TestUpdateWebhookEndpoint(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url:         "https://example.com/webhooks",
+			Description: "test",
+		},
+	})
+	require.NoError(t, err)
+
+	res, err := u.Store.UpdateWebhookEndpoint(ctx, &backendv1.UpdateWebhookEndpointRequest{
+		Id: createRes.WebhookEndpoint.Id,
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url:        "https://example.com/webhooks/v2",
+			EventTypes: []string{"sync.organization"},
+			Disabled:   refOrNil(true),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/webhooks/v2", res.WebhookEndpoint.Url)
+	require.Equal(t, "test", res.WebhookEndpoint.Description)
+	require.Equal(t, []string{"sync.organization"}, res.WebhookEndpoint.EventTypes)
+	require.True(t, res.WebhookEndpoint.GetDisabled())
+}
+
+func TestDeleteWebhookEndpoint(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url: "https://example.com/webhooks",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.DeleteWebhookEndpoint(ctx, &backendv1.DeleteWebhookEndpointRequest{Id: createRes.WebhookEndpoint.Id})
+	require.NoError(t, err)
+
+	_, err = u.Store.GetWebhookEndpoint(ctx, &backendv1.GetWebhookEndpointRequest{Id: createRes.WebhookEndpoint.Id})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeNotFound, connectErr.Code())
+}
+
+func TestWebhookEndpoint_AuditLogEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url: "https://example.com/webhooks",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.UpdateWebhookEndpoint(ctx, &backendv1.UpdateWebhookEndpointRequest{
+		Id: createRes.WebhookEndpoint.Id,
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url: "https://example.com/webhooks/v2",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = u.Store.DeleteWebhookEndpoint(ctx, &backendv1.DeleteWebhookEndpointRequest{Id: createRes.WebhookEndpoint.Id})
+	require.NoError(t, err)
+
+	listRes, err := u.Store.ConsoleListCustomAuditLogEvents(ctx, &backendv1.ConsoleListAuditLogEventsRequest{
+		ResourceType: backendv1.AuditLogEventResourceType_AUDIT_LOG_EVENT_RESOURCE_TYPE_WEBHOOK_ENDPOINT,
+		ResourceId:   createRes.WebhookEndpoint.Id,
+	})
+	require.NoError(t, err)
+
+	var eventNames []string
+	for _, event := range listRes.AuditLogEvents {
+		eventNames = append(eventNames, event.EventName)
+	}
+	require.Equal(t, []string{
+		"tesseral.webhook_endpoints.delete",
+		"tesseral.webhook_endpoints.update",
+		"tesseral.webhook_endpoints.create",
+	}, eventNames)
+
+	updateDetails := listRes.AuditLogEvents[1].EventDetails.AsMap()
+	require.Equal(t, "https://example.com/webhooks/v2", updateDetails["webhookEndpoint"].(map[string]any)["url"])
+	require.Equal(t, "https://example.com/webhooks", updateDetails["previousWebhookEndpoint"].(map[string]any)["url"])
+}
+
+func TestListWebhookDeliveries_Empty(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	createRes, err := u.Store.CreateWebhookEndpoint(ctx, &backendv1.CreateWebhookEndpointRequest{
+		WebhookEndpoint: &backendv1.WebhookEndpoint{
+			Url: "https://example.com/webhooks",
+		},
+	})
+	require.NoError(t, err)
+
+	res, err := u.Store.ListWebhookDeliveries(ctx, &backendv1.ListWebhookDeliveriesRequest{
+		WebhookEndpointId: createRes.WebhookEndpoint.Id,
+	})
+	require.NoError(t, err)
+	require.Empty(t, res.WebhookDeliveries)
+	require.Empty(t, res.NextPageToken)
+}
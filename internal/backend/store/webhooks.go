@@ -0,0 +1,43 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/backend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
+)
+
+// enqueueWebhookMessage writes a webhook message to the outbox. The webhook
+// relay sends it once q's transaction commits.
+func (s *Store) enqueueWebhookMessage(ctx context.Context, q *queries.Queries, projectID uuid.UUID, msg webhooks.Message) error {
+	if _, err := q.GetProjectWebhookSettings(ctx, projectID); err != nil {
+		// We want to ignore this error if the project does not have webhook settings
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("get project webhook settings: %w", err)
+	}
+
+	payload, err := json.Marshal(msg.Payload)
+	if err != nil {
+		return fmt.Errorf("marshal webhook payload: %w", err)
+	}
+
+	if _, err := q.CreateWebhookOutboxMessage(ctx, queries.CreateWebhookOutboxMessageParams{
+		ID:        uuidv7.NewWithTime(time.Now()),
+		ProjectID: projectID,
+		EventType: msg.EventType,
+		Payload:   payload,
+	}); err != nil {
+		return fmt.Errorf("create webhook outbox message: %w", err)
+	}
+
+	return nil
+}
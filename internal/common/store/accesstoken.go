@@ -22,6 +22,7 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 	"github.com/tesseral-labs/tesseral/internal/ujwt"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 const accessTokenDuration = time.Minute * 5
@@ -30,15 +31,17 @@ func (s *Store) IssueAccessToken(ctx context.Context, projectID uuid.UUID, refre
 	// this type exists to unify the datatypes we get from refresh tokens that
 	// belong to sessions vs relayed sessions
 	var qDetails struct {
-		SessionID               uuid.UUID
-		UserID                  uuid.UUID
-		OrganizationID          uuid.UUID
-		UserIsOwner             bool
-		UserEmail               string
-		UserDisplayName         *string
-		UserProfilePictureUrl   *string
-		OrganizationDisplayName string
-		ImpersonatorUserID      *uuid.UUID
+		SessionID                  uuid.UUID
+		UserID                     uuid.UUID
+		OrganizationID             uuid.UUID
+		UserIsOwner                bool
+		UserEmail                  string
+		UserDisplayName            *string
+		UserProfilePictureUrl      *string
+		OrganizationDisplayName    string
+		ImpersonatorUserID         *uuid.UUID
+		UserPublicMetadata         []byte
+		OrganizationPublicMetadata []byte
 	}
 
 	switch {
@@ -72,6 +75,8 @@ func (s *Store) IssueAccessToken(ctx context.Context, projectID uuid.UUID, refre
 		qDetails.UserProfilePictureUrl = qSessionDetails.UserProfilePictureUrl
 		qDetails.OrganizationDisplayName = qSessionDetails.OrganizationDisplayName
 		qDetails.ImpersonatorUserID = qSessionDetails.ImpersonatorUserID
+		qDetails.UserPublicMetadata = qSessionDetails.UserPublicMetadata
+		qDetails.OrganizationPublicMetadata = qSessionDetails.OrganizationPublicMetadata
 	case strings.HasPrefix(refreshToken, "tesseral_secret_relayed_session_refresh_token_"):
 		slog.InfoContext(ctx, "refresh_relayed_session_token")
 
@@ -102,6 +107,8 @@ func (s *Store) IssueAccessToken(ctx context.Context, projectID uuid.UUID, refre
 		qDetails.UserProfilePictureUrl = qSessionDetails.UserProfilePictureUrl
 		qDetails.OrganizationDisplayName = qSessionDetails.OrganizationDisplayName
 		qDetails.ImpersonatorUserID = qSessionDetails.ImpersonatorUserID
+		qDetails.UserPublicMetadata = qSessionDetails.UserPublicMetadata
+		qDetails.OrganizationPublicMetadata = qSessionDetails.OrganizationPublicMetadata
 	}
 
 	issAndAud := fmt.Sprintf("https://%s.tesseral.app", strings.ReplaceAll(idformat.Project.Format(projectID), "_", "-"))
@@ -147,6 +154,27 @@ func (s *Store) IssueAccessToken(ctx context.Context, projectID uuid.UUID, refre
 
 	slices.Sort(actions)
 
+	// GetOrganizationPath returns the organization first and its root last
+	organizationIDs, err := s.q.GetOrganizationPath(ctx, qDetails.OrganizationID)
+	if err != nil {
+		return "", fmt.Errorf("get organization path: %w", err)
+	}
+
+	var organizationPath []string
+	for _, organizationID := range slices.Backward(organizationIDs) {
+		organizationPath = append(organizationPath, idformat.Organization.Format(organizationID))
+	}
+
+	var userPublicMetadata structpb.Struct
+	if err := protojson.Unmarshal(qDetails.UserPublicMetadata, &userPublicMetadata); err != nil {
+		return "", fmt.Errorf("unmarshal user public metadata: %w", err)
+	}
+
+	var organizationPublicMetadata structpb.Struct
+	if err := protojson.Unmarshal(qDetails.OrganizationPublicMetadata, &organizationPublicMetadata); err != nil {
+		return "", fmt.Errorf("unmarshal organization public metadata: %w", err)
+	}
+
 	claims := &commonv1.AccessTokenData{
 		Iss: issAndAud,
 		Sub: idformat.User.Format(qDetails.UserID),
@@ -162,10 +190,13 @@ func (s *Store) IssueAccessToken(ctx context.Context, projectID uuid.UUID, refre
 			Email:             qDetails.UserEmail,
 			DisplayName:       derefOrEmpty(qDetails.UserDisplayName),
 			ProfilePictureUrl: derefOrEmpty(qDetails.UserProfilePictureUrl),
+			PublicMetadata:    &userPublicMetadata,
 		},
 		Organization: &commonv1.AccessTokenOrganization{
-			Id:          idformat.Organization.Format(qDetails.OrganizationID),
-			DisplayName: qDetails.OrganizationDisplayName,
+			Id:             idformat.Organization.Format(qDetails.OrganizationID),
+			DisplayName:    qDetails.OrganizationDisplayName,
+			Path:           organizationPath,
+			PublicMetadata: &organizationPublicMetadata,
 		},
 		Actions:      actions,
 		Impersonator: impersonator,
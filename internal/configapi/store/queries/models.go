@@ -13,19 +13,64 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AccessRequestStatus string
+
+const (
+	AccessRequestStatusPending  AccessRequestStatus = "pending"
+	AccessRequestStatusApproved AccessRequestStatus = "approved"
+	AccessRequestStatusDenied   AccessRequestStatus = "denied"
+)
+
+func (e *AccessRequestStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AccessRequestStatus(s)
+	case string:
+		*e = AccessRequestStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AccessRequestStatus: %T", src)
+	}
+	return nil
+}
+
+type NullAccessRequestStatus struct {
+	AccessRequestStatus AccessRequestStatus
+	Valid               bool // Valid is true if AccessRequestStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAccessRequestStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.AccessRequestStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AccessRequestStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAccessRequestStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AccessRequestStatus), nil
+}
+
 type AuditLogEventResourceType string
 
 const (
-	AuditLogEventResourceTypeApiKey         AuditLogEventResourceType = "api_key"
-	AuditLogEventResourceTypeOrganization   AuditLogEventResourceType = "organization"
-	AuditLogEventResourceTypePasskey        AuditLogEventResourceType = "passkey"
-	AuditLogEventResourceTypeRole           AuditLogEventResourceType = "role"
-	AuditLogEventResourceTypeSamlConnection AuditLogEventResourceType = "saml_connection"
-	AuditLogEventResourceTypeScimApiKey     AuditLogEventResourceType = "scim_api_key"
-	AuditLogEventResourceTypeSession        AuditLogEventResourceType = "session"
-	AuditLogEventResourceTypeUser           AuditLogEventResourceType = "user"
-	AuditLogEventResourceTypeUserInvite     AuditLogEventResourceType = "user_invite"
-	AuditLogEventResourceTypeOidcConnection AuditLogEventResourceType = "oidc_connection"
+	AuditLogEventResourceTypeApiKey          AuditLogEventResourceType = "api_key"
+	AuditLogEventResourceTypeOrganization    AuditLogEventResourceType = "organization"
+	AuditLogEventResourceTypePasskey         AuditLogEventResourceType = "passkey"
+	AuditLogEventResourceTypeRole            AuditLogEventResourceType = "role"
+	AuditLogEventResourceTypeSamlConnection  AuditLogEventResourceType = "saml_connection"
+	AuditLogEventResourceTypeScimApiKey      AuditLogEventResourceType = "scim_api_key"
+	AuditLogEventResourceTypeSession         AuditLogEventResourceType = "session"
+	AuditLogEventResourceTypeUser            AuditLogEventResourceType = "user"
+	AuditLogEventResourceTypeUserInvite      AuditLogEventResourceType = "user_invite"
+	AuditLogEventResourceTypeOidcConnection  AuditLogEventResourceType = "oidc_connection"
+	AuditLogEventResourceTypeRoleTemplate    AuditLogEventResourceType = "role_template"
+	AuditLogEventResourceTypeWebhookEndpoint AuditLogEventResourceType = "webhook_endpoint"
 )
 
 func (e *AuditLogEventResourceType) Scan(src interface{}) error {
@@ -63,6 +108,49 @@ func (ns NullAuditLogEventResourceType) Value() (driver.Value, error) {
 	return string(ns.AuditLogEventResourceType), nil
 }
 
+type AuditLogExportDestinationType string
+
+const (
+	AuditLogExportDestinationTypeHttps  AuditLogExportDestinationType = "https"
+	AuditLogExportDestinationTypeS3     AuditLogExportDestinationType = "s3"
+	AuditLogExportDestinationTypeSyslog AuditLogExportDestinationType = "syslog"
+)
+
+func (e *AuditLogExportDestinationType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AuditLogExportDestinationType(s)
+	case string:
+		*e = AuditLogExportDestinationType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AuditLogExportDestinationType: %T", src)
+	}
+	return nil
+}
+
+type NullAuditLogExportDestinationType struct {
+	AuditLogExportDestinationType AuditLogExportDestinationType
+	Valid                         bool // Valid is true if AuditLogExportDestinationType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAuditLogExportDestinationType) Scan(value interface{}) error {
+	if value == nil {
+		ns.AuditLogExportDestinationType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AuditLogExportDestinationType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAuditLogExportDestinationType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AuditLogExportDestinationType), nil
+}
+
 type AuthMethod string
 
 const (
@@ -106,6 +194,49 @@ func (ns NullAuthMethod) Value() (driver.Value, error) {
 	return string(ns.AuthMethod), nil
 }
 
+type EmailTemplateType string
+
+const (
+	EmailTemplateTypeEmailVerification EmailTemplateType = "email_verification"
+	EmailTemplateTypePasswordReset     EmailTemplateType = "password_reset"
+	EmailTemplateTypeUserInvite        EmailTemplateType = "user_invite"
+)
+
+func (e *EmailTemplateType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EmailTemplateType(s)
+	case string:
+		*e = EmailTemplateType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EmailTemplateType: %T", src)
+	}
+	return nil
+}
+
+type NullEmailTemplateType struct {
+	EmailTemplateType EmailTemplateType
+	Valid             bool // Valid is true if EmailTemplateType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEmailTemplateType) Scan(value interface{}) error {
+	if value == nil {
+		ns.EmailTemplateType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EmailTemplateType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEmailTemplateType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EmailTemplateType), nil
+}
+
 type LogInLayout string
 
 const (
@@ -196,6 +327,62 @@ func (ns NullPrimaryAuthFactor) Value() (driver.Value, error) {
 	return string(ns.PrimaryAuthFactor), nil
 }
 
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+func (e *WebhookDeliveryStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WebhookDeliveryStatus(s)
+	case string:
+		*e = WebhookDeliveryStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WebhookDeliveryStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWebhookDeliveryStatus struct {
+	WebhookDeliveryStatus WebhookDeliveryStatus
+	Valid                 bool // Valid is true if WebhookDeliveryStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWebhookDeliveryStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WebhookDeliveryStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WebhookDeliveryStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWebhookDeliveryStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WebhookDeliveryStatus), nil
+}
+
+type AccessRequest struct {
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	RoleID          uuid.UUID
+	Reason          string
+	DurationSeconds int32
+	Status          AccessRequestStatus
+	ReviewerUserID  *uuid.UUID
+	ReviewTime      *time.Time
+	CreateTime      *time.Time
+	UpdateTime      *time.Time
+}
+
 type Action struct {
 	ID          uuid.UUID
 	ProjectID   uuid.UUID
@@ -204,14 +391,28 @@ type Action struct {
 }
 
 type ApiKey struct {
-	ID                uuid.UUID
-	OrganizationID    uuid.UUID
-	DisplayName       string
-	SecretTokenSha256 []byte
-	SecretTokenSuffix *string
-	ExpireTime        *time.Time
-	CreateTime        *time.Time
-	UpdateTime        *time.Time
+	ID                            uuid.UUID
+	OrganizationID                uuid.UUID
+	DisplayName                   string
+	SecretTokenSha256             []byte
+	SecretTokenSuffix             *string
+	ExpireTime                    *time.Time
+	CreateTime                    *time.Time
+	UpdateTime                    *time.Time
+	LastUsedTime                  *time.Time
+	LastUsedIpAddress             *string
+	IpAllowlist                   []string
+	RequestQuota                  *int32
+	RequestQuotaWindowSeconds     *int32
+	PreviousSecretTokenSha256     []byte
+	PreviousSecretTokenSuffix     *string
+	PreviousSecretTokenExpireTime *time.Time
+}
+
+type ApiKeyRequestQuotaUsage struct {
+	ApiKeyID     uuid.UUID
+	WindowStart  *time.Time
+	RequestCount int32
 }
 
 type ApiKeyRoleAssignment struct {
@@ -219,25 +420,82 @@ type ApiKeyRoleAssignment struct {
 	ApiKeyID   uuid.UUID
 	RoleID     uuid.UUID
 	CreateTime *time.Time
+	ExpireTime *time.Time
+}
+
+type AuditLogArchive struct {
+	ID             uuid.UUID
+	ProjectID      uuid.UUID
+	OrganizationID *uuid.UUID
+	S3Bucket       string
+	S3Key          string
+	EventCount     int32
+	FirstEventTime *time.Time
+	LastEventTime  *time.Time
+	CreateTime     *time.Time
+	RestoreTime    *time.Time
+	S3RoleArn      *string
+}
+
+type AuditLogArchivedChainLink struct {
+	ProjectID         uuid.UUID
+	ChainSequence     int64
+	ChainHash         []byte
+	AuditLogArchiveID uuid.UUID
+}
+
+type AuditLogChainHead struct {
+	ProjectID     uuid.UUID
+	Sequence      int64
+	Hash          []byte
+	UpdateTime    *time.Time
+	KeyCiphertext []byte
 }
 
 type AuditLogEvent struct {
-	ID                         uuid.UUID
-	ProjectID                  uuid.UUID
-	OrganizationID             *uuid.UUID
-	ActorUserID                *uuid.UUID
-	ActorSessionID             *uuid.UUID
-	ActorApiKeyID              *uuid.UUID
-	ActorConsoleUserID         *uuid.UUID
-	ActorConsoleSessionID      *uuid.UUID
-	ActorBackendApiKeyID       *uuid.UUID
-	ActorIntermediateSessionID *uuid.UUID
-	ResourceType               *AuditLogEventResourceType
-	ResourceID                 *uuid.UUID
-	EventName                  string
-	EventTime                  *time.Time
-	EventDetails               []byte
-	ActorScimApiKeyID          *uuid.UUID
+	ID                            uuid.UUID
+	ProjectID                     uuid.UUID
+	OrganizationID                *uuid.UUID
+	ActorUserID                   *uuid.UUID
+	ActorSessionID                *uuid.UUID
+	ActorApiKeyID                 *uuid.UUID
+	ActorConsoleUserID            *uuid.UUID
+	ActorConsoleSessionID         *uuid.UUID
+	ActorBackendApiKeyID          *uuid.UUID
+	ActorIntermediateSessionID    *uuid.UUID
+	ResourceType                  *AuditLogEventResourceType
+	ResourceID                    *uuid.UUID
+	EventName                     string
+	EventTime                     *time.Time
+	EventDetails                  []byte
+	ActorScimApiKeyID             *uuid.UUID
+	ChainSequence                 *int64
+	ChainHash                     []byte
+	RestoredFromAuditLogArchiveID *uuid.UUID
+}
+
+type AuditLogExportDestination struct {
+	ID                      uuid.UUID
+	ProjectID               uuid.UUID
+	OrganizationID          *uuid.UUID
+	DisplayName             string
+	Type                    AuditLogExportDestinationType
+	Disabled                bool
+	HttpsUrl                *string
+	SigningSecretCiphertext []byte
+	S3Bucket                *string
+	S3Prefix                *string
+	SyslogNetwork           *string
+	SyslogAddress           *string
+	CursorAuditLogEventID   *uuid.UUID
+	NextAttemptTime         *time.Time
+	LastAttemptTime         *time.Time
+	LastSuccessTime         *time.Time
+	LastError               *string
+	ConsecutiveFailureCount int32
+	CreateTime              *time.Time
+	UpdateTime              *time.Time
+	S3RoleArn               *string
 }
 
 type BackendApiKey struct {
@@ -247,6 +505,37 @@ type BackendApiKey struct {
 	DisplayName       string
 	CreateTime        *time.Time
 	UpdateTime        *time.Time
+	Scopes            []string
+	OrganizationIds   []uuid.UUID
+}
+
+type EmailTemplate struct {
+	ID           uuid.UUID
+	ProjectID    uuid.UUID
+	TemplateType EmailTemplateType
+	Locale       *string
+	Subject      string
+	TextBody     string
+	HtmlBody     string
+	CreateTime   *time.Time
+	UpdateTime   *time.Time
+}
+
+type Identity struct {
+	ID                                  uuid.UUID
+	ProjectID                           uuid.UUID
+	Email                               string
+	CreateTime                          *time.Time
+	UpdateTime                          *time.Time
+	PasswordBcrypt                      *string
+	FailedPasswordAttempts              int32
+	PasswordLockoutExpireTime           *time.Time
+	AuthenticatorAppSecretCiphertext    []byte
+	FailedAuthenticatorAppAttempts      int32
+	AuthenticatorAppLockoutExpireTime   *time.Time
+	AuthenticatorAppRecoveryCodeSha256s [][]byte
+	ImportedPasswordHash                *string
+	EmailVerified                       bool
 }
 
 type IntermediateSession struct {
@@ -291,6 +580,7 @@ type IntermediateSession struct {
 	OidcState                             *string
 	OidcCodeVerifier                      *string
 	VerifiedOidcConnectionID              *uuid.UUID
+	VerifiedIdentityID                    *uuid.UUID
 }
 
 type OauthVerifiedEmail struct {
@@ -334,12 +624,21 @@ type Organization struct {
 	LogInWithGithub           bool
 	ApiKeysEnabled            bool
 	LogInWithOidc             bool
+	AuditLogRetentionDays     *int32
+	ParentOrganizationID      *uuid.UUID
+	PublicMetadata            []byte
+	PrivateMetadata           []byte
+	EnforceSsoForDomains      bool
 }
 
 type OrganizationDomain struct {
 	ID             uuid.UUID
 	OrganizationID uuid.UUID
 	Domain         string
+	CreateTime     *time.Time
+	Verified       bool
+	VerifyTime     *time.Time
+	LastCheckTime  *time.Time
 }
 
 type OrganizationGoogleHostedDomain struct {
@@ -356,7 +655,7 @@ type OrganizationMicrosoftTenantID struct {
 
 type Passkey struct {
 	ID           uuid.UUID
-	UserID       uuid.UUID
+	UserID       *uuid.UUID
 	CreateTime   *time.Time
 	UpdateTime   *time.Time
 	CredentialID []byte
@@ -364,6 +663,7 @@ type Passkey struct {
 	Aaguid       string
 	Disabled     bool
 	RpID         string
+	IdentityID   uuid.UUID
 }
 
 type Project struct {
@@ -401,6 +701,10 @@ type Project struct {
 	ApiKeySecretTokenPrefix              *string
 	AuditLogsEnabled                     bool
 	LogInWithOidc                        bool
+	AuditLogRetentionDays                *int32
+	EntitledAuditLogRetentionDays        *int32
+	AuditLogArchiveS3Bucket              *string
+	AuditLogArchiveS3RoleArn             *string
 }
 
 type ProjectEmailQuotaDailyUsage struct {
@@ -455,13 +759,16 @@ type RelayedSession struct {
 }
 
 type Role struct {
-	ID             uuid.UUID
-	ProjectID      uuid.UUID
-	OrganizationID *uuid.UUID
-	CreateTime     *time.Time
-	UpdateTime     *time.Time
-	DisplayName    string
-	Description    string
+	ID                  uuid.UUID
+	ProjectID           uuid.UUID
+	OrganizationID      *uuid.UUID
+	CreateTime          *time.Time
+	UpdateTime          *time.Time
+	DisplayName         string
+	Description         string
+	Requestable         bool
+	RoleTemplateID      *uuid.UUID
+	RoleTemplateVersion *int32
 }
 
 type RoleAction struct {
@@ -470,6 +777,27 @@ type RoleAction struct {
 	ActionID uuid.UUID
 }
 
+type RoleInheritedRole struct {
+	RoleID          uuid.UUID
+	InheritedRoleID uuid.UUID
+}
+
+type RoleTemplate struct {
+	ID          uuid.UUID
+	ProjectID   uuid.UUID
+	CreateTime  *time.Time
+	UpdateTime  *time.Time
+	DisplayName string
+	Description string
+	Version     int32
+}
+
+type RoleTemplateAction struct {
+	ID             uuid.UUID
+	RoleTemplateID uuid.UUID
+	ActionID       uuid.UUID
+}
+
 type SamlConnection struct {
 	ID                 uuid.UUID
 	OrganizationID     uuid.UUID
@@ -511,24 +839,20 @@ type SessionSigningKey struct {
 }
 
 type User struct {
-	ID                                  uuid.UUID
-	OrganizationID                      uuid.UUID
-	PasswordBcrypt                      *string
-	GoogleUserID                        *string
-	MicrosoftUserID                     *string
-	Email                               string
-	CreateTime                          *time.Time
-	UpdateTime                          *time.Time
-	IsOwner                             bool
-	FailedPasswordAttempts              int32
-	PasswordLockoutExpireTime           *time.Time
-	AuthenticatorAppSecretCiphertext    []byte
-	FailedAuthenticatorAppAttempts      int32
-	AuthenticatorAppLockoutExpireTime   *time.Time
-	AuthenticatorAppRecoveryCodeSha256s [][]byte
-	DisplayName                         *string
-	ProfilePictureUrl                   *string
-	GithubUserID                        *string
+	ID                uuid.UUID
+	OrganizationID    uuid.UUID
+	GoogleUserID      *string
+	MicrosoftUserID   *string
+	Email             string
+	CreateTime        *time.Time
+	UpdateTime        *time.Time
+	IsOwner           bool
+	DisplayName       *string
+	ProfilePictureUrl *string
+	GithubUserID      *string
+	IdentityID        uuid.UUID
+	PublicMetadata    []byte
+	PrivateMetadata   []byte
 }
 
 type UserAuthenticatorAppChallenge struct {
@@ -556,12 +880,61 @@ type UserInvite struct {
 }
 
 type UserRoleAssignment struct {
-	ID     uuid.UUID
-	RoleID uuid.UUID
-	UserID uuid.UUID
+	ID                   uuid.UUID
+	RoleID               uuid.UUID
+	UserID               uuid.UUID
+	ResourceType         *string
+	ResourceID           *string
+	ExpireTime           *time.Time
+	InheritToDescendants bool
 }
 
 type VaultDomainSetting struct {
 	ProjectID     uuid.UUID
 	PendingDomain string
 }
+
+type WebhookDelivery struct {
+	ID                     uuid.UUID
+	WebhookMessageID       uuid.UUID
+	WebhookEndpointID      uuid.UUID
+	Status                 WebhookDeliveryStatus
+	AttemptCount           int32
+	NextAttemptTime        *time.Time
+	LastAttemptTime        *time.Time
+	LastResponseStatusCode *int32
+	LastError              *string
+	CreateTime             *time.Time
+	UpdateTime             *time.Time
+}
+
+type WebhookEndpoint struct {
+	ID                      uuid.UUID
+	ProjectID               uuid.UUID
+	Url                     string
+	Description             *string
+	EventTypes              []string
+	Disabled                bool
+	SigningSecretCiphertext []byte
+	CreateTime              *time.Time
+	UpdateTime              *time.Time
+}
+
+type WebhookMessage struct {
+	ID         uuid.UUID
+	ProjectID  uuid.UUID
+	EventType  string
+	Payload    []byte
+	CreateTime *time.Time
+}
+
+type WebhookOutboxMessage struct {
+	ID              uuid.UUID
+	ProjectID       uuid.UUID
+	EventType       string
+	Payload         []byte
+	AttemptCount    int32
+	NextAttemptTime *time.Time
+	LastError       *string
+	CreateTime      *time.Time
+}
@@ -0,0 +1,25 @@
+// Package emailsender sends transactional emails, such as password resets,
+// email verifications, and user invites.
+package emailsender
+
+import (
+	"context"
+)
+
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+
+	// TextBody is the plain-text body of the message. It is required.
+	TextBody string
+
+	// HTMLBody, if set, is sent alongside TextBody as an alternative
+	// representation of the message.
+	HTMLBody string
+}
+
+// Sender sends email messages.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,220 @@
+package emailsender
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMessage_Text(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeMessage(&buf, Message{
+		From:     "noreply@mail.example.com",
+		To:       []string{"alice@example.com"},
+		Subject:  "Acme - Réinitialiser le mot de passe",
+		TextBody: "Your code is 123456.\n",
+	}))
+
+	parsed, err := mail.ReadMessage(&buf)
+	require.NoError(t, err)
+	require.Equal(t, "noreply@mail.example.com", parsed.Header.Get("From"))
+	require.Equal(t, "alice@example.com", parsed.Header.Get("To"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	require.Equal(t, "Acme - Réinitialiser le mot de passe", subject)
+
+	body, err := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	require.NoError(t, err)
+	// Line breaks in email bodies are CRLF.
+	require.Equal(t, "Your code is 123456.\r\n", string(body))
+}
+
+func TestWriteMessage_HTML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeMessage(&buf, Message{
+		From:     "noreply@mail.example.com",
+		To:       []string{"alice@example.com", "bob@example.com"},
+		Subject:  "Hello",
+		TextBody: "Hello, world",
+		HTMLBody: "<p>Hello, world</p>",
+	}))
+
+	parsed, err := mail.ReadMessage(&buf)
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com, bob@example.com", parsed.Header.Get("To"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	var contentTypes, bodies []string
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		// multipart.Reader transparently decodes quoted-printable parts.
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+
+		contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+
+	require.Equal(t, []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}, contentTypes)
+	require.Equal(t, []string{"Hello, world", "<p>Hello, world</p>"}, bodies)
+}
+
+func TestDirectorySender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender := &DirectorySender{Dir: dir}
+
+	for _, subject := range []string{"first", "second"} {
+		require.NoError(t, sender.Send(t.Context(), Message{
+			From:     "noreply@mail.example.com",
+			To:       []string{"alice@example.com"},
+			Subject:  subject,
+			TextBody: "body",
+		}))
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	var subjects []string
+	for _, entry := range entries {
+		f, err := os.Open(filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+
+		parsed, err := mail.ReadMessage(f)
+		require.NoError(t, err)
+		subjects = append(subjects, parsed.Header.Get("Subject"))
+		require.NoError(t, f.Close())
+	}
+
+	require.Equal(t, []string{"first", "second"}, subjects)
+}
+
+func TestMemorySender(t *testing.T) {
+	sender := &MemorySender{}
+	msg := Message{
+		From:     "noreply@mail.example.com",
+		To:       []string{"alice@example.com"},
+		Subject:  "Hello",
+		TextBody: "body",
+	}
+
+	require.NoError(t, sender.Send(t.Context(), msg))
+	require.Equal(t, []Message{msg}, sender.Messages())
+}
+
+func TestSMTPSender(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		fmt.Fprint(conn, "220 localhost ESMTP\r\n")
+
+		var data strings.Builder
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					received <- data.String()
+					fmt.Fprint(conn, "250 OK\r\n")
+					continue
+				}
+				data.WriteString(line)
+				continue
+			}
+
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				fmt.Fprint(conn, "250 localhost\r\n")
+			case strings.HasPrefix(line, "DATA"):
+				inData = true
+				fmt.Fprint(conn, "354 go ahead\r\n")
+			case strings.HasPrefix(line, "QUIT"):
+				fmt.Fprint(conn, "221 bye\r\n")
+				return
+			default:
+				fmt.Fprint(conn, "250 OK\r\n")
+			}
+		}
+	}()
+
+	sender := &SMTPSender{Addr: ln.Addr().String()}
+	require.NoError(t, sender.Send(t.Context(), Message{
+		From:     "noreply@mail.example.com",
+		To:       []string{"alice@example.com"},
+		Subject:  "Hello",
+		TextBody: "body",
+	}))
+
+	parsed, err := mail.ReadMessage(strings.NewReader(<-received))
+	require.NoError(t, err)
+	require.Equal(t, "Hello", parsed.Header.Get("Subject"))
+}
+
+func TestSMTPSender_ContextDeadline(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	// accept connections, but never greet them
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	sender := &SMTPSender{Addr: ln.Addr().String()}
+	err = sender.Send(ctx, Message{
+		From:     "noreply@mail.example.com",
+		To:       []string{"alice@example.com"},
+		Subject:  "Hello",
+		TextBody: "body",
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,90 @@
+package emailsender
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+)
+
+// DirectorySender writes each message to its own .eml file in Dir, for
+// inspecting mail in local development.
+type DirectorySender struct {
+	Dir string
+}
+
+func (s *DirectorySender) Send(ctx context.Context, msg Message) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	// UUIDv7s sort by time, so the directory lists messages in the order they
+	// were sent.
+	name := filepath.Join(s.Dir, fmt.Sprintf("%s.eml", uuidv7.NewWithTime(time.Now())))
+	f, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer f.Close()
+
+	if err := writeMessage(f, msg); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close file: %w", err)
+	}
+
+	return nil
+}
+
+// WriterSender writes messages to W, one after another. It is typically used
+// with os.Stdout.
+type WriterSender struct {
+	W io.Writer
+
+	mu sync.Mutex
+}
+
+func (s *WriterSender) Send(ctx context.Context, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := writeMessage(s.W, msg); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+
+	if _, err := io.WriteString(s.W, "\r\n"); err != nil {
+		return fmt.Errorf("write message separator: %w", err)
+	}
+
+	return nil
+}
+
+// MemorySender records messages instead of sending them. It is intended for
+// tests.
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (s *MemorySender) Send(ctx context.Context, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first.
+func (s *MemorySender) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Message(nil), s.messages...)
+}
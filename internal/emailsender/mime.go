@@ -0,0 +1,72 @@
+package emailsender
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// writeMessage writes msg to w in RFC 5322 format. Messages with an HTML body
+// are written as multipart/alternative.
+func writeMessage(w io.Writer, msg Message) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "From: %s\r\n", msg.From)
+	fmt.Fprintf(bw, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(bw, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(bw, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(bw, "MIME-Version: 1.0\r\n")
+
+	if msg.HTMLBody == "" {
+		fmt.Fprintf(bw, "Content-Type: text/plain; charset=utf-8\r\n")
+		fmt.Fprintf(bw, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(bw, msg.TextBody); err != nil {
+			return fmt.Errorf("write text body: %w", err)
+		}
+		return bw.Flush()
+	}
+
+	mw := multipart.NewWriter(bw)
+	fmt.Fprintf(bw, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	// Per RFC 2046, parts are ordered from least to most preferred.
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{contentType: "text/plain; charset=utf-8", body: msg.TextBody},
+		{contentType: "text/html; charset=utf-8", body: msg.HTMLBody},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return fmt.Errorf("create part: %w", err)
+		}
+
+		if err := writeQuotedPrintable(pw, part.body); err != nil {
+			return fmt.Errorf("write part: %w", err)
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return fmt.Errorf("close multipart writer: %w", err)
+	}
+
+	return bw.Flush()
+}
+
+func writeQuotedPrintable(w io.Writer, s string) error {
+	qw := quotedprintable.NewWriter(w)
+	if _, err := io.WriteString(qw, s); err != nil {
+		return err
+	}
+	return qw.Close()
+}
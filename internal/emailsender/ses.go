@@ -0,0 +1,46 @@
+package emailsender
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// SESSender sends messages through AWS SES.
+type SESSender struct {
+	Client *sesv2.Client
+}
+
+func (s *SESSender) Send(ctx context.Context, msg Message) error {
+	body := &types.Body{
+		Text: &types.Content{
+			Data: &msg.TextBody,
+		},
+	}
+	if msg.HTMLBody != "" {
+		body.Html = &types.Content{
+			Data: &msg.HTMLBody,
+		}
+	}
+
+	if _, err := s.Client.SendEmail(ctx, &sesv2.SendEmailInput{
+		Content: &types.EmailContent{
+			Simple: &types.Message{
+				Subject: &types.Content{
+					Data: &msg.Subject,
+				},
+				Body: body,
+			},
+		},
+		Destination: &types.Destination{
+			ToAddresses: msg.To,
+		},
+		FromEmailAddress: &msg.From,
+	}); err != nil {
+		return fmt.Errorf("send ses email: %w", err)
+	}
+
+	return nil
+}
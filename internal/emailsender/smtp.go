@@ -0,0 +1,120 @@
+package emailsender
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// smtpTimeout bounds how long sending a message may take when the caller's
+// context has no deadline of its own.
+const smtpTimeout = 30 * time.Second
+
+// SMTPSender sends messages through an SMTP server, such as one provided by
+// Postmark or SendGrid.
+type SMTPSender struct {
+	// Addr is the host:port of the SMTP server.
+	Addr string
+
+	// Username and Password, if set, are used to authenticate with PLAIN auth.
+	// net/smtp only sends PLAIN credentials over TLS or to localhost.
+	Username string
+	Password string
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	var buf bytes.Buffer
+	if err := writeMessage(&buf, msg); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+
+	host, _, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return fmt.Errorf("split smtp addr: %w", err)
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, smtpTimeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("dial smtp server: %w", err)
+	}
+	defer conn.Close()
+
+	// net/smtp has no notion of contexts, so bound the whole conversation by
+	// ctx's deadline, and end it early if ctx is canceled.
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("set smtp connection deadline: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := s.send(conn, host, msg, buf.Bytes()); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("send smtp mail: %w", ctx.Err())
+		}
+		return fmt.Errorf("send smtp mail: %w", err)
+	}
+
+	return nil
+}
+
+// send sends msg over conn the way smtp.SendMail does: upgrading to TLS if the
+// server supports it, then authenticating if credentials are configured.
+func (s *SMTPSender) send(conn net.Conn, host string, msg Message, body []byte) error {
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return fmt.Errorf("create client: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("start tls: %w", err)
+		}
+	}
+
+	if s.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(msg.From); err != nil {
+		return fmt.Errorf("mail: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("rcpt: %w", err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("write data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("close data: %w", err)
+	}
+
+	if err := c.Quit(); err != nil {
+		return fmt.Errorf("quit: %w", err)
+	}
+
+	return nil
+}
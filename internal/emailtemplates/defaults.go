@@ -0,0 +1,96 @@
+package emailtemplates
+
+// Default returns the built-in template for emails of type typ. Default
+// templates are in English and have no locale.
+func Default(typ string) Template {
+	return defaultTemplates[typ]
+}
+
+var defaultTemplates = map[string]Template{
+	TypeEmailVerification: {
+		Subject: `{{ .ProjectDisplayName }} - Verify your email address`,
+		TextBody: `Hello,
+
+To continue logging in to {{ .ProjectDisplayName }}, please verify your email address by visiting the link below.
+
+{{ .EmailVerificationLink }}
+
+You can also go back to the "Check your email" page and enter this verification code manually:
+
+{{ .EmailVerificationCode }}
+
+If you did not request this verification, please ignore this email.
+`,
+		HTMLBody: defaultHTMLBody(`
+          <p>Hello,</p>
+          <p>To continue logging in to {{ .ProjectDisplayName }}, please verify your email address by clicking the button below.</p>
+          <p style="margin: 32px 0;">
+            <a href="{{ .EmailVerificationLink }}" style="background-color: {{ .PrimaryColor }}; border-radius: 6px; color: #ffffff; display: inline-block; font-weight: 600; padding: 12px 20px; text-decoration: none;">Verify email address</a>
+          </p>
+          <p>You can also go back to the "Check your email" page and enter this verification code manually:</p>
+          <p style="font-family: monospace; font-size: 14px;">{{ .EmailVerificationCode }}</p>
+          <p style="color: #64748b;">If you did not request this verification, please ignore this email.</p>`),
+	},
+	TypePasswordReset: {
+		Subject: `{{ .ProjectDisplayName }} - Reset password`,
+		TextBody: `Hello,
+
+Someone has requested a password reset for your {{ .ProjectDisplayName }} account. If you did not request this, please ignore this email.
+
+To continue logging in to {{ .ProjectDisplayName }}, please go back to the "Forgot password" page and enter this verification code:
+
+{{ .PasswordResetCode }}
+
+If you did not request this verification, please ignore this email.
+`,
+		HTMLBody: defaultHTMLBody(`
+          <p>Hello,</p>
+          <p>Someone has requested a password reset for your {{ .ProjectDisplayName }} account. If you did not request this, please ignore this email.</p>
+          <p>To continue logging in to {{ .ProjectDisplayName }}, please go back to the "Forgot password" page and enter this verification code:</p>
+          <p style="border-left: 4px solid {{ .PrimaryColor }}; font-family: monospace; font-size: 14px; margin: 32px 0; padding-left: 12px;">{{ .PasswordResetCode }}</p>
+          <p style="color: #64748b;">If you did not request this verification, please ignore this email.</p>`),
+	},
+	TypeUserInvite: {
+		Subject: `{{ .ProjectDisplayName }} - You've been invited to join {{ .OrganizationDisplayName }}`,
+		TextBody: `Hello,
+
+You have been invited to join {{ .OrganizationDisplayName }} in {{ .ProjectDisplayName }}.
+
+You can accept this invite by signing up for {{ .ProjectDisplayName }}:
+
+{{ .SignupLink }}
+`,
+		HTMLBody: defaultHTMLBody(`
+          <p>Hello,</p>
+          <p>You have been invited to join <strong>{{ .OrganizationDisplayName }}</strong> in {{ .ProjectDisplayName }}.</p>
+          <p style="margin: 32px 0;">
+            <a href="{{ .SignupLink }}" style="background-color: {{ .PrimaryColor }}; border-radius: 6px; color: #ffffff; display: inline-block; font-weight: 600; padding: 12px 20px; text-decoration: none;">Accept invite</a>
+          </p>
+          <p style="color: #64748b;">You can also accept this invite by visiting {{ .SignupLink }}.</p>`),
+	},
+}
+
+// defaultHTMLBody wraps content in the layout shared by the default HTML
+// templates: the project's logo, or its name if it has no logo, above a
+// content card.
+func defaultHTMLBody(content string) string {
+	return `<!DOCTYPE html>
+<html>
+  <body style="background-color: #f8fafc; color: #0f172a; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif; font-size: 16px; line-height: 1.5; margin: 0; padding: 32px 16px;">
+    <table role="presentation" width="100%" cellspacing="0" cellpadding="0">
+      <tr>
+        <td align="center">
+          {{ if .LogoURL }}<img src="{{ .LogoURL }}" alt="{{ .ProjectDisplayName }}" style="max-height: 48px; max-width: 200px;">{{ else }}<p style="font-size: 20px; font-weight: 600;">{{ .ProjectDisplayName }}</p>{{ end }}
+        </td>
+      </tr>
+      <tr>
+        <td align="center" style="padding-top: 24px;">
+          <div style="background-color: #ffffff; border: 1px solid #e2e8f0; border-radius: 8px; max-width: 560px; padding: 32px; text-align: left;">` + content + `
+          </div>
+        </td>
+      </tr>
+    </table>
+  </body>
+</html>
+`
+}
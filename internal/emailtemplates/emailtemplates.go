@@ -0,0 +1,257 @@
+// Package emailtemplates renders the transactional emails Tesseral sends on
+// behalf of projects.
+//
+// Each email type has a built-in default template. Projects may override the
+// default with their own templates, optionally one per locale. Templates use
+// Go template syntax; the subject and text body are rendered with
+// text/template, and the HTML body with html/template.
+package emailtemplates
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"slices"
+	"text/template"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	TypeEmailVerification = "email_verification"
+	TypePasswordReset     = "password_reset"
+	TypeUserInvite        = "user_invite"
+)
+
+// Types returns every email type, in a stable order.
+func Types() []string {
+	return []string{TypeEmailVerification, TypePasswordReset, TypeUserInvite}
+}
+
+// Variables available to every template. LogoURL is empty if the project has
+// no logo. PrimaryColor is always set; it falls back to a neutral color if the
+// project has none.
+const (
+	VariableProjectDisplayName = "ProjectDisplayName"
+	VariableLogoURL            = "LogoURL"
+	VariablePrimaryColor       = "PrimaryColor"
+)
+
+// DefaultPrimaryColor is used for projects that have not configured a primary
+// color in their UI settings.
+const DefaultPrimaryColor = "#0f172a"
+
+// typeVariables are the variables available to templates of each type, in
+// addition to the common variables above. The values are example values used
+// to validate and preview templates.
+var typeVariables = map[string]map[string]string{
+	TypeEmailVerification: {
+		"EmailVerificationLink": "https://vault.example.com/verify-email?code=email_verification_challenge_code_example",
+		"EmailVerificationCode": "email_verification_challenge_code_example",
+	},
+	TypePasswordReset: {
+		"PasswordResetCode": "password_reset_code_example",
+	},
+	TypeUserInvite: {
+		"OrganizationDisplayName": "Example Organization",
+		"SignupLink":              "https://vault.example.com/signup",
+	},
+}
+
+// Variables returns the names of the variables available to templates of
+// type typ, sorted.
+func Variables(typ string) []string {
+	names := []string{VariableProjectDisplayName, VariableLogoURL, VariablePrimaryColor}
+	for name := range typeVariables[typ] {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+// SampleVariables returns example values for the variables available to
+// templates of type typ. The project variables are left for the caller to
+// fill in.
+func SampleVariables(typ string) map[string]string {
+	vars := map[string]string{
+		VariableProjectDisplayName: "Example Project",
+		VariableLogoURL:            "",
+		VariablePrimaryColor:       DefaultPrimaryColor,
+	}
+	for name, value := range typeVariables[typ] {
+		vars[name] = value
+	}
+	return vars
+}
+
+type Template struct {
+	// Locale is a BCP 47 language tag, or empty if the template applies to
+	// every locale.
+	Locale string
+
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Email is a rendered Template.
+type Email struct {
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Validate returns an error describing the first problem with t as a template
+// of type typ, such as a syntax error or a reference to a variable that is not
+// available to typ.
+func Validate(typ string, t Template) error {
+	if _, ok := typeVariables[typ]; !ok {
+		return fmt.Errorf("unknown email template type: %q", typ)
+	}
+
+	if t.Locale != "" {
+		if _, err := ParseLocale(t.Locale); err != nil {
+			return err
+		}
+	}
+
+	if t.Subject == "" {
+		return fmt.Errorf("subject is required")
+	}
+	if t.TextBody == "" {
+		return fmt.Errorf("text body is required")
+	}
+	if t.HTMLBody == "" {
+		return fmt.Errorf("html body is required")
+	}
+
+	if _, err := Render(t, SampleVariables(typ)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseLocale parses locale as a BCP 47 language tag and returns it in
+// canonical form.
+func ParseLocale(locale string) (string, error) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", fmt.Errorf("invalid locale %q: %w", locale, err)
+	}
+	return tag.String(), nil
+}
+
+// Render executes t with vars. It is an error for t to reference a variable
+// that is not in vars.
+func Render(t Template, vars map[string]string) (*Email, error) {
+	subject, err := executeText("subject", t.Subject, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	textBody, err := executeText("text body", t.TextBody, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	htmlBody, err := executeHTML(t.HTMLBody, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Email{
+		Subject:  subject,
+		TextBody: textBody,
+		HTMLBody: htmlBody,
+	}, nil
+}
+
+func executeText(name, text string, vars map[string]string) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("execute %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+func executeHTML(text string, vars map[string]string) (string, error) {
+	tmpl, err := htmltemplate.New("html body").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse html body: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("execute html body: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Select returns the template to use for an email of type typ, given a
+// project's templates of that type and the recipient's Accept-Language
+// header.
+//
+// Select prefers the template whose locale best matches acceptLanguage, then
+// the project's template without a locale, then the built-in default.
+func Select(typ string, templates []Template, acceptLanguage string) Template {
+	var locales []string
+	for _, t := range templates {
+		if t.Locale != "" {
+			locales = append(locales, t.Locale)
+		}
+	}
+
+	if locale, ok := MatchLocale(acceptLanguage, locales); ok {
+		for _, t := range templates {
+			if t.Locale == locale {
+				return t
+			}
+		}
+	}
+
+	for _, t := range templates {
+		if t.Locale == "" {
+			return t
+		}
+	}
+
+	return Default(typ)
+}
+
+// MatchLocale returns the element of locales that best matches the
+// Accept-Language header acceptLanguage. It returns false if acceptLanguage is
+// empty or invalid, or if no element of locales is an acceptable match.
+func MatchLocale(acceptLanguage string, locales []string) (string, bool) {
+	if acceptLanguage == "" || len(locales) == 0 {
+		return "", false
+	}
+
+	preferred, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(preferred) == 0 {
+		return "", false
+	}
+
+	var supported []language.Tag
+	for _, locale := range locales {
+		tag, err := language.Parse(locale)
+		if err != nil {
+			continue
+		}
+		supported = append(supported, tag)
+	}
+	if len(supported) == 0 {
+		return "", false
+	}
+
+	_, index, confidence := language.NewMatcher(supported).Match(preferred...)
+	if confidence == language.No {
+		return "", false
+	}
+	return supported[index].String(), true
+}
@@ -0,0 +1,98 @@
+package emailtemplates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultTemplatesAreValid(t *testing.T) {
+	for _, typ := range Types() {
+		t.Run(typ, func(t *testing.T) {
+			require.NoError(t, Validate(typ, Default(typ)))
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	vars := SampleVariables(TypeUserInvite)
+	vars[VariableProjectDisplayName] = "Acme"
+	vars[VariableLogoURL] = "https://usercontent.example.com/logo"
+	vars[VariablePrimaryColor] = "#ff0000"
+	vars["OrganizationDisplayName"] = "<Widgets & Co>"
+
+	email, err := Render(Default(TypeUserInvite), vars)
+	require.NoError(t, err)
+	require.Equal(t, "Acme - You've been invited to join <Widgets & Co>", email.Subject)
+	require.Contains(t, email.TextBody, "You have been invited to join <Widgets & Co> in Acme.")
+	require.Contains(t, email.HTMLBody, "&lt;Widgets &amp; Co&gt;")
+	require.Contains(t, email.HTMLBody, `src="https://usercontent.example.com/logo"`)
+	require.Contains(t, email.HTMLBody, "background-color: #ff0000")
+}
+
+func TestRender_NoLogo(t *testing.T) {
+	vars := SampleVariables(TypePasswordReset)
+	vars[VariableProjectDisplayName] = "Acme"
+
+	email, err := Render(Default(TypePasswordReset), vars)
+	require.NoError(t, err)
+	require.NotContains(t, email.HTMLBody, "<img")
+	require.Contains(t, email.HTMLBody, ">Acme</p>")
+}
+
+func TestValidate(t *testing.T) {
+	valid := Template{
+		Subject:  "{{ .ProjectDisplayName }} - Reset password",
+		TextBody: "Your code is {{ .PasswordResetCode }}",
+		HTMLBody: "<p>Your code is {{ .PasswordResetCode }}</p>",
+	}
+	require.NoError(t, Validate(TypePasswordReset, valid))
+
+	unknownVariable := valid
+	unknownVariable.TextBody = "Sign up at {{ .SignupLink }}"
+	require.ErrorContains(t, Validate(TypePasswordReset, unknownVariable), "SignupLink")
+
+	syntaxError := valid
+	syntaxError.HTMLBody = "<p>{{ .PasswordResetCode </p>"
+	require.ErrorContains(t, Validate(TypePasswordReset, syntaxError), "parse html body")
+
+	badLocale := valid
+	badLocale.Locale = "not a locale"
+	require.ErrorContains(t, Validate(TypePasswordReset, badLocale), "invalid locale")
+
+	require.ErrorContains(t, Validate("unknown", valid), "unknown email template type")
+}
+
+func TestMatchLocale(t *testing.T) {
+	locales := []string{"de", "fr-CA", "pt-BR"}
+
+	testCases := []struct {
+		acceptLanguage string
+		want           string
+		wantOK         bool
+	}{
+		{acceptLanguage: "de-DE,de;q=0.9,en;q=0.8", want: "de", wantOK: true},
+		{acceptLanguage: "fr-CA", want: "fr-CA", wantOK: true},
+		{acceptLanguage: "en-US,pt-BR;q=0.5", want: "pt-BR", wantOK: true},
+		{acceptLanguage: "ja", wantOK: false},
+		{acceptLanguage: "", wantOK: false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			got, ok := MatchLocale(tt.acceptLanguage, locales)
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	german := Template{Locale: "de", Subject: "Passwort zurücksetzen"}
+	fallback := Template{Subject: "Reset your password"}
+
+	require.Equal(t, german, Select(TypePasswordReset, []Template{fallback, german}, "de-AT"))
+	require.Equal(t, fallback, Select(TypePasswordReset, []Template{fallback, german}, "es"))
+	require.Equal(t, Default(TypePasswordReset), Select(TypePasswordReset, []Template{german}, "es"))
+	require.Equal(t, Default(TypePasswordReset), Select(TypePasswordReset, nil, ""))
+}
@@ -75,12 +75,24 @@ func New(s *store.Store, p *projectid.Sniffer, cookier *cookies.Cookier) connect
 				return nil, connect.NewError(connect.CodeUnauthenticated, err)
 			}
 
-			ctx = authn.NewContext(ctx, authn.ContextData{
+			data := authn.ContextData{
 				SessionID:      claims["session"].(map[string]any)["id"].(string),
 				UserID:         claims["user"].(map[string]any)["id"].(string),
 				OrganizationID: claims["organization"].(map[string]any)["id"].(string),
 				ProjectID:      requestProjectID,
-			})
+			}
+			ctx = authn.NewContext(ctx, data)
+
+			// owners of an organization may act on one of its descendants
+			// instead
+			if organizationID := req.Header().Get("X-Tesseral-Organization-Id"); organizationID != "" && organizationID != data.OrganizationID {
+				if err := s.AuthorizeDescendantOrganization(ctx, organizationID); err != nil {
+					return nil, fmt.Errorf("authorize descendant organization: %w", err)
+				}
+
+				data.OrganizationID = organizationID
+				ctx = authn.NewContext(ctx, data)
+			}
 
 			return next(ctx, req)
 		}
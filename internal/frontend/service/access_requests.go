@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+)
+
+func (s *Service) ListAccessRequests(ctx context.Context, req *connect.Request[frontendv1.ListAccessRequestsRequest]) (*connect.Response[frontendv1.ListAccessRequestsResponse], error) {
+	res, err := s.Store.ListAccessRequests(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) GetAccessRequest(ctx context.Context, req *connect.Request[frontendv1.GetAccessRequestRequest]) (*connect.Response[frontendv1.GetAccessRequestResponse], error) {
+	res, err := s.Store.GetAccessRequest(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) CreateAccessRequest(ctx context.Context, req *connect.Request[frontendv1.CreateAccessRequestRequest]) (*connect.Response[frontendv1.CreateAccessRequestResponse], error) {
+	res, err := s.Store.CreateAccessRequest(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) ApproveAccessRequest(ctx context.Context, req *connect.Request[frontendv1.ApproveAccessRequestRequest]) (*connect.Response[frontendv1.ApproveAccessRequestResponse], error) {
+	res, err := s.Store.ApproveAccessRequest(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
+func (s *Service) DenyAccessRequest(ctx context.Context, req *connect.Request[frontendv1.DenyAccessRequestRequest]) (*connect.Response[frontendv1.DenyAccessRequestResponse], error) {
+	res, err := s.Store.DenyAccessRequest(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
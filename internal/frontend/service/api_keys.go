@@ -53,6 +53,15 @@ func (s *Service) RevokeAPIKey(ctx context.Context, req *connect.Request[fronten
 	return connect.NewResponse(res), nil
 }
 
+func (s *Service) RotateAPIKey(ctx context.Context, req *connect.Request[frontendv1.RotateAPIKeyRequest]) (*connect.Response[frontendv1.RotateAPIKeyResponse], error) {
+	res, err := s.Store.RotateAPIKey(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
 func (s *Service) UpdateAPIKey(ctx context.Context, req *connect.Request[frontendv1.UpdateAPIKeyRequest]) (*connect.Response[frontendv1.UpdateAPIKeyResponse], error) {
 	res, err := s.Store.UpdateAPIKey(ctx, req.Msg)
 	if err != nil {
@@ -16,6 +16,14 @@ func (s *Service) GetOrganization(ctx context.Context, req *connect.Request[fron
 	return connect.NewResponse(res), nil
 }
 
+func (s *Service) ListOrganizationDescendants(ctx context.Context, req *connect.Request[frontendv1.ListOrganizationDescendantsRequest]) (*connect.Response[frontendv1.ListOrganizationDescendantsResponse], error) {
+	res, err := s.Store.ListOrganizationDescendants(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+	return connect.NewResponse(res), nil
+}
+
 func (s *Service) UpdateOrganization(ctx context.Context, req *connect.Request[frontendv1.UpdateOrganizationRequest]) (*connect.Response[frontendv1.UpdateOrganizationResponse], error) {
 	res, err := s.Store.UpdateOrganization(ctx, req.Msg)
 	if err != nil {
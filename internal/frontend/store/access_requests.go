@@ -0,0 +1,379 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxAccessRequestDuration is the longest a User may request a Role for.
+const maxAccessRequestDuration = 7 * 24 * time.Hour
+
+func (s *Store) ListAccessRequests(ctx context.Context, req *frontendv1.ListAccessRequestsRequest) (*frontendv1.ListAccessRequestsResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qUser, err := q.GetUserByID(ctx, authn.UserID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get user by id: %w", err)
+	}
+
+	var startID uuid.UUID
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	limit := 10
+	var qAccessRequests []queries.AccessRequest
+	if qUser.IsOwner {
+		qAccessRequests, err = q.ListAccessRequests(ctx, queries.ListAccessRequestsParams{
+			OrganizationID: authn.OrganizationID(ctx),
+			ID:             startID,
+			Limit:          int32(limit + 1),
+		})
+	} else {
+		qAccessRequests, err = q.ListAccessRequestsByUser(ctx, queries.ListAccessRequestsByUserParams{
+			UserID: authn.UserID(ctx),
+			ID:     startID,
+			Limit:  int32(limit + 1),
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list access requests: %w", err)
+	}
+
+	var accessRequests []*frontendv1.AccessRequest
+	for _, qAccessRequest := range qAccessRequests {
+		accessRequests = append(accessRequests, parseAccessRequest(qAccessRequest))
+	}
+
+	var nextPageToken string
+	if len(accessRequests) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qAccessRequests[limit].ID)
+		accessRequests = accessRequests[:limit]
+	}
+
+	return &frontendv1.ListAccessRequestsResponse{
+		AccessRequests: accessRequests,
+		NextPageToken:  nextPageToken,
+	}, nil
+}
+
+func (s *Store) GetAccessRequest(ctx context.Context, req *frontendv1.GetAccessRequestRequest) (*frontendv1.GetAccessRequestResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qAccessRequest, err := s.getAccessRequest(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// non-owners may only see their own access requests
+	if qAccessRequest.UserID != authn.UserID(ctx) {
+		if err := s.validateIsOwner(ctx); err != nil {
+			return nil, apierror.NewNotFoundError("access request not found", fmt.Errorf("validate is owner: %w", err))
+		}
+	}
+
+	return &frontendv1.GetAccessRequestResponse{AccessRequest: parseAccessRequest(*qAccessRequest)}, nil
+}
+
+func (s *Store) CreateAccessRequest(ctx context.Context, req *frontendv1.CreateAccessRequestRequest) (*frontendv1.CreateAccessRequestResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	roleID, err := idformat.Role.Parse(req.AccessRequest.RoleId)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid role id", fmt.Errorf("parse role id: %w", err))
+	}
+
+	duration := time.Duration(req.AccessRequest.DurationSeconds) * time.Second
+	if duration <= 0 || duration > maxAccessRequestDuration {
+		return nil, apierror.NewInvalidArgumentError("duration_seconds must be between 1 second and 7 days", fmt.Errorf("invalid duration_seconds: %d", req.AccessRequest.DurationSeconds))
+	}
+
+	orgID := authn.OrganizationID(ctx)
+	qRole, err := q.GetRole(ctx, queries.GetRoleParams{
+		ProjectID:      authn.ProjectID(ctx),
+		OrganizationID: &orgID,
+		ID:             roleID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("role not found", fmt.Errorf("get role: %w", err))
+		}
+		return nil, fmt.Errorf("get role: %w", err)
+	}
+
+	if !qRole.Requestable {
+		return nil, apierror.NewFailedPreconditionError("role is not requestable", fmt.Errorf("role is not requestable"))
+	}
+
+	qAccessRequest, err := q.CreateAccessRequest(ctx, queries.CreateAccessRequestParams{
+		ID:              uuid.New(),
+		UserID:          authn.UserID(ctx),
+		RoleID:          roleID,
+		Reason:          req.AccessRequest.Reason,
+		DurationSeconds: req.AccessRequest.DurationSeconds,
+	})
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23505" {
+			return nil, apierror.NewAlreadyExistsError("a pending access request for this role already exists", fmt.Errorf("create access request: %w", err))
+		}
+		return nil, fmt.Errorf("create access request: %w", err)
+	}
+
+	auditAccessRequest, err := s.auditlogStore.GetAccessRequest(ctx, tx, qAccessRequest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit access request: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+		EventName: "tesseral.access_requests.create",
+		EventDetails: &auditlogv1.CreateAccessRequest{
+			AccessRequest: auditAccessRequest,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeUser,
+		ResourceID:   &qAccessRequest.UserID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &frontendv1.CreateAccessRequestResponse{AccessRequest: parseAccessRequest(qAccessRequest)}, nil
+}
+
+func (s *Store) ApproveAccessRequest(ctx context.Context, req *frontendv1.ApproveAccessRequestRequest) (*frontendv1.ApproveAccessRequestResponse, error) {
+	if err := s.validateIsOwner(ctx); err != nil {
+		return nil, fmt.Errorf("validate is owner: %w", err)
+	}
+
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qPreviousAccessRequest, err := s.getAccessRequest(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if qPreviousAccessRequest.UserID == authn.UserID(ctx) {
+		return nil, apierror.NewPermissionDeniedError("users cannot approve their own access requests", fmt.Errorf("user cannot approve own access request"))
+	}
+
+	auditPreviousAccessRequest, err := s.auditlogStore.GetAccessRequest(ctx, tx, qPreviousAccessRequest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit previous access request: %w", err)
+	}
+
+	qAccessRequest, err := s.reviewAccessRequest(ctx, q, qPreviousAccessRequest.ID, queries.AccessRequestStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	expireTime := time.Now().Add(time.Duration(qAccessRequest.DurationSeconds) * time.Second)
+	if err := q.UpsertUserRoleAssignment(ctx, queries.UpsertUserRoleAssignmentParams{
+		ID:         uuid.New(),
+		RoleID:     qAccessRequest.RoleID,
+		UserID:     qAccessRequest.UserID,
+		ExpireTime: &expireTime,
+	}); err != nil {
+		return nil, fmt.Errorf("upsert user role assignment: %w", err)
+	}
+
+	qUserRoleAssignment, err := q.GetUserRoleAssignmentByUserAndRole(ctx, queries.GetUserRoleAssignmentByUserAndRoleParams{
+		UserID: qAccessRequest.UserID,
+		RoleID: qAccessRequest.RoleID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get user role assignment by user and role: %w", err)
+	}
+
+	auditAccessRequest, err := s.auditlogStore.GetAccessRequest(ctx, tx, qAccessRequest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit access request: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+		EventName: "tesseral.access_requests.approve",
+		EventDetails: &auditlogv1.ApproveAccessRequest{
+			AccessRequest:         auditAccessRequest,
+			PreviousAccessRequest: auditPreviousAccessRequest,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeUser,
+		ResourceID:   &qAccessRequest.UserID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	auditUserRoleAssignment, err := s.auditlogStore.GetUserRoleAssignment(ctx, tx, qUserRoleAssignment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit user role assignment: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+		EventName: "tesseral.users.assign_role",
+		EventDetails: &auditlogv1.AssignUserRole{
+			UserRoleAssignment: auditUserRoleAssignment,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeUser,
+		ResourceID:   &qUserRoleAssignment.UserID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &frontendv1.ApproveAccessRequestResponse{
+		AccessRequest:      parseAccessRequest(*qAccessRequest),
+		UserRoleAssignment: parseUserRoleAssignment(qUserRoleAssignment),
+	}, nil
+}
+
+func (s *Store) DenyAccessRequest(ctx context.Context, req *frontendv1.DenyAccessRequestRequest) (*frontendv1.DenyAccessRequestResponse, error) {
+	if err := s.validateIsOwner(ctx); err != nil {
+		return nil, fmt.Errorf("validate is owner: %w", err)
+	}
+
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	qPreviousAccessRequest, err := s.getAccessRequest(ctx, q, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	auditPreviousAccessRequest, err := s.auditlogStore.GetAccessRequest(ctx, tx, qPreviousAccessRequest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit previous access request: %w", err)
+	}
+
+	qAccessRequest, err := s.reviewAccessRequest(ctx, q, qPreviousAccessRequest.ID, queries.AccessRequestStatusDenied)
+	if err != nil {
+		return nil, err
+	}
+
+	auditAccessRequest, err := s.auditlogStore.GetAccessRequest(ctx, tx, qAccessRequest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit access request: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+		EventName: "tesseral.access_requests.deny",
+		EventDetails: &auditlogv1.DenyAccessRequest{
+			AccessRequest:         auditAccessRequest,
+			PreviousAccessRequest: auditPreviousAccessRequest,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeUser,
+		ResourceID:   &qAccessRequest.UserID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return &frontendv1.DenyAccessRequestResponse{AccessRequest: parseAccessRequest(*qAccessRequest)}, nil
+}
+
+func (s *Store) getAccessRequest(ctx context.Context, q *queries.Queries, id string) (*queries.AccessRequest, error) {
+	accessRequestID, err := idformat.AccessRequest.Parse(id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid access request id", fmt.Errorf("parse access request id: %w", err))
+	}
+
+	qAccessRequest, err := q.GetAccessRequest(ctx, queries.GetAccessRequestParams{
+		ID:             accessRequestID,
+		OrganizationID: authn.OrganizationID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("access request not found", fmt.Errorf("get access request: %w", err))
+		}
+		return nil, fmt.Errorf("get access request: %w", err)
+	}
+
+	return &qAccessRequest, nil
+}
+
+// reviewAccessRequest moves a pending access request to status, recording the
+// current user as its reviewer.
+func (s *Store) reviewAccessRequest(ctx context.Context, q *queries.Queries, id uuid.UUID, status queries.AccessRequestStatus) (*queries.AccessRequest, error) {
+	reviewerUserID := authn.UserID(ctx)
+	qAccessRequest, err := q.UpdateAccessRequestStatus(ctx, queries.UpdateAccessRequestStatusParams{
+		ID:             id,
+		Status:         status,
+		ReviewerUserID: &reviewerUserID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewFailedPreconditionError("access request is not pending", fmt.Errorf("update access request status: %w", err))
+		}
+		return nil, fmt.Errorf("update access request status: %w", err)
+	}
+
+	return &qAccessRequest, nil
+}
+
+func parseAccessRequest(qAccessRequest queries.AccessRequest) *frontendv1.AccessRequest {
+	var status frontendv1.AccessRequestStatus
+	switch qAccessRequest.Status {
+	case queries.AccessRequestStatusPending:
+		status = frontendv1.AccessRequestStatus_ACCESS_REQUEST_STATUS_PENDING
+	case queries.AccessRequestStatusApproved:
+		status = frontendv1.AccessRequestStatus_ACCESS_REQUEST_STATUS_APPROVED
+	case queries.AccessRequestStatusDenied:
+		status = frontendv1.AccessRequestStatus_ACCESS_REQUEST_STATUS_DENIED
+	}
+
+	var reviewerUserID string
+	if qAccessRequest.ReviewerUserID != nil {
+		reviewerUserID = idformat.User.Format(*qAccessRequest.ReviewerUserID)
+	}
+
+	return &frontendv1.AccessRequest{
+		Id:              idformat.AccessRequest.Format(qAccessRequest.ID),
+		CreateTime:      timestamppb.New(*qAccessRequest.CreateTime),
+		UpdateTime:      timestamppb.New(*qAccessRequest.UpdateTime),
+		UserId:          idformat.User.Format(qAccessRequest.UserID),
+		RoleId:          idformat.Role.Format(qAccessRequest.RoleID),
+		Reason:          qAccessRequest.Reason,
+		DurationSeconds: qAccessRequest.DurationSeconds,
+		Status:          status,
+		ReviewerUserId:  reviewerUserID,
+		ReviewTime:      timestampOrNil(qAccessRequest.ReviewTime),
+	}
+}
@@ -0,0 +1,220 @@
+package store
+
+import (
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
+	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func TestAccessRequest_Approve(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ownerCtx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName:        "test",
+		CustomRolesEnabled: refOrNil(true),
+	})
+
+	orgID := idformat.Organization.Format(authn.OrganizationID(ownerCtx))
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "user1@example.com",
+	})
+	userCtx := authn.NewContext(t.Context(), authn.ContextData{
+		ProjectID:      u.ProjectID,
+		OrganizationID: orgID,
+		UserID:         userID,
+		SessionID:      idformat.Session.Format(uuid.New()),
+	})
+
+	roleResp, err := u.Store.CreateRole(ownerCtx, &frontendv1.CreateRoleRequest{
+		Role: &frontendv1.Role{
+			DisplayName: "role1",
+		},
+	})
+	require.NoError(t, err)
+
+	// roles are not requestable by default
+	_, err = u.Store.CreateAccessRequest(userCtx, &frontendv1.CreateAccessRequestRequest{
+		AccessRequest: &frontendv1.AccessRequest{
+			RoleId:          roleResp.Role.Id,
+			Reason:          "on call",
+			DurationSeconds: 3600,
+		},
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+
+	roleID, err := idformat.Role.Parse(roleResp.Role.Id)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `UPDATE roles SET requestable = true WHERE id = $1`, uuid.UUID(roleID))
+	require.NoError(t, err)
+
+	createResp, err := u.Store.CreateAccessRequest(userCtx, &frontendv1.CreateAccessRequestRequest{
+		AccessRequest: &frontendv1.AccessRequest{
+			RoleId:          roleResp.Role.Id,
+			Reason:          "on call",
+			DurationSeconds: 3600,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, userID, createResp.AccessRequest.UserId)
+	require.Equal(t, frontendv1.AccessRequestStatus_ACCESS_REQUEST_STATUS_PENDING, createResp.AccessRequest.Status)
+
+	// only one pending request per role
+	_, err = u.Store.CreateAccessRequest(userCtx, &frontendv1.CreateAccessRequestRequest{
+		AccessRequest: &frontendv1.AccessRequest{
+			RoleId:          roleResp.Role.Id,
+			Reason:          "on call",
+			DurationSeconds: 3600,
+		},
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeAlreadyExists, connectErr.Code())
+
+	// non-owners cannot approve requests
+	_, err = u.Store.ApproveAccessRequest(userCtx, &frontendv1.ApproveAccessRequestRequest{
+		Id: createResp.AccessRequest.Id,
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodePermissionDenied, connectErr.Code())
+
+	approveResp, err := u.Store.ApproveAccessRequest(ownerCtx, &frontendv1.ApproveAccessRequestRequest{
+		Id: createResp.AccessRequest.Id,
+	})
+	require.NoError(t, err)
+	require.Equal(t, frontendv1.AccessRequestStatus_ACCESS_REQUEST_STATUS_APPROVED, approveResp.AccessRequest.Status)
+	require.Equal(t, idformat.User.Format(authn.UserID(ownerCtx)), approveResp.AccessRequest.ReviewerUserId)
+	require.Equal(t, userID, approveResp.UserRoleAssignment.UserId)
+	require.Equal(t, roleResp.Role.Id, approveResp.UserRoleAssignment.RoleId)
+	require.NotNil(t, approveResp.UserRoleAssignment.ExpireTime)
+
+	// reviewed requests cannot be reviewed again
+	_, err = u.Store.DenyAccessRequest(ownerCtx, &frontendv1.DenyAccessRequestRequest{
+		Id: createResp.AccessRequest.Id,
+	})
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+
+	listResp, err := u.Store.ListAccessRequests(userCtx, &frontendv1.ListAccessRequestsRequest{})
+	require.NoError(t, err)
+	require.Len(t, listResp.AccessRequests, 1)
+}
+
+func TestAccessRequest_Deny(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ownerCtx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName:        "test",
+		CustomRolesEnabled: refOrNil(true),
+	})
+
+	orgID := idformat.Organization.Format(authn.OrganizationID(ownerCtx))
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "user1@example.com",
+	})
+	userCtx := authn.NewContext(t.Context(), authn.ContextData{
+		ProjectID:      u.ProjectID,
+		OrganizationID: orgID,
+		UserID:         userID,
+		SessionID:      idformat.Session.Format(uuid.New()),
+	})
+
+	roleResp, err := u.Store.CreateRole(ownerCtx, &frontendv1.CreateRoleRequest{
+		Role: &frontendv1.Role{
+			DisplayName: "role1",
+		},
+	})
+	require.NoError(t, err)
+
+	roleID, err := idformat.Role.Parse(roleResp.Role.Id)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `UPDATE roles SET requestable = true WHERE id = $1`, uuid.UUID(roleID))
+	require.NoError(t, err)
+
+	createResp, err := u.Store.CreateAccessRequest(userCtx, &frontendv1.CreateAccessRequestRequest{
+		AccessRequest: &frontendv1.AccessRequest{
+			RoleId:          roleResp.Role.Id,
+			Reason:          "on call",
+			DurationSeconds: 3600,
+		},
+	})
+	require.NoError(t, err)
+
+	denyResp, err := u.Store.DenyAccessRequest(ownerCtx, &frontendv1.DenyAccessRequestRequest{
+		Id: createResp.AccessRequest.Id,
+	})
+	require.NoError(t, err)
+	require.Equal(t, frontendv1.AccessRequestStatus_ACCESS_REQUEST_STATUS_DENIED, denyResp.AccessRequest.Status)
+
+	assignmentsResp, err := u.Store.ListUserRoleAssignments(ownerCtx, &frontendv1.ListUserRoleAssignmentsRequest{
+		UserId: userID,
+	})
+	require.NoError(t, err)
+	require.Empty(t, assignmentsResp.UserRoleAssignments)
+}
+
+func TestAccessRequest_ApproveKeepsPermanentAssignment(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ownerCtx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName:        "test",
+		CustomRolesEnabled: refOrNil(true),
+	})
+
+	orgID := idformat.Organization.Format(authn.OrganizationID(ownerCtx))
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{
+		Email: "user1@example.com",
+	})
+	userCtx := authn.NewContext(t.Context(), authn.ContextData{
+		ProjectID:      u.ProjectID,
+		OrganizationID: orgID,
+		UserID:         userID,
+		SessionID:      idformat.Session.Format(uuid.New()),
+	})
+
+	roleResp, err := u.Store.CreateRole(ownerCtx, &frontendv1.CreateRoleRequest{
+		Role: &frontendv1.Role{
+			DisplayName: "role1",
+		},
+	})
+	require.NoError(t, err)
+
+	roleID, err := idformat.Role.Parse(roleResp.Role.Id)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `UPDATE roles SET requestable = true WHERE id = $1`, uuid.UUID(roleID))
+	require.NoError(t, err)
+
+	_, err = u.Store.CreateUserRoleAssignment(ownerCtx, &frontendv1.CreateUserRoleAssignmentRequest{
+		UserRoleAssignment: &frontendv1.UserRoleAssignment{
+			UserId:               userID,
+			RoleId:               roleResp.Role.Id,
+			InheritToDescendants: true,
+		},
+	})
+	require.NoError(t, err)
+
+	createResp, err := u.Store.CreateAccessRequest(userCtx, &frontendv1.CreateAccessRequestRequest{
+		AccessRequest: &frontendv1.AccessRequest{
+			RoleId:          roleResp.Role.Id,
+			Reason:          "on call",
+			DurationSeconds: 3600,
+		},
+	})
+	require.NoError(t, err)
+
+	approveResp, err := u.Store.ApproveAccessRequest(ownerCtx, &frontendv1.ApproveAccessRequestRequest{
+		Id: createResp.AccessRequest.Id,
+	})
+	require.NoError(t, err)
+	require.Nil(t, approveResp.UserRoleAssignment.ExpireTime)
+	require.True(t, approveResp.UserRoleAssignment.InheritToDescendants)
+}
@@ -34,6 +34,11 @@ func (s *Store) CreateAPIKeyRoleAssignment(ctx context.Context, req *frontendv1.
 		return nil, apierror.NewInvalidArgumentError("invalid role id", fmt.Errorf("parse role id: %w", err))
 	}
 
+	expireTime, err := parseRoleAssignmentExpireTime(req.ApiKeyRoleAssignment.ExpireTime)
+	if err != nil {
+		return nil, err
+	}
+
 	if _, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
 		ID:             apiKeyID,
 		OrganizationID: authn.OrganizationID(ctx),
@@ -57,9 +62,10 @@ func (s *Store) CreateAPIKeyRoleAssignment(ctx context.Context, req *frontendv1.
 	}
 
 	qAPIKeyRoleAssignment, err := q.CreateAPIKeyRoleAssignment(ctx, queries.CreateAPIKeyRoleAssignmentParams{
-		ID:       uuid.New(),
-		ApiKeyID: apiKeyID,
-		RoleID:   roleID,
+		ID:         uuid.New(),
+		ApiKeyID:   apiKeyID,
+		RoleID:     roleID,
+		ExpireTime: expireTime,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create api key role assignment: %w", err)
@@ -197,8 +203,9 @@ func (s *Store) ListAPIKeyRoleAssignments(ctx context.Context, req *frontendv1.L
 
 func parseAPIKeyRoleAssignment(qAPIKeyRoleAssignment queries.ApiKeyRoleAssignment) *frontendv1.APIKeyRoleAssignment {
 	return &frontendv1.APIKeyRoleAssignment{
-		Id:       idformat.APIKeyRoleAssignment.Format(qAPIKeyRoleAssignment.ID),
-		ApiKeyId: idformat.APIKey.Format(qAPIKeyRoleAssignment.ApiKeyID),
-		RoleId:   idformat.Role.Format(qAPIKeyRoleAssignment.RoleID),
+		Id:         idformat.APIKeyRoleAssignment.Format(qAPIKeyRoleAssignment.ID),
+		ApiKeyId:   idformat.APIKey.Format(qAPIKeyRoleAssignment.ApiKeyID),
+		RoleId:     idformat.Role.Format(qAPIKeyRoleAssignment.RoleID),
+		ExpireTime: timestampOrNil(qAPIKeyRoleAssignment.ExpireTime),
 	}
 }
@@ -22,6 +22,13 @@ import (
 
 const apiKeySecretTokenSuffixLength = 4
 
+// defaultAPIKeyRotationGracePeriod and maxAPIKeyRotationGracePeriod bound how
+// long an API Key's previous secret token remains valid after RotateAPIKey.
+const (
+	defaultAPIKeyRotationGracePeriod = 24 * time.Hour
+	maxAPIKeyRotationGracePeriod     = 30 * 24 * time.Hour
+)
+
 func (s *Store) CreateAPIKey(ctx context.Context, req *frontendv1.CreateAPIKeyRequest) (*frontendv1.CreateAPIKeyResponse, error) {
 	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
@@ -297,6 +304,109 @@ func (s *Store) RevokeAPIKey(ctx context.Context, req *frontendv1.RevokeAPIKeyRe
 	return &frontendv1.RevokeAPIKeyResponse{}, nil
 }
 
+func (s *Store) RotateAPIKey(ctx context.Context, req *frontendv1.RotateAPIKeyRequest) (*frontendv1.RotateAPIKeyResponse, error) {
+	tx, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	apiKeyID, err := idformat.APIKey.Parse(req.Id)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid api key id", fmt.Errorf("parse api key id: %w", err))
+	}
+
+	gracePeriod, err := parseAPIKeyRotationGracePeriod(req.GracePeriodSeconds)
+	if err != nil {
+		return nil, err
+	}
+
+	qPreviousAPIKey, err := q.GetAPIKeyByID(ctx, queries.GetAPIKeyByIDParams{
+		ID:             apiKeyID,
+		OrganizationID: authn.OrganizationID(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("api key not found", fmt.Errorf("get api key: %w", err))
+		}
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+
+	if qPreviousAPIKey.SecretTokenSha256 == nil {
+		return nil, apierror.NewFailedPreconditionError("revoked api keys cannot be rotated", fmt.Errorf("api key is revoked"))
+	}
+
+	qProject, err := q.GetProjectByID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get project by id: %w", err)
+	}
+
+	if !qProject.ApiKeysEnabled {
+		return nil, apierror.NewPermissionDeniedError("api keys are not enabled for this project", fmt.Errorf("api keys not enabled for project"))
+	}
+
+	if qProject.ApiKeySecretTokenPrefix == nil || *qProject.ApiKeySecretTokenPrefix == "" {
+		return nil, apierror.NewInvalidArgumentError("api key secret token prefix is required", fmt.Errorf("api key secret token prefix is required"))
+	}
+
+	auditPreviousAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, qPreviousAPIKey.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit log api key: %w", err)
+	}
+
+	var secretTokenValue [35]byte
+	if _, err := rand.Read(secretTokenValue[:]); err != nil {
+		return nil, fmt.Errorf("generate secret token: %w", err)
+	}
+
+	secretToken := prettysecret.Format(*qProject.ApiKeySecretTokenPrefix, secretTokenValue)
+	secretTokenSuffix := secretToken[len(secretToken)-apiKeySecretTokenSuffixLength:]
+	secretTokenSHA256 := sha256.Sum256(secretTokenValue[:])
+
+	// The previous secret token is cleared, and its expiry logged, by the
+	// backend store's ExpireAPIKeyPreviousSecretTokens. With no grace period,
+	// it expires immediately.
+	previousSecretTokenExpireTime := time.Now().Add(gracePeriod)
+
+	qAPIKey, err := q.RotateAPIKey(ctx, queries.RotateAPIKeyParams{
+		ID:                            apiKeyID,
+		OrganizationID:                authn.OrganizationID(ctx),
+		PreviousSecretTokenExpireTime: &previousSecretTokenExpireTime,
+		SecretTokenSha256:             secretTokenSHA256[:],
+		SecretTokenSuffix:             &secretTokenSuffix,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rotate api key: %w", err)
+	}
+
+	auditAPIKey, err := s.auditlogStore.GetAPIKey(ctx, tx, qAPIKey.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit log api key: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+		EventName: "tesseral.api_keys.rotate",
+		EventDetails: &auditlogv1.RotateAPIKey{
+			ApiKey:         auditAPIKey,
+			PreviousApiKey: auditPreviousAPIKey,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeApiKey,
+		ResourceID:   &qAPIKey.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	apiKey := parseAPIKey(qAPIKey)
+	apiKey.SecretToken = secretToken
+	return &frontendv1.RotateAPIKeyResponse{
+		ApiKey: apiKey,
+	}, nil
+}
+
 func (s *Store) UpdateAPIKey(ctx context.Context, req *frontendv1.UpdateAPIKeyRequest) (*frontendv1.UpdateAPIKeyResponse, error) {
 	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
@@ -362,13 +472,27 @@ func (s *Store) UpdateAPIKey(ctx context.Context, req *frontendv1.UpdateAPIKeyRe
 
 func parseAPIKey(qAPIKey queries.ApiKey) *frontendv1.APIKey {
 	return &frontendv1.APIKey{
-		Id:                idformat.APIKey.Format(qAPIKey.ID),
-		DisplayName:       qAPIKey.DisplayName,
-		CreateTime:        timestamppb.New(*qAPIKey.CreateTime),
-		UpdateTime:        timestamppb.New(*qAPIKey.UpdateTime),
-		ExpireTime:        timestampOrNil(qAPIKey.ExpireTime),
-		Revoked:           qAPIKey.SecretTokenSha256 == nil,
-		SecretToken:       "", // intentionally left blank
-		SecretTokenSuffix: derefOrEmpty(qAPIKey.SecretTokenSuffix),
+		Id:                            idformat.APIKey.Format(qAPIKey.ID),
+		DisplayName:                   qAPIKey.DisplayName,
+		CreateTime:                    timestamppb.New(*qAPIKey.CreateTime),
+		UpdateTime:                    timestamppb.New(*qAPIKey.UpdateTime),
+		ExpireTime:                    timestampOrNil(qAPIKey.ExpireTime),
+		Revoked:                       qAPIKey.SecretTokenSha256 == nil,
+		SecretToken:                   "", // intentionally left blank
+		SecretTokenSuffix:             derefOrEmpty(qAPIKey.SecretTokenSuffix),
+		PreviousSecretTokenSuffix:     qAPIKey.PreviousSecretTokenSuffix,
+		PreviousSecretTokenExpireTime: timestampOrNil(qAPIKey.PreviousSecretTokenExpireTime),
+	}
+}
+
+func parseAPIKeyRotationGracePeriod(gracePeriodSeconds *int32) (time.Duration, error) {
+	if gracePeriodSeconds == nil {
+		return defaultAPIKeyRotationGracePeriod, nil
+	}
+
+	gracePeriod := time.Duration(*gracePeriodSeconds) * time.Second
+	if gracePeriod < 0 || gracePeriod > maxAPIKeyRotationGracePeriod {
+		return 0, apierror.NewInvalidArgumentError(fmt.Sprintf("grace_period_seconds must be between 0 and %d", int(maxAPIKeyRotationGracePeriod.Seconds())), fmt.Errorf("invalid grace period: %d", *gracePeriodSeconds))
 	}
+	return gracePeriod, nil
 }
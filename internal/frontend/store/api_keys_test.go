@@ -272,3 +272,38 @@ func TestListAPIKeys_Pagination(t *testing.T) {
 	}
 	require.ElementsMatch(t, createdIDs, allIDs)
 }
+
+func TestRotateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName:    "Test Organization",
+		ApiKeysEnabled: refOrNil(true),
+	})
+
+	createResp, err := u.Store.CreateAPIKey(ctx, &frontendv1.CreateAPIKeyRequest{
+		ApiKey: &frontendv1.APIKey{
+			DisplayName: "Test Key",
+		},
+	})
+	require.NoError(t, err)
+
+	rotateResp, err := u.Store.RotateAPIKey(ctx, &frontendv1.RotateAPIKeyRequest{
+		Id:                 createResp.ApiKey.Id,
+		GracePeriodSeconds: refOrNil(int32(3600)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, createResp.ApiKey.Id, rotateResp.ApiKey.Id)
+	require.NotEmpty(t, rotateResp.ApiKey.SecretToken)
+	require.NotEqual(t, createResp.ApiKey.SecretToken, rotateResp.ApiKey.SecretToken)
+	require.Equal(t, createResp.ApiKey.SecretTokenSuffix, rotateResp.ApiKey.GetPreviousSecretTokenSuffix())
+
+	_, err = u.Store.RotateAPIKey(ctx, &frontendv1.RotateAPIKeyRequest{
+		Id:                 createResp.ApiKey.Id,
+		GracePeriodSeconds: refOrNil(int32(-1)),
+	})
+	var connectErr *connect.Error
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+}
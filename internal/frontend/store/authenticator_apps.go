@@ -113,13 +113,23 @@ func (s *Store) RegisterAuthenticatorApp(ctx context.Context, req *frontendv1.Re
 		return nil, fmt.Errorf("get audit user: %w", err)
 	}
 
-	qUser, err := q.UpdateUserAuthenticatorApp(ctx, queries.UpdateUserAuthenticatorAppParams{
-		ID:                                  authn.UserID(ctx),
+	qUser, err := q.GetUserByID(ctx, authn.UserID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get user by id: %w", err)
+	}
+
+	if err := s.checkCanChangeCredentials(ctx, q, qUser, req.CurrentPassword); err != nil {
+		return nil, err
+	}
+
+	// authenticator apps belong to the user's identity, and so apply to all of
+	// its organizations
+	if _, err := q.UpdateIdentityAuthenticatorApp(ctx, queries.UpdateIdentityAuthenticatorAppParams{
+		ID:                                  qUser.IdentityID,
 		AuthenticatorAppSecretCiphertext:    qUserAuthenticatorAppChallenge.AuthenticatorAppSecretCiphertext,
 		AuthenticatorAppRecoveryCodeSha256s: recoveryCodeSHA256s,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("update user authenticator app: %w", err)
+	}); err != nil {
+		return nil, fmt.Errorf("update identity authenticator app: %w", err)
 	}
 
 	auditUser, err := s.auditlogStore.GetUser(ctx, tx, qUser.ID)
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/tesseral-labs/tesseral/internal/acceptlanguage"
+	"github.com/tesseral-labs/tesseral/internal/emailtemplates"
+	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+// renderEmail renders the current project's email template of type
+// templateType, localized for the current request.
+func (s *Store) renderEmail(ctx context.Context, qProject queries.Project, templateType string, vars map[string]string) (*emailtemplates.Email, error) {
+	qEmailTemplates, err := s.q.ListEmailTemplatesByType(ctx, queries.ListEmailTemplatesByTypeParams{
+		ProjectID:    qProject.ID,
+		TemplateType: queries.EmailTemplateType(templateType),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list email templates by type: %w", err)
+	}
+
+	var templates []emailtemplates.Template
+	for _, qEmailTemplate := range qEmailTemplates {
+		templates = append(templates, emailtemplates.Template{
+			Locale:   derefOrEmpty(qEmailTemplate.Locale),
+			Subject:  qEmailTemplate.Subject,
+			TextBody: qEmailTemplate.TextBody,
+			HTMLBody: qEmailTemplate.HtmlBody,
+		})
+	}
+
+	qProjectUISettings, err := s.q.GetProjectUISettings(ctx, qProject.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get project ui settings: %w", err)
+	}
+
+	// Emails outlive presigned URLs, so link to the logo by its public user
+	// content URL instead.
+	var logoURL string
+	if s.userContentBaseUrl != "" {
+		logoKey := fmt.Sprintf("vault-ui-settings-v1/%s/logo", idformat.Project.Format(qProject.ID))
+		logoExists, err := s.getUserContentFileExists(ctx, logoKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check if logo file exists: %w", err)
+		}
+		if logoExists {
+			logoURL = fmt.Sprintf("%s/%s", s.userContentBaseUrl, logoKey)
+		}
+	}
+
+	primaryColor := emailtemplates.DefaultPrimaryColor
+	if qProjectUISettings.PrimaryColor != nil {
+		primaryColor = *qProjectUISettings.PrimaryColor
+	}
+
+	vars[emailtemplates.VariableProjectDisplayName] = qProject.DisplayName
+	vars[emailtemplates.VariableLogoURL] = logoURL
+	vars[emailtemplates.VariablePrimaryColor] = primaryColor
+
+	template := emailtemplates.Select(templateType, templates, acceptlanguage.FromContext(ctx))
+	email, err := emailtemplates.Render(template, vars)
+	if err != nil {
+		return nil, fmt.Errorf("render email template: %w", err)
+	}
+
+	return email, nil
+}
+
+func (s *Store) getUserContentFileExists(ctx context.Context, key string) (bool, error) {
+	if _, err := s.s3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: &s.s3UserContentBucketName,
+		Key:    &key,
+	}); err != nil {
+		var notFoundErr *types.NotFound
+		if errors.As(err, &notFoundErr) {
+			return false, nil
+		}
+
+		// Return other errors
+		return false, fmt.Errorf("failed to check if user content file exists: %w", err)
+	}
+
+	return true, nil
+}
@@ -6,11 +6,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/tesseral-labs/tesseral/internal/auditlog"
 	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
 	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
 	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -47,11 +49,19 @@ func (s *Store) logAuditEvent(ctx context.Context, q *queries.Queries, req logAu
 		EventDetails:   eventDetailsBytes,
 	}
 
+	if err := s.chainAuditLogEvent(ctx, q, &qEventParams, eventDetailsBytes); err != nil {
+		return queries.AuditLogEvent{}, err
+	}
+
 	qEvent, err := q.CreateAuditLogEvent(ctx, qEventParams)
 	if err != nil {
 		return queries.AuditLogEvent{}, err
 	}
 
+	if err := s.sendWebhookEvent(ctx, q, qEvent, req.EventDetails); err != nil {
+		return queries.AuditLogEvent{}, fmt.Errorf("send webhook event: %w", err)
+	}
+
 	return qEvent, nil
 }
 
@@ -83,3 +93,74 @@ func parseSessionEventDetails(qSession queries.Session, impersonatorEmail *strin
 		ImpersonatorEmail: derefOrEmpty(impersonatorEmail),
 	}
 }
+
+// chainAuditLogEvent sets the chain sequence and hash of an audit log event
+// about to be created, and advances its project's chain head. The chain head
+// stays locked until q's transaction ends; see package auditlog.
+func (s *Store) chainAuditLogEvent(ctx context.Context, q *queries.Queries, params *queries.CreateAuditLogEventParams, eventDetails []byte) error {
+	qChainHead, err := q.LockAuditLogChainHead(ctx, params.ProjectID)
+	if err != nil {
+		return fmt.Errorf("lock audit log chain head: %w", err)
+	}
+
+	key, keyCiphertext, err := s.auditlogStore.ChainKey(ctx, params.ProjectID, qChainHead.KeyCiphertext)
+	if err != nil {
+		return fmt.Errorf("get audit log chain key: %w", err)
+	}
+
+	sequence := qChainHead.Sequence + 1
+	hash, err := auditlog.ChainHash(key, qChainHead.Hash, sequence, auditlog.ChainEvent{
+		ID:             params.ID,
+		ProjectID:      params.ProjectID,
+		OrganizationID: params.OrganizationID,
+		ActorUserID:    params.ActorUserID,
+		ActorSessionID: params.ActorSessionID,
+		ResourceType:   (*string)(params.ResourceType),
+		ResourceID:     params.ResourceID,
+		EventName:      params.EventName,
+		EventTime:      *params.EventTime,
+		EventDetails:   eventDetails,
+	})
+	if err != nil {
+		return fmt.Errorf("compute audit log chain hash: %w", err)
+	}
+
+	if err := q.UpdateAuditLogChainHead(ctx, queries.UpdateAuditLogChainHeadParams{
+		ProjectID:     params.ProjectID,
+		Sequence:      sequence,
+		Hash:          hash,
+		KeyCiphertext: keyCiphertext,
+	}); err != nil {
+		return fmt.Errorf("update audit log chain head: %w", err)
+	}
+
+	params.ChainSequence = &sequence
+	params.ChainHash = hash
+	return nil
+}
+
+// sendWebhookEvent enqueues the typed webhook event corresponding to an audit
+// log event, if the event catalogue has one.
+func (s *Store) sendWebhookEvent(ctx context.Context, q *queries.Queries, qEvent queries.AuditLogEvent, eventDetails proto.Message) error {
+	event := webhooks.NewEvent(webhooks.NewEventParams{
+		AuditLogEventID: qEvent.ID,
+		ProjectID:       qEvent.ProjectID,
+		OrganizationID:  qEvent.OrganizationID,
+		EventTime:       *qEvent.EventTime,
+		EventDetails:    eventDetails,
+	})
+	if event == nil {
+		return nil
+	}
+
+	msg, err := webhooks.NewEventMessage(event)
+	if err != nil {
+		return fmt.Errorf("create webhook event message: %w", err)
+	}
+
+	if err := s.enqueueWebhookMessage(ctx, q, qEvent.ProjectID, msg); err != nil {
+		return fmt.Errorf("enqueue webhook message: %w", err)
+	}
+
+	return nil
+}
@@ -6,13 +6,15 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
 	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
+	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
 )
 
 func (s *Store) Logout(ctx context.Context, req *frontendv1.LogoutRequest) (*frontendv1.LogoutResponse, error) {
-	_, q, commit, rollback, err := s.tx(ctx)
+	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("start transaction: %w", err)
 	}
@@ -28,11 +30,33 @@ func (s *Store) Logout(ctx context.Context, req *frontendv1.LogoutRequest) (*fro
 		return nil, fmt.Errorf("get session by id: %w", err)
 	}
 
+	auditPreviousSession, err := s.auditlogStore.GetSession(ctx, tx, qSession.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit previous session: %w", err)
+	}
+
 	// Invalidate the session if one exists
 	if err := q.InvalidateSession(ctx, qSession.ID); err != nil {
 		return nil, fmt.Errorf("delete session: %w", err)
 	}
 
+	auditSession, err := s.auditlogStore.GetSession(ctx, tx, qSession.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get audit session: %w", err)
+	}
+
+	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
+		EventName: "tesseral.sessions.revoke",
+		EventDetails: &auditlogv1.RevokeSession{
+			Session:         auditSession,
+			PreviousSession: auditPreviousSession,
+		},
+		ResourceType: queries.AuditLogEventResourceTypeSession,
+		ResourceID:   &qSession.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("create audit log event: %w", err)
+	}
+
 	if err := commit(); err != nil {
 		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
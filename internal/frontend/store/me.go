@@ -54,16 +54,21 @@ func (s *Store) UpdateMe(ctx context.Context, req *frontendv1.UpdateMeRequest) (
 		return nil, fmt.Errorf("create audit log event: %w", err)
 	}
 
-	if err := commit(); err != nil {
-		return nil, fmt.Errorf("commit: %w", err)
-	}
-
 	// send sync user event
-	if err := s.sendSyncUserEvent(ctx, qUpdatedUser); err != nil {
+	if err := s.sendSyncUserEvent(ctx, q, qUpdatedUser); err != nil {
 		return nil, fmt.Errorf("send sync user event: %w", err)
 	}
 
+	qIdentity, err := q.GetIdentityByID(ctx, qUpdatedUser.IdentityID)
+	if err != nil {
+		return nil, fmt.Errorf("get identity by id: %w", err)
+	}
+
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
 	return &frontendv1.UpdateMeResponse{
-		User: parseUser(qUpdatedUser),
+		User: parseUser(qUpdatedUser, qIdentity),
 	}, nil
 }
@@ -4,16 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log/slog"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/svix/svix-webhooks/go/models"
 	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
 	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
 	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -41,6 +41,78 @@ func (s *Store) GetOrganization(ctx context.Context, req *frontendv1.GetOrganiza
 	return &frontendv1.GetOrganizationResponse{Organization: parseOrganization(qProject, qOrganization)}, nil
 }
 
+func (s *Store) ListOrganizationDescendants(ctx context.Context, req *frontendv1.ListOrganizationDescendantsRequest) (*frontendv1.ListOrganizationDescendantsResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	var startID uuid.UUID
+	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
+		return nil, fmt.Errorf("unmarshal page token: %w", err)
+	}
+
+	qProject, err := q.GetProjectByID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get project by id: %w", err)
+	}
+
+	limit := 10
+	qOrganizations, err := q.ListOrganizationDescendants(ctx, queries.ListOrganizationDescendantsParams{
+		OrganizationID: authn.OrganizationID(ctx),
+		StartID:        startID,
+		Limit:          int32(limit + 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list organization descendants: %w", err)
+	}
+
+	var organizations []*frontendv1.Organization
+	for _, qOrganization := range qOrganizations {
+		organizations = append(organizations, parseOrganization(qProject, qOrganization))
+	}
+
+	var nextPageToken string
+	if len(organizations) == limit+1 {
+		nextPageToken = s.pageEncoder.Marshal(qOrganizations[limit].ID)
+		organizations = organizations[:limit]
+	}
+
+	return &frontendv1.ListOrganizationDescendantsResponse{
+		Organizations: organizations,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// AuthorizeDescendantOrganization returns an error unless the current user
+// may manage the organization with the given ID on behalf of the current
+// organization. Owners of an organization may manage all of its descendants.
+func (s *Store) AuthorizeDescendantOrganization(ctx context.Context, organizationID string) error {
+	orgID, err := idformat.Organization.Parse(organizationID)
+	if err != nil {
+		return apierror.NewInvalidArgumentError("invalid organization id", fmt.Errorf("parse organization id: %w", err))
+	}
+
+	if err := s.validateIsOwner(ctx); err != nil {
+		return err
+	}
+
+	isDescendant, err := s.q.IsOrganizationDescendant(ctx, queries.IsOrganizationDescendantParams{
+		OrganizationID:         orgID,
+		AncestorOrganizationID: authn.OrganizationID(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("is organization descendant: %w", err)
+	}
+
+	if !isDescendant {
+		return apierror.NewPermissionDeniedError("organization is not a descendant of the current organization", fmt.Errorf("organization is not a descendant of the current organization"))
+	}
+
+	return nil
+}
+
 func (s *Store) UpdateOrganization(ctx context.Context, req *frontendv1.UpdateOrganizationRequest) (*frontendv1.UpdateOrganizationResponse, error) {
 	tx, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
@@ -184,48 +256,41 @@ func (s *Store) UpdateOrganization(ctx context.Context, req *frontendv1.UpdateOr
 		return nil, fmt.Errorf("create audit log event: %w", err)
 	}
 
+	// send sync organization event
+	if err := s.sendSyncOrganizationEvent(ctx, q, qUpdatedOrg); err != nil {
+		return nil, fmt.Errorf("send sync organization event: %w", err)
+	}
+
 	// Commit the transaction
 	if err := commit(); err != nil {
 		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
 
-	// send sync organization event
-	if err := s.sendSyncOrganizationEvent(ctx, qUpdatedOrg); err != nil {
-		return nil, fmt.Errorf("send sync organization event: %w", err)
-	}
-
 	return &frontendv1.UpdateOrganizationResponse{
 		Organization: parseOrganization(qProject, qUpdatedOrg),
 	}, nil
 }
 
-func (s *Store) sendSyncOrganizationEvent(ctx context.Context, qOrg queries.Organization) error {
-	qProjectWebhookSettings, err := s.q.GetProjectWebhookSettings(ctx, authn.ProjectID(ctx))
-	if err != nil {
-		// We want to ignore this error if the project does not have webhook settings
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil
-		}
-		return fmt.Errorf("get project by id: %w", err)
-	}
-
-	message, err := s.svixClient.Message.Create(ctx, qProjectWebhookSettings.AppID, models.MessageIn{
+func (s *Store) sendSyncOrganizationEvent(ctx context.Context, q *queries.Queries, qOrg queries.Organization) error {
+	if err := s.enqueueWebhookMessage(ctx, q, authn.ProjectID(ctx), webhooks.Message{
 		EventType: "sync.organization",
 		Payload: map[string]interface{}{
 			"type":           "sync.organization",
 			"organizationId": idformat.Organization.Format(qOrg.ID),
 		},
-	}, nil)
-	if err != nil {
-		return fmt.Errorf("create message: %w", err)
+	}); err != nil {
+		return fmt.Errorf("enqueue webhook message: %w", err)
 	}
 
-	slog.InfoContext(ctx, "svix_message_created", "message_id", message.Id, "event_type", message.EventType, "organization_id", idformat.Organization.Format(qOrg.ID))
-
 	return nil
 }
 
 func parseOrganization(qProject queries.Project, qOrg queries.Organization) *frontendv1.Organization {
+	var parentOrganizationID string
+	if qOrg.ParentOrganizationID != nil {
+		parentOrganizationID = idformat.Organization.Format(*qOrg.ParentOrganizationID)
+	}
+
 	return &frontendv1.Organization{
 		Id:                        idformat.Organization.Format(qOrg.ID),
 		DisplayName:               qOrg.DisplayName,
@@ -246,6 +311,8 @@ func parseOrganization(qProject queries.Project, qOrg queries.Organization) *fro
 		CustomRolesEnabled:        qOrg.CustomRolesEnabled,
 		ApiKeysEnabled:            qOrg.ApiKeysEnabled && qProject.ApiKeysEnabled && qProject.EntitledBackendApiKeys,
 		ScimEnabled:               qOrg.ScimEnabled,
+		ParentOrganizationId:      parentOrganizationID,
+		PublicMetadata:            parseMetadata(qOrg.PublicMetadata),
 	}
 }
 
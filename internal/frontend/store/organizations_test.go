@@ -111,3 +111,42 @@ func TestUpdateOrganization_UpdatesFields(t *testing.T) {
 	require.True(t, updateUnchangedResp.Organization.GetRequireMfa())
 
 }
+
+func TestAuthorizeDescendantOrganization(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{DisplayName: "parent"})
+
+	newChildOrganization := func(parentOrganizationID uuid.UUID) string {
+		organizationID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{DisplayName: "child"})
+		organizationUUID, err := idformat.Organization.Parse(organizationID)
+		require.NoError(t, err)
+
+		_, err = u.Environment.DB.Exec(t.Context(), `UPDATE organizations SET parent_organization_id = $1 WHERE id = $2`, parentOrganizationID, uuid.UUID(organizationUUID))
+		require.NoError(t, err)
+		return organizationID
+	}
+
+	childOrganizationID := newChildOrganization(authn.OrganizationID(ctx))
+	childOrganizationUUID, err := idformat.Organization.Parse(childOrganizationID)
+	require.NoError(t, err)
+	grandchildOrganizationID := newChildOrganization(childOrganizationUUID)
+	unrelatedOrganizationID := u.Environment.NewOrganization(t, u.ProjectID, &backendv1.Organization{DisplayName: "unrelated"})
+
+	require.NoError(t, u.Store.AuthorizeDescendantOrganization(ctx, childOrganizationID))
+	require.NoError(t, u.Store.AuthorizeDescendantOrganization(ctx, grandchildOrganizationID))
+
+	var connectErr *connect.Error
+	err = u.Store.AuthorizeDescendantOrganization(ctx, unrelatedOrganizationID)
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodePermissionDenied, connectErr.Code())
+
+	// only owners manage descendants
+	_, err = u.Environment.DB.Exec(t.Context(), `UPDATE users SET is_owner = false WHERE id = $1`, authn.UserID(ctx))
+	require.NoError(t, err)
+
+	err = u.Store.AuthorizeDescendantOrganization(ctx, childOrganizationID)
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodePermissionDenied, connectErr.Code())
+}
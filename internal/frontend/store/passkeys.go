@@ -26,6 +26,11 @@ func (s *Store) ListMyPasskeys(ctx context.Context, req *frontendv1.ListMyPasske
 	}
 	defer rollback()
 
+	qUser, err := q.GetUserByID(ctx, authn.UserID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get user by id: %w", err)
+	}
+
 	var startID uuid.UUID
 	if err := s.pageEncoder.Unmarshal(req.PageToken, &startID); err != nil {
 		return nil, fmt.Errorf("unmarshal page token: %w", err)
@@ -33,9 +38,9 @@ func (s *Store) ListMyPasskeys(ctx context.Context, req *frontendv1.ListMyPasske
 
 	limit := 10
 	qPasskeys, err := q.ListPasskeys(ctx, queries.ListPasskeysParams{
-		UserID: authn.UserID(ctx),
-		ID:     startID,
-		Limit:  int32(limit + 1),
+		IdentityID: qUser.IdentityID,
+		ID:         startID,
+		Limit:      int32(limit + 1),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("list passkeys: %w", err)
@@ -70,16 +75,21 @@ func (s *Store) DeleteMyPasskey(ctx context.Context, req *frontendv1.DeleteMyPas
 		return nil, fmt.Errorf("parse passkey id: %w", err)
 	}
 
-	qPasskey, err := q.GetUserPasskey(ctx, queries.GetUserPasskeyParams{
-		UserID: authn.UserID(ctx),
-		ID:     passkeyID,
+	qUser, err := q.GetUserByID(ctx, authn.UserID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get user by id: %w", err)
+	}
+
+	qPasskey, err := q.GetIdentityPasskey(ctx, queries.GetIdentityPasskeyParams{
+		IdentityID: qUser.IdentityID,
+		ID:         passkeyID,
 	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, apierror.NewNotFoundError("passkey not found", fmt.Errorf("get user passkey: %w", err))
+			return nil, apierror.NewNotFoundError("passkey not found", fmt.Errorf("get identity passkey: %w", err))
 		}
 
-		return nil, fmt.Errorf("get user passkey: %w", err)
+		return nil, fmt.Errorf("get identity passkey: %w", err)
 	}
 
 	auditPasskey, err := s.auditlogStore.GetPasskey(ctx, tx, qPasskey.ID)
@@ -159,9 +169,19 @@ func (s *Store) RegisterPasskey(ctx context.Context, req *frontendv1.RegisterPas
 		return nil, fmt.Errorf("marshal public key: %w", err)
 	}
 
+	qUser, err := q.GetUserByID(ctx, authn.UserID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get user by id: %w", err)
+	}
+
+	if err := s.checkCanChangeCredentials(ctx, q, qUser, req.CurrentPassword); err != nil {
+		return nil, err
+	}
+
 	qPasskey, err := q.CreatePasskey(ctx, queries.CreatePasskeyParams{
 		ID:           uuid.New(),
-		UserID:       authn.UserID(ctx),
+		IdentityID:   qUser.IdentityID,
+		UserID:       &qUser.ID,
 		CredentialID: cred.ID,
 		PublicKey:    publicKey,
 		Aaguid:       cred.AAGUID,
@@ -197,9 +217,14 @@ func (s *Store) RegisterPasskey(ctx context.Context, req *frontendv1.RegisterPas
 }
 
 func parsePasskey(qPasskey queries.Passkey) *frontendv1.Passkey {
+	var userID string
+	if qPasskey.UserID != nil {
+		userID = idformat.User.Format(*qPasskey.UserID)
+	}
+
 	return &frontendv1.Passkey{
 		Id:           idformat.Passkey.Format(qPasskey.ID),
-		UserId:       idformat.User.Format(qPasskey.UserID),
+		UserId:       userID,
 		CreateTime:   timestamppb.New(*qPasskey.CreateTime),
 		UpdateTime:   timestamppb.New(*qPasskey.UpdateTime),
 		Disabled:     qPasskey.Disabled,
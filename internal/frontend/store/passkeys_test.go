@@ -54,8 +54,8 @@ func TestListMyPasskeys_Pagination(t *testing.T) {
 	// Create 3 passkeys
 	for range 15 {
 		_, err := u.Environment.DB.Exec(ctx, `
-		INSERT INTO passkeys (id, user_id, credential_id, public_key, aaguid, rp_id)
-    		VALUES (gen_random_uuid(), $1, ''::bytea, ''::bytea, '', '')
+		INSERT INTO passkeys (id, identity_id, user_id, credential_id, public_key, aaguid, rp_id)
+    		VALUES (gen_random_uuid(), (SELECT identity_id FROM users WHERE id = $1), $1, ''::bytea, ''::bytea, '', '')
 		`,
 			authn.UserID(ctx))
 		require.NoError(t, err)
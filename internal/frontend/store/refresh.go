@@ -76,7 +76,7 @@ func (s *Store) CreateRefreshAuditLogEvent(ctx context.Context, accessToken stri
 	// from the session details. As such, we can't use the logAuditEvent() fuction
 	// directly, so we're calling the CreateAuditLogEvent query directly.
 	resourceType := queries.AuditLogEventResourceTypeSession
-	if _, err := q.CreateAuditLogEvent(ctx, queries.CreateAuditLogEventParams{
+	qEventParams := queries.CreateAuditLogEventParams{
 		ID:             eventID,
 		ProjectID:      projectID,
 		OrganizationID: (*uuid.UUID)(&orgID),
@@ -87,7 +87,13 @@ func (s *Store) CreateRefreshAuditLogEvent(ctx context.Context, accessToken stri
 		EventName:      "tesseral.sessions.refresh",
 		EventTime:      &eventTime,
 		EventDetails:   eventDetailsBytes,
-	}); err != nil {
+	}
+
+	if err := s.chainAuditLogEvent(ctx, q, &qEventParams, eventDetailsBytes); err != nil {
+		return fmt.Errorf("chain audit log event: %w", err)
+	}
+
+	if _, err := q.CreateAuditLogEvent(ctx, qEventParams); err != nil {
 		return fmt.Errorf("log audit event: %w", err)
 	}
 
@@ -242,6 +242,10 @@ func (s *Store) UpdateRole(ctx context.Context, req *frontendv1.UpdateRoleReques
 		return nil, fmt.Errorf("get role: %w", err)
 	}
 
+	if qRole.RoleTemplateID != nil {
+		return nil, apierror.NewFailedPreconditionError("roles based on a role template cannot be modified", fmt.Errorf("role is based on a role template"))
+	}
+
 	qActions, err := q.GetActions(ctx, authn.ProjectID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get actions: %w", err)
@@ -364,6 +368,10 @@ func (s *Store) DeleteRole(ctx context.Context, req *frontendv1.DeleteRoleReques
 		return nil, fmt.Errorf("get role: %w", err)
 	}
 
+	if qRole.RoleTemplateID != nil {
+		return nil, apierror.NewFailedPreconditionError("roles based on a role template cannot be deleted", fmt.Errorf("role is based on a role template"))
+	}
+
 	auditRole, err := s.auditlogStore.GetRole(ctx, tx, qRole.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get audit role: %w", err)
@@ -398,6 +406,11 @@ func parseRole(qRole queries.Role, qRoleActions []queries.RoleAction, qActions [
 		orgID = idformat.Organization.Format(*qRole.OrganizationID)
 	}
 
+	var roleTemplateID string
+	if qRole.RoleTemplateID != nil {
+		roleTemplateID = idformat.RoleTemplate.Format(*qRole.RoleTemplateID)
+	}
+
 	var actions []string
 	for _, qRoleAction := range qRoleActions {
 		if qRoleAction.RoleID != qRole.ID {
@@ -420,5 +433,7 @@ func parseRole(qRole queries.Role, qRoleActions []queries.RoleAction, qActions [
 		DisplayName:    qRole.DisplayName,
 		Description:    qRole.Description,
 		Actions:        actions,
+		Requestable:    qRole.Requestable,
+		RoleTemplateId: roleTemplateID,
 	}
 }
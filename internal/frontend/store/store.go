@@ -7,16 +7,18 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/kms"
-	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	svix "github.com/svix/svix-webhooks/go"
 	auditlogstore "github.com/tesseral-labs/tesseral/internal/auditlog/store"
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
 	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/hibp"
 	"github.com/tesseral-labs/tesseral/internal/oidcclient"
 	"github.com/tesseral-labs/tesseral/internal/pagetoken"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -28,12 +30,14 @@ type Store struct {
 	intermediateSessionSigningKeyKMSKeyID string
 	oidcClientSecretsKMSKeyID             string
 	kms                                   *kms.Client
-	ses                                   *sesv2.Client
+	emailSender                           emailsender.Sender
+	s3                                    *s3.Client
+	s3UserContentBucketName               string
+	userContentBaseUrl                    string
 	pageEncoder                           pagetoken.Encoder
 	q                                     *queries.Queries
 	sessionSigningKeyKmsKeyID             string
 	authenticatorAppSecretsKMSKeyID       string
-	svixClient                            *svix.Svix
 	auditlogStore                         *auditlogstore.Store
 	oidc                                  *oidcclient.Client
 }
@@ -45,11 +49,13 @@ type NewStoreParams struct {
 	IntermediateSessionSigningKeyKMSKeyID string
 	OIDCClientSecretsKMSKeyID             string
 	KMS                                   *kms.Client
-	SES                                   *sesv2.Client
+	EmailSender                           emailsender.Sender
+	S3                                    *s3.Client
+	S3UserContentBucketName               string
+	UserContentBaseUrl                    string
 	PageEncoder                           pagetoken.Encoder
 	SessionSigningKeyKmsKeyID             string
 	AuthenticatorAppSecretsKMSKeyID       string
-	SvixClient                            *svix.Svix
 	AuditlogStore                         *auditlogstore.Store
 	OIDCClient                            *oidcclient.Client
 }
@@ -65,12 +71,14 @@ func New(p NewStoreParams) *Store {
 		intermediateSessionSigningKeyKMSKeyID: p.IntermediateSessionSigningKeyKMSKeyID,
 		oidcClientSecretsKMSKeyID:             p.OIDCClientSecretsKMSKeyID,
 		kms:                                   p.KMS,
-		ses:                                   p.SES,
+		emailSender:                           p.EmailSender,
+		s3:                                    p.S3,
+		s3UserContentBucketName:               p.S3UserContentBucketName,
+		userContentBaseUrl:                    p.UserContentBaseUrl,
 		pageEncoder:                           p.PageEncoder,
 		q:                                     queries.New(p.DB),
 		sessionSigningKeyKmsKeyID:             p.SessionSigningKeyKmsKeyID,
 		authenticatorAppSecretsKMSKeyID:       p.AuthenticatorAppSecretsKMSKeyID,
-		svixClient:                            p.SvixClient,
 		auditlogStore:                         p.AuditlogStore,
 		oidc:                                  p.OIDCClient,
 	}
@@ -111,3 +119,11 @@ func refOrNil[T comparable](t T) *T {
 	}
 	return &t
 }
+
+func parseMetadata(metadata []byte) *structpb.Struct {
+	var s structpb.Struct
+	if err := protojson.Unmarshal(metadata, &s); err != nil {
+		panic(fmt.Errorf("unmarshal metadata: %w", err))
+	}
+	return &s
+}
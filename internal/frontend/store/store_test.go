@@ -5,12 +5,12 @@ import (
 	"net/http"
 	"testing"
 
-	"github.com/google/uuid"
+	auditlogstore "github.com/tesseral-labs/tesseral/internal/auditlog/store"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	commonstore "github.com/tesseral-labs/tesseral/internal/common/store"
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
 	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
 	"github.com/tesseral-labs/tesseral/internal/oidcclient"
-	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 	"github.com/tesseral-labs/tesseral/internal/storetesting"
 )
 
@@ -31,9 +31,11 @@ type testUtil struct {
 	Common      *commonstore.Store
 	Environment *storetesting.Environment
 	ProjectID   string
+	EmailSender *emailsender.MemorySender
 }
 
 func newTestUtil(t *testing.T) *testUtil {
+	emailSender := &emailsender.MemorySender{}
 	store := New(NewStoreParams{
 		DB:                              environment.DB,
 		KMS:                             environment.KMS.Client,
@@ -43,6 +45,11 @@ func newTestUtil(t *testing.T) *testUtil {
 		AuthenticatorAppSecretsKMSKeyID: environment.KMS.AuthenticatorAppSecretsKMSKeyID,
 		OIDCClientSecretsKMSKeyID:       environment.KMS.OIDCClientSecretsKMSKeyID,
 		OIDCClient:                      &oidcclient.Client{HTTPClient: http.DefaultClient},
+		EmailSender:                     emailSender,
+		AuditlogStore: auditlogstore.New(auditlogstore.NewStoreParams{
+			KMS:                       environment.KMS.Client,
+			AuditLogChainKeysKMSKeyID: environment.KMS.AuditLogChainKeysKMSKeyID,
+		}),
 	})
 	commonStore := commonstore.New(commonstore.NewStoreParams{
 		AppAuthRootDomain:         environment.ConsoleDomain,
@@ -57,6 +64,7 @@ func newTestUtil(t *testing.T) *testUtil {
 		Common:      commonStore,
 		Environment: environment,
 		ProjectID:   projectID,
+		EmailSender: emailSender,
 	}
 }
 
@@ -65,12 +73,13 @@ func (u *testUtil) NewOrganizationContext(t *testing.T, organization *backendv1.
 	userID := u.Environment.NewUser(t, organizationID, &backendv1.User{
 		Owner: refOrNil(true),
 	})
+	sessionID, _ := u.Environment.NewSession(t, userID)
 
 	ctx := authn.NewContext(t.Context(), authn.ContextData{
 		ProjectID:      u.ProjectID,
 		OrganizationID: organizationID,
 		UserID:         userID,
-		SessionID:      idformat.Session.Format(uuid.New()),
+		SessionID:      sessionID,
 	})
 
 	return ctx
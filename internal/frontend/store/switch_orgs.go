@@ -23,8 +23,8 @@ func (s *Store) ListSwitchableOrganizations(ctx context.Context, req *frontendv1
 	}
 
 	qOrgs, err := q.ListSwitchableOrganizations(ctx, queries.ListSwitchableOrganizationsParams{
-		ProjectID: authn.ProjectID(ctx),
-		Email:     qUser.Email,
+		ProjectID:  authn.ProjectID(ctx),
+		IdentityID: qUser.IdentityID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("list switchable organizations: %w", err)
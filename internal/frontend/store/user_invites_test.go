@@ -32,6 +32,30 @@ func TestCreateUserInvite_Success(t *testing.T) {
 	require.Equal(t, "invitee@example.com", resp.UserInvite.Email)
 }
 
+func TestCreateUserInvite_SendEmail(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{
+		DisplayName: "Test Org",
+	})
+
+	_, err := u.Store.CreateUserInvite(ctx, &frontendv1.CreateUserInviteRequest{
+		UserInvite: &frontendv1.UserInvite{
+			Email: "invitee@example.com",
+			Owner: false,
+		},
+		SendEmail: true,
+	})
+	require.NoError(t, err)
+
+	messages := u.EmailSender.Messages()
+	require.Len(t, messages, 1)
+	require.Equal(t, []string{"invitee@example.com"}, messages[0].To)
+	require.Contains(t, messages[0].Subject, "You've been invited to join Test Org")
+	require.Contains(t, messages[0].TextBody, "/signup")
+}
+
 func TestCreateUserInvite_SelfServeUserCreationDisabled(t *testing.T) {
 	t.Parallel()
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -13,6 +14,7 @@ import (
 	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
 	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *Store) ListUserRoleAssignments(ctx context.Context, req *frontendv1.ListUserRoleAssignmentsRequest) (*frontendv1.ListUserRoleAssignmentsResponse, error) {
@@ -188,6 +190,11 @@ func (s *Store) CreateUserRoleAssignment(ctx context.Context, req *frontendv1.Cr
 		return nil, apierror.NewInvalidArgumentError("invalid user id", fmt.Errorf("parse user id: %w", err))
 	}
 
+	expireTime, err := parseRoleAssignmentExpireTime(req.UserRoleAssignment.ExpireTime)
+	if err != nil {
+		return nil, err
+	}
+
 	// ensure both role and user belong to project/organization
 	orgID := authn.OrganizationID(ctx)
 	if _, err := q.GetRole(ctx, queries.GetRoleParams{
@@ -212,9 +219,11 @@ func (s *Store) CreateUserRoleAssignment(ctx context.Context, req *frontendv1.Cr
 	}
 
 	if err := q.UpsertUserRoleAssignment(ctx, queries.UpsertUserRoleAssignmentParams{
-		ID:     uuid.New(),
-		RoleID: roleID,
-		UserID: userID,
+		ID:                   uuid.New(),
+		RoleID:               roleID,
+		UserID:               userID,
+		ExpireTime:           expireTime,
+		InheritToDescendants: &req.UserRoleAssignment.InheritToDescendants,
 	}); err != nil {
 		return nil, fmt.Errorf("upsert user role assignment: %w", err)
 	}
@@ -308,8 +317,24 @@ func (s *Store) DeleteUserRoleAssignment(ctx context.Context, req *frontendv1.De
 
 func parseUserRoleAssignment(qUserRoleAssignment queries.UserRoleAssignment) *frontendv1.UserRoleAssignment {
 	return &frontendv1.UserRoleAssignment{
-		Id:     idformat.UserRoleAssignment.Format(qUserRoleAssignment.ID),
-		RoleId: idformat.Role.Format(qUserRoleAssignment.RoleID),
-		UserId: idformat.User.Format(qUserRoleAssignment.UserID),
+		Id:                   idformat.UserRoleAssignment.Format(qUserRoleAssignment.ID),
+		RoleId:               idformat.Role.Format(qUserRoleAssignment.RoleID),
+		UserId:               idformat.User.Format(qUserRoleAssignment.UserID),
+		ExpireTime:           timestampOrNil(qUserRoleAssignment.ExpireTime),
+		InheritToDescendants: qUserRoleAssignment.InheritToDescendants,
+	}
+}
+
+// parseRoleAssignmentExpireTime validates an optional role assignment
+// expire_time, which must be in the future if provided.
+func parseRoleAssignmentExpireTime(expireTime *timestamppb.Timestamp) (*time.Time, error) {
+	if expireTime == nil {
+		return nil, nil
+	}
+
+	t := expireTime.AsTime()
+	if !t.After(time.Now()) {
+		return nil, apierror.NewInvalidArgumentError("expire_time must be in the future", fmt.Errorf("expire_time must be in the future"))
 	}
+	return &t, nil
 }
@@ -4,22 +4,27 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/svix/svix-webhooks/go/models"
 	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
 	"github.com/tesseral-labs/tesseral/internal/bcryptcost"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
 	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
 	"github.com/tesseral-labs/tesseral/internal/frontend/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/passwordhash"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
 	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// credentialChangeMaxSessionAge is how recently a user must have logged in to
+// change their credentials without presenting their current password.
+const credentialChangeMaxSessionAge = 10 * time.Minute
+
 func (s *Store) SetUserPassword(ctx context.Context, req *frontendv1.SetPasswordRequest) (*frontendv1.SetPasswordResponse, error) {
 	// Check if the password is compromised.
 	pwned, err := s.hibp.Pwned(ctx, req.Password)
@@ -41,9 +46,20 @@ func (s *Store) SetUserPassword(ctx context.Context, req *frontendv1.SetPassword
 		return nil, apierror.NewFailedPreconditionError("could not generate password hash", fmt.Errorf("generate bcrypt hash: %w", err))
 	}
 
+	qUser, err := q.GetUserByID(ctx, authn.UserID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get user by id: %w", err)
+	}
+
+	if err := s.checkCanChangeCredentials(ctx, q, qUser, req.CurrentPassword); err != nil {
+		return nil, err
+	}
+
+	// passwords belong to the user's identity, and so apply to all of its
+	// organizations
 	passwordBcrypt := string(passwordBcryptBytes)
 	if _, err = q.SetPassword(ctx, queries.SetPasswordParams{
-		ID:             authn.UserID(ctx),
+		ID:             qUser.IdentityID,
 		PasswordBcrypt: &passwordBcrypt,
 	}); err != nil {
 		return nil, fmt.Errorf("set password: %w", err)
@@ -56,6 +72,66 @@ func (s *Store) SetUserPassword(ctx context.Context, req *frontendv1.SetPassword
 	return &frontendv1.SetPasswordResponse{}, nil
 }
 
+// checkCanChangeCredentials returns an error unless the user has proven they
+// still control their identity, either by presenting its current password or
+// by having logged in recently without impersonation.
+//
+// Credentials belong to the identity, and so apply to all of its
+// organizations. Without this check, a stolen or long-lived session in one
+// organization could take over the others.
+func (s *Store) checkCanChangeCredentials(ctx context.Context, q *queries.Queries, qUser queries.User, currentPassword string) error {
+	if currentPassword != "" {
+		qIdentity, err := q.GetIdentityByID(ctx, qUser.IdentityID)
+		if err != nil {
+			return fmt.Errorf("get identity by id: %w", err)
+		}
+
+		if qIdentity.PasswordLockoutExpireTime != nil && qIdentity.PasswordLockoutExpireTime.After(time.Now()) {
+			return apierror.NewFailedPreconditionError("too many password attempts; user is temporarily locked out", nil)
+		}
+
+		if err := matchPassword(qIdentity, currentPassword); err != nil {
+			return apierror.NewIncorrectPasswordError("incorrect current password", fmt.Errorf("match password: %w", err))
+		}
+
+		return nil
+	}
+
+	qSession, err := q.GetSessionByID(ctx, authn.SessionID(ctx))
+	if err != nil {
+		return fmt.Errorf("get session by id: %w", err)
+	}
+
+	if qSession.ImpersonatorUserID != nil || qSession.CreateTime.Before(time.Now().Add(-credentialChangeMaxSessionAge)) {
+		return apierror.NewFailedPreconditionError("current password or a recent login is required to change credentials", nil)
+	}
+
+	return nil
+}
+
+// matchPassword returns an error if password does not match the identity's
+// password, which may be a bcrypt hash or a hash imported from another
+// provider.
+func matchPassword(qIdentity queries.Identity, password string) error {
+	if qIdentity.PasswordBcrypt != nil {
+		return bcrypt.CompareHashAndPassword([]byte(*qIdentity.PasswordBcrypt), []byte(password))
+	}
+
+	if qIdentity.ImportedPasswordHash == nil {
+		return fmt.Errorf("identity does not have password configured")
+	}
+
+	ok, err := passwordhash.Verify(*qIdentity.ImportedPasswordHash, password)
+	if err != nil {
+		return fmt.Errorf("verify imported password hash: %w", err)
+	}
+	if !ok {
+		return bcrypt.ErrMismatchedHashAndPassword
+	}
+
+	return nil
+}
+
 func (s *Store) ListUsers(ctx context.Context, req *frontendv1.ListUsersRequest) (*frontendv1.ListUsersResponse, error) {
 	_, q, _, rollback, err := s.tx(ctx)
 	if err != nil {
@@ -80,7 +156,12 @@ func (s *Store) ListUsers(ctx context.Context, req *frontendv1.ListUsersRequest)
 
 	var users []*frontendv1.User
 	for _, qUser := range qUsers {
-		users = append(users, parseUser(qUser))
+		qIdentity, err := q.GetIdentityByID(ctx, qUser.IdentityID)
+		if err != nil {
+			return nil, fmt.Errorf("get identity by id: %w", err)
+		}
+
+		users = append(users, parseUser(qUser, qIdentity))
 	}
 
 	var nextPageToken string
@@ -119,7 +200,12 @@ func (s *Store) GetUser(ctx context.Context, req *frontendv1.GetUserRequest) (*f
 		return nil, fmt.Errorf("get user: %w", err)
 	}
 
-	return &frontendv1.GetUserResponse{User: parseUser(qUser)}, nil
+	qIdentity, err := q.GetIdentityByID(ctx, qUser.IdentityID)
+	if err != nil {
+		return nil, fmt.Errorf("get identity by id: %w", err)
+	}
+
+	return &frontendv1.GetUserResponse{User: parseUser(qUser, qIdentity)}, nil
 }
 
 func (s *Store) UpdateUser(ctx context.Context, req *frontendv1.UpdateUserRequest) (*frontendv1.UpdateUserResponse, error) {
@@ -176,6 +262,11 @@ func (s *Store) UpdateUser(ctx context.Context, req *frontendv1.UpdateUserReques
 		return nil, fmt.Errorf("update user: %w", err)
 	}
 
+	qIdentity, err := q.GetIdentityByID(ctx, qUpdatedUser.IdentityID)
+	if err != nil {
+		return nil, fmt.Errorf("get identity by id: %w", err)
+	}
+
 	auditUser, err := s.auditlogStore.GetUser(ctx, tx, qUpdatedUser.ID)
 	if err != nil {
 		return nil, fmt.Errorf("get audit user: %w", err)
@@ -193,17 +284,17 @@ func (s *Store) UpdateUser(ctx context.Context, req *frontendv1.UpdateUserReques
 		return nil, fmt.Errorf("create audit log event: %w", err)
 	}
 
+	// send sync user event
+	if err := s.sendSyncUserEvent(ctx, q, qUpdatedUser); err != nil {
+		return nil, fmt.Errorf("send sync user event: %w", err)
+	}
+
 	// Commit the transaction.
 	if err := commit(); err != nil {
 		return nil, fmt.Errorf("commit transaction: %w", err)
 	}
 
-	// send sync user event
-	if err := s.sendSyncUserEvent(ctx, qUpdatedUser); err != nil {
-		return nil, fmt.Errorf("send sync user event: %w", err)
-	}
-
-	return &frontendv1.UpdateUserResponse{User: parseUser(qUpdatedUser)}, nil
+	return &frontendv1.UpdateUserResponse{User: parseUser(qUpdatedUser, qIdentity)}, nil
 }
 
 func (s *Store) DeleteUser(ctx context.Context, req *frontendv1.DeleteUserRequest) (*frontendv1.DeleteUserResponse, error) {
@@ -247,6 +338,10 @@ func (s *Store) DeleteUser(ctx context.Context, req *frontendv1.DeleteUserReques
 		return nil, fmt.Errorf("delete user: %w", err)
 	}
 
+	if err := q.DeleteIdentityIfUnused(ctx, qUser.IdentityID); err != nil {
+		return nil, fmt.Errorf("delete identity if unused: %w", err)
+	}
+
 	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
 		EventName: "tesseral.users.delete",
 		EventDetails: &auditlogv1.DeleteUser{
@@ -258,45 +353,33 @@ func (s *Store) DeleteUser(ctx context.Context, req *frontendv1.DeleteUserReques
 		return nil, fmt.Errorf("create audit log event: %w", err)
 	}
 
-	if err := commit(); err != nil {
-		return nil, fmt.Errorf("commit transaction: %w", err)
-	}
-
 	// send sync user event
-	if err := s.sendSyncUserEvent(ctx, qUser); err != nil {
+	if err := s.sendSyncUserEvent(ctx, q, qUser); err != nil {
 		return nil, fmt.Errorf("send sync user event: %w", err)
 	}
 
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit transaction: %w", err)
+	}
+
 	return &frontendv1.DeleteUserResponse{}, nil
 }
 
-func (s *Store) sendSyncUserEvent(ctx context.Context, qUser queries.User) error {
-	qProjectWebhookSettings, err := s.q.GetProjectWebhookSettings(ctx, authn.ProjectID(ctx))
-	if err != nil {
-		// We want to ignore this error if the project does not have webhook settings
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil
-		}
-		return fmt.Errorf("get project by id: %w", err)
-	}
-
-	message, err := s.svixClient.Message.Create(ctx, qProjectWebhookSettings.AppID, models.MessageIn{
+func (s *Store) sendSyncUserEvent(ctx context.Context, q *queries.Queries, qUser queries.User) error {
+	if err := s.enqueueWebhookMessage(ctx, q, authn.ProjectID(ctx), webhooks.Message{
 		EventType: "sync.user",
 		Payload: map[string]interface{}{
 			"type":   "sync.user",
 			"userId": idformat.User.Format(qUser.ID),
 		},
-	}, nil)
-	if err != nil {
-		return fmt.Errorf("create message: %w", err)
+	}); err != nil {
+		return fmt.Errorf("enqueue webhook message: %w", err)
 	}
 
-	slog.InfoContext(ctx, "svix_message_created", "message_id", message.Id, "event_type", message.EventType, "user_id", idformat.User.Format(qUser.ID))
-
 	return nil
 }
 
-func parseUser(qUser queries.User) *frontendv1.User {
+func parseUser(qUser queries.User, qIdentity queries.Identity) *frontendv1.User {
 	return &frontendv1.User{
 		Id:                  idformat.User.Format(qUser.ID),
 		CreateTime:          timestamppb.New(*qUser.CreateTime),
@@ -306,8 +389,9 @@ func parseUser(qUser queries.User) *frontendv1.User {
 		GoogleUserId:        derefOrEmpty(qUser.GoogleUserID),
 		MicrosoftUserId:     derefOrEmpty(qUser.MicrosoftUserID),
 		GithubUserId:        derefOrEmpty(qUser.GithubUserID),
-		HasAuthenticatorApp: qUser.AuthenticatorAppSecretCiphertext != nil,
+		HasAuthenticatorApp: qIdentity.AuthenticatorAppSecretCiphertext != nil,
 		DisplayName:         qUser.DisplayName,
 		ProfilePictureUrl:   qUser.ProfilePictureUrl,
+		PublicMetadata:      parseMetadata(qUser.PublicMetadata),
 	}
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/tesseral-labs/tesseral/internal/frontend/authn"
 	frontendv1 "github.com/tesseral-labs/tesseral/internal/frontend/gen/tesseral/frontend/v1"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestListUsers_ReturnsAll(t *testing.T) {
@@ -191,3 +192,35 @@ func TestDeleteUser_CannotDeleteSelf(t *testing.T) {
 	require.ErrorAs(t, err, &connectErr)
 	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
 }
+
+func TestCheckCanChangeCredentials(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUtil(t)
+	ctx := u.NewOrganizationContext(t, &backendv1.Organization{DisplayName: "Test Org"})
+
+	qUser, err := u.Store.q.GetUserByID(ctx, authn.UserID(ctx))
+	require.NoError(t, err)
+
+	passwordBcrypt, err := bcrypt.GenerateFromPassword([]byte("current password"), bcrypt.MinCost)
+	require.NoError(t, err)
+	_, err = u.Environment.DB.Exec(t.Context(), `UPDATE identities SET password_bcrypt = $1 WHERE id = $2`, string(passwordBcrypt), qUser.IdentityID)
+	require.NoError(t, err)
+
+	// a fresh session does not need the current password
+	require.NoError(t, u.Store.checkCanChangeCredentials(ctx, u.Store.q, qUser, ""))
+
+	_, err = u.Environment.DB.Exec(t.Context(), `UPDATE sessions SET create_time = now() - interval '1 hour' WHERE id = $1`, authn.SessionID(ctx))
+	require.NoError(t, err)
+
+	// a stale session does
+	var connectErr *connect.Error
+	err = u.Store.checkCanChangeCredentials(ctx, u.Store.q, qUser, "")
+	require.ErrorAs(t, err, &connectErr)
+	require.Equal(t, connect.CodeFailedPrecondition, connectErr.Code())
+
+	err = u.Store.checkCanChangeCredentials(ctx, u.Store.q, qUser, "wrong password")
+	require.Error(t, err)
+
+	require.NoError(t, u.Store.checkCanChangeCredentials(ctx, u.Store.q, qUser, "current password"))
+}
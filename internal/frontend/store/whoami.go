@@ -20,7 +20,12 @@ func (s *Store) Whoami(ctx context.Context, req *frontendv1.WhoamiRequest) (*fro
 		return nil, fmt.Errorf("get user by id: %w", err)
 	}
 
+	qIdentity, err := q.GetIdentityByID(ctx, qUser.IdentityID)
+	if err != nil {
+		return nil, fmt.Errorf("get identity by id: %w", err)
+	}
+
 	return &frontendv1.WhoamiResponse{
-		User: parseUser(qUser),
+		User: parseUser(qUser, qIdentity),
 	}, nil
 }
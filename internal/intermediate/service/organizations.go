@@ -26,6 +26,15 @@ func (s *Service) ListOrganizations(ctx context.Context, req *connect.Request[in
 	return connect.NewResponse(res), nil
 }
 
+func (s *Service) DiscoverHomeRealm(ctx context.Context, req *connect.Request[intermediatev1.DiscoverHomeRealmRequest]) (*connect.Response[intermediatev1.DiscoverHomeRealmResponse], error) {
+	res, err := s.Store.DiscoverHomeRealm(ctx, req.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return connect.NewResponse(res), nil
+}
+
 func (s *Service) ListSAMLOrganizations(ctx context.Context, req *connect.Request[intermediatev1.ListSAMLOrganizationsRequest]) (*connect.Response[intermediatev1.ListSAMLOrganizationsResponse], error) {
 	res, err := s.Store.ListSAMLOrganizations(ctx, req.Msg)
 	if err != nil {
@@ -167,10 +167,36 @@ func (s *Store) VerifyAuthenticatorApp(ctx context.Context, req *intermediatev1.
 	}
 	defer rollback()
 
+	qIntermediateSession, err := q.GetIntermediateSessionByID(ctx, authn.IntermediateSessionID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get intermediate session by id: %w", err)
+	}
+
+	qOrg, err := q.GetProjectOrganizationByID(ctx, queries.GetProjectOrganizationByIDParams{
+		ProjectID: authn.ProjectID(ctx),
+		ID:        *qIntermediateSession.OrganizationID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get organization by id: %w", err)
+	}
+
+	qMatchingIdentity, err := s.matchIdentity(ctx, q, qOrg, qIntermediateSession)
+	if err != nil {
+		return nil, fmt.Errorf("match identity: %w", err)
+	}
+
 	if _, err := q.UpdateIntermediateSessionAuthenticatorAppVerified(ctx, authn.IntermediateSessionID(ctx)); err != nil {
 		return nil, fmt.Errorf("update intermediate session authenticator app verified: %w", err)
 	}
 
+	// the authenticator app proves ownership of the identity
+	if _, err := q.UpdateIntermediateSessionVerifiedIdentityID(ctx, queries.UpdateIntermediateSessionVerifiedIdentityIDParams{
+		ID:                 authn.IntermediateSessionID(ctx),
+		VerifiedIdentityID: &qMatchingIdentity.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("update intermediate session verified identity id: %w", err)
+	}
+
 	if err := commit(); err != nil {
 		return nil, fmt.Errorf("commit: %w", err)
 	}
@@ -220,9 +246,9 @@ func (s *Store) verifyAuthenticatorAppByRecoveryCode(ctx context.Context, recove
 		return fmt.Errorf("get organization by id: %w", err)
 	}
 
-	qMatchingUser, err := s.matchUser(ctx, q, qOrg, qIntermediateSession)
+	qMatchingIdentity, err := s.matchIdentity(ctx, q, qOrg, qIntermediateSession)
 	if err != nil {
-		return fmt.Errorf("match user: %w", err)
+		return fmt.Errorf("match identity: %w", err)
 	}
 
 	recoveryCodeUUID, err := idformat.AuthenticatorAppRecoveryCode.Parse(recoveryCode)
@@ -234,7 +260,7 @@ func (s *Store) verifyAuthenticatorAppByRecoveryCode(ctx context.Context, recove
 
 	var ok bool
 	var recoveryCodeSHA256s [][]byte
-	for _, b := range qMatchingUser.AuthenticatorAppRecoveryCodeSha256s {
+	for _, b := range qMatchingIdentity.AuthenticatorAppRecoveryCodeSha256s {
 		if bytes.Equal(recoveryCodeSHA256[:], b) {
 			ok = true
 			continue // do not keep this recovery code around; it's used
@@ -243,12 +269,12 @@ func (s *Store) verifyAuthenticatorAppByRecoveryCode(ctx context.Context, recove
 		recoveryCodeSHA256s = append(recoveryCodeSHA256s, b)
 	}
 
-	// write back the remaining backup codes to the user
-	if _, err := q.UpdateUserAuthenticatorAppRecoveryCodeSHA256s(ctx, queries.UpdateUserAuthenticatorAppRecoveryCodeSHA256sParams{
-		ID:                                  qMatchingUser.ID,
+	// write back the remaining backup codes to the identity
+	if _, err := q.UpdateIdentityAuthenticatorAppRecoveryCodeSHA256s(ctx, queries.UpdateIdentityAuthenticatorAppRecoveryCodeSHA256sParams{
+		ID:                                  qMatchingIdentity.ID,
 		AuthenticatorAppRecoveryCodeSha256s: recoveryCodeSHA256s,
 	}); err != nil {
-		return fmt.Errorf("update intermediate session authenticator app backup code sha256s: %w", err)
+		return fmt.Errorf("update identity authenticator app backup code sha256s: %w", err)
 	}
 
 	// commit; our writes conflict with those from
@@ -292,12 +318,12 @@ func (s *Store) checkAuthenticatorAppLockedOut(ctx context.Context) error {
 		return fmt.Errorf("get organization by id: %w", err)
 	}
 
-	qMatchingUser, err := s.matchUser(ctx, q, qOrg, qIntermediateSession)
+	qMatchingIdentity, err := s.matchIdentity(ctx, q, qOrg, qIntermediateSession)
 	if err != nil {
-		return fmt.Errorf("match user: %w", err)
+		return fmt.Errorf("match identity: %w", err)
 	}
 
-	if qMatchingUser.AuthenticatorAppLockoutExpireTime != nil && qMatchingUser.AuthenticatorAppLockoutExpireTime.After(time.Now()) {
+	if qMatchingIdentity.AuthenticatorAppLockoutExpireTime != nil && qMatchingIdentity.AuthenticatorAppLockoutExpireTime.After(time.Now()) {
 		return apierror.NewFailedPreconditionError("too many authenticator app attempts; user is temporarily locked out", nil)
 	}
 	return nil
@@ -323,17 +349,17 @@ func (s *Store) updateUserAuthenticatorAppLockoutState(ctx context.Context, last
 		return fmt.Errorf("get organization by id: %w", err)
 	}
 
-	qMatchingUser, err := s.matchUser(ctx, q, qOrg, qIntermediateSession)
+	qMatchingIdentity, err := s.matchIdentity(ctx, q, qOrg, qIntermediateSession)
 	if err != nil {
-		return fmt.Errorf("match user: %w", err)
+		return fmt.Errorf("match identity: %w", err)
 	}
 
 	if lastAttemptSuccessful {
-		if _, err := q.UpdateUserFailedAuthenticatorAppAttempts(ctx, queries.UpdateUserFailedAuthenticatorAppAttemptsParams{
-			ID:                             qMatchingUser.ID,
+		if _, err := q.UpdateIdentityFailedAuthenticatorAppAttempts(ctx, queries.UpdateIdentityFailedAuthenticatorAppAttemptsParams{
+			ID:                             qMatchingIdentity.ID,
 			FailedAuthenticatorAppAttempts: 0,
 		}); err != nil {
-			return fmt.Errorf("update user failed authenticator app attempts: %w", err)
+			return fmt.Errorf("update identity failed authenticator app attempts: %w", err)
 		}
 
 		if err := commit(); err != nil {
@@ -343,23 +369,23 @@ func (s *Store) updateUserAuthenticatorAppLockoutState(ctx context.Context, last
 		return nil
 	}
 
-	attempts := qMatchingUser.FailedAuthenticatorAppAttempts + 1
+	attempts := qMatchingIdentity.FailedAuthenticatorAppAttempts + 1
 	if attempts >= backupCodeLockoutAttempts {
 		// lock the user out
 		expireTime := time.Now().Add(backupCodeLockoutDuration)
-		if _, err := q.UpdateUserAuthenticatorAppLockoutExpireTime(ctx, queries.UpdateUserAuthenticatorAppLockoutExpireTimeParams{
-			ID:                                qMatchingUser.ID,
+		if _, err := q.UpdateIdentityAuthenticatorAppLockoutExpireTime(ctx, queries.UpdateIdentityAuthenticatorAppLockoutExpireTimeParams{
+			ID:                                qMatchingIdentity.ID,
 			AuthenticatorAppLockoutExpireTime: &expireTime,
 		}); err != nil {
-			return fmt.Errorf("update user authenticator app lockout expire time: %w", err)
+			return fmt.Errorf("update identity authenticator app lockout expire time: %w", err)
 		}
 
 		// reset fail count
-		if _, err := q.UpdateUserFailedAuthenticatorAppAttempts(ctx, queries.UpdateUserFailedAuthenticatorAppAttemptsParams{
-			ID:                             qMatchingUser.ID,
+		if _, err := q.UpdateIdentityFailedAuthenticatorAppAttempts(ctx, queries.UpdateIdentityFailedAuthenticatorAppAttemptsParams{
+			ID:                             qMatchingIdentity.ID,
 			FailedAuthenticatorAppAttempts: 0,
 		}); err != nil {
-			return fmt.Errorf("update user failed authenticator app attempts: %w", err)
+			return fmt.Errorf("update identity failed authenticator app attempts: %w", err)
 		}
 
 		if err := commit(); err != nil {
@@ -370,11 +396,11 @@ func (s *Store) updateUserAuthenticatorAppLockoutState(ctx context.Context, last
 	}
 
 	// bump attempt count
-	if _, err := q.UpdateUserFailedAuthenticatorAppAttempts(ctx, queries.UpdateUserFailedAuthenticatorAppAttemptsParams{
-		ID:                             qMatchingUser.ID,
+	if _, err := q.UpdateIdentityFailedAuthenticatorAppAttempts(ctx, queries.UpdateIdentityFailedAuthenticatorAppAttemptsParams{
+		ID:                             qMatchingIdentity.ID,
 		FailedAuthenticatorAppAttempts: attempts,
 	}); err != nil {
-		return fmt.Errorf("update user failed authenticator app attempts: %w", err)
+		return fmt.Errorf("update identity failed authenticator app attempts: %w", err)
 	}
 	return nil
 }
@@ -428,9 +454,9 @@ func (s *Store) getAuthenticatorAppSecret(ctx context.Context) ([]byte, error) {
 		return nil, fmt.Errorf("get organization by id: %w", err)
 	}
 
-	qMatchingUser, err := s.matchUser(ctx, q, qOrg, qIntermediateSession)
+	qMatchingIdentity, err := s.matchIdentity(ctx, q, qOrg, qIntermediateSession)
 	if err != nil {
-		return nil, fmt.Errorf("match user: %w", err)
+		return nil, fmt.Errorf("match identity: %w", err)
 	}
 
 	// close tx before calling kms
@@ -441,7 +467,7 @@ func (s *Store) getAuthenticatorAppSecret(ctx context.Context) ([]byte, error) {
 	decryptRes, err := s.kms.Decrypt(ctx, &kms.DecryptInput{
 		KeyId:               &s.authenticatorAppSecretsKMSKeyID,
 		EncryptionAlgorithm: types.EncryptionAlgorithmSpecRsaesOaepSha256,
-		CiphertextBlob:      qMatchingUser.AuthenticatorAppSecretCiphertext,
+		CiphertextBlob:      qMatchingIdentity.AuthenticatorAppSecretCiphertext,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("decrypt authenticator app secret ciphertext: %w", err)
@@ -451,8 +477,8 @@ func (s *Store) getAuthenticatorAppSecret(ctx context.Context) ([]byte, error) {
 }
 
 func (s *Store) checkShouldRegisterAuthenticatorApp(ctx context.Context) error {
-	// don't register an authenticator app if you're already matching a user,
-	// and that user has one
+	// don't register an authenticator app if you're already matching an
+	// identity, and that identity has one
 
 	_, q, _, rollback, err := s.tx(ctx)
 	if err != nil {
@@ -473,17 +499,17 @@ func (s *Store) checkShouldRegisterAuthenticatorApp(ctx context.Context) error {
 		return fmt.Errorf("get organization by id: %w", err)
 	}
 
-	qUser, err := s.matchUser(ctx, q, qOrg, qIntermediateSession)
+	qIdentity, err := s.matchIdentity(ctx, q, qOrg, qIntermediateSession)
 	if err != nil {
-		return fmt.Errorf("match user: %w", err)
+		return fmt.Errorf("match identity: %w", err)
 	}
 
-	// no matching user; it's ok to register passkeys
-	if qUser == nil {
+	// no matching identity; it's ok to register passkeys
+	if qIdentity == nil {
 		return nil
 	}
 
-	if qUser.AuthenticatorAppSecretCiphertext != nil {
+	if qIdentity.AuthenticatorAppSecretCiphertext != nil {
 		return apierror.NewFailedPreconditionError("user already has an authenticator app", nil)
 	}
 	return nil
@@ -6,16 +6,15 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
-	"html/template"
 	"log/slog"
 	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/sesv2"
-	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/tesseral-labs/tesseral/internal/emailtemplates"
+
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/authn"
 	intermediatev1 "github.com/tesseral-labs/tesseral/internal/intermediate/gen/tesseral/intermediate/v1"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/store/queries"
@@ -166,62 +165,31 @@ func (s *Store) VerifyEmailChallenge(ctx context.Context, req *intermediatev1.Ve
 	return &intermediatev1.VerifyEmailChallengeResponse{}, nil
 }
 
-var emailVerificationEmailBodyTmpl = template.Must(template.New("emailVerificationEmailBody").Parse(`Hello,
-
-To continue logging in to {{ .ProjectDisplayName }}, please verify your email address by visiting the link below.
-
-{{ .EmailVerificationLink }}
-
-You can also go back to the "Check your email" page and enter this verification code manually:
-
-{{ .EmailVerificationCode }}
-
-If you did not request this verification, please ignore this email.
-`))
-
 func (s *Store) sendEmailVerificationChallenge(ctx context.Context, toAddress string, secretToken string) error {
 	qProject, err := s.q.GetProjectByID(ctx, authn.ProjectID(ctx))
 	if err != nil {
 		return fmt.Errorf("get project by id: %w", err)
 	}
 
-	subject := fmt.Sprintf("%s - Verify your email address", qProject.DisplayName)
-
 	vaultDomain := qProject.VaultDomain
 	if authn.ProjectID(ctx) == *s.dogfoodProjectID {
 		vaultDomain = s.consoleDomain
 	}
 
-	var body bytes.Buffer
-	if err := emailVerificationEmailBodyTmpl.Execute(&body, struct {
-		ProjectDisplayName    string
-		EmailVerificationLink string
-		EmailVerificationCode string
-	}{
-		ProjectDisplayName:    qProject.DisplayName,
-		EmailVerificationLink: fmt.Sprintf("https://%s/verify-email?code=%s", vaultDomain, secretToken),
-		EmailVerificationCode: secretToken,
-	}); err != nil {
-		return fmt.Errorf("execute email verification email body template: %w", err)
-	}
-
-	if _, err := s.ses.SendEmail(ctx, &sesv2.SendEmailInput{
-		Content: &types.EmailContent{
-			Simple: &types.Message{
-				Subject: &types.Content{
-					Data: &subject,
-				},
-				Body: &types.Body{
-					Text: &types.Content{
-						Data: aws.String(body.String()),
-					},
-				},
-			},
-		},
-		Destination: &types.Destination{
-			ToAddresses: []string{toAddress},
-		},
-		FromEmailAddress: aws.String(fmt.Sprintf("noreply@%s", qProject.EmailSendFromDomain)),
+	email, err := s.renderEmail(ctx, qProject, emailtemplates.TypeEmailVerification, map[string]string{
+		"EmailVerificationLink": fmt.Sprintf("https://%s/verify-email?code=%s", vaultDomain, secretToken),
+		"EmailVerificationCode": secretToken,
+	})
+	if err != nil {
+		return fmt.Errorf("render email verification email: %w", err)
+	}
+
+	if err := s.emailSender.Send(ctx, emailsender.Message{
+		From:     fmt.Sprintf("noreply@%s", qProject.EmailSendFromDomain),
+		To:       []string{toAddress},
+		Subject:  email.Subject,
+		TextBody: email.TextBody,
+		HTMLBody: email.HTMLBody,
 	}); err != nil {
 		return fmt.Errorf("send email: %w", err)
 	}
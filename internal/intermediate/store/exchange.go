@@ -10,13 +10,14 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/svix/svix-webhooks/go/models"
 	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/emailaddr"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/authn"
 	intermediatev1 "github.com/tesseral-labs/tesseral/internal/intermediate/gen/tesseral/intermediate/v1"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
 )
 
 const sessionDuration = time.Hour * 24 * 7
@@ -74,6 +75,12 @@ func (s *Store) ExchangeIntermediateSessionForSession(ctx context.Context, req *
 		return nil, fmt.Errorf("match user: %w", err)
 	}
 
+	// credentials verified on the intermediate session belong to an identity,
+	// and only log in as one of its users
+	if qUser != nil && qIntermediateSession.VerifiedIdentityID != nil && *qIntermediateSession.VerifiedIdentityID != qUser.IdentityID {
+		return nil, apierror.NewFailedPreconditionError("verified credentials belong to another user", fmt.Errorf("verified identity does not match user identity"))
+	}
+
 	var (
 		newUser        = qUser == nil
 		detailsUpdated = newUser
@@ -86,16 +93,22 @@ func (s *Store) ExchangeIntermediateSessionForSession(ctx context.Context, req *
 		}
 
 		slog.InfoContext(ctx, "create_user")
+
+		qIdentity, err := s.newUserIdentity(ctx, q, qIntermediateSession)
+		if err != nil {
+			return nil, fmt.Errorf("new user identity: %w", err)
+		}
+
 		qNewUser, err := q.CreateUser(ctx, queries.CreateUserParams{
 			ID:                uuid.New(),
 			OrganizationID:    qOrg.ID,
+			IdentityID:        qIdentity.ID,
 			Email:             *qIntermediateSession.Email,
 			DisplayName:       qIntermediateSession.UserDisplayName,
 			ProfilePictureUrl: qIntermediateSession.ProfilePictureUrl,
 			GoogleUserID:      qIntermediateSession.GoogleUserID,
 			MicrosoftUserID:   qIntermediateSession.MicrosoftUserID,
 			GithubUserID:      qIntermediateSession.GithubUserID,
-			PasswordBcrypt:    qIntermediateSession.NewUserPasswordBcrypt,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("create user: %w", err)
@@ -143,7 +156,6 @@ func (s *Store) ExchangeIntermediateSessionForSession(ctx context.Context, req *
 				MicrosoftUserID:   qIntermediateSession.MicrosoftUserID,
 				DisplayName:       qIntermediateSession.UserDisplayName,
 				ProfilePictureUrl: qIntermediateSession.ProfilePictureUrl,
-				PasswordBcrypt:    qIntermediateSession.NewUserPasswordBcrypt,
 			})
 			if err != nil {
 				return nil, fmt.Errorf("update user: %w", err)
@@ -171,8 +183,19 @@ func (s *Store) ExchangeIntermediateSessionForSession(ctx context.Context, req *
 		}
 	}
 
+	// if a password is registered on the intermediate session, copy it onto the
+	// user's identity
+	if qIntermediateSession.NewUserPasswordBcrypt != nil {
+		if _, err := q.UpdateIdentityPasswordBcrypt(ctx, queries.UpdateIdentityPasswordBcryptParams{
+			ID:             qUser.IdentityID,
+			PasswordBcrypt: qIntermediateSession.NewUserPasswordBcrypt,
+		}); err != nil {
+			return nil, fmt.Errorf("update identity password bcrypt: %w", err)
+		}
+	}
+
 	// if a passkey is registered on the intermediate session, copy it onto the
-	// user
+	// user's identity
 	if qIntermediateSession.PasskeyCredentialID != nil {
 		slog.InfoContext(ctx, "register_passkey")
 		detailsUpdated = true
@@ -182,7 +205,7 @@ func (s *Store) ExchangeIntermediateSessionForSession(ctx context.Context, req *
 	}
 
 	// if an authenticator app is registered on the intermediate session, copy
-	// it onto the user
+	// it onto the user's identity
 	if qIntermediateSession.AuthenticatorAppSecretCiphertext != nil {
 		slog.InfoContext(ctx, "register_authenticator_app")
 		detailsUpdated = true
@@ -288,17 +311,17 @@ func (s *Store) ExchangeIntermediateSessionForSession(ctx context.Context, req *
 		return nil, fmt.Errorf("create audit log event: %w", err)
 	}
 
-	if err := commit(); err != nil {
-		return nil, err
-	}
-
 	if detailsUpdated {
 		// Send sync user event
-		if err := s.sendSyncUserEvent(ctx, *qUser); err != nil {
+		if err := s.sendSyncUserEvent(ctx, q, *qUser); err != nil {
 			return nil, fmt.Errorf("send sync user event: %w", err)
 		}
 	}
 
+	if err := commit(); err != nil {
+		return nil, err
+	}
+
 	return &intermediatev1.ExchangeIntermediateSessionForSessionResponse{
 		AccessToken:                           "", // populated in service
 		RefreshToken:                          idformat.SessionRefreshToken.Format(refreshToken),
@@ -309,29 +332,17 @@ func (s *Store) ExchangeIntermediateSessionForSession(ctx context.Context, req *
 	}, nil
 }
 
-func (s *Store) sendSyncUserEvent(ctx context.Context, qUser queries.User) error {
-	qProjectWebhookSettings, err := s.q.GetProjectWebhookSettings(ctx, authn.ProjectID(ctx))
-	if err != nil {
-		// We want to ignore this error if the project does not have webhook settings
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil
-		}
-		return fmt.Errorf("get project by id: %w", err)
-	}
-
-	message, err := s.svixClient.Message.Create(ctx, qProjectWebhookSettings.AppID, models.MessageIn{
+func (s *Store) sendSyncUserEvent(ctx context.Context, q *queries.Queries, qUser queries.User) error {
+	if err := s.enqueueWebhookMessage(ctx, q, authn.ProjectID(ctx), webhooks.Message{
 		EventType: "sync.user",
 		Payload: map[string]interface{}{
 			"type":   "sync.user",
 			"userId": idformat.User.Format(qUser.ID),
 		},
-	}, nil)
-	if err != nil {
-		return fmt.Errorf("create message: %w", err)
+	}); err != nil {
+		return fmt.Errorf("enqueue webhook message: %w", err)
 	}
 
-	slog.InfoContext(ctx, "svix_message_created", "message_id", message.Id, "event_type", message.EventType, "user_id", idformat.User.Format(qUser.ID))
-
 	return nil
 }
 
@@ -358,10 +369,29 @@ func (s *Store) validateAuthRequirementsSatisfied(ctx context.Context, q *querie
 		return fmt.Errorf("get organization by id: %w", err)
 	}
 
-	return validateAuthRequirementsSatisfiedInner(qIntermediateSession, emailVerified, qOrg)
+	var ssoRequired bool
+	if qOrg.EnforceSsoForDomains && qIntermediateSession.Email != nil {
+		domain, err := emailaddr.Parse(*qIntermediateSession.Email)
+		if err != nil {
+			return fmt.Errorf("parse email: %w", err)
+		}
+
+		ssoRequired, err = q.GetOrganizationHasVerifiedDomain(ctx, queries.GetOrganizationHasVerifiedDomainParams{
+			OrganizationID: qOrg.ID,
+			Domain:         domain,
+		})
+		if err != nil {
+			return fmt.Errorf("get organization has verified domain: %w", err)
+		}
+	}
+
+	return validateAuthRequirementsSatisfiedInner(qIntermediateSession, emailVerified, qOrg, ssoRequired)
 }
 
-func validateAuthRequirementsSatisfiedInner(qIntermediateSession queries.IntermediateSession, emailVerified bool, qOrg queries.Organization) error {
+// validateAuthRequirementsSatisfiedInner returns an error if the intermediate
+// session does not satisfy qOrg's login requirements. ssoRequired indicates
+// that qOrg enforces SSO for the session's email domain.
+func validateAuthRequirementsSatisfiedInner(qIntermediateSession queries.IntermediateSession, emailVerified bool, qOrg queries.Organization, ssoRequired bool) error {
 	if qIntermediateSession.Email == nil {
 		panic(fmt.Errorf("intermediate session missing email: %v", qIntermediateSession.ID))
 	}
@@ -379,6 +409,10 @@ func validateAuthRequirementsSatisfiedInner(qIntermediateSession queries.Interme
 			*qIntermediateSession.PrimaryAuthFactor == queries.PrimaryAuthFactorOidc
 
 	if !isEnterpriseLogin {
+		if ssoRequired {
+			return apierror.NewFailedPreconditionError("sso required", nil)
+		}
+
 		if qOrg.LogInWithPassword && !qIntermediateSession.PasswordVerified {
 			return apierror.NewFailedPreconditionError("password not verified", nil)
 		}
@@ -481,6 +515,97 @@ func (s *Store) matchUser(ctx context.Context, q *queries.Queries, qOrg queries.
 	return nil, nil
 }
 
+// matchIdentity returns the identity whose credentials apply to an
+// intermediate session: the identity of the matching user, if any, or else the
+// identity whose credentials the intermediate session has verified, or else the
+// identity with the intermediate session's email, whose users are in other
+// organizations.
+//
+// Verifying the credentials of the identity with the intermediate session's
+// email proves ownership of it, and so lets a new user join it; see
+// newUserIdentity.
+func (s *Store) matchIdentity(ctx context.Context, q *queries.Queries, qOrg queries.Organization, qIntermediateSession queries.IntermediateSession) (*queries.Identity, error) {
+	qUser, err := s.matchUser(ctx, q, qOrg, qIntermediateSession)
+	if err != nil {
+		return nil, fmt.Errorf("match user: %w", err)
+	}
+
+	if qUser != nil {
+		qIdentity, err := q.GetIdentityByID(ctx, qUser.IdentityID)
+		if err != nil {
+			return nil, fmt.Errorf("get identity by id: %w", err)
+		}
+		return &qIdentity, nil
+	}
+
+	if qIntermediateSession.VerifiedIdentityID != nil {
+		qIdentity, err := q.GetIdentityByID(ctx, *qIntermediateSession.VerifiedIdentityID)
+		if err != nil {
+			return nil, fmt.Errorf("get identity by id: %w", err)
+		}
+		return &qIdentity, nil
+	}
+
+	qIdentity, err := q.GetIdentityByEmail(ctx, queries.GetIdentityByEmailParams{
+		ProjectID: authn.ProjectID(ctx),
+		Email:     *qIntermediateSession.Email,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get identity by email: %w", err)
+	}
+
+	return &qIdentity, nil
+}
+
+// newUserIdentity returns the identity for a new user created from an
+// intermediate session.
+//
+// A new user only joins an existing identity, and so gets its credentials, if
+// the intermediate session has proven ownership of it: by verifying one of the
+// identity's credentials, or by verifying a code sent to its email. Otherwise,
+// such as for users provisioned by an organization's SAML or OIDC identity
+// provider, the new user gets an identity of its own.
+func (s *Store) newUserIdentity(ctx context.Context, q *queries.Queries, qIntermediateSession queries.IntermediateSession) (queries.Identity, error) {
+	if qIntermediateSession.VerifiedIdentityID != nil {
+		qIdentity, err := q.GetIdentityByID(ctx, *qIntermediateSession.VerifiedIdentityID)
+		if err != nil {
+			return queries.Identity{}, fmt.Errorf("get identity by id: %w", err)
+		}
+		return qIdentity, nil
+	}
+
+	// both codes are sent to the intermediate session's email
+	emailVerified := qIntermediateSession.EmailVerificationChallengeCompleted || qIntermediateSession.PasswordResetCodeVerified
+	if emailVerified {
+		qIdentity, err := q.GetIdentityByEmail(ctx, queries.GetIdentityByEmailParams{
+			ProjectID: qIntermediateSession.ProjectID,
+			Email:     *qIntermediateSession.Email,
+		})
+		if err == nil {
+			return qIdentity, nil
+		}
+
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return queries.Identity{}, fmt.Errorf("get identity by email: %w", err)
+		}
+	}
+
+	qIdentity, err := q.CreateIdentity(ctx, queries.CreateIdentityParams{
+		ID:            uuid.New(),
+		ProjectID:     qIntermediateSession.ProjectID,
+		Email:         *qIntermediateSession.Email,
+		EmailVerified: emailVerified,
+	})
+	if err != nil {
+		return queries.Identity{}, fmt.Errorf("create identity: %w", err)
+	}
+
+	return qIdentity, nil
+}
+
 func (s *Store) matchGoogleUser(ctx context.Context, q *queries.Queries, qOrg queries.Organization, qIntermediateSession queries.IntermediateSession) (*queries.User, error) {
 	if qIntermediateSession.GoogleUserID == nil {
 		return nil, nil
@@ -554,18 +679,19 @@ func (s *Store) matchEmailUser(ctx context.Context, q *queries.Queries, qOrg que
 }
 
 func (s *Store) copyRegisteredPasskeySettings(ctx context.Context, q *queries.Queries, qIntermediateSession queries.IntermediateSession, qUser queries.User) error {
-	userHasPasskey, err := q.GetUserHasActivePasskey(ctx, qUser.ID)
+	identityHasPasskey, err := q.GetIdentityHasActivePasskey(ctx, qUser.IdentityID)
 	if err != nil {
-		return fmt.Errorf("get user has passkey: %w", err)
+		return fmt.Errorf("get identity has passkey: %w", err)
 	}
 
-	if userHasPasskey {
-		return fmt.Errorf("user already has a passkey")
+	if identityHasPasskey {
+		return fmt.Errorf("identity already has a passkey")
 	}
 
 	if _, err := q.CreatePasskey(ctx, queries.CreatePasskeyParams{
 		ID:           uuid.New(),
-		UserID:       qUser.ID,
+		IdentityID:   qUser.IdentityID,
+		UserID:       &qUser.ID,
 		CredentialID: qIntermediateSession.PasskeyCredentialID,
 		PublicKey:    qIntermediateSession.PasskeyPublicKey,
 		Aaguid:       *qIntermediateSession.PasskeyAaguid,
@@ -578,16 +704,21 @@ func (s *Store) copyRegisteredPasskeySettings(ctx context.Context, q *queries.Qu
 }
 
 func (s *Store) copyRegisteredAuthenticatorAppSettings(ctx context.Context, q *queries.Queries, qIntermediateSession queries.IntermediateSession, qUser queries.User) error {
-	if qUser.AuthenticatorAppSecretCiphertext != nil || qUser.AuthenticatorAppRecoveryCodeSha256s != nil {
-		return fmt.Errorf("user already has authenticator app registered")
+	qIdentity, err := q.GetIdentityByID(ctx, qUser.IdentityID)
+	if err != nil {
+		return fmt.Errorf("get identity by id: %w", err)
+	}
+
+	if qIdentity.AuthenticatorAppSecretCiphertext != nil || qIdentity.AuthenticatorAppRecoveryCodeSha256s != nil {
+		return fmt.Errorf("identity already has authenticator app registered")
 	}
 
-	if _, err := q.UpdateUserAuthenticatorApp(ctx, queries.UpdateUserAuthenticatorAppParams{
+	if _, err := q.UpdateIdentityAuthenticatorApp(ctx, queries.UpdateIdentityAuthenticatorAppParams{
 		AuthenticatorAppSecretCiphertext:    qIntermediateSession.AuthenticatorAppSecretCiphertext,
 		AuthenticatorAppRecoveryCodeSha256s: qIntermediateSession.AuthenticatorAppRecoveryCodeSha256s,
-		ID:                                  qUser.ID,
+		ID:                                  qIdentity.ID,
 	}); err != nil {
-		return fmt.Errorf("update user authenticator app: %w", err)
+		return fmt.Errorf("update identity authenticator app: %w", err)
 	}
 
 	return nil
@@ -4,8 +4,12 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 )
 
 func TestStore_validateAuthRequirementsSatisfiedInner(t *testing.T) {
@@ -14,6 +18,7 @@ func TestStore_validateAuthRequirementsSatisfiedInner(t *testing.T) {
 		qIntermediateSession queries.IntermediateSession
 		emailVerified        bool
 		qOrg                 queries.Organization
+		ssoRequired          bool
 		wantErr              bool
 	}{
 		{
@@ -205,11 +210,59 @@ func TestStore_validateAuthRequirementsSatisfiedInner(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "password sso required",
+			qIntermediateSession: queries.IntermediateSession{
+				PrimaryAuthFactor: primaryAuthFactor(queries.PrimaryAuthFactorPassword),
+				PasswordVerified:  true,
+				Email:             aws.String("foo@bar.com"),
+			},
+			emailVerified: true,
+			qOrg: queries.Organization{
+				LogInWithPassword:    true,
+				LogInWithSaml:        true,
+				EnforceSsoForDomains: true,
+			},
+			ssoRequired: true,
+			wantErr:     true,
+		},
+		{
+			name: "google sso required",
+			qIntermediateSession: queries.IntermediateSession{
+				PrimaryAuthFactor: primaryAuthFactor(queries.PrimaryAuthFactorGoogle),
+				GoogleUserID:      aws.String("foo"),
+				Email:             aws.String("foo@bar.com"),
+			},
+			emailVerified: true,
+			qOrg: queries.Organization{
+				LogInWithGoogle:      true,
+				LogInWithSaml:        true,
+				EnforceSsoForDomains: true,
+			},
+			ssoRequired: true,
+			wantErr:     true,
+		},
+		{
+			name: "saml sso required",
+			qIntermediateSession: queries.IntermediateSession{
+				PrimaryAuthFactor:        primaryAuthFactor(queries.PrimaryAuthFactorSaml),
+				VerifiedSamlConnectionID: &uuid.UUID{},
+				Email:                    aws.String("foo@bar.com"),
+			},
+			emailVerified: true,
+			qOrg: queries.Organization{
+				LogInWithPassword:    true,
+				LogInWithSaml:        true,
+				EnforceSsoForDomains: true,
+			},
+			ssoRequired: true,
+			wantErr:     false,
+		},
 	}
 
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateAuthRequirementsSatisfiedInner(tt.qIntermediateSession, tt.emailVerified, tt.qOrg)
+			err := validateAuthRequirementsSatisfiedInner(tt.qIntermediateSession, tt.emailVerified, tt.qOrg, tt.ssoRequired)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -219,6 +272,56 @@ func TestStore_validateAuthRequirementsSatisfiedInner(t *testing.T) {
 	}
 }
 
+func TestStore_newUserIdentity(t *testing.T) {
+	t.Parallel()
+
+	_, u := newTestUtil(t)
+	orgID := u.NewOrganization(t, &backendv1.Organization{DisplayName: "test"})
+	userID := u.Environment.NewUser(t, orgID, &backendv1.User{Email: "victim@example.com"})
+
+	userUUID, err := idformat.User.Parse(userID)
+	require.NoError(t, err)
+
+	var existingIdentityID uuid.UUID
+	err = u.Environment.DB.QueryRow(t.Context(), `SELECT identity_id FROM users WHERE id = $1`, uuid.UUID(userUUID)).Scan(&existingIdentityID)
+	require.NoError(t, err)
+
+	projectUUID, err := idformat.Project.Parse(u.ProjectID)
+	require.NoError(t, err)
+
+	newUserIdentity := func(qIntermediateSession queries.IntermediateSession) queries.Identity {
+		qIntermediateSession.ProjectID = projectUUID
+		qIntermediateSession.Email = aws.String("victim@example.com")
+
+		qIdentity, err := u.Store.newUserIdentity(t.Context(), u.Store.q, qIntermediateSession)
+		require.NoError(t, err)
+		return qIdentity
+	}
+
+	t.Run("sso login does not join existing identity", func(t *testing.T) {
+		samlConnectionID := uuid.New()
+		qIdentity := newUserIdentity(queries.IntermediateSession{
+			VerifiedSamlConnectionID: &samlConnectionID,
+		})
+		require.NotEqual(t, existingIdentityID, qIdentity.ID)
+		require.False(t, qIdentity.EmailVerified)
+	})
+
+	t.Run("email verification joins existing identity", func(t *testing.T) {
+		qIdentity := newUserIdentity(queries.IntermediateSession{
+			EmailVerificationChallengeCompleted: true,
+		})
+		require.Equal(t, existingIdentityID, qIdentity.ID)
+	})
+
+	t.Run("verified credentials join their identity", func(t *testing.T) {
+		qIdentity := newUserIdentity(queries.IntermediateSession{
+			VerifiedIdentityID: &existingIdentityID,
+		})
+		require.Equal(t, existingIdentityID, qIdentity.ID)
+	})
+}
+
 func primaryAuthFactor(v queries.PrimaryAuthFactor) *queries.PrimaryAuthFactor {
 	return &v
 }
@@ -74,7 +74,7 @@ func (s *Store) RedeemUserImpersonationToken(ctx context.Context, req *intermedi
 		return nil, fmt.Errorf("get audit session: %w", err)
 	}
 
-	if err := logImpersonateAuditEvent(ctx, q, logAuditEventParams{
+	if err := s.logImpersonateAuditEvent(ctx, q, logAuditEventParams{
 		EventName:      "tesseral.sessions.create",
 		EventDetails:   &auditlogv1.CreateSession{Session: auditSession},
 		OrganizationID: &qImpersonatedUser.OrganizationID,
@@ -94,7 +94,7 @@ func (s *Store) RedeemUserImpersonationToken(ctx context.Context, req *intermedi
 	}, nil
 }
 
-func logImpersonateAuditEvent(ctx context.Context, q *queries.Queries, data logAuditEventParams) error {
+func (s *Store) logImpersonateAuditEvent(ctx context.Context, q *queries.Queries, data logAuditEventParams) error {
 	// Generate the UUIDv7 based on the event time.
 	eventTime := time.Now()
 	eventID := uuidv7.NewWithTime(eventTime)
@@ -115,6 +115,10 @@ func logImpersonateAuditEvent(ctx context.Context, q *queries.Queries, data logA
 		EventDetails:   eventDetailsBytes,
 	}
 
+	if err := s.chainAuditLogEvent(ctx, q, &qEventParams, eventDetailsBytes); err != nil {
+		return err
+	}
+
 	_, err = q.CreateAuditLogEvent(ctx, qEventParams)
 	return err
 }
@@ -4,17 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log/slog"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/svix/svix-webhooks/go/models"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
 	"github.com/tesseral-labs/tesseral/internal/emailaddr"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/authn"
 	intermediatev1 "github.com/tesseral-labs/tesseral/internal/intermediate/gen/tesseral/intermediate/v1"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
 )
 
 func (s *Store) CreateOrganization(ctx context.Context, req *intermediatev1.CreateOrganizationRequest) (*intermediatev1.CreateOrganizationResponse, error) {
@@ -67,6 +66,14 @@ func (s *Store) CreateOrganization(ctx context.Context, req *intermediatev1.Crea
 		return nil, fmt.Errorf("create organization: %w", err)
 	}
 
+	// Give the organization its project's role template roles.
+	if err := q.CreateOrganizationRoleTemplateRoles(ctx, qOrganization.ID); err != nil {
+		return nil, fmt.Errorf("create organization role template roles: %w", err)
+	}
+	if err := q.CreateOrganizationRoleTemplateRoleActions(ctx, qOrganization.ID); err != nil {
+		return nil, fmt.Errorf("create organization role template role actions: %w", err)
+	}
+
 	// If the intermediate session is associated with a Google or Microsoft
 	// login, associate the organization as well.
 	if intermediateSession.GoogleHostedDomain != "" {
@@ -106,15 +113,15 @@ func (s *Store) CreateOrganization(ctx context.Context, req *intermediatev1.Crea
 		return nil, fmt.Errorf("update intermediate session organization ID: %w", err)
 	}
 
-	if err := commit(); err != nil {
-		return nil, fmt.Errorf("commit: %w", err)
-	}
-
 	// Send a sync.organization event to the webhook.
-	if err := s.sendSyncOrganizationEvent(ctx, qOrganization); err != nil {
+	if err := s.sendSyncOrganizationEvent(ctx, q, qOrganization); err != nil {
 		return nil, fmt.Errorf("send sync organization event: %w", err)
 	}
 
+	if err := commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
 	return &intermediatev1.CreateOrganizationResponse{
 		OrganizationId: idformat.Organization.Format(qOrganization.ID),
 	}, nil
@@ -183,13 +190,21 @@ func (s *Store) ListOrganizations(ctx context.Context, req *intermediatev1.ListO
 		}
 
 		org.UserExists = existingUser != nil
-		if existingUser != nil {
-			org.UserHasPassword = existingUser.PasswordBcrypt != nil
-			org.UserHasAuthenticatorApp = existingUser.AuthenticatorAppSecretCiphertext != nil
 
-			hasPasskeys, err := q.GetUserHasActivePasskey(ctx, existingUser.ID)
+		// credentials belong to the identity, which may have users in other
+		// organizations even if it has none in this one
+		existingIdentity, err := s.matchIdentity(ctx, q, qOrg, qIntermediateSession)
+		if err != nil {
+			return nil, fmt.Errorf("match identity: %w", err)
+		}
+
+		if existingIdentity != nil {
+			org.UserHasPassword = existingIdentity.PasswordBcrypt != nil || existingIdentity.ImportedPasswordHash != nil
+			org.UserHasAuthenticatorApp = existingIdentity.AuthenticatorAppSecretCiphertext != nil
+
+			hasPasskeys, err := q.GetIdentityHasActivePasskey(ctx, existingIdentity.ID)
 			if err != nil {
-				return nil, fmt.Errorf("get user has active passkey: %w", err)
+				return nil, fmt.Errorf("get identity has active passkey: %w", err)
 			}
 
 			org.UserHasPasskey = hasPasskeys
@@ -319,6 +334,78 @@ func (s *Store) ListOIDCOrganizations(ctx context.Context, req *intermediatev1.L
 	}, nil
 }
 
+// DiscoverHomeRealm returns the organization that owns an email's verified
+// domain, so that the user can be sent straight to its SSO connection.
+func (s *Store) DiscoverHomeRealm(ctx context.Context, req *intermediatev1.DiscoverHomeRealmRequest) (*intermediatev1.DiscoverHomeRealmResponse, error) {
+	_, q, _, rollback, err := s.tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback()
+
+	domain, err := emailaddr.Parse(req.Email)
+	if err != nil {
+		return nil, apierror.NewInvalidArgumentError("invalid email address", fmt.Errorf("parse email: %w", err))
+	}
+
+	qProject, err := q.GetProjectByID(ctx, authn.ProjectID(ctx))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apierror.NewNotFoundError("project not found", fmt.Errorf("get project by id: %w", err))
+		}
+
+		return nil, fmt.Errorf("get project by id: %w", err)
+	}
+
+	qOrganizations, err := q.ListHomeRealmOrganizations(ctx, queries.ListHomeRealmOrganizationsParams{
+		ProjectID: authn.ProjectID(ctx),
+		Domain:    domain,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list home realm organizations: %w", err)
+	}
+
+	var homeRealms []*intermediatev1.DiscoverHomeRealmResponse
+	for _, qOrg := range qOrganizations {
+		if qOrg.LogInWithSaml && qProject.LogInWithSaml {
+			qSAMLConnection, err := q.GetOrganizationPrimarySAMLConnection(ctx, qOrg.ID)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return nil, fmt.Errorf("get organization primary saml connection: %w", err)
+			}
+
+			if err == nil {
+				homeRealms = append(homeRealms, &intermediatev1.DiscoverHomeRealmResponse{
+					Organization: parseOrganization(qOrg, &qSAMLConnection, nil),
+					EnforceSso:   qOrg.EnforceSsoForDomains,
+				})
+				continue
+			}
+		}
+
+		if qOrg.LogInWithOidc && qProject.LogInWithOidc {
+			qOIDCConnection, err := q.GetOrganizationPrimaryOIDCConnection(ctx, qOrg.ID)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return nil, fmt.Errorf("get organization primary oidc connection: %w", err)
+			}
+
+			if err == nil {
+				homeRealms = append(homeRealms, &intermediatev1.DiscoverHomeRealmResponse{
+					Organization: parseOrganization(qOrg, nil, &qOIDCConnection),
+					EnforceSso:   qOrg.EnforceSsoForDomains,
+				})
+			}
+		}
+	}
+
+	// more than one organization with SSO may have verified the same domain;
+	// leave it to the user to pick between them
+	if len(homeRealms) != 1 {
+		return &intermediatev1.DiscoverHomeRealmResponse{}, nil
+	}
+
+	return homeRealms[0], nil
+}
+
 func (s *Store) SetOrganization(ctx context.Context, req *intermediatev1.SetOrganizationRequest) (*intermediatev1.SetOrganizationResponse, error) {
 	intermediateSession := authn.IntermediateSession(ctx)
 	intermediateSessionID, err := idformat.IntermediateSession.Parse(intermediateSession.Id)
@@ -456,30 +543,17 @@ func (s *Store) getVisibleOrganizations(ctx context.Context, q *queries.Queries,
 	return qOrgsDeduped, nil
 }
 
-func (s *Store) sendSyncOrganizationEvent(ctx context.Context, qOrg queries.Organization) error {
-	qProjectWebhookSettings, err := s.q.GetProjectWebhookSettings(ctx, authn.ProjectID(ctx))
-	if err != nil {
-		// We want to ignore this error if the project does not have webhook settings
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil
-		}
-
-		return fmt.Errorf("get project by id: %w", err)
-	}
-
-	message, err := s.svixClient.Message.Create(ctx, qProjectWebhookSettings.AppID, models.MessageIn{
+func (s *Store) sendSyncOrganizationEvent(ctx context.Context, q *queries.Queries, qOrg queries.Organization) error {
+	if err := s.enqueueWebhookMessage(ctx, q, authn.ProjectID(ctx), webhooks.Message{
 		EventType: "sync.organization",
 		Payload: map[string]interface{}{
 			"type":           "sync.organization",
 			"organizationId": idformat.Organization.Format(qOrg.ID),
 		},
-	}, nil)
-	if err != nil {
-		return fmt.Errorf("create message: %w", err)
+	}); err != nil {
+		return fmt.Errorf("enqueue webhook message: %w", err)
 	}
 
-	slog.InfoContext(ctx, "svix_message_created", "message_id", message.Id, "event_type", message.EventType, "organization_id", idformat.Organization.Format(qOrg.ID))
-
 	return nil
 }
 
@@ -62,8 +62,8 @@ func TestListSAMLOrganizations_OrganizationSAMLDisabled(t *testing.T) {
 
 	// Create the organization domain
 	_, err = u.Environment.DB.Exec(t.Context(), `
-INSERT INTO organization_domains (id, organization_id, domain)
-VALUES (gen_random_uuid(), $1::uuid, $2);
+INSERT INTO organization_domains (id, organization_id, domain, verified)
+VALUES (gen_random_uuid(), $1::uuid, $2, true);
 `,
 		organizationUUID,
 		environment.ConsoleDomain)
@@ -89,8 +89,8 @@ func TestListSAMLOrganizations_OrganizationSAMLEnabled(t *testing.T) {
 
 	// Create the organization domain
 	_, err = u.Environment.DB.Exec(t.Context(), `
-INSERT INTO organization_domains (id, organization_id, domain)
-VALUES (gen_random_uuid(), $1::uuid, $2);
+INSERT INTO organization_domains (id, organization_id, domain, verified)
+VALUES (gen_random_uuid(), $1::uuid, $2, true);
 `,
 		organizationUUID,
 		environment.ConsoleDomain)
@@ -116,8 +116,8 @@ func TestListSAMLOrganizations_OrganizationSAMLNonPrimary(t *testing.T) {
 
 	// Create the organization domain
 	_, err = u.Environment.DB.Exec(t.Context(), `
-INSERT INTO organization_domains (id, organization_id, domain)
-VALUES (gen_random_uuid(), $1::uuid, $2);
+INSERT INTO organization_domains (id, organization_id, domain, verified)
+VALUES (gen_random_uuid(), $1::uuid, $2, true);
 `,
 		organizationUUID,
 		environment.ConsoleDomain)
@@ -157,8 +157,8 @@ func TestListSAMLOrganizations_ForActiveDomain(t *testing.T) {
 
 	// Create the organization domain
 	_, err = u.Environment.DB.Exec(t.Context(), `
-INSERT INTO organization_domains (id, organization_id, domain)
-VALUES (gen_random_uuid(), $1::uuid, $2);
+INSERT INTO organization_domains (id, organization_id, domain, verified)
+VALUES (gen_random_uuid(), $1::uuid, $2, true);
 `,
 		uuid.UUID(organizationUUID).String(),
 		domain)
@@ -183,6 +183,45 @@ VALUES ($1::uuid, $2::uuid, true, 'https://idp.example.com/saml/redirect', ''::b
 	require.Equal(t, idformat.SAMLConnection.Format(samlConnectionID), res.Organizations[0].PrimarySamlConnectionId)
 }
 
+func TestListSAMLOrganizations_UnverifiedDomain(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	organizationID := u.NewOrganization(t, &backendv1.Organization{
+		DisplayName:   "Test Organization",
+		LogInWithSaml: refOrNil(true),
+	})
+	organizationUUID, err := idformat.Organization.Parse(organizationID)
+	require.NoError(t, err)
+
+	domain, err := emailaddr.Parse(authn.IntermediateSession(ctx).Email)
+	require.NoError(t, err)
+
+	// Create an organization domain that has not been verified
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO organization_domains (id, organization_id, domain)
+VALUES (gen_random_uuid(), $1::uuid, $2);
+`,
+		uuid.UUID(organizationUUID).String(),
+		domain)
+	require.NoError(t, err)
+
+	// Create a SAML connection for the organization
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO saml_connections (id, organization_id, is_primary, idp_redirect_url, idp_x509_certificate, idp_entity_id)
+VALUES (gen_random_uuid(), $1::uuid, true, 'https://idp.example.com/saml/redirect', ''::bytea, 'https://idp.example.com/saml/idp');
+`,
+		uuid.UUID(organizationUUID).String())
+	require.NoError(t, err)
+
+	res, err := u.Store.ListSAMLOrganizations(ctx, &intermediatev1.ListSAMLOrganizationsRequest{
+		Email: authn.IntermediateSession(ctx).Email,
+	})
+	require.NoError(t, err)
+	require.Empty(t, res.Organizations)
+}
+
 func TestListSAMLOrganizations_ForDifferentDomain(t *testing.T) {
 	t.Parallel()
 
@@ -269,8 +308,8 @@ func TestListOIDCOrganizations_OrganizationOIDCDisabled(t *testing.T) {
 
 	// Create the organization domain
 	_, err = u.Environment.DB.Exec(t.Context(), `
-INSERT INTO organization_domains (id, organization_id, domain)
-VALUES (gen_random_uuid(), $1::uuid, $2);
+INSERT INTO organization_domains (id, organization_id, domain, verified)
+VALUES (gen_random_uuid(), $1::uuid, $2, true);
 `,
 		organizationUUID,
 		environment.ConsoleDomain)
@@ -296,8 +335,8 @@ func TestListOIDCOrganizations_OrganizationOIDCEnabled(t *testing.T) {
 
 	// Create the organization domain
 	_, err = u.Environment.DB.Exec(t.Context(), `
-INSERT INTO organization_domains (id, organization_id, domain)
-VALUES (gen_random_uuid(), $1::uuid, $2);
+INSERT INTO organization_domains (id, organization_id, domain, verified)
+VALUES (gen_random_uuid(), $1::uuid, $2, true);
 `,
 		organizationUUID,
 		environment.ConsoleDomain)
@@ -323,8 +362,8 @@ func TestListOIDCOrganizations_OrganizationOIDCNonPrimary(t *testing.T) {
 
 	// Create the organization domain
 	_, err = u.Environment.DB.Exec(t.Context(), `
-INSERT INTO organization_domains (id, organization_id, domain)
-VALUES (gen_random_uuid(), $1::uuid, $2);
+INSERT INTO organization_domains (id, organization_id, domain, verified)
+VALUES (gen_random_uuid(), $1::uuid, $2, true);
 `,
 		organizationUUID,
 		environment.ConsoleDomain)
@@ -364,8 +403,8 @@ func TestListOIDCOrganizations_ForActiveDomain(t *testing.T) {
 
 	// Create the organization domain
 	_, err = u.Environment.DB.Exec(t.Context(), `
-INSERT INTO organization_domains (id, organization_id, domain)
-VALUES (gen_random_uuid(), $1::uuid, $2);
+INSERT INTO organization_domains (id, organization_id, domain, verified)
+VALUES (gen_random_uuid(), $1::uuid, $2, true);
 `,
 		uuid.UUID(organizationUUID).String(),
 		domain)
@@ -426,3 +465,54 @@ VALUES ($1::uuid, $2::uuid, true, 'https://accounts.google.com/.well-known/openi
 	require.NoError(t, err)
 	require.Empty(t, res.Organizations)
 }
+
+func TestDiscoverHomeRealm(t *testing.T) {
+	t.Parallel()
+
+	ctx, u := newTestUtil(t)
+
+	organizationID := u.NewOrganization(t, &backendv1.Organization{
+		DisplayName:   "Test Organization",
+		LogInWithSaml: refOrNil(true),
+	})
+	organizationUUID, err := idformat.Organization.Parse(organizationID)
+	require.NoError(t, err)
+
+	_, err = u.Environment.DB.Exec(t.Context(), `
+UPDATE organizations SET enforce_sso_for_domains = true WHERE id = $1::uuid;
+`,
+		uuid.UUID(organizationUUID).String())
+	require.NoError(t, err)
+
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO organization_domains (id, organization_id, domain, verified)
+VALUES (gen_random_uuid(), $1::uuid, 'sso.example.com', true);
+`,
+		uuid.UUID(organizationUUID).String())
+	require.NoError(t, err)
+
+	samlConnectionID := uuid.New()
+	_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO saml_connections (id, organization_id, is_primary, idp_redirect_url, idp_x509_certificate, idp_entity_id)
+VALUES ($1::uuid, $2::uuid, true, 'https://idp.example.com/saml/redirect', ''::bytea, 'https://idp.example.com/saml/idp');
+`,
+		samlConnectionID.String(),
+		uuid.UUID(organizationUUID).String())
+	require.NoError(t, err)
+
+	res, err := u.Store.DiscoverHomeRealm(ctx, &intermediatev1.DiscoverHomeRealmRequest{
+		Email: "alice@sso.example.com",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, res.Organization)
+	require.Equal(t, organizationID, res.Organization.Id)
+	require.Equal(t, idformat.SAMLConnection.Format(samlConnectionID), res.Organization.PrimarySamlConnectionId)
+	require.True(t, res.EnforceSso)
+
+	res, err = u.Store.DiscoverHomeRealm(ctx, &intermediatev1.DiscoverHomeRealmRequest{
+		Email: "alice@other.example.com",
+	})
+	require.NoError(t, err)
+	require.Nil(t, res.Organization)
+	require.False(t, res.EnforceSso)
+}
@@ -115,14 +115,14 @@ func (s *Store) IssuePasskeyChallenge(ctx context.Context, req *intermediatev1.I
 		return nil, fmt.Errorf("get organization by id: %w", err)
 	}
 
-	qMatchingUser, err := s.matchUser(ctx, q, qOrg, qIntermediateSession)
+	qMatchingIdentity, err := s.matchIdentity(ctx, q, qOrg, qIntermediateSession)
 	if err != nil {
-		return nil, fmt.Errorf("match user: %w", err)
+		return nil, fmt.Errorf("match identity: %w", err)
 	}
 
-	credentialIDs, err := q.GetUserPasskeyCredentialIDs(ctx, qMatchingUser.ID)
+	credentialIDs, err := q.GetIdentityPasskeyCredentialIDs(ctx, qMatchingIdentity.ID)
 	if err != nil {
-		return nil, fmt.Errorf("get user passkey credential ids: %w", err)
+		return nil, fmt.Errorf("get identity passkey credential ids: %w", err)
 	}
 
 	var challenge [32]byte
@@ -169,14 +169,14 @@ func (s *Store) VerifyPasskey(ctx context.Context, req *intermediatev1.VerifyPas
 		return nil, fmt.Errorf("get organization by id: %w", err)
 	}
 
-	qMatchingUser, err := s.matchUser(ctx, q, qOrg, qIntermediateSession)
+	qMatchingIdentity, err := s.matchIdentity(ctx, q, qOrg, qIntermediateSession)
 	if err != nil {
-		return nil, fmt.Errorf("match user: %w", err)
+		return nil, fmt.Errorf("match identity: %w", err)
 	}
 
 	qPasskey, err := q.GetPasskeyByCredentialID(ctx, queries.GetPasskeyByCredentialIDParams{
 		CredentialID: req.CredentialId,
-		UserID:       qMatchingUser.ID,
+		IdentityID:   qMatchingIdentity.ID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("get passkey by credential id: %w", err)
@@ -217,6 +217,14 @@ func (s *Store) VerifyPasskey(ctx context.Context, req *intermediatev1.VerifyPas
 		return nil, fmt.Errorf("update intermediate session passkey verified: %w", err)
 	}
 
+	// the passkey proves ownership of the identity
+	if _, err := q.UpdateIntermediateSessionVerifiedIdentityID(ctx, queries.UpdateIntermediateSessionVerifiedIdentityIDParams{
+		ID:                 authn.IntermediateSessionID(ctx),
+		VerifiedIdentityID: &qMatchingIdentity.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("update intermediate session verified identity id: %w", err)
+	}
+
 	if err := commit(); err != nil {
 		return nil, fmt.Errorf("commit: %w", err)
 	}
@@ -225,8 +233,8 @@ func (s *Store) VerifyPasskey(ctx context.Context, req *intermediatev1.VerifyPas
 }
 
 func (s *Store) checkShouldRegisterPasskey(ctx context.Context, q *queries.Queries) error {
-	// don't register passkeys if you're already matching a user, and that user
-	// has at least one active passkey
+	// don't register passkeys if you're already matching an identity, and that
+	// identity has at least one active passkey
 
 	qIntermediateSession, err := q.GetIntermediateSessionByID(ctx, authn.IntermediateSessionID(ctx))
 	if err != nil {
@@ -241,20 +249,20 @@ func (s *Store) checkShouldRegisterPasskey(ctx context.Context, q *queries.Queri
 		return fmt.Errorf("get organization by id: %w", err)
 	}
 
-	qUser, err := s.matchUser(ctx, q, qOrg, qIntermediateSession)
+	qIdentity, err := s.matchIdentity(ctx, q, qOrg, qIntermediateSession)
 	if err != nil {
-		return fmt.Errorf("match user: %w", err)
+		return fmt.Errorf("match identity: %w", err)
 	}
 
-	// no matching user; it's ok to register passkeys
-	if qUser == nil {
+	// no matching identity; it's ok to register passkeys
+	if qIdentity == nil {
 		return nil
 	}
 
-	// does the matching user have any active passkeys?
-	hasPasskeys, err := q.GetUserHasActivePasskey(ctx, qUser.ID)
+	// does the matching identity have any active passkeys?
+	hasPasskeys, err := q.GetIdentityHasActivePasskey(ctx, qIdentity.ID)
 	if err != nil {
-		return fmt.Errorf("get user has passkey: %w", err)
+		return fmt.Errorf("get identity has passkey: %w", err)
 	}
 
 	if hasPasskeys {
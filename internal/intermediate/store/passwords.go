@@ -6,19 +6,20 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
-	"html/template"
 	"log/slog"
+	"slices"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/sesv2"
-	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/tesseral-labs/tesseral/internal/emailtemplates"
+
 	"github.com/google/uuid"
 	"github.com/tesseral-labs/tesseral/internal/bcryptcost"
 	"github.com/tesseral-labs/tesseral/internal/common/apierror"
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/authn"
 	intermediatev1 "github.com/tesseral-labs/tesseral/internal/intermediate/gen/tesseral/intermediate/v1"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/store/queries"
+	"github.com/tesseral-labs/tesseral/internal/passwordhash"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -89,15 +90,15 @@ func (s *Store) RegisterPassword(ctx context.Context, req *intermediatev1.Regist
 		return nil, apierror.NewFailedPreconditionError("email not verified", fmt.Errorf("email not verified"))
 	}
 
-	// only allow password registration if the matching user doesn't already
-	// have one, or if the intermediate session has verified a password reset
-	// code
-	qUser, err := s.matchUser(ctx, q, qOrg, qIntermediateSession)
+	// only allow password registration if the matching identity doesn't
+	// already have one, or if the intermediate session has verified a password
+	// reset code
+	qIdentity, err := s.matchIdentity(ctx, q, qOrg, qIntermediateSession)
 	if err != nil {
-		return nil, fmt.Errorf("match user: %w", err)
+		return nil, fmt.Errorf("match identity: %w", err)
 	}
 
-	if qUser != nil && qUser.PasswordBcrypt != nil && !qIntermediateSession.PasswordResetCodeVerified {
+	if qIdentity != nil && (qIdentity.PasswordBcrypt != nil || qIdentity.ImportedPasswordHash != nil) && !qIntermediateSession.PasswordResetCodeVerified {
 		return nil, apierror.NewFailedPreconditionError("user already has password configured", fmt.Errorf("user already has password configured"))
 	}
 
@@ -179,7 +180,8 @@ func (s *Store) VerifyPassword(ctx context.Context, req *intermediatev1.VerifyPa
 	//
 	// 1. The organization must have passwords enabled,
 	// 2. The intermediate session must have a verified email, and
-	// 3. A user in that org must have the same email.
+	// 3. The intermediate session must match an identity. That identity may
+	//    not yet have a user in the org.
 	if !qOrg.LogInWithPassword {
 		return nil, apierror.NewFailedPreconditionError("password authentication not enabled", nil)
 	}
@@ -193,16 +195,16 @@ func (s *Store) VerifyPassword(ctx context.Context, req *intermediatev1.VerifyPa
 		return nil, apierror.NewFailedPreconditionError("email not verified", nil)
 	}
 
-	qMatchingUser, err := s.matchEmailUser(ctx, q, qOrg, qIntermediateSession)
+	qMatchingIdentity, err := s.matchIdentity(ctx, q, qOrg, qIntermediateSession)
 	if err != nil {
-		return nil, fmt.Errorf("match email user: %w", err)
+		return nil, fmt.Errorf("match identity: %w", err)
 	}
 
-	if qMatchingUser == nil {
+	if qMatchingIdentity == nil {
 		return nil, apierror.NewFailedPreconditionError("no corresponding user found", nil)
 	}
 
-	if err := s.attemptMatchPassword(ctx, q, *qMatchingUser, req.Password); err != nil {
+	if err := s.attemptMatchPassword(ctx, q, *qMatchingIdentity, req.Password); err != nil {
 		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
 			return nil, apierror.NewIncorrectPasswordError("incorrect password", nil)
 		}
@@ -222,11 +224,11 @@ func (s *Store) VerifyPassword(ctx context.Context, req *intermediatev1.VerifyPa
 	}
 
 	passwordBcrypt := string(passwordBcryptBytes)
-	if _, err := q.UpdateUserPasswordBcrypt(ctx, queries.UpdateUserPasswordBcryptParams{
-		ID:             qMatchingUser.ID,
+	if _, err := q.UpdateIdentityPasswordBcrypt(ctx, queries.UpdateIdentityPasswordBcryptParams{
+		ID:             qMatchingIdentity.ID,
 		PasswordBcrypt: &passwordBcrypt,
 	}); err != nil {
-		return nil, fmt.Errorf("update user password bcrypt: %w", err)
+		return nil, fmt.Errorf("update identity password bcrypt: %w", err)
 	}
 
 	if _, err := q.UpdateIntermediateSessionPasswordVerified(ctx, queries.UpdateIntermediateSessionPasswordVerifiedParams{
@@ -236,6 +238,14 @@ func (s *Store) VerifyPassword(ctx context.Context, req *intermediatev1.VerifyPa
 		return nil, fmt.Errorf("update intermediate session password verified: %w", err)
 	}
 
+	// the password proves ownership of the identity
+	if _, err := q.UpdateIntermediateSessionVerifiedIdentityID(ctx, queries.UpdateIntermediateSessionVerifiedIdentityIDParams{
+		ID:                 qIntermediateSession.ID,
+		VerifiedIdentityID: &qMatchingIdentity.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("update intermediate session verified identity id: %w", err)
+	}
+
 	if err := commit(); err != nil {
 		return nil, fmt.Errorf("commit: %w", err)
 	}
@@ -247,11 +257,12 @@ func (s *Store) VerifyPassword(ctx context.Context, req *intermediatev1.VerifyPa
 // password.
 func (s *Store) logInWithPassword(ctx context.Context, req *intermediatev1.VerifyPasswordRequest) (*intermediatev1.VerifyPasswordResponse, error) {
 	// In this flow, we don't require verifying an email or any other previous
-	// state on the intermediate session. We issue a user an intermediate
-	// session for the unique user with that email and password.
+	// state on the intermediate session. We issue an intermediate session for
+	// the unique identity with that email and password.
 	//
-	// If there is no unique password-having user with the given email, then
-	// refuse to proceed.
+	// If there is no unique password-having identity with the given email,
+	// then refuse to proceed. If that identity has users in several
+	// organizations, the user chooses among them afterwards.
 
 	_, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
@@ -281,13 +292,25 @@ func (s *Store) logInWithPassword(ctx context.Context, req *intermediatev1.Verif
 		return nil, fmt.Errorf("get users by project id and email: %w", err)
 	}
 
-	if len(qUsers) != 1 {
+	// users of the same identity share a password, so the password identifies
+	// an identity rather than a user
+	var identityIDs []uuid.UUID
+	for _, qUser := range qUsers {
+		if !slices.Contains(identityIDs, qUser.IdentityID) {
+			identityIDs = append(identityIDs, qUser.IdentityID)
+		}
+	}
+
+	if len(identityIDs) != 1 {
 		return nil, apierror.NewPasswordsUnavailableForEmailError("password-based login not available for this email", nil)
 	}
 
-	qMatchingUser := qUsers[0]
+	qMatchingIdentity, err := q.GetIdentityByID(ctx, identityIDs[0])
+	if err != nil {
+		return nil, fmt.Errorf("get identity by id: %w", err)
+	}
 
-	if err := s.attemptMatchPassword(ctx, q, qMatchingUser, req.Password); err != nil {
+	if err := s.attemptMatchPassword(ctx, q, qMatchingIdentity, req.Password); err != nil {
 		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
 			return nil, apierror.NewIncorrectPasswordError("incorrect password", nil)
 		}
@@ -301,11 +324,11 @@ func (s *Store) logInWithPassword(ctx context.Context, req *intermediatev1.Verif
 	}
 
 	passwordBcrypt := string(passwordBcryptBytes)
-	if _, err := q.UpdateUserPasswordBcrypt(ctx, queries.UpdateUserPasswordBcryptParams{
-		ID:             qMatchingUser.ID,
+	if _, err := q.UpdateIdentityPasswordBcrypt(ctx, queries.UpdateIdentityPasswordBcryptParams{
+		ID:             qMatchingIdentity.ID,
 		PasswordBcrypt: &passwordBcrypt,
 	}); err != nil {
-		return nil, fmt.Errorf("update user password bcrypt: %w", err)
+		return nil, fmt.Errorf("update identity password bcrypt: %w", err)
 	}
 
 	if _, err := q.UpdateIntermediateSessionEmail(ctx, queries.UpdateIntermediateSessionEmailParams{
@@ -320,13 +343,28 @@ func (s *Store) logInWithPassword(ctx context.Context, req *intermediatev1.Verif
 		return nil, fmt.Errorf("update intermediate session email verification challenge completed: %w", err)
 	}
 
+	// if the identity has several users, leave the organization unset so that
+	// the user chooses one
+	var organizationID *uuid.UUID
+	if len(qUsers) == 1 {
+		organizationID = &qUsers[0].OrganizationID
+	}
+
 	if _, err := q.UpdateIntermediateSessionPasswordVerified(ctx, queries.UpdateIntermediateSessionPasswordVerifiedParams{
 		ID:             authn.IntermediateSessionID(ctx),
-		OrganizationID: &qMatchingUser.OrganizationID,
+		OrganizationID: organizationID,
 	}); err != nil {
 		return nil, fmt.Errorf("update intermediate session password verified: %w", err)
 	}
 
+	// the password proves ownership of the identity
+	if _, err := q.UpdateIntermediateSessionVerifiedIdentityID(ctx, queries.UpdateIntermediateSessionVerifiedIdentityIDParams{
+		ID:                 authn.IntermediateSessionID(ctx),
+		VerifiedIdentityID: &qMatchingIdentity.ID,
+	}); err != nil {
+		return nil, fmt.Errorf("update intermediate session verified identity id: %w", err)
+	}
+
 	if _, err := q.UpdateIntermediateSessionPrimaryAuthFactor(ctx, queries.UpdateIntermediateSessionPrimaryAuthFactorParams{
 		ID:                authn.IntermediateSessionID(ctx),
 		PrimaryAuthFactor: refOrNil(queries.PrimaryAuthFactorPassword),
@@ -341,44 +379,44 @@ func (s *Store) logInWithPassword(ctx context.Context, req *intermediatev1.Verif
 	return &intermediatev1.VerifyPasswordResponse{}, nil
 }
 
-func (s *Store) attemptMatchPassword(ctx context.Context, q *queries.Queries, qUser queries.User, password string) error {
-	if qUser.PasswordBcrypt == nil {
+func (s *Store) attemptMatchPassword(ctx context.Context, q *queries.Queries, qIdentity queries.Identity, password string) error {
+	if qIdentity.PasswordBcrypt == nil && qIdentity.ImportedPasswordHash == nil {
 		return apierror.NewFailedPreconditionError("user does not have password configured", nil)
 	}
 
-	if qUser.PasswordLockoutExpireTime != nil && qUser.PasswordLockoutExpireTime.After(time.Now()) {
+	if qIdentity.PasswordLockoutExpireTime != nil && qIdentity.PasswordLockoutExpireTime.After(time.Now()) {
 		return apierror.NewFailedPreconditionError("too many password attempts; user is temporarily locked out", nil)
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(*qUser.PasswordBcrypt), []byte(password)); err != nil {
-		attempts := qUser.FailedPasswordAttempts + 1
+	if err := matchPassword(qIdentity, password); err != nil {
+		attempts := qIdentity.FailedPasswordAttempts + 1
 		if attempts >= passwordLockoutAttempts {
 			// lock the user out
 			passwordLockoutExpireTime := time.Now().Add(passwordLockoutDuration)
-			if _, err := q.UpdateUserPasswordLockoutExpireTime(ctx, queries.UpdateUserPasswordLockoutExpireTimeParams{
-				ID:                        qUser.ID,
+			if _, err := q.UpdateIdentityPasswordLockoutExpireTime(ctx, queries.UpdateIdentityPasswordLockoutExpireTimeParams{
+				ID:                        qIdentity.ID,
 				PasswordLockoutExpireTime: &passwordLockoutExpireTime,
 			}); err != nil {
-				return fmt.Errorf("update user password lockout expire time: %w", err)
+				return fmt.Errorf("update identity password lockout expire time: %w", err)
 			}
 
 			// reset fail count
-			if _, err := q.UpdateUserFailedPasswordAttempts(ctx, queries.UpdateUserFailedPasswordAttemptsParams{
-				ID:                     qUser.ID,
+			if _, err := q.UpdateIdentityFailedPasswordAttempts(ctx, queries.UpdateIdentityFailedPasswordAttemptsParams{
+				ID:                     qIdentity.ID,
 				FailedPasswordAttempts: 0,
 			}); err != nil {
-				return fmt.Errorf("update user failed password attempts: %w", err)
+				return fmt.Errorf("update identity failed password attempts: %w", err)
 			}
 
 			return apierror.NewFailedPreconditionError("too many password attempts; user is temporarily locked out", nil)
 		}
 
 		// update fail count, but do not lock out
-		if _, err := q.UpdateUserFailedPasswordAttempts(ctx, queries.UpdateUserFailedPasswordAttemptsParams{
-			ID:                     qUser.ID,
+		if _, err := q.UpdateIdentityFailedPasswordAttempts(ctx, queries.UpdateIdentityFailedPasswordAttemptsParams{
+			ID:                     qIdentity.ID,
 			FailedPasswordAttempts: attempts,
 		}); err != nil {
-			return fmt.Errorf("update user failed password attempts: %w", err)
+			return fmt.Errorf("update identity failed password attempts: %w", err)
 		}
 
 		return fmt.Errorf("bcrypt: %w", err)
@@ -387,6 +425,28 @@ func (s *Store) attemptMatchPassword(ctx context.Context, q *queries.Queries, qU
 	return nil
 }
 
+// matchPassword returns bcrypt.ErrMismatchedHashAndPassword if password does
+// not match the identity's password.
+//
+// Identities imported from other providers may have a non-bcrypt password
+// hash instead. Callers replace it with a bcrypt hash once the password
+// matches; see UpdateIdentityPasswordBcrypt.
+func matchPassword(qIdentity queries.Identity, password string) error {
+	if qIdentity.PasswordBcrypt != nil {
+		return bcrypt.CompareHashAndPassword([]byte(*qIdentity.PasswordBcrypt), []byte(password))
+	}
+
+	ok, err := passwordhash.Verify(*qIdentity.ImportedPasswordHash, password)
+	if err != nil {
+		return fmt.Errorf("verify imported password hash: %w", err)
+	}
+	if !ok {
+		return bcrypt.ErrMismatchedHashAndPassword
+	}
+
+	return nil
+}
+
 func (s *Store) IssuePasswordResetCode(ctx context.Context, req *intermediatev1.IssuePasswordResetCodeRequest) (*intermediatev1.IssuePasswordResetCodeResponse, error) {
 	_, q, commit, rollback, err := s.tx(ctx)
 	if err != nil {
@@ -454,53 +514,25 @@ func (s *Store) IssuePasswordResetCode(ctx context.Context, req *intermediatev1.
 	return &intermediatev1.IssuePasswordResetCodeResponse{}, nil
 }
 
-var passwordResetEmailBodyTmpl = template.Must(template.New("emailVerificationEmailBody").Parse(`Hello,
-
-Someone has requested a password reset for your {{ .ProjectDisplayName }} account. If you did not request this, please ignore this email.
-
-To continue logging in to {{ .ProjectDisplayName }}, please go back to the "Forgot password" page and enter this verification code:
-
-{{ .PasswordResetCode }}
-
-If you did not request this verification, please ignore this email.
-`))
-
 func (s *Store) sendPasswordResetCode(ctx context.Context, toAddress string, passwordResetCode string) error {
 	qProject, err := s.q.GetProjectByID(ctx, authn.ProjectID(ctx))
 	if err != nil {
 		return fmt.Errorf("get project by id: %w", err)
 	}
 
-	subject := fmt.Sprintf("%s - Reset password", qProject.DisplayName)
+	email, err := s.renderEmail(ctx, qProject, emailtemplates.TypePasswordReset, map[string]string{
+		"PasswordResetCode": passwordResetCode,
+	})
+	if err != nil {
+		return fmt.Errorf("render password reset email: %w", err)
+	}
 
-	var body bytes.Buffer
-	if err := passwordResetEmailBodyTmpl.Execute(&body, struct {
-		ProjectDisplayName string
-		PasswordResetCode  string
-	}{
-		ProjectDisplayName: qProject.DisplayName,
-		PasswordResetCode:  passwordResetCode,
-	}); err != nil {
-		return fmt.Errorf("execute password reset email body template: %w", err)
-	}
-
-	if _, err := s.ses.SendEmail(ctx, &sesv2.SendEmailInput{
-		Content: &types.EmailContent{
-			Simple: &types.Message{
-				Subject: &types.Content{
-					Data: &subject,
-				},
-				Body: &types.Body{
-					Text: &types.Content{
-						Data: aws.String(body.String()),
-					},
-				},
-			},
-		},
-		Destination: &types.Destination{
-			ToAddresses: []string{toAddress},
-		},
-		FromEmailAddress: aws.String(fmt.Sprintf("noreply@%s", qProject.EmailSendFromDomain)),
+	if err := s.emailSender.Send(ctx, emailsender.Message{
+		From:     fmt.Sprintf("noreply@%s", qProject.EmailSendFromDomain),
+		To:       []string{toAddress},
+		Subject:  email.Subject,
+		TextBody: email.TextBody,
+		HTMLBody: email.HTMLBody,
 	}); err != nil {
 		return fmt.Errorf("send email: %w", err)
 	}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/intermediate/authn"
+	intermediatev1 "github.com/tesseral-labs/tesseral/internal/intermediate/gen/tesseral/intermediate/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func TestVerifyPassword_LogInWithPassword(t *testing.T) {
+	t.Parallel()
+
+	for _, userCount := range []int{1, 2} {
+		ctx, u := newTestUtil(t)
+
+		projectUUID, err := idformat.Project.Parse(u.ProjectID)
+		require.NoError(t, err)
+		intermediateSessionUUID, err := idformat.IntermediateSession.Parse(authn.IntermediateSession(ctx).Id)
+		require.NoError(t, err)
+
+		secretToken := uuid.New()
+		_, err = u.Environment.DB.Exec(t.Context(), `
+INSERT INTO intermediate_sessions (id, project_id, expire_time, secret_token_sha256)
+  VALUES ($1::uuid, $2::uuid, now() + interval '15 minutes', $3);
+`,
+			uuid.UUID(intermediateSessionUUID).String(),
+			uuid.UUID(projectUUID).String(),
+			secretToken[:],
+		)
+		require.NoError(t, err)
+
+		// users in several organizations share an identity, and so a password
+		var organizationIDs []string
+		for range userCount {
+			organizationID := u.NewOrganization(t, &backendv1.Organization{
+				DisplayName:       "Test Organization",
+				LogInWithPassword: refOrNil(true),
+			})
+			u.Environment.NewUser(t, organizationID, &backendv1.User{Email: "user@example.com"})
+			organizationIDs = append(organizationIDs, organizationID)
+		}
+
+		_, err = u.Store.VerifyPassword(ctx, &intermediatev1.VerifyPasswordRequest{
+			Email:    "user@example.com",
+			Password: "password",
+		})
+		require.NoError(t, err)
+
+		var organizationID, verifiedIdentityID *uuid.UUID
+		err = u.Environment.DB.QueryRow(t.Context(), `SELECT organization_id, verified_identity_id FROM intermediate_sessions WHERE id = $1`, uuid.UUID(intermediateSessionUUID)).Scan(&organizationID, &verifiedIdentityID)
+		require.NoError(t, err)
+		require.NotNil(t, verifiedIdentityID)
+
+		// with several users, the user chooses an organization afterwards
+		if userCount == 1 {
+			require.NotNil(t, organizationID)
+			require.Equal(t, organizationIDs[0], idformat.Organization.Format(*organizationID))
+		} else {
+			require.Nil(t, organizationID)
+		}
+	}
+}
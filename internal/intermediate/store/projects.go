@@ -363,17 +363,33 @@ func (s *Store) createProjectForCurrentUser(ctx context.Context, q *queries.Quer
 		}
 	}
 
-	// create a user from the intermediate session
+	// create a user from the intermediate session, joining the dogfood
+	// identity with the same email if the intermediate session has proven
+	// ownership of it
+	qIdentity, err := s.newUserIdentity(ctx, q, *qIntermediateSession)
+	if err != nil {
+		return nil, fmt.Errorf("new user identity: %w", err)
+	}
+
+	if qIntermediateSession.NewUserPasswordBcrypt != nil {
+		if _, err := q.UpdateIdentityPasswordBcrypt(ctx, queries.UpdateIdentityPasswordBcryptParams{
+			ID:             qIdentity.ID,
+			PasswordBcrypt: qIntermediateSession.NewUserPasswordBcrypt,
+		}); err != nil {
+			return nil, fmt.Errorf("update identity password bcrypt: %w", err)
+		}
+	}
+
 	qUser, err := q.CreateUser(ctx, queries.CreateUserParams{
 		ID:                uuid.New(),
 		OrganizationID:    qOrganization.ID,
+		IdentityID:        qIdentity.ID,
 		Email:             *qIntermediateSession.Email,
 		DisplayName:       qIntermediateSession.UserDisplayName,
 		ProfilePictureUrl: qIntermediateSession.ProfilePictureUrl,
 		GoogleUserID:      qIntermediateSession.GoogleUserID,
 		MicrosoftUserID:   qIntermediateSession.MicrosoftUserID,
 		IsOwner:           true,
-		PasswordBcrypt:    qIntermediateSession.NewUserPasswordBcrypt,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create user: %w", err)
@@ -442,7 +458,7 @@ func (s *Store) createProjectForCurrentUser(ctx context.Context, q *queries.Quer
 		return nil, fmt.Errorf("create project trusted domain: %w", err)
 	}
 
-	// Create a Svix application for the project to send webhooks to
+	// Create a webhook application for the project to send webhooks to
 	if _, err := s.createProjectWebhookSettings(ctx, q, qProject); err != nil {
 		return nil, fmt.Errorf("create webhook: %w", err)
 	}
@@ -7,19 +7,19 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/sesv2"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	stripeclient "github.com/stripe/stripe-go/v82/client"
-	svix "github.com/svix/svix-webhooks/go"
 	auditlogstore "github.com/tesseral-labs/tesseral/internal/auditlog/store"
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
 	"github.com/tesseral-labs/tesseral/internal/githuboauth"
 	"github.com/tesseral-labs/tesseral/internal/googleoauth"
 	"github.com/tesseral-labs/tesseral/internal/hibp"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/microsoftoauth"
 	"github.com/tesseral-labs/tesseral/internal/pagetoken"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
 )
 
 type Store struct {
@@ -34,7 +34,7 @@ type Store struct {
 	kms                                   *kms.Client
 	pageEncoder                           pagetoken.Encoder
 	q                                     *queries.Queries
-	ses                                   *sesv2.Client
+	emailSender                           emailsender.Sender
 	sessionSigningKeyKmsKeyID             string
 	githubOAuthClientSecretsKMSKeyID      string
 	googleOAuthClientSecretsKMSKeyID      string
@@ -46,7 +46,7 @@ type Store struct {
 	userContentBaseUrl                    string
 	s3UserContentBucketName               string
 	stripeClient                          *stripeclient.API
-	svixClient                            *svix.Svix
+	webhookDispatcher                     webhooks.Dispatcher
 	auditlogStore                         *auditlogstore.Store
 	defaultGoogleOAuthClientID            string
 	defaultGoogleOAuthClientSecret        string
@@ -68,7 +68,7 @@ type NewStoreParams struct {
 	S3                                    *s3.Client
 	KMS                                   *kms.Client
 	PageEncoder                           pagetoken.Encoder
-	SES                                   *sesv2.Client
+	EmailSender                           emailsender.Sender
 	SessionSigningKeyKmsKeyID             string
 	GithubOAuthClientSecretsKMSKeyID      string
 	GoogleOAuthClientSecretsKMSKeyID      string
@@ -80,7 +80,7 @@ type NewStoreParams struct {
 	UserContentBaseUrl                    string
 	S3UserContentBucketName               string
 	StripeClient                          *stripeclient.API
-	SvixClient                            *svix.Svix
+	WebhookDispatcher                     webhooks.Dispatcher
 	AuditlogStore                         *auditlogstore.Store
 	DefaultGoogleOAuthClientID            string
 	DefaultGoogleOAuthClientSecret        string
@@ -108,7 +108,7 @@ func New(p NewStoreParams) *Store {
 		kms:                                   p.KMS,
 		pageEncoder:                           p.PageEncoder,
 		q:                                     queries.New(p.DB),
-		ses:                                   p.SES,
+		emailSender:                           p.EmailSender,
 		sessionSigningKeyKmsKeyID:             p.SessionSigningKeyKmsKeyID,
 		githubOAuthClient:                     p.GithubOAuthClient,
 		googleOAuthClient:                     p.GoogleOAuthClient,
@@ -120,7 +120,7 @@ func New(p NewStoreParams) *Store {
 		userContentBaseUrl:                    p.UserContentBaseUrl,
 		s3UserContentBucketName:               p.S3UserContentBucketName,
 		stripeClient:                          p.StripeClient,
-		svixClient:                            p.SvixClient,
+		webhookDispatcher:                     p.WebhookDispatcher,
 		auditlogStore:                         p.AuditlogStore,
 		defaultGoogleOAuthClientID:            p.DefaultGoogleOAuthClientID,
 		defaultGoogleOAuthClientSecret:        p.DefaultGoogleOAuthClientSecret,
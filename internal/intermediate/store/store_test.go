@@ -6,7 +6,9 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	auditlogstore "github.com/tesseral-labs/tesseral/internal/auditlog/store"
 	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/emailsender"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/authn"
 	intermediatev1 "github.com/tesseral-labs/tesseral/internal/intermediate/gen/tesseral/intermediate/v1"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
@@ -29,9 +31,11 @@ type testUtil struct {
 	Store       *Store
 	Environment *storetesting.Environment
 	ProjectID   string
+	EmailSender *emailsender.MemorySender
 }
 
 func newTestUtil(t *testing.T) (context.Context, *testUtil) {
+	emailSender := &emailsender.MemorySender{}
 	store := New(NewStoreParams{
 		DB:                        environment.DB,
 		S3:                        environment.S3.Client,
@@ -39,6 +43,11 @@ func newTestUtil(t *testing.T) (context.Context, *testUtil) {
 		SessionSigningKeyKmsKeyID: environment.KMS.SessionSigningKeyID,
 		DogfoodProjectID:          environment.DogfoodProjectID,
 		ConsoleDomain:             environment.ConsoleDomain,
+		EmailSender:               emailSender,
+		AuditlogStore: auditlogstore.New(auditlogstore.NewStoreParams{
+			KMS:                       environment.KMS.Client,
+			AuditLogChainKeysKMSKeyID: environment.KMS.AuditLogChainKeysKMSKeyID,
+		}),
 	})
 	projectID, _ := environment.NewProject(t)
 
@@ -53,6 +62,7 @@ func newTestUtil(t *testing.T) (context.Context, *testUtil) {
 		Store:       store,
 		Environment: environment,
 		ProjectID:   projectID,
+		EmailSender: emailSender,
 	}
 }
 
@@ -2,38 +2,31 @@ package store
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/svix/svix-webhooks/go/models"
+	"github.com/jackc/pgx/v5"
 	intermediatev1 "github.com/tesseral-labs/tesseral/internal/intermediate/gen/tesseral/intermediate/v1"
 	"github.com/tesseral-labs/tesseral/internal/intermediate/store/queries"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-func (s *Store) createSvixApplication(ctx context.Context, displayName string) (*models.ApplicationOut, error) {
-	// Create a new Svix application
-	app, err := s.svixClient.Application.Create(ctx, models.ApplicationIn{
-		Name: displayName,
-	}, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return app, nil
-}
-
 func (s *Store) createProjectWebhookSettings(ctx context.Context, q *queries.Queries, qProject queries.Project) (*intermediatev1.ProjectWebhookSettings, error) {
-	svixApplication, err := s.createSvixApplication(ctx, qProject.DisplayName)
+	appID, err := s.webhookDispatcher.CreateApplication(ctx, qProject.ID, qProject.DisplayName)
 	if err != nil {
-		return nil, fmt.Errorf("create svix application: %w", err)
+		return nil, fmt.Errorf("create webhook application: %w", err)
 	}
 
 	qWebhook, err := q.CreateProjectWebhookSettings(ctx, queries.CreateProjectWebhookSettingsParams{
 		ID:        uuid.New(),
 		ProjectID: qProject.ID,
-		AppID:     svixApplication.Id,
+		AppID:     appID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create webhook: %w", err)
@@ -42,6 +35,34 @@ func (s *Store) createProjectWebhookSettings(ctx context.Context, q *queries.Que
 	return parseProjectWebhookSettings(qWebhook), nil
 }
 
+// enqueueWebhookMessage writes a webhook message to the outbox. The webhook
+// relay sends it once q's transaction commits.
+func (s *Store) enqueueWebhookMessage(ctx context.Context, q *queries.Queries, projectID uuid.UUID, msg webhooks.Message) error {
+	if _, err := q.GetProjectWebhookSettings(ctx, projectID); err != nil {
+		// We want to ignore this error if the project does not have webhook settings
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("get project webhook settings: %w", err)
+	}
+
+	payload, err := json.Marshal(msg.Payload)
+	if err != nil {
+		return fmt.Errorf("marshal webhook payload: %w", err)
+	}
+
+	if _, err := q.CreateWebhookOutboxMessage(ctx, queries.CreateWebhookOutboxMessageParams{
+		ID:        uuidv7.NewWithTime(time.Now()),
+		ProjectID: projectID,
+		EventType: msg.EventType,
+		Payload:   payload,
+	}); err != nil {
+		return fmt.Errorf("create webhook outbox message: %w", err)
+	}
+
+	return nil
+}
+
 func parseProjectWebhookSettings(qWebhook queries.ProjectWebhookSetting) *intermediatev1.ProjectWebhookSettings {
 	return &intermediatev1.ProjectWebhookSettings{
 		Id:         idformat.ProjectWebhookSettings.Format(qWebhook.ID),
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	auditlogstore "github.com/tesseral-labs/tesseral/internal/auditlog/store"
 	"github.com/tesseral-labs/tesseral/internal/oidc/authn"
 	"github.com/tesseral-labs/tesseral/internal/oidcclient"
 	"github.com/tesseral-labs/tesseral/internal/store/idformat"
@@ -36,6 +37,10 @@ func newTestUtil(t *testing.T) (context.Context, *testUtil) {
 		KMS:                       environment.KMS.Client,
 		OIDCClientSecretsKMSKeyID: environment.KMS.OIDCClientSecretsKMSKeyID,
 		OIDCClient:                &oidcclient.Client{HTTPClient: http.DefaultClient},
+		AuditlogStore: auditlogstore.New(auditlogstore.NewStoreParams{
+			KMS:                       environment.KMS.Client,
+			AuditLogChainKeysKMSKeyID: environment.KMS.AuditLogChainKeysKMSKeyID,
+		}),
 	})
 
 	projectID, _ := environment.NewProject(t)
@@ -0,0 +1,295 @@
+// Package passwordhash verifies password hashes imported from other identity
+// providers.
+//
+// Hashes are strings in a PHC-like format:
+//
+//	$2a$..., $2b$..., $2y$...                        bcrypt
+//	$argon2id$v=19$m=<KiB>,t=<passes>,p=<lanes>$<salt>$<hash>
+//	$argon2i$v=19$m=<KiB>,t=<passes>,p=<lanes>$<salt>$<hash>
+//	$pbkdf2-<sha1|sha256|sha512>$i=<iterations>$<salt>$<hash>
+//	$firebase-scrypt$r=<rounds>,m=<memcost>$<salt>$<salt separator>$<signer key>$<hash>
+//
+// Binary values are base64-encoded, with or without padding.
+package passwordhash
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Limits on hash parameters, so that verifying an imported hash can't be made
+// arbitrarily expensive.
+const (
+	maxPBKDF2Iterations     = 2_000_000
+	maxArgon2Memory         = 256 * 1024
+	maxArgon2Time           = 16
+	maxArgon2Threads        = 16
+	maxFirebaseScryptRounds = 16
+	maxFirebaseScryptMemory = 16
+)
+
+// IsBcrypt returns whether hash is a bcrypt hash.
+func IsBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// Validate returns an error if hash is not in a supported format.
+func Validate(hash string) error {
+	_, err := parse(hash)
+	return err
+}
+
+// Verify returns whether password matches hash. It returns an error if hash is
+// not in a supported format.
+func Verify(hash, password string) (bool, error) {
+	v, err := parse(hash)
+	if err != nil {
+		return false, err
+	}
+
+	return v(password)
+}
+
+// FormatFirebaseScrypt returns a hash in the format this package expects for a
+// Firebase scrypt password hash, given a user's salt and hash and the hash
+// parameters from the Firebase project's password hash configuration.
+func FormatFirebaseScrypt(rounds, memCost int, salt, saltSeparator, signerKey, hash []byte) string {
+	return fmt.Sprintf("$firebase-scrypt$r=%d,m=%d$%s$%s$%s$%s",
+		rounds,
+		memCost,
+		base64.StdEncoding.EncodeToString(salt),
+		base64.StdEncoding.EncodeToString(saltSeparator),
+		base64.StdEncoding.EncodeToString(signerKey),
+		base64.StdEncoding.EncodeToString(hash),
+	)
+}
+
+type verifier func(password string) (bool, error)
+
+func parse(hash string) (verifier, error) {
+	if IsBcrypt(hash) {
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return nil, fmt.Errorf("invalid bcrypt hash: %w", err)
+		}
+
+		return func(password string) (bool, error) {
+			if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+				if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+					return false, nil
+				}
+				return false, fmt.Errorf("bcrypt: %w", err)
+			}
+			return true, nil
+		}, nil
+	}
+
+	parts := strings.Split(hash, "$")
+	if len(parts) < 2 || parts[0] != "" {
+		return nil, fmt.Errorf("invalid password hash format")
+	}
+
+	switch parts[1] {
+	case "argon2id", "argon2i":
+		return parseArgon2(parts)
+	case "pbkdf2-sha1", "pbkdf2-sha256", "pbkdf2-sha512":
+		return parsePBKDF2(parts)
+	case "firebase-scrypt":
+		return parseFirebaseScrypt(parts)
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm: %q", parts[1])
+	}
+}
+
+func parseArgon2(parts []string) (verifier, error) {
+	if len(parts) != 6 {
+		return nil, fmt.Errorf("invalid %s hash format", parts[1])
+	}
+
+	if parts[2] != fmt.Sprintf("v=%d", argon2.Version) {
+		return nil, fmt.Errorf("unsupported %s version: %q", parts[1], parts[2])
+	}
+
+	params, err := parseParams(parts[3], "m", "t", "p")
+	if err != nil {
+		return nil, fmt.Errorf("parse %s params: %w", parts[1], err)
+	}
+
+	memory, time, threads := params["m"], params["t"], params["p"]
+	if memory > maxArgon2Memory || time > maxArgon2Time || threads > maxArgon2Threads {
+		return nil, fmt.Errorf("%s params too large", parts[1])
+	}
+
+	salt, err := decodeBase64(parts[4])
+	if err != nil {
+		return nil, fmt.Errorf("decode %s salt: %w", parts[1], err)
+	}
+
+	want, err := decodeBase64(parts[5])
+	if err != nil {
+		return nil, fmt.Errorf("decode %s hash: %w", parts[1], err)
+	}
+
+	key := argon2.IDKey
+	if parts[1] == "argon2i" {
+		key = argon2.Key
+	}
+
+	return func(password string) (bool, error) {
+		got := key([]byte(password), salt, uint32(time), uint32(memory), uint8(threads), uint32(len(want)))
+		return subtle.ConstantTimeCompare(got, want) == 1, nil
+	}, nil
+}
+
+func parsePBKDF2(parts []string) (verifier, error) {
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("invalid %s hash format", parts[1])
+	}
+
+	var h func() hash.Hash
+	switch parts[1] {
+	case "pbkdf2-sha1":
+		h = sha1.New
+	case "pbkdf2-sha256":
+		h = sha256.New
+	case "pbkdf2-sha512":
+		h = sha512.New
+	}
+
+	params, err := parseParams(parts[2], "i")
+	if err != nil {
+		return nil, fmt.Errorf("parse %s params: %w", parts[1], err)
+	}
+
+	iterations := params["i"]
+	if iterations > maxPBKDF2Iterations {
+		return nil, fmt.Errorf("%s params too large", parts[1])
+	}
+
+	salt, err := decodeBase64(parts[3])
+	if err != nil {
+		return nil, fmt.Errorf("decode %s salt: %w", parts[1], err)
+	}
+
+	want, err := decodeBase64(parts[4])
+	if err != nil {
+		return nil, fmt.Errorf("decode %s hash: %w", parts[1], err)
+	}
+
+	return func(password string) (bool, error) {
+		got := pbkdf2.Key([]byte(password), salt, iterations, len(want), h)
+		return subtle.ConstantTimeCompare(got, want) == 1, nil
+	}, nil
+}
+
+// parseFirebaseScrypt parses a hash from Firebase's modified scrypt. Firebase
+// derives a key from the password using scrypt, and uses that key to encrypt a
+// per-project signer key with AES-256-CTR; the result is the password hash.
+func parseFirebaseScrypt(parts []string) (verifier, error) {
+	if len(parts) != 7 {
+		return nil, fmt.Errorf("invalid firebase-scrypt hash format")
+	}
+
+	params, err := parseParams(parts[2], "r", "m")
+	if err != nil {
+		return nil, fmt.Errorf("parse firebase-scrypt params: %w", err)
+	}
+
+	rounds, memCost := params["r"], params["m"]
+	if rounds > maxFirebaseScryptRounds || memCost > maxFirebaseScryptMemory {
+		return nil, fmt.Errorf("firebase-scrypt params too large")
+	}
+
+	salt, err := decodeBase64(parts[3])
+	if err != nil {
+		return nil, fmt.Errorf("decode firebase-scrypt salt: %w", err)
+	}
+
+	saltSeparator, err := decodeBase64(parts[4])
+	if err != nil {
+		return nil, fmt.Errorf("decode firebase-scrypt salt separator: %w", err)
+	}
+
+	signerKey, err := decodeBase64(parts[5])
+	if err != nil {
+		return nil, fmt.Errorf("decode firebase-scrypt signer key: %w", err)
+	}
+
+	want, err := decodeBase64(parts[6])
+	if err != nil {
+		return nil, fmt.Errorf("decode firebase-scrypt hash: %w", err)
+	}
+
+	return func(password string) (bool, error) {
+		key, err := scrypt.Key([]byte(password), append(salt[:len(salt):len(salt)], saltSeparator...), 1<<memCost, rounds, 1, 32)
+		if err != nil {
+			return false, fmt.Errorf("scrypt: %w", err)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return false, fmt.Errorf("create aes cipher: %w", err)
+		}
+
+		got := make([]byte, len(signerKey))
+		cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(got, signerKey)
+		return subtle.ConstantTimeCompare(got, want) == 1, nil
+	}, nil
+}
+
+// parseParams parses a comma-separated list of positive integer parameters,
+// like "m=65536,t=3,p=4". Each of names must appear exactly once, and no other
+// parameters are allowed.
+func parseParams(s string, names ...string) (map[string]int, error) {
+	params := map[string]int{}
+	for _, param := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid param: %q", param)
+		}
+
+		if _, ok := params[name]; ok {
+			return nil, fmt.Errorf("duplicate param: %q", name)
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid value for param %q: %q", name, value)
+		}
+
+		params[name] = n
+	}
+
+	if len(params) != len(names) {
+		return nil, fmt.Errorf("expected params %v, got %q", names, s)
+	}
+
+	for _, name := range names {
+		if _, ok := params[name]; !ok {
+			return nil, fmt.Errorf("missing param: %q", name)
+		}
+	}
+
+	return params, nil
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	if strings.HasSuffix(s, "=") {
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
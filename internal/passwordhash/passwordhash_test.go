@@ -0,0 +1,91 @@
+package passwordhash_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tesseral-labs/tesseral/internal/passwordhash"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+func TestVerify(t *testing.T) {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	salt := []byte("0123456789abcdef")
+	argon2idHash := fmt.Sprintf("$argon2id$v=19$m=1024,t=2,p=1$%s$%s",
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(argon2.IDKey([]byte("password"), salt, 2, 1024, 1, 32)))
+	argon2iHash := fmt.Sprintf("$argon2i$v=19$m=1024,t=2,p=1$%s$%s",
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(argon2.Key([]byte("password"), salt, 2, 1024, 1, 32)))
+	pbkdf2Hash := fmt.Sprintf("$pbkdf2-sha256$i=1000$%s$%s",
+		base64.StdEncoding.EncodeToString(salt),
+		base64.StdEncoding.EncodeToString(pbkdf2.Key([]byte("password"), salt, 1000, 32, sha256.New)))
+
+	// test vector from https://github.com/firebase/scrypt
+	firebaseHash := passwordhash.FormatFirebaseScrypt(
+		8,
+		14,
+		mustDecodeBase64(t, "42xEC+ixf3L2lw=="),
+		mustDecodeBase64(t, "Bw=="),
+		mustDecodeBase64(t, "jxspr8Ki0RYycVU8zykbdLGjFQ3McFUH0uiiTvC8pVMXAn210wjLNmdZJzxUECKbm0QsEmYUSDzZvpjeJ9WmXA=="),
+		mustDecodeBase64(t, "lSrfV15cpx95/sZS2W9c9Kp6i/LVgQNDNC/qzrCnh1SAyZvqmZqAjTdn3aoItz+VHjoZilo78198JAdRuid5lQ=="),
+	)
+
+	testCases := []struct {
+		name     string
+		hash     string
+		password string
+	}{
+		{name: "bcrypt", hash: string(bcryptHash), password: "password"},
+		{name: "argon2id", hash: argon2idHash, password: "password"},
+		{name: "argon2i", hash: argon2iHash, password: "password"},
+		{name: "pbkdf2", hash: pbkdf2Hash, password: "password"},
+		{name: "firebase-scrypt", hash: firebaseHash, password: "user1password"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, passwordhash.Validate(tt.hash))
+
+			ok, err := passwordhash.Verify(tt.hash, tt.password)
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = passwordhash.Verify(tt.hash, "wrong"+tt.password)
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestValidate_Invalid(t *testing.T) {
+	for _, hash := range []string{
+		"",
+		"password",
+		"$md5$abc",
+		"$2b$10$tooshort",
+		"$argon2id$v=16$m=1024,t=2,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=2$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1048576,t=2,p=1$c2FsdA$aGFzaA",
+		"$pbkdf2-md5$i=1000$c2FsdA$aGFzaA",
+		"$pbkdf2-sha256$i=0$c2FsdA$aGFzaA",
+		"$pbkdf2-sha256$i=1000$!!!$aGFzaA",
+		"$firebase-scrypt$r=8,m=14$c2FsdA$Bw$a2V5",
+	} {
+		assert.Error(t, passwordhash.Validate(hash), hash)
+	}
+}
+
+func mustDecodeBase64(t *testing.T, s string) []byte {
+	b, err := base64.StdEncoding.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
@@ -0,0 +1,718 @@
+// Package projectexport exports a project's configuration and users from a
+// Tesseral database, and imports them into another.
+//
+// Exports are JSON documents containing the rows of each table that describes
+// a project, as produced by postgres's to_jsonb. Exports can only be imported
+// into a database with the same schema version they were exported from.
+//
+// Secrets that are encrypted with KMS, such as OAuth client secrets and
+// session signing keys, are decrypted on export and re-encrypted with the
+// target's KMS keys on import. Exports therefore contain plaintext secrets,
+// and must be handled accordingly.
+package projectexport
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+// Version is the version of the export format.
+const Version = 1
+
+type Export struct {
+	Version             int       `json:"version"`
+	SchemaVersion       int64     `json:"schema_version"`
+	ProjectID           string    `json:"project_id"`
+	ExportTime          time.Time `json:"export_time"`
+	IncludesCredentials bool      `json:"includes_credentials"`
+	Tables              []Table   `json:"tables"`
+}
+
+type Table struct {
+	Name string `json:"name"`
+	Rows []Row  `json:"rows"`
+}
+
+// Row is a row of a table, keyed by column name.
+type Row map[string]json.RawMessage
+
+// KMSKeyIDs are the KMS keys a database's secrets are encrypted with.
+type KMSKeyIDs struct {
+	Session                     string
+	GoogleOAuthClientSecrets    string
+	MicrosoftOAuthClientSecrets string
+	GithubOAuthClientSecrets    string
+	OIDCClientSecrets           string
+	AuthenticatorAppSecrets     string
+}
+
+type table struct {
+	name string
+
+	// key are the columns that uniquely identify a row; "id" if empty
+	key []string
+
+	// where is a condition selecting the table's rows for a project, with the
+	// project ID as $1
+	where string
+
+	// secrets maps columns encrypted with KMS to the key they are encrypted
+	// with
+	secrets map[string]func(KMSKeyIDs) string
+
+	// credentials is whether the whole table is only exported with
+	// credentials
+	credentials bool
+
+	// credentialColumns are set to null unless credentials are exported, and
+	// are left unchanged by imports without credentials
+	credentialColumns []string
+
+	// deferredColumns reference other rows of the same table, so are only
+	// set once every row of the table is imported
+	deferredColumns []string
+
+	// excludedColumns are not exported
+	excludedColumns []string
+}
+
+// tables are the tables that make up a project, ordered so that a row only
+// references rows in preceding tables.
+var tables = []table{
+	{
+		name:  "projects",
+		where: "id = $1",
+		secrets: map[string]func(KMSKeyIDs) string{
+			"google_oauth_client_secret_ciphertext":    func(k KMSKeyIDs) string { return k.GoogleOAuthClientSecrets },
+			"microsoft_oauth_client_secret_ciphertext": func(k KMSKeyIDs) string { return k.MicrosoftOAuthClientSecrets },
+			"github_oauth_client_secret_ciphertext":    func(k KMSKeyIDs) string { return k.GithubOAuthClientSecrets },
+		},
+		// the organization that owns the project lives in the deployment's
+		// dogfood project, so does not carry over between deployments
+		excludedColumns: []string{"organization_id"},
+	},
+	{name: "vault_domain_settings", key: []string{"project_id"}, where: "project_id = $1"},
+	{name: "project_ui_settings", where: "project_id = $1"},
+	{name: "project_trusted_domains", where: "project_id = $1"},
+	{name: "email_templates", where: "project_id = $1"},
+	{
+		name:  "session_signing_keys",
+		where: "project_id = $1",
+		secrets: map[string]func(KMSKeyIDs) string{
+			"private_key_cipher_text": func(k KMSKeyIDs) string { return k.Session },
+		},
+	},
+	{name: "actions", where: "project_id = $1"},
+	{name: "role_templates", where: "project_id = $1"},
+	{name: "role_template_actions", where: "role_template_id IN (SELECT id FROM role_templates WHERE project_id = $1)"},
+	{
+		name:            "organizations",
+		where:           "project_id = $1",
+		deferredColumns: []string{"parent_organization_id"},
+	},
+	{name: "organization_domains", where: "organization_id IN (SELECT id FROM organizations WHERE project_id = $1)"},
+	{name: "organization_google_hosted_domains", where: "organization_id IN (SELECT id FROM organizations WHERE project_id = $1)"},
+	{name: "organization_microsoft_tenant_ids", where: "organization_id IN (SELECT id FROM organizations WHERE project_id = $1)"},
+	{name: "saml_connections", where: "organization_id IN (SELECT id FROM organizations WHERE project_id = $1)"},
+	{
+		name:  "oidc_connections",
+		where: "organization_id IN (SELECT id FROM organizations WHERE project_id = $1)",
+		secrets: map[string]func(KMSKeyIDs) string{
+			"client_secret_ciphertext": func(k KMSKeyIDs) string { return k.OIDCClientSecrets },
+		},
+	},
+	{name: "roles", where: "project_id = $1"},
+	{name: "role_actions", where: "role_id IN (SELECT id FROM roles WHERE project_id = $1)"},
+	{name: "role_inherited_roles", key: []string{"role_id", "inherited_role_id"}, where: "role_id IN (SELECT id FROM roles WHERE project_id = $1)"},
+	{
+		name:  "identities",
+		where: "project_id = $1",
+		secrets: map[string]func(KMSKeyIDs) string{
+			"authenticator_app_secret_ciphertext": func(k KMSKeyIDs) string { return k.AuthenticatorAppSecrets },
+		},
+		credentialColumns: []string{
+			"password_bcrypt",
+			"imported_password_hash",
+			"password_lockout_expire_time",
+			"authenticator_app_secret_ciphertext",
+			"authenticator_app_lockout_expire_time",
+			"authenticator_app_recovery_code_sha256s",
+		},
+	},
+	{name: "users", where: "organization_id IN (SELECT id FROM organizations WHERE project_id = $1)"},
+	{name: "user_role_assignments", where: "user_id IN (SELECT users.id FROM users JOIN organizations ON users.organization_id = organizations.id WHERE organizations.project_id = $1)"},
+	{name: "passkeys", where: "identity_id IN (SELECT id FROM identities WHERE project_id = $1)", credentials: true},
+}
+
+// excludedTables are the tables that are deliberately not exported. Every
+// table in the schema is in either tables or excludedTables.
+var excludedTables = []string{
+	"schema_migrations",
+
+	// sessions and other short-lived login state
+	"sessions",
+	"intermediate_sessions",
+	"relayed_sessions",
+	"oauth_verified_emails",
+	"user_authenticator_app_challenges",
+	"user_impersonation_tokens",
+	"user_invites",
+	"access_requests",
+
+	// keys are issued by, and only meaningful to, the deployment that created
+	// them
+	"project_api_keys",
+	"publishable_keys",
+	"api_keys",
+	"api_key_role_assignments",
+	"scim_api_keys",
+
+	// usage, audit logs, and webhook deliveries are history, not configuration
+	"api_key_request_quota_usage",
+	"project_email_quota_daily_usage",
+	"audit_log_events",
+	"audit_log_chain_heads",
+	"audit_log_archives",
+	"audit_log_archived_chain_links",
+	"audit_log_export_destinations",
+	"project_webhook_settings",
+	"webhook_endpoints",
+	"webhook_messages",
+	"webhook_deliveries",
+	"webhook_outbox_messages",
+}
+
+func (t table) keyColumns() []string {
+	if len(t.key) == 0 {
+		return []string{"id"}
+	}
+	return t.key
+}
+
+// rowKey returns a row's key, formatted for display. It returns false if the
+// row is missing a key column.
+func (t table) rowKey(row Row) (string, bool) {
+	var parts []string
+	for _, column := range t.keyColumns() {
+		value, ok := row[column]
+		if !ok {
+			return "", false
+		}
+		parts = append(parts, formatKey(value))
+	}
+	return strings.Join(parts, "/"), true
+}
+
+// keyCondition returns a condition matching rows of t to rows of r with the
+// same key.
+func (t table) keyCondition() string {
+	var conditions []string
+	for _, column := range t.keyColumns() {
+		conditions = append(conditions, fmt.Sprintf("t.%s = r.%s", pgx.Identifier{column}.Sanitize(), pgx.Identifier{column}.Sanitize()))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+type ExportProjectParams struct {
+	DB                 *pgxpool.Pool
+	KMS                *kms.Client
+	KMSKeyIDs          KMSKeyIDs
+	ProjectID          uuid.UUID
+	IncludeCredentials bool
+}
+
+// ExportProject exports a project. Credentials, such as password hashes and
+// passkeys, are only exported if IncludeCredentials is set.
+func ExportProject(ctx context.Context, p ExportProjectParams) (*Export, error) {
+	tx, err := p.DB.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	export, err := exportProject(ctx, tx, p.KMS, p.KMSKeyIDs, p.ProjectID, p.IncludeCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(export.Tables[0].Rows) == 0 {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	return export, nil
+}
+
+func exportProject(ctx context.Context, tx pgx.Tx, kms_ *kms.Client, kmsKeyIDs KMSKeyIDs, projectID uuid.UUID, includeCredentials bool) (*Export, error) {
+	// to_jsonb formats timestamps in the session's time zone
+	if _, err := tx.Exec(ctx, "SET LOCAL TIME ZONE 'UTC'"); err != nil {
+		return nil, fmt.Errorf("set time zone: %w", err)
+	}
+
+	schemaVersion, err := getSchemaVersion(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &Export{
+		Version:             Version,
+		SchemaVersion:       schemaVersion,
+		ProjectID:           idformat.Project.Format(projectID),
+		ExportTime:          time.Now().UTC(),
+		IncludesCredentials: includeCredentials,
+	}
+
+	for _, t := range tables {
+		if t.credentials && !includeCredentials {
+			continue
+		}
+
+		rows, err := exportTable(ctx, tx, t, projectID)
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", t.name, err)
+		}
+
+		for _, row := range rows {
+			for _, column := range t.excludedColumns {
+				delete(row, column)
+			}
+
+			if !includeCredentials {
+				for _, column := range t.credentialColumns {
+					row[column] = json.RawMessage("null")
+				}
+			}
+
+			for column, keyID := range t.secrets {
+				if err := decryptColumn(ctx, kms_, keyID(kmsKeyIDs), row, column); err != nil {
+					return nil, fmt.Errorf("decrypt %s.%s: %w", t.name, column, err)
+				}
+			}
+		}
+
+		export.Tables = append(export.Tables, Table{
+			Name: t.name,
+			Rows: rows,
+		})
+	}
+
+	return export, nil
+}
+
+func exportTable(ctx context.Context, tx pgx.Tx, t table, projectID uuid.UUID) ([]Row, error) {
+	name := pgx.Identifier{t.name}.Sanitize()
+	var keys []string
+	for _, column := range t.keyColumns() {
+		keys = append(keys, pgx.Identifier{column}.Sanitize())
+	}
+	sqlRows, err := tx.Query(ctx, fmt.Sprintf("SELECT to_jsonb(t) FROM %s AS t WHERE %s ORDER BY %s", name, t.where, strings.Join(keys, ", ")), projectID)
+	if err != nil {
+		return nil, fmt.Errorf("query rows: %w", err)
+	}
+
+	data, err := pgx.CollectRows(sqlRows, pgx.RowTo[[]byte])
+	if err != nil {
+		return nil, fmt.Errorf("collect rows: %w", err)
+	}
+
+	rows := []Row{}
+	for _, d := range data {
+		var row Row
+		if err := json.Unmarshal(d, &row); err != nil {
+			return nil, fmt.Errorf("unmarshal row: %w", err)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func getSchemaVersion(ctx context.Context, tx pgx.Tx) (int64, error) {
+	var version int64
+	var dirty bool
+	if err := tx.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations").Scan(&version, &dirty); err != nil {
+		return 0, fmt.Errorf("get schema version: %w", err)
+	}
+
+	if dirty {
+		return 0, fmt.Errorf("schema version %d is dirty", version)
+	}
+
+	return version, nil
+}
+
+type ImportProjectParams struct {
+	DB        *pgxpool.Pool
+	KMS       *kms.Client
+	KMSKeyIDs KMSKeyIDs
+	Export    *Export
+
+	// OwnerOrganizationID is the organization, in the target's dogfood
+	// project, that owns the project. It is only used if the project does not
+	// already exist in the target.
+	OwnerOrganizationID *uuid.UUID
+
+	// DryRun returns the changes an import would make, without making them.
+	DryRun bool
+}
+
+type ChangeType string
+
+const (
+	ChangeTypeCreate ChangeType = "create"
+	ChangeTypeUpdate ChangeType = "update"
+	ChangeTypeDelete ChangeType = "delete"
+)
+
+// Change is a change an import makes to a row.
+type Change struct {
+	Type  ChangeType
+	Table string
+	Key   string
+
+	// Columns are the columns an update changes.
+	Columns []string
+
+	t   table
+	row Row
+}
+
+// ImportProject makes the target's copy of a project match an export,
+// creating, updating, and deleting rows as necessary. It returns the changes
+// it made, or would make if DryRun is set.
+//
+// If the export does not include credentials, imports leave existing
+// credentials unchanged.
+func ImportProject(ctx context.Context, p ImportProjectParams) ([]Change, error) {
+	if p.Export.Version != Version {
+		return nil, fmt.Errorf("unsupported export version: %d", p.Export.Version)
+	}
+
+	projectID, err := idformat.Project.Parse(p.Export.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("parse project id: %w", err)
+	}
+
+	tx, err := p.DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	current, err := exportProject(ctx, tx, p.KMS, p.KMSKeyIDs, projectID, p.Export.IncludesCredentials)
+	if err != nil {
+		return nil, fmt.Errorf("export current project: %w", err)
+	}
+
+	if current.SchemaVersion != p.Export.SchemaVersion {
+		return nil, fmt.Errorf("export has schema version %d, but database has schema version %d", p.Export.SchemaVersion, current.SchemaVersion)
+	}
+
+	changes, err := diff(p.Export, current)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.DryRun {
+		return changes, nil
+	}
+
+	if err := apply(ctx, tx, p.KMS, p.KMSKeyIDs, p.OwnerOrganizationID, changes); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return changes, nil
+}
+
+// diff returns the changes that make current match export.
+func diff(export, current *Export) ([]Change, error) {
+	exportTables := map[string][]Row{}
+	for _, t := range export.Tables {
+		exportTables[t.Name] = t.Rows
+	}
+
+	currentTables := map[string][]Row{}
+	for _, t := range current.Tables {
+		currentTables[t.Name] = t.Rows
+	}
+
+	var changes []Change
+	for _, t := range tables {
+		if t.credentials && !export.IncludesCredentials {
+			continue
+		}
+
+		currentRows := map[string]Row{}
+		for _, row := range currentTables[t.name] {
+			key, _ := t.rowKey(row)
+			currentRows[key] = row
+		}
+
+		exportKeys := map[string]struct{}{}
+		for _, row := range exportTables[t.name] {
+			key, ok := t.rowKey(row)
+			if !ok {
+				return nil, fmt.Errorf("%s row missing %s", t.name, strings.Join(t.keyColumns(), ", "))
+			}
+
+			exportKeys[key] = struct{}{}
+
+			currentRow, ok := currentRows[key]
+			if !ok {
+				changes = append(changes, Change{Type: ChangeTypeCreate, Table: t.name, Key: key, t: t, row: row})
+				continue
+			}
+
+			var columns []string
+			for column, value := range row {
+				if slices.Contains(t.excludedColumns, column) {
+					continue
+				}
+				if !export.IncludesCredentials && slices.Contains(t.credentialColumns, column) {
+					continue
+				}
+				if !jsonEqual(value, currentRow[column]) {
+					columns = append(columns, column)
+				}
+			}
+
+			if len(columns) > 0 {
+				slices.Sort(columns)
+				changes = append(changes, Change{Type: ChangeTypeUpdate, Table: t.name, Key: key, Columns: columns, t: t, row: row})
+			}
+		}
+
+		for _, row := range currentTables[t.name] {
+			key, _ := t.rowKey(row)
+			if _, ok := exportKeys[key]; !ok {
+				changes = append(changes, Change{Type: ChangeTypeDelete, Table: t.name, Key: key, t: t, row: row})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// apply makes changes to the database. Rows are created and updated in table
+// order, then deferred columns are set, and finally rows are deleted in reverse
+// table order.
+func apply(ctx context.Context, tx pgx.Tx, kms_ *kms.Client, kmsKeyIDs KMSKeyIDs, ownerOrganizationID *uuid.UUID, changes []Change) error {
+	for _, change := range changes {
+		if change.Type == ChangeTypeDelete {
+			continue
+		}
+
+		row := Row{}
+		for column, value := range change.row {
+			row[column] = value
+		}
+
+		for column, keyID := range change.t.secrets {
+			if err := encryptColumn(ctx, kms_, keyID(kmsKeyIDs), row, column); err != nil {
+				return fmt.Errorf("encrypt %s.%s: %w", change.t.name, column, err)
+			}
+		}
+
+		switch change.Type {
+		case ChangeTypeCreate:
+			for _, column := range change.t.deferredColumns {
+				row[column] = json.RawMessage("null")
+			}
+
+			if change.t.name == "projects" && ownerOrganizationID != nil {
+				row["organization_id"] = json.RawMessage(fmt.Sprintf("%q", ownerOrganizationID.String()))
+			}
+
+			if err := insertRow(ctx, tx, change.t, row); err != nil {
+				return fmt.Errorf("create %s %s: %w", change.Table, change.Key, err)
+			}
+		case ChangeTypeUpdate:
+			var columns []string
+			for _, column := range change.Columns {
+				if !slices.Contains(change.t.deferredColumns, column) {
+					columns = append(columns, column)
+				}
+			}
+
+			if err := updateRow(ctx, tx, change.t, row, columns); err != nil {
+				return fmt.Errorf("update %s %s: %w", change.Table, change.Key, err)
+			}
+		}
+	}
+
+	for _, change := range changes {
+		if change.Type == ChangeTypeDelete || len(change.t.deferredColumns) == 0 {
+			continue
+		}
+
+		if err := updateRow(ctx, tx, change.t, change.row, change.t.deferredColumns); err != nil {
+			return fmt.Errorf("update %s %s: %w", change.Table, change.Key, err)
+		}
+	}
+
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+		if change.Type != ChangeTypeDelete {
+			continue
+		}
+
+		query := fmt.Sprintf("DELETE FROM %s AS t USING jsonb_populate_record(NULL::%s, $1) AS r WHERE %s",
+			pgx.Identifier{change.t.name}.Sanitize(),
+			pgx.Identifier{change.t.name}.Sanitize(),
+			change.t.keyCondition(),
+		)
+		if _, err := tx.Exec(ctx, query, change.row); err != nil {
+			return fmt.Errorf("delete %s %s: %w", change.Table, change.Key, err)
+		}
+	}
+
+	return nil
+}
+
+func insertRow(ctx context.Context, tx pgx.Tx, t table, row Row) error {
+	name := pgx.Identifier{t.name}.Sanitize()
+	query := fmt.Sprintf("INSERT INTO %s SELECT * FROM jsonb_populate_record(NULL::%s, $1)", name, name)
+	if _, err := tx.Exec(ctx, query, row); err != nil {
+		return err
+	}
+	return nil
+}
+
+func updateRow(ctx context.Context, tx pgx.Tx, t table, row Row, columns []string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+
+	var sets []string
+	for _, column := range columns {
+		sets = append(sets, fmt.Sprintf("%s = r.%s", pgx.Identifier{column}.Sanitize(), pgx.Identifier{column}.Sanitize()))
+	}
+
+	name := pgx.Identifier{t.name}.Sanitize()
+	query := fmt.Sprintf("UPDATE %s AS t SET %s FROM jsonb_populate_record(NULL::%s, $1) AS r WHERE %s", name, strings.Join(sets, ", "), name, t.keyCondition())
+	if _, err := tx.Exec(ctx, query, row); err != nil {
+		return err
+	}
+	return nil
+}
+
+func decryptColumn(ctx context.Context, kms_ *kms.Client, keyID string, row Row, column string) error {
+	ciphertext, err := parseBytea(row[column])
+	if err != nil {
+		return err
+	}
+	if ciphertext == nil {
+		return nil
+	}
+
+	decryptRes, err := kms_.Decrypt(ctx, &kms.DecryptInput{
+		CiphertextBlob:      ciphertext,
+		EncryptionAlgorithm: types.EncryptionAlgorithmSpecRsaesOaepSha256,
+		KeyId:               &keyID,
+	})
+	if err != nil {
+		return fmt.Errorf("decrypt: %w", err)
+	}
+
+	row[column] = formatBytea(decryptRes.Plaintext)
+	return nil
+}
+
+func encryptColumn(ctx context.Context, kms_ *kms.Client, keyID string, row Row, column string) error {
+	plaintext, err := parseBytea(row[column])
+	if err != nil {
+		return err
+	}
+	if plaintext == nil {
+		return nil
+	}
+
+	encryptRes, err := kms_.Encrypt(ctx, &kms.EncryptInput{
+		EncryptionAlgorithm: types.EncryptionAlgorithmSpecRsaesOaepSha256,
+		KeyId:               &keyID,
+		Plaintext:           plaintext,
+	})
+	if err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+
+	row[column] = formatBytea(encryptRes.CiphertextBlob)
+	return nil
+}
+
+// parseBytea parses a bytea as formatted by to_jsonb, like "\\x0102". It
+// returns nil for null.
+func parseBytea(value json.RawMessage) ([]byte, error) {
+	var s *string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return nil, fmt.Errorf("unmarshal bytea: %w", err)
+	}
+	if s == nil {
+		return nil, nil
+	}
+
+	hexValue, ok := strings.CutPrefix(*s, `\x`)
+	if !ok {
+		return nil, fmt.Errorf("invalid bytea: %q", *s)
+	}
+
+	b, err := hex.DecodeString(hexValue)
+	if err != nil {
+		return nil, fmt.Errorf("decode bytea: %w", err)
+	}
+
+	return b, nil
+}
+
+func formatBytea(b []byte) json.RawMessage {
+	value, err := json.Marshal(`\x` + hex.EncodeToString(b))
+	if err != nil {
+		panic(fmt.Errorf("marshal bytea: %w", err))
+	}
+	return value
+}
+
+func formatKey(key json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(key, &s); err != nil {
+		return string(key)
+	}
+	return s
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	if a == nil {
+		a = json.RawMessage("null")
+	}
+	if b == nil {
+		b = json.RawMessage("null")
+	}
+
+	var x, y any
+	if err := json.Unmarshal(a, &x); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		return false
+	}
+
+	xJSON, _ := json.Marshal(x)
+	yJSON, _ := json.Marshal(y)
+	return string(xJSON) == string(yJSON)
+}
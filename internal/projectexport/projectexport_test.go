@@ -0,0 +1,187 @@
+package projectexport
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	backendv1 "github.com/tesseral-labs/tesseral/internal/backend/gen/tesseral/backend/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/storetesting"
+)
+
+func TestDiff(t *testing.T) {
+	current := &Export{
+		Tables: []Table{
+			{
+				Name: "organizations",
+				Rows: []Row{
+					mustRow(t, `{"id": "1", "display_name": "unchanged", "parent_organization_id": null}`),
+					mustRow(t, `{"id": "2", "display_name": "before", "parent_organization_id": null}`),
+					mustRow(t, `{"id": "3", "display_name": "deleted", "parent_organization_id": null}`),
+				},
+			},
+			{
+				Name: "identities",
+				Rows: []Row{
+					mustRow(t, `{"id": "4", "email": "a@example.com", "password_bcrypt": "hash"}`),
+				},
+			},
+			{
+				Name: "passkeys",
+				Rows: []Row{
+					mustRow(t, `{"id": "5"}`),
+				},
+			},
+		},
+	}
+
+	export := &Export{
+		IncludesCredentials: false,
+		Tables: []Table{
+			{
+				Name: "organizations",
+				Rows: []Row{
+					mustRow(t, `{"id":"1","display_name":"unchanged","parent_organization_id":null}`),
+					mustRow(t, `{"id":"2","display_name":"after","parent_organization_id":"1"}`),
+					mustRow(t, `{"id":"6","display_name":"created","parent_organization_id":null}`),
+				},
+			},
+			{
+				Name: "identities",
+				Rows: []Row{
+					// credentials are not exported, so are not changed
+					mustRow(t, `{"id":"4","email":"a@example.com","password_bcrypt":null}`),
+				},
+			},
+		},
+	}
+
+	changes, err := diff(export, current)
+	require.NoError(t, err)
+
+	type change struct {
+		Type    ChangeType
+		Table   string
+		Key     string
+		Columns []string
+	}
+
+	var got []change
+	for _, c := range changes {
+		got = append(got, change{Type: c.Type, Table: c.Table, Key: c.Key, Columns: c.Columns})
+	}
+
+	require.Equal(t, []change{
+		{Type: ChangeTypeUpdate, Table: "organizations", Key: "2", Columns: []string{"display_name", "parent_organization_id"}},
+		{Type: ChangeTypeCreate, Table: "organizations", Key: "6"},
+		{Type: ChangeTypeDelete, Table: "organizations", Key: "3"},
+	}, got)
+}
+
+func TestExportImportProject(t *testing.T) {
+	env, cleanup := storetesting.NewEnvironment()
+	defer cleanup()
+
+	formattedProjectID, _ := env.NewProject(t)
+	env.NewOrganization(t, formattedProjectID, &backendv1.Organization{DisplayName: "test"})
+
+	projectID, err := idformat.Project.Parse(formattedProjectID)
+	require.NoError(t, err)
+
+	// every table in the schema is either exported or explicitly excluded
+	sqlRows, err := env.DB.Query(t.Context(), "SELECT table_name FROM information_schema.tables WHERE table_schema = 'public' AND table_type = 'BASE TABLE'")
+	require.NoError(t, err)
+	schemaTables, err := pgx.CollectRows(sqlRows, pgx.RowTo[string])
+	require.NoError(t, err)
+
+	for _, name := range schemaTables {
+		exported := slices.ContainsFunc(tables, func(t table) bool { return t.name == name })
+		require.True(t, exported || slices.Contains(excludedTables, name), "table %s is neither exported nor excluded", name)
+	}
+
+	readerRoleID, editorRoleID := uuid.New(), uuid.New()
+	_, err = env.DB.Exec(t.Context(), `
+INSERT INTO roles (id, project_id, display_name, description)
+  VALUES ($1, $3, 'reader', ''), ($2, $3, 'editor', '');
+`, readerRoleID, editorRoleID, uuid.UUID(projectID))
+	require.NoError(t, err)
+	_, err = env.DB.Exec(t.Context(), "INSERT INTO role_inherited_roles (role_id, inherited_role_id) VALUES ($1, $2)", editorRoleID, readerRoleID)
+	require.NoError(t, err)
+
+	kmsKeyIDs := KMSKeyIDs{
+		Session:                     env.KMS.SessionSigningKeyID,
+		GoogleOAuthClientSecrets:    env.KMS.GoogleOAuthClientSecretsKMSKeyID,
+		MicrosoftOAuthClientSecrets: env.KMS.MicrosoftOAuthClientSecretsKMSKeyID,
+		GithubOAuthClientSecrets:    env.KMS.GithubOAuthClientSecretsKMSKeyID,
+		OIDCClientSecrets:           env.KMS.OIDCClientSecretsKMSKeyID,
+		AuthenticatorAppSecrets:     env.KMS.AuthenticatorAppSecretsKMSKeyID,
+	}
+
+	export, err := ExportProject(t.Context(), ExportProjectParams{
+		DB:                 env.DB,
+		KMS:                env.KMS.Client,
+		KMSKeyIDs:          kmsKeyIDs,
+		ProjectID:          projectID,
+		IncludeCredentials: true,
+	})
+	require.NoError(t, err)
+
+	var exportedTables []string
+	for _, table := range export.Tables {
+		exportedTables = append(exportedTables, table.Name)
+	}
+	require.Contains(t, exportedTables, "role_inherited_roles")
+
+	_, err = env.DB.Exec(t.Context(), "DELETE FROM role_inherited_roles WHERE role_id = $1", editorRoleID)
+	require.NoError(t, err)
+
+	changes, err := ImportProject(t.Context(), ImportProjectParams{
+		DB:        env.DB,
+		KMS:       env.KMS.Client,
+		KMSKeyIDs: kmsKeyIDs,
+		Export:    export,
+	})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, ChangeTypeCreate, changes[0].Type)
+	require.Equal(t, "role_inherited_roles", changes[0].Table)
+
+	var inheritedRoleID uuid.UUID
+	err = env.DB.QueryRow(t.Context(), "SELECT inherited_role_id FROM role_inherited_roles WHERE role_id = $1", editorRoleID).Scan(&inheritedRoleID)
+	require.NoError(t, err)
+	require.Equal(t, readerRoleID, inheritedRoleID)
+
+	// importing again is a no-op
+	changes, err = ImportProject(t.Context(), ImportProjectParams{
+		DB:        env.DB,
+		KMS:       env.KMS.Client,
+		KMSKeyIDs: kmsKeyIDs,
+		Export:    export,
+		DryRun:    true,
+	})
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestParseBytea(t *testing.T) {
+	b, err := parseBytea(formatBytea([]byte("secret")))
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret"), b)
+
+	b, err = parseBytea(json.RawMessage("null"))
+	require.NoError(t, err)
+	require.Nil(t, b)
+
+	_, err = parseBytea(json.RawMessage(`"secret"`))
+	require.Error(t, err)
+}
+
+func mustRow(t *testing.T, s string) Row {
+	var row Row
+	require.NoError(t, json.Unmarshal([]byte(s), &row))
+	return row
+}
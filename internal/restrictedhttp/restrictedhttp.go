@@ -36,6 +36,17 @@ func NewTransport() *http.Transport {
 	}
 }
 
+// DialContext connects to addr like net.Dialer.DialContext, but refuses to
+// connect to private networks. It is for non-HTTP connections to addresses
+// provided by customers, e.g. syslog servers.
+func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &restrictedDialer{
+		dial:     (&net.Dialer{}).DialContext,
+		denyList: privateIPNetworks,
+	}
+	return dialer.DialContext(ctx, network, addr)
+}
+
 type restrictedDialer struct {
 	dial     func(ctx context.Context, network, addr string) (net.Conn, error)
 	denyList denyList
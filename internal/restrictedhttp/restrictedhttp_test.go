@@ -1,6 +1,8 @@
 package restrictedhttp
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -75,3 +77,17 @@ func TestRestrictedTransport(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestDialContext(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	_, err = DialContext(context.Background(), "tcp", ln.Addr().String())
+	require.Error(t, err)
+
+	_, err = DialContext(context.Background(), "udp", "10.10.10.10:514")
+	require.Error(t, err)
+}
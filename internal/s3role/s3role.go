@@ -0,0 +1,54 @@
+// Package s3role accesses customers' S3 buckets through IAM roles they grant
+// Tesseral, rather than with Tesseral's own credentials.
+//
+// Tesseral assumes a customer's role with the customer's Project ID as the
+// external ID. A role whose trust policy requires that external ID can
+// therefore only be used on behalf of that Project, even if another Project
+// learns its ARN.
+package s3role
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/google/uuid"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+// ExternalID returns the external ID Tesseral presents when assuming a role on
+// behalf of a Project.
+func ExternalID(projectID uuid.UUID) string {
+	return idformat.Project.Format(projectID)
+}
+
+// ValidateRoleARN returns an error if roleARN is not the ARN of an IAM role.
+func ValidateRoleARN(roleARN string) error {
+	a, err := arn.Parse(roleARN)
+	if err != nil {
+		return fmt.Errorf("parse arn: %w", err)
+	}
+
+	if a.Service != "iam" || !strings.HasPrefix(a.Resource, "role/") {
+		return fmt.Errorf("arn is not an iam role: %q", roleARN)
+	}
+
+	return nil
+}
+
+// WithRole returns an S3 option that makes requests with the credentials of
+// roleARN, assumed on behalf of projectID.
+func WithRole(stsClient *sts.Client, roleARN string, projectID uuid.UUID) func(*s3.Options) {
+	provider := stscreds.NewAssumeRoleProvider(stsClient, roleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.ExternalID = aws.String(ExternalID(projectID))
+		o.RoleSessionName = "tesseral"
+	})
+
+	return func(o *s3.Options) {
+		o.Credentials = aws.NewCredentialsCache(provider)
+	}
+}
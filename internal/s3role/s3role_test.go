@@ -0,0 +1,27 @@
+package s3role
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRoleARN(t *testing.T) {
+	t.Parallel()
+
+	for roleARN, valid := range map[string]bool{
+		"arn:aws:iam::123456789012:role/tesseral-audit-logs":      true,
+		"arn:aws:iam::123456789012:role/path/tesseral-audit-logs": true,
+		"arn:aws:iam::123456789012:user/tesseral-audit-logs":      false,
+		"arn:aws:s3:::tesseral-audit-logs":                        false,
+		"tesseral-audit-logs":                                     false,
+		"":                                                        false,
+	} {
+		err := ValidateRoleARN(roleARN)
+		if valid {
+			require.NoError(t, err, roleARN)
+		} else {
+			require.Error(t, err, roleARN)
+		}
+	}
+}
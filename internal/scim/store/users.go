@@ -162,9 +162,21 @@ func (s *Store) CreateUser(ctx context.Context, user User) (User, error) {
 		return nil, fmt.Errorf("validate email domain: %w", err)
 	}
 
+	// users provisioned over SCIM get an identity of their own, rather than
+	// joining the identity of another user with the same email
+	qIdentity, err := q.CreateIdentity(ctx, queries.CreateIdentityParams{
+		ID:        uuid.New(),
+		ProjectID: authn.ProjectID(ctx),
+		Email:     parsed.UserName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create identity: %w", err)
+	}
+
 	qUser, err := q.CreateUser(ctx, queries.CreateUserParams{
 		ID:             uuid.New(),
 		OrganizationID: authn.OrganizationID(ctx),
+		IdentityID:     qIdentity.ID,
 		Email:          parsed.UserName,
 	})
 	if err != nil {
@@ -220,16 +232,27 @@ func (s *Store) UpdateUser(ctx context.Context, id string, user User) (User, err
 		return s.DeleteUser(ctx, id)
 	}
 
+	qPreviousUser, err := q.GetUserByID(ctx, queries.GetUserByIDParams{
+		OrganizationID: authn.OrganizationID(ctx),
+		ID:             userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &SCIMError{
+				Status: http.StatusNotFound,
+				Detail: "user not found",
+			}
+		}
+
+		return nil, fmt.Errorf("get user by id: %w", err)
+	}
+
 	auditPreviousUser, err := s.auditlogStore.GetUser(ctx, tx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("get user for audit log: %w", err)
 	}
 
-	qUser, err := q.UpdateUser(ctx, queries.UpdateUserParams{
-		OrganizationID: authn.OrganizationID(ctx),
-		ID:             userID,
-		Email:          parsed.UserName,
-	})
+	qUser, err := s.updateUserEmail(ctx, q, qPreviousUser, parsed.UserName)
 	if err != nil {
 		var pgxErr *pgconn.PgError
 		if errors.As(err, &pgxErr) {
@@ -241,7 +264,7 @@ func (s *Store) UpdateUser(ctx context.Context, id string, user User) (User, err
 			}
 		}
 
-		return nil, fmt.Errorf("update user: %w", err)
+		return nil, fmt.Errorf("update user email: %w", err)
 	}
 
 	auditUser, err := s.auditlogStore.GetUser(ctx, tx, qUser.ID)
@@ -340,11 +363,7 @@ func (s *Store) PatchUser(ctx context.Context, id string, operations PatchOperat
 		return nil, fmt.Errorf("get user for audit log: %w", err)
 	}
 
-	qUser, err = q.UpdateUser(ctx, queries.UpdateUserParams{
-		OrganizationID: authn.OrganizationID(ctx),
-		ID:             userID,
-		Email:          parsed.UserName,
-	})
+	qUser, err = s.updateUserEmail(ctx, q, qUser, parsed.UserName)
 	if err != nil {
 		var pgxErr *pgconn.PgError
 		if errors.As(err, &pgxErr) {
@@ -356,7 +375,7 @@ func (s *Store) PatchUser(ctx context.Context, id string, operations PatchOperat
 			}
 		}
 
-		return nil, fmt.Errorf("update user: %w", err)
+		return nil, fmt.Errorf("update user email: %w", err)
 	}
 
 	auditUser, err := s.auditlogStore.GetUser(ctx, tx, qUser.ID)
@@ -425,6 +444,10 @@ func (s *Store) DeleteUser(ctx context.Context, id string) (User, error) {
 		return nil, fmt.Errorf("delete user: %w", err)
 	}
 
+	if err := q.DeleteIdentityIfUnused(ctx, qUser.IdentityID); err != nil {
+		return nil, fmt.Errorf("delete identity if unused: %w", err)
+	}
+
 	if _, err := s.logAuditEvent(ctx, q, logAuditEventParams{
 		EventName: "tesseral.users.delete",
 		EventDetails: &auditlogv1.DeleteUser{
@@ -444,6 +467,46 @@ func (s *Store) DeleteUser(ctx context.Context, id string) (User, error) {
 	return formatUser(true, qUser, false), nil
 }
 
+// updateUserEmail updates a user's email. If the email changes, this also
+// moves the user onto a new identity of its own, deleting the previous identity
+// if no other user has it.
+//
+// SCIM does not prove that the user owns the new email, so the user never
+// joins another user's identity, and so never gets its credentials.
+func (s *Store) updateUserEmail(ctx context.Context, q *queries.Queries, qUser queries.User, email string) (queries.User, error) {
+	identityID := qUser.IdentityID
+	if email != qUser.Email {
+		qIdentity, err := q.CreateIdentity(ctx, queries.CreateIdentityParams{
+			ID:        uuid.New(),
+			ProjectID: authn.ProjectID(ctx),
+			Email:     email,
+		})
+		if err != nil {
+			return queries.User{}, fmt.Errorf("create identity: %w", err)
+		}
+
+		identityID = qIdentity.ID
+	}
+
+	qUpdatedUser, err := q.UpdateUser(ctx, queries.UpdateUserParams{
+		OrganizationID: qUser.OrganizationID,
+		ID:             qUser.ID,
+		Email:          email,
+		IdentityID:     identityID,
+	})
+	if err != nil {
+		return queries.User{}, fmt.Errorf("update user: %w", err)
+	}
+
+	if qUser.IdentityID != identityID {
+		if err := q.DeleteIdentityIfUnused(ctx, qUser.IdentityID); err != nil {
+			return queries.User{}, fmt.Errorf("delete identity if unused: %w", err)
+		}
+	}
+
+	return qUpdatedUser, nil
+}
+
 func parseUser(user User) (*parsedUser, error) {
 	m, ok := user.(map[string]any)
 	if !ok {
@@ -126,12 +126,22 @@ func (s *Store) CreateDogfoodProject(ctx context.Context, req *CreateDogfoodProj
 	// create the bootstrap user inside the dogfood organization
 	bootstrapUserEmail := req.RootUserEmail
 	bootstrapUserPasswordBcrypt := string(bootstrapUserPasswordBcryptBytes)
+	qIdentity, err := q.CreateIdentity(ctx, queries.CreateIdentityParams{
+		ID:             uuid.New(),
+		ProjectID:      dogfoodProjectID,
+		Email:          bootstrapUserEmail,
+		PasswordBcrypt: &bootstrapUserPasswordBcrypt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create identity: %w", err)
+	}
+
 	if _, err := q.CreateUser(ctx, queries.CreateUserParams{
 		ID:             uuid.New(),
 		OrganizationID: dogfoodOrganizationID,
+		IdentityID:     qIdentity.ID,
 		Email:          bootstrapUserEmail,
 		IsOwner:        true,
-		PasswordBcrypt: &bootstrapUserPasswordBcrypt,
 	}); err != nil {
 		return nil, fmt.Errorf("create user: %w", err)
 	}
@@ -25,7 +25,9 @@ var (
 	AuthenticatorAppRecoveryCode  = prettyuuid.MustNewFormat("authenticator_app_recovery_code_", alphabet)
 	PasswordResetCode             = prettyuuid.MustNewFormat("password_reset_code_", alphabet)
 	Role                          = prettyuuid.MustNewFormat("role_", alphabet)
+	RoleTemplate                  = prettyuuid.MustNewFormat("role_template_", alphabet)
 	UserRoleAssignment            = prettyuuid.MustNewFormat("user_role_assignment_", alphabet)
+	AccessRequest                 = prettyuuid.MustNewFormat("access_request_", alphabet)
 
 	IntermediateSessionSecretToken = prettyuuid.MustNewFormat("tesseral_secret_intermediate_session_token_", alphabet)
 
@@ -48,7 +50,16 @@ var (
 	ProjectWebhookSettings = prettyuuid.MustNewFormat("project_webhook_settings_", alphabet)
 	AuditLogEvent          = prettyuuid.MustNewFormat("audit_log_event_", alphabet)
 
+	AuditLogExportDestination = prettyuuid.MustNewFormat("audit_log_export_destination_", alphabet)
+	AuditLogArchive           = prettyuuid.MustNewFormat("audit_log_archive_", alphabet)
+
 	OIDCConnection = prettyuuid.MustNewFormat("oidc_connection_", alphabet)
+
+	WebhookEndpoint = prettyuuid.MustNewFormat("webhook_endpoint_", alphabet)
+	WebhookMessage  = prettyuuid.MustNewFormat("webhook_message_", alphabet)
+	WebhookDelivery = prettyuuid.MustNewFormat("webhook_delivery_", alphabet)
+
+	EmailTemplate = prettyuuid.MustNewFormat("email_template_", alphabet)
 )
 
 func MustNewFormat(prefix string) prettyuuid.Format {
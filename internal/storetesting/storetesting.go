@@ -79,8 +79,11 @@ INSERT INTO project_ui_settings (id, project_id)
   VALUES (gen_random_uuid(), '252491cc-76e3-4957-ab23-47d83c34f240'::uuid);
 
 -- Create a user in the dogfood project
-INSERT INTO users (id, email, password_bcrypt, organization_id, is_owner)
-  VALUES ('e071bbfe-6f27-4526-ab37-0ad251742836'::uuid, 'root@app.tesseral.example.com', crypt('password', gen_salt('bf', 14)), '7a76decb-6d79-49ce-9449-34fcc53151df', true);
+INSERT INTO identities (id, project_id, email, password_bcrypt, email_verified)
+  VALUES ('5a0a4e3c-1b43-4a8e-9a57-5d7b1f0f6c21'::uuid, '252491cc-76e3-4957-ab23-47d83c34f240', 'root@app.tesseral.example.com', crypt('password', gen_salt('bf', 14)), TRUE);
+
+INSERT INTO users (id, identity_id, email, organization_id, is_owner)
+  VALUES ('e071bbfe-6f27-4526-ab37-0ad251742836'::uuid, '5a0a4e3c-1b43-4a8e-9a57-5d7b1f0f6c21'::uuid, 'root@app.tesseral.example.com', '7a76decb-6d79-49ce-9449-34fcc53151df', true);
 `
 
 	_, err := e.DB.Exec(context.Background(), sql)
@@ -168,8 +171,13 @@ INSERT INTO session_signing_keys (id, project_id, public_key, private_key_cipher
 	userEmail := fmt.Sprintf("%s@%s", formattedUserID, projectVaultDomain)
 
 	_, err = e.DB.Exec(t.Context(), `
-INSERT INTO users (id, email, password_bcrypt, organization_id, is_owner)
-  VALUES ($1::uuid, $2, crypt('password', gen_salt('bf', 14)), $3::uuid, true);
+WITH identity AS (
+  INSERT INTO identities (id, project_id, email, password_bcrypt, email_verified)
+    VALUES (gen_random_uuid(), (SELECT project_id FROM organizations WHERE id = $3::uuid), $2, crypt('password', gen_salt('bf', 14)), TRUE)
+  RETURNING id
+)
+INSERT INTO users (id, identity_id, email, organization_id, is_owner)
+  SELECT $1::uuid, identity.id, $2, $3::uuid, true FROM identity;
 `,
 		userID.String(),
 		userEmail,
@@ -233,8 +241,14 @@ func (e *Environment) NewUser(t *testing.T, organizationID string, user *backend
 
 	// Create the user
 	_, err = e.DB.Exec(t.Context(), `
-INSERT INTO users (id, email, password_bcrypt, organization_id, is_owner, display_name, google_user_id, microsoft_user_id, github_user_id)
-  VALUES ($1::uuid, $2, crypt('password', gen_salt('bf', 14)), $3::uuid, $4, $5, $6, $7, $8);
+WITH identity AS (
+  INSERT INTO identities (id, project_id, email, password_bcrypt, email_verified)
+    VALUES (gen_random_uuid(), (SELECT project_id FROM organizations WHERE id = $3::uuid), $2, crypt('password', gen_salt('bf', 14)), TRUE)
+  ON CONFLICT (project_id, email) WHERE email_verified DO UPDATE SET email = excluded.email
+  RETURNING id
+)
+INSERT INTO users (id, identity_id, email, organization_id, is_owner, display_name, google_user_id, microsoft_user_id, github_user_id)
+  SELECT $1::uuid, identity.id, $2, $3::uuid, $4, $5, $6, $7, $8 FROM identity;
 `,
 		userID.String(),
 		user.Email,
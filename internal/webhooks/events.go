@@ -0,0 +1,191 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	webhooksv1 "github.com/tesseral-labs/tesseral/internal/webhooks/gen/tesseral/webhooks/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// EventVersion is the version of the webhook event catalogue defined in
+// tesseral.webhooks.v1.
+const EventVersion = "v1"
+
+type NewEventParams struct {
+	AuditLogEventID uuid.UUID
+	ProjectID       uuid.UUID
+	OrganizationID  *uuid.UUID
+	EventTime       time.Time
+
+	// EventDetails is the tesseral.auditlog.v1 message logged with the audit
+	// log event.
+	EventDetails proto.Message
+}
+
+// NewEvent returns the typed webhook event for an audit log event. It returns
+// nil if the audit log event has no counterpart in the event catalogue, as is
+// the case for custom audit log events.
+func NewEvent(p NewEventParams) *webhooksv1.Event {
+	event := &webhooksv1.Event{
+		Id:         idformat.AuditLogEvent.Format(p.AuditLogEventID),
+		Version:    EventVersion,
+		CreateTime: timestamppb.New(p.EventTime),
+		ProjectId:  idformat.Project.Format(p.ProjectID),
+	}
+
+	if p.OrganizationID != nil {
+		organizationID := idformat.Organization.Format(*p.OrganizationID)
+		event.OrganizationId = &organizationID
+	}
+
+	switch d := p.EventDetails.(type) {
+	case *auditlogv1.CreateUser:
+		event.Type = "user.created"
+		event.Data = &webhooksv1.Event_User{User: &webhooksv1.UserData{User: d.User}}
+	case *auditlogv1.UpdateUser:
+		event.Type = "user.updated"
+		event.Data = &webhooksv1.Event_User{User: &webhooksv1.UserData{User: d.User, PreviousUser: d.PreviousUser}}
+	case *auditlogv1.DeleteUser:
+		event.Type = "user.deleted"
+		event.Data = &webhooksv1.Event_User{User: &webhooksv1.UserData{PreviousUser: d.User}}
+
+	case *auditlogv1.CreateSession:
+		event.Type = "session.created"
+		event.Data = &webhooksv1.Event_Session{Session: &webhooksv1.SessionData{Session: d.Session}}
+	case *auditlogv1.RevokeSession:
+		event.Type = "session.revoked"
+		event.Data = &webhooksv1.Event_Session{Session: &webhooksv1.SessionData{Session: d.Session, PreviousSession: d.PreviousSession}}
+
+	case *auditlogv1.CreateOrganization:
+		event.Type = "organization.created"
+		event.Data = &webhooksv1.Event_Organization{Organization: &webhooksv1.OrganizationData{Organization: d.Organization}}
+	case *auditlogv1.UpdateOrganization:
+		event.Type = "organization.updated"
+		event.Data = &webhooksv1.Event_Organization{Organization: &webhooksv1.OrganizationData{Organization: d.Organization, PreviousOrganization: d.PreviousOrganization}}
+	case *auditlogv1.DeleteOrganization:
+		event.Type = "organization.deleted"
+		event.Data = &webhooksv1.Event_Organization{Organization: &webhooksv1.OrganizationData{PreviousOrganization: d.Organization}}
+
+	case *auditlogv1.AssignUserRole:
+		event.Type = "role_assignment.changed"
+		event.Data = &webhooksv1.Event_RoleAssignment{RoleAssignment: &webhooksv1.RoleAssignmentData{UserRoleAssignment: d.UserRoleAssignment}}
+	case *auditlogv1.UnassignUserRole:
+		event.Type = "role_assignment.changed"
+		event.Data = &webhooksv1.Event_RoleAssignment{RoleAssignment: &webhooksv1.RoleAssignmentData{PreviousUserRoleAssignment: d.UserRoleAssignment}}
+	case *auditlogv1.AssignAPIKeyRole:
+		event.Type = "role_assignment.changed"
+		event.Data = &webhooksv1.Event_RoleAssignment{RoleAssignment: &webhooksv1.RoleAssignmentData{ApiKeyRoleAssignment: d.ApiKeyRoleAssignment}}
+	case *auditlogv1.UnassignAPIKeyRole:
+		event.Type = "role_assignment.changed"
+		event.Data = &webhooksv1.Event_RoleAssignment{RoleAssignment: &webhooksv1.RoleAssignmentData{PreviousApiKeyRoleAssignment: d.ApiKeyRoleAssignment}}
+
+	case *auditlogv1.CreateSAMLConnection:
+		event.Type = "saml_connection.created"
+		event.Data = &webhooksv1.Event_SamlConnection{SamlConnection: &webhooksv1.SAMLConnectionData{SamlConnection: d.SamlConnection}}
+	case *auditlogv1.UpdateSAMLConnection:
+		event.Type = "saml_connection.updated"
+		event.Data = &webhooksv1.Event_SamlConnection{SamlConnection: &webhooksv1.SAMLConnectionData{SamlConnection: d.SamlConnection, PreviousSamlConnection: d.PreviousSamlConnection}}
+	case *auditlogv1.DeleteSAMLConnection:
+		event.Type = "saml_connection.deleted"
+		event.Data = &webhooksv1.Event_SamlConnection{SamlConnection: &webhooksv1.SAMLConnectionData{PreviousSamlConnection: d.SamlConnection}}
+
+	case *auditlogv1.CreateOIDCConnection:
+		event.Type = "oidc_connection.created"
+		event.Data = &webhooksv1.Event_OidcConnection{OidcConnection: &webhooksv1.OIDCConnectionData{OidcConnection: d.OidcConnection}}
+	case *auditlogv1.UpdateOIDCConnection:
+		event.Type = "oidc_connection.updated"
+		event.Data = &webhooksv1.Event_OidcConnection{OidcConnection: &webhooksv1.OIDCConnectionData{OidcConnection: d.OidcConnection, PreviousOidcConnection: d.PreviousOidcConnection}}
+	case *auditlogv1.DeleteOIDCConnection:
+		event.Type = "oidc_connection.deleted"
+		event.Data = &webhooksv1.Event_OidcConnection{OidcConnection: &webhooksv1.OIDCConnectionData{PreviousOidcConnection: d.OidcConnection}}
+
+	case *auditlogv1.CreateAPIKey:
+		event.Type = "api_key.created"
+		event.Data = &webhooksv1.Event_ApiKey{ApiKey: &webhooksv1.APIKeyData{ApiKey: d.ApiKey}}
+	case *auditlogv1.UpdateAPIKey:
+		event.Type = "api_key.updated"
+		event.Data = &webhooksv1.Event_ApiKey{ApiKey: &webhooksv1.APIKeyData{ApiKey: d.ApiKey, PreviousApiKey: d.PreviousApiKey}}
+	case *auditlogv1.RotateAPIKey:
+		event.Type = "api_key.rotated"
+		event.Data = &webhooksv1.Event_ApiKey{ApiKey: &webhooksv1.APIKeyData{ApiKey: d.ApiKey, PreviousApiKey: d.PreviousApiKey}}
+	case *auditlogv1.ExpireAPIKeyPreviousSecretToken:
+		event.Type = "api_key.previous_secret_token_expired"
+		event.Data = &webhooksv1.Event_ApiKey{ApiKey: &webhooksv1.APIKeyData{ApiKey: d.ApiKey, PreviousApiKey: d.PreviousApiKey}}
+	case *auditlogv1.RevokeAPIKey:
+		event.Type = "api_key.revoked"
+		event.Data = &webhooksv1.Event_ApiKey{ApiKey: &webhooksv1.APIKeyData{ApiKey: d.ApiKey, PreviousApiKey: d.PreviousApiKey}}
+	case *auditlogv1.DeleteAPIKey:
+		event.Type = "api_key.deleted"
+		event.Data = &webhooksv1.Event_ApiKey{ApiKey: &webhooksv1.APIKeyData{PreviousApiKey: d.ApiKey}}
+
+	case *auditlogv1.CreateSCIMAPIKey:
+		event.Type = "scim_api_key.created"
+		event.Data = &webhooksv1.Event_ScimApiKey{ScimApiKey: &webhooksv1.SCIMAPIKeyData{ScimApiKey: d.ScimApiKey}}
+	case *auditlogv1.UpdateSCIMAPIKey:
+		event.Type = "scim_api_key.updated"
+		event.Data = &webhooksv1.Event_ScimApiKey{ScimApiKey: &webhooksv1.SCIMAPIKeyData{ScimApiKey: d.ScimApiKey, PreviousScimApiKey: d.PreviousScimApiKey}}
+	case *auditlogv1.RevokeSCIMAPIKey:
+		event.Type = "scim_api_key.revoked"
+		event.Data = &webhooksv1.Event_ScimApiKey{ScimApiKey: &webhooksv1.SCIMAPIKeyData{ScimApiKey: d.ScimApiKey, PreviousScimApiKey: d.PreviousScimApiKey}}
+	case *auditlogv1.DeleteSCIMAPIKey:
+		event.Type = "scim_api_key.deleted"
+		event.Data = &webhooksv1.Event_ScimApiKey{ScimApiKey: &webhooksv1.SCIMAPIKeyData{PreviousScimApiKey: d.ScimApiKey}}
+
+	case *auditlogv1.CreateRole:
+		event.Type = "role.created"
+		event.Data = &webhooksv1.Event_Role{Role: &webhooksv1.RoleData{Role: d.Role}}
+	case *auditlogv1.UpdateRole:
+		event.Type = "role.updated"
+		event.Data = &webhooksv1.Event_Role{Role: &webhooksv1.RoleData{Role: d.Role, PreviousRole: d.PreviousRole}}
+	case *auditlogv1.DeleteRole:
+		event.Type = "role.deleted"
+		event.Data = &webhooksv1.Event_Role{Role: &webhooksv1.RoleData{PreviousRole: d.Role}}
+
+	case *auditlogv1.CreateUserInvite:
+		event.Type = "user_invite.created"
+		event.Data = &webhooksv1.Event_UserInvite{UserInvite: &webhooksv1.UserInviteData{UserInvite: d.UserInvite}}
+	case *auditlogv1.DeleteUserInvite:
+		event.Type = "user_invite.deleted"
+		event.Data = &webhooksv1.Event_UserInvite{UserInvite: &webhooksv1.UserInviteData{PreviousUserInvite: d.UserInvite}}
+
+	case *auditlogv1.CreatePasskey:
+		event.Type = "passkey.created"
+		event.Data = &webhooksv1.Event_Passkey{Passkey: &webhooksv1.PasskeyData{Passkey: d.Passkey}}
+	case *auditlogv1.UpdatePasskey:
+		event.Type = "passkey.updated"
+		event.Data = &webhooksv1.Event_Passkey{Passkey: &webhooksv1.PasskeyData{Passkey: d.Passkey, PreviousPasskey: d.PreviousPasskey}}
+	case *auditlogv1.DeletePasskey:
+		event.Type = "passkey.deleted"
+		event.Data = &webhooksv1.Event_Passkey{Passkey: &webhooksv1.PasskeyData{PreviousPasskey: d.Passkey}}
+
+	default:
+		return nil
+	}
+
+	return event
+}
+
+// NewEventMessage returns the message to send for a typed webhook event.
+func NewEventMessage(event *webhooksv1.Event) (Message, error) {
+	eventBytes, err := protojson.Marshal(event)
+	if err != nil {
+		return Message{}, fmt.Errorf("marshal event: %w", err)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(eventBytes, &payload); err != nil {
+		return Message{}, fmt.Errorf("unmarshal event: %w", err)
+	}
+
+	return Message{
+		EventType: event.Type,
+		Payload:   payload,
+	}, nil
+}
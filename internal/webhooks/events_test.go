@@ -0,0 +1,117 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	auditlogv1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+)
+
+func TestNewEvent(t *testing.T) {
+	auditLogEventID := uuid.New()
+	projectID := uuid.New()
+	organizationID := uuid.New()
+
+	event := NewEvent(NewEventParams{
+		AuditLogEventID: auditLogEventID,
+		ProjectID:       projectID,
+		OrganizationID:  &organizationID,
+		EventTime:       time.Now(),
+		EventDetails: &auditlogv1.UpdateUser{
+			User:         &auditlogv1.User{Id: "user_123", Email: "new@example.com"},
+			PreviousUser: &auditlogv1.User{Id: "user_123", Email: "old@example.com"},
+		},
+	})
+	require.NotNil(t, event)
+	require.Equal(t, "user.updated", event.Type)
+	require.Equal(t, EventVersion, event.Version)
+	require.Equal(t, idformat.AuditLogEvent.Format(auditLogEventID), event.Id)
+	require.Equal(t, idformat.Project.Format(projectID), event.ProjectId)
+	require.Equal(t, idformat.Organization.Format(organizationID), event.GetOrganizationId())
+	require.Equal(t, "new@example.com", event.GetUser().GetUser().GetEmail())
+	require.Equal(t, "old@example.com", event.GetUser().GetPreviousUser().GetEmail())
+}
+
+func TestNewEvent_Deleted(t *testing.T) {
+	event := NewEvent(NewEventParams{
+		AuditLogEventID: uuid.New(),
+		ProjectID:       uuid.New(),
+		EventTime:       time.Now(),
+		EventDetails: &auditlogv1.DeleteUser{
+			User: &auditlogv1.User{Id: "user_123"},
+		},
+	})
+	require.NotNil(t, event)
+	require.Equal(t, "user.deleted", event.Type)
+	require.Nil(t, event.OrganizationId)
+	require.Nil(t, event.GetUser().GetUser())
+	require.Equal(t, "user_123", event.GetUser().GetPreviousUser().GetId())
+}
+
+func TestNewEvent_RoleAssignment(t *testing.T) {
+	event := NewEvent(NewEventParams{
+		AuditLogEventID: uuid.New(),
+		ProjectID:       uuid.New(),
+		EventTime:       time.Now(),
+		EventDetails: &auditlogv1.UnassignAPIKeyRole{
+			ApiKeyRoleAssignment: &auditlogv1.APIKeyRoleAssignment{Id: "api_key_role_assignment_123"},
+		},
+	})
+	require.NotNil(t, event)
+	require.Equal(t, "role_assignment.changed", event.Type)
+	require.Nil(t, event.GetRoleAssignment().GetApiKeyRoleAssignment())
+	require.Equal(t, "api_key_role_assignment_123", event.GetRoleAssignment().GetPreviousApiKeyRoleAssignment().GetId())
+}
+
+func TestNewEvent_NotInCatalogue(t *testing.T) {
+	event := NewEvent(NewEventParams{
+		AuditLogEventID: uuid.New(),
+		ProjectID:       uuid.New(),
+		EventTime:       time.Now(),
+		EventDetails: &auditlogv1.InitiateSAMLConnection{
+			SamlConnection: &auditlogv1.SAMLConnection{Id: "saml_connection_123"},
+		},
+	})
+	require.Nil(t, event)
+}
+
+func TestNewEventMessage(t *testing.T) {
+	event := NewEvent(NewEventParams{
+		AuditLogEventID: uuid.New(),
+		ProjectID:       uuid.New(),
+		EventTime:       time.Now(),
+		EventDetails: &auditlogv1.RevokeSession{
+			Session:         &auditlogv1.Session{Id: "session_123", Revoked: true},
+			PreviousSession: &auditlogv1.Session{Id: "session_123"},
+		},
+	})
+
+	msg, err := NewEventMessage(event)
+	require.NoError(t, err)
+	require.Equal(t, "session.revoked", msg.EventType)
+	require.Equal(t, "session.revoked", msg.Payload["type"])
+	require.Equal(t, EventVersion, msg.Payload["version"])
+
+	session := msg.Payload["session"].(map[string]any)
+	require.Equal(t, true, session["session"].(map[string]any)["revoked"])
+	require.NotContains(t, session["previousSession"].(map[string]any), "revoked")
+}
+
+func TestNewEvent_APIKeyRotated(t *testing.T) {
+	event := NewEvent(NewEventParams{
+		AuditLogEventID: uuid.New(),
+		ProjectID:       uuid.New(),
+		EventTime:       time.Now(),
+		EventDetails: &auditlogv1.RotateAPIKey{
+			ApiKey:         &auditlogv1.APIKey{Id: "api_key_123", SecretTokenSuffix: "new1"},
+			PreviousApiKey: &auditlogv1.APIKey{Id: "api_key_123", SecretTokenSuffix: "old1"},
+		},
+	})
+	require.NotNil(t, event)
+	require.Equal(t, "api_key.rotated", event.Type)
+	require.Equal(t, "new1", event.GetApiKey().GetApiKey().GetSecretTokenSuffix())
+	require.Equal(t, "old1", event.GetApiKey().GetPreviousApiKey().GetSecretTokenSuffix())
+}
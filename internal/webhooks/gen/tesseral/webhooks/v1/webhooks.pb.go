@@ -0,0 +1,1257 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: tesseral/webhooks/v1/webhooks.proto
+
+package webhooksv1
+
+import (
+	v1 "github.com/tesseral-labs/tesseral/internal/auditlog/gen/tesseral/auditlog/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Event is the payload of every typed webhook message.
+//
+// Events in this package are version "v1". Fields may be added to v1 events,
+// but existing fields are never removed or repurposed; such changes require a
+// new version.
+//
+// Each data message carries a snapshot of the affected resource before and
+// after the event. The "before" snapshot is unset for events that create a
+// resource, and the "after" snapshot is unset for events that delete one.
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The ID of the audit log event this webhook event was derived from.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The event type, e.g. "user.created".
+	Type string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	// The catalogue version of the event, e.g. "v1".
+	Version        string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	CreateTime     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	ProjectId      string                 `protobuf:"bytes,5,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	OrganizationId *string                `protobuf:"bytes,6,opt,name=organization_id,json=organizationId,proto3,oneof" json:"organization_id,omitempty"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*Event_User
+	//	*Event_Session
+	//	*Event_Organization
+	//	*Event_RoleAssignment
+	//	*Event_SamlConnection
+	//	*Event_OidcConnection
+	//	*Event_ApiKey
+	//	*Event_ScimApiKey
+	//	*Event_Role
+	//	*Event_UserInvite
+	//	*Event_Passkey
+	Data          isEvent_Data `protobuf_oneof:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_tesseral_webhooks_v1_webhooks_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Event) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Event) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *Event) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+func (x *Event) GetOrganizationId() string {
+	if x != nil && x.OrganizationId != nil {
+		return *x.OrganizationId
+	}
+	return ""
+}
+
+func (x *Event) GetData() isEvent_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Event) GetUser() *UserData {
+	if x != nil {
+		if x, ok := x.Data.(*Event_User); ok {
+			return x.User
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetSession() *SessionData {
+	if x != nil {
+		if x, ok := x.Data.(*Event_Session); ok {
+			return x.Session
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetOrganization() *OrganizationData {
+	if x != nil {
+		if x, ok := x.Data.(*Event_Organization); ok {
+			return x.Organization
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetRoleAssignment() *RoleAssignmentData {
+	if x != nil {
+		if x, ok := x.Data.(*Event_RoleAssignment); ok {
+			return x.RoleAssignment
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetSamlConnection() *SAMLConnectionData {
+	if x != nil {
+		if x, ok := x.Data.(*Event_SamlConnection); ok {
+			return x.SamlConnection
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetOidcConnection() *OIDCConnectionData {
+	if x != nil {
+		if x, ok := x.Data.(*Event_OidcConnection); ok {
+			return x.OidcConnection
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetApiKey() *APIKeyData {
+	if x != nil {
+		if x, ok := x.Data.(*Event_ApiKey); ok {
+			return x.ApiKey
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetScimApiKey() *SCIMAPIKeyData {
+	if x != nil {
+		if x, ok := x.Data.(*Event_ScimApiKey); ok {
+			return x.ScimApiKey
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetRole() *RoleData {
+	if x != nil {
+		if x, ok := x.Data.(*Event_Role); ok {
+			return x.Role
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetUserInvite() *UserInviteData {
+	if x != nil {
+		if x, ok := x.Data.(*Event_UserInvite); ok {
+			return x.UserInvite
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetPasskey() *PasskeyData {
+	if x != nil {
+		if x, ok := x.Data.(*Event_Passkey); ok {
+			return x.Passkey
+		}
+	}
+	return nil
+}
+
+type isEvent_Data interface {
+	isEvent_Data()
+}
+
+type Event_User struct {
+	User *UserData `protobuf:"bytes,10,opt,name=user,proto3,oneof"`
+}
+
+type Event_Session struct {
+	Session *SessionData `protobuf:"bytes,11,opt,name=session,proto3,oneof"`
+}
+
+type Event_Organization struct {
+	Organization *OrganizationData `protobuf:"bytes,12,opt,name=organization,proto3,oneof"`
+}
+
+type Event_RoleAssignment struct {
+	RoleAssignment *RoleAssignmentData `protobuf:"bytes,13,opt,name=role_assignment,json=roleAssignment,proto3,oneof"`
+}
+
+type Event_SamlConnection struct {
+	SamlConnection *SAMLConnectionData `protobuf:"bytes,14,opt,name=saml_connection,json=samlConnection,proto3,oneof"`
+}
+
+type Event_OidcConnection struct {
+	OidcConnection *OIDCConnectionData `protobuf:"bytes,15,opt,name=oidc_connection,json=oidcConnection,proto3,oneof"`
+}
+
+type Event_ApiKey struct {
+	ApiKey *APIKeyData `protobuf:"bytes,16,opt,name=api_key,json=apiKey,proto3,oneof"`
+}
+
+type Event_ScimApiKey struct {
+	ScimApiKey *SCIMAPIKeyData `protobuf:"bytes,17,opt,name=scim_api_key,json=scimApiKey,proto3,oneof"`
+}
+
+type Event_Role struct {
+	Role *RoleData `protobuf:"bytes,18,opt,name=role,proto3,oneof"`
+}
+
+type Event_UserInvite struct {
+	UserInvite *UserInviteData `protobuf:"bytes,19,opt,name=user_invite,json=userInvite,proto3,oneof"`
+}
+
+type Event_Passkey struct {
+	Passkey *PasskeyData `protobuf:"bytes,20,opt,name=passkey,proto3,oneof"`
+}
+
+func (*Event_User) isEvent_Data() {}
+
+func (*Event_Session) isEvent_Data() {}
+
+func (*Event_Organization) isEvent_Data() {}
+
+func (*Event_RoleAssignment) isEvent_Data() {}
+
+func (*Event_SamlConnection) isEvent_Data() {}
+
+func (*Event_OidcConnection) isEvent_Data() {}
+
+func (*Event_ApiKey) isEvent_Data() {}
+
+func (*Event_ScimApiKey) isEvent_Data() {}
+
+func (*Event_Role) isEvent_Data() {}
+
+func (*Event_UserInvite) isEvent_Data() {}
+
+func (*Event_Passkey) isEvent_Data() {}
+
+// Sent for user.created, user.updated, and user.deleted.
+type UserData struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *v1.User               `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	PreviousUser  *v1.User               `protobuf:"bytes,2,opt,name=previous_user,json=previousUser,proto3" json:"previous_user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserData) Reset() {
+	*x = UserData{}
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserData) ProtoMessage() {}
+
+func (x *UserData) ProtoReflect() protoreflect.Message {
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserData.ProtoReflect.Descriptor instead.
+func (*UserData) Descriptor() ([]byte, []int) {
+	return file_tesseral_webhooks_v1_webhooks_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *UserData) GetUser() *v1.User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *UserData) GetPreviousUser() *v1.User {
+	if x != nil {
+		return x.PreviousUser
+	}
+	return nil
+}
+
+// Sent for session.created and session.revoked.
+type SessionData struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Session         *v1.Session            `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	PreviousSession *v1.Session            `protobuf:"bytes,2,opt,name=previous_session,json=previousSession,proto3" json:"previous_session,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SessionData) Reset() {
+	*x = SessionData{}
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionData) ProtoMessage() {}
+
+func (x *SessionData) ProtoReflect() protoreflect.Message {
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionData.ProtoReflect.Descriptor instead.
+func (*SessionData) Descriptor() ([]byte, []int) {
+	return file_tesseral_webhooks_v1_webhooks_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SessionData) GetSession() *v1.Session {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+func (x *SessionData) GetPreviousSession() *v1.Session {
+	if x != nil {
+		return x.PreviousSession
+	}
+	return nil
+}
+
+// Sent for organization.created, organization.updated, and
+// organization.deleted.
+type OrganizationData struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Organization         *v1.Organization       `protobuf:"bytes,1,opt,name=organization,proto3" json:"organization,omitempty"`
+	PreviousOrganization *v1.Organization       `protobuf:"bytes,2,opt,name=previous_organization,json=previousOrganization,proto3" json:"previous_organization,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *OrganizationData) Reset() {
+	*x = OrganizationData{}
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrganizationData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrganizationData) ProtoMessage() {}
+
+func (x *OrganizationData) ProtoReflect() protoreflect.Message {
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrganizationData.ProtoReflect.Descriptor instead.
+func (*OrganizationData) Descriptor() ([]byte, []int) {
+	return file_tesseral_webhooks_v1_webhooks_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OrganizationData) GetOrganization() *v1.Organization {
+	if x != nil {
+		return x.Organization
+	}
+	return nil
+}
+
+func (x *OrganizationData) GetPreviousOrganization() *v1.Organization {
+	if x != nil {
+		return x.PreviousOrganization
+	}
+	return nil
+}
+
+// Sent for role_assignment.changed. Exactly one of the user or API key role
+// assignment pairs is populated. An assignment has only an "after" snapshot,
+// and an unassignment has only a "before" snapshot.
+type RoleAssignmentData struct {
+	state                        protoimpl.MessageState   `protogen:"open.v1"`
+	UserRoleAssignment           *v1.UserRoleAssignment   `protobuf:"bytes,1,opt,name=user_role_assignment,json=userRoleAssignment,proto3" json:"user_role_assignment,omitempty"`
+	PreviousUserRoleAssignment   *v1.UserRoleAssignment   `protobuf:"bytes,2,opt,name=previous_user_role_assignment,json=previousUserRoleAssignment,proto3" json:"previous_user_role_assignment,omitempty"`
+	ApiKeyRoleAssignment         *v1.APIKeyRoleAssignment `protobuf:"bytes,3,opt,name=api_key_role_assignment,json=apiKeyRoleAssignment,proto3" json:"api_key_role_assignment,omitempty"`
+	PreviousApiKeyRoleAssignment *v1.APIKeyRoleAssignment `protobuf:"bytes,4,opt,name=previous_api_key_role_assignment,json=previousApiKeyRoleAssignment,proto3" json:"previous_api_key_role_assignment,omitempty"`
+	unknownFields                protoimpl.UnknownFields
+	sizeCache                    protoimpl.SizeCache
+}
+
+func (x *RoleAssignmentData) Reset() {
+	*x = RoleAssignmentData{}
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoleAssignmentData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoleAssignmentData) ProtoMessage() {}
+
+func (x *RoleAssignmentData) ProtoReflect() protoreflect.Message {
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoleAssignmentData.ProtoReflect.Descriptor instead.
+func (*RoleAssignmentData) Descriptor() ([]byte, []int) {
+	return file_tesseral_webhooks_v1_webhooks_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RoleAssignmentData) GetUserRoleAssignment() *v1.UserRoleAssignment {
+	if x != nil {
+		return x.UserRoleAssignment
+	}
+	return nil
+}
+
+func (x *RoleAssignmentData) GetPreviousUserRoleAssignment() *v1.UserRoleAssignment {
+	if x != nil {
+		return x.PreviousUserRoleAssignment
+	}
+	return nil
+}
+
+func (x *RoleAssignmentData) GetApiKeyRoleAssignment() *v1.APIKeyRoleAssignment {
+	if x != nil {
+		return x.ApiKeyRoleAssignment
+	}
+	return nil
+}
+
+func (x *RoleAssignmentData) GetPreviousApiKeyRoleAssignment() *v1.APIKeyRoleAssignment {
+	if x != nil {
+		return x.PreviousApiKeyRoleAssignment
+	}
+	return nil
+}
+
+// Sent for saml_connection.created, saml_connection.updated, and
+// saml_connection.deleted.
+type SAMLConnectionData struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	SamlConnection         *v1.SAMLConnection     `protobuf:"bytes,1,opt,name=saml_connection,json=samlConnection,proto3" json:"saml_connection,omitempty"`
+	PreviousSamlConnection *v1.SAMLConnection     `protobuf:"bytes,2,opt,name=previous_saml_connection,json=previousSamlConnection,proto3" json:"previous_saml_connection,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *SAMLConnectionData) Reset() {
+	*x = SAMLConnectionData{}
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SAMLConnectionData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SAMLConnectionData) ProtoMessage() {}
+
+func (x *SAMLConnectionData) ProtoReflect() protoreflect.Message {
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SAMLConnectionData.ProtoReflect.Descriptor instead.
+func (*SAMLConnectionData) Descriptor() ([]byte, []int) {
+	return file_tesseral_webhooks_v1_webhooks_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SAMLConnectionData) GetSamlConnection() *v1.SAMLConnection {
+	if x != nil {
+		return x.SamlConnection
+	}
+	return nil
+}
+
+func (x *SAMLConnectionData) GetPreviousSamlConnection() *v1.SAMLConnection {
+	if x != nil {
+		return x.PreviousSamlConnection
+	}
+	return nil
+}
+
+// Sent for oidc_connection.created, oidc_connection.updated, and
+// oidc_connection.deleted.
+type OIDCConnectionData struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	OidcConnection         *v1.OIDCConnection     `protobuf:"bytes,1,opt,name=oidc_connection,json=oidcConnection,proto3" json:"oidc_connection,omitempty"`
+	PreviousOidcConnection *v1.OIDCConnection     `protobuf:"bytes,2,opt,name=previous_oidc_connection,json=previousOidcConnection,proto3" json:"previous_oidc_connection,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *OIDCConnectionData) Reset() {
+	*x = OIDCConnectionData{}
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OIDCConnectionData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OIDCConnectionData) ProtoMessage() {}
+
+func (x *OIDCConnectionData) ProtoReflect() protoreflect.Message {
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OIDCConnectionData.ProtoReflect.Descriptor instead.
+func (*OIDCConnectionData) Descriptor() ([]byte, []int) {
+	return file_tesseral_webhooks_v1_webhooks_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *OIDCConnectionData) GetOidcConnection() *v1.OIDCConnection {
+	if x != nil {
+		return x.OidcConnection
+	}
+	return nil
+}
+
+func (x *OIDCConnectionData) GetPreviousOidcConnection() *v1.OIDCConnection {
+	if x != nil {
+		return x.PreviousOidcConnection
+	}
+	return nil
+}
+
+// Sent for api_key.created, api_key.updated, api_key.rotated,
+// api_key.previous_secret_token_expired, api_key.revoked, and api_key.deleted.
+type APIKeyData struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ApiKey         *v1.APIKey             `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	PreviousApiKey *v1.APIKey             `protobuf:"bytes,2,opt,name=previous_api_key,json=previousApiKey,proto3" json:"previous_api_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *APIKeyData) Reset() {
+	*x = APIKeyData{}
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *APIKeyData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*APIKeyData) ProtoMessage() {}
+
+func (x *APIKeyData) ProtoReflect() protoreflect.Message {
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use APIKeyData.ProtoReflect.Descriptor instead.
+func (*APIKeyData) Descriptor() ([]byte, []int) {
+	return file_tesseral_webhooks_v1_webhooks_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *APIKeyData) GetApiKey() *v1.APIKey {
+	if x != nil {
+		return x.ApiKey
+	}
+	return nil
+}
+
+func (x *APIKeyData) GetPreviousApiKey() *v1.APIKey {
+	if x != nil {
+		return x.PreviousApiKey
+	}
+	return nil
+}
+
+// Sent for scim_api_key.created, scim_api_key.updated, scim_api_key.revoked,
+// and scim_api_key.deleted.
+type SCIMAPIKeyData struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	ScimApiKey         *v1.SCIMAPIKey         `protobuf:"bytes,1,opt,name=scim_api_key,json=scimApiKey,proto3" json:"scim_api_key,omitempty"`
+	PreviousScimApiKey *v1.SCIMAPIKey         `protobuf:"bytes,2,opt,name=previous_scim_api_key,json=previousScimApiKey,proto3" json:"previous_scim_api_key,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *SCIMAPIKeyData) Reset() {
+	*x = SCIMAPIKeyData{}
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SCIMAPIKeyData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SCIMAPIKeyData) ProtoMessage() {}
+
+func (x *SCIMAPIKeyData) ProtoReflect() protoreflect.Message {
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SCIMAPIKeyData.ProtoReflect.Descriptor instead.
+func (*SCIMAPIKeyData) Descriptor() ([]byte, []int) {
+	return file_tesseral_webhooks_v1_webhooks_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SCIMAPIKeyData) GetScimApiKey() *v1.SCIMAPIKey {
+	if x != nil {
+		return x.ScimApiKey
+	}
+	return nil
+}
+
+func (x *SCIMAPIKeyData) GetPreviousScimApiKey() *v1.SCIMAPIKey {
+	if x != nil {
+		return x.PreviousScimApiKey
+	}
+	return nil
+}
+
+// Sent for role.created, role.updated, and role.deleted.
+type RoleData struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Role          *v1.Role               `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	PreviousRole  *v1.Role               `protobuf:"bytes,2,opt,name=previous_role,json=previousRole,proto3" json:"previous_role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoleData) Reset() {
+	*x = RoleData{}
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoleData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoleData) ProtoMessage() {}
+
+func (x *RoleData) ProtoReflect() protoreflect.Message {
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoleData.ProtoReflect.Descriptor instead.
+func (*RoleData) Descriptor() ([]byte, []int) {
+	return file_tesseral_webhooks_v1_webhooks_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RoleData) GetRole() *v1.Role {
+	if x != nil {
+		return x.Role
+	}
+	return nil
+}
+
+func (x *RoleData) GetPreviousRole() *v1.Role {
+	if x != nil {
+		return x.PreviousRole
+	}
+	return nil
+}
+
+// Sent for user_invite.created and user_invite.deleted.
+type UserInviteData struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	UserInvite         *v1.UserInvite         `protobuf:"bytes,1,opt,name=user_invite,json=userInvite,proto3" json:"user_invite,omitempty"`
+	PreviousUserInvite *v1.UserInvite         `protobuf:"bytes,2,opt,name=previous_user_invite,json=previousUserInvite,proto3" json:"previous_user_invite,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *UserInviteData) Reset() {
+	*x = UserInviteData{}
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserInviteData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserInviteData) ProtoMessage() {}
+
+func (x *UserInviteData) ProtoReflect() protoreflect.Message {
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserInviteData.ProtoReflect.Descriptor instead.
+func (*UserInviteData) Descriptor() ([]byte, []int) {
+	return file_tesseral_webhooks_v1_webhooks_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UserInviteData) GetUserInvite() *v1.UserInvite {
+	if x != nil {
+		return x.UserInvite
+	}
+	return nil
+}
+
+func (x *UserInviteData) GetPreviousUserInvite() *v1.UserInvite {
+	if x != nil {
+		return x.PreviousUserInvite
+	}
+	return nil
+}
+
+// Sent for passkey.created, passkey.updated, and passkey.deleted.
+type PasskeyData struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Passkey         *v1.Passkey            `protobuf:"bytes,1,opt,name=passkey,proto3" json:"passkey,omitempty"`
+	PreviousPasskey *v1.Passkey            `protobuf:"bytes,2,opt,name=previous_passkey,json=previousPasskey,proto3" json:"previous_passkey,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PasskeyData) Reset() {
+	*x = PasskeyData{}
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PasskeyData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PasskeyData) ProtoMessage() {}
+
+func (x *PasskeyData) ProtoReflect() protoreflect.Message {
+	mi := &file_tesseral_webhooks_v1_webhooks_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PasskeyData.ProtoReflect.Descriptor instead.
+func (*PasskeyData) Descriptor() ([]byte, []int) {
+	return file_tesseral_webhooks_v1_webhooks_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PasskeyData) GetPasskey() *v1.Passkey {
+	if x != nil {
+		return x.Passkey
+	}
+	return nil
+}
+
+func (x *PasskeyData) GetPreviousPasskey() *v1.Passkey {
+	if x != nil {
+		return x.PreviousPasskey
+	}
+	return nil
+}
+
+var File_tesseral_webhooks_v1_webhooks_proto protoreflect.FileDescriptor
+
+var file_tesseral_webhooks_v1_webhooks_proto_rawDesc = string([]byte{
+	0x0a, 0x23, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2f, 0x77, 0x65, 0x62, 0x68, 0x6f,
+	0x6f, 0x6b, 0x73, 0x2f, 0x76, 0x31, 0x2f, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x14, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e,
+	0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x21, 0x74, 0x65,
+	0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2f, 0x61, 0x75, 0x64, 0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2f,
+	0x76, 0x31, 0x2f, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
+	0xf2, 0x07, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x0b, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x54, 0x69, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x5f,
+	0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63,
+	0x74, 0x49, 0x64, 0x12, 0x2c, 0x0a, 0x0f, 0x6f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x0e,
+	0x6f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x88, 0x01,
+	0x01, 0x12, 0x34, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1e, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x77, 0x65, 0x62, 0x68, 0x6f,
+	0x6f, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x48,
+	0x00, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x3d, 0x0a, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65,
+	0x72, 0x61, 0x6c, 0x2e, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x07, 0x73,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x4c, 0x0a, 0x0c, 0x6f, 0x72, 0x67, 0x61, 0x6e, 0x69,
+	0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x74,
+	0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x0c, 0x6f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x53, 0x0a, 0x0f, 0x72, 0x6f, 0x6c, 0x65, 0x5f, 0x61, 0x73, 0x73,
+	0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x28, 0x2e,
+	0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x6d,
+	0x65, 0x6e, 0x74, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x0e, 0x72, 0x6f, 0x6c, 0x65, 0x41,
+	0x73, 0x73, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x53, 0x0a, 0x0f, 0x73, 0x61, 0x6d,
+	0x6c, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0e, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x28, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x77, 0x65,
+	0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x41, 0x4d, 0x4c, 0x43, 0x6f,
+	0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x0e,
+	0x73, 0x61, 0x6d, 0x6c, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x53,
+	0x0a, 0x0f, 0x6f, 0x69, 0x64, 0x63, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72,
+	0x61, 0x6c, 0x2e, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4f,
+	0x49, 0x44, 0x43, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74,
+	0x61, 0x48, 0x00, 0x52, 0x0e, 0x6f, 0x69, 0x64, 0x63, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x07, 0x61, 0x70, 0x69, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x10,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e,
+	0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x50, 0x49, 0x4b,
+	0x65, 0x79, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x06, 0x61, 0x70, 0x69, 0x4b, 0x65, 0x79,
+	0x12, 0x48, 0x0a, 0x0c, 0x73, 0x63, 0x69, 0x6d, 0x5f, 0x61, 0x70, 0x69, 0x5f, 0x6b, 0x65, 0x79,
+	0x18, 0x11, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61,
+	0x6c, 0x2e, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x43,
+	0x49, 0x4d, 0x41, 0x50, 0x49, 0x4b, 0x65, 0x79, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x0a,
+	0x73, 0x63, 0x69, 0x6d, 0x41, 0x70, 0x69, 0x4b, 0x65, 0x79, 0x12, 0x34, 0x0a, 0x04, 0x72, 0x6f,
+	0x6c, 0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65,
+	0x72, 0x61, 0x6c, 0x2e, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x52, 0x6f, 0x6c, 0x65, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65,
+	0x12, 0x47, 0x0a, 0x0b, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x18,
+	0x13, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c,
+	0x2e, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65,
+	0x72, 0x49, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52, 0x0a, 0x75,
+	0x73, 0x65, 0x72, 0x49, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x12, 0x3d, 0x0a, 0x07, 0x70, 0x61, 0x73,
+	0x73, 0x6b, 0x65, 0x79, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x74, 0x65, 0x73,
+	0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x50, 0x61, 0x73, 0x73, 0x6b, 0x65, 0x79, 0x44, 0x61, 0x74, 0x61, 0x48, 0x00, 0x52,
+	0x07, 0x70, 0x61, 0x73, 0x73, 0x6b, 0x65, 0x79, 0x42, 0x06, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x42, 0x12, 0x0a, 0x10, 0x5f, 0x6f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x69, 0x64, 0x22, 0x7b, 0x0a, 0x08, 0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61,
+	0x12, 0x2e, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74, 0x6c,
+	0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72,
+	0x12, 0x3f, 0x0a, 0x0d, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x75, 0x73, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72,
+	0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x55,
+	0x73, 0x65, 0x72, 0x52, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x55, 0x73, 0x65,
+	0x72, 0x22, 0x90, 0x01, 0x0a, 0x0b, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74,
+	0x61, 0x12, 0x37, 0x0a, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75,
+	0x64, 0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x48, 0x0a, 0x10, 0x70, 0x72,
+	0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e,
+	0x61, 0x75, 0x64, 0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x52, 0x0f, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x22, 0xb3, 0x01, 0x0a, 0x10, 0x4f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x12, 0x46, 0x0a, 0x0c, 0x6f, 0x72, 0x67,
+	0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x22, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74,
+	0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x6f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x57, 0x0a, 0x15, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x6f, 0x72,
+	0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x22, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69,
+	0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x14, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x4f, 0x72,
+	0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xb4, 0x03, 0x0a, 0x12, 0x52,
+	0x6f, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x44, 0x61, 0x74,
+	0x61, 0x12, 0x5a, 0x0a, 0x14, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x72, 0x6f, 0x6c, 0x65, 0x5f, 0x61,
+	0x73, 0x73, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x28, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74,
+	0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x6f, 0x6c, 0x65, 0x41,
+	0x73, 0x73, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x12, 0x75, 0x73, 0x65, 0x72, 0x52,
+	0x6f, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x6b, 0x0a,
+	0x1d, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x72,
+	0x6f, 0x6c, 0x65, 0x5f, 0x61, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e,
+	0x61, 0x75, 0x64, 0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65, 0x72,
+	0x52, 0x6f, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x1a,
+	0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x55, 0x73, 0x65, 0x72, 0x52, 0x6f, 0x6c, 0x65,
+	0x41, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x61, 0x0a, 0x17, 0x61, 0x70,
+	0x69, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x72, 0x6f, 0x6c, 0x65, 0x5f, 0x61, 0x73, 0x73, 0x69, 0x67,
+	0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x74, 0x65,
+	0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e,
+	0x76, 0x31, 0x2e, 0x41, 0x50, 0x49, 0x4b, 0x65, 0x79, 0x52, 0x6f, 0x6c, 0x65, 0x41, 0x73, 0x73,
+	0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x14, 0x61, 0x70, 0x69, 0x4b, 0x65, 0x79, 0x52,
+	0x6f, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x72, 0x0a,
+	0x20, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x61, 0x70, 0x69, 0x5f, 0x6b, 0x65,
+	0x79, 0x5f, 0x72, 0x6f, 0x6c, 0x65, 0x5f, 0x61, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e,
+	0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72,
+	0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x41,
+	0x50, 0x49, 0x4b, 0x65, 0x79, 0x52, 0x6f, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x6d,
+	0x65, 0x6e, 0x74, 0x52, 0x1c, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x41, 0x70, 0x69,
+	0x4b, 0x65, 0x79, 0x52, 0x6f, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e,
+	0x74, 0x22, 0xc3, 0x01, 0x0a, 0x12, 0x53, 0x41, 0x4d, 0x4c, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x12, 0x4d, 0x0a, 0x0f, 0x73, 0x61, 0x6d, 0x6c,
+	0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x24, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64,
+	0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x41, 0x4d, 0x4c, 0x43, 0x6f, 0x6e,
+	0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0e, 0x73, 0x61, 0x6d, 0x6c, 0x43, 0x6f, 0x6e,
+	0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x5e, 0x0a, 0x18, 0x70, 0x72, 0x65, 0x76, 0x69,
+	0x6f, 0x75, 0x73, 0x5f, 0x73, 0x61, 0x6d, 0x6c, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x74, 0x65, 0x73, 0x73,
+	0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x41, 0x4d, 0x4c, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x16, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x53, 0x61, 0x6d, 0x6c, 0x43, 0x6f, 0x6e,
+	0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xc3, 0x01, 0x0a, 0x12, 0x4f, 0x49, 0x44, 0x43,
+	0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x12, 0x4d,
+	0x0a, 0x0f, 0x6f, 0x69, 0x64, 0x63, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72,
+	0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4f,
+	0x49, 0x44, 0x43, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0e, 0x6f,
+	0x69, 0x64, 0x63, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x5e, 0x0a,
+	0x18, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x6f, 0x69, 0x64, 0x63, 0x5f, 0x63,
+	0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x24, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74,
+	0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x49, 0x44, 0x43, 0x43, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x16, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x4f,
+	0x69, 0x64, 0x63, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x8b, 0x01,
+	0x0a, 0x0a, 0x41, 0x50, 0x49, 0x4b, 0x65, 0x79, 0x44, 0x61, 0x74, 0x61, 0x12, 0x35, 0x0a, 0x07,
+	0x61, 0x70, 0x69, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e,
+	0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74, 0x6c, 0x6f,
+	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x50, 0x49, 0x4b, 0x65, 0x79, 0x52, 0x06, 0x61, 0x70, 0x69,
+	0x4b, 0x65, 0x79, 0x12, 0x46, 0x0a, 0x10, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f,
+	0x61, 0x70, 0x69, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e,
+	0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74, 0x6c, 0x6f,
+	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x50, 0x49, 0x4b, 0x65, 0x79, 0x52, 0x0e, 0x70, 0x72, 0x65,
+	0x76, 0x69, 0x6f, 0x75, 0x73, 0x41, 0x70, 0x69, 0x4b, 0x65, 0x79, 0x22, 0xa9, 0x01, 0x0a, 0x0e,
+	0x53, 0x43, 0x49, 0x4d, 0x41, 0x50, 0x49, 0x4b, 0x65, 0x79, 0x44, 0x61, 0x74, 0x61, 0x12, 0x42,
+	0x0a, 0x0c, 0x73, 0x63, 0x69, 0x6d, 0x5f, 0x61, 0x70, 0x69, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e,
+	0x61, 0x75, 0x64, 0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x43, 0x49, 0x4d,
+	0x41, 0x50, 0x49, 0x4b, 0x65, 0x79, 0x52, 0x0a, 0x73, 0x63, 0x69, 0x6d, 0x41, 0x70, 0x69, 0x4b,
+	0x65, 0x79, 0x12, 0x53, 0x0a, 0x15, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x73,
+	0x63, 0x69, 0x6d, 0x5f, 0x61, 0x70, 0x69, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64,
+	0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x43, 0x49, 0x4d, 0x41, 0x50, 0x49,
+	0x4b, 0x65, 0x79, 0x52, 0x12, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x53, 0x63, 0x69,
+	0x6d, 0x41, 0x70, 0x69, 0x4b, 0x65, 0x79, 0x22, 0x7b, 0x0a, 0x08, 0x52, 0x6f, 0x6c, 0x65, 0x44,
+	0x61, 0x74, 0x61, 0x12, 0x2e, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64,
+	0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x04, 0x72,
+	0x6f, 0x6c, 0x65, 0x12, 0x3f, 0x0a, 0x0d, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f,
+	0x72, 0x6f, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x65, 0x73,
+	0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73,
+	0x52, 0x6f, 0x6c, 0x65, 0x22, 0xa7, 0x01, 0x0a, 0x0e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x76,
+	0x69, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x12, 0x41, 0x0a, 0x0b, 0x75, 0x73, 0x65, 0x72, 0x5f,
+	0x69, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x74,
+	0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74, 0x6c, 0x6f, 0x67,
+	0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x52, 0x0a,
+	0x75, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x12, 0x52, 0x0a, 0x14, 0x70, 0x72,
+	0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x6e, 0x76, 0x69,
+	0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65,
+	0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e,
+	0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x52, 0x12, 0x70, 0x72, 0x65, 0x76,
+	0x69, 0x6f, 0x75, 0x73, 0x55, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x22, 0x90,
+	0x01, 0x0a, 0x0b, 0x50, 0x61, 0x73, 0x73, 0x6b, 0x65, 0x79, 0x44, 0x61, 0x74, 0x61, 0x12, 0x37,
+	0x0a, 0x07, 0x70, 0x61, 0x73, 0x73, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1d, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x74,
+	0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x73, 0x73, 0x6b, 0x65, 0x79, 0x52, 0x07,
+	0x70, 0x61, 0x73, 0x73, 0x6b, 0x65, 0x79, 0x12, 0x48, 0x0a, 0x10, 0x70, 0x72, 0x65, 0x76, 0x69,
+	0x6f, 0x75, 0x73, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1d, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x61, 0x75, 0x64,
+	0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x73, 0x73, 0x6b, 0x65, 0x79,
+	0x52, 0x0f, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x50, 0x61, 0x73, 0x73, 0x6b, 0x65,
+	0x79, 0x42, 0xf4, 0x01, 0x0a, 0x18, 0x63, 0x6f, 0x6d, 0x2e, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72,
+	0x61, 0x6c, 0x2e, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x42, 0x0d,
+	0x57, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a,
+	0x57, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x65, 0x73, 0x73,
+	0x65, 0x72, 0x61, 0x6c, 0x2d, 0x6c, 0x61, 0x62, 0x73, 0x2f, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72,
+	0x61, 0x6c, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x77, 0x65, 0x62, 0x68,
+	0x6f, 0x6f, 0x6b, 0x73, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x74, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61,
+	0x6c, 0x2f, 0x77, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x2f, 0x76, 0x31, 0x3b, 0x77, 0x65,
+	0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x76, 0x31, 0xa2, 0x02, 0x03, 0x54, 0x57, 0x58, 0xaa, 0x02,
+	0x14, 0x54, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x2e, 0x57, 0x65, 0x62, 0x68, 0x6f, 0x6f,
+	0x6b, 0x73, 0x2e, 0x56, 0x31, 0xca, 0x02, 0x14, 0x54, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c,
+	0x5c, 0x57, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73, 0x5c, 0x56, 0x31, 0xe2, 0x02, 0x20, 0x54,
+	0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x5c, 0x57, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x73,
+	0x5c, 0x56, 0x31, 0x5c, 0x47, 0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea,
+	0x02, 0x16, 0x54, 0x65, 0x73, 0x73, 0x65, 0x72, 0x61, 0x6c, 0x3a, 0x3a, 0x57, 0x65, 0x62, 0x68,
+	0x6f, 0x6f, 0x6b, 0x73, 0x3a, 0x3a, 0x56, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_tesseral_webhooks_v1_webhooks_proto_rawDescOnce sync.Once
+	file_tesseral_webhooks_v1_webhooks_proto_rawDescData []byte
+)
+
+func file_tesseral_webhooks_v1_webhooks_proto_rawDescGZIP() []byte {
+	file_tesseral_webhooks_v1_webhooks_proto_rawDescOnce.Do(func() {
+		file_tesseral_webhooks_v1_webhooks_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tesseral_webhooks_v1_webhooks_proto_rawDesc), len(file_tesseral_webhooks_v1_webhooks_proto_rawDesc)))
+	})
+	return file_tesseral_webhooks_v1_webhooks_proto_rawDescData
+}
+
+var file_tesseral_webhooks_v1_webhooks_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_tesseral_webhooks_v1_webhooks_proto_goTypes = []any{
+	(*Event)(nil),                   // 0: tesseral.webhooks.v1.Event
+	(*UserData)(nil),                // 1: tesseral.webhooks.v1.UserData
+	(*SessionData)(nil),             // 2: tesseral.webhooks.v1.SessionData
+	(*OrganizationData)(nil),        // 3: tesseral.webhooks.v1.OrganizationData
+	(*RoleAssignmentData)(nil),      // 4: tesseral.webhooks.v1.RoleAssignmentData
+	(*SAMLConnectionData)(nil),      // 5: tesseral.webhooks.v1.SAMLConnectionData
+	(*OIDCConnectionData)(nil),      // 6: tesseral.webhooks.v1.OIDCConnectionData
+	(*APIKeyData)(nil),              // 7: tesseral.webhooks.v1.APIKeyData
+	(*SCIMAPIKeyData)(nil),          // 8: tesseral.webhooks.v1.SCIMAPIKeyData
+	(*RoleData)(nil),                // 9: tesseral.webhooks.v1.RoleData
+	(*UserInviteData)(nil),          // 10: tesseral.webhooks.v1.UserInviteData
+	(*PasskeyData)(nil),             // 11: tesseral.webhooks.v1.PasskeyData
+	(*timestamppb.Timestamp)(nil),   // 12: google.protobuf.Timestamp
+	(*v1.User)(nil),                 // 13: tesseral.auditlog.v1.User
+	(*v1.Session)(nil),              // 14: tesseral.auditlog.v1.Session
+	(*v1.Organization)(nil),         // 15: tesseral.auditlog.v1.Organization
+	(*v1.UserRoleAssignment)(nil),   // 16: tesseral.auditlog.v1.UserRoleAssignment
+	(*v1.APIKeyRoleAssignment)(nil), // 17: tesseral.auditlog.v1.APIKeyRoleAssignment
+	(*v1.SAMLConnection)(nil),       // 18: tesseral.auditlog.v1.SAMLConnection
+	(*v1.OIDCConnection)(nil),       // 19: tesseral.auditlog.v1.OIDCConnection
+	(*v1.APIKey)(nil),               // 20: tesseral.auditlog.v1.APIKey
+	(*v1.SCIMAPIKey)(nil),           // 21: tesseral.auditlog.v1.SCIMAPIKey
+	(*v1.Role)(nil),                 // 22: tesseral.auditlog.v1.Role
+	(*v1.UserInvite)(nil),           // 23: tesseral.auditlog.v1.UserInvite
+	(*v1.Passkey)(nil),              // 24: tesseral.auditlog.v1.Passkey
+}
+var file_tesseral_webhooks_v1_webhooks_proto_depIdxs = []int32{
+	12, // 0: tesseral.webhooks.v1.Event.create_time:type_name -> google.protobuf.Timestamp
+	1,  // 1: tesseral.webhooks.v1.Event.user:type_name -> tesseral.webhooks.v1.UserData
+	2,  // 2: tesseral.webhooks.v1.Event.session:type_name -> tesseral.webhooks.v1.SessionData
+	3,  // 3: tesseral.webhooks.v1.Event.organization:type_name -> tesseral.webhooks.v1.OrganizationData
+	4,  // 4: tesseral.webhooks.v1.Event.role_assignment:type_name -> tesseral.webhooks.v1.RoleAssignmentData
+	5,  // 5: tesseral.webhooks.v1.Event.saml_connection:type_name -> tesseral.webhooks.v1.SAMLConnectionData
+	6,  // 6: tesseral.webhooks.v1.Event.oidc_connection:type_name -> tesseral.webhooks.v1.OIDCConnectionData
+	7,  // 7: tesseral.webhooks.v1.Event.api_key:type_name -> tesseral.webhooks.v1.APIKeyData
+	8,  // 8: tesseral.webhooks.v1.Event.scim_api_key:type_name -> tesseral.webhooks.v1.SCIMAPIKeyData
+	9,  // 9: tesseral.webhooks.v1.Event.role:type_name -> tesseral.webhooks.v1.RoleData
+	10, // 10: tesseral.webhooks.v1.Event.user_invite:type_name -> tesseral.webhooks.v1.UserInviteData
+	11, // 11: tesseral.webhooks.v1.Event.passkey:type_name -> tesseral.webhooks.v1.PasskeyData
+	13, // 12: tesseral.webhooks.v1.UserData.user:type_name -> tesseral.auditlog.v1.User
+	13, // 13: tesseral.webhooks.v1.UserData.previous_user:type_name -> tesseral.auditlog.v1.User
+	14, // 14: tesseral.webhooks.v1.SessionData.session:type_name -> tesseral.auditlog.v1.Session
+	14, // 15: tesseral.webhooks.v1.SessionData.previous_session:type_name -> tesseral.auditlog.v1.Session
+	15, // 16: tesseral.webhooks.v1.OrganizationData.organization:type_name -> tesseral.auditlog.v1.Organization
+	15, // 17: tesseral.webhooks.v1.OrganizationData.previous_organization:type_name -> tesseral.auditlog.v1.Organization
+	16, // 18: tesseral.webhooks.v1.RoleAssignmentData.user_role_assignment:type_name -> tesseral.auditlog.v1.UserRoleAssignment
+	16, // 19: tesseral.webhooks.v1.RoleAssignmentData.previous_user_role_assignment:type_name -> tesseral.auditlog.v1.UserRoleAssignment
+	17, // 20: tesseral.webhooks.v1.RoleAssignmentData.api_key_role_assignment:type_name -> tesseral.auditlog.v1.APIKeyRoleAssignment
+	17, // 21: tesseral.webhooks.v1.RoleAssignmentData.previous_api_key_role_assignment:type_name -> tesseral.auditlog.v1.APIKeyRoleAssignment
+	18, // 22: tesseral.webhooks.v1.SAMLConnectionData.saml_connection:type_name -> tesseral.auditlog.v1.SAMLConnection
+	18, // 23: tesseral.webhooks.v1.SAMLConnectionData.previous_saml_connection:type_name -> tesseral.auditlog.v1.SAMLConnection
+	19, // 24: tesseral.webhooks.v1.OIDCConnectionData.oidc_connection:type_name -> tesseral.auditlog.v1.OIDCConnection
+	19, // 25: tesseral.webhooks.v1.OIDCConnectionData.previous_oidc_connection:type_name -> tesseral.auditlog.v1.OIDCConnection
+	20, // 26: tesseral.webhooks.v1.APIKeyData.api_key:type_name -> tesseral.auditlog.v1.APIKey
+	20, // 27: tesseral.webhooks.v1.APIKeyData.previous_api_key:type_name -> tesseral.auditlog.v1.APIKey
+	21, // 28: tesseral.webhooks.v1.SCIMAPIKeyData.scim_api_key:type_name -> tesseral.auditlog.v1.SCIMAPIKey
+	21, // 29: tesseral.webhooks.v1.SCIMAPIKeyData.previous_scim_api_key:type_name -> tesseral.auditlog.v1.SCIMAPIKey
+	22, // 30: tesseral.webhooks.v1.RoleData.role:type_name -> tesseral.auditlog.v1.Role
+	22, // 31: tesseral.webhooks.v1.RoleData.previous_role:type_name -> tesseral.auditlog.v1.Role
+	23, // 32: tesseral.webhooks.v1.UserInviteData.user_invite:type_name -> tesseral.auditlog.v1.UserInvite
+	23, // 33: tesseral.webhooks.v1.UserInviteData.previous_user_invite:type_name -> tesseral.auditlog.v1.UserInvite
+	24, // 34: tesseral.webhooks.v1.PasskeyData.passkey:type_name -> tesseral.auditlog.v1.Passkey
+	24, // 35: tesseral.webhooks.v1.PasskeyData.previous_passkey:type_name -> tesseral.auditlog.v1.Passkey
+	36, // [36:36] is the sub-list for method output_type
+	36, // [36:36] is the sub-list for method input_type
+	36, // [36:36] is the sub-list for extension type_name
+	36, // [36:36] is the sub-list for extension extendee
+	0,  // [0:36] is the sub-list for field type_name
+}
+
+func init() { file_tesseral_webhooks_v1_webhooks_proto_init() }
+func file_tesseral_webhooks_v1_webhooks_proto_init() {
+	if File_tesseral_webhooks_v1_webhooks_proto != nil {
+		return
+	}
+	file_tesseral_webhooks_v1_webhooks_proto_msgTypes[0].OneofWrappers = []any{
+		(*Event_User)(nil),
+		(*Event_Session)(nil),
+		(*Event_Organization)(nil),
+		(*Event_RoleAssignment)(nil),
+		(*Event_SamlConnection)(nil),
+		(*Event_OidcConnection)(nil),
+		(*Event_ApiKey)(nil),
+		(*Event_ScimApiKey)(nil),
+		(*Event_Role)(nil),
+		(*Event_UserInvite)(nil),
+		(*Event_Passkey)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tesseral_webhooks_v1_webhooks_proto_rawDesc), len(file_tesseral_webhooks_v1_webhooks_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_tesseral_webhooks_v1_webhooks_proto_goTypes,
+		DependencyIndexes: file_tesseral_webhooks_v1_webhooks_proto_depIdxs,
+		MessageInfos:      file_tesseral_webhooks_v1_webhooks_proto_msgTypes,
+	}.Build()
+	File_tesseral_webhooks_v1_webhooks_proto = out.File
+	file_tesseral_webhooks_v1_webhooks_proto_goTypes = nil
+	file_tesseral_webhooks_v1_webhooks_proto_depIdxs = nil
+}
@@ -0,0 +1,64 @@
+package webhooks
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	// Test vector from the Standard Webhooks reference implementations.
+	sig, err := Sign("whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw", "msg_p5jXN8AQM9LWM0D4loKWxJek", time.Unix(1614265330, 0), []byte(`{"test": 2432232314}`))
+	require.NoError(t, err)
+	require.Equal(t, "v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE=", sig)
+}
+
+func TestVerify(t *testing.T) {
+	secret, err := NewSigningSecret()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(secret, "whsec_"))
+
+	otherSecret, err := NewSigningSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	payload := []byte(`{"type":"sync.user","userId":"user_123"}`)
+
+	sig, err := Sign(secret, "webhook_message_123", now, payload)
+	require.NoError(t, err)
+
+	otherSig, err := Sign(otherSecret, "webhook_message_123", now, payload)
+	require.NoError(t, err)
+
+	header := func(sig string) http.Header {
+		h := http.Header{}
+		h.Set(HeaderID, "webhook_message_123")
+		h.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+		h.Set(HeaderSignature, sig)
+		return h
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(t, Verify(secret, header(sig), payload, now))
+	})
+
+	t.Run("valid among several signatures", func(t *testing.T) {
+		require.NoError(t, Verify(secret, header(otherSig+" "+sig), payload, now))
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		require.Error(t, Verify(secret, header(otherSig), payload, now))
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		require.Error(t, Verify(secret, header(sig), []byte(`{"type":"sync.user","userId":"user_456"}`), now))
+	})
+
+	t.Run("stale timestamp", func(t *testing.T) {
+		require.Error(t, Verify(secret, header(sig), payload, now.Add(time.Hour)))
+	})
+}
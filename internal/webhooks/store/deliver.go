@@ -0,0 +1,227 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
+	"github.com/tesseral-labs/tesseral/internal/webhooks/store/queries"
+)
+
+const (
+	deliveryBatchSize     = 100
+	deliveryPollInterval  = time.Second
+	deliveryTimeout       = 15 * time.Second
+	deliveryLeaseDuration = time.Minute
+)
+
+// retrySchedule is the delay before each retry of a failed delivery. A
+// delivery that fails after the last retry is marked as failed.
+var retrySchedule = []time.Duration{
+	5 * time.Second,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+	5 * time.Hour,
+	10 * time.Hour,
+	10 * time.Hour,
+}
+
+// RunDelivery delivers pending webhook messages until ctx is canceled.
+//
+// Deliveries are claimed with a lease, so multiple API servers may call
+// RunDelivery concurrently. A delivery whose lease expires before it is
+// completed, e.g. because the server crashed, is attempted again; delivery is
+// at-least-once.
+func (s *Store) RunDelivery(ctx context.Context) error {
+	ticker := time.NewTicker(deliveryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.deliverPending(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "deliver_pending_webhooks_error", "err", err)
+		}
+
+		// A full batch suggests there is a backlog; keep going without waiting
+		// for the next tick.
+		if err == nil && n == deliveryBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Store) deliverPending(ctx context.Context) (int, error) {
+	leaseExpireTime := time.Now().Add(deliveryLeaseDuration)
+	qDeliveries, err := s.q.ClaimWebhookDeliveries(ctx, queries.ClaimWebhookDeliveriesParams{
+		Limit:           deliveryBatchSize,
+		LeaseExpireTime: &leaseExpireTime,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+
+	// A delivery that can't be attempted, e.g. because its signing secret
+	// can't be decrypted, counts as a failed attempt; otherwise it would be
+	// claimed again on every lease, ahead of the deliveries behind it.
+	for _, qDelivery := range qDeliveries {
+		if err := s.deliver(ctx, qDelivery); err != nil {
+			slog.ErrorContext(ctx, "deliver_webhook_error",
+				"webhook_delivery_id", idformat.WebhookDelivery.Format(qDelivery.ID),
+				"err", err)
+
+			if err := s.recordDeliveryError(ctx, qDelivery, err); err != nil {
+				slog.ErrorContext(ctx, "record_webhook_delivery_error",
+					"webhook_delivery_id", idformat.WebhookDelivery.Format(qDelivery.ID),
+					"err", err)
+			}
+		}
+	}
+
+	return len(qDeliveries), nil
+}
+
+func (s *Store) deliver(ctx context.Context, qDelivery queries.WebhookDelivery) error {
+	qMessage, err := s.q.GetWebhookMessage(ctx, qDelivery.WebhookMessageID)
+	if err != nil {
+		return fmt.Errorf("get webhook message: %w", err)
+	}
+
+	qEndpoint, err := s.q.GetWebhookEndpoint(ctx, qDelivery.WebhookEndpointID)
+	if err != nil {
+		return fmt.Errorf("get webhook endpoint: %w", err)
+	}
+
+	attemptTime := time.Now()
+	if qEndpoint.Disabled {
+		errorMessage := "webhook endpoint is disabled"
+		if _, err := s.q.UpdateWebhookDeliveryAttempt(ctx, queries.UpdateWebhookDeliveryAttemptParams{
+			ID:              qDelivery.ID,
+			Status:          queries.WebhookDeliveryStatusFailed,
+			LastAttemptTime: &attemptTime,
+			LastError:       &errorMessage,
+		}); err != nil {
+			return fmt.Errorf("update webhook delivery attempt: %w", err)
+		}
+		return nil
+	}
+
+	decryptRes, err := s.kms.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:               &s.webhookSigningSecretsKMSKeyID,
+		EncryptionAlgorithm: types.EncryptionAlgorithmSpecRsaesOaepSha256,
+		CiphertextBlob:      qEndpoint.SigningSecretCiphertext,
+	})
+	if err != nil {
+		return fmt.Errorf("decrypt webhook signing secret: %w", err)
+	}
+
+	msgID := idformat.WebhookMessage.Format(qMessage.ID)
+	signature, err := webhooks.Sign(string(decryptRes.Plaintext), msgID, attemptTime, qMessage.Payload)
+	if err != nil {
+		return fmt.Errorf("sign webhook message: %w", err)
+	}
+
+	statusCode, sendErr := s.send(ctx, qEndpoint.Url, msgID, attemptTime, signature, qMessage.Payload)
+
+	updates := queries.UpdateWebhookDeliveryAttemptParams{
+		ID:                     qDelivery.ID,
+		Status:                 queries.WebhookDeliveryStatusSucceeded,
+		LastAttemptTime:        &attemptTime,
+		LastResponseStatusCode: statusCode,
+	}
+
+	if sendErr != nil {
+		updates = failedDeliveryAttempt(qDelivery, attemptTime, sendErr)
+		updates.LastResponseStatusCode = statusCode
+	}
+
+	if _, err := s.q.UpdateWebhookDeliveryAttempt(ctx, updates); err != nil {
+		return fmt.Errorf("update webhook delivery attempt: %w", err)
+	}
+
+	slog.InfoContext(ctx, "webhook_delivery_attempt",
+		"webhook_delivery_id", idformat.WebhookDelivery.Format(qDelivery.ID),
+		"webhook_message_id", msgID,
+		"webhook_endpoint_id", idformat.WebhookEndpoint.Format(qEndpoint.ID),
+		"status", updates.Status,
+		"error", sendErr)
+
+	return nil
+}
+
+// recordDeliveryError records err as a failed attempt of qDelivery.
+func (s *Store) recordDeliveryError(ctx context.Context, qDelivery queries.WebhookDelivery, err error) error {
+	if _, err := s.q.UpdateWebhookDeliveryAttempt(ctx, failedDeliveryAttempt(qDelivery, time.Now(), err)); err != nil {
+		return fmt.Errorf("update webhook delivery attempt: %w", err)
+	}
+
+	return nil
+}
+
+// failedDeliveryAttempt returns the updates for a failed attempt of qDelivery,
+// scheduling a retry according to retrySchedule.
+func failedDeliveryAttempt(qDelivery queries.WebhookDelivery, attemptTime time.Time, err error) queries.UpdateWebhookDeliveryAttemptParams {
+	errorMessage := err.Error()
+	updates := queries.UpdateWebhookDeliveryAttemptParams{
+		ID:              qDelivery.ID,
+		Status:          queries.WebhookDeliveryStatusFailed,
+		LastAttemptTime: &attemptTime,
+		LastError:       &errorMessage,
+	}
+
+	if int(qDelivery.AttemptCount) < len(retrySchedule) {
+		nextAttemptTime := attemptTime.Add(retrySchedule[qDelivery.AttemptCount])
+		updates.Status = queries.WebhookDeliveryStatusPending
+		updates.NextAttemptTime = &nextAttemptTime
+	}
+
+	return updates
+}
+
+// send posts a signed message to an endpoint. Only 2xx responses count as
+// successful deliveries.
+func (s *Store) send(ctx context.Context, url, msgID string, timestamp time.Time, signature string, payload []byte) (*int32, error) {
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhooks.HeaderID, msgID)
+	req.Header.Set(webhooks.HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(webhooks.HeaderSignature, signature)
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	// Drain a bounded amount of the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+
+	statusCode := int32(res.StatusCode)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return &statusCode, fmt.Errorf("unexpected response status code: %d", res.StatusCode)
+	}
+
+	return &statusCode, nil
+}
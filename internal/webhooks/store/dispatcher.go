@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/uuidv7"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
+	"github.com/tesseral-labs/tesseral/internal/webhooks/store/queries"
+)
+
+// CreateApplication returns the project ID as the app ID. The built-in
+// dispatcher keys webhook endpoints directly on projects, so there is nothing
+// else to provision.
+func (s *Store) CreateApplication(ctx context.Context, projectID uuid.UUID, displayName string) (string, error) {
+	return idformat.Project.Format(projectID), nil
+}
+
+// SendMessage records a message and schedules a delivery to every enabled
+// endpoint subscribed to the message's event type. If msg.ID was already sent,
+// SendMessage does nothing and returns the existing message's ID.
+func (s *Store) SendMessage(ctx context.Context, appID string, msg webhooks.Message) (string, error) {
+	projectID, err := idformat.Project.Parse(appID)
+	if err != nil {
+		return "", fmt.Errorf("parse app id: %w", err)
+	}
+
+	payload, err := json.Marshal(msg.Payload)
+	if err != nil {
+		return "", fmt.Errorf("marshal payload: %w", err)
+	}
+
+	_, q, commit, rollback, err := s.tx(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer rollback()
+
+	now := time.Now()
+	messageID := msg.ID
+	if messageID == uuid.Nil {
+		messageID = uuidv7.NewWithTime(now)
+	}
+
+	qMessage, err := q.CreateWebhookMessage(ctx, queries.CreateWebhookMessageParams{
+		ID:        messageID,
+		ProjectID: projectID,
+		EventType: msg.EventType,
+		Payload:   payload,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return idformat.WebhookMessage.Format(messageID), nil
+		}
+		return "", fmt.Errorf("create webhook message: %w", err)
+	}
+
+	qEndpoints, err := q.ListWebhookEndpointsForEventType(ctx, queries.ListWebhookEndpointsForEventTypeParams{
+		ProjectID: projectID,
+		EventType: msg.EventType,
+	})
+	if err != nil {
+		return "", fmt.Errorf("list webhook endpoints for event type: %w", err)
+	}
+
+	for _, qEndpoint := range qEndpoints {
+		if _, err := q.CreateWebhookDelivery(ctx, queries.CreateWebhookDeliveryParams{
+			ID:                uuidv7.NewWithTime(now),
+			WebhookMessageID:  qMessage.ID,
+			WebhookEndpointID: qEndpoint.ID,
+			NextAttemptTime:   &now,
+		}); err != nil {
+			return "", fmt.Errorf("create webhook delivery: %w", err)
+		}
+	}
+
+	if err := commit(); err != nil {
+		return "", fmt.Errorf("commit: %w", err)
+	}
+
+	return idformat.WebhookMessage.Format(qMessage.ID), nil
+}
+
+func (s *Store) ManagementURL(ctx context.Context, appID string) (string, error) {
+	return "", webhooks.ErrManagementURLUnsupported
+}
@@ -0,0 +1,178 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
+	"github.com/tesseral-labs/tesseral/internal/webhooks/store/queries"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	relayBatchSize     = 100
+	relayPollInterval  = time.Second
+	relayLeaseDuration = time.Minute
+
+	// relayMaxBackoff caps the delay between attempts to relay an outbox
+	// message. Outbox messages are retried until they succeed.
+	relayMaxBackoff = 10 * time.Minute
+)
+
+// RunRelay sends messages from the webhook outbox through the Store's
+// dispatcher until ctx is canceled.
+//
+// Stores write outbox messages in the same transaction as the change they
+// describe, so a message is relayed if and only if its change is committed.
+// Outbox messages are claimed with a lease and deleted only once sent, so
+// delivery is at-least-once; the outbox message ID is passed to the
+// dispatcher as the message ID so that resends are deduplicated.
+func (s *Store) RunRelay(ctx context.Context) error {
+	meter := otel.Meter("github.com/tesseral-labs/tesseral/internal/webhooks/store")
+
+	relayed, err := meter.Int64Counter("webhook_outbox.relayed",
+		metric.WithDescription("Webhook outbox messages sent to the dispatcher."))
+	if err != nil {
+		return fmt.Errorf("create relayed counter: %w", err)
+	}
+
+	failures, err := meter.Int64Counter("webhook_outbox.failures",
+		metric.WithDescription("Failed attempts to send webhook outbox messages to the dispatcher."))
+	if err != nil {
+		return fmt.Errorf("create failures counter: %w", err)
+	}
+
+	if _, err := meter.Int64ObservableGauge("webhook_outbox.depth",
+		metric.WithDescription("Webhook outbox messages waiting to be sent."),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			depth, err := s.q.CountWebhookOutboxMessages(ctx)
+			if err != nil {
+				return fmt.Errorf("count webhook outbox messages: %w", err)
+			}
+
+			o.Observe(depth)
+			return nil
+		}),
+	); err != nil {
+		return fmt.Errorf("create depth gauge: %w", err)
+	}
+
+	ticker := time.NewTicker(relayPollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.relayPending(ctx, relayed, failures)
+		if err != nil {
+			slog.ErrorContext(ctx, "relay_webhook_outbox_error", "err", err)
+		}
+
+		// A full batch suggests there is a backlog; keep going without waiting
+		// for the next tick.
+		if err == nil && n == relayBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Store) relayPending(ctx context.Context, relayed, failures metric.Int64Counter) (int, error) {
+	leaseExpireTime := time.Now().Add(relayLeaseDuration)
+	qOutboxMessages, err := s.q.ClaimWebhookOutboxMessages(ctx, queries.ClaimWebhookOutboxMessagesParams{
+		Limit:           relayBatchSize,
+		LeaseExpireTime: &leaseExpireTime,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("claim webhook outbox messages: %w", err)
+	}
+
+	for _, qOutboxMessage := range qOutboxMessages {
+		sendErr := s.relay(ctx, qOutboxMessage)
+		if sendErr == nil {
+			relayed.Add(ctx, 1)
+			// If the message can't be deleted, it is resent once its lease
+			// expires. That's a duplicate the dispatcher deduplicates, so carry
+			// on with the rest of the batch.
+			if err := s.q.DeleteWebhookOutboxMessage(ctx, qOutboxMessage.ID); err != nil {
+				slog.ErrorContext(ctx, "delete_webhook_outbox_message_error",
+					"webhook_outbox_message_id", qOutboxMessage.ID,
+					"err", err)
+			}
+			continue
+		}
+
+		failures.Add(ctx, 1)
+		slog.ErrorContext(ctx, "relay_webhook_outbox_message_error",
+			"webhook_outbox_message_id", qOutboxMessage.ID,
+			"event_type", qOutboxMessage.EventType,
+			"attempt_count", qOutboxMessage.AttemptCount+1,
+			"err", sendErr)
+
+		errorMessage := sendErr.Error()
+		nextAttemptTime := time.Now().Add(relayBackoff(qOutboxMessage.AttemptCount))
+		if err := s.q.UpdateWebhookOutboxMessageAttempt(ctx, queries.UpdateWebhookOutboxMessageAttemptParams{
+			ID:              qOutboxMessage.ID,
+			NextAttemptTime: &nextAttemptTime,
+			LastError:       &errorMessage,
+		}); err != nil {
+			// The message is retried once its lease expires instead.
+			slog.ErrorContext(ctx, "update_webhook_outbox_message_attempt_error",
+				"webhook_outbox_message_id", qOutboxMessage.ID,
+				"err", err)
+		}
+	}
+
+	return len(qOutboxMessages), nil
+}
+
+func (s *Store) relay(ctx context.Context, qOutboxMessage queries.WebhookOutboxMessage) error {
+	qProjectWebhookSettings, err := s.q.GetProjectWebhookSettings(ctx, qOutboxMessage.ProjectID)
+	if err != nil {
+		// The project's webhook settings may have been removed since the message
+		// was written; there is nowhere to send it.
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("get project webhook settings: %w", err)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(qOutboxMessage.Payload, &payload); err != nil {
+		return fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	messageID, err := s.dispatcher.SendMessage(ctx, qProjectWebhookSettings.AppID, webhooks.Message{
+		ID:        qOutboxMessage.ID,
+		EventType: qOutboxMessage.EventType,
+		Payload:   payload,
+	})
+	if err != nil {
+		return fmt.Errorf("send webhook message: %w", err)
+	}
+
+	slog.InfoContext(ctx, "webhook_message_sent", "message_id", messageID, "event_type", qOutboxMessage.EventType)
+	return nil
+}
+
+// relayBackoff returns the delay before the next attempt to relay a message
+// that has failed attemptCount times before.
+func relayBackoff(attemptCount int32) time.Duration {
+	backoff := time.Second
+	for range attemptCount {
+		backoff *= 2
+		if backoff >= relayMaxBackoff {
+			return relayMaxBackoff
+		}
+	}
+	return backoff
+}
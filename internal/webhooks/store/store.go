@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
+	"github.com/tesseral-labs/tesseral/internal/webhooks/store/queries"
+)
+
+// Store is Tesseral's built-in webhooks.Dispatcher. Messages are written to
+// Postgres, and delivered to each project's webhook endpoints by RunDelivery.
+//
+// Store also relays the webhook outbox to a dispatcher, which need not be the
+// Store itself; see RunRelay.
+type Store struct {
+	db                            *pgxpool.Pool
+	q                             *queries.Queries
+	kms                           *kms.Client
+	webhookSigningSecretsKMSKeyID string
+	httpClient                    *http.Client
+	dispatcher                    webhooks.Dispatcher
+}
+
+var _ webhooks.Dispatcher = &Store{}
+
+type NewStoreParams struct {
+	DB                            *pgxpool.Pool
+	KMS                           *kms.Client
+	WebhookSigningSecretsKMSKeyID string
+	HTTPClient                    *http.Client
+
+	// Dispatcher is where RunRelay sends outbox messages. If nil, the Store
+	// itself is used.
+	Dispatcher webhooks.Dispatcher
+}
+
+func New(p NewStoreParams) *Store {
+	store := &Store{
+		db:                            p.DB,
+		q:                             queries.New(p.DB),
+		kms:                           p.KMS,
+		webhookSigningSecretsKMSKeyID: p.WebhookSigningSecretsKMSKeyID,
+		httpClient:                    p.HTTPClient,
+		dispatcher:                    p.Dispatcher,
+	}
+
+	if store.dispatcher == nil {
+		store.dispatcher = store
+	}
+
+	return store
+}
+
+func (s *Store) tx(ctx context.Context) (tx pgx.Tx, q *queries.Queries, commit func() error, rollback func() error, err error) {
+	tx, err = s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("begin tx: %w", err)
+	}
+
+	commit = func() error { return tx.Commit(ctx) }
+	rollback = func() error { return tx.Rollback(ctx) }
+	return tx, queries.New(tx), commit, rollback, nil
+}
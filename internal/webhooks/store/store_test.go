@@ -0,0 +1,367 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/tesseral-labs/tesseral/internal/store/idformat"
+	"github.com/tesseral-labs/tesseral/internal/storetesting"
+	"github.com/tesseral-labs/tesseral/internal/webhooks"
+	"github.com/tesseral-labs/tesseral/internal/webhooks/store/queries"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+var (
+	environment *storetesting.Environment
+)
+
+func TestMain(m *testing.M) {
+	testEnvironment, cleanup := storetesting.NewEnvironment()
+	defer cleanup()
+
+	environment = testEnvironment
+	m.Run()
+}
+
+type receivedMessage struct {
+	header http.Header
+	body   []byte
+}
+
+// testReceiver is a webhook endpoint that responds to each request with the
+// next status code in statusCodes, and records the requests it receives.
+type testReceiver struct {
+	mu          sync.Mutex
+	statusCodes []int
+	received    []receivedMessage
+}
+
+func (r *testReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.received = append(r.received, receivedMessage{header: req.Header, body: body})
+
+	statusCode := http.StatusOK
+	if len(r.statusCodes) > 0 {
+		statusCode, r.statusCodes = r.statusCodes[0], r.statusCodes[1:]
+	}
+	w.WriteHeader(statusCode)
+}
+
+func newTestStore(t *testing.T) (*Store, uuid.UUID) {
+	formattedProjectID, _ := environment.NewProject(t)
+	projectID, err := idformat.Project.Parse(formattedProjectID)
+	require.NoError(t, err)
+
+	return New(NewStoreParams{
+		DB:                            environment.DB,
+		KMS:                           environment.KMS.Client,
+		WebhookSigningSecretsKMSKeyID: environment.KMS.WebhookSigningSecretsKMSKeyID,
+		HTTPClient:                    http.DefaultClient,
+	}), projectID
+}
+
+func newTestEndpoint(t *testing.T, projectID uuid.UUID, url string, eventTypes []string) string {
+	secret, err := webhooks.NewSigningSecret()
+	require.NoError(t, err)
+
+	encryptRes, err := environment.KMS.Client.Encrypt(t.Context(), &kms.EncryptInput{
+		KeyId:               &environment.KMS.WebhookSigningSecretsKMSKeyID,
+		EncryptionAlgorithm: types.EncryptionAlgorithmSpecRsaesOaepSha256,
+		Plaintext:           []byte(secret),
+	})
+	require.NoError(t, err)
+
+	_, err = environment.DB.Exec(t.Context(), `
+INSERT INTO webhook_endpoints (id, project_id, url, event_types, signing_secret_ciphertext)
+  VALUES (gen_random_uuid(), $1::uuid, $2, $3, $4);
+`,
+		projectID.String(),
+		url,
+		eventTypes,
+		encryptRes.CiphertextBlob,
+	)
+	require.NoError(t, err)
+
+	return secret
+}
+
+func TestSendMessage_Delivers(t *testing.T) {
+	store, projectID := newTestStore(t)
+
+	receiver := &testReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	secret := newTestEndpoint(t, projectID, server.URL, nil)
+
+	appID, err := store.CreateApplication(t.Context(), projectID, "test")
+	require.NoError(t, err)
+
+	messageID, err := store.SendMessage(t.Context(), appID, webhooks.Message{
+		EventType: "sync.user",
+		Payload:   map[string]any{"type": "sync.user", "userId": "user_123"},
+	})
+	require.NoError(t, err)
+
+	_, err = store.deliverPending(t.Context())
+	require.NoError(t, err)
+
+	require.Len(t, receiver.received, 1)
+	received := receiver.received[0]
+	require.Equal(t, messageID, received.header.Get(webhooks.HeaderID))
+	require.NoError(t, webhooks.Verify(secret, received.header, received.body, time.Now()))
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(received.body, &payload))
+	require.Equal(t, "user_123", payload["userId"])
+}
+
+func TestSendMessage_FiltersEventTypes(t *testing.T) {
+	store, projectID := newTestStore(t)
+
+	receiver := &testReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	newTestEndpoint(t, projectID, server.URL, []string{"sync.organization"})
+
+	appID, err := store.CreateApplication(t.Context(), projectID, "test")
+	require.NoError(t, err)
+
+	_, err = store.SendMessage(t.Context(), appID, webhooks.Message{
+		EventType: "sync.user",
+		Payload:   map[string]any{"type": "sync.user", "userId": "user_123"},
+	})
+	require.NoError(t, err)
+
+	_, err = store.deliverPending(t.Context())
+	require.NoError(t, err)
+
+	require.Empty(t, receiver.received)
+}
+
+func TestSendMessage_Retries(t *testing.T) {
+	store, projectID := newTestStore(t)
+
+	receiver := &testReceiver{statusCodes: []int{http.StatusInternalServerError}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	newTestEndpoint(t, projectID, server.URL, nil)
+
+	appID, err := store.CreateApplication(t.Context(), projectID, "test")
+	require.NoError(t, err)
+
+	messageID, err := store.SendMessage(t.Context(), appID, webhooks.Message{
+		EventType: "sync.user",
+		Payload:   map[string]any{"type": "sync.user", "userId": "user_123"},
+	})
+	require.NoError(t, err)
+
+	webhookMessageID, err := idformat.WebhookMessage.Parse(messageID)
+	require.NoError(t, err)
+
+	_, err = store.deliverPending(t.Context())
+	require.NoError(t, err)
+	require.Len(t, receiver.received, 1)
+
+	var (
+		status       queries.WebhookDeliveryStatus
+		attemptCount int32
+		lastError    *string
+	)
+	err = environment.DB.QueryRow(t.Context(), `SELECT status, attempt_count, last_error FROM webhook_deliveries WHERE webhook_message_id = $1`, webhookMessageID).Scan(&status, &attemptCount, &lastError)
+	require.NoError(t, err)
+	require.Equal(t, queries.WebhookDeliveryStatusPending, status)
+	require.Equal(t, int32(1), attemptCount)
+	require.NotNil(t, lastError)
+
+	// Skip the backoff.
+	_, err = environment.DB.Exec(t.Context(), `UPDATE webhook_deliveries SET next_attempt_time = now() WHERE webhook_message_id = $1`, webhookMessageID)
+	require.NoError(t, err)
+
+	_, err = store.deliverPending(t.Context())
+	require.NoError(t, err)
+	require.Len(t, receiver.received, 2)
+
+	// Both attempts carry the same message ID, so receivers can deduplicate.
+	require.Equal(t, receiver.received[0].header.Get(webhooks.HeaderID), receiver.received[1].header.Get(webhooks.HeaderID))
+
+	err = environment.DB.QueryRow(t.Context(), `SELECT status, attempt_count, last_error FROM webhook_deliveries WHERE webhook_message_id = $1`, webhookMessageID).Scan(&status, &attemptCount, &lastError)
+	require.NoError(t, err)
+	require.Equal(t, queries.WebhookDeliveryStatusSucceeded, status)
+	require.Equal(t, int32(2), attemptCount)
+}
+
+func TestSendMessage_DeliveryError(t *testing.T) {
+	store, projectID := newTestStore(t)
+
+	// An endpoint whose signing secret can't be decrypted.
+	_, err := environment.DB.Exec(t.Context(), `
+INSERT INTO webhook_endpoints (id, project_id, url, signing_secret_ciphertext)
+  VALUES (gen_random_uuid(), $1::uuid, 'https://example.com', 'invalid');
+`,
+		projectID.String(),
+	)
+	require.NoError(t, err)
+
+	appID, err := store.CreateApplication(t.Context(), projectID, "test")
+	require.NoError(t, err)
+
+	messageID, err := store.SendMessage(t.Context(), appID, webhooks.Message{
+		EventType: "sync.user",
+		Payload:   map[string]any{"type": "sync.user", "userId": "user_123"},
+	})
+	require.NoError(t, err)
+
+	webhookMessageID, err := idformat.WebhookMessage.Parse(messageID)
+	require.NoError(t, err)
+
+	_, err = store.deliverPending(t.Context())
+	require.NoError(t, err)
+
+	var (
+		status          queries.WebhookDeliveryStatus
+		attemptCount    int32
+		lastError       *string
+		nextAttemptTime time.Time
+	)
+	err = environment.DB.QueryRow(t.Context(), `SELECT status, attempt_count, last_error, next_attempt_time FROM webhook_deliveries WHERE webhook_message_id = $1`, webhookMessageID).Scan(&status, &attemptCount, &lastError, &nextAttemptTime)
+	require.NoError(t, err)
+	require.Equal(t, queries.WebhookDeliveryStatusPending, status)
+	require.Equal(t, int32(1), attemptCount)
+	require.NotNil(t, lastError)
+	require.True(t, nextAttemptTime.After(time.Now()))
+}
+
+// failingDispatcher is a webhooks.Dispatcher whose SendMessage always fails.
+type failingDispatcher struct {
+	webhooks.Dispatcher
+}
+
+func (d failingDispatcher) SendMessage(ctx context.Context, appID string, msg webhooks.Message) (string, error) {
+	return "", errors.New("dispatcher unavailable")
+}
+
+func newTestOutboxMessage(t *testing.T, projectID uuid.UUID) uuid.UUID {
+	_, err := environment.DB.Exec(t.Context(), `
+INSERT INTO project_webhook_settings (id, project_id, app_id)
+  VALUES (gen_random_uuid(), $1::uuid, $2);
+`,
+		projectID.String(),
+		idformat.Project.Format(projectID),
+	)
+	require.NoError(t, err)
+
+	outboxMessageID := uuid.New()
+	_, err = environment.DB.Exec(t.Context(), `
+INSERT INTO webhook_outbox_messages (id, project_id, event_type, payload)
+  VALUES ($1::uuid, $2::uuid, 'sync.user', '{"type": "sync.user", "userId": "user_123"}');
+`,
+		outboxMessageID.String(),
+		projectID.String(),
+	)
+	require.NoError(t, err)
+
+	return outboxMessageID
+}
+
+func TestRelay(t *testing.T) {
+	store, projectID := newTestStore(t)
+
+	receiver := &testReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	newTestEndpoint(t, projectID, server.URL, nil)
+	outboxMessageID := newTestOutboxMessage(t, projectID)
+
+	meter := noop.NewMeterProvider().Meter("")
+	relayed, err := meter.Int64Counter("relayed")
+	require.NoError(t, err)
+	failures, err := meter.Int64Counter("failures")
+	require.NoError(t, err)
+
+	_, err = store.relayPending(t.Context(), relayed, failures)
+	require.NoError(t, err)
+
+	// The outbox message is removed once relayed, and relayed with the outbox
+	// message ID as the webhook message ID.
+	var outboxCount int
+	err = environment.DB.QueryRow(t.Context(), `SELECT count(*) FROM webhook_outbox_messages WHERE id = $1`, outboxMessageID).Scan(&outboxCount)
+	require.NoError(t, err)
+	require.Zero(t, outboxCount)
+
+	_, err = store.deliverPending(t.Context())
+	require.NoError(t, err)
+
+	require.Len(t, receiver.received, 1)
+	require.Equal(t, idformat.WebhookMessage.Format(outboxMessageID), receiver.received[0].header.Get(webhooks.HeaderID))
+
+	// Resending the same message is a no-op.
+	_, err = store.SendMessage(t.Context(), idformat.Project.Format(projectID), webhooks.Message{
+		ID:        outboxMessageID,
+		EventType: "sync.user",
+		Payload:   map[string]any{"type": "sync.user", "userId": "user_123"},
+	})
+	require.NoError(t, err)
+
+	_, err = store.deliverPending(t.Context())
+	require.NoError(t, err)
+	require.Len(t, receiver.received, 1)
+}
+
+func TestRelay_DispatcherFailure(t *testing.T) {
+	formattedProjectID, _ := environment.NewProject(t)
+	projectID, err := idformat.Project.Parse(formattedProjectID)
+	require.NoError(t, err)
+
+	store := New(NewStoreParams{
+		DB:         environment.DB,
+		Dispatcher: failingDispatcher{},
+	})
+
+	outboxMessageID := newTestOutboxMessage(t, projectID)
+
+	meter := noop.NewMeterProvider().Meter("")
+	relayed, err := meter.Int64Counter("relayed")
+	require.NoError(t, err)
+	failures, err := meter.Int64Counter("failures")
+	require.NoError(t, err)
+
+	_, err = store.relayPending(t.Context(), relayed, failures)
+	require.NoError(t, err)
+
+	var (
+		attemptCount    int32
+		lastError       *string
+		nextAttemptTime time.Time
+	)
+	err = environment.DB.QueryRow(t.Context(), `SELECT attempt_count, last_error, next_attempt_time FROM webhook_outbox_messages WHERE id = $1`, outboxMessageID).Scan(&attemptCount, &lastError, &nextAttemptTime)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), attemptCount)
+	require.NotNil(t, lastError)
+	require.True(t, nextAttemptTime.After(time.Now()))
+}
+
+func TestRelayBackoff(t *testing.T) {
+	require.Equal(t, time.Second, relayBackoff(0))
+	require.Equal(t, 2*time.Second, relayBackoff(1))
+	require.Equal(t, 8*time.Second, relayBackoff(3))
+	require.Equal(t, relayMaxBackoff, relayBackoff(100))
+}
@@ -0,0 +1,53 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	svix "github.com/svix/svix-webhooks/go"
+	"github.com/svix/svix-webhooks/go/models"
+)
+
+// SvixDispatcher delivers webhook messages through Svix.
+type SvixDispatcher struct {
+	Client *svix.Svix
+}
+
+func (d *SvixDispatcher) CreateApplication(ctx context.Context, projectID uuid.UUID, displayName string) (string, error) {
+	app, err := d.Client.Application.Create(ctx, models.ApplicationIn{
+		Name: displayName,
+	}, nil)
+	if err != nil {
+		return "", fmt.Errorf("create svix application: %w", err)
+	}
+
+	return app.Id, nil
+}
+
+func (d *SvixDispatcher) SendMessage(ctx context.Context, appID string, msg Message) (string, error) {
+	var opts *svix.MessageCreateOptions
+	if msg.ID != uuid.Nil {
+		idempotencyKey := msg.ID.String()
+		opts = &svix.MessageCreateOptions{IdempotencyKey: &idempotencyKey}
+	}
+
+	message, err := d.Client.Message.Create(ctx, appID, models.MessageIn{
+		EventType: msg.EventType,
+		Payload:   msg.Payload,
+	}, opts)
+	if err != nil {
+		return "", fmt.Errorf("create svix message: %w", err)
+	}
+
+	return message.Id, nil
+}
+
+func (d *SvixDispatcher) ManagementURL(ctx context.Context, appID string) (string, error) {
+	dashboard, err := d.Client.Authentication.AppPortalAccess(ctx, appID, models.AppPortalAccessIn{}, nil)
+	if err != nil {
+		return "", fmt.Errorf("get svix app portal access: %w", err)
+	}
+
+	return dashboard.Url, nil
+}
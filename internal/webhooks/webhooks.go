@@ -0,0 +1,34 @@
+// Package webhooks sends project webhook messages to customer endpoints.
+package webhooks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrManagementURLUnsupported is returned by dispatchers that do not provide
+// a hosted portal for managing webhook endpoints.
+var ErrManagementURLUnsupported = errors.New("webhook management url not supported by dispatcher")
+
+type Message struct {
+	// ID, if set, makes sending the message idempotent. Dispatchers deliver a
+	// message at most once per ID, so a message may safely be resent after an
+	// error.
+	ID uuid.UUID
+
+	EventType string
+	Payload   map[string]any
+}
+
+// Dispatcher delivers webhook messages on behalf of a project.
+//
+// Each project has an application, identified by the app ID stored in
+// project_webhook_settings. Messages sent to an application are delivered
+// to every endpoint registered for that application.
+type Dispatcher interface {
+	CreateApplication(ctx context.Context, projectID uuid.UUID, displayName string) (string, error)
+	SendMessage(ctx context.Context, appID string, msg Message) (string, error)
+	ManagementURL(ctx context.Context, appID string) (string, error)
+}
@@ -30,6 +30,14 @@ FROM
 WHERE
     id = $1;
 
+-- name: GetIdentity :one
+SELECT
+    *
+FROM
+    identities
+WHERE
+    id = $1;
+
 -- name: GetPasskey :one
 SELECT
     *
@@ -62,6 +70,14 @@ FROM
 WHERE
     role_id = ANY ($1::uuid[]);
 
+-- name: BatchGetRoleInheritedRolesByRoleID :many
+SELECT
+    *
+FROM
+    role_inherited_roles
+WHERE
+    role_id = ANY ($1::uuid[]);
+
 -- name: GetSAMLConnection :one
 SELECT
     *
@@ -118,3 +134,82 @@ FROM
 WHERE
     id = $1;
 
+-- name: GetAccessRequest :one
+SELECT
+    *
+FROM
+    access_requests
+WHERE
+    id = $1;
+
+-- name: GetRoleTemplate :one
+SELECT
+    *
+FROM
+    role_templates
+WHERE
+    id = $1;
+
+-- name: GetRoleTemplateActions :many
+SELECT
+    actions.name
+FROM
+    role_template_actions
+    JOIN actions ON role_template_actions.action_id = actions.id
+WHERE
+    role_template_actions.role_template_id = $1
+ORDER BY
+    actions.name;
+
+-- name: GetWebhookEndpoint :one
+SELECT
+    *
+FROM
+    webhook_endpoints
+WHERE
+    id = $1;
+
+-- name: GetAuditLogChainHead :one
+SELECT
+    *
+FROM
+    audit_log_chain_heads
+WHERE
+    project_id = $1;
+
+-- name: ListAuditLogEventsByChainSequence :many
+SELECT
+    *
+FROM
+    audit_log_events
+WHERE
+    project_id = $1
+    AND chain_sequence > @after_sequence::bigint
+ORDER BY
+    chain_sequence
+LIMIT $2;
+
+-- name: ListUnchainedAuditLogEventIDsAfter :many
+SELECT
+    id
+FROM
+    audit_log_events
+WHERE
+    project_id = $1
+    AND chain_sequence IS NULL
+    AND id > $2
+ORDER BY
+    id
+LIMIT $3;
+
+-- name: ListAuditLogArchivedChainLinks :many
+SELECT
+    *
+FROM
+    audit_log_archived_chain_links
+WHERE
+    project_id = $1
+    AND chain_sequence > @after_sequence::bigint
+ORDER BY
+    chain_sequence
+LIMIT $2;
@@ -0,0 +1,68 @@
+-- name: ClaimAuditLogExportDestinations :many
+UPDATE
+    audit_log_export_destinations
+SET
+    next_attempt_time = @lease_expire_time
+WHERE
+    id IN (
+        SELECT
+            id
+        FROM
+            audit_log_export_destinations
+        WHERE
+            NOT disabled
+            AND next_attempt_time <= now()
+        ORDER BY
+            next_attempt_time
+        LIMIT $1
+        FOR UPDATE
+            SKIP LOCKED)
+RETURNING
+    *;
+
+-- name: ListAuditLogEventsAfterCursor :many
+SELECT
+    *
+FROM
+    audit_log_events
+WHERE
+    project_id = @project_id
+    AND (sqlc.narg (organization_id)::uuid IS NULL
+        OR organization_id = sqlc.narg (organization_id)::uuid)
+    AND id > @cursor::uuid
+    AND id < @before::uuid
+ORDER BY
+    id
+LIMIT $1;
+
+-- name: UpdateAuditLogExportDestinationSuccess :exec
+UPDATE
+    audit_log_export_destinations
+SET
+    cursor_audit_log_event_id = $2,
+    next_attempt_time = $3,
+    last_attempt_time = $4,
+    last_success_time = $4,
+    last_error = NULL,
+    consecutive_failure_count = 0
+WHERE
+    id = $1;
+
+-- name: UpdateAuditLogExportDestinationFailure :exec
+UPDATE
+    audit_log_export_destinations
+SET
+    next_attempt_time = $2,
+    last_attempt_time = $3,
+    last_error = $4,
+    consecutive_failure_count = consecutive_failure_count + 1
+WHERE
+    id = $1;
+
+-- name: RescheduleAuditLogExportDestination :exec
+UPDATE
+    audit_log_export_destinations
+SET
+    next_attempt_time = $2
+WHERE
+    id = $1;
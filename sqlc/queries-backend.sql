@@ -1,6 +1,6 @@
 -- name: CreateOrganization :one
-INSERT INTO organizations (id, project_id, display_name, log_in_with_google, log_in_with_microsoft, log_in_with_github, log_in_with_email, log_in_with_password, log_in_with_saml, log_in_with_oidc, log_in_with_authenticator_app, log_in_with_passkey, scim_enabled)
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+INSERT INTO organizations (id, project_id, display_name, log_in_with_google, log_in_with_microsoft, log_in_with_github, log_in_with_email, log_in_with_password, log_in_with_saml, log_in_with_oidc, log_in_with_authenticator_app, log_in_with_passkey, scim_enabled, parent_organization_id, public_metadata, private_metadata)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 RETURNING
     *;
 
@@ -21,6 +21,15 @@ FROM
 WHERE
     id = $1;
 
+-- name: GetProjectByIDForUpdate :one
+SELECT
+    *
+FROM
+    projects
+WHERE
+    id = $1
+FOR UPDATE;
+
 -- name: GetBackendAPIKeyBySecretTokenSHA256 :one
 SELECT
     *
@@ -29,6 +38,17 @@ FROM
 WHERE
     secret_token_sha256 = $1;
 
+-- name: GetCurrentSessionSigningKeyByProjectID :one
+SELECT
+    *
+FROM
+    session_signing_keys
+WHERE
+    project_id = $1
+ORDER BY
+    create_time DESC
+LIMIT 1;
+
 -- name: GetSessionSigningKeysByProjectID :many
 SELECT
     *
@@ -43,11 +63,17 @@ SELECT
 FROM
     organizations
 WHERE
-    project_id = $1
-    AND id >= $2
+    project_id = @project_id
+    AND id >= @id
+    AND (sqlc.narg ('metadata_key')::varchar IS NULL
+        OR public_metadata ? sqlc.narg ('metadata_key')::varchar
+        OR private_metadata ? sqlc.narg ('metadata_key')::varchar)
+    AND (sqlc.narg ('metadata_value')::jsonb IS NULL
+        OR public_metadata -> sqlc.narg ('metadata_key')::varchar = sqlc.narg ('metadata_value')::jsonb
+        OR private_metadata -> sqlc.narg ('metadata_key')::varchar = sqlc.narg ('metadata_value')::jsonb)
 ORDER BY
     id
-LIMIT $3;
+LIMIT sqlc.arg ('limit');
 
 -- name: GetProjectIDOrganizationBacks :one
 SELECT
@@ -85,7 +111,12 @@ SET
     scim_enabled = $10,
     require_mfa = $11,
     custom_roles_enabled = $12,
-    api_keys_enabled = $14
+    api_keys_enabled = $14,
+    audit_log_retention_days = $16,
+    parent_organization_id = $17,
+    public_metadata = $18,
+    private_metadata = $19,
+    enforce_sso_for_domains = $20
 WHERE
     id = $1
 RETURNING
@@ -95,6 +126,65 @@ RETURNING
 DELETE FROM organizations
 WHERE id = $1;
 
+-- name: GetOrganizationHasChildren :one
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            organizations
+        WHERE
+            parent_organization_id = $1);
+
+-- name: GetOrganizationAncestorIDs :many
+WITH RECURSIVE ancestor_organizations (id) AS (
+    SELECT
+        organizations.parent_organization_id
+    FROM
+        organizations
+    WHERE
+        organizations.id = $1
+        AND organizations.parent_organization_id IS NOT NULL
+    UNION
+    SELECT
+        organizations.parent_organization_id
+    FROM
+        organizations
+        JOIN ancestor_organizations ON organizations.id = ancestor_organizations.id
+    WHERE
+        organizations.parent_organization_id IS NOT NULL
+)
+SELECT
+    ancestor_organizations.id::uuid
+FROM
+    ancestor_organizations;
+
+-- name: ListOrganizationDescendants :many
+WITH RECURSIVE descendant_organizations (id) AS (
+    SELECT
+        organizations.id
+    FROM
+        organizations
+    WHERE
+        organizations.parent_organization_id = @organization_id::uuid
+    UNION
+    SELECT
+        organizations.id
+    FROM
+        organizations
+        JOIN descendant_organizations ON organizations.parent_organization_id = descendant_organizations.id
+)
+SELECT
+    organizations.*
+FROM
+    organizations
+    JOIN descendant_organizations ON organizations.id = descendant_organizations.id
+WHERE
+    organizations.id >= @start_id::uuid
+ORDER BY
+    organizations.id
+LIMIT sqlc.arg('limit');
+
 -- name: UpdateProject :one
 UPDATE
     projects
@@ -122,7 +212,10 @@ SET
     cookie_domain = $17,
     api_keys_enabled = $21,
     api_key_secret_token_prefix = $22,
-    audit_logs_enabled = $23
+    audit_logs_enabled = $23,
+    audit_log_retention_days = $25,
+    audit_log_archive_s3_bucket = $26,
+    audit_log_archive_s3_role_arn = $27
 WHERE
     id = $1
 RETURNING
@@ -234,17 +327,6 @@ WHERE
 RETURNING
     *;
 
--- name: UpdateUserPassword :one
-UPDATE
-    users
-SET
-    update_time = now(),
-    password_bcrypt = $2
-WHERE
-    id = $1
-RETURNING
-    *;
-
 -- name: ListSAMLConnections :many
 SELECT
     *
@@ -429,8 +511,8 @@ WHERE
     AND project_id = $2;
 
 -- name: CreateBackendAPIKey :one
-INSERT INTO backend_api_keys (id, project_id, display_name, secret_token_sha256)
-    VALUES ($1, $2, $3, $4)
+INSERT INTO backend_api_keys (id, project_id, display_name, secret_token_sha256, scopes, organization_ids)
+    VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING
     *;
 
@@ -439,7 +521,9 @@ UPDATE
     backend_api_keys
 SET
     update_time = now(),
-    display_name = $1
+    display_name = $1,
+    scopes = $3,
+    organization_ids = $4
 WHERE
     id = $2
 RETURNING
@@ -460,6 +544,131 @@ WHERE
 RETURNING
     *;
 
+-- name: GetResourceOrganizationIDs :many
+SELECT
+    organizations.id AS resource_id,
+    organizations.id AS organization_id
+FROM
+    organizations
+WHERE
+    organizations.project_id = @project_id
+    AND organizations.id = ANY (@organization_ids::uuid[])
+UNION ALL
+SELECT
+    users.id,
+    users.organization_id
+FROM
+    users
+    JOIN organizations ON users.organization_id = organizations.id
+WHERE
+    organizations.project_id = @project_id
+    AND users.id = ANY (@user_ids::uuid[])
+UNION ALL
+SELECT
+    sessions.id,
+    users.organization_id
+FROM
+    sessions
+    JOIN users ON sessions.user_id = users.id
+    JOIN organizations ON users.organization_id = organizations.id
+WHERE
+    organizations.project_id = @project_id
+    AND sessions.id = ANY (@session_ids::uuid[])
+UNION ALL
+SELECT
+    passkeys.id,
+    users.organization_id
+FROM
+    passkeys
+    JOIN users ON passkeys.user_id = users.id
+    JOIN organizations ON users.organization_id = organizations.id
+WHERE
+    organizations.project_id = @project_id
+    AND passkeys.id = ANY (@passkey_ids::uuid[])
+UNION ALL
+SELECT
+    user_invites.id,
+    user_invites.organization_id
+FROM
+    user_invites
+    JOIN organizations ON user_invites.organization_id = organizations.id
+WHERE
+    organizations.project_id = @project_id
+    AND user_invites.id = ANY (@user_invite_ids::uuid[])
+UNION ALL
+SELECT
+    user_role_assignments.id,
+    users.organization_id
+FROM
+    user_role_assignments
+    JOIN users ON user_role_assignments.user_id = users.id
+    JOIN organizations ON users.organization_id = organizations.id
+WHERE
+    organizations.project_id = @project_id
+    AND user_role_assignments.id = ANY (@user_role_assignment_ids::uuid[])
+UNION ALL
+SELECT
+    access_requests.id,
+    users.organization_id
+FROM
+    access_requests
+    JOIN users ON access_requests.user_id = users.id
+    JOIN organizations ON users.organization_id = organizations.id
+WHERE
+    organizations.project_id = @project_id
+    AND access_requests.id = ANY (@access_request_ids::uuid[])
+UNION ALL
+SELECT
+    saml_connections.id,
+    saml_connections.organization_id
+FROM
+    saml_connections
+    JOIN organizations ON saml_connections.organization_id = organizations.id
+WHERE
+    organizations.project_id = @project_id
+    AND saml_connections.id = ANY (@saml_connection_ids::uuid[])
+UNION ALL
+SELECT
+    oidc_connections.id,
+    oidc_connections.organization_id
+FROM
+    oidc_connections
+    JOIN organizations ON oidc_connections.organization_id = organizations.id
+WHERE
+    organizations.project_id = @project_id
+    AND oidc_connections.id = ANY (@oidc_connection_ids::uuid[])
+UNION ALL
+SELECT
+    scim_api_keys.id,
+    scim_api_keys.organization_id
+FROM
+    scim_api_keys
+    JOIN organizations ON scim_api_keys.organization_id = organizations.id
+WHERE
+    organizations.project_id = @project_id
+    AND scim_api_keys.id = ANY (@scim_api_key_ids::uuid[])
+UNION ALL
+SELECT
+    api_keys.id,
+    api_keys.organization_id
+FROM
+    api_keys
+    JOIN organizations ON api_keys.organization_id = organizations.id
+WHERE
+    organizations.project_id = @project_id
+    AND api_keys.id = ANY (@api_key_ids::uuid[])
+UNION ALL
+SELECT
+    api_key_role_assignments.id,
+    api_keys.organization_id
+FROM
+    api_key_role_assignments
+    JOIN api_keys ON api_key_role_assignments.api_key_id = api_keys.id
+    JOIN organizations ON api_keys.organization_id = organizations.id
+WHERE
+    organizations.project_id = @project_id
+    AND api_key_role_assignments.id = ANY (@api_key_role_assignment_ids::uuid[]);
+
 -- name: ListPublishableKeys :many
 SELECT
     *
@@ -509,11 +718,17 @@ SELECT
 FROM
     users
 WHERE
-    organization_id = $1
-    AND id >= $2
+    organization_id = @organization_id
+    AND id >= @id
+    AND (sqlc.narg ('metadata_key')::varchar IS NULL
+        OR public_metadata ? sqlc.narg ('metadata_key')::varchar
+        OR private_metadata ? sqlc.narg ('metadata_key')::varchar)
+    AND (sqlc.narg ('metadata_value')::jsonb IS NULL
+        OR public_metadata -> sqlc.narg ('metadata_key')::varchar = sqlc.narg ('metadata_value')::jsonb
+        OR private_metadata -> sqlc.narg ('metadata_key')::varchar = sqlc.narg ('metadata_value')::jsonb)
 ORDER BY
     id
-LIMIT $3;
+LIMIT sqlc.arg ('limit');
 
 -- name: GetUser :one
 SELECT
@@ -526,11 +741,59 @@ WHERE
     AND organizations.project_id = $2;
 
 -- name: CreateUser :one
-INSERT INTO users (id, organization_id, google_user_id, microsoft_user_id, github_user_id, email, is_owner)
-    VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO users (id, organization_id, identity_id, google_user_id, microsoft_user_id, github_user_id, email, is_owner, public_metadata, private_metadata, display_name, profile_picture_url)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING
+    *;
+
+-- name: CreateIdentity :one
+INSERT INTO identities (id, project_id, email)
+    VALUES ($1, $2, $3)
+RETURNING
+    *;
+
+-- name: UpdateIdentityPassword :one
+UPDATE
+    identities
+SET
+    update_time = now(),
+    password_bcrypt = $2,
+    imported_password_hash = $3
+WHERE
+    id = $1
 RETURNING
     *;
 
+-- name: GetIdentityByID :one
+SELECT
+    *
+FROM
+    identities
+WHERE
+    id = $1;
+
+-- name: DeleteIdentityIfUnused :exec
+DELETE FROM identities
+WHERE identities.id = $1
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            users
+        WHERE
+            users.identity_id = identities.id);
+
+-- name: DeleteUnusedProjectIdentities :exec
+DELETE FROM identities
+WHERE project_id = $1
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            users
+        WHERE
+            identity_id = identities.id);
+
 -- name: UpdateUser :one
 UPDATE
     users
@@ -542,7 +805,10 @@ SET
     github_user_id = $8,
     is_owner = $5,
     display_name = $6,
-    profile_picture_url = $7
+    profile_picture_url = $7,
+    identity_id = $9,
+    public_metadata = $10,
+    private_metadata = $11
 WHERE
     id = $1
 RETURNING
@@ -692,18 +958,53 @@ FROM
     JOIN organizations ON organization_domains.organization_id = organizations.id
 WHERE
     public.organization_domains.organization_id = $1
-    AND organizations.project_id = $2;
+    AND organizations.project_id = $2
+ORDER BY
+    organization_domains.domain;
 
--- name: DeleteOrganizationDomains :exec
+-- name: DeleteOrganizationDomainsNotIn :exec
 DELETE FROM organization_domains
-WHERE organization_id = $1;
+WHERE organization_id = @organization_id
+    AND NOT (DOMAIN = ANY (@domains::varchar[]));
 
--- name: CreateOrganizationDomain :one
+-- name: CreateOrganizationDomain :exec
 INSERT INTO organization_domains (id, organization_id, DOMAIN)
     VALUES ($1, $2, $3)
+ON CONFLICT (organization_id, DOMAIN)
+    DO NOTHING;
+
+-- name: UpdateOrganizationDomainVerification :one
+UPDATE
+    organization_domains
+SET
+    verified = $2,
+    verify_time = $3,
+    last_check_time = now()
+WHERE
+    id = $1
 RETURNING
     *;
 
+-- name: ListOrganizationDomainsToCheck :many
+SELECT
+    organization_domains.*,
+    organizations.project_id
+FROM
+    organization_domains
+    JOIN organizations ON organization_domains.organization_id = organizations.id
+WHERE
+    organization_domains.last_check_time IS NULL
+    OR (organization_domains.verified
+        AND organization_domains.last_check_time < @verified_check_before)
+    OR (NOT organization_domains.verified
+        AND organization_domains.create_time > @pending_create_after
+        AND organization_domains.last_check_time < @pending_check_before)
+ORDER BY
+    organization_domains.last_check_time NULLS FIRST
+LIMIT sqlc.arg ('limit')
+FOR UPDATE
+    OF organization_domains SKIP LOCKED;
+
 -- name: GetOrganizationGoogleHostedDomains :many
 SELECT
     organization_google_hosted_domains.*
@@ -750,7 +1051,7 @@ SELECT
 FROM
     passkeys
 WHERE
-    user_id = $1
+    identity_id = $1
     AND id >= $2
 ORDER BY
     id
@@ -761,11 +1062,10 @@ SELECT
     passkeys.*
 FROM
     passkeys
-    JOIN users ON passkeys.user_id = users.id
-    JOIN organizations ON users.organization_id = organizations.id
+    JOIN identities ON passkeys.identity_id = identities.id
 WHERE
     passkeys.id = $1
-    AND organizations.project_id = $2;
+    AND identities.project_id = $2;
 
 -- name: UpdatePasskey :one
 UPDATE
@@ -868,14 +1168,12 @@ SET
     disabled = TRUE,
     update_time = now()
 FROM
-    users,
-    organizations,
+    identities,
     projects
 WHERE
     passkeys.rp_id != projects.vault_domain
-    AND passkeys.user_id = users.id
-    AND users.organization_id = organizations.id
-    AND organizations.project_id = projects.id
+    AND passkeys.identity_id = identities.id
+    AND identities.project_id = projects.id
     AND projects.id = $1;
 
 -- name: UpdateProjectEmailSendFromDomain :one
@@ -949,6 +1247,18 @@ ORDER BY
     id
 LIMIT $4;
 
+-- name: GetProjectRoles :many
+SELECT
+    *
+FROM
+    roles
+WHERE
+    project_id = $1
+    AND organization_id IS NULL
+    AND role_template_id IS NULL
+ORDER BY
+    id;
+
 -- name: BatchGetRoleActionsByRoleID :many
 SELECT
     *
@@ -967,8 +1277,8 @@ WHERE
     AND project_id = $2;
 
 -- name: CreateRole :one
-INSERT INTO roles (id, project_id, organization_id, display_name, description)
-    VALUES ($1, $2, $3, $4, $5)
+INSERT INTO roles (id, project_id, organization_id, display_name, description, requestable)
+    VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING
     *;
 
@@ -978,7 +1288,8 @@ UPDATE
 SET
     update_time = now(),
     display_name = $2,
-    description = $3
+    description = $3,
+    requestable = $4
 WHERE
     id = $1
 RETURNING
@@ -999,6 +1310,151 @@ WHERE role_id = $1
 DELETE FROM roles
 WHERE id = $1;
 
+-- name: ListRoleTemplates :many
+SELECT
+    *
+FROM
+    role_templates
+WHERE
+    project_id = $1
+    AND id >= $2
+ORDER BY
+    id
+LIMIT $3;
+
+-- name: GetRoleTemplate :one
+SELECT
+    *
+FROM
+    role_templates
+WHERE
+    id = $1
+    AND project_id = $2;
+
+-- name: CreateRoleTemplate :one
+INSERT INTO role_templates (id, project_id, display_name, description)
+    VALUES ($1, $2, $3, $4)
+RETURNING
+    *;
+
+-- name: UpdateRoleTemplate :one
+UPDATE
+    role_templates
+SET
+    update_time = now(),
+    display_name = $2,
+    description = $3,
+    version = version + 1
+WHERE
+    id = $1
+RETURNING
+    *;
+
+-- name: DeleteRoleTemplate :exec
+DELETE FROM role_templates
+WHERE id = $1;
+
+-- name: BatchGetRoleTemplateActionsByRoleTemplateID :many
+SELECT
+    *
+FROM
+    role_template_actions
+WHERE
+    role_template_id = ANY ($1::uuid[]);
+
+-- name: UpsertRoleTemplateAction :exec
+INSERT INTO role_template_actions (id, role_template_id, action_id)
+    VALUES ($1, $2, $3)
+ON CONFLICT (role_template_id, action_id)
+    DO NOTHING;
+
+-- name: DeleteRoleTemplateActionsByActionIDNotInList :exec
+DELETE FROM role_template_actions
+WHERE role_template_id = $1
+    AND NOT (action_id = ANY (@action_ids::uuid[]));
+
+-- name: UpsertRoleTemplateRoles :exec
+INSERT INTO roles (id, project_id, organization_id, display_name, description, role_template_id, role_template_version)
+SELECT
+    gen_random_uuid(),
+    role_templates.project_id,
+    organizations.id,
+    role_templates.display_name,
+    role_templates.description,
+    role_templates.id,
+    role_templates.version
+FROM
+    role_templates
+    JOIN organizations ON role_templates.project_id = organizations.project_id
+WHERE
+    role_templates.id = $1
+ON CONFLICT (role_template_id,
+    organization_id)
+WHERE
+    role_template_id IS NOT NULL
+        DO UPDATE SET
+            update_time = now(),
+            display_name = excluded.display_name,
+            description = excluded.description,
+            role_template_version = excluded.role_template_version
+        WHERE
+            roles.role_template_version IS DISTINCT FROM excluded.role_template_version;
+
+-- name: UpsertRoleTemplateRoleActions :exec
+INSERT INTO role_actions (id, role_id, action_id)
+SELECT
+    gen_random_uuid(),
+    roles.id,
+    role_template_actions.action_id
+FROM
+    roles
+    JOIN role_template_actions ON roles.role_template_id = role_template_actions.role_template_id
+WHERE
+    roles.role_template_id = @role_template_id::uuid
+ON CONFLICT (role_id,
+    action_id)
+    DO NOTHING;
+
+-- name: DeleteRoleTemplateRoleActionsNotInTemplate :exec
+DELETE FROM role_actions USING roles
+WHERE role_actions.role_id = roles.id
+    AND roles.role_template_id = @role_template_id::uuid
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            role_template_actions
+        WHERE
+            role_template_actions.role_template_id = @role_template_id::uuid
+            AND role_template_actions.action_id = role_actions.action_id);
+
+-- name: ListRoleTemplatesMissingRoles :many
+SELECT
+    role_templates.id
+FROM
+    role_templates
+WHERE
+    EXISTS (
+        SELECT
+            1
+        FROM
+            organizations
+        WHERE
+            organizations.project_id = role_templates.project_id
+            AND NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    roles
+                WHERE
+                    roles.role_template_id = role_templates.id
+                    AND roles.organization_id = organizations.id))
+ORDER BY
+    role_templates.id
+LIMIT $1
+FOR UPDATE
+    SKIP LOCKED;
+
 -- name: ListUserRoleAssignmentsByRole :many
 SELECT
     *
@@ -1034,10 +1490,12 @@ WHERE
     AND roles.project_id = $2;
 
 -- name: UpsertUserRoleAssignment :exec
-INSERT INTO user_role_assignments (id, role_id, user_id)
-    VALUES ($1, $2, $3)
-ON CONFLICT (role_id, user_id)
-    DO NOTHING;
+INSERT INTO user_role_assignments (id, role_id, user_id, resource_type, resource_id, expire_time, inherit_to_descendants)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (role_id, user_id, resource_type, resource_id)
+    DO UPDATE SET
+        expire_time = excluded.expire_time,
+        inherit_to_descendants = excluded.inherit_to_descendants;
 
 -- name: GetUserRoleAssignmentByUserAndRole :one
 SELECT
@@ -1046,10 +1504,269 @@ FROM
     user_role_assignments
 WHERE
     user_id = $1
-    AND role_id = $2;
+    AND role_id = $2
+    AND resource_type IS NOT DISTINCT FROM $3
+    AND resource_id IS NOT DISTINCT FROM $4;
 
--- name: DeleteUserRoleAssignment :exec
-DELETE FROM user_role_assignments
+-- name: BatchGetRoleInheritedRolesByRoleID :many
+SELECT
+    *
+FROM
+    role_inherited_roles
+WHERE
+    role_id = ANY ($1::uuid[]);
+
+-- name: UpsertRoleInheritedRole :exec
+INSERT INTO role_inherited_roles (role_id, inherited_role_id)
+    VALUES ($1, $2)
+ON CONFLICT (role_id, inherited_role_id)
+    DO NOTHING;
+
+-- name: DeleteRoleInheritedRolesNotInList :exec
+DELETE FROM role_inherited_roles
+WHERE role_id = $1
+    AND NOT (inherited_role_id = ANY (@inherited_role_ids::uuid[]));
+
+-- name: ListTransitivelyInheritedRoleIDs :many
+WITH RECURSIVE inherited_roles (role_id) AS (
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+    WHERE
+        role_inherited_roles.role_id = ANY (@role_ids::uuid[])
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN inherited_roles ON role_inherited_roles.role_id = inherited_roles.role_id
+)
+SELECT
+    role_id
+FROM
+    inherited_roles;
+
+-- name: CheckUserPermission :one
+WITH RECURSIVE ancestor_organizations (id) AS (
+    SELECT
+        organizations.parent_organization_id
+    FROM
+        users
+        JOIN organizations ON users.organization_id = organizations.id
+    WHERE
+        users.id = @user_id
+        AND organizations.parent_organization_id IS NOT NULL
+    UNION
+    SELECT
+        organizations.parent_organization_id
+    FROM
+        organizations
+        JOIN ancestor_organizations ON organizations.id = ancestor_organizations.id
+    WHERE
+        organizations.parent_organization_id IS NOT NULL
+),
+ancestor_users (id) AS (
+    SELECT
+        ancestor_users.id
+    FROM
+        users
+        JOIN users AS ancestor_users ON users.identity_id = ancestor_users.identity_id
+        JOIN ancestor_organizations ON ancestor_users.organization_id = ancestor_organizations.id
+    WHERE
+        users.id = @user_id
+),
+user_roles (role_id) AS (
+    SELECT
+        user_role_assignments.role_id
+    FROM
+        user_role_assignments
+    WHERE (user_role_assignments.user_id = @user_id
+        OR (user_role_assignments.inherit_to_descendants
+            AND user_role_assignments.user_id IN (
+                SELECT
+                    ancestor_users.id
+                FROM
+                    ancestor_users)))
+        AND (user_role_assignments.resource_type IS NULL
+            OR (user_role_assignments.resource_type = @resource_type::varchar
+                AND user_role_assignments.resource_id = @resource_id::varchar))
+        AND (user_role_assignments.expire_time IS NULL
+            OR user_role_assignments.expire_time > now())
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN user_roles ON role_inherited_roles.role_id = user_roles.role_id
+)
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            user_roles
+            JOIN role_actions ON user_roles.role_id = role_actions.role_id
+            JOIN actions ON role_actions.action_id = actions.id
+        WHERE
+            actions.name = @action::varchar);
+
+-- name: ExplainUserAction :many
+WITH RECURSIVE ancestor_organizations (id) AS (
+    SELECT
+        organizations.parent_organization_id
+    FROM
+        users
+        JOIN organizations ON users.organization_id = organizations.id
+    WHERE
+        users.id = @user_id
+        AND organizations.parent_organization_id IS NOT NULL
+    UNION
+    SELECT
+        organizations.parent_organization_id
+    FROM
+        organizations
+        JOIN ancestor_organizations ON organizations.id = ancestor_organizations.id
+    WHERE
+        organizations.parent_organization_id IS NOT NULL
+),
+ancestor_users (id) AS (
+    SELECT
+        ancestor_users.id
+    FROM
+        users
+        JOIN users AS ancestor_users ON users.identity_id = ancestor_users.identity_id
+        JOIN ancestor_organizations ON ancestor_users.organization_id = ancestor_organizations.id
+    WHERE
+        users.id = @user_id
+),
+granted_roles (user_role_assignment_id, resource_type, resource_id, role_id, role_ids) AS (
+    SELECT
+        user_role_assignments.id,
+        user_role_assignments.resource_type,
+        user_role_assignments.resource_id,
+        user_role_assignments.role_id,
+        ARRAY[user_role_assignments.role_id]
+    FROM
+        user_role_assignments
+    WHERE (user_role_assignments.user_id = @user_id
+        OR (user_role_assignments.inherit_to_descendants
+            AND user_role_assignments.user_id IN (
+                SELECT
+                    ancestor_users.id
+                FROM
+                    ancestor_users)))
+        AND (user_role_assignments.resource_type IS NULL
+            OR (user_role_assignments.resource_type = @resource_type::varchar
+                AND user_role_assignments.resource_id = @resource_id::varchar))
+        AND (user_role_assignments.expire_time IS NULL
+            OR user_role_assignments.expire_time > now())
+    UNION ALL
+    SELECT
+        granted_roles.user_role_assignment_id,
+        granted_roles.resource_type,
+        granted_roles.resource_id,
+        role_inherited_roles.inherited_role_id,
+        granted_roles.role_ids || role_inherited_roles.inherited_role_id
+    FROM
+        granted_roles
+        JOIN role_inherited_roles ON granted_roles.role_id = role_inherited_roles.role_id
+    WHERE
+        NOT role_inherited_roles.inherited_role_id = ANY (granted_roles.role_ids)
+)
+SELECT
+    granted_roles.user_role_assignment_id,
+    granted_roles.resource_type,
+    granted_roles.resource_id,
+    granted_roles.role_ids::uuid[] AS role_ids
+FROM
+    granted_roles
+    JOIN role_actions ON granted_roles.role_id = role_actions.role_id
+    JOIN actions ON role_actions.action_id = actions.id
+WHERE
+    actions.name = @action::varchar
+ORDER BY
+    granted_roles.user_role_assignment_id,
+    cardinality(granted_roles.role_ids);
+
+-- name: ListUserPermittedResourceIDs :many
+WITH RECURSIVE ancestor_organizations (id) AS (
+    SELECT
+        organizations.parent_organization_id
+    FROM
+        users
+        JOIN organizations ON users.organization_id = organizations.id
+    WHERE
+        users.id = @user_id
+        AND organizations.parent_organization_id IS NOT NULL
+    UNION
+    SELECT
+        organizations.parent_organization_id
+    FROM
+        organizations
+        JOIN ancestor_organizations ON organizations.id = ancestor_organizations.id
+    WHERE
+        organizations.parent_organization_id IS NOT NULL
+),
+ancestor_users (id) AS (
+    SELECT
+        ancestor_users.id
+    FROM
+        users
+        JOIN users AS ancestor_users ON users.identity_id = ancestor_users.identity_id
+        JOIN ancestor_organizations ON ancestor_users.organization_id = ancestor_organizations.id
+    WHERE
+        users.id = @user_id
+),
+assigned_roles (role_id, included_role_id) AS (
+    SELECT
+        user_role_assignments.role_id,
+        user_role_assignments.role_id
+    FROM
+        user_role_assignments
+    WHERE (user_role_assignments.user_id = @user_id
+        OR (user_role_assignments.inherit_to_descendants
+            AND user_role_assignments.user_id IN (
+                SELECT
+                    ancestor_users.id
+                FROM
+                    ancestor_users)))
+        AND user_role_assignments.resource_type = @resource_type::varchar
+        AND (user_role_assignments.expire_time IS NULL
+            OR user_role_assignments.expire_time > now())
+    UNION
+    SELECT
+        assigned_roles.role_id,
+        role_inherited_roles.inherited_role_id
+    FROM
+        assigned_roles
+        JOIN role_inherited_roles ON assigned_roles.included_role_id = role_inherited_roles.role_id
+)
+SELECT DISTINCT
+    user_role_assignments.resource_id::varchar
+FROM
+    user_role_assignments
+    JOIN assigned_roles ON user_role_assignments.role_id = assigned_roles.role_id
+    JOIN role_actions ON assigned_roles.included_role_id = role_actions.role_id
+    JOIN actions ON role_actions.action_id = actions.id
+WHERE (user_role_assignments.user_id = @user_id
+    OR (user_role_assignments.inherit_to_descendants
+        AND user_role_assignments.user_id IN (
+            SELECT
+                ancestor_users.id
+            FROM
+                ancestor_users)))
+    AND user_role_assignments.resource_type = @resource_type::varchar
+    AND (user_role_assignments.expire_time IS NULL
+        OR user_role_assignments.expire_time > now())
+    AND user_role_assignments.resource_id > @start_resource_id::varchar
+    AND actions.name = @action::varchar
+ORDER BY
+    user_role_assignments.resource_id::varchar
+LIMIT sqlc.arg('limit');
+
+-- name: DeleteUserRoleAssignment :exec
+DELETE FROM user_role_assignments
 WHERE id = $1;
 
 -- name: GetProjectWebhookSettings :one
@@ -1061,8 +1778,8 @@ WHERE
     project_id = $1;
 
 -- name: CreateAPIKey :one
-INSERT INTO api_keys (id, organization_id, display_name, secret_token_sha256, secret_token_suffix, expire_time)
-    VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO api_keys (id, organization_id, display_name, secret_token_sha256, secret_token_suffix, expire_time, ip_allowlist, request_quota, request_quota_window_seconds)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING
     *;
 
@@ -1078,7 +1795,10 @@ UPDATE
     api_keys
 SET
     update_time = now(),
-    display_name = $2
+    display_name = $2,
+    ip_allowlist = $4,
+    request_quota = $5,
+    request_quota_window_seconds = $6
 FROM
     organizations AS organization
 WHERE
@@ -1100,16 +1820,46 @@ WHERE
 -- name: GetAPIKeyDetailsBySecretTokenSHA256 :one
 SELECT
     api_keys.id,
-    api_keys.organization_id
+    api_keys.organization_id,
+    api_keys.ip_allowlist,
+    api_keys.request_quota,
+    api_keys.request_quota_window_seconds
 FROM
     api_keys
     JOIN organizations AS organization ON api_keys.organization_id = organization.id
-WHERE
-    api_keys.secret_token_sha256 = $1
+WHERE (api_keys.secret_token_sha256 = $1
+    OR (api_keys.previous_secret_token_sha256 = $1
+        AND api_keys.previous_secret_token_expire_time > now()))
     AND organization.project_id = $2
     AND (api_keys.expire_time > now()
         OR api_keys.expire_time IS NULL);
 
+-- name: DeleteAPIKeyRequestQuotaUsageBefore :exec
+DELETE FROM api_key_request_quota_usage
+WHERE api_key_id = $1
+    AND window_start < $2;
+
+-- name: IncrementAPIKeyRequestQuotaUsage :one
+INSERT INTO api_key_request_quota_usage (api_key_id, window_start, request_count)
+    VALUES ($1, $2, 1)
+ON CONFLICT (api_key_id, window_start)
+    DO UPDATE SET
+        request_count = api_key_request_quota_usage.request_count + 1
+    RETURNING
+        *;
+
+-- name: UpdateAPIKeyLastUsed :exec
+UPDATE
+    api_keys
+SET
+    last_used_time = now(),
+    last_used_ip_address = $2
+WHERE
+    id = $1
+    AND (last_used_time IS NULL
+        OR last_used_time < $3
+        OR last_used_ip_address IS DISTINCT FROM $2);
+
 -- name: ListAPIKeys :many
 SELECT
     api_keys.*
@@ -1130,30 +1880,169 @@ UPDATE
 SET
     update_time = now(),
     secret_token_sha256 = NULL,
-    secret_token_suffix = NULL
+    secret_token_suffix = NULL,
+    previous_secret_token_sha256 = NULL,
+    previous_secret_token_suffix = NULL,
+    previous_secret_token_expire_time = NULL
 FROM
     organizations AS organization
 WHERE
     api_keys.id = $1
     AND organization.project_id = $2;
 
+-- name: RotateAPIKey :one
+UPDATE
+    api_keys
+SET
+    update_time = now(),
+    previous_secret_token_sha256 = api_keys.secret_token_sha256,
+    previous_secret_token_suffix = api_keys.secret_token_suffix,
+    previous_secret_token_expire_time = $3,
+    secret_token_sha256 = $4,
+    secret_token_suffix = $5
+FROM
+    organizations AS organization
+WHERE
+    api_keys.organization_id = organization.id
+    AND api_keys.id = $1
+    AND organization.project_id = $2
+RETURNING
+    api_keys.*;
+
+-- name: ListAPIKeysWithExpiredPreviousSecretToken :many
+SELECT
+    api_keys.id,
+    api_keys.organization_id,
+    organization.project_id
+FROM
+    api_keys
+    JOIN organizations AS organization ON api_keys.organization_id = organization.id
+WHERE
+    api_keys.previous_secret_token_expire_time <= now()
+ORDER BY
+    api_keys.previous_secret_token_expire_time
+LIMIT $1
+FOR UPDATE
+    OF api_keys SKIP LOCKED;
+
+-- name: ClearAPIKeyPreviousSecretToken :one
+UPDATE
+    api_keys
+SET
+    update_time = now(),
+    previous_secret_token_sha256 = NULL,
+    previous_secret_token_suffix = NULL,
+    previous_secret_token_expire_time = NULL
+WHERE
+    id = $1
+RETURNING
+    *;
+
+-- name: ListExpiredUserRoleAssignments :many
+SELECT
+    user_role_assignments.id,
+    user_role_assignments.user_id,
+    users.organization_id,
+    organizations.project_id
+FROM
+    user_role_assignments
+    JOIN users ON user_role_assignments.user_id = users.id
+    JOIN organizations ON users.organization_id = organizations.id
+WHERE
+    user_role_assignments.expire_time <= now()
+ORDER BY
+    user_role_assignments.expire_time
+LIMIT $1
+FOR UPDATE
+    OF user_role_assignments SKIP LOCKED;
+
+-- name: ListExpiredAPIKeyRoleAssignments :many
+SELECT
+    api_key_role_assignments.id,
+    api_key_role_assignments.api_key_id,
+    api_keys.organization_id,
+    organizations.project_id
+FROM
+    api_key_role_assignments
+    JOIN api_keys ON api_key_role_assignments.api_key_id = api_keys.id
+    JOIN organizations ON api_keys.organization_id = organizations.id
+WHERE
+    api_key_role_assignments.expire_time <= now()
+ORDER BY
+    api_key_role_assignments.expire_time
+LIMIT $1
+FOR UPDATE
+    OF api_key_role_assignments SKIP LOCKED;
+
+-- name: DeleteExpiredAPIKeyRoleAssignment :exec
+DELETE FROM api_key_role_assignments
+WHERE id = $1;
+
 -- name: CreateAPIKeyRoleAssignment :one
-INSERT INTO api_key_role_assignments (id, api_key_id, role_id)
-    VALUES ($1, $2, $3)
+INSERT INTO api_key_role_assignments (id, api_key_id, role_id, expire_time)
+    VALUES ($1, $2, $3, $4)
 RETURNING
     *;
 
 -- name: GetAPIKeyActions :many
+WITH RECURSIVE api_key_roles (role_id) AS (
+    SELECT
+        api_key_role_assignments.role_id
+    FROM
+        api_key_role_assignments
+    WHERE
+        api_key_role_assignments.api_key_id = $1
+        AND (api_key_role_assignments.expire_time IS NULL
+            OR api_key_role_assignments.expire_time > now())
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN api_key_roles ON role_inherited_roles.role_id = api_key_roles.role_id
+)
 SELECT DISTINCT
     (actions.name)
 FROM
-    api_keys
-    JOIN api_key_role_assignments ON api_keys.id = api_key_role_assignments.api_key_id
-    JOIN roles ON api_key_role_assignments.role_id = roles.id
-    JOIN role_actions ON roles.id = role_actions.role_id
+    api_key_roles
+    JOIN role_actions ON api_key_roles.role_id = role_actions.role_id
+    JOIN actions ON role_actions.action_id = actions.id;
+
+-- name: ExplainAPIKeyAction :many
+WITH RECURSIVE granted_roles (api_key_role_assignment_id, role_id, role_ids) AS (
+    SELECT
+        api_key_role_assignments.id,
+        api_key_role_assignments.role_id,
+        ARRAY[api_key_role_assignments.role_id]
+    FROM
+        api_key_role_assignments
+    WHERE
+        api_key_role_assignments.api_key_id = @api_key_id
+        AND (api_key_role_assignments.expire_time IS NULL
+            OR api_key_role_assignments.expire_time > now())
+    UNION ALL
+    SELECT
+        granted_roles.api_key_role_assignment_id,
+        role_inherited_roles.inherited_role_id,
+        granted_roles.role_ids || role_inherited_roles.inherited_role_id
+    FROM
+        granted_roles
+        JOIN role_inherited_roles ON granted_roles.role_id = role_inherited_roles.role_id
+    WHERE
+        NOT role_inherited_roles.inherited_role_id = ANY (granted_roles.role_ids)
+)
+SELECT
+    granted_roles.api_key_role_assignment_id,
+    granted_roles.role_ids::uuid[] AS role_ids
+FROM
+    granted_roles
+    JOIN role_actions ON granted_roles.role_id = role_actions.role_id
     JOIN actions ON role_actions.action_id = actions.id
 WHERE
-    api_keys.id = $1;
+    actions.name = @action::varchar
+ORDER BY
+    granted_roles.api_key_role_assignment_id,
+    cardinality(granted_roles.role_ids);
 
 -- name: GetAPIKeyRoleAssignment :one
 SELECT
@@ -1199,12 +2088,68 @@ WHERE api_key_role_assignments.api_key_id = api_keys.id
     AND api_key_role_assignments.id = $1
     AND organizations.project_id = $2;
 
+-- name: GetAccessRequest :one
+SELECT
+    access_requests.*
+FROM
+    access_requests
+    JOIN users ON access_requests.user_id = users.id
+    JOIN organizations ON users.organization_id = organizations.id
+WHERE
+    access_requests.id = $1
+    AND organizations.project_id = $2;
+
+-- name: ListAccessRequestsByOrganization :many
+SELECT
+    access_requests.*
+FROM
+    access_requests
+    JOIN users ON access_requests.user_id = users.id
+WHERE
+    users.organization_id = $1
+    AND access_requests.id >= $2
+ORDER BY
+    access_requests.id
+LIMIT $3;
+
+-- name: ListAccessRequestsByUser :many
+SELECT
+    *
+FROM
+    access_requests
+WHERE
+    user_id = $1
+    AND id >= $2
+ORDER BY
+    id
+LIMIT $3;
+
 -- name: CreateAuditLogEvent :one
-INSERT INTO audit_log_events (id, project_id, organization_id, actor_user_id, actor_session_id, actor_api_key_id, actor_console_user_id, actor_console_session_id, actor_backend_api_key_id, actor_intermediate_session_id, actor_scim_api_key_id, resource_type, resource_id, event_name, event_time, event_details)
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, coalesce(@event_details, '{}'::jsonb))
+INSERT INTO audit_log_events (id, project_id, organization_id, actor_user_id, actor_session_id, actor_api_key_id, actor_console_user_id, actor_console_session_id, actor_backend_api_key_id, actor_intermediate_session_id, actor_scim_api_key_id, resource_type, resource_id, event_name, event_time, event_details, chain_sequence, chain_hash)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, coalesce(@event_details, '{}'::jsonb), $16, $17)
 RETURNING
     *;
 
+-- name: LockAuditLogChainHead :one
+INSERT INTO audit_log_chain_heads (project_id)
+    VALUES ($1)
+ON CONFLICT (project_id)
+    DO UPDATE SET
+        project_id = excluded.project_id
+    RETURNING
+        *;
+
+-- name: UpdateAuditLogChainHead :exec
+UPDATE
+    audit_log_chain_heads
+SET
+    update_time = now(),
+    sequence = $2,
+    hash = $3,
+    key_ciphertext = $4
+WHERE
+    project_id = $1;
+
 -- name: DeriveAuditLogEventContextForSessionID :one
 SELECT
     sessions.id AS session_id,
@@ -1277,6 +2222,41 @@ ORDER BY
     id DESC
 LIMIT $1;
 
+-- name: ConsoleListAuditLogEventsWithEventDetailsFilter :many
+SELECT
+    *
+FROM
+    audit_log_events
+WHERE
+    project_id = @project_id
+    AND (organization_id = @organization_id
+        OR @organization_id IS NULL)
+    AND (event_time >= @start_time
+        OR @start_time IS NULL)
+    AND (event_time <= @end_time
+        OR @end_time IS NULL)
+    AND (event_name = sqlc.narg ('event_name')
+        OR sqlc.narg ('event_name') IS NULL)
+    AND (actor_user_id = @actor_user_id
+        OR @actor_user_id IS NULL)
+    AND (actor_session_id = @actor_session_id
+        OR @actor_session_id IS NULL)
+    AND (actor_api_key_id = @actor_api_key_id
+        OR @actor_api_key_id IS NULL)
+    AND (actor_backend_api_key_id = @actor_backend_api_key_id
+        OR @actor_backend_api_key_id IS NULL)
+    AND (actor_scim_api_key_id = @actor_scim_api_key_id
+        OR @actor_scim_api_key_id IS NULL)
+    AND (resource_type = @resource_type
+        OR @resource_type IS NULL)
+    AND (resource_id = @resource_id
+        OR @resource_id IS NULL)
+    AND event_details @@ sqlc.arg ('event_details_filter')::varchar::jsonpath
+    AND id <= @id
+ORDER BY
+    id DESC
+LIMIT $1;
+
 -- name: ConsoleListAuditLogEventNames :many
 SELECT DISTINCT
     event_name
@@ -1301,3 +2281,334 @@ WHERE
 ORDER BY
     event_name;
 
+-- name: ListWebhookEndpoints :many
+SELECT
+    *
+FROM
+    webhook_endpoints
+WHERE
+    project_id = $1
+    AND id >= $2
+ORDER BY
+    id
+LIMIT $3;
+
+-- name: GetWebhookEndpoint :one
+SELECT
+    *
+FROM
+    webhook_endpoints
+WHERE
+    id = $1
+    AND project_id = $2;
+
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (id, project_id, url, description, event_types, disabled, signing_secret_ciphertext)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING
+    *;
+
+-- name: UpdateWebhookEndpoint :one
+UPDATE
+    webhook_endpoints
+SET
+    update_time = now(),
+    url = $2,
+    description = $3,
+    event_types = $4,
+    disabled = $5
+WHERE
+    id = $1
+RETURNING
+    *;
+
+-- name: DeleteWebhookEndpoint :exec
+DELETE FROM webhook_endpoints
+WHERE id = $1;
+
+-- name: ListWebhookDeliveries :many
+SELECT
+    webhook_deliveries.*,
+    webhook_messages.event_type
+FROM
+    webhook_deliveries
+    JOIN webhook_messages ON webhook_deliveries.webhook_message_id = webhook_messages.id
+WHERE
+    webhook_deliveries.webhook_endpoint_id = $1
+    AND webhook_deliveries.id <= $2
+ORDER BY
+    webhook_deliveries.id DESC
+LIMIT $3;
+
+-- name: CreateWebhookOutboxMessage :one
+INSERT INTO webhook_outbox_messages (id, project_id, event_type, payload)
+    VALUES ($1, $2, $3, $4)
+RETURNING
+    *;
+
+
+-- name: ListEmailTemplates :many
+SELECT
+    *
+FROM
+    email_templates
+WHERE
+    project_id = $1
+    AND id >= $2
+ORDER BY
+    id
+LIMIT $3;
+
+-- name: ListEmailTemplatesByType :many
+SELECT
+    *
+FROM
+    email_templates
+WHERE
+    project_id = $1
+    AND template_type = $2;
+
+-- name: GetEmailTemplate :one
+SELECT
+    *
+FROM
+    email_templates
+WHERE
+    id = $1
+    AND project_id = $2;
+
+-- name: CreateEmailTemplate :one
+INSERT INTO email_templates (id, project_id, template_type, locale, subject, text_body, html_body)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING
+    *;
+
+-- name: UpdateEmailTemplate :one
+UPDATE
+    email_templates
+SET
+    update_time = now(),
+    locale = $2,
+    subject = $3,
+    text_body = $4,
+    html_body = $5
+WHERE
+    id = $1
+RETURNING
+    *;
+
+-- name: DeleteEmailTemplate :exec
+DELETE FROM email_templates
+WHERE id = $1;
+
+-- name: ListAuditLogExportDestinations :many
+SELECT
+    *
+FROM
+    audit_log_export_destinations
+WHERE
+    project_id = $1
+    AND id >= $2
+ORDER BY
+    id
+LIMIT $3;
+
+-- name: ListAuditLogExportDestinationsByOrganization :many
+SELECT
+    *
+FROM
+    audit_log_export_destinations
+WHERE
+    project_id = $1
+    AND organization_id = $2
+    AND id >= $3
+ORDER BY
+    id
+LIMIT $4;
+
+-- name: GetAuditLogExportDestination :one
+SELECT
+    *
+FROM
+    audit_log_export_destinations
+WHERE
+    id = $1
+    AND project_id = $2;
+
+-- name: CreateAuditLogExportDestination :one
+INSERT INTO audit_log_export_destinations (id, project_id, organization_id, display_name, type, disabled, https_url, signing_secret_ciphertext, s3_bucket, s3_prefix, s3_role_arn, syslog_network, syslog_address)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING
+    *;
+
+-- name: UpdateAuditLogExportDestination :one
+UPDATE
+    audit_log_export_destinations
+SET
+    update_time = now(),
+    display_name = $2,
+    disabled = $3,
+    https_url = $4,
+    s3_bucket = $5,
+    s3_prefix = $6,
+    s3_role_arn = $9,
+    syslog_network = $7,
+    syslog_address = $8,
+    next_attempt_time = now()
+WHERE
+    id = $1
+RETURNING
+    *;
+
+-- name: DeleteAuditLogExportDestination :exec
+DELETE FROM audit_log_export_destinations
+WHERE id = $1;
+
+-- name: ListAuditLogRetentionProjects :many
+SELECT
+    *
+FROM
+    projects
+WHERE
+    audit_log_retention_days IS NOT NULL
+    OR entitled_audit_log_retention_days IS NOT NULL
+    OR EXISTS (
+        SELECT
+            1
+        FROM
+            organizations
+        WHERE
+            organizations.project_id = projects.id
+            AND organizations.audit_log_retention_days IS NOT NULL);
+
+-- name: ListExpiredAuditLogEvents :many
+SELECT
+    audit_log_events.*
+FROM
+    audit_log_events
+    JOIN projects ON audit_log_events.project_id = projects.id
+    LEFT JOIN organizations ON audit_log_events.organization_id = organizations.id
+WHERE
+    audit_log_events.project_id = $1
+    AND audit_log_events.restored_from_audit_log_archive_id IS NULL
+    AND audit_log_events.event_time < now() - make_interval(days => least (coalesce(organizations.audit_log_retention_days, projects.audit_log_retention_days), projects.entitled_audit_log_retention_days))
+ORDER BY
+    audit_log_events.event_time
+LIMIT $2
+FOR UPDATE
+    OF audit_log_events SKIP LOCKED;
+
+-- name: CreateAuditLogArchive :one
+INSERT INTO audit_log_archives (id, project_id, organization_id, s3_bucket, s3_key, s3_role_arn, event_count, first_event_time, last_event_time)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING
+    *;
+
+-- name: CreateAuditLogArchivedChainLinks :exec
+INSERT INTO audit_log_archived_chain_links (project_id, chain_sequence, chain_hash, audit_log_archive_id)
+SELECT
+    @project_id,
+    unnest(@chain_sequences::bigint[]),
+    unnest(@chain_hashes::bytea[]),
+    @audit_log_archive_id;
+
+-- name: DeleteAuditLogEvents :exec
+DELETE FROM audit_log_events
+WHERE id = ANY (@ids::uuid[]);
+
+-- name: ListAuditLogArchives :many
+SELECT
+    *
+FROM
+    audit_log_archives
+WHERE
+    project_id = $1
+    AND id <= $2
+ORDER BY
+    id DESC
+LIMIT $3;
+
+-- name: ListAuditLogArchivesByOrganization :many
+SELECT
+    *
+FROM
+    audit_log_archives
+WHERE
+    project_id = $1
+    AND organization_id = $2
+    AND id <= $3
+ORDER BY
+    id DESC
+LIMIT $4;
+
+-- name: GetAuditLogArchiveForUpdate :one
+SELECT
+    *
+FROM
+    audit_log_archives
+WHERE
+    id = $1
+    AND project_id = $2
+FOR UPDATE;
+
+-- name: RestoreAuditLogEvent :exec
+INSERT INTO audit_log_events (id, project_id, organization_id, actor_user_id, actor_session_id, actor_api_key_id, actor_console_user_id, actor_console_session_id, actor_backend_api_key_id, actor_intermediate_session_id, actor_scim_api_key_id, resource_type, resource_id, event_name, event_time, event_details, chain_sequence, chain_hash, restored_from_audit_log_archive_id)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19);
+
+-- name: DeleteAuditLogArchivedChainLinks :exec
+DELETE FROM audit_log_archived_chain_links
+WHERE audit_log_archive_id = $1;
+
+-- name: UpdateAuditLogArchiveRestoreTime :one
+UPDATE
+    audit_log_archives
+SET
+    restore_time = now()
+WHERE
+    id = $1
+RETURNING
+    *;
+
+-- name: ListAuditLogArchivedChainLinksByArchive :many
+SELECT
+    *
+FROM
+    audit_log_archived_chain_links
+WHERE
+    audit_log_archive_id = $1;
+
+-- name: CreateOrganizationRoleTemplateRoles :exec
+INSERT INTO roles (id, project_id, organization_id, display_name, description, role_template_id, role_template_version)
+SELECT
+    gen_random_uuid(),
+    role_templates.project_id,
+    organizations.id,
+    role_templates.display_name,
+    role_templates.description,
+    role_templates.id,
+    role_templates.version
+FROM
+    role_templates
+    JOIN organizations ON role_templates.project_id = organizations.project_id
+WHERE
+    organizations.id = $1
+ON CONFLICT (role_template_id,
+    organization_id)
+WHERE
+    role_template_id IS NOT NULL
+        DO NOTHING;
+
+-- name: CreateOrganizationRoleTemplateRoleActions :exec
+INSERT INTO role_actions (id, role_id, action_id)
+SELECT
+    gen_random_uuid(),
+    roles.id,
+    role_template_actions.action_id
+FROM
+    roles
+    JOIN role_template_actions ON roles.role_template_id = role_template_actions.role_template_id
+WHERE
+    roles.organization_id = @organization_id::uuid
+ON CONFLICT (role_id,
+    action_id)
+    DO NOTHING;
@@ -22,8 +22,10 @@ SELECT
     users.email AS user_email,
     users.display_name AS user_display_name,
     users.profile_picture_url AS user_profile_picture_url,
+    users.public_metadata AS user_public_metadata,
     organizations.id AS organization_id,
     organizations.display_name AS organization_display_name,
+    organizations.public_metadata AS organization_public_metadata,
     sessions.impersonator_user_id
 FROM
     relayed_sessions
@@ -42,8 +44,10 @@ SELECT
     users.email AS user_email,
     users.display_name AS user_display_name,
     users.profile_picture_url AS user_profile_picture_url,
+    users.public_metadata AS user_public_metadata,
     organizations.id AS organization_id,
     organizations.display_name AS organization_display_name,
+    organizations.public_metadata AS organization_public_metadata,
     sessions.impersonator_user_id
 FROM
     sessions
@@ -71,16 +75,90 @@ WHERE
     project_id = $1;
 
 -- name: GetUserActions :many
+WITH RECURSIVE ancestor_organizations (id) AS (
+    SELECT
+        organizations.parent_organization_id
+    FROM
+        users
+        JOIN organizations ON users.organization_id = organizations.id
+    WHERE
+        users.id = $1
+        AND organizations.parent_organization_id IS NOT NULL
+    UNION
+    SELECT
+        organizations.parent_organization_id
+    FROM
+        organizations
+        JOIN ancestor_organizations ON organizations.id = ancestor_organizations.id
+    WHERE
+        organizations.parent_organization_id IS NOT NULL
+),
+ancestor_users (id) AS (
+    SELECT
+        ancestor_users.id
+    FROM
+        users
+        JOIN users AS ancestor_users ON users.identity_id = ancestor_users.identity_id
+        JOIN ancestor_organizations ON ancestor_users.organization_id = ancestor_organizations.id
+    WHERE
+        users.id = $1
+),
+user_roles (role_id) AS (
+    SELECT
+        user_role_assignments.role_id
+    FROM
+        user_role_assignments
+    WHERE (user_role_assignments.user_id = $1
+        OR (user_role_assignments.inherit_to_descendants
+            AND user_role_assignments.user_id IN (
+                SELECT
+                    ancestor_users.id
+                FROM
+                    ancestor_users)))
+        AND user_role_assignments.resource_type IS NULL
+        AND (user_role_assignments.expire_time IS NULL
+            OR user_role_assignments.expire_time > now())
+    UNION
+    SELECT
+        role_inherited_roles.inherited_role_id
+    FROM
+        role_inherited_roles
+        JOIN user_roles ON role_inherited_roles.role_id = user_roles.role_id
+)
 SELECT DISTINCT
     (actions.name)
 FROM
-    users
-    JOIN user_role_assignments ON users.id = user_role_assignments.user_id
-    JOIN roles ON user_role_assignments.role_id = roles.id
-    JOIN role_actions ON roles.id = role_actions.role_id
-    JOIN actions ON role_actions.action_id = actions.id
-WHERE
-    user_id = $1;
+    user_roles
+    JOIN role_actions ON user_roles.role_id = role_actions.role_id
+    JOIN actions ON role_actions.action_id = actions.id;
+
+-- name: GetOrganizationPath :one
+WITH RECURSIVE organization_path (id, organization_ids) AS (
+    SELECT
+        organizations.id,
+        ARRAY[organizations.id]
+    FROM
+        organizations
+    WHERE
+        organizations.id = $1
+    UNION ALL
+    SELECT
+        organizations.parent_organization_id,
+        organization_path.organization_ids || organizations.parent_organization_id
+    FROM
+        organization_path
+        JOIN organizations ON organization_path.id = organizations.id
+    WHERE
+        organizations.parent_organization_id IS NOT NULL
+        AND NOT organizations.parent_organization_id = ANY (organization_path.organization_ids)
+)
+SELECT
+    organization_path.organization_ids::uuid[] AS organization_ids
+FROM
+    organization_path
+ORDER BY
+    cardinality(organization_path.organization_ids) DESC
+LIMIT 1;
 
 -- name: GetCurrentSessionSigningKeyByProjectID :one
 SELECT
@@ -8,11 +8,30 @@ WHERE
     AND id = $2;
 
 -- name: CreateUser :one
-INSERT INTO users (id, organization_id, email, password_bcrypt, google_user_id, microsoft_user_id, github_user_id)
+INSERT INTO users (id, organization_id, identity_id, email, google_user_id, microsoft_user_id, github_user_id)
     VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING
     *;
 
+-- name: GetIdentityByID :one
+SELECT
+    *
+FROM
+    identities
+WHERE
+    id = $1;
+
+-- name: DeleteIdentityIfUnused :exec
+DELETE FROM identities
+WHERE identities.id = $1
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            users
+        WHERE
+            users.identity_id = identities.id);
+
 -- name: GetCurrentSessionKeyByProjectID :one
 SELECT
     *
@@ -60,6 +79,59 @@ FROM
 WHERE
     id = $1;
 
+-- name: IsOrganizationDescendant :one
+WITH RECURSIVE ancestor_organizations (id) AS (
+    SELECT
+        organizations.parent_organization_id
+    FROM
+        organizations
+    WHERE
+        organizations.id = @organization_id::uuid
+        AND organizations.parent_organization_id IS NOT NULL
+    UNION
+    SELECT
+        organizations.parent_organization_id
+    FROM
+        organizations
+        JOIN ancestor_organizations ON organizations.id = ancestor_organizations.id
+    WHERE
+        organizations.parent_organization_id IS NOT NULL
+)
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            ancestor_organizations
+        WHERE
+            ancestor_organizations.id = @ancestor_organization_id::uuid);
+
+-- name: ListOrganizationDescendants :many
+WITH RECURSIVE descendant_organizations (id) AS (
+    SELECT
+        organizations.id
+    FROM
+        organizations
+    WHERE
+        organizations.parent_organization_id = @organization_id::uuid
+    UNION
+    SELECT
+        organizations.id
+    FROM
+        organizations
+        JOIN descendant_organizations ON organizations.parent_organization_id = descendant_organizations.id
+)
+SELECT
+    organizations.*
+FROM
+    organizations
+    JOIN descendant_organizations ON organizations.id = descendant_organizations.id
+WHERE
+    organizations.id >= @start_id::uuid
+ORDER BY
+    organizations.id
+LIMIT sqlc.arg('limit');
+
 -- name: GetProjectTrustedDomains :many
 SELECT
     *
@@ -266,10 +338,11 @@ RETURNING
 
 -- name: SetPassword :one
 UPDATE
-    users
+    identities
 SET
     update_time = now(),
-    password_bcrypt = $2
+    password_bcrypt = $2,
+    imported_password_hash = NULL
 WHERE
     id = $1
 RETURNING
@@ -338,26 +411,26 @@ SELECT
 FROM
     passkeys
 WHERE
-    user_id = $1
+    identity_id = $1
     AND id >= $2
 ORDER BY
     id
 LIMIT $3;
 
 -- name: CreatePasskey :one
-INSERT INTO passkeys (id, user_id, credential_id, public_key, aaguid, rp_id)
-    VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO passkeys (id, identity_id, user_id, credential_id, public_key, aaguid, rp_id)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING
     *;
 
--- name: GetUserPasskey :one
+-- name: GetIdentityPasskey :one
 SELECT
     *
 FROM
     passkeys
 WHERE
     id = $1
-    AND user_id = $2;
+    AND identity_id = $2;
 
 -- name: DeletePasskey :exec
 DELETE FROM passkeys
@@ -384,9 +457,9 @@ WHERE
 DELETE FROM user_authenticator_app_challenges
 WHERE user_id = $1;
 
--- name: UpdateUserAuthenticatorApp :one
+-- name: UpdateIdentityAuthenticatorApp :one
 UPDATE
-    users
+    identities
 SET
     authenticator_app_secret_ciphertext = $1,
     authenticator_app_recovery_code_sha256s = $2
@@ -492,7 +565,7 @@ WHERE
             users
         WHERE
             organization_id = organizations.id
-            AND users.email = $2);
+            AND users.identity_id = $2);
 
 -- name: GetProjectByBackingOrganizationID :one
 SELECT
@@ -632,10 +705,17 @@ WHERE
         OR roles.organization_id = $3);
 
 -- name: UpsertUserRoleAssignment :exec
-INSERT INTO user_role_assignments (id, role_id, user_id)
-    VALUES ($1, $2, $3)
-ON CONFLICT (role_id, user_id)
-    DO NOTHING;
+INSERT INTO user_role_assignments (id, role_id, user_id, expire_time, inherit_to_descendants)
+    VALUES ($1, $2, $3, $4, coalesce(sqlc.narg ('inherit_to_descendants')::boolean, FALSE))
+ON CONFLICT (role_id, user_id, resource_type, resource_id)
+    DO UPDATE SET
+        expire_time = CASE WHEN user_role_assignments.expire_time IS NULL
+            OR excluded.expire_time IS NULL THEN
+            NULL
+        ELSE
+            greatest (user_role_assignments.expire_time, excluded.expire_time)
+        END,
+        inherit_to_descendants = coalesce(sqlc.narg ('inherit_to_descendants')::boolean, user_role_assignments.inherit_to_descendants);
 
 -- name: GetUserRoleAssignmentByUserAndRole :one
 SELECT
@@ -644,12 +724,68 @@ FROM
     user_role_assignments
 WHERE
     user_id = $1
-    AND role_id = $2;
+    AND role_id = $2
+    AND resource_type IS NULL;
 
 -- name: DeleteUserRoleAssignment :exec
 DELETE FROM user_role_assignments
 WHERE id = $1;
 
+-- name: CreateAccessRequest :one
+INSERT INTO access_requests (id, user_id, role_id, reason, duration_seconds)
+    VALUES ($1, $2, $3, $4, $5)
+RETURNING
+    *;
+
+-- name: GetAccessRequest :one
+SELECT
+    access_requests.*
+FROM
+    access_requests
+    JOIN users ON access_requests.user_id = users.id
+WHERE
+    access_requests.id = $1
+    AND users.organization_id = $2;
+
+-- name: ListAccessRequests :many
+SELECT
+    access_requests.*
+FROM
+    access_requests
+    JOIN users ON access_requests.user_id = users.id
+WHERE
+    users.organization_id = $1
+    AND access_requests.id >= $2
+ORDER BY
+    access_requests.id
+LIMIT $3;
+
+-- name: ListAccessRequestsByUser :many
+SELECT
+    *
+FROM
+    access_requests
+WHERE
+    user_id = $1
+    AND id >= $2
+ORDER BY
+    id
+LIMIT $3;
+
+-- name: UpdateAccessRequestStatus :one
+UPDATE
+    access_requests
+SET
+    update_time = now(),
+    status = $2,
+    reviewer_user_id = $3,
+    review_time = now()
+WHERE
+    id = $1
+    AND status = 'pending'
+RETURNING
+    *;
+
 -- name: GetProjectWebhookSettings :one
 SELECT
     *
@@ -709,7 +845,26 @@ UPDATE
 SET
     update_time = now(),
     secret_token_sha256 = NULL,
-    secret_token_suffix = NULL
+    secret_token_suffix = NULL,
+    previous_secret_token_sha256 = NULL,
+    previous_secret_token_suffix = NULL,
+    previous_secret_token_expire_time = NULL
+WHERE
+    id = $1
+    AND organization_id = $2
+RETURNING
+    *;
+
+-- name: RotateAPIKey :one
+UPDATE
+    api_keys
+SET
+    update_time = now(),
+    previous_secret_token_sha256 = secret_token_sha256,
+    previous_secret_token_suffix = secret_token_suffix,
+    previous_secret_token_expire_time = $3,
+    secret_token_sha256 = $4,
+    secret_token_suffix = $5
 WHERE
     id = $1
     AND organization_id = $2
@@ -717,8 +872,8 @@ RETURNING
     *;
 
 -- name: CreateAPIKeyRoleAssignment :one
-INSERT INTO api_key_role_assignments (id, api_key_id, role_id)
-    VALUES ($1, $2, $3)
+INSERT INTO api_key_role_assignments (id, api_key_id, role_id, expire_time)
+    VALUES ($1, $2, $3, $4)
 RETURNING
     *;
 
@@ -775,11 +930,31 @@ WHERE api_key_role_assignments.api_key_id = api_keys.id
     AND api_keys.organization_id = $2;
 
 -- name: CreateAuditLogEvent :one
-INSERT INTO audit_log_events (id, project_id, organization_id, actor_user_id, actor_session_id, resource_type, resource_id, event_name, event_time, event_details)
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, coalesce(@event_details, '{}'::jsonb))
+INSERT INTO audit_log_events (id, project_id, organization_id, actor_user_id, actor_session_id, resource_type, resource_id, event_name, event_time, event_details, chain_sequence, chain_hash)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, coalesce(@event_details, '{}'::jsonb), $10, $11)
 RETURNING
     *;
 
+-- name: LockAuditLogChainHead :one
+INSERT INTO audit_log_chain_heads (project_id)
+    VALUES ($1)
+ON CONFLICT (project_id)
+    DO UPDATE SET
+        project_id = excluded.project_id
+    RETURNING
+        *;
+
+-- name: UpdateAuditLogChainHead :exec
+UPDATE
+    audit_log_chain_heads
+SET
+    update_time = now(),
+    sequence = $2,
+    hash = $3,
+    key_ciphertext = $4
+WHERE
+    project_id = $1;
+
 -- name: ListAuditLogEvents :many
 SELECT
     *
@@ -822,3 +997,18 @@ LIMIT $1;
 -- ORDER BY
 --     event_time DESC
 -- LIMIT $1;
+-- name: CreateWebhookOutboxMessage :one
+INSERT INTO webhook_outbox_messages (id, project_id, event_type, payload)
+    VALUES ($1, $2, $3, $4)
+RETURNING
+    *;
+
+
+-- name: ListEmailTemplatesByType :many
+SELECT
+    *
+FROM
+    email_templates
+WHERE
+    project_id = $1
+    AND template_type = $2;
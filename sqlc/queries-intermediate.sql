@@ -70,11 +70,35 @@ RETURNING
     *;
 
 -- name: CreateUser :one
-INSERT INTO users (id, organization_id, email, display_name, profile_picture_url, google_user_id, microsoft_user_id, github_user_id, is_owner, password_bcrypt)
+INSERT INTO users (id, organization_id, identity_id, email, display_name, profile_picture_url, google_user_id, microsoft_user_id, github_user_id, is_owner)
     VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 RETURNING
     *;
 
+-- name: CreateIdentity :one
+INSERT INTO identities (id, project_id, email, email_verified)
+    VALUES ($1, $2, $3, $4)
+RETURNING
+    *;
+
+-- name: GetIdentityByID :one
+SELECT
+    *
+FROM
+    identities
+WHERE
+    id = $1;
+
+-- name: GetIdentityByEmail :one
+SELECT
+    *
+FROM
+    identities
+WHERE
+    project_id = $1
+    AND email = $2
+    AND email_verified;
+
 -- name: GetIntermediateSessionByID :one
 SELECT
     *
@@ -229,7 +253,33 @@ FROM
 WHERE
     organizations.project_id = $1
     AND organizations.log_in_with_saml = TRUE
-    AND organization_domains.domain = $2;
+    AND organization_domains.domain = $2
+    AND organization_domains.verified;
+
+-- name: ListHomeRealmOrganizations :many
+SELECT
+    organizations.*
+FROM
+    organizations
+    JOIN organization_domains ON organizations.id = organization_domains.organization_id
+WHERE
+    organizations.project_id = $1
+    AND (organizations.log_in_with_saml = TRUE
+        OR organizations.log_in_with_oidc = TRUE)
+    AND organization_domains.domain = $2
+    AND organization_domains.verified;
+
+-- name: GetOrganizationHasVerifiedDomain :one
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            organization_domains
+        WHERE
+            organization_id = $1
+            AND DOMAIN = $2
+            AND verified);
 
 -- name: ListOIDCOrganizations :many
 SELECT
@@ -240,7 +290,8 @@ FROM
 WHERE
     organizations.project_id = $1
     AND organizations.log_in_with_oidc = TRUE
-    AND organization_domains.domain = $2;
+    AND organization_domains.domain = $2
+    AND organization_domains.verified;
 
 -- name: RevokeIntermediateSession :one
 UPDATE
@@ -332,9 +383,11 @@ SELECT
 FROM
     users
     JOIN organizations ON users.organization_id = organizations.id
+    JOIN identities ON users.identity_id = identities.id
 WHERE
     users.email = $1
-    AND users.password_bcrypt IS NOT NULL
+    AND (identities.password_bcrypt IS NOT NULL
+        OR identities.imported_password_hash IS NOT NULL)
     AND organizations.project_id = $2
     AND organizations.log_in_with_password = TRUE
     AND NOT organizations.logins_disabled;
@@ -437,9 +490,9 @@ INSERT INTO organization_microsoft_tenant_ids (id, organization_id, microsoft_te
 RETURNING
     *;
 
--- name: UpdateUserFailedPasswordAttempts :one
+-- name: UpdateIdentityFailedPasswordAttempts :one
 UPDATE
-    users
+    identities
 SET
     failed_password_attempts = $1
 WHERE
@@ -447,9 +500,9 @@ WHERE
 RETURNING
     *;
 
--- name: UpdateUserPasswordLockoutExpireTime :one
+-- name: UpdateIdentityPasswordLockoutExpireTime :one
 UPDATE
-    users
+    identities
 SET
     password_lockout_expire_time = $1
 WHERE
@@ -457,11 +510,12 @@ WHERE
 RETURNING
     *;
 
--- name: UpdateUserPasswordBcrypt :one
+-- name: UpdateIdentityPasswordBcrypt :one
 UPDATE
-    users
+    identities
 SET
-    password_bcrypt = $1
+    password_bcrypt = $1,
+    imported_password_hash = NULL
 WHERE
     id = $2
 RETURNING
@@ -495,7 +549,7 @@ WHERE
 RETURNING
     *;
 
--- name: GetUserHasActivePasskey :one
+-- name: GetIdentityHasActivePasskey :one
 SELECT
     EXISTS (
         SELECT
@@ -503,7 +557,7 @@ SELECT
         FROM
             passkeys
         WHERE
-            user_id = $1
+            identity_id = $1
             AND disabled = FALSE);
 
 -- name: UpdateIntermediateSessionRegisterPasskey :one
@@ -522,8 +576,8 @@ RETURNING
     *;
 
 -- name: CreatePasskey :one
-INSERT INTO passkeys (id, user_id, credential_id, public_key, aaguid, rp_id)
-    VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO passkeys (id, identity_id, user_id, credential_id, public_key, aaguid, rp_id)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING
     *;
 
@@ -538,6 +592,17 @@ WHERE
 RETURNING
     *;
 
+-- name: UpdateIntermediateSessionVerifiedIdentityID :one
+UPDATE
+    intermediate_sessions
+SET
+    verified_identity_id = $1,
+    update_time = now()
+WHERE
+    id = $2
+RETURNING
+    *;
+
 -- name: UpdateIntermediateSessionPasskeyVerified :one
 UPDATE
     intermediate_sessions
@@ -550,13 +615,13 @@ WHERE
 RETURNING
     *;
 
--- name: GetUserPasskeyCredentialIDs :many
+-- name: GetIdentityPasskeyCredentialIDs :many
 SELECT
     credential_id
 FROM
     passkeys
 WHERE
-    user_id = $1;
+    identity_id = $1;
 
 -- name: GetPasskeyByCredentialID :one
 SELECT
@@ -565,7 +630,7 @@ FROM
     passkeys
 WHERE
     credential_id = $1
-    AND user_id = $2;
+    AND identity_id = $2;
 
 -- name: UpdateIntermediateSessionAuthenticatorAppSecretCiphertext :one
 UPDATE
@@ -600,9 +665,9 @@ WHERE
 RETURNING
     *;
 
--- name: UpdateUserAuthenticatorApp :one
+-- name: UpdateIdentityAuthenticatorApp :one
 UPDATE
-    users
+    identities
 SET
     authenticator_app_secret_ciphertext = $1,
     authenticator_app_recovery_code_sha256s = $2
@@ -611,9 +676,9 @@ WHERE
 RETURNING
     *;
 
--- name: UpdateUserAuthenticatorAppRecoveryCodeSHA256s :one
+-- name: UpdateIdentityAuthenticatorAppRecoveryCodeSHA256s :one
 UPDATE
-    users
+    identities
 SET
     authenticator_app_recovery_code_sha256s = $1
 WHERE
@@ -621,9 +686,9 @@ WHERE
 RETURNING
     *;
 
--- name: UpdateUserFailedAuthenticatorAppAttempts :one
+-- name: UpdateIdentityFailedAuthenticatorAppAttempts :one
 UPDATE
-    users
+    identities
 SET
     failed_authenticator_app_attempts = $1
 WHERE
@@ -631,9 +696,9 @@ WHERE
 RETURNING
     *;
 
--- name: UpdateUserAuthenticatorAppLockoutExpireTime :one
+-- name: UpdateIdentityAuthenticatorAppLockoutExpireTime :one
 UPDATE
-    users
+    identities
 SET
     authenticator_app_lockout_expire_time = $1
 WHERE
@@ -781,19 +846,38 @@ SET
     google_user_id = coalesce(sqlc.narg (google_user_id), google_user_id),
     microsoft_user_id = coalesce(sqlc.narg (microsoft_user_id), microsoft_user_id),
     display_name = coalesce(sqlc.narg (display_name), display_name),
-    profile_picture_url = coalesce(sqlc.narg (profile_picture_url), profile_picture_url),
-    password_bcrypt = coalesce(sqlc.narg (password_bcrypt), password_bcrypt)
+    profile_picture_url = coalesce(sqlc.narg (profile_picture_url), profile_picture_url)
 WHERE
     id = $1
 RETURNING
     *;
 
 -- name: CreateAuditLogEvent :one
-INSERT INTO audit_log_events (id, project_id, organization_id, actor_user_id, actor_session_id, resource_type, resource_id, event_name, event_time, event_details, actor_intermediate_session_id)
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, coalesce(@event_details, '{}'::jsonb), $10)
+INSERT INTO audit_log_events (id, project_id, organization_id, actor_user_id, actor_session_id, resource_type, resource_id, event_name, event_time, event_details, chain_sequence, chain_hash, actor_intermediate_session_id)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, coalesce(@event_details, '{}'::jsonb), $10, $11, $12)
 RETURNING
     *;
 
+-- name: LockAuditLogChainHead :one
+INSERT INTO audit_log_chain_heads (project_id)
+    VALUES ($1)
+ON CONFLICT (project_id)
+    DO UPDATE SET
+        project_id = excluded.project_id
+    RETURNING
+        *;
+
+-- name: UpdateAuditLogChainHead :exec
+UPDATE
+    audit_log_chain_heads
+SET
+    update_time = now(),
+    sequence = $2,
+    hash = $3,
+    key_ciphertext = $4
+WHERE
+    project_id = $1;
+
 -- name: GetUserByID :one
 SELECT
     *
@@ -802,3 +886,54 @@ FROM
 WHERE
     id = $1;
 
+-- name: CreateWebhookOutboxMessage :one
+INSERT INTO webhook_outbox_messages (id, project_id, event_type, payload)
+    VALUES ($1, $2, $3, $4)
+RETURNING
+    *;
+
+
+-- name: ListEmailTemplatesByType :many
+SELECT
+    *
+FROM
+    email_templates
+WHERE
+    project_id = $1
+    AND template_type = $2;
+
+-- name: CreateOrganizationRoleTemplateRoles :exec
+INSERT INTO roles (id, project_id, organization_id, display_name, description, role_template_id, role_template_version)
+SELECT
+    gen_random_uuid(),
+    role_templates.project_id,
+    organizations.id,
+    role_templates.display_name,
+    role_templates.description,
+    role_templates.id,
+    role_templates.version
+FROM
+    role_templates
+    JOIN organizations ON role_templates.project_id = organizations.project_id
+WHERE
+    organizations.id = $1
+ON CONFLICT (role_template_id,
+    organization_id)
+WHERE
+    role_template_id IS NOT NULL
+        DO NOTHING;
+
+-- name: CreateOrganizationRoleTemplateRoleActions :exec
+INSERT INTO role_actions (id, role_id, action_id)
+SELECT
+    gen_random_uuid(),
+    roles.id,
+    role_template_actions.action_id
+FROM
+    roles
+    JOIN role_template_actions ON roles.role_template_id = role_template_actions.role_template_id
+WHERE
+    roles.organization_id = @organization_id::uuid
+ON CONFLICT (role_id,
+    action_id)
+    DO NOTHING;
@@ -23,7 +23,8 @@ SELECT
 FROM
     organization_domains
 WHERE
-    organization_id = $1;
+    organization_id = $1
+    AND verified;
 
 -- name: GetIntermediateSessionByTokenSHA256AndProjectID :one
 SELECT
@@ -52,8 +53,42 @@ WHERE
     id = $1;
 
 -- name: CreateAuditLogEvent :one
-INSERT INTO audit_log_events (id, project_id, organization_id, actor_user_id, actor_session_id, resource_type, resource_id, event_name, event_time, event_details)
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, coalesce(@event_details, '{}'::jsonb))
+INSERT INTO audit_log_events (id, project_id, organization_id, actor_user_id, actor_session_id, resource_type, resource_id, event_name, event_time, event_details, chain_sequence, chain_hash)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, coalesce(@event_details, '{}'::jsonb), $10, $11)
+RETURNING
+    *;
+
+-- name: LockAuditLogChainHead :one
+INSERT INTO audit_log_chain_heads (project_id)
+    VALUES ($1)
+ON CONFLICT (project_id)
+    DO UPDATE SET
+        project_id = excluded.project_id
+    RETURNING
+        *;
+
+-- name: UpdateAuditLogChainHead :exec
+UPDATE
+    audit_log_chain_heads
+SET
+    update_time = now(),
+    sequence = $2,
+    hash = $3,
+    key_ciphertext = $4
+WHERE
+    project_id = $1;
+
+-- name: GetProjectWebhookSettings :one
+SELECT
+    *
+FROM
+    project_webhook_settings
+WHERE
+    project_id = $1;
+
+-- name: CreateWebhookOutboxMessage :one
+INSERT INTO webhook_outbox_messages (id, project_id, event_type, payload)
+    VALUES ($1, $2, $3, $4)
 RETURNING
     *;
 
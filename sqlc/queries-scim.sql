@@ -14,7 +14,8 @@ SELECT
 FROM
     organization_domains
 WHERE
-    organization_id = $1;
+    organization_id = $1
+    AND verified;
 
 -- name: CountUsers :one
 SELECT
@@ -54,8 +55,8 @@ WHERE
     AND email = $2;
 
 -- name: CreateUser :one
-INSERT INTO users (id, organization_id, email, is_owner)
-    VALUES ($1, $2, $3, $4)
+INSERT INTO users (id, organization_id, identity_id, email, is_owner)
+    VALUES ($1, $2, $3, $4, $5)
 RETURNING
     *;
 
@@ -63,13 +64,31 @@ RETURNING
 UPDATE
     users
 SET
-    email = $1
+    email = $1,
+    identity_id = $4
 WHERE
     id = $2
     AND organization_id = $3
 RETURNING
     *;
 
+-- name: CreateIdentity :one
+INSERT INTO identities (id, project_id, email)
+    VALUES ($1, $2, $3)
+RETURNING
+    *;
+
+-- name: DeleteIdentityIfUnused :exec
+DELETE FROM identities
+WHERE identities.id = $1
+    AND NOT EXISTS (
+        SELECT
+            1
+        FROM
+            users
+        WHERE
+            users.identity_id = identities.id);
+
 -- name: DeleteUser :one
 DELETE FROM users
 WHERE id = $1
@@ -78,8 +97,42 @@ RETURNING
     *;
 
 -- name: CreateAuditLogEvent :one
-INSERT INTO audit_log_events (id, project_id, organization_id, actor_scim_api_key_id, resource_type, resource_id, event_name, event_time, event_details)
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, coalesce(@event_details, '{}'::jsonb))
+INSERT INTO audit_log_events (id, project_id, organization_id, actor_scim_api_key_id, resource_type, resource_id, event_name, event_time, event_details, chain_sequence, chain_hash)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, coalesce(@event_details, '{}'::jsonb), $9, $10)
+RETURNING
+    *;
+
+-- name: LockAuditLogChainHead :one
+INSERT INTO audit_log_chain_heads (project_id)
+    VALUES ($1)
+ON CONFLICT (project_id)
+    DO UPDATE SET
+        project_id = excluded.project_id
+    RETURNING
+        *;
+
+-- name: UpdateAuditLogChainHead :exec
+UPDATE
+    audit_log_chain_heads
+SET
+    update_time = now(),
+    sequence = $2,
+    hash = $3,
+    key_ciphertext = $4
+WHERE
+    project_id = $1;
+
+-- name: GetProjectWebhookSettings :one
+SELECT
+    *
+FROM
+    project_webhook_settings
+WHERE
+    project_id = $1;
+
+-- name: CreateWebhookOutboxMessage :one
+INSERT INTO webhook_outbox_messages (id, project_id, event_type, payload)
+    VALUES ($1, $2, $3, $4)
 RETURNING
     *;
 
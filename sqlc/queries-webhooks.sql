@@ -0,0 +1,128 @@
+-- name: ListWebhookEndpointsForEventType :many
+SELECT
+    *
+FROM
+    webhook_endpoints
+WHERE
+    project_id = $1
+    AND NOT disabled
+    AND (cardinality(event_types) = 0
+        OR @event_type::varchar = ANY (event_types));
+
+-- name: CreateWebhookMessage :one
+INSERT INTO webhook_messages (id, project_id, event_type, payload)
+    VALUES ($1, $2, $3, $4)
+ON CONFLICT (id)
+    DO NOTHING
+RETURNING
+    *;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (id, webhook_message_id, webhook_endpoint_id, next_attempt_time)
+    VALUES ($1, $2, $3, $4)
+RETURNING
+    *;
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE
+    webhook_deliveries
+SET
+    next_attempt_time = @lease_expire_time
+WHERE
+    id IN (
+        SELECT
+            id
+        FROM
+            webhook_deliveries
+        WHERE
+            status = 'pending'
+            AND next_attempt_time <= now()
+        ORDER BY
+            next_attempt_time
+        LIMIT $1
+        FOR UPDATE
+            SKIP LOCKED)
+RETURNING
+    *;
+
+-- name: GetWebhookMessage :one
+SELECT
+    *
+FROM
+    webhook_messages
+WHERE
+    id = $1;
+
+-- name: GetWebhookEndpoint :one
+SELECT
+    *
+FROM
+    webhook_endpoints
+WHERE
+    id = $1;
+
+-- name: UpdateWebhookDeliveryAttempt :one
+UPDATE
+    webhook_deliveries
+SET
+    update_time = now(),
+    status = $2,
+    attempt_count = attempt_count + 1,
+    next_attempt_time = $3,
+    last_attempt_time = $4,
+    last_response_status_code = $5,
+    last_error = $6
+WHERE
+    id = $1
+RETURNING
+    *;
+
+-- name: ClaimWebhookOutboxMessages :many
+UPDATE
+    webhook_outbox_messages
+SET
+    next_attempt_time = @lease_expire_time
+WHERE
+    id IN (
+        SELECT
+            id
+        FROM
+            webhook_outbox_messages
+        WHERE
+            next_attempt_time <= now()
+        ORDER BY
+            next_attempt_time
+        LIMIT $1
+        FOR UPDATE
+            SKIP LOCKED)
+RETURNING
+    *;
+
+-- name: DeleteWebhookOutboxMessage :exec
+DELETE FROM webhook_outbox_messages
+WHERE id = $1;
+
+-- name: UpdateWebhookOutboxMessageAttempt :exec
+UPDATE
+    webhook_outbox_messages
+SET
+    attempt_count = attempt_count + 1,
+    next_attempt_time = $2,
+    last_error = $3
+WHERE
+    id = $1;
+
+-- name: CountWebhookOutboxMessages :one
+SELECT
+    count(*)
+FROM
+    webhook_outbox_messages;
+
+-- name: GetProjectWebhookSettings :one
+SELECT
+    *
+FROM
+    project_webhook_settings
+WHERE
+    project_id = $1;
+
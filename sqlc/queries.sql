@@ -29,11 +29,17 @@ RETURNING
     *;
 
 -- name: CreateUser :one
-INSERT INTO users (id, organization_id, email, is_owner, password_bcrypt, google_user_id, microsoft_user_id)
+INSERT INTO users (id, organization_id, identity_id, email, is_owner, google_user_id, microsoft_user_id)
     VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING
     *;
 
+-- name: CreateIdentity :one
+INSERT INTO identities (id, project_id, email, password_bcrypt, email_verified)
+    VALUES ($1, $2, $3, $4, TRUE)
+RETURNING
+    *;
+
 -- name: GetOrganizationByID :one
 SELECT
     *
@@ -127,9 +133,8 @@ UPDATE
 SET
     organization_id = $2,
     email = $3,
-    password_bcrypt = $4,
-    google_user_id = $5,
-    microsoft_user_id = $6
+    google_user_id = $4,
+    microsoft_user_id = $5
 WHERE
     id = $1
 RETURNING